@@ -0,0 +1,200 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// hedgedLogClient is a trillian.TrillianLogClient which hedges read RPCs
+// across a number of backends.
+type hedgedLogClient struct {
+	// TrillianLogClient is the primary backend, used for RPCs which are not
+	// hedged.
+	trillian.TrillianLogClient
+	backends []trillian.TrillianLogClient
+	delay    time.Duration
+}
+
+// NewHedgedLogClient returns a trillian.TrillianLogClient which hedges
+// idempotent read RPCs across the given backends, which would typically be
+// connections to different log server replicas.
+//
+// Each read RPC is first sent to backends[0]. If it hasn't completed after
+// delay, or if it fails with Unavailable or DeadlineExceeded, it is also sent
+// to the next backend, and so on. The first successful response is returned,
+// and the other requests cancelled. Any other error is returned straight
+// away, as another backend would give the same answer. If every backend
+// fails, the last error is returned. Write RPCs and
+// WatchSignedLogRoots are only sent to backends[0].
+//
+// Responses from different replicas may reflect different tree sizes, which
+// LogClient already tolerates. Callers using the returned client directly
+// should do the same, or pass the session_token of their writes with their
+// reads: replicas whose view is older than the token fail the read, which is
+// then sent to the next backend.
+func NewHedgedLogClient(delay time.Duration, backends ...trillian.TrillianLogClient) (trillian.TrillianLogClient, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("no backends")
+	}
+	if delay < 0 {
+		return nil, errors.New("negative hedging delay")
+	}
+	return &hedgedLogClient{
+		TrillianLogClient: backends[0],
+		backends:          backends,
+		delay:             delay,
+	}, nil
+}
+
+// hedge sends an RPC to each of the backends in turn, as described by
+// NewHedgedLogClient. Each attempt is made with its own copy of opts, see
+// attemptOpts.
+func hedge[Resp any](ctx context.Context, c *hedgedLogClient, opts []grpc.CallOption, call func(context.Context, trillian.TrillianLogClient, ...grpc.CallOption) (Resp, error)) (Resp, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp Resp
+		err  error
+		done func()
+	}
+	results := make(chan result, len(c.backends))
+	timer := time.NewTimer(c.delay)
+	defer timer.Stop()
+
+	started, pending := 0, 0
+	start := func() {
+		if started == len(c.backends) || ctx.Err() != nil {
+			return
+		}
+		backend := c.backends[started]
+		started++
+		pending++
+		attempt, done := attemptOpts(opts)
+		go func() {
+			resp, err := call(ctx, backend, attempt...)
+			results <- result{resp: resp, err: err, done: done}
+		}()
+		timer.Reset(c.delay)
+	}
+
+	start()
+	var last result
+	for pending > 0 {
+		select {
+		case last = <-results:
+			pending--
+			if last.err == nil || !hedgeable(last.err) {
+				last.done()
+				return last.resp, last.err
+			}
+			start()
+		case <-timer.C:
+			start()
+		}
+	}
+	last.done()
+	return last.resp, last.err
+}
+
+// hedgeable returns whether an RPC which failed with err may succeed on
+// another backend.
+func hedgeable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// attemptOpts returns a copy of opts for one attempt of a hedged RPC.
+// Options which store results of the RPC, such as its header, trailer or
+// peer, are given the attempt's own variables, so that concurrent attempts
+// don't race on the caller's. The returned function copies the results of the
+// attempt to the caller's variables, and is called for the attempt whose
+// response or error is returned.
+func attemptOpts(opts []grpc.CallOption) ([]grpc.CallOption, func()) {
+	ret := make([]grpc.CallOption, len(opts))
+	var copies []func()
+	for i, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			md := new(metadata.MD)
+			ret[i] = grpc.Header(md)
+			copies = append(copies, func() { *o.HeaderAddr = *md })
+		case grpc.TrailerCallOption:
+			md := new(metadata.MD)
+			ret[i] = grpc.Trailer(md)
+			copies = append(copies, func() { *o.TrailerAddr = *md })
+		case grpc.PeerCallOption:
+			p := new(peer.Peer)
+			ret[i] = grpc.Peer(p)
+			copies = append(copies, func() { *o.PeerAddr = *p })
+		default:
+			ret[i] = o
+		}
+	}
+	return ret, func() {
+		for _, c := range copies {
+			c()
+		}
+	}
+}
+
+func (c *hedgedLogClient) GetInclusionProof(ctx context.Context, in *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+		return b.GetInclusionProof(ctx, in, opts...)
+	})
+}
+
+func (c *hedgedLogClient) GetInclusionProofByHash(ctx context.Context, in *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+		return b.GetInclusionProofByHash(ctx, in, opts...)
+	})
+}
+
+func (c *hedgedLogClient) GetConsistencyProof(ctx context.Context, in *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+		return b.GetConsistencyProof(ctx, in, opts...)
+	})
+}
+
+func (c *hedgedLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+		return b.GetLatestSignedLogRoot(ctx, in, opts...)
+	})
+}
+
+func (c *hedgedLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+		return b.GetEntryAndProof(ctx, in, opts...)
+	})
+}
+
+func (c *hedgedLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	return hedge(ctx, c, opts, func(ctx context.Context, b trillian.TrillianLogClient, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+		return b.GetLeavesByRange(ctx, in, opts...)
+	})
+}
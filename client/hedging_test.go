@@ -0,0 +1,140 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeBackend answers GetLatestSignedLogRoot after a delay, with an error if
+// one is set, and otherwise with a root containing its name.
+type fakeBackend struct {
+	trillian.TrillianLogClient
+	name  string
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (f *fakeBackend) GetLatestSignedLogRoot(ctx context.Context, _ *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	f.calls.Add(1)
+	// Like a real connection, report the header however the call ends.
+	defer func() {
+		for _, o := range opts {
+			if h, ok := o.(grpc.HeaderCallOption); ok {
+				*h.HeaderAddr = metadata.Pairs("backend", f.name)
+			}
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-time.After(f.delay):
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: []byte(f.name)}}, nil
+}
+
+func TestHedgedLogClient(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	for _, test := range []struct {
+		desc      string
+		backends  []*fakeBackend
+		want      string
+		wantCode  codes.Code
+		wantCalls []int32
+	}{
+		{
+			desc:      "primary-fast",
+			backends:  []*fakeBackend{{name: "a"}, {name: "b"}},
+			want:      "a",
+			wantCalls: []int32{1, 0},
+		},
+		{
+			desc:      "primary-slow",
+			backends:  []*fakeBackend{{name: "a", delay: time.Minute}, {name: "b"}},
+			want:      "b",
+			wantCalls: []int32{1, 1},
+		},
+		{
+			desc:      "primary-fails",
+			backends:  []*fakeBackend{{name: "a", err: unavailable}, {name: "b", delay: 10 * time.Millisecond}},
+			want:      "b",
+			wantCalls: []int32{1, 1},
+		},
+		{
+			desc:      "primary-not-found",
+			backends:  []*fakeBackend{{name: "a", err: status.Error(codes.NotFound, "no such log")}, {name: "b"}},
+			wantCode:  codes.NotFound,
+			wantCalls: []int32{1, 0},
+		},
+		{
+			desc:      "all-fail",
+			backends:  []*fakeBackend{{name: "a", err: unavailable}, {name: "b", err: unavailable}},
+			wantCode:  codes.Unavailable,
+			wantCalls: []int32{1, 1},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			backends := make([]trillian.TrillianLogClient, 0, len(test.backends))
+			for _, b := range test.backends {
+				backends = append(backends, b)
+			}
+			c, err := NewHedgedLogClient(50*time.Millisecond, backends...)
+			if err != nil {
+				t.Fatalf("NewHedgedLogClient(): %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			var header metadata.MD
+			resp, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{}, grpc.Header(&header))
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetLatestSignedLogRoot()=%v, want code %v", err, test.wantCode)
+			}
+			if got := string(resp.GetSignedLogRoot().GetLogRoot()); got != test.want {
+				t.Errorf("GetLatestSignedLogRoot() answered by %q, want %q", got, test.want)
+			}
+			if got := header.Get("backend"); test.want != "" && (len(got) != 1 || got[0] != test.want) {
+				t.Errorf("GetLatestSignedLogRoot() header from %q, want %q", got, test.want)
+			}
+			for i, b := range test.backends {
+				if got := b.calls.Load(); got != test.wantCalls[i] {
+					t.Errorf("backend %s called %d times, want %d", b.name, got, test.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewHedgedLogClientErrors(t *testing.T) {
+	if _, err := NewHedgedLogClient(time.Second); err == nil {
+		t.Error("NewHedgedLogClient() with no backends returned nil error")
+	}
+	if _, err := NewHedgedLogClient(-time.Second, &fakeBackend{}); err == nil {
+		t.Error("NewHedgedLogClient() with negative delay returned nil error")
+	}
+}
@@ -75,6 +75,19 @@ func (c *LogClient) AddLeaf(ctx context.Context, data []byte) error {
 	return nil
 }
 
+// AddLeafHash adds a pre-hashed leaf, with no LeafValue, to the append only
+// log, which must allow pre-hashed leaves. Like AddLeaf, it blocks until a
+// successful inclusion proof can be retrieved.
+func (c *LogClient) AddLeafHash(ctx context.Context, leafHash []byte) error {
+	if err := c.QueueLeafHash(ctx, leafHash); err != nil {
+		return fmt.Errorf("QueueLeafHash(): %v", err)
+	}
+	if err := c.WaitForInclusionOfHash(ctx, leafHash); err != nil {
+		return fmt.Errorf("WaitForInclusionOfHash(): %v", err)
+	}
+	return nil
+}
+
 // ListByIndex returns the requested leaves by index.
 func (c *LogClient) ListByIndex(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
 	resp, err := c.client.GetLeavesByRange(ctx,
@@ -232,8 +245,12 @@ func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 // It is best to call this method with a context that will timeout to avoid
 // waiting forever.
 func (c *LogClient) WaitForInclusion(ctx context.Context, data []byte) error {
-	leaf := prepareLeaf(c.hasher, data)
+	return c.WaitForInclusionOfHash(ctx, c.hasher.HashLeaf(data))
+}
 
+// WaitForInclusionOfHash is like WaitForInclusion, but takes the Merkle leaf
+// hash of the leaf rather than its data.
+func (c *LogClient) WaitForInclusionOfHash(ctx context.Context, leafHash []byte) error {
 	// If a minimum merge delay has been configured, wait at least that long before
 	// starting to poll
 	if c.MinMergeDelay > 0 {
@@ -250,7 +267,7 @@ func (c *LogClient) WaitForInclusion(ctx context.Context, data []byte) error {
 
 		// It is illegal to ask for an inclusion proof with TreeSize = 0.
 		if root.TreeSize >= 1 {
-			ok, err := c.getAndVerifyInclusionProof(ctx, leaf.MerkleLeafHash, root)
+			ok, err := c.getAndVerifyInclusionProof(ctx, leafHash, root)
 			if err != nil && status.Code(err) != codes.NotFound {
 				return err
 			} else if ok {
@@ -333,6 +350,18 @@ func (c *LogClient) QueueLeaf(ctx context.Context, data []byte) error {
 	return err
 }
 
+// QueueLeafHash adds a pre-hashed leaf to a Trillian log without blocking.
+// The leaf has no LeafValue, so the log must allow pre-hashed leaves, and its
+// identity hash defaults to leafHash.
+// AlreadyExists is considered a success case by this function.
+func (c *LogClient) QueueLeafHash(ctx context.Context, leafHash []byte) error {
+	_, err := c.client.QueueLeaf(ctx, &trillian.QueueLeafRequest{
+		LogId: c.LogID,
+		Leaf:  &trillian.LogLeaf{MerkleLeafHash: leafHash},
+	})
+	return err
+}
+
 // prepareLeaf returns a trillian.LogLeaf prepopulated with leaf data and hash.
 func prepareLeaf(hasher merkle.LogHasher, data []byte) *trillian.LogLeaf {
 	leafHash := hasher.HashLeaf(data)
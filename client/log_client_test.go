@@ -25,6 +25,8 @@ import (
 	"github.com/google/trillian/testonly/integration"
 	"github.com/google/trillian/types"
 	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/google/trillian/storage/testdb"
@@ -47,7 +49,7 @@ func addSequencedLeaves(ctx context.Context, env *integration.LogEnv, client *Lo
 	if err := client.AddSequencedLeaves(ctx, dataByIndex); err != nil {
 		return fmt.Errorf("AddSequencedLeaves(): %v", err)
 	}
-	env.Sequencer.OperationSingle(ctx)
+	env.Sequence(ctx)
 	if err := client.WaitForInclusion(ctx, leaves[len(leaves)-1]); err != nil {
 		return fmt.Errorf("WaitForInclusion(): %v", err)
 	}
@@ -139,7 +141,7 @@ func TestWaitForInclusion(t *testing.T) {
 			if err := client.QueueLeaf(ctx, test.leaf); err != nil {
 				t.Fatalf("QueueLeaf(): %v", err)
 			}
-			env.Sequencer.OperationSingle(ctx)
+			env.Sequence(ctx)
 			err = client.WaitForInclusion(ctx, test.leaf)
 			if got := err != nil; got != test.wantErr {
 				t.Errorf("WaitForInclusion(): %v, want error: %v", err, test.wantErr)
@@ -148,6 +150,38 @@ func TestWaitForInclusion(t *testing.T) {
 	}
 }
 
+func TestAddLeafHash(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc     string
+		allow    bool
+		wantCode codes.Code
+	}{
+		{desc: "allowed", allow: true, wantCode: codes.OK},
+		{desc: "not allowed", wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+			tree.AllowPrehashedLeaves = tc.allow
+			env, client := clientEnvForTest(ctx, t, tree)
+			defer env.Close()
+
+			leafHash := rfc6962.DefaultHasher.HashLeaf([]byte("kept elsewhere"))
+			err := client.QueueLeafHash(ctx, leafHash)
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("QueueLeafHash(): %v, want code %v", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			env.Sequence(ctx)
+			if err := client.WaitForInclusionOfHash(ctx, leafHash); err != nil {
+				t.Errorf("WaitForInclusionOfHash(): %v", err)
+			}
+		})
+	}
+}
+
 func TestUpdateRoot(t *testing.T) {
 	ctx := context.Background()
 	env, client := clientEnvForTest(ctx, t, stestonly.LogTree)
@@ -169,7 +203,7 @@ func TestUpdateRoot(t *testing.T) {
 		t.Fatalf("QueueLeaf(%s): %v, want nil", data, err)
 	}
 
-	env.Sequencer.OperationSingle(ctx)
+	env.Sequence(ctx)
 
 	// UpdateRoot should see a change.
 	root, err = client.UpdateRoot(ctx)
@@ -193,7 +227,7 @@ func TestUpdateRootSkew(t *testing.T) {
 	if err := client.QueueLeaf(ctx, data); err != nil {
 		t.Fatalf("QueueLeaf(%s): %v, want nil", data, err)
 	}
-	env.Sequencer.OperationSingle(ctx)
+	env.Sequence(ctx)
 
 	root, err := client.UpdateRoot(ctx)
 	if err != nil {
@@ -205,7 +239,7 @@ func TestUpdateRootSkew(t *testing.T) {
 	if err := client.QueueLeaf(ctx, data2); err != nil {
 		t.Fatalf("QueueLeaf(%s): %v, want nil", data2, err)
 	}
-	env.Sequencer.OperationSingle(ctx)
+	env.Sequence(ctx)
 
 	// Now force a bad request.
 	badRawClient := &MutatingLogClient{TrillianLogClient: env.Log, mutateRootSize: true}
@@ -15,14 +15,17 @@
 package client
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math/bits"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/types"
 	"github.com/transparency-dev/merkle"
+	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/proof"
-	"github.com/transparency-dev/merkle/rfc6962"
 )
 
 // LogVerifier allows verification of output from Trillian Logs, both regular
@@ -49,7 +52,11 @@ func NewLogVerifierFromTree(config *trillian.Tree) (*LogVerifier, error) {
 		return nil, fmt.Errorf("client: NewLogVerifierFromTree(): TreeType: %v, want %v or %v", got, log, pLog)
 	}
 
-	return NewLogVerifier(rfc6962.DefaultHasher), nil
+	hasher, err := hashers.ForTree(config)
+	if err != nil {
+		return nil, fmt.Errorf("client: NewLogVerifierFromTree(): %v", err)
+	}
+	return NewLogVerifier(hasher), nil
 }
 
 // VerifyRoot verifies that newRoot is a valid append-only operation from
@@ -89,3 +96,138 @@ func (c *LogVerifier) VerifyInclusionByHash(trusted *types.LogRootV1, leafHash [
 
 	return proof.VerifyInclusion(c.hasher, uint64(pf.LeafIndex), trusted.TreeSize, leafHash, pf.Hashes, trusted.RootHash)
 }
+
+// VerifyRangeInclusion verifies that the leaves with the given Merkle
+// leafHashes are at indices [start, start+len(leafHashes)) of the tree with
+// the given trusted root, using the left and right hashes returned by
+// GetRangeInclusionProof.
+func (c *LogVerifier) VerifyRangeInclusion(trusted *types.LogRootV1, start uint64, leafHashes, left, right [][]byte) error {
+	if trusted == nil {
+		return fmt.Errorf("VerifyRangeInclusion() error: trusted == nil")
+	}
+	end := start + uint64(len(leafHashes))
+	if end > trusted.TreeSize {
+		return fmt.Errorf("VerifyRangeInclusion() error: range end %d beyond tree size %d", end, trusted.TreeSize)
+	}
+
+	rf := compact.RangeFactory{Hash: c.hasher.HashChildren}
+	cr, err := rf.NewRange(0, start, left)
+	if err != nil {
+		return fmt.Errorf("invalid left hashes: %v", err)
+	}
+	for _, h := range leafHashes {
+		if err := cr.Append(h, nil); err != nil {
+			return err
+		}
+	}
+	rr, err := rf.NewRange(end, trusted.TreeSize, right)
+	if err != nil {
+		return fmt.Errorf("invalid right hashes: %v", err)
+	}
+	if err := cr.AppendRange(rr, nil); err != nil {
+		return err
+	}
+	got, err := cr.GetRootHash(nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, trusted.RootHash) {
+		return fmt.Errorf("range [%d, %d) calculated root %x, want %x", start, end, got, trusted.RootHash)
+	}
+	return nil
+}
+
+// VerifyInclusionBatch verifies that each of the given Merkle leafHashes is
+// included in the tree with the given trusted root, using the corresponding
+// inclusion proof. It is equivalent to calling VerifyInclusionByHash for each
+// leaf, but shares the work of hashing the parts of the paths to the root
+// which the proofs have in common, which is most of it when many leaves of the
+// same tree are verified.
+//
+// Once a proof has been verified, the nodes on its path and their siblings
+// are known to be correct, so the verification of a later proof stops as soon
+// as it reaches one of them; the remainder of that proof isn't examined.
+func (c *LogVerifier) VerifyInclusionBatch(trusted *types.LogRootV1, leafHashes [][]byte, proofs []*trillian.Proof) error {
+	if trusted == nil {
+		return fmt.Errorf("VerifyInclusionBatch() error: trusted == nil")
+	}
+	if len(leafHashes) != len(proofs) {
+		return fmt.Errorf("VerifyInclusionBatch() error: got %d leaf hashes and %d proofs", len(leafHashes), len(proofs))
+	}
+
+	verified := make(map[compact.NodeID][]byte)
+	for i, pf := range proofs {
+		if pf == nil {
+			return fmt.Errorf("VerifyInclusionBatch() error: proof %d == nil", i)
+		}
+		if err := c.verifyInclusionCached(verified, trusted, leafHashes[i], pf); err != nil {
+			return fmt.Errorf("proof %d for leaf %d: %v", i, pf.LeafIndex, err)
+		}
+	}
+	return nil
+}
+
+// verifyInclusionCached verifies a single inclusion proof in the same way as
+// proof.VerifyInclusion, except that it stops at the first node found in
+// verified, and adds the nodes it visited to verified if it succeeds.
+func (c *LogVerifier) verifyInclusionCached(verified map[compact.NodeID][]byte, trusted *types.LogRootV1, leafHash []byte, pf *trillian.Proof) error {
+	index, size := uint64(pf.LeafIndex), trusted.TreeSize
+	if pf.LeafIndex < 0 || index >= size {
+		return fmt.Errorf("index %d out of range for tree size %d", pf.LeafIndex, size)
+	}
+	// The inner hashes are the siblings below the level at which the paths of
+	// the leaf and of the last leaf of the tree meet; the border hashes are
+	// left siblings on the right border of the tree above that.
+	inner := bits.Len64(index ^ (size - 1))
+	border := bits.OnesCount64(index >> uint(inner))
+	if got, want := len(pf.Hashes), inner+border; got != want {
+		return fmt.Errorf("wrong proof size %d, want %d", got, want)
+	}
+
+	// The nodes visited on the path from the leaf, and their siblings. Most
+	// proofs of a batch join a verified path after a few nodes.
+	var ids []compact.NodeID
+	var hashes [][]byte
+	id, hash := compact.NewNodeID(0, index), leafHash
+	siblings := pf.Hashes
+	for {
+		if want, ok := verified[id]; ok {
+			if !bytes.Equal(hash, want) {
+				return fmt.Errorf("calculated hash %x of node %+v, want %x", hash, id, want)
+			}
+			break
+		}
+		ids, hashes = append(ids, id), append(hashes, hash)
+		if id.Index == 0 && len(siblings) == 0 {
+			if !bytes.Equal(hash, trusted.RootHash) {
+				return fmt.Errorf("calculated root %x, want %x", hash, trusted.RootHash)
+			}
+			break
+		}
+		switch {
+		case id.Level < uint(inner):
+			// An inner sibling, on either side.
+			ids, hashes = append(ids, id.Sibling()), append(hashes, siblings[0])
+			if id.Index&1 == 0 {
+				hash = c.hasher.HashChildren(hash, siblings[0])
+			} else {
+				hash = c.hasher.HashChildren(siblings[0], hash)
+			}
+			siblings = siblings[1:]
+		case id.Index&1 == 1:
+			// A border sibling, always on the left.
+			ids, hashes = append(ids, id.Sibling()), append(hashes, siblings[0])
+			hash = c.hasher.HashChildren(siblings[0], hash)
+			siblings = siblings[1:]
+		default:
+			// The node has no sibling in a tree of this size, so its hash is
+			// promoted to the parent unchanged.
+		}
+		id = id.Parent()
+	}
+
+	for i, id := range ids {
+		verified[id] = hashes[i]
+	}
+	return nil
+}
@@ -15,11 +15,14 @@
 package client
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/types"
+	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/rfc6962"
+	inmemory "github.com/transparency-dev/merkle/testonly"
 )
 
 func TestVerifyRootErrors(t *testing.T) {
@@ -66,3 +69,150 @@ func TestVerifyInclusionByHashErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyRangeInclusion(t *testing.T) {
+	const size = 11
+	hasher := rfc6962.DefaultHasher
+	ref := inmemory.New(hasher)
+	var leafHashes [][]byte
+	for i := 0; i < size; i++ {
+		ref.AppendData([]byte(fmt.Sprintf("leaf-%d", i)))
+		leafHashes = append(leafHashes, ref.LeafHash(uint64(i)))
+	}
+	root := &types.LogRootV1{TreeSize: size, RootHash: ref.Hash()}
+	rf := compact.RangeFactory{Hash: hasher.HashChildren}
+	rangeHashes := func(begin, end uint64) [][]byte {
+		cr := rf.NewEmptyRange(begin)
+		for _, h := range leafHashes[begin:end] {
+			if err := cr.Append(h, nil); err != nil {
+				t.Fatalf("Append(): %v", err)
+			}
+		}
+		return cr.Hashes()
+	}
+	v := NewLogVerifier(hasher)
+
+	for _, r := range [][2]uint64{{0, size}, {0, 1}, {3, 8}, {4, 8}, {10, size}} {
+		start, end := r[0], r[1]
+		left, right := rangeHashes(0, start), rangeHashes(end, size)
+		if err := v.VerifyRangeInclusion(root, start, leafHashes[start:end], left, right); err != nil {
+			t.Errorf("VerifyRangeInclusion(%d, %d): %v", start, end, err)
+		}
+		tampered := append([][]byte{hasher.HashLeaf([]byte("tampered"))}, leafHashes[start+1:end]...)
+		if err := v.VerifyRangeInclusion(root, start, tampered, left, right); err == nil {
+			t.Errorf("VerifyRangeInclusion(%d, %d) with tampered leaf succeeded", start, end)
+		}
+	}
+
+	if err := v.VerifyRangeInclusion(root, 3, leafHashes[4:6], rangeHashes(0, 3), rangeHashes(5, size)); err == nil {
+		t.Error("VerifyRangeInclusion() with shifted leaves succeeded")
+	}
+	if err := v.VerifyRangeInclusion(nil, 0, leafHashes, nil, nil); err == nil {
+		t.Error("VerifyRangeInclusion() with nil root succeeded")
+	}
+}
+
+// inclusionBatch returns the leaf hashes and inclusion proofs for the given
+// indices of a tree of the given size, along with its root.
+func inclusionBatch(t testing.TB, size uint64, indices []uint64) (*types.LogRootV1, [][]byte, []*trillian.Proof) {
+	t.Helper()
+	ref := inmemory.New(rfc6962.DefaultHasher)
+	for i := uint64(0); i < size; i++ {
+		ref.AppendData([]byte(fmt.Sprintf("leaf-%d", i)))
+	}
+	var leafHashes [][]byte
+	var proofs []*trillian.Proof
+	for _, index := range indices {
+		hashes, err := ref.InclusionProof(index, size)
+		if err != nil {
+			t.Fatalf("InclusionProof(%d, %d): %v", index, size, err)
+		}
+		leafHashes = append(leafHashes, ref.LeafHash(index))
+		proofs = append(proofs, &trillian.Proof{LeafIndex: int64(index), Hashes: hashes})
+	}
+	return &types.LogRootV1{TreeSize: size, RootHash: ref.Hash()}, leafHashes, proofs
+}
+
+func TestVerifyInclusionBatch(t *testing.T) {
+	v := NewLogVerifier(rfc6962.DefaultHasher)
+	for _, size := range []uint64{1, 2, 7, 8, 11, 32, 33} {
+		var indices []uint64
+		for i := uint64(0); i < size; i++ {
+			indices = append(indices, i)
+		}
+		// Verify every leaf, twice, in an order which mixes the left and
+		// right of the tree.
+		for i := uint64(0); i < size; i++ {
+			indices = append(indices, (i*5)%size)
+		}
+		root, leafHashes, proofs := inclusionBatch(t, size, indices)
+		if err := v.VerifyInclusionBatch(root, leafHashes, proofs); err != nil {
+			t.Errorf("size %d: VerifyInclusionBatch(): %v", size, err)
+		}
+
+		// Every tampered proof must be rejected, even after the others have
+		// been verified.
+		for i := range proofs {
+			tampered := make([][]byte, len(leafHashes))
+			copy(tampered, leafHashes)
+			tampered[i] = rfc6962.DefaultHasher.HashLeaf([]byte("tampered"))
+			if err := v.VerifyInclusionBatch(root, tampered, proofs); err == nil {
+				t.Errorf("size %d: VerifyInclusionBatch() with tampered leaf %d succeeded", size, i)
+			}
+		}
+	}
+}
+
+func TestVerifyInclusionBatchErrors(t *testing.T) {
+	v := NewLogVerifier(rfc6962.DefaultHasher)
+	root, leafHashes, proofs := inclusionBatch(t, 11, []uint64{3, 4})
+	wrongIndex := []*trillian.Proof{proofs[0], {LeafIndex: 5, Hashes: proofs[1].Hashes}}
+	short := []*trillian.Proof{proofs[0], {LeafIndex: 4, Hashes: proofs[1].Hashes[1:]}}
+
+	for _, tc := range []struct {
+		desc       string
+		trusted    *types.LogRootV1
+		leafHashes [][]byte
+		proofs     []*trillian.Proof
+	}{
+		{desc: "trustedNil", trusted: nil, leafHashes: leafHashes, proofs: proofs},
+		{desc: "countMismatch", trusted: root, leafHashes: leafHashes[:1], proofs: proofs},
+		{desc: "proofNil", trusted: root, leafHashes: leafHashes, proofs: []*trillian.Proof{proofs[0], nil}},
+		{desc: "indexBeyondSize", trusted: root, leafHashes: leafHashes, proofs: []*trillian.Proof{{LeafIndex: 11}, proofs[1]}},
+		{desc: "wrongIndex", trusted: root, leafHashes: leafHashes, proofs: wrongIndex},
+		{desc: "shortProof", trusted: root, leafHashes: leafHashes, proofs: short},
+	} {
+		if err := v.VerifyInclusionBatch(tc.trusted, tc.leafHashes, tc.proofs); err == nil {
+			t.Errorf("%v: VerifyInclusionBatch() error expected, but got nil", tc.desc)
+		}
+	}
+}
+
+// BenchmarkVerifyInclusion compares verifying the inclusion proofs of 10k
+// consecutive leaves of a 1M leaf tree one at a time and as a batch.
+func BenchmarkVerifyInclusion(b *testing.B) {
+	const size, count = 1 << 20, 10000
+	indices := make([]uint64, count)
+	for i := range indices {
+		indices[i] = size/2 + uint64(i)
+	}
+	root, leafHashes, proofs := inclusionBatch(b, size, indices)
+	v := NewLogVerifier(rfc6962.DefaultHasher)
+
+	b.Run("naive", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i, pf := range proofs {
+				if err := v.VerifyInclusionByHash(root, leafHashes[i], pf); err != nil {
+					b.Fatalf("VerifyInclusionByHash(): %v", err)
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if err := v.VerifyInclusionBatch(root, leafHashes, proofs); err != nil {
+				b.Fatalf("VerifyInclusionBatch(): %v", err)
+			}
+		}
+	})
+}
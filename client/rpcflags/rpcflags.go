@@ -22,25 +22,52 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"k8s.io/klog/v2"
+
+	xdscreds "google.golang.org/grpc/credentials/xds"
+
+	// Register client-side health checking, used when enabled by the service
+	// config, and the xds:/// resolver, configured by GRPC_XDS_BOOTSTRAP.
+	_ "google.golang.org/grpc/health"
+	_ "google.golang.org/grpc/xds"
 )
 
 // tlsCertFile is the flag-assigned value for the path to the Trillian server's TLS certificate.
 var tlsCertFile = flag.String("tls_cert_file", "", "Path to the file containing the Trillian server's PEM-encoded public TLS certificate. If unset, unsecured connections will be used")
 
+var (
+	serviceConfig = flag.String("service_config", "", "Default gRPC service config, in JSON, e.g. to set the load balancing policy or enable client-side health checking. A service config provided by the resolver, e.g. over xDS, takes precedence")
+	xdsCreds      = flag.Bool("xds_creds", false, "If true, the security configuration is taken from the xDS control plane when dialing xds:/// targets, with --tls_cert_file as a fallback")
+)
+
 // NewClientDialOptionsFromFlags returns a list of grpc.DialOption values to be
 // passed as DialOption arguments to grpc.Dial
 func NewClientDialOptionsFromFlags() ([]grpc.DialOption, error) {
 	dialOpts := []grpc.DialOption{}
 
+	var creds credentials.TransportCredentials
 	if *tlsCertFile == "" {
-		klog.Warning("Using an insecure gRPC connection to Trillian")
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if !*xdsCreds {
+			klog.Warning("Using an insecure gRPC connection to Trillian")
+		}
+		creds = insecure.NewCredentials()
 	} else {
-		creds, err := credentials.NewClientTLSFromFile(*tlsCertFile, "")
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(*tlsCertFile, "")
 		if err != nil {
 			return nil, err
 		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	}
+	if *xdsCreds {
+		var err error
+		creds, err = xdscreds.NewClientCredentials(xdscreds.ClientOptions{FallbackCreds: creds})
+		if err != nil {
+			return nil, err
+		}
+	}
+	dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+
+	if *serviceConfig != "" {
+		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(*serviceConfig))
 	}
 
 	return dialOpts, nil
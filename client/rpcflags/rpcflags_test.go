@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/pem"
 	"flag"
+	"net"
 	"os"
 	"testing"
 
@@ -28,6 +29,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func TestNewClientDialOptionsFromFlagsWithTLSCertFileNotSet(t *testing.T) {
@@ -142,3 +146,53 @@ func TestNewClientDialOptionsFromFlagsWithTLSCertFileSet(t *testing.T) {
 		t.Errorf("failed to request trees from the Admin Server: %v", err)
 	}
 }
+
+func TestNewClientDialOptionsFromFlagsWithServiceConfig(t *testing.T) {
+	defer flagsaver.Save().MustRestore()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	for _, test := range []struct {
+		desc    string
+		config  string
+		wantErr bool
+	}{
+		{desc: "round-robin", config: `{"loadBalancingConfig": [{"round_robin": {}}], "healthCheckConfig": {"serviceName": ""}}`},
+		{desc: "invalid", config: `{"loadBalancingConfig": `, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if err := flag.Set("service_config", test.config); err != nil {
+				t.Fatalf("Failed to set flag: %v", err)
+			}
+			dialOpts, err := NewClientDialOptionsFromFlags()
+			if err != nil {
+				t.Fatalf("NewClientDialOptionsFromFlags(): %v", err)
+			}
+			conn, err := grpc.NewClient(lis.Addr().String(), dialOpts...)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("NewClient(): %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer func() {
+				if err := conn.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+			rsp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatalf("Check(): %v", err)
+			}
+			if got, want := rsp.Status, healthpb.HealthCheckResponse_SERVING; got != want {
+				t.Errorf("Check() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shardset manages families of temporally sharded trees, such as the
+// yearly shards of a Certificate Transparency log.
+//
+// Each tree in a shard set carries a trillian.TemporalShard describing the
+// set it belongs to and its validity window. The windows of a shard set are
+// contiguous and of equal length, starting from a fixed point in time. A
+// Manager creates shards ahead of time so that a shard always exists for the
+// near future, and For selects the shard an entry should be submitted to.
+package shardset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+)
+
+// ErrNoShard is returned by For if no shard covers the requested time.
+var ErrNoShard = errors.New("no shard covers the requested time")
+
+// Config defines a shard set.
+type Config struct {
+	// Name identifies the shard set. It is stored in the TemporalShard of
+	// every tree in the set.
+	Name string
+	// Template is used to create new shards. Its TemporalShard field is
+	// populated by the Manager and must be unset.
+	Template *trillian.Tree
+	// Start is the beginning of the first shard's validity window.
+	Start time.Time
+	// Period is the length of each shard's validity window.
+	Period time.Duration
+	// Lookahead is the number of shards after the current one which should
+	// exist at all times.
+	Lookahead int
+}
+
+// Manager maintains the trees of a single shard set.
+type Manager struct {
+	cfg   Config
+	admin trillian.TrillianAdminClient
+	log   trillian.TrillianLogClient
+}
+
+// NewManager returns a Manager for the shard set described by cfg, which uses
+// admin and log to list and create its trees.
+func NewManager(cfg Config, admin trillian.TrillianAdminClient, log trillian.TrillianLogClient) (*Manager, error) {
+	switch {
+	case cfg.Name == "":
+		return nil, errors.New("shard set name is required")
+	case cfg.Template == nil:
+		return nil, errors.New("shard template is required")
+	case cfg.Template.TemporalShard != nil:
+		return nil, errors.New("shard template must not have a temporal_shard")
+	case cfg.Period <= 0:
+		return nil, fmt.Errorf("shard period must be positive, got %v", cfg.Period)
+	case cfg.Lookahead < 0:
+		return nil, fmt.Errorf("shard lookahead must not be negative, got %d", cfg.Lookahead)
+	}
+	return &Manager{cfg: cfg, admin: admin, log: log}, nil
+}
+
+// Shards returns the trees of the shard set, ordered by the start of their
+// validity windows.
+func (m *Manager) Shards(ctx context.Context) ([]*trillian.Tree, error) {
+	resp, err := m.admin.ListTrees(ctx, &trillian.ListTreesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trees: %v", err)
+	}
+	var shards []*trillian.Tree
+	for _, tree := range resp.Tree {
+		if tree.GetTemporalShard().GetShardSet() == m.cfg.Name {
+			shards = append(shards, tree)
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].TemporalShard.NotAfterStart.AsTime().Before(shards[j].TemporalShard.NotAfterStart.AsTime())
+	})
+	return shards, nil
+}
+
+// EnsureShards creates any missing shards from the one covering now up to
+// Lookahead shards into the future, and returns all of the set's shards.
+// Shards are never created for windows which started before Config.Start.
+func (m *Manager) EnsureShards(ctx context.Context, now time.Time) ([]*trillian.Tree, error) {
+	shards, err := m.Shards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Windows are keyed by their instant, as the times read back from the
+	// trees are in UTC, whatever the location of Config.Start.
+	existing := make(map[int64]bool)
+	for _, s := range shards {
+		existing[s.TemporalShard.NotAfterStart.AsTime().UnixNano()] = true
+	}
+
+	first := int64(0)
+	if now.After(m.cfg.Start) {
+		first = int64(now.Sub(m.cfg.Start) / m.cfg.Period)
+	}
+	created := false
+	for i := first; i <= first+int64(m.cfg.Lookahead); i++ {
+		start := m.cfg.Start.Add(time.Duration(i) * m.cfg.Period)
+		if existing[start.UnixNano()] {
+			continue
+		}
+		tree := proto.Clone(m.cfg.Template).(*trillian.Tree)
+		tree.TemporalShard = &trillian.TemporalShard{
+			ShardSet:      m.cfg.Name,
+			NotAfterStart: timestamppb.New(start),
+			NotAfterLimit: timestamppb.New(start.Add(m.cfg.Period)),
+		}
+		newTree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: tree}, m.admin, m.log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shard starting at %v: %v", start, err)
+		}
+		klog.Infof("Created shard %d of shard set %q for [%v, %v)", newTree.TreeId, m.cfg.Name, start, start.Add(m.cfg.Period))
+		created = true
+	}
+	if !created {
+		return shards, nil
+	}
+	return m.Shards(ctx)
+}
+
+// Run calls EnsureShards every interval until ctx is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.EnsureShards(ctx, time.Now()); err != nil {
+			klog.Errorf("Failed to ensure shards of shard set %q: %v", m.cfg.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// For returns the shard whose validity window contains t, or ErrNoShard if
+// there is none. Shards which are not temporal shards are ignored.
+func For(shards []*trillian.Tree, t time.Time) (*trillian.Tree, error) {
+	for _, tree := range shards {
+		s := tree.GetTemporalShard()
+		if s == nil {
+			continue
+		}
+		if !t.Before(s.NotAfterStart.AsTime()) && t.Before(s.NotAfterLimit.AsTime()) {
+			return tree, nil
+		}
+	}
+	return nil, ErrNoShard
+}
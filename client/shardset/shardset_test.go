@@ -0,0 +1,136 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shardset
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/trilliantest"
+)
+
+func TestEnsureShards(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := trilliantest.NewLogEnv(ctx, trilliantest.Config{ManualSequencing: true})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	// A tree which isn't part of the shard set must be left alone.
+	if _, err := env.CreateLog(ctx, stestonly.LogTree); err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	const period = 24 * time.Hour
+	m, err := NewManager(Config{
+		Name:      "daily",
+		Template:  stestonly.LogTree,
+		Start:     start,
+		Period:    period,
+		Lookahead: 2,
+	}, env.Admin, env.Log)
+	if err != nil {
+		t.Fatalf("NewManager(): %v", err)
+	}
+
+	now := start.Add(36 * time.Hour)
+	shards, err := m.EnsureShards(ctx, now)
+	if err != nil {
+		t.Fatalf("EnsureShards(): %v", err)
+	}
+	if got, want := len(shards), 3; got != want {
+		t.Fatalf("EnsureShards() returned %d shards, want %d", got, want)
+	}
+	for i, s := range shards {
+		wantStart := start.Add(time.Duration(i+1) * period)
+		if got := s.TemporalShard.NotAfterStart.AsTime(); !got.Equal(wantStart) {
+			t.Errorf("shard %d starts at %v, want %v", i, got, wantStart)
+		}
+	}
+
+	// Calling again with the same time creates nothing new, while moving on a
+	// day creates exactly one more shard.
+	if again, err := m.EnsureShards(ctx, now); err != nil || len(again) != 3 {
+		t.Errorf("EnsureShards() again = %d shards, %v; want 3, nil", len(again), err)
+	}
+	if later, err := m.EnsureShards(ctx, now.Add(period)); err != nil || len(later) != 4 {
+		t.Errorf("EnsureShards() a day later = %d shards, %v; want 4, nil", len(later), err)
+	}
+
+	got, err := For(shards, now)
+	if err != nil {
+		t.Fatalf("For(%v): %v", now, err)
+	}
+	if got.TreeId != shards[0].TreeId {
+		t.Errorf("For(%v) = tree %d, want %d", now, got.TreeId, shards[0].TreeId)
+	}
+	if _, err := For(shards, start); !errors.Is(err, ErrNoShard) {
+		t.Errorf("For(%v) = %v, want %v", start, err, ErrNoShard)
+	}
+}
+
+func TestEnsureShardsNonUTCStart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := trilliantest.NewLogEnv(ctx, trilliantest.Config{ManualSequencing: true})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	// The shards read back from the trees start at the same instants, but in
+	// UTC, and without a monotonic clock reading.
+	start := time.Now().In(time.FixedZone("UTC+2", 2*60*60))
+	m, err := NewManager(Config{Name: "hourly", Template: stestonly.LogTree, Start: start, Period: time.Hour, Lookahead: 1}, env.Admin, env.Log)
+	if err != nil {
+		t.Fatalf("NewManager(): %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		shards, err := m.EnsureShards(ctx, start)
+		if err != nil {
+			t.Fatalf("EnsureShards(): %v", err)
+		}
+		if got, want := len(shards), 2; got != want {
+			t.Fatalf("EnsureShards() call %d returned %d shards, want %d", i, got, want)
+		}
+	}
+}
+
+func TestNewManagerErrors(t *testing.T) {
+	valid := Config{Name: "set", Template: stestonly.LogTree, Period: time.Hour}
+	for _, test := range []struct {
+		desc string
+		cfg  func(c Config) Config
+	}{
+		{desc: "no-name", cfg: func(c Config) Config { c.Name = ""; return c }},
+		{desc: "no-template", cfg: func(c Config) Config { c.Template = nil; return c }},
+		{desc: "zero-period", cfg: func(c Config) Config { c.Period = 0; return c }},
+		{desc: "negative-lookahead", cfg: func(c Config) Config { c.Lookahead = -1; return c }},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := NewManager(test.cfg(valid), nil, nil); err == nil {
+				t.Error("NewManager() returned nil error")
+			}
+		})
+	}
+}
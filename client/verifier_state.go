@@ -0,0 +1,168 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/transparency-dev/merkle/compact"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// verifierStateV1 is the version of the serialized VerifierState.
+const verifierStateV1 = 1
+
+// VerifierState is a verified root of a log, together with the compact range
+// of all the leaves which it covers. It allows a client which sees every leaf
+// of a growing log, such as a monitor, to verify each new root from just the
+// leaves added since, without fetching consistency proofs or keeping earlier
+// leaves. A VerifierState can be persisted with MarshalBinary, and restored
+// with LogVerifier.ParseVerifierState. It is immutable, and safe for
+// concurrent use.
+type VerifierState struct {
+	root types.LogRootV1
+	// hashes are those of the compact range [0, root.TreeSize).
+	hashes [][]byte
+}
+
+// Root returns the verified root of the state.
+func (s *VerifierState) Root() *types.LogRootV1 {
+	r := s.root
+	return &r
+}
+
+// MarshalBinary serializes the state as the TLS encoding of:
+//
+//	struct {
+//	  uint16 version;
+//	  opaque log_root<0..65535>;
+//	  Hash hashes<0..65535>;
+//	} VerifierState;
+//
+// where log_root is a serialized LogRoot, and Hash is opaque<1..255>.
+func (s *VerifierState) MarshalBinary() ([]byte, error) {
+	root, err := s.root.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var b cryptobyte.Builder
+	b.AddUint16(verifierStateV1)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(root) })
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, h := range s.hashes {
+			b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(h) })
+		}
+	})
+	return b.Bytes()
+}
+
+// NewVerifierState returns the state of an empty log, from which all its
+// leaves can be verified.
+func (c *LogVerifier) NewVerifierState() *VerifierState {
+	return &VerifierState{root: types.LogRootV1{RootHash: c.hasher.EmptyRoot()}}
+}
+
+// ParseVerifierState restores a state serialized by MarshalBinary, checking
+// that its compact range matches its root.
+func (c *LogVerifier) ParseVerifierState(data []byte) (*VerifierState, error) {
+	s := cryptobyte.String(data)
+	var version uint16
+	var root, hashes cryptobyte.String
+	if !s.ReadUint16(&version) || !s.ReadUint16LengthPrefixed(&root) || !s.ReadUint16LengthPrefixed(&hashes) || !s.Empty() {
+		return nil, errors.New("malformed verifier state")
+	}
+	if version != verifierStateV1 {
+		return nil, fmt.Errorf("invalid verifier state version: %d, want %d", version, verifierStateV1)
+	}
+	state := &VerifierState{}
+	if err := state.root.UnmarshalBinary(root); err != nil {
+		return nil, fmt.Errorf("invalid verifier state root: %v", err)
+	}
+	for !hashes.Empty() {
+		var h cryptobyte.String
+		if !hashes.ReadUint8LengthPrefixed(&h) || h.Empty() {
+			return nil, errors.New("malformed verifier state hash")
+		}
+		state.hashes = append(state.hashes, bytes.Clone(h))
+	}
+
+	rf := compact.RangeFactory{Hash: c.hasher.HashChildren}
+	cr, err := rf.NewRange(0, state.root.TreeSize, state.hashes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier state hashes: %v", err)
+	}
+	if err := c.checkRange(cr, &state.root); err != nil {
+		return nil, fmt.Errorf("verifier state: %v", err)
+	}
+	return state, nil
+}
+
+// VerifyAppend verifies that newRoot is the root of the log of trusted with
+// the leaves with the given Merkle leafHashes appended, and returns the state
+// for newRoot. The leafHashes must be those of all the leaves at indices
+// [trusted.Root().TreeSize, newRoot.TreeSize).
+func (c *LogVerifier) VerifyAppend(trusted *VerifierState, newRoot *trillian.SignedLogRoot, leafHashes [][]byte) (*VerifierState, error) {
+	if trusted == nil {
+		return nil, fmt.Errorf("VerifyAppend() error: trusted == nil")
+	}
+	if newRoot == nil {
+		return nil, fmt.Errorf("VerifyAppend() error: newRoot == nil")
+	}
+	var r types.LogRootV1
+	if err := r.UnmarshalBinary(newRoot.LogRoot); err != nil {
+		return nil, err
+	}
+	if got, want := trusted.root.TreeSize+uint64(len(leafHashes)), r.TreeSize; got != want {
+		return nil, fmt.Errorf("VerifyAppend() error: %d leaves appended to tree size %d, want tree size %d", len(leafHashes), trusted.root.TreeSize, want)
+	}
+
+	rf := compact.RangeFactory{Hash: c.hasher.HashChildren}
+	// Appending may reuse the slice passed in, so copy it to leave the
+	// trusted state untouched.
+	cr, err := rf.NewRange(0, trusted.root.TreeSize, slices.Clone(trusted.hashes))
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range leafHashes {
+		if err := cr.Append(h, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkRange(cr, &r); err != nil {
+		return nil, err
+	}
+	return &VerifierState{root: r, hashes: cr.Hashes()}, nil
+}
+
+// checkRange returns an error if the compact range [0, root.TreeSize) doesn't
+// match root.
+func (c *LogVerifier) checkRange(cr *compact.Range, root *types.LogRootV1) error {
+	got := c.hasher.EmptyRoot()
+	if root.TreeSize > 0 {
+		var err error
+		if got, err = cr.GetRootHash(nil); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(got, root.RootHash) {
+		return fmt.Errorf("tree size %d calculated root %x, want %x", root.TreeSize, got, root.RootHash)
+	}
+	return nil
+}
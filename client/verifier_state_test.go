@@ -0,0 +1,127 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/transparency-dev/merkle/rfc6962"
+	inmemory "github.com/transparency-dev/merkle/testonly"
+)
+
+func TestVerifyAppend(t *testing.T) {
+	hasher := rfc6962.DefaultHasher
+	ref := inmemory.New(hasher)
+	v := NewLogVerifier(hasher)
+	signedRoot := func(size uint64) *trillian.SignedLogRoot {
+		t.Helper()
+		logRoot, err := (&types.LogRootV1{TreeSize: size, RootHash: ref.HashAt(size)}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(): %v", err)
+		}
+		return &trillian.SignedLogRoot{LogRoot: logRoot}
+	}
+
+	state := v.NewVerifierState()
+	for _, batch := range []int{0, 1, 5, 2, 16, 9} {
+		begin := ref.Size()
+		for i := 0; i < batch; i++ {
+			ref.AppendData([]byte(fmt.Sprintf("leaf-%d", ref.Size())))
+		}
+		var leafHashes [][]byte
+		for i := begin; i < ref.Size(); i++ {
+			leafHashes = append(leafHashes, ref.LeafHash(i))
+		}
+		newRoot := signedRoot(ref.Size())
+
+		if batch > 0 {
+			tampered := append([][]byte{hasher.HashLeaf([]byte("tampered"))}, leafHashes[1:]...)
+			if _, err := v.VerifyAppend(state, newRoot, tampered); err == nil {
+				t.Errorf("VerifyAppend(%d) with tampered leaf succeeded", ref.Size())
+			}
+			if _, err := v.VerifyAppend(state, newRoot, leafHashes[1:]); err == nil {
+				t.Errorf("VerifyAppend(%d) with missing leaf succeeded", ref.Size())
+			}
+		}
+		next, err := v.VerifyAppend(state, newRoot, leafHashes)
+		if err != nil {
+			t.Fatalf("VerifyAppend(%d): %v", ref.Size(), err)
+		}
+
+		// The state survives a round trip through its serialization.
+		data, err := next.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(): %v", err)
+		}
+		state, err = v.ParseVerifierState(data)
+		if err != nil {
+			t.Fatalf("ParseVerifierState(): %v", err)
+		}
+		if diff := cmp.Diff(next.Root(), state.Root()); diff != "" {
+			t.Errorf("ParseVerifierState() root diff (-want +got):\n%s", diff)
+		}
+	}
+
+	if _, err := v.VerifyAppend(nil, signedRoot(0), nil); err == nil {
+		t.Error("VerifyAppend() with nil state succeeded")
+	}
+	if _, err := v.VerifyAppend(state, nil, nil); err == nil {
+		t.Error("VerifyAppend() with nil root succeeded")
+	}
+}
+
+func TestParseVerifierStateErrors(t *testing.T) {
+	hasher := rfc6962.DefaultHasher
+	ref := inmemory.New(hasher)
+	v := NewLogVerifier(hasher)
+	var leafHashes [][]byte
+	for i := 0; i < 7; i++ {
+		ref.AppendData([]byte(fmt.Sprintf("leaf-%d", i)))
+		leafHashes = append(leafHashes, ref.LeafHash(uint64(i)))
+	}
+	logRoot, err := (&types.LogRootV1{TreeSize: ref.Size(), RootHash: ref.Hash()}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	state, err := v.VerifyAppend(v.NewVerifierState(), &trillian.SignedLogRoot{LogRoot: logRoot}, leafHashes)
+	if err != nil {
+		t.Fatalf("VerifyAppend(): %v", err)
+	}
+	data, err := state.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc string
+		data []byte
+	}{
+		{desc: "empty", data: nil},
+		{desc: "truncated", data: data[:len(data)-1]},
+		{desc: "trailing", data: append(append([]byte{}, data...), 0)},
+		{desc: "version", data: append([]byte{0, 2}, data[2:]...)},
+		{desc: "corrupted-hash", data: append(append([]byte{}, data[:len(data)-1]...), data[len(data)-1]^1)},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := v.ParseVerifierState(test.data); err == nil {
+				t.Error("ParseVerifierState() succeeded")
+			}
+		})
+	}
+}
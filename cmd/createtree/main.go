@@ -22,6 +22,11 @@
 // stderr in case of failure. The output is minimal to allow for easy usage in
 // automated scripts.
 //
+// With --shard_set and --shard_period, the command defines a temporal shard set
+// instead, creating its missing shards up to --shard_lookahead shards ahead,
+// and outputs the tree IDs of all of the set's shards, one per line. Running
+// it again, e.g. from cron, creates the shards of the following windows.
+//
 // Several flags are provided to configure the create tree, most of which try to
 // assume reasonable defaults.
 package main
@@ -31,14 +36,18 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/client/shardset"
 	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/merkle/hashers"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
 
@@ -51,6 +60,26 @@ var (
 	displayName     = flag.String("display_name", "", "Display name of the new tree")
 	description     = flag.String("description", "", "Description of the new tree")
 	maxRootDuration = flag.Duration("max_root_duration", time.Hour, "Interval after which a new signed root is produced despite no submissions; zero means never")
+	autoFreeze      = flag.Bool("auto_freeze", false, "If true, the signer freezes the tree once it is DRAINING and all queued leaves have been integrated")
+	maxTreeSize     = flag.Int64("max_tree_size", 0, "Maximum number of leaves the tree may contain; zero means unlimited")
+	shardSet        = flag.String("shard_set", "", "If set, the name of the temporal shard set the new tree belongs to")
+	notAfterStart   = flag.String("not_after_start", "", "Start of the temporal shard's validity window (RFC 3339), inclusive")
+	notAfterLimit   = flag.String("not_after_limit", "", "End of the temporal shard's validity window (RFC 3339), exclusive")
+	shardPeriod     = flag.Duration("shard_period", 0, "If set, --shard_set defines a shard set whose windows are this long, starting at --not_after_start, and the missing shards from the one covering the current time up to --shard_lookahead ahead of it are created")
+	shardLookahead  = flag.Int("shard_lookahead", 1, "Number of shards after the current one which --shard_period creates")
+	owner           = flag.String("owner", "", "Team or person responsible for the new tree")
+	contact         = flag.String("contact", "", "How to reach the owner of the new tree, e.g. an email address")
+	mutableExtra    = flag.Bool("mutable_extra_data", false, "If true, the ExtraData of the new tree's leaves may be replaced with UpdateLeafExtraData")
+	allowRedaction  = flag.Bool("allow_redaction", false, "If true, the LeafValue of the new tree's leaves may be replaced with a tombstone with RedactLeaf")
+	leafKeyURI      = flag.String("leaf_encryption_key_uri", "", "If set, the new tree's leaf data is encrypted at rest with a data key wrapped by this key encryption key")
+	mergeDelay      = flag.Duration("merge_delay_target", 0, "If set, the delay within which the new tree's leaves should be integrated, which the signer tracks its compliance with")
+	dequeueOrder    = flag.String("dequeue_order", trillian.DequeueOrder_STORAGE_DEQUEUE_ORDER.String(), "Order in which the new tree's queued leaves are integrated")
+	disabledMethods = flag.String("disabled_methods", "", "Comma-separated names of the TrillianLog RPCs which are refused for the new tree, e.g. GetLeavesByRange")
+	allowPrehashed  = flag.Bool("allow_prehashed_leaves", false, "If true, leaves may be queued to the new tree with only their Merkle leaf hash and no LeafValue")
+	leafValidators  = flag.String("leaf_validators", "", "Comma-separated names of the registered leaf validators which check the leaves queued to the new tree")
+	origin          = flag.String("checkpoint_origin", "", "Origin line of the new tree's checkpoints, unique within the deployment, e.g. example.com/log2025")
+	ecosystemIDs    = flag.String("ecosystem_ids", "", "Comma-separated <ecosystem>:<identifier> IDs of the new tree, each unique within the deployment")
+	hasherID        = flag.String("hasher_id", "", fmt.Sprintf("Hasher of the new tree's Merkle tree, empty meaning %v. One of: %v", hashers.RFC6962SHA256, hashers.IDs()))
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
@@ -58,7 +87,7 @@ var (
 )
 
 // TODO(Martin2112): Pass everything needed into this and don't refer to flags.
-func createTree(ctx context.Context) (*trillian.Tree, error) {
+func createTree(ctx context.Context) ([]*trillian.Tree, error) {
 	if *adminServerAddr == "" {
 		return nil, errAdminAddrNotSet
 	}
@@ -86,7 +115,38 @@ func createTree(ctx context.Context) (*trillian.Tree, error) {
 	adminClient := trillian.NewTrillianAdminClient(conn)
 	logClient := trillian.NewTrillianLogClient(conn)
 
-	return client.CreateAndInitTree(ctx, req, adminClient, logClient)
+	if *shardSet != "" && *shardPeriod > 0 {
+		return createShards(ctx, req.Tree, adminClient, logClient, time.Now())
+	}
+	tree, err := client.CreateAndInitTree(ctx, req, adminClient, logClient)
+	if err != nil {
+		return nil, err
+	}
+	return []*trillian.Tree{tree}, nil
+}
+
+// createShards creates the missing shards of the shard set defined by the
+// flags, from the one covering now, with template as the tree of each shard.
+// It returns all of the set's shards.
+func createShards(ctx context.Context, template *trillian.Tree, admin trillian.TrillianAdminClient, log trillian.TrillianLogClient, now time.Time) ([]*trillian.Tree, error) {
+	if *notAfterLimit != "" {
+		return nil, errors.New("not_after_limit can't be set with shard_period")
+	}
+	start, err := time.Parse(time.RFC3339, *notAfterStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse not_after_start: %v", err)
+	}
+	m, err := shardset.NewManager(shardset.Config{
+		Name:      *shardSet,
+		Template:  template,
+		Start:     start,
+		Period:    *shardPeriod,
+		Lookahead: *shardLookahead,
+	}, admin, log)
+	if err != nil {
+		return nil, err
+	}
+	return m.EnsureShards(ctx, now)
 }
 
 func newRequest() (*trillian.CreateTreeRequest, error) {
@@ -100,13 +160,58 @@ func newRequest() (*trillian.CreateTreeRequest, error) {
 		return nil, fmt.Errorf("unknown TreeType: %v", *treeType)
 	}
 
+	do, ok := trillian.DequeueOrder_value[*dequeueOrder]
+	if !ok {
+		return nil, fmt.Errorf("unknown DequeueOrder: %v", *dequeueOrder)
+	}
+
 	ctr := &trillian.CreateTreeRequest{Tree: &trillian.Tree{
-		TreeState:       trillian.TreeState(ts),
-		TreeType:        trillian.TreeType(tt),
-		DisplayName:     *displayName,
-		Description:     *description,
-		MaxRootDuration: durationpb.New(*maxRootDuration),
+		TreeState:            trillian.TreeState(ts),
+		TreeType:             trillian.TreeType(tt),
+		DisplayName:          *displayName,
+		Description:          *description,
+		MaxRootDuration:      durationpb.New(*maxRootDuration),
+		AutoFreeze:           *autoFreeze,
+		MaxTreeSize:          *maxTreeSize,
+		Owner:                *owner,
+		Contact:              *contact,
+		CheckpointOrigin:     *origin,
+		MutableExtraData:     *mutableExtra,
+		AllowRedaction:       *allowRedaction,
+		HasherId:             *hasherID,
+		DequeueOrder:         trillian.DequeueOrder(do),
+		AllowPrehashedLeaves: *allowPrehashed,
 	}}
+	if *disabledMethods != "" {
+		ctr.Tree.DisabledMethods = strings.Split(*disabledMethods, ",")
+	}
+	if *leafValidators != "" {
+		ctr.Tree.LeafValidators = strings.Split(*leafValidators, ",")
+	}
+	if *ecosystemIDs != "" {
+		ctr.Tree.EcosystemIds = strings.Split(*ecosystemIDs, ",")
+	}
+	if *mergeDelay > 0 {
+		ctr.Tree.MergeDelayTarget = durationpb.New(*mergeDelay)
+	}
+	if *shardSet != "" && *shardPeriod == 0 {
+		start, err := time.Parse(time.RFC3339, *notAfterStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse not_after_start: %v", err)
+		}
+		limit, err := time.Parse(time.RFC3339, *notAfterLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse not_after_limit: %v", err)
+		}
+		ctr.Tree.TemporalShard = &trillian.TemporalShard{
+			ShardSet:      *shardSet,
+			NotAfterStart: timestamppb.New(start),
+			NotAfterLimit: timestamppb.New(limit),
+		}
+	}
+	if *leafKeyURI != "" {
+		ctr.Tree.LeafEncryption = &trillian.LeafEncryption{KeyUri: *leafKeyURI}
+	}
 	klog.Infof("Creating tree %+v", ctr.Tree)
 
 	return ctr, nil
@@ -125,13 +230,16 @@ func main() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), *rpcDeadline)
 	defer cancel()
-	tree, err := createTree(ctx)
+	trees, err := createTree(ctx)
 	if err != nil {
 		klog.Exitf("Failed to create tree: %v", err)
 	}
 
 	// DO NOT change the output format, scripts are meant to depend on it.
 	// If you really want to change it, provide an output_format flag and
-	// keep the default as-is.
-	fmt.Println(tree.TreeId)
+	// keep the default as-is. The shards of a shard set are output one per
+	// line.
+	for _, tree := range trees {
+		fmt.Println(tree.TreeId)
+	}
 }
@@ -17,6 +17,7 @@ package main
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -52,6 +53,14 @@ func TestCreateTree(t *testing.T) {
 	nonDefaultTree.TreeType = trillian.TreeType_LOG
 	nonDefaultTree.DisplayName = "Llamas Log"
 	nonDefaultTree.Description = "For all your digital llama needs!"
+	nonDefaultTree.Owner = "llama-team"
+	nonDefaultTree.Contact = "llamas@example.com"
+	nonDefaultTree.MutableExtraData = true
+	nonDefaultTree.AllowRedaction = true
+	nonDefaultTree.LeafEncryption = &trillian.LeafEncryption{KeyUri: "local:llama-kek"}
+	nonDefaultTree.DequeueOrder = trillian.DequeueOrder_FIFO_DEQUEUE_ORDER
+	nonDefaultTree.DisabledMethods = []string{"GetLeavesByRange", "GetLeavesByIndices"}
+	nonDefaultTree.AllowPrehashedLeaves = true
 
 	runTest(t, []*testCase{
 		{
@@ -65,6 +74,14 @@ func TestCreateTree(t *testing.T) {
 				*treeType = nonDefaultTree.TreeType.String()
 				*displayName = nonDefaultTree.DisplayName
 				*description = nonDefaultTree.Description
+				*owner = nonDefaultTree.Owner
+				*contact = nonDefaultTree.Contact
+				*mutableExtra = nonDefaultTree.MutableExtraData
+				*allowRedaction = nonDefaultTree.AllowRedaction
+				*leafKeyURI = nonDefaultTree.LeafEncryption.KeyUri
+				*dequeueOrder = nonDefaultTree.DequeueOrder.String()
+				*disabledMethods = strings.Join(nonDefaultTree.DisabledMethods, ",")
+				*allowPrehashed = nonDefaultTree.AllowPrehashedLeaves
 			},
 			wantTree: nonDefaultTree,
 		},
@@ -105,6 +122,54 @@ func TestCreateTree(t *testing.T) {
 	})
 }
 
+func TestCreateShards(t *testing.T) {
+	defer flagsaver.Save().MustRestore()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, stopFakeServer, err := testonly.NewMockServer(ctrl)
+	if err != nil {
+		t.Fatalf("Error starting fake server: %v", err)
+	}
+	defer stopFakeServer()
+	*adminServerAddr = s.Addr
+	*shardSet = "daily"
+	*notAfterStart = "2025-01-01T00:00:00Z"
+	*shardPeriod = 24 * time.Hour
+	*shardLookahead = 1
+
+	var created []*trillian.Tree
+	s.Admin.EXPECT().ListTrees(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *trillian.ListTreesRequest) (*trillian.ListTreesResponse, error) {
+		return &trillian.ListTreesResponse{Tree: created}, nil
+	}).Times(2)
+	s.Admin.EXPECT().CreateTree(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req *trillian.CreateTreeRequest) (*trillian.Tree, error) {
+		tree := proto.Clone(req.Tree).(*trillian.Tree)
+		tree.TreeId = int64(len(created) + 1)
+		created = append(created, tree)
+		return tree, nil
+	}).Times(2)
+	s.Log.EXPECT().InitLog(gomock.Any(), gomock.Any()).Return(&trillian.InitLogResponse{}, nil).Times(2)
+	s.Log.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(&trillian.GetLatestSignedLogRootResponse{}, nil).Times(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	shards, err := createTree(ctx)
+	if err != nil {
+		t.Fatalf("createTree(): %v", err)
+	}
+	if got, want := len(shards), 2; got != want {
+		t.Fatalf("createTree() returned %d shards, want %d", got, want)
+	}
+	now := time.Now()
+	first := shards[0].TemporalShard
+	if first.GetShardSet() != "daily" || now.Before(first.NotAfterStart.AsTime()) || !now.Before(first.NotAfterLimit.AsTime()) {
+		t.Errorf("first shard %v doesn't cover now", first)
+	}
+	if got, want := shards[1].TemporalShard.NotAfterStart.AsTime(), first.NotAfterLimit.AsTime(); !got.Equal(want) {
+		t.Errorf("second shard starts at %v, want %v", got, want)
+	}
+}
+
 // runTest executes the createtree command against a fake TrillianAdminServer
 // for each of the provided tests, and checks that the tree in the request is
 // as expected, or an expected error occurs.
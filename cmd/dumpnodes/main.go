@@ -0,0 +1,115 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the implementation and entry point for the dumpnodes
+// command, a debugging tool which prints the stored tiles of Merkle nodes
+// containing a given node of a log, from the node's own tile up to the root.
+//
+// This helps to diagnose proof mismatches, e.g. those reported by monitors,
+// by showing what is physically in storage. The command only reads storage.
+//
+// Example usage:
+// $ ./dumpnodes --storage_system=mysql --mysql_uri=... --log_id=logid --level=0 --index=1234
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/protobuf/encoding/prototext"
+	"k8s.io/klog/v2"
+
+	// Register supported storage providers.
+	"github.com/google/trillian/cmd/internal/provider"
+)
+
+var (
+	storageSystem = flag.String("storage_system", provider.DefaultStorageSystem, fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	logID         = flag.Int64("log_id", 0, "Trillian LogID whose Merkle nodes are dumped")
+	level         = flag.Uint("level", 0, "Level of the node, 0 being the leaves")
+	index         = flag.Uint64("index", 0, "Index of the node within its level")
+	revision      = flag.Int64("revision", -1, "Tree revision to read the tiles at, or -1 for the latest")
+	configFile    = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			klog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+
+	ctx := context.Background()
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		klog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer func() {
+		if err := sp.Close(); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	tree, err := storage.GetTree(ctx, sp.AdminStorage(), *logID)
+	if err != nil {
+		klog.Exitf("Failed to get tree %d: %v", *logID, err)
+	}
+	tx, err := sp.LogStorage().SnapshotForTree(ctx, tree)
+	if err != nil {
+		klog.Exitf("Failed to start snapshot: %v", err)
+	}
+	defer tx.Close()
+	sr, ok := tx.(storage.SubtreeReader)
+	if !ok {
+		klog.Exitf("Storage system %q doesn't support reading tiles", *storageSystem)
+	}
+
+	id := compact.NewNodeID(*level, *index)
+	if *revision < 0 {
+		// Stored nodes are only meaningful for the latest revision.
+		if nodes, err := tx.GetMerkleNodes(ctx, []compact.NodeID{id}); err != nil {
+			fmt.Printf("# Node %+v: %v\n", id, err)
+		} else if len(nodes) == 1 {
+			fmt.Printf("# Node %+v: %x\n", id, nodes[0].Hash)
+		}
+	}
+	ids := cache.TileIDs(id)
+	tiles, err := sr.ReadSubtrees(ctx, ids, *revision)
+	if err != nil {
+		klog.Exitf("Failed to read tiles: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, tile := range tiles {
+		found[string(tile.Prefix)] = true
+		fmt.Printf("# Tile %x\n%s\n", tile.Prefix, prototext.Format(tile))
+	}
+	for _, id := range ids {
+		if !found[string(id)] {
+			fmt.Printf("# Tile %x: not stored\n", id)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		klog.Exitf("Commit(): %v", err)
+	}
+}
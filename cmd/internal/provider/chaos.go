@@ -0,0 +1,7 @@
+//go:build chaos
+
+package provider
+
+import (
+	_ "github.com/google/trillian/util/election2/chaos"
+)
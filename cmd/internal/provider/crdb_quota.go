@@ -0,0 +1,7 @@
+//go:build crdbquota || (!(etcdquota || memoryquota || mysqlquota || postgresqlquota) && (crdb || !(cloudspanner || mysql || postgresql)))
+
+package provider
+
+import (
+	_ "github.com/google/trillian/quota/crdbqm"
+)
@@ -0,0 +1,7 @@
+//go:build etcdquota || !(crdbquota || memoryquota || mysqlquota || postgresqlquota)
+
+package provider
+
+import (
+	_ "github.com/google/trillian/quota/etcd"
+)
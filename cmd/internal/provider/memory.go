@@ -0,0 +1,7 @@
+//go:build memoryquota || !(crdbquota || etcdquota || mysqlquota || postgresqlquota)
+
+package provider
+
+import (
+	_ "github.com/google/trillian/quota/memoryqm"
+)
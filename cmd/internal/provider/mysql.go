@@ -4,6 +4,4 @@ package provider
 
 import (
 	_ "github.com/google/trillian/storage/mysql"
-
-	_ "github.com/google/trillian/quota/mysqlqm"
 )
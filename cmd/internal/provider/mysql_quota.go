@@ -0,0 +1,7 @@
+//go:build mysqlquota || (!(crdbquota || etcdquota || memoryquota || postgresqlquota) && (mysql || !(cloudspanner || crdb || postgresql)))
+
+package provider
+
+import (
+	_ "github.com/google/trillian/quota/mysqlqm"
+)
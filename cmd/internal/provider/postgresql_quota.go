@@ -0,0 +1,7 @@
+//go:build postgresqlquota || (!(crdbquota || etcdquota || memoryquota || mysqlquota) && (postgresql || !(cloudspanner || crdb || mysql)))
+
+package provider
+
+import (
+	_ "github.com/google/trillian/quota/postgresqlqm"
+)
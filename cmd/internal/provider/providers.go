@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/election2"
+)
+
+var (
+	// EtcdServers holds the addresses of the etcd servers, which the etcd
+	// quota and election providers use, and with which the servers announce
+	// themselves. It's defined here, rather than by one of those providers,
+	// so that it exists whichever of them are compiled in.
+	EtcdServers = flag.String("etcd_servers", "", "A comma-separated list of etcd servers; no etcd registration if empty")
+
+	// ListProviders is set to have the binary print the providers compiled
+	// in to it and exit.
+	ListProviders = flag.Bool("list_providers", false, "If true, print the storage, quota and election providers compiled in to this binary, and exit")
+)
+
+// PrintProviders writes the names of the storage, quota and election
+// providers which are compiled in to the binary to w, one kind per line.
+func PrintProviders(w io.Writer) error {
+	for _, kind := range []struct {
+		name      string
+		providers []string
+	}{
+		{name: "storage", providers: storage.Providers()},
+		{name: "quota", providers: quota.Providers()},
+		{name: "election", providers: election2.Providers()},
+	} {
+		slices.Sort(kind.providers)
+		if _, err := fmt.Fprintf(w, "%s: %v\n", kind.name, kind.providers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintProviders(t *testing.T) {
+	var b strings.Builder
+	if err := PrintProviders(&b); err != nil {
+		t.Fatalf("PrintProviders(): %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("PrintProviders() wrote %q, want 3 lines", b.String())
+	}
+	for i, want := range []string{"storage: [", "quota: [", "election: ["} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("PrintProviders() line %d = %q, want prefix %q", i, lines[i], want)
+		}
+	}
+	// The noop quota and election providers are always compiled in.
+	if !strings.Contains(lines[1], "noop") || !strings.Contains(lines[2], "noop") {
+		t.Errorf("PrintProviders() = %q, want the noop providers", b.String())
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selftest runs the checks of the --self_test mode of the Trillian
+// binaries, which check their configuration and dependencies, report on each
+// of them, and exit. It's meant for init containers and for pre-flight checks
+// of configuration changes.
+package selftest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/election2"
+)
+
+// ElectionResource is the resource which the election check stands for.
+const ElectionResource = "trillian-self-test"
+
+// Check is one check of a binary's configuration.
+type Check struct {
+	// Name identifies what is checked in the report.
+	Name string
+	// Run returns a description of what it found, or an error if the check
+	// fails.
+	Run func(ctx context.Context) (string, error)
+}
+
+// Run runs the checks in order, each with the timeout, and writes a report of
+// them to w. It returns whether all of them passed.
+func Run(ctx context.Context, w io.Writer, timeout time.Duration, checks []Check) bool {
+	failed := 0
+	for _, c := range checks {
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		detail, err := c.Run(cctx)
+		cancel()
+		took := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s (%v): %v\n", c.Name, took, err)
+		} else {
+			fmt.Fprintf(w, "PASS %s (%v): %s\n", c.Name, took, detail)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(w, "Self-test failed: %d of %d checks failed\n", failed, len(checks))
+		return false
+	}
+	fmt.Fprintf(w, "Self-test passed: %d checks\n", len(checks))
+	return true
+}
+
+// Storage returns the checks of the storage system: that the provider can be
+// created, that its databases are accessible, and that they have the schema
+// of this version of Trillian, if the provider can tell. The provider is
+// closed after the last of them.
+func Storage(system string, newProvider func() (storage.Provider, error)) []Check {
+	var sp storage.Provider
+	return []Check{
+		{
+			Name: "storage",
+			Run: func(ctx context.Context) (string, error) {
+				var err error
+				if sp, err = newProvider(); err != nil {
+					return "", fmt.Errorf("failed to create %s storage provider: %v", system, err)
+				}
+				if err := sp.AdminStorage().CheckDatabaseAccessible(ctx); err != nil {
+					return "", fmt.Errorf("admin storage isn't accessible: %v", err)
+				}
+				if err := sp.LogStorage().CheckDatabaseAccessible(ctx); err != nil {
+					return "", fmt.Errorf("log storage isn't accessible: %v", err)
+				}
+				return fmt.Sprintf("%s storage is accessible", system), nil
+			},
+		},
+		{
+			Name: "schema",
+			Run: func(ctx context.Context) (string, error) {
+				if sp == nil {
+					return "", errors.New("the storage provider couldn't be created")
+				}
+				defer func() {
+					_ = sp.Close()
+				}()
+				sc, ok := sp.(storage.SchemaChecker)
+				if !ok {
+					return fmt.Sprintf("%s storage can't check its schema; skipped", system), nil
+				}
+				if err := sc.CheckSchema(ctx); err != nil {
+					return "", err
+				}
+				return "the database has the schema of this version", nil
+			},
+		},
+	}
+}
+
+// Quota returns the check of the quota system, which creates its manager,
+// takes a global write token from it and puts the token back.
+func Quota(system string) Check {
+	return Check{
+		Name: "quota",
+		Run: func(ctx context.Context) (string, error) {
+			qm, err := quota.NewManager(system)
+			if err != nil {
+				return "", fmt.Errorf("failed to create %s quota manager: %v", system, err)
+			}
+			specs := []quota.Spec{{Group: quota.Global, Kind: quota.Write}}
+			if err := qm.GetTokens(ctx, 1, specs); err != nil {
+				return "", fmt.Errorf("failed to get a token from %s quota manager: %v", system, err)
+			}
+			if err := qm.PutTokens(ctx, 1, specs); err != nil {
+				return "", fmt.Errorf("failed to put a token back to %s quota manager: %v", system, err)
+			}
+			return fmt.Sprintf("%s quota manager answers", system), nil
+		},
+	}
+}
+
+// Election returns the check of the election system, which wins and then
+// resigns the election of ElectionResource.
+func Election(system string, newFactory func() (election2.Factory, error)) Check {
+	return Check{
+		Name: "election",
+		Run: func(ctx context.Context) (string, error) {
+			f, err := newFactory()
+			if err != nil {
+				return "", fmt.Errorf("failed to create %s election factory: %v", system, err)
+			}
+			e, err := f.NewElection(ctx, ElectionResource)
+			if err != nil {
+				return "", fmt.Errorf("failed to create %s election: %v", system, err)
+			}
+			defer func() {
+				_ = e.Close(context.Background())
+			}()
+			if err := e.Await(ctx); err != nil {
+				return "", fmt.Errorf("failed to win %s election of %s: %v", system, ElectionResource, err)
+			}
+			if err := e.Resign(ctx); err != nil {
+				return "", fmt.Errorf("failed to resign %s election of %s: %v", system, ElectionResource, err)
+			}
+			return fmt.Sprintf("won and resigned the %s election of %s", system, ElectionResource), nil
+		},
+	}
+}
+
+// KeyPair returns the check of a TLS certificate and key, which must match,
+// and the certificate must be valid now.
+func KeyPair(name, certFile, keyFile string) Check {
+	return Check{
+		Name: name,
+		Run: func(context.Context) (string, error) {
+			if certFile == "" && keyFile == "" {
+				return "not configured", nil
+			}
+			pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return "", err
+			}
+			cert, err := x509.ParseCertificate(pair.Certificate[0])
+			if err != nil {
+				return "", err
+			}
+			now := time.Now()
+			if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+				return "", fmt.Errorf("certificate %s is valid from %v to %v", certFile, cert.NotBefore, cert.NotAfter)
+			}
+			return fmt.Sprintf("certificate for %q expires %v", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)), nil
+		},
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selftest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/election2"
+
+	_ "github.com/google/trillian/storage/memory"
+)
+
+func TestRun(t *testing.T) {
+	pass := Check{Name: "pass", Run: func(context.Context) (string, error) { return "fine", nil }}
+	fail := Check{Name: "fail", Run: func(context.Context) (string, error) { return "", errors.New("broken") }}
+	slow := Check{Name: "slow", Run: func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+
+	for _, test := range []struct {
+		desc   string
+		checks []Check
+		want   bool
+		lines  []string
+	}{
+		{desc: "pass", checks: []Check{pass, pass}, want: true, lines: []string{"PASS pass (", "): fine", "Self-test passed: 2 checks"}},
+		{desc: "fail", checks: []Check{pass, fail}, lines: []string{"FAIL fail (", "): broken", "Self-test failed: 1 of 2 checks failed"}},
+		{desc: "timeout", checks: []Check{slow, pass}, lines: []string{"FAIL slow (", "deadline exceeded", "PASS pass (", "Self-test failed: 1 of 2 checks failed"}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var b strings.Builder
+			if got := Run(context.Background(), &b, 10*time.Millisecond, test.checks); got != test.want {
+				t.Errorf("Run() = %v, want %v", got, test.want)
+			}
+			for _, l := range test.lines {
+				if !strings.Contains(b.String(), l) {
+					t.Errorf("Run() wrote %q, want it to contain %q", b.String(), l)
+				}
+			}
+		})
+	}
+}
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	checks := Storage("memory", func() (storage.Provider, error) {
+		return storage.NewProvider("memory", monitoring.InertMetricFactory{})
+	})
+	if !Run(ctx, &strings.Builder{}, time.Second, checks) {
+		t.Error("Run(memory storage) = false, want true")
+	}
+
+	checks = Storage("missing", func() (storage.Provider, error) {
+		return storage.NewProvider("missing", monitoring.InertMetricFactory{})
+	})
+	var b strings.Builder
+	if Run(ctx, &b, time.Second, checks) {
+		t.Error("Run(missing storage) = true, want false")
+	}
+	if want := "Self-test failed: 2 of 2 checks failed"; !strings.Contains(b.String(), want) {
+		t.Errorf("Run(missing storage) wrote %q, want it to contain %q", b.String(), want)
+	}
+}
+
+func TestQuotaAndElection(t *testing.T) {
+	checks := []Check{
+		Quota("noop"),
+		Election("noop", func() (election2.Factory, error) { return election2.NoopFactory{}, nil }),
+	}
+	var b strings.Builder
+	if !Run(context.Background(), &b, time.Second, checks) {
+		t.Errorf("Run() = false, want true; report:\n%s", b.String())
+	}
+	if Run(context.Background(), &b, time.Second, []Check{Quota("missing")}) {
+		t.Error("Run(missing quota) = true, want false")
+	}
+}
+
+func TestKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	current := writeKeyPair(t, dir, "current", now.Add(-time.Hour), now.Add(time.Hour))
+	expired := writeKeyPair(t, dir, "expired", now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	for _, test := range []struct {
+		desc    string
+		cert    string
+		key     string
+		wantErr bool
+		want    string
+	}{
+		{desc: "not configured", want: "not configured"},
+		{desc: "current", cert: current + ".crt", key: current + ".key", want: `certificate for "current" expires`},
+		{desc: "expired", cert: expired + ".crt", key: expired + ".key", wantErr: true},
+		{desc: "mismatch", cert: current + ".crt", key: expired + ".key", wantErr: true},
+		{desc: "missing", cert: filepath.Join(dir, "missing.crt"), key: current + ".key", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := KeyPair("tls", test.cert, test.key).Run(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Run() = %q, %v, want error: %v", got, err, test.wantErr)
+			}
+			if !strings.Contains(got, test.want) {
+				t.Errorf("Run() = %q, want it to contain %q", got, test.want)
+			}
+		})
+	}
+}
+
+// writeKeyPair writes a self-signed certificate and its key to dir, and
+// returns the path they share, without the .crt and .key extensions.
+func writeKeyPair(t *testing.T, dir, name string, notBefore, notAfter time.Time) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverutil
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// HTTPAuth holds the credentials accepted by the HTTP server. A request is
+// allowed if it presents any of the credentials which are set.
+type HTTPAuth struct {
+	// BasicUser and BasicPassword are accepted with HTTP basic auth, if
+	// BasicUser is set.
+	BasicUser, BasicPassword string
+	// BearerToken is accepted in an "Authorization: Bearer" header, if set.
+	BearerToken string
+}
+
+// LoadHTTPAuth reads HTTP credentials from files. The basic auth file holds a
+// "user:password" line, and the bearer token file holds the token. Either
+// file name may be empty, and LoadHTTPAuth returns nil if both are.
+func LoadHTTPAuth(basicAuthFile, bearerTokenFile string) (*HTTPAuth, error) {
+	if basicAuthFile == "" && bearerTokenFile == "" {
+		return nil, nil
+	}
+	var a HTTPAuth
+	if basicAuthFile != "" {
+		data, err := os.ReadFile(basicAuthFile)
+		if err != nil {
+			return nil, err
+		}
+		user, password, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+		if !ok || user == "" || password == "" {
+			return nil, fmt.Errorf("%s: want a user:password line", basicAuthFile)
+		}
+		a.BasicUser, a.BasicPassword = user, password
+	}
+	if bearerTokenFile != "" {
+		data, err := os.ReadFile(bearerTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		if a.BearerToken = strings.TrimSpace(string(data)); a.BearerToken == "" {
+			return nil, errors.New(bearerTokenFile + ": empty bearer token")
+		}
+	}
+	return &a, nil
+}
+
+// Handler returns a handler which serves authenticated requests with h, and
+// rejects others with 401 Unauthorized. Requests for /healthz and /readyz are
+// always served, for load balancers and orchestrators probing the server.
+func (a *HTTPAuth) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/healthz" || req.URL.Path == "/readyz" || a.allowed(req) {
+			h.ServeHTTP(rw, req)
+			return
+		}
+		if a.BasicUser != "" {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="trillian"`)
+		} else {
+			rw.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func (a *HTTPAuth) allowed(req *http.Request) bool {
+	if a.BasicUser != "" {
+		if user, password, ok := req.BasicAuth(); ok && equal(user, a.BasicUser) && equal(password, a.BasicPassword) {
+			return true
+		}
+	}
+	if a.BearerToken != "" {
+		if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok && equal(token, a.BearerToken) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares credentials in constant time.
+func equal(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
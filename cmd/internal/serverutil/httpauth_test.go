@@ -0,0 +1,77 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTTPAuth(t *testing.T) {
+	dir := t.TempDir()
+	basicFile := filepath.Join(dir, "basic")
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(basicFile, []byte("prom:secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tokenFile, []byte("tok3n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadHTTPAuth(basicFile, tokenFile)
+	if err != nil {
+		t.Fatalf("LoadHTTPAuth(): %v", err)
+	}
+	h := a.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	for _, test := range []struct {
+		desc   string
+		path   string
+		header func(*http.Request)
+		want   int
+	}{
+		{desc: "no credentials", path: "/metrics", want: http.StatusUnauthorized},
+		{desc: "healthz", path: "/healthz", want: http.StatusOK},
+		{desc: "readyz", path: "/readyz", want: http.StatusOK},
+		{desc: "basic", path: "/metrics", header: func(r *http.Request) { r.SetBasicAuth("prom", "secret") }, want: http.StatusOK},
+		{desc: "wrong password", path: "/metrics", header: func(r *http.Request) { r.SetBasicAuth("prom", "guess") }, want: http.StatusUnauthorized},
+		{desc: "bearer", path: "/metrics", header: func(r *http.Request) { r.Header.Set("Authorization", "Bearer tok3n") }, want: http.StatusOK},
+		{desc: "wrong token", path: "/metrics", header: func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }, want: http.StatusUnauthorized},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.header != nil {
+				test.header(req)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Code; got != test.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", got, test.want)
+			}
+		})
+	}
+
+	if a, err := LoadHTTPAuth("", ""); a != nil || err != nil {
+		t.Errorf("LoadHTTPAuth(none) = %v, %v, want nil, nil", a, err)
+	}
+	if err := os.WriteFile(basicFile, []byte("prom"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHTTPAuth(basicFile, ""); err == nil {
+		t.Error("LoadHTTPAuth(no password): got no error")
+	}
+}
@@ -17,10 +17,13 @@ package serverutil
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/google/trillian"
@@ -34,11 +37,17 @@ import (
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/xds"
 	"k8s.io/klog/v2"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	channelz "google.golang.org/grpc/channelz/service"
+	xdscreds "google.golang.org/grpc/credentials/xds"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
@@ -62,6 +71,25 @@ type Main struct {
 	// TLS Certificate and Key files for the server.
 	TLSCertFile, TLSKeyFile string
 
+	// TLS Certificate and Key files for the HTTP server. If unset, the HTTP
+	// server uses TLSCertFile and TLSKeyFile.
+	HTTPTLSCertFile, HTTPTLSKeyFile string
+	// HTTPAuth, if set, authenticates requests to the HTTP server, so that
+	// metrics and debug pages can be exposed beyond a trusted network.
+	HTTPAuth *HTTPAuth
+
+	// AdminRPCEndpoint, if set, is where the TrillianAdmin service is served
+	// instead of on RPCEndpoint, so that the admin API can be restricted to
+	// an internal network while the RPCEndpoint is public.
+	AdminRPCEndpoint string
+	// TLS Certificate and Key files for the admin server. If unset, the admin
+	// server uses unsecured connections.
+	AdminTLSCertFile, AdminTLSKeyFile string
+	// AdminTLSClientCAFile, if set, is a file of PEM CA certificates, and only
+	// admin clients presenting a certificate signed by one of them are
+	// accepted. It requires AdminTLSCertFile and AdminTLSKeyFile.
+	AdminTLSClientCAFile string
+
 	DBClose func() error
 
 	Registry extension.Registry
@@ -70,15 +98,26 @@ type Main struct {
 	QuotaDryRun bool
 
 	// RegisterServerFn is called to register RPC servers.
-	RegisterServerFn func(*grpc.Server, extension.Registry) error
+	RegisterServerFn func(grpc.ServiceRegistrar, extension.Registry) error
 
-	// IsHealthy will be called whenever "/healthz" is called on the mux.
-	// A nil return value from this function will result in a 200-OK response
-	// on the /healthz endpoint.
+	// IsHealthy will be called whenever "/healthz" is called on the mux, and
+	// every HealthCheckInterval to set the status reported by the gRPC health
+	// service. A nil return value from this function will result in a 200-OK
+	// response on the /healthz endpoint, and a SERVING status.
 	IsHealthy func(context.Context) error
 	// HealthyDeadline is the maximum duration to wait wait for a successful
 	// IsHealthy() call.
 	HealthyDeadline time.Duration
+	// HealthCheckInterval is how often the gRPC health service status is
+	// updated. Defaults to 5 seconds.
+	HealthCheckInterval time.Duration
+	// Warmup, if set, is called when the server starts, and retried every
+	// HealthCheckInterval until it succeeds, e.g. to open the database
+	// connections before the first requests arrive. Until then, the "/readyz"
+	// endpoint fails and the gRPC health service reports NOT_SERVING, so that
+	// load balancers don't send requests to the server. The "/healthz"
+	// endpoint is not affected.
+	Warmup func(context.Context) error
 
 	// AllowedTreeTypes determines which types of trees may be created through the Admin Server
 	// bound by Main. nil means unrestricted.
@@ -88,27 +127,182 @@ type Main struct {
 	TreeDeleteThreshold   time.Duration
 	TreeDeleteMinInterval time.Duration
 
+	// AdminOperations configures the long-running operations of the Admin
+	// Server bound by Main.
+	AdminOperations admin.OperationsConfig
+
 	// These will be added to the GRPC server options.
 	ExtraOptions []grpc.ServerOption
+
+	// TimeSource is used to time requests. Defaults to clock.System.
+	TimeSource clock.TimeSource
+
+	// DefaultRPCDeadline is applied to requests which arrive without a
+	// deadline, and MaxRPCDeadline caps the deadlines of all requests. Zero
+	// values are ignored. Streaming RPCs are exempt.
+	DefaultRPCDeadline, MaxRPCDeadline time.Duration
+
+	// UnaryInterceptors are run after the stats and error wrapping
+	// interceptors, but before the Trillian interceptor checks tree access
+	// and charges quota. Interceptors which answer requests without calling
+	// the handler must check tree access themselves.
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	// StreamInterceptors are the counterparts of UnaryInterceptors for
+	// streaming RPCs, and run at the same point.
+	StreamInterceptors []grpc.StreamServerInterceptor
+
+	// RecoverPanics turns panics of RPC handlers into Internal errors rather
+	// than crashing the server, unless MaxPanics is positive and that many
+	// have happened.
+	RecoverPanics bool
+	MaxPanics     int
+
+	// AccountBandwidth counts the bytes of the requests and responses of
+	// each tree, for chargeback in multi-tenant deployments.
+	AccountBandwidth bool
+
+	// Channelz registers the gRPC channelz service on the RPC server, which
+	// gives live visibility into its channels, sockets and streams.
+	Channelz bool
+
+	// XDS serves the RPC endpoint with an xDS-enabled gRPC server, which
+	// gets its listener and security configuration from the xDS control
+	// plane named in the GRPC_XDS_BOOTSTRAP file, so that it can run behind
+	// xDS-aware proxies. The TLS settings above are used as a fallback when
+	// the control plane provides no security configuration. The admin server
+	// is not affected.
+	XDS bool
+
+	// warm is closed once Warmup has succeeded. It is nil if there is no
+	// warmup to wait for.
+	warm chan struct{}
 }
 
-func (m *Main) healthz(rw http.ResponseWriter, req *http.Request) {
-	if m.IsHealthy != nil {
-		ctx, cancel := context.WithTimeout(req.Context(), m.HealthyDeadline)
-		defer cancel()
-		if err := m.IsHealthy(ctx); err != nil {
-			rw.WriteHeader(http.StatusServiceUnavailable)
-			if _, err := rw.Write([]byte(err.Error())); err != nil {
-				klog.Errorf("Write(): %v", err)
-			}
+// grpcServer is the part of *grpc.Server, and of the xDS-enabled
+// *xds.GRPCServer, that Main uses.
+type grpcServer interface {
+	reflection.GRPCServer
+	Serve(net.Listener) error
+	GracefulStop()
+}
+
+// checkHealth calls IsHealthy, if set, bounded by HealthyDeadline.
+func (m *Main) checkHealth(ctx context.Context) error {
+	if m.IsHealthy == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.HealthyDeadline)
+	defer cancel()
+	return m.IsHealthy(ctx)
+}
+
+// checkReady returns an error if the server is still warming up, or if it is
+// not healthy.
+func (m *Main) checkReady(ctx context.Context) error {
+	if m.warm != nil {
+		select {
+		case <-m.warm:
+		default:
+			return errors.New("warming up")
+		}
+	}
+	return m.checkHealth(ctx)
+}
+
+// warmup calls Warmup until it succeeds, or ctx is done, and then marks the
+// server as warmed up.
+func (m *Main) warmup(ctx context.Context) {
+	ticker := time.NewTicker(m.HealthCheckInterval)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		err := m.Warmup(ctx)
+		if err == nil {
+			break
+		}
+		klog.Warningf("Warmup failed: %v", err)
+		select {
+		case <-ctx.Done():
 			return
+		case <-ticker.C:
+		}
+	}
+	klog.Infof("Warmup completed in %v", time.Since(start))
+	close(m.warm)
+}
+
+func (m *Main) healthz(rw http.ResponseWriter, req *http.Request) {
+	writeCheck(rw, m.checkHealth(req.Context()))
+}
+
+func (m *Main) readyz(rw http.ResponseWriter, req *http.Request) {
+	writeCheck(rw, m.checkReady(req.Context()))
+}
+
+// writeCheck writes the response of a health or readiness check which
+// returned err.
+func writeCheck(rw http.ResponseWriter, err error) {
+	if err != nil {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		if _, err := rw.Write([]byte(err.Error())); err != nil {
+			klog.Errorf("Write(): %v", err)
 		}
+		return
 	}
 	if _, err := rw.Write([]byte("ok")); err != nil {
 		klog.Errorf("Write(): %v", err)
 	}
 }
 
+// updateHealth sets the status of the given services in hs according to
+// whether the server is warmed up and the result of IsHealthy, and returns it.
+func (m *Main) updateHealth(ctx context.Context, hs *health.Server, services []string) healthpb.HealthCheckResponse_ServingStatus {
+	st := healthpb.HealthCheckResponse_SERVING
+	if err := m.checkReady(ctx); err != nil {
+		klog.Warningf("Health check failed: %v", err)
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	for _, s := range services {
+		hs.SetServingStatus(s, st)
+	}
+	return st
+}
+
+// reportHealth updates the status of the given services in hs every
+// HealthCheckInterval, and as soon as the server is warmed up, until ctx is
+// done.
+func (m *Main) reportHealth(ctx context.Context, hs *health.Server, services []string) {
+	ticker := time.NewTicker(m.HealthCheckInterval)
+	defer ticker.Stop()
+	warm := m.warm
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		if st := m.updateHealth(ctx, hs, services); st != last {
+			klog.Infof("gRPC health status is now %v", st)
+			last = st
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-warm:
+			warm = nil
+		}
+	}
+}
+
+// serviceNames returns the names of the services registered on the given
+// servers, along with the empty name which stands for the server as a whole.
+func serviceNames(srvs ...grpcServer) []string {
+	names := []string{""}
+	for _, srv := range srvs {
+		for name := range srv.GetServiceInfo() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // Run starts the configured server. Blocks until the server exits.
 func (m *Main) Run(ctx context.Context) error {
 	klog.CopyStandardLogTo("WARNING")
@@ -116,12 +310,18 @@ func (m *Main) Run(ctx context.Context) error {
 	if m.HealthyDeadline == 0 {
 		m.HealthyDeadline = 5 * time.Second
 	}
+	if m.HealthCheckInterval == 0 {
+		m.HealthCheckInterval = 5 * time.Second
+	}
 
-	srv, err := m.newGRPCServer()
+	srv, adminSrv, err := m.newGRPCServers()
 	if err != nil {
 		klog.Exitf("Error creating gRPC server: %v", err)
 	}
 	defer srv.GracefulStop()
+	if adminSrv != nil {
+		defer adminSrv.GracefulStop()
+	}
 
 	defer func() {
 		if err := m.DBClose(); err != nil {
@@ -132,26 +332,66 @@ func (m *Main) Run(ctx context.Context) error {
 	if err := m.RegisterServerFn(srv, m.Registry); err != nil {
 		return err
 	}
-	trillian.RegisterTrillianAdminServer(srv, admin.New(m.Registry, m.AllowedTreeTypes))
+	adminServer := admin.NewWithOperations(m.Registry, m.AllowedTreeTypes, m.AdminOperations)
+	if adminSrv != nil {
+		trillian.RegisterTrillianAdminServer(adminSrv, adminServer)
+		reflection.Register(adminSrv)
+	} else {
+		trillian.RegisterTrillianAdminServer(srv, adminServer)
+	}
 	reflection.Register(srv)
+	if m.Channelz {
+		channelz.RegisterChannelzServiceToServer(srv)
+	}
+
+	// The health service starts out NOT_SERVING, until the first check.
+	hs := health.NewServer()
+	services := serviceNames(srv)
+	if adminSrv != nil {
+		services = append(services, serviceNames(adminSrv)...)
+		healthpb.RegisterHealthServer(adminSrv, hs)
+	}
+	healthpb.RegisterHealthServer(srv, hs)
+	for _, s := range services {
+		hs.SetServingStatus(s, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
 
 	g, ctx := errgroup.WithContext(ctx)
+	if m.Warmup != nil {
+		m.warm = make(chan struct{})
+		g.Go(func() error {
+			m.warmup(ctx)
+			return nil
+		})
+	}
+	g.Go(func() error {
+		m.reportHealth(ctx, hs, services)
+		return nil
+	})
 
 	if endpoint := m.HTTPEndpoint; endpoint != "" {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/healthz", m.healthz)
+		http.HandleFunc("/readyz", m.readyz)
 
 		s := &http.Server{
 			Addr: endpoint,
 		}
+		if m.HTTPAuth != nil {
+			s.Handler = m.HTTPAuth.Handler(http.DefaultServeMux)
+		}
+		certFile, keyFile := m.TLSCertFile, m.TLSKeyFile
+		if m.HTTPTLSCertFile != "" || m.HTTPTLSKeyFile != "" {
+			certFile, keyFile = m.HTTPTLSCertFile, m.HTTPTLSKeyFile
+		}
 
 		run := func() error {
 			klog.Infof("HTTP server starting on %v", endpoint)
 
 			var err error
 			// Let http.ListenAndServeTLS handle the error case when only one of the flags is set.
-			if m.TLSCertFile != "" || m.TLSKeyFile != "" {
-				err = s.ListenAndServeTLS(m.TLSCertFile, m.TLSKeyFile)
+			if certFile != "" || keyFile != "" {
+				err = s.ListenAndServeTLS(certFile, keyFile)
 			} else {
 				err = s.ListenAndServe()
 			}
@@ -216,6 +456,8 @@ func (m *Main) Run(ctx context.Context) error {
 		klog.Infof("Stopping RPC server...")
 		klog.Flush()
 
+		// Tell health checking clients and proxies to go elsewhere first.
+		hs.Shutdown()
 		srv.GracefulStop()
 	}
 
@@ -223,6 +465,30 @@ func (m *Main) Run(ctx context.Context) error {
 		return srvRun(ctx, run, shutdown)
 	})
 
+	if adminSrv != nil {
+		klog.Infof("Admin RPC server starting on %v", m.AdminRPCEndpoint)
+		adminLis, err := net.Listen("tcp", m.AdminRPCEndpoint)
+		if err != nil {
+			return err
+		}
+		run := func() error {
+			if err := adminSrv.Serve(adminLis); err != nil {
+				return fmt.Errorf("admin RPC server terminated: %v", err)
+			}
+			return nil
+		}
+		shutdown := func() {
+			klog.Infof("Stopping admin RPC server...")
+			klog.Flush()
+
+			hs.Shutdown()
+			adminSrv.GracefulStop()
+		}
+		g.Go(func() error {
+			return srvRun(ctx, run, shutdown)
+		})
+	}
+
 	// wait for all jobs to exit gracefully
 	err = g.Wait()
 
@@ -232,32 +498,111 @@ func (m *Main) Run(ctx context.Context) error {
 	return err
 }
 
-// newGRPCServer starts a new Trillian gRPC server.
-func (m *Main) newGRPCServer() (*grpc.Server, error) {
-	stats := monitoring.NewRPCStatsInterceptor(clock.System, m.StatsPrefix, m.Registry.MetricFactory)
+// newGRPCServers creates the Trillian gRPC server, and the admin gRPC server
+// if AdminRPCEndpoint is set. Both run the same interceptors.
+func (m *Main) newGRPCServers() (grpcServer, grpcServer, error) {
+	ts := m.TimeSource
+	if ts == nil {
+		ts = clock.System
+	}
+	stats := monitoring.NewRPCStatsInterceptor(ts, m.StatsPrefix, m.Registry.MetricFactory)
 	ti := interceptor.New(m.Registry.AdminStorage, m.Registry.QuotaManager, m.QuotaDryRun, m.Registry.MetricFactory)
 
+	interceptors := []grpc.UnaryServerInterceptor{stats.Interceptor()}
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if m.AccountBandwidth {
+		interceptors = append(interceptors, interceptor.Bandwidth(m.Registry.MetricFactory))
+	}
+	if m.RecoverPanics {
+		recovery := interceptor.NewRecovery(m.MaxPanics, m.Registry.MetricFactory)
+		interceptors = append(interceptors, recovery.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, recovery.StreamInterceptor)
+	}
+	interceptors = append(interceptors, interceptor.ErrorWrapper)
+	if m.DefaultRPCDeadline > 0 || m.MaxRPCDeadline > 0 {
+		interceptors = append(interceptors, interceptor.Deadline(m.DefaultRPCDeadline, m.MaxRPCDeadline))
+	}
+	interceptors = append(interceptors, m.UnaryInterceptors...)
+	interceptors = append(interceptors, ti.UnaryInterceptor)
+	streamInterceptors = append(streamInterceptors, m.StreamInterceptors...)
+	streamInterceptors = append(streamInterceptors, ti.StreamInterceptor)
+
 	serverOpts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
-			stats.Interceptor(),
-			interceptor.ErrorWrapper,
-			ti.UnaryInterceptor,
-		)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(interceptors...)),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	}
 	serverOpts = append(serverOpts, m.ExtraOptions...)
 
-	// Let credentials.NewServerTLSFromFile handle the error case when only one of the flags is set.
-	if m.TLSCertFile != "" || m.TLSKeyFile != "" {
-		serverCreds, err := credentials.NewServerTLSFromFile(m.TLSCertFile, m.TLSKeyFile)
+	srv, err := newGRPCServer(serverOpts, m.TLSCertFile, m.TLSKeyFile, "", m.XDS)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.AdminRPCEndpoint == "" {
+		return srv, nil, nil
+	}
+	adminSrv, err := newGRPCServer(serverOpts, m.AdminTLSCertFile, m.AdminTLSKeyFile, m.AdminTLSClientCAFile, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("admin server: %v", err)
+	}
+	return srv, adminSrv, nil
+}
+
+// newGRPCServer creates a gRPC server with the given options, serving TLS
+// with the given certificate and key if either is set, and requiring client
+// certificates signed by the CAs in clientCAFile if it is set. If useXDS is
+// set, the server is configured by xDS, and the TLS settings are only used as
+// a fallback.
+func newGRPCServer(opts []grpc.ServerOption, certFile, keyFile, clientCAFile string, useXDS bool) (grpcServer, error) {
+	creds, err := serverCreds(certFile, keyFile, clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	if useXDS {
+		if creds == nil {
+			creds = insecure.NewCredentials()
+		}
+		xdsCreds, err := xdscreds.NewServerCredentials(xdscreds.ServerOptions{FallbackCreds: creds})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xDS credentials: %v", err)
+		}
+		return xds.NewGRPCServer(append(opts, grpc.Creds(xdsCreds))...)
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	return grpc.NewServer(opts...), nil
+}
+
+// serverCreds returns the transport credentials for the given TLS settings,
+// as described for newGRPCServer, or nil if TLS is not configured.
+func serverCreds(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	switch {
+	case clientCAFile != "":
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("client certificate verification requires a TLS certificate and key")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
 			return nil, err
 		}
-		serverOpts = append(serverOpts, grpc.Creds(serverCreds))
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates in %s", clientCAFile)
+		}
+		return credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientCAs:    clientCAs,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		}), nil
+	case certFile != "" || keyFile != "":
+		// Let credentials.NewServerTLSFromFile handle the error case when only one of the flags is set.
+		return credentials.NewServerTLSFromFile(certFile, keyFile)
 	}
-
-	s := grpc.NewServer(serverOpts...)
-
-	return s, nil
+	return nil, nil
 }
 
 // AnnounceSelf announces this binary's presence to etcd. This calls the cancel
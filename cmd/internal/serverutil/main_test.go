@@ -0,0 +1,114 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestUpdateHealth(t *testing.T) {
+	ctx := context.Background()
+	var healthErr error
+	m := &Main{
+		IsHealthy:       func(context.Context) error { return healthErr },
+		HealthyDeadline: time.Second,
+	}
+	hs := health.NewServer()
+	services := []string{"", "trillian.TrillianLog"}
+
+	for _, test := range []struct {
+		err  error
+		want healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{want: healthpb.HealthCheckResponse_SERVING},
+		{err: errors.New("no database"), want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{want: healthpb.HealthCheckResponse_SERVING},
+	} {
+		healthErr = test.err
+		if got := m.updateHealth(ctx, hs, services); got != test.want {
+			t.Errorf("updateHealth() with error %v = %v, want %v", test.err, got, test.want)
+		}
+		for _, s := range services {
+			rsp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: s})
+			if err != nil {
+				t.Fatalf("Check(%q): %v", s, err)
+			}
+			if rsp.Status != test.want {
+				t.Errorf("Check(%q) = %v, want %v", s, rsp.Status, test.want)
+			}
+		}
+	}
+
+	// Once shut down, the status stays NOT_SERVING.
+	hs.Shutdown()
+	healthErr = nil
+	m.updateHealth(ctx, hs, services)
+	if rsp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil || rsp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Check() after Shutdown() = %v, %v, want NOT_SERVING", rsp, err)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	ctx := context.Background()
+	attempts := 0
+	m := &Main{
+		IsHealthy:       func(context.Context) error { return nil },
+		HealthyDeadline: time.Second,
+		Warmup: func(context.Context) error {
+			if attempts++; attempts < 3 {
+				return errors.New("no connection")
+			}
+			return nil
+		},
+		HealthCheckInterval: time.Millisecond,
+		warm:                make(chan struct{}),
+	}
+	hs := health.NewServer()
+	services := []string{""}
+
+	check := func(h http.HandlerFunc, want int) {
+		t.Helper()
+		rw := httptest.NewRecorder()
+		h(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rw.Code != want {
+			t.Errorf("status = %d, want %d", rw.Code, want)
+		}
+	}
+
+	// Not ready, but alive, until warmed up.
+	if got, want := m.updateHealth(ctx, hs, services), healthpb.HealthCheckResponse_NOT_SERVING; got != want {
+		t.Errorf("updateHealth() before warmup = %v, want %v", got, want)
+	}
+	check(m.readyz, http.StatusServiceUnavailable)
+	check(m.healthz, http.StatusOK)
+
+	m.warmup(ctx)
+	if attempts != 3 {
+		t.Errorf("Warmup called %d times, want 3", attempts)
+	}
+	if got, want := m.updateHealth(ctx, hs, services), healthpb.HealthCheckResponse_SERVING; got != want {
+		t.Errorf("updateHealth() after warmup = %v, want %v", got, want)
+	}
+	check(m.readyz, http.StatusOK)
+}
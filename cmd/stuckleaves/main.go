@@ -0,0 +1,108 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains the implementation and entry point for the
+// stuckleaves command, which inspects the queue of unsequenced leaves of a log
+// and requeues or discards those stuck in it for longer than a threshold, e.g.
+// because of a historic bug or a misconfigured guard window.
+//
+// Requeued leaves are moved to the back of the queue. Discarded leaves are
+// removed from the queue along with their deduplication data, so that they
+// can be submitted again. The command works directly on the storage, bypassing
+// wrappers such as --storage_append_only_guard.
+//
+// Example usage:
+// $ ./stuckleaves --storage_system=mysql --mysql_uri=... --log_id=logid --threshold=24h --action=requeue --dry_run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+	"k8s.io/klog/v2"
+
+	// Register supported storage providers.
+	"github.com/google/trillian/cmd/internal/provider"
+)
+
+var (
+	storageSystem = flag.String("storage_system", provider.DefaultStorageSystem, fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	logID         = flag.Int64("log_id", 0, "Trillian LogID whose queue is inspected")
+	threshold     = flag.Duration("threshold", 0, "Leaves queued for longer than this are considered stuck")
+	limit         = flag.Int("limit", 1000, "Max number of stuck leaves to act on")
+	action        = flag.String("action", "list", "What to do with the stuck leaves. One of: list, requeue, discard")
+	dryRun        = flag.Bool("dry_run", false, "If true, only list the stuck leaves, whatever the action")
+	configFile    = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			klog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+
+	var act log.StuckLeafAction
+	switch *action {
+	case "list":
+		act = log.ListStuckLeaves
+	case "requeue":
+		act = log.RequeueStuckLeaves
+	case "discard":
+		act = log.DiscardStuckLeaves
+	default:
+		klog.Exitf("Unknown --action %q", *action)
+	}
+	if *dryRun {
+		act = log.ListStuckLeaves
+	}
+	if *threshold <= 0 {
+		klog.Exit("--threshold must be positive")
+	}
+
+	ctx := context.Background()
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		klog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer func() {
+		if err := sp.Close(); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	tree, err := storage.GetTree(ctx, sp.AdminStorage(), *logID)
+	if err != nil {
+		klog.Exitf("Failed to get tree %d: %v", *logID, err)
+	}
+	before := clock.System.Now().Add(-*threshold)
+	leaves, err := log.RepairStuckLeaves(ctx, tree, before, *limit, act, clock.System, sp.LogStorage())
+	if err != nil {
+		klog.Exitf("Failed to %s stuck leaves: %v", act, err)
+	}
+	for _, leaf := range leaves {
+		fmt.Printf("%x\t%x\t%v\n", leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.QueueTimestamp.AsTime())
+	}
+	klog.Infof("Applied %s to %d leaves of log %d queued before %v", act, len(leaves), *logID, before)
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/mq"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+)
+
+// exporterMetrics holds the metrics exported by the exporter.
+type exporterMetrics struct {
+	leaves   monitoring.Counter
+	lag      monitoring.Gauge
+	failures monitoring.Counter
+}
+
+func newExporterMetrics(mf monitoring.MetricFactory) *exporterMetrics {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &exporterMetrics{
+		leaves:   mf.NewCounter("exported_leaves", "Number of leaves published", "logid"),
+		lag:      mf.NewGauge("export_lag", "Number of leaves integrated into the log but not yet published, as of the latest pass", "logid"),
+		failures: mf.NewCounter("export_failures", "Number of export passes which failed", "logid"),
+	}
+}
+
+// cursor persists the index of the next leaf to be published.
+type cursor interface {
+	Load() (int64, error)
+	Store(next int64) error
+}
+
+// fileCursor is a cursor stored in the named file.
+type fileCursor string
+
+// Load implements cursor. It returns 0 if the file doesn't exist yet.
+func (c fileCursor) Load() (int64, error) {
+	data, err := os.ReadFile(string(c))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Store implements cursor. The file is replaced atomically, so it is never
+// left partly written.
+func (c fileCursor) Store(next int64) error {
+	f, err := os.CreateTemp(filepath.Dir(string(c)), filepath.Base(string(c))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := fmt.Fprintln(f, next); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), string(c))
+}
+
+// memCursor is a cursor which is not persisted.
+type memCursor int64
+
+func (c *memCursor) Load() (int64, error) { return int64(*c), nil }
+
+func (c *memCursor) Store(next int64) error {
+	*c = memCursor(next)
+	return nil
+}
+
+// exporter publishes the leaves of a log to a message queue, in index order,
+// once they are covered by the latest published root.
+//
+// Each leaf is published as a message whose data is the JSON encoding of its
+// trillian.LogLeaf, and whose key is "<log ID>/<leaf index>". The cursor is
+// stored after each batch is published, so a leaf is only published again if
+// the exporter stops between the two; consumers can drop such duplicates by
+// their keys.
+type exporter struct {
+	logID          int64
+	label          string
+	client         trillian.TrillianLogClient
+	pub            mq.Publisher
+	cursor         cursor
+	batchSize      int
+	includePayload bool
+	metrics        *exporterMetrics
+}
+
+func newExporter(logID int64, c trillian.TrillianLogClient, pub mq.Publisher, cur cursor, batchSize int, includePayload bool, metrics *exporterMetrics) *exporter {
+	return &exporter{
+		logID:          logID,
+		label:          strconv.FormatInt(logID, 10),
+		client:         c,
+		pub:            pub,
+		cursor:         cur,
+		batchSize:      batchSize,
+		includePayload: includePayload,
+		metrics:        metrics,
+	}
+}
+
+// run exports the new leaves every interval, until ctx is done.
+func (e *exporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := e.exportOnce(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			e.metrics.failures.Inc(e.label)
+			klog.Warningf("%d: export failed: %v", e.logID, err)
+		} else if n > 0 {
+			klog.V(1).Infof("%d: exported %d leaves", e.logID, n)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// exportOnce publishes the leaves from the cursor up to the size of the
+// latest root, and returns how many it published.
+func (e *exporter) exportOnce(ctx context.Context) (int, error) {
+	next, err := e.cursor.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load cursor: %v", err)
+	}
+	rsp, err := e.client.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: e.logID})
+	if err != nil {
+		return 0, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(rsp.GetSignedLogRoot().GetLogRoot()); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal root: %v", err)
+	}
+	size := int64(root.TreeSize)
+	e.metrics.lag.Set(float64(max(size-next, 0)), e.label)
+
+	exported := 0
+	for next < size {
+		lrsp, err := e.client.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{
+			LogId:      e.logID,
+			StartIndex: next,
+			Count:      min(int64(e.batchSize), size-next),
+		})
+		if err != nil {
+			return exported, err
+		}
+		if len(lrsp.Leaves) == 0 {
+			return exported, fmt.Errorf("no leaves returned from index %d", next)
+		}
+		msgs := make([]*mq.Message, 0, len(lrsp.Leaves))
+		for i, leaf := range lrsp.Leaves {
+			if got, want := leaf.LeafIndex, next+int64(i); got != want {
+				return exported, fmt.Errorf("got leaf index %d, want %d", got, want)
+			}
+			msg, err := e.message(leaf)
+			if err != nil {
+				return exported, err
+			}
+			msgs = append(msgs, msg)
+		}
+		if err := e.pub.Publish(ctx, msgs); err != nil {
+			return exported, err
+		}
+		next += int64(len(msgs))
+		if err := e.cursor.Store(next); err != nil {
+			return exported, fmt.Errorf("failed to store cursor: %v", err)
+		}
+		exported += len(msgs)
+		e.metrics.leaves.Add(float64(len(msgs)), e.label)
+		e.metrics.lag.Set(float64(size-next), e.label)
+	}
+	return exported, nil
+}
+
+// message returns the message published for leaf.
+func (e *exporter) message(leaf *trillian.LogLeaf) (*mq.Message, error) {
+	if !e.includePayload {
+		leaf = proto.Clone(leaf).(*trillian.LogLeaf)
+		leaf.LeafValue, leaf.ExtraData = nil, nil
+	}
+	data, err := protojson.Marshal(leaf)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("%d/%d", e.logID, leaf.LeafIndex)
+	return &mq.Message{ID: id, Key: []byte(id), Data: data}, nil
+}
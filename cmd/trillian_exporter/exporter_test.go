@@ -0,0 +1,124 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/trilliantest"
+	"github.com/google/trillian/util/mq"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fakePublisher records the messages it publishes, and fails with err if set.
+type fakePublisher struct {
+	msgs []*mq.Message
+	err  error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msgs []*mq.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+func TestExportOnce(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := trilliantest.NewLogEnv(ctx, trilliantest.Config{ManualSequencing: true})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+	tree, err := env.CreateLog(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+	queue := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := env.Log.QueueLeaf(ctx, &trillian.QueueLeafRequest{
+				LogId: tree.TreeId,
+				Leaf:  &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d %d", n, i))},
+			}); err != nil {
+				t.Fatalf("QueueLeaf(): %v", err)
+			}
+		}
+	}
+
+	pub := &fakePublisher{}
+	cur := fileCursor(filepath.Join(t.TempDir(), "cursor"))
+	metrics := newExporterMetrics(monitoring.InertMetricFactory{})
+	e := newExporter(tree.TreeId, env.Log, pub, cur, 2, false, metrics)
+
+	// Queued leaves aren't exported until they are integrated.
+	queue(5)
+	for _, want := range []int{0, 5} {
+		if got, err := e.exportOnce(ctx); err != nil || got != want {
+			t.Fatalf("exportOnce() = %d, %v, want %d", got, err, want)
+		}
+		env.Sequence(ctx)
+	}
+	// Only the leaves after the cursor are exported.
+	queue(2)
+	env.Sequence(ctx)
+	if got, err := e.exportOnce(ctx); err != nil || got != 2 {
+		t.Fatalf("exportOnce() = %d, %v, want 2", got, err)
+	}
+	if next, err := cur.Load(); err != nil || next != 7 {
+		t.Errorf("cursor = %d, %v, want 7", next, err)
+	}
+
+	if got, want := len(pub.msgs), 7; got != want {
+		t.Fatalf("published %d messages, want %d", got, want)
+	}
+	for i, m := range pub.msgs {
+		if got, want := string(m.Key), fmt.Sprintf("%d/%d", tree.TreeId, i); got != want {
+			t.Errorf("message %d key = %q, want %q", i, got, want)
+		}
+		var leaf trillian.LogLeaf
+		if err := protojson.Unmarshal(m.Data, &leaf); err != nil {
+			t.Fatalf("message %d: Unmarshal(): %v", i, err)
+		}
+		if leaf.LeafIndex != int64(i) || len(leaf.MerkleLeafHash) == 0 || leaf.LeafValue != nil {
+			t.Errorf("message %d = %v, want leaf %d with hashes but no payload", i, &leaf, i)
+		}
+	}
+	if got := metrics.lag.(*monitoring.InertFloat).Value(e.label); got != 0 {
+		t.Errorf("export lag = %v, want 0", got)
+	}
+
+	// A failed publish leaves the cursor where it was.
+	queue(1)
+	env.Sequence(ctx)
+	pub.err = errors.New("unavailable")
+	if _, err := e.exportOnce(ctx); err == nil {
+		t.Error("exportOnce() succeeded with a failing publisher, want error")
+	}
+	if next, _ := cur.Load(); next != 7 {
+		t.Errorf("cursor after failure = %d, want 7", next)
+	}
+}
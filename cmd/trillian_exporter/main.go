@@ -0,0 +1,126 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_exporter binary publishes each leaf integrated into a Trillian
+// log to a Google Cloud Pub/Sub topic or a Kafka topic, once it is covered by
+// a published root, giving downstream indexers a feed of the log's leaves.
+// Leaves are published in index order, each exactly once unless the exporter
+// stops between publishing a batch and storing its cursor.
+//
+// Example usage:
+// $ ./trillian_exporter --log_server=host:port --log_id=1234 --cursor_file=/var/lib/exporter/1234 --pubsub_topic=projects/p/topics/t
+// $ ./trillian_exporter --log_server=host:port --log_id=1234 --cursor_file=/var/lib/exporter/1234 --kafka_rest_proxy=http://host:8082 --kafka_topic=leaves
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/mq"
+	"github.com/google/trillian/util/mq/kafka"
+	"github.com/google/trillian/util/mq/pubsub"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logServerAddr  = flag.String("log_server", "", "Address of the gRPC Trillian Log Server (host:port)")
+	logID          = flag.Int64("log_id", 0, "Trillian LogID to export the leaves of")
+	httpEndpoint   = flag.String("http_endpoint", "localhost:8095", "Endpoint for HTTP metrics (host:port)")
+	pollInterval   = flag.Duration("poll_interval", time.Second, "Time between checks for newly integrated leaves")
+	batchSize      = flag.Int("batch_size", 1000, "Max number of leaves to publish at a time")
+	includePayload = flag.Bool("include_payload", false, "If true, the leaf_value and extra_data of each leaf are published, as well as its index and hashes")
+	cursorFile     = flag.String("cursor_file", "", "File storing the index of the next leaf to publish, so that leaves aren't published again after a restart. If unset, the export starts from the first leaf")
+
+	pubsubTopic        = flag.String("pubsub_topic", "", "Pub/Sub topic to publish to, as projects/<project>/topics/<topic>")
+	pubsubKeyAttribute = flag.String("pubsub_key_attribute", "trillian_leaf", "Attribute of Pub/Sub messages set to <log ID>/<leaf index>")
+
+	kafkaRESTProxy = flag.String("kafka_rest_proxy", "", "URL of the Kafka REST Proxy to publish through")
+	kafkaTopic     = flag.String("kafka_topic", "", "Kafka topic to publish to; the key of each record is <log ID>/<leaf index>")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go util.AwaitSignal(ctx, cancel)
+
+	pub, err := newPublisher(ctx)
+	if err != nil {
+		klog.Exitf("Failed to create publisher: %v", err)
+	}
+	var cur cursor = new(memCursor)
+	if *cursorFile != "" {
+		cur = fileCursor(*cursorFile)
+	} else {
+		klog.Warning("No --cursor_file, leaves will be published again after a restart")
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		klog.Exitf("Failed to determine dial options: %v", err)
+	}
+	conn, err := grpc.NewClient(*logServerAddr, dialOpts...)
+	if err != nil {
+		klog.Exitf("Failed to dial %v: %v", *logServerAddr, err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	http.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: *httpEndpoint}
+	go func() {
+		klog.Infof("HTTP server starting on %v", *httpEndpoint)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Exitf("HTTP server failed: %v", err)
+		}
+	}()
+
+	metrics := newExporterMetrics(prometheus.MetricFactory{})
+	newExporter(*logID, trillian.NewTrillianLogClient(conn), pub, cur, *batchSize, *includePayload, metrics).run(ctx, *pollInterval)
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		klog.Errorf("HTTP server shutdown: %v", err)
+	}
+}
+
+// newPublisher returns the publisher to the message queue set by the flags.
+func newPublisher(ctx context.Context) (mq.Publisher, error) {
+	switch {
+	case *pubsubTopic != "" && *kafkaTopic != "":
+		return nil, errors.New("only one of --pubsub_topic and --kafka_topic may be set")
+	case *pubsubTopic != "":
+		return pubsub.NewPublisher(ctx, *pubsubTopic, *pubsubKeyAttribute)
+	case *kafkaTopic != "":
+		if *kafkaRESTProxy == "" {
+			return nil, errors.New("--kafka_rest_proxy must be set with --kafka_topic")
+		}
+		return kafka.NewPublisher(nil, *kafkaRESTProxy, *kafkaTopic), nil
+	}
+	return nil, errors.New("one of --pubsub_topic and --kafka_topic must be set")
+}
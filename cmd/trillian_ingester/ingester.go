@@ -0,0 +1,152 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"strconv"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/mq"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// Results of ingesting a message, used to label the messages metric.
+const (
+	resultQueued    = "queued"
+	resultDuplicate = "duplicate"
+	resultRejected  = "rejected"
+)
+
+// ingesterMetrics holds the metrics exported by the ingester.
+type ingesterMetrics struct {
+	messages monitoring.Counter
+	failures monitoring.Counter
+}
+
+func newIngesterMetrics(mf monitoring.MetricFactory) *ingesterMetrics {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &ingesterMetrics{
+		messages: mf.NewCounter("ingested_messages", "Number of messages ingested, by result", "logid", "result"),
+		failures: mf.NewCounter("ingest_failures", "Number of batches of messages which failed to be ingested, and will be delivered again", "logid"),
+	}
+}
+
+// ingester queues the leaves consumed from a message queue into a log.
+//
+// Messages are only acknowledged once their leaves are queued, so each one is
+// queued at least once. Redelivered messages are deduplicated by the log: the
+// identity hash of each leaf is the hash of the key of its message if it has
+// one, or else the hash of the leaf, as usual.
+type ingester struct {
+	logID       int64
+	label       string
+	client      trillian.TrillianLogClient
+	sub         mq.Subscriber
+	batchSize   int
+	concurrency int
+	metrics     *ingesterMetrics
+}
+
+func newIngester(logID int64, c trillian.TrillianLogClient, sub mq.Subscriber, batchSize, concurrency int, metrics *ingesterMetrics) *ingester {
+	return &ingester{
+		logID:       logID,
+		label:       strconv.FormatInt(logID, 10),
+		client:      c,
+		sub:         sub,
+		batchSize:   batchSize,
+		concurrency: concurrency,
+		metrics:     metrics,
+	}
+}
+
+// run ingests batches of messages until ctx is done.
+func (i *ingester) run(ctx context.Context) {
+	bo := backoff.Backoff{Min: time.Second, Max: time.Minute, Factor: 2, Jitter: true}
+	for ctx.Err() == nil {
+		if err := i.ingestBatch(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			i.metrics.failures.Inc(i.label)
+			klog.Warningf("%d: failed to ingest batch: %v", i.logID, err)
+			select {
+			case <-time.After(bo.Duration()):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		bo.Reset()
+	}
+}
+
+// ingestBatch receives a batch of messages, queues their leaves, and
+// acknowledges them once they are all queued. If any of them can't be
+// queued, none are acknowledged, and the whole batch is delivered again.
+func (i *ingester) ingestBatch(ctx context.Context) error {
+	msgs, err := i.sub.Receive(ctx, i.batchSize)
+	if err != nil || len(msgs) == 0 {
+		return err
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(i.concurrency)
+	for _, m := range msgs {
+		g.Go(func() error { return i.queue(gctx, m) })
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return i.sub.Ack(ctx, msgs)
+}
+
+// queue queues the leaf of m, retrying while the log is unavailable. A leaf
+// which the log rejects as invalid is dropped, so it doesn't hold up the
+// messages after it.
+func (i *ingester) queue(ctx context.Context, m *mq.Message) error {
+	leaf := &trillian.LogLeaf{LeafValue: m.Data}
+	if len(m.Key) > 0 {
+		h := sha256.Sum256(m.Key)
+		leaf.LeafIdentityHash = h[:]
+	}
+	bo := backoff.Backoff{Min: 100 * time.Millisecond, Max: 10 * time.Second, Factor: 2, Jitter: true}
+	var rsp *trillian.QueueLeafResponse
+	err := bo.Retry(ctx, func() error {
+		var err error
+		rsp, err = i.client.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: i.logID, Leaf: leaf})
+		return err
+	})
+	if status.Code(err) == codes.InvalidArgument {
+		klog.Warningf("%d: dropping message %v: %v", i.logID, m.ID, err)
+		i.metrics.messages.Inc(i.label, resultRejected)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	result := resultQueued
+	if codes.Code(rsp.GetQueuedLeaf().GetStatus().GetCode()) == codes.AlreadyExists {
+		result = resultDuplicate
+	}
+	i.metrics.messages.Inc(i.label, result)
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/mq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSubscriber delivers its messages once, and again until they are acked.
+type fakeSubscriber struct {
+	pending []*mq.Message
+	acked   int
+}
+
+func (s *fakeSubscriber) Receive(ctx context.Context, max int) ([]*mq.Message, error) {
+	return s.pending[:min(max, len(s.pending))], nil
+}
+
+func (s *fakeSubscriber) Ack(ctx context.Context, msgs []*mq.Message) error {
+	s.pending = s.pending[len(msgs):]
+	s.acked += len(msgs)
+	return nil
+}
+
+func (s *fakeSubscriber) Close(ctx context.Context) error { return nil }
+
+// fakeLog queues leaves, deduplicating them by identity hash or value as the
+// log server does, and fails with err if set.
+type fakeLog struct {
+	trillian.TrillianLogClient
+	queued map[string]bool
+	err    error
+	mu     sync.Mutex
+}
+
+func (f *fakeLog) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	id := string(req.Leaf.LeafIdentityHash)
+	if id == "" {
+		id = string(req.Leaf.LeafValue)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rsp := &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}
+	if f.queued[id] {
+		rsp.QueuedLeaf.Status = status.New(codes.AlreadyExists, "duplicate").Proto()
+	}
+	f.queued[id] = true
+	return rsp, nil
+}
+
+func TestIngestBatch(t *testing.T) {
+	ctx := context.Background()
+	sub := &fakeSubscriber{}
+	for i := 0; i < 5; i++ {
+		sub.pending = append(sub.pending, mq.NewMessage(fmt.Sprint(i), nil, []byte(fmt.Sprintf("leaf %d", i)), nil))
+	}
+	// A redelivery of the first message, and a message whose key is the same
+	// as another's.
+	sub.pending = append(sub.pending,
+		mq.NewMessage("0", nil, []byte("leaf 0"), nil),
+		mq.NewMessage("5", []byte("key"), []byte("leaf 5"), nil),
+		mq.NewMessage("6", []byte("key"), []byte("leaf 6"), nil))
+
+	metrics := newIngesterMetrics(monitoring.InertMetricFactory{})
+	log := &fakeLog{queued: make(map[string]bool)}
+	// Each batch is queued before the next, so that duplicates are detected
+	// deterministically.
+	i := newIngester(1234, log, sub, 4, 1, metrics)
+	for len(sub.pending) > 0 {
+		if err := i.ingestBatch(ctx); err != nil {
+			t.Fatalf("ingestBatch(): %v", err)
+		}
+	}
+
+	if got, want := sub.acked, 8; got != want {
+		t.Errorf("acked %d messages, want %d", got, want)
+	}
+	for _, want := range []struct {
+		result string
+		n      float64
+	}{{resultQueued, 6}, {resultDuplicate, 2}} {
+		if got := metrics.messages.(*monitoring.InertFloat).Value("1234", want.result); got != want.n {
+			t.Errorf("%s messages = %v, want %v", want.result, got, want.n)
+		}
+	}
+}
+
+func TestIngestBatchErrors(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		desc      string
+		err       error
+		wantErr   bool
+		wantAcked int
+	}{
+		{desc: "rejected", err: status.Error(codes.InvalidArgument, "too big"), wantAcked: 1},
+		{desc: "failed", err: status.Error(codes.NotFound, "no such log"), wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			sub := &fakeSubscriber{pending: []*mq.Message{mq.NewMessage("0", nil, []byte("leaf"), nil)}}
+			i := newIngester(1234, &fakeLog{err: test.err}, sub, 10, 1, newIngesterMetrics(nil))
+			if err := i.ingestBatch(ctx); (err != nil) != test.wantErr {
+				t.Errorf("ingestBatch() = %v, want error: %v", err, test.wantErr)
+			}
+			if sub.acked != test.wantAcked {
+				t.Errorf("acked %d messages, want %d", sub.acked, test.wantAcked)
+			}
+		})
+	}
+}
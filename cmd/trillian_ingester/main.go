@@ -0,0 +1,123 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_ingester binary consumes leaves from a Google Cloud Pub/Sub
+// subscription or a Kafka topic, and queues them into a Trillian log. The
+// payload of each message is the value of a leaf. Messages are acknowledged
+// once their leaves are queued, and redelivered messages are deduplicated by
+// the log.
+//
+// Example usage:
+// $ ./trillian_ingester --log_server=host:port --log_id=1234 --pubsub_subscription=projects/p/subscriptions/s
+// $ ./trillian_ingester --log_server=host:port --log_id=1234 --kafka_rest_proxy=http://host:8082 --kafka_topic=leaves
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/mq"
+	"github.com/google/trillian/util/mq/kafka"
+	"github.com/google/trillian/util/mq/pubsub"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logServerAddr = flag.String("log_server", "", "Address of the gRPC Trillian Log Server (host:port)")
+	logID         = flag.Int64("log_id", 0, "Trillian LogID to queue the leaves into")
+	httpEndpoint  = flag.String("http_endpoint", "localhost:8094", "Endpoint for HTTP metrics (host:port)")
+	batchSize     = flag.Int("batch_size", 100, "Max number of messages to receive at a time")
+	concurrency   = flag.Int("concurrency", 10, "Max number of leaves to queue concurrently")
+
+	pubsubSubscription = flag.String("pubsub_subscription", "", "Pub/Sub subscription to consume, as projects/<project>/subscriptions/<subscription>")
+	pubsubKeyAttribute = flag.String("pubsub_key_attribute", "", "If set, the attribute of Pub/Sub messages whose value identifies duplicate leaves")
+
+	kafkaRESTProxy = flag.String("kafka_rest_proxy", "", "URL of the Kafka REST Proxy to consume through")
+	kafkaGroup     = flag.String("kafka_group", "trillian_ingester", "Kafka consumer group")
+	kafkaTopic     = flag.String("kafka_topic", "", "Kafka topic to consume; the key of each record, if any, identifies duplicate leaves")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go util.AwaitSignal(ctx, cancel)
+
+	sub, err := newSubscriber(ctx)
+	if err != nil {
+		klog.Exitf("Failed to create subscriber: %v", err)
+	}
+	defer func() {
+		if err := sub.Close(context.Background()); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		klog.Exitf("Failed to determine dial options: %v", err)
+	}
+	conn, err := grpc.NewClient(*logServerAddr, dialOpts...)
+	if err != nil {
+		klog.Exitf("Failed to dial %v: %v", *logServerAddr, err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	http.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: *httpEndpoint}
+	go func() {
+		klog.Infof("HTTP server starting on %v", *httpEndpoint)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Exitf("HTTP server failed: %v", err)
+		}
+	}()
+
+	metrics := newIngesterMetrics(prometheus.MetricFactory{})
+	newIngester(*logID, trillian.NewTrillianLogClient(conn), sub, *batchSize, *concurrency, metrics).run(ctx)
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		klog.Errorf("HTTP server shutdown: %v", err)
+	}
+}
+
+// newSubscriber returns the subscriber to the message queue set by the flags.
+func newSubscriber(ctx context.Context) (mq.Subscriber, error) {
+	switch {
+	case *pubsubSubscription != "" && *kafkaTopic != "":
+		return nil, errors.New("only one of --pubsub_subscription and --kafka_topic may be set")
+	case *pubsubSubscription != "":
+		return pubsub.NewSubscriber(ctx, *pubsubSubscription, *pubsubKeyAttribute)
+	case *kafkaTopic != "":
+		if *kafkaRESTProxy == "" {
+			return nil, errors.New("--kafka_rest_proxy must be set with --kafka_topic")
+		}
+		return kafka.NewSubscriber(ctx, nil, *kafkaRESTProxy, *kafkaGroup, *kafkaTopic)
+	}
+	return nil, errors.New("one of --pubsub_subscription and --kafka_topic must be set")
+}
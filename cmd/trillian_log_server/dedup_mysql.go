@@ -0,0 +1,47 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build mysql || !(cloudspanner || crdb || postgresql)
+
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/google/trillian/dedup"
+	"github.com/google/trillian/dedup/mysqldedup"
+	"github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/util/clock"
+)
+
+var dedupMySQL = flag.Bool("dedup_mysql", false, "If true, recently submitted leaves are shared with other log servers via the MySQL RecentSubmissions table")
+
+func init() {
+	sharedDedupCaches = append(sharedDedupCaches, func(ctx context.Context) (dedup.Cache, error) {
+		if !*dedupMySQL {
+			return nil, nil
+		}
+		db, err := mysql.GetDatabase()
+		if err != nil {
+			return nil, err
+		}
+		c, err := mysqldedup.New(db, *dedupTTL, clock.System)
+		if err != nil {
+			return nil, err
+		}
+		go c.Run(ctx, *dedupTTL)
+		return c, nil
+	})
+}
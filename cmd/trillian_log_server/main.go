@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
+	"net/netip"
 	"os"
 	"runtime/pprof"
 	"strings"
@@ -28,19 +29,33 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/cmd/internal/selftest"
 	"github.com/google/trillian/cmd/internal/serverutil"
+	"github.com/google/trillian/dedup"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
+	"github.com/google/trillian/monitoring/process"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/google/trillian/quota"
-	"github.com/google/trillian/quota/etcd"
 	"github.com/google/trillian/quota/etcd/quotaapi"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/admin"
+	"github.com/google/trillian/server/admission"
+	"github.com/google/trillian/server/auditlog"
+	"github.com/google/trillian/server/peerquota"
+	"github.com/google/trillian/server/session"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/breaker"
+	"github.com/google/trillian/storage/envelope"
+	"github.com/google/trillian/storage/errmetrics"
+	"github.com/google/trillian/storage/guard"
+	"github.com/google/trillian/storage/replication"
+	"github.com/google/trillian/storage/slowlog"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/load"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
@@ -58,25 +73,104 @@ var (
 	etcdService     = flag.String("etcd_service", "trillian-logserver", "Service name to announce ourselves under")
 	etcdHTTPService = flag.String("etcd_http_service", "trillian-logserver-http", "Service name to announce our HTTP endpoint under")
 
+	healthCheckInterval = flag.Duration("health_check_interval", time.Second*5, "How often the status reported by the gRPC health service is updated")
+	xdsServing          = flag.Bool("xds", false, "If true, the RPC endpoint is served by an xDS-enabled gRPC server, configured by the control plane named in the GRPC_XDS_BOOTSTRAP file, with the TLS flags as a fallback")
+
+	httpTLSCertFile     = flag.String("http_tls_cert_file", "", "Path to the TLS certificate of the HTTP server. If unset, --tls_cert_file is used.")
+	httpTLSKeyFile      = flag.String("http_tls_key_file", "", "Path to the TLS key of the HTTP server. If unset, --tls_key_file is used.")
+	httpBasicAuthFile   = flag.String("http_basic_auth_file", "", "If set, path to a file holding a user:password line, which HTTP requests other than /healthz and /readyz may authenticate with using basic auth")
+	httpBearerTokenFile = flag.String("http_bearer_token_file", "", "If set, path to a file holding a token, which HTTP requests other than /healthz and /readyz may authenticate with as a bearer token")
+
+	adminRPCEndpoint     = flag.String("admin_rpc_endpoint", "", "If set, endpoint for TrillianAdmin RPC requests (host:port), which are then not served on --rpc_endpoint")
+	adminTLSCertFile     = flag.String("admin_tls_cert_file", "", "Path to the TLS certificate of the admin server. If unset, the admin server will use unsecured connections.")
+	adminTLSKeyFile      = flag.String("admin_tls_key_file", "", "Path to the TLS key of the admin server. If unset, the admin server will use unsecured connections.")
+	adminTLSClientCAFile = flag.String("admin_tls_client_ca_file", "", "If set, path to a file of PEM CA certificates, and only admin clients presenting a certificate signed by one of them are accepted")
+
+	defaultRPCDeadline = flag.Duration("default_rpc_deadline", 0, "If set, deadline applied to unary RPCs which arrive without one. Streaming RPCs are exempt")
+	maxRPCDeadline     = flag.Duration("max_rpc_deadline", 0, "If set, unary RPC deadlines further away than this are shortened to it. Streaming RPCs are exempt")
+
+	recoverPanics = flag.Bool("recover_panics", true, "If true, panics of RPC handlers are logged and returned as Internal errors, rather than crashing the server")
+	maxPanics     = flag.Int("max_panics", 0, "If positive, the server exits after this many RPC handler panics recovered by --recover_panics")
+
+	accountBandwidth = flag.Bool("account_bandwidth", false, "If true, the bytes of requests and responses are counted by tree and method")
+
 	quotaSystem = flag.String("quota_system", provider.DefaultQuotaSystem, fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
 	quotaDryRun = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
 
 	storageSystem = flag.String("storage_system", provider.DefaultStorageSystem, fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
 
+	breakerFailures     = flag.Int("storage_breaker_failures", 0, "If positive, this many consecutive storage failures for a tree make its requests fail fast with Unavailable, until a probe request succeeds")
+	breakerOpenDuration = flag.Duration("storage_breaker_open_duration", 30*time.Second, "How long requests for a tree fail fast after its storage circuit breaker opens, before a probe request is allowed")
+
+	errorMetrics           = flag.Bool("storage_error_metrics", true, "If true, errors returned by storage operations are counted by operation and canonical error code")
+	appendOnlyGuard        = flag.Bool("storage_append_only_guard", false, "If true, storage writes which would modify sequenced leaves, stored Merkle nodes or roll back the log root are rejected")
+	slowOperationThreshold = flag.Duration("storage_slow_operation_threshold", 0, "If positive, storage operations and transactions which take longer than this are logged and counted, with their tree ID and row count")
+
+	leafEncryptionKeys = flag.String("leaf_encryption_local_keys", "", "Path to a file of key encryption keys for trees with leaf encryption, one '<key-uri> <hex-aes-key>' per line")
+
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", serverutil.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
 
+	// Long-running admin operation flags.
+	exportDir          = flag.String("export_dir", "", "If set, the directory which the ExportTree admin operation writes logs to")
+	operationBatchSize = flag.Int("admin_operation_batch_size", 1000, "Max number of leaves read or copied per transaction by long-running admin operations")
+	operationRetention = flag.Duration("admin_operation_retention", 24*time.Hour, "How long finished long-running admin operations are kept for")
+
 	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to stackdriver. Can be empty for GCP, consult docs for other platforms.")
 	tracingPercent   = flag.Int("tracing_percent", 0, "Percent of requests to be traced. Zero is a special case to use the DefaultSampler")
+	debugPages       = flag.Bool("debug_pages", false, "If true, the gRPC channelz service is registered on the RPC endpoint, and OpenCensus zPages are served under /debug/ on the HTTP endpoint")
+
+	runtimeMetricsInterval = flag.Duration("runtime_metrics_interval", 10*time.Second, "How often Go runtime, process and storage resource statistics are exported through the metrics backend (0 means they are not)")
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
+	selfTest        = flag.Bool("self_test", false, "If true, check the configured storage, quota, TLS keys and leaf encryption keys, print a report of the checks and exit, with status 1 if any failed")
+	selfTestTimeout = flag.Duration("self_test_timeout", 30*time.Second, "Timeout of each check of --self_test")
+
 	// Profiling related flags.
 	cpuProfile = flag.String("cpuprofile", "", "If set, write CPU profile to this file")
 	memProfile = flag.String("memprofile", "", "If set, write memory profile to this file")
 	maxMsgSize = flag.Int("max_msg_size_bytes", 0, "Optional max gRPC message size in bytes")
+
+	// Admission control flags.
+	admissionMaxInFlight   = flag.Int("admission_max_in_flight", 0, "If positive, low-priority requests are queued while more than this many requests are in flight")
+	admissionMaxQueueDepth = flag.Int("admission_max_queue_depth", 100, "Number of low-priority requests which may be queued by --admission_max_in_flight before further ones are shed")
+	admissionMaxCPU        = flag.Float64("admission_max_cpu", 0, "If set, low-priority requests are shed while the fraction of available CPU used exceeds this, e.g. 0.9")
+	admissionLowPriority   = flag.String("admission_low_priority_methods", strings.Join(admission.DefaultLowPriorityMethods, ","), "Comma-separated names of the RPCs which admission control may shed")
+
+	// Per-peer quota flags.
+	peerQuotaReadQPS  = flag.Float64("peer_quota_read_qps", 0, "If positive, the rate of read requests allowed from each network peer")
+	peerQuotaWriteQPS = flag.Float64("peer_quota_write_qps", 0, "If positive, the rate of leaves which each network peer may write")
+	peerQuotaBurst    = flag.Int("peer_quota_burst", 0, "Number of requests or leaves which each network peer may send at once; defaults to the rate")
+	peerQuotaIPv4Bits = flag.Int("peer_quota_ipv4_prefix_len", 32, "Length of the IPv4 prefixes which network peers are grouped by for per-peer quota")
+	peerQuotaIPv6Bits = flag.Int("peer_quota_ipv6_prefix_len", 64, "Length of the IPv6 prefixes which network peers are grouped by for per-peer quota")
+	peerQuotaAllow    = flag.String("peer_quota_allowlist", "", "Comma-separated prefixes of network peers exempt from per-peer quota, e.g. 10.0.0.0/8")
+
+	// Duplicate suppression flags.
+	dedupCacheSize = flag.Int("dedup_cache_size", 0, "If positive, duplicate QueueLeaf requests are answered from an in-memory cache of this many recently submitted leaves, before quota is charged")
+	dedupTTL       = flag.Duration("dedup_ttl", 10*time.Minute, "How long recently submitted leaves are remembered for duplicate suppression")
+	dedupCoalesce  = flag.Bool("dedup_coalesce_in_flight", false, "If true, concurrent QueueLeaf requests for the same leaf are merged, so that only the first reaches storage and the others receive its result, before quota is charged")
+
+	// Audit log flags.
+	auditLogSink            = flag.String("audit_log_sink", "", "If set, a summary of each RPC is written to this sink: file:///path, an http(s):// URL such as a fluentd in_http input, or pubsub://projects/<project>/topics/<topic>")
+	auditLogSampleRate      = flag.Float64("audit_log_sample_rate", 1, "Fraction of successful RPCs written to --audit_log_sink")
+	auditLogErrorSampleRate = flag.Float64("audit_log_error_sample_rate", 1, "Fraction of failed RPCs written to --audit_log_sink")
+
+	// Read-your-writes flags.
+	sessionTokens = flag.Bool("session_tokens", false, "If true, QueueLeaf and AddSequencedLeaves responses carry session tokens, and reads presenting one fail with Unavailable if this server's view of the log is older than the token's")
+
+	proofWorkers = flag.Int("proof_workers", 0, "If positive, the number of inclusion and consistency proofs built at once, beyond which proof requests wait for a worker; concurrent requests for identical proofs then share the work of building them")
+
+	// sharedDedupCaches holds constructors for the dedup caches shared
+	// between log servers which are compiled in. Each returns nil if it isn't
+	// enabled by flags.
+	sharedDedupCaches []func(context.Context) (dedup.Cache, error)
+
+	// newMigrationStorage returns the storage which the MigrateTree admin
+	// operation copies logs to, or nil if migration is disabled.
+	newMigrationStorage = func(monitoring.MetricFactory) (*replication.Storage, error) { return nil, nil }
 )
 
 func main() {
@@ -84,11 +178,26 @@ func main() {
 	flag.Parse()
 	defer klog.Flush()
 
+	if *provider.ListProviders {
+		if err := provider.PrintProviders(os.Stdout); err != nil {
+			klog.Exitf("Failed to list providers: %v", err)
+		}
+		return
+	}
+
 	if *configFile != "" {
 		if err := cmd.ParseFlagFile(*configFile); err != nil {
 			klog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
 		}
 	}
+	if *selfTest {
+		if !selftest.Run(context.Background(), os.Stdout, *selfTestTimeout, selfTestChecks()) {
+			klog.Flush()
+			os.Exit(1)
+		}
+		return
+	}
+
 	klog.Info("**** Log Server Starting ****")
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -98,6 +207,9 @@ func main() {
 	var options []grpc.ServerOption
 	mf := prometheus.MetricFactory{}
 	monitoring.SetStartSpan(opencensus.StartSpan)
+	if *runtimeMetricsInterval > 0 {
+		go process.NewExporter(mf).Run(ctx, *runtimeMetricsInterval)
+	}
 
 	if *tracing {
 		opts, err := opencensus.EnableRPCServerTracing(*tracingProjectID, *tracingPercent)
@@ -107,6 +219,13 @@ func main() {
 		// Enable the server request counter tracing etc.
 		options = append(options, opts...)
 	}
+	if *debugPages {
+		opts := opencensus.EnableZPages(nil, "/debug")
+		if !*tracing {
+			// Otherwise the tracing stats handler already feeds the zPages.
+			options = append(options, opts...)
+		}
+	}
 
 	if *maxMsgSize > 0 {
 		options = append(options, grpc.MaxRecvMsgSize(*maxMsgSize))
@@ -120,9 +239,12 @@ func main() {
 			klog.Errorf("Close(): %v", err)
 		}
 	}()
+	if *runtimeMetricsInterval > 0 {
+		go storage.RunStatsExporter(ctx, sp, *runtimeMetricsInterval)
+	}
 
 	var client *clientv3.Client
-	if servers := *etcd.Servers; servers != "" {
+	if servers := *provider.EtcdServers; servers != "" {
 		if client, err = clientv3.New(clientv3.Config{
 			Endpoints:   strings.Split(servers, ","),
 			DialTimeout: 5 * time.Second,
@@ -150,11 +272,37 @@ func main() {
 		klog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	ls := sp.LogStorage()
+	if *errorMetrics {
+		ls = errmetrics.NewLogStorage(ls, *storageSystem, mf)
+	}
+	if *slowOperationThreshold > 0 {
+		ls = slowlog.NewLogStorage(ls, slowlog.Config{Threshold: *slowOperationThreshold}, mf)
+	}
+	if *appendOnlyGuard {
+		ls = guard.NewLogStorage(ls, mf)
+	}
+	if *breakerFailures > 0 {
+		ls = breaker.NewLogStorage(ls, breaker.Config{
+			FailureThreshold: *breakerFailures,
+			OpenDuration:     *breakerOpenDuration,
+		}, mf)
+	}
+	var keyWrapper envelope.KeyWrapper
+	if *leafEncryptionKeys != "" {
+		keyWrapper, err = newLocalKeyWrapper(*leafEncryptionKeys)
+		if err != nil {
+			klog.Exitf("Failed to load leaf encryption keys: %v", err)
+		}
+	}
+	ls = envelope.NewLogStorage(ls, keyWrapper)
+
 	registry := extension.Registry{
-		AdminStorage:  sp.AdminStorage(),
-		LogStorage:    sp.LogStorage(),
-		QuotaManager:  qm,
-		MetricFactory: mf,
+		AdminStorage:   sp.AdminStorage(),
+		LogStorage:     ls,
+		QuotaManager:   qm,
+		MetricFactory:  mf,
+		LeafKeyWrapper: keyWrapper,
 	}
 
 	// Enable CPU profile if requested.
@@ -166,23 +314,137 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	var interceptors []grpc.UnaryServerInterceptor
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if *auditLogSink != "" {
+		sink, err := auditlog.NewSink(ctx, *auditLogSink)
+		if err != nil {
+			klog.Exitf("Failed to create audit log sink: %v", err)
+		}
+		auditLogger := auditlog.New(auditlog.Config{
+			Sink:            sink,
+			SampleRate:      *auditLogSampleRate,
+			ErrorSampleRate: *auditLogErrorSampleRate,
+		}, mf)
+		defer func() {
+			if err := auditLogger.Close(); err != nil {
+				klog.Errorf("Failed to close audit log: %v", err)
+			}
+		}()
+		// First, so that requests rejected by the other interceptors are
+		// recorded too.
+		interceptors = append(interceptors, auditLogger.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, auditLogger.StreamInterceptor)
+	}
+	if *admissionMaxInFlight > 0 || *admissionMaxCPU > 0 {
+		cfg := admission.Config{
+			LowPriorityMethods: strings.Split(*admissionLowPriority, ","),
+			MaxInFlight:        *admissionMaxInFlight,
+			MaxQueueDepth:      *admissionMaxQueueDepth,
+		}
+		if *admissionMaxCPU > 0 {
+			lm := load.NewMonitor(0, *admissionMaxCPU)
+			go lm.Run(ctx, time.Second)
+			cfg.Overloaded = lm.Overloaded
+		}
+		controller := admission.New(cfg, mf)
+		interceptors = append(interceptors, controller.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, controller.StreamInterceptor)
+	}
+	if *peerQuotaReadQPS > 0 || *peerQuotaWriteQPS > 0 {
+		cfg := peerquota.Config{
+			ReadRate:      *peerQuotaReadQPS,
+			WriteRate:     *peerQuotaWriteQPS,
+			Burst:         *peerQuotaBurst,
+			IPv4PrefixLen: *peerQuotaIPv4Bits,
+			IPv6PrefixLen: *peerQuotaIPv6Bits,
+		}
+		if *peerQuotaAllow != "" {
+			for _, p := range strings.Split(*peerQuotaAllow, ",") {
+				prefix, err := netip.ParsePrefix(strings.TrimSpace(p))
+				if err != nil {
+					klog.Exitf("Invalid --peer_quota_allowlist: %v", err)
+				}
+				cfg.Allowlist = append(cfg.Allowlist, prefix)
+			}
+		}
+		limiter := peerquota.New(cfg, mf, clock.System)
+		interceptors = append(interceptors, limiter.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, limiter.StreamInterceptor)
+	}
+	if *sessionTokens {
+		// Before dedup, so that duplicate submissions are issued tokens too.
+		interceptors = append(interceptors, session.NewInterceptor(sp.AdminStorage(), ls, mf).UnaryInterceptor)
+	}
+	if cache, err := newDedupCache(ctx); err != nil {
+		klog.Exitf("Failed to create dedup cache: %v", err)
+	} else if cache != nil {
+		interceptors = append(interceptors, dedup.NewInterceptor(cache, sp.AdminStorage(), mf).UnaryInterceptor)
+	}
+	if *dedupCoalesce {
+		interceptors = append(interceptors, dedup.NewCoalescer(sp.AdminStorage(), mf).UnaryInterceptor)
+	}
+
+	httpAuth, err := serverutil.LoadHTTPAuth(*httpBasicAuthFile, *httpBearerTokenFile)
+	if err != nil {
+		klog.Exitf("Failed to load HTTP credentials: %v", err)
+	}
+
+	opsCfg := admin.OperationsConfig{
+		ExportDir: *exportDir,
+		BatchSize: *operationBatchSize,
+		Retention: *operationRetention,
+	}
+	if target, err := newMigrationStorage(mf); err != nil {
+		klog.Exitf("Failed to open migration storage: %v", err)
+	} else if target != nil {
+		opsCfg.MigrationSource = &replication.Storage{Admin: sp.AdminStorage(), Log: sp.LogStorage()}
+		opsCfg.MigrationTarget = target
+	}
+
+	var warmup func(context.Context) error
+	if w, ok := sp.(storage.Warmer); ok {
+		warmup = w.Warmup
+	}
+
 	m := serverutil.Main{
 		RPCEndpoint:  *rpcEndpoint,
 		HTTPEndpoint: *httpEndpoint,
 		TLSCertFile:  *tlsCertFile,
 		TLSKeyFile:   *tlsKeyFile,
 		StatsPrefix:  "log",
+
+		HTTPTLSCertFile: *httpTLSCertFile,
+		HTTPTLSKeyFile:  *httpTLSKeyFile,
+		HTTPAuth:        httpAuth,
+
+		AdminRPCEndpoint:     *adminRPCEndpoint,
+		AdminTLSCertFile:     *adminTLSCertFile,
+		AdminTLSKeyFile:      *adminTLSKeyFile,
+		AdminTLSClientCAFile: *adminTLSClientCAFile,
+
 		ExtraOptions: options,
 		QuotaDryRun:  *quotaDryRun,
-		DBClose:      sp.Close,
-		Registry:     registry,
-		RegisterServerFn: func(s *grpc.Server, registry extension.Registry) error {
-			logServer := server.NewTrillianLogRPCServer(registry, clock.System)
+		Channelz:     *debugPages,
+
+		DefaultRPCDeadline: *defaultRPCDeadline,
+		MaxRPCDeadline:     *maxRPCDeadline,
+		RecoverPanics:      *recoverPanics,
+		MaxPanics:          *maxPanics,
+		AccountBandwidth:   *accountBandwidth,
+		UnaryInterceptors:  interceptors,
+		StreamInterceptors: streamInterceptors,
+		DBClose:            sp.Close,
+		Registry:           registry,
+		RegisterServerFn: func(s grpc.ServiceRegistrar, registry extension.Registry) error {
+			logServer := server.NewTrillianLogRPCServer(registry, clock.System, server.WithProofWorkers(*proofWorkers))
 			if err := logServer.IsHealthy(); err != nil {
 				return err
 			}
 			trillian.RegisterTrillianLogServer(s, logServer)
-			if *quotaSystem == etcd.QuotaManagerName {
+			// The etcd quota provider isn't imported here, as it may not be
+			// compiled in, so it's named literally.
+			if *quotaSystem == "etcd" {
 				quotapb.RegisterQuotaServer(s, quotaapi.NewServer(client))
 			}
 			return nil
@@ -192,10 +454,14 @@ func main() {
 			return as.CheckDatabaseAccessible(ctx)
 		},
 		HealthyDeadline:       *healthzTimeout,
+		HealthCheckInterval:   *healthCheckInterval,
+		Warmup:                warmup,
+		XDS:                   *xdsServing,
 		AllowedTreeTypes:      []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG},
 		TreeGCEnabled:         *treeGCEnabled,
 		TreeDeleteThreshold:   *treeDeleteThreshold,
 		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		AdminOperations:       opsCfg,
 	}
 
 	if err := m.Run(ctx); err != nil {
@@ -210,6 +476,28 @@ func main() {
 	}
 }
 
+// newDedupCache returns the duplicate suppression cache configured by flags,
+// or nil if duplicate suppression is disabled.
+func newDedupCache(ctx context.Context) (dedup.Cache, error) {
+	var caches []dedup.Cache
+	if *dedupCacheSize > 0 {
+		caches = append(caches, dedup.NewMemoryCache(*dedupCacheSize, *dedupTTL, clock.System))
+	}
+	for _, newCache := range sharedDedupCaches {
+		c, err := newCache(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			caches = append(caches, c)
+		}
+	}
+	if len(caches) == 0 {
+		return nil, nil
+	}
+	return dedup.Layered(caches...), nil
+}
+
 func mustCreate(fileName string) *os.File {
 	f, err := os.Create(fileName)
 	if err != nil {
@@ -217,3 +505,43 @@ func mustCreate(fileName string) *os.File {
 	}
 	return f
 }
+
+// selfTestChecks returns the checks of --self_test.
+func selfTestChecks() []selftest.Check {
+	checks := selftest.Storage(*storageSystem, func() (storage.Provider, error) {
+		return storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	})
+	return append(checks,
+		selftest.Quota(*quotaSystem),
+		selftest.KeyPair("tls", *tlsCertFile, *tlsKeyFile),
+		selftest.KeyPair("http_tls", *httpTLSCertFile, *httpTLSKeyFile),
+		selftest.KeyPair("admin_tls", *adminTLSCertFile, *adminTLSKeyFile),
+		selftest.Check{
+			Name: "leaf_encryption_keys",
+			Run: func(context.Context) (string, error) {
+				if *leafEncryptionKeys == "" {
+					return "not configured", nil
+				}
+				if _, err := newLocalKeyWrapper(*leafEncryptionKeys); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("loaded %s", *leafEncryptionKeys), nil
+			},
+		},
+	)
+}
+
+// newLocalKeyWrapper returns a key wrapper for the key encryption keys in
+// the named file.
+func newLocalKeyWrapper(fileName string) (envelope.KeyWrapper, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys, err := envelope.ParseLocalKeys(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return envelope.NewLocalKeyWrapper(keys)
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build mysql || !(cloudspanner || crdb || postgresql)
+
+package main
+
+import (
+	"flag"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/storage/replication"
+)
+
+var migrationMySQLURI = flag.String("migration_mysql_uri", "", "If set, the MigrateTree admin operation copies logs to the MySQL database at this URI")
+
+func init() {
+	newMigrationStorage = func(mf monitoring.MetricFactory) (*replication.Storage, error) {
+		if *migrationMySQLURI == "" {
+			return nil, nil
+		}
+		db, err := mysql.OpenDB(*migrationMySQLURI)
+		if err != nil {
+			return nil, err
+		}
+		return &replication.Storage{Admin: mysql.NewAdminStorage(db), Log: mysql.NewLogStorage(db, mf)}, nil
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
@@ -33,15 +34,21 @@ import (
 	"time"
 
 	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/cmd/internal/selftest"
 	"github.com/google/trillian/cmd/internal/serverutil"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/log"
+	"github.com/google/trillian/log/signerpb"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
+	"github.com/google/trillian/monitoring/process"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/google/trillian/quota"
-	"github.com/google/trillian/quota/etcd"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/errmetrics"
+	"github.com/google/trillian/storage/guard"
+	"github.com/google/trillian/storage/replication"
+	"github.com/google/trillian/storage/slowlog"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
@@ -62,25 +69,65 @@ var (
 	sequencerIntervalFlag    = flag.Duration("sequencer_interval", 100*time.Millisecond, "Time between each sequencing pass through all logs")
 	batchSizeFlag            = flag.Int("batch_size", 1000, "Max number of leaves to process per batch")
 	numSeqFlag               = flag.Int("num_sequencers", 10, "Number of sequencer workers to run in parallel")
+	sequencerTXBatchSizeFlag = flag.Int("sequencer_tx_batch_size", 0, "If set, the maximum number of leaves to integrate in each storage transaction; larger batches are staged over several transactions before their root is published, where the storage supports it.")
 	sequencerGuardWindowFlag = flag.Duration("sequencer_guard_window", 0, "If set, the time elapsed before submitted leaves are eligible for sequencing")
 	forceMaster              = flag.Bool("force_master", false, "If true, assume master for all logs")
 	etcdHTTPService          = flag.String("etcd_http_service", "trillian-logsigner-http", "Service name to announce our HTTP endpoint under")
 	healthzTimeout           = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
+	healthCheckInterval      = flag.Duration("health_check_interval", time.Second*5, "How often the status reported by the gRPC health service is updated")
+	xdsServing               = flag.Bool("xds", false, "If true, the RPC endpoint is served by an xDS-enabled gRPC server, configured by the control plane named in the GRPC_XDS_BOOTSTRAP file, with the TLS flags as a fallback")
+	debugPages               = flag.Bool("debug_pages", false, "If true, the gRPC channelz service is registered on the RPC endpoint, and OpenCensus zPages are served under /debug/ on the HTTP endpoint")
+	runtimeMetricsInterval   = flag.Duration("runtime_metrics_interval", 10*time.Second, "How often Go runtime, process and storage resource statistics are exported through the metrics backend (0 means they are not)")
+
+	maxRootDurationMargin = flag.Duration("max_root_duration_margin", 5*time.Second, "How long before the max_root_duration of a tree lapses to sign a new root for it, even if there are no new leaves. Capped at half of the max_root_duration")
+
+	recoverPanics = flag.Bool("recover_panics", true, "If true, panics of RPC handlers are logged and returned as Internal errors, rather than crashing the server")
+	maxPanics     = flag.Int("max_panics", 0, "If positive, the server exits after this many RPC handler panics recovered by --recover_panics")
+
+	httpTLSCertFile     = flag.String("http_tls_cert_file", "", "Path to the TLS certificate of the HTTP server. If unset, --tls_cert_file is used.")
+	httpTLSKeyFile      = flag.String("http_tls_key_file", "", "Path to the TLS key of the HTTP server. If unset, --tls_key_file is used.")
+	httpBasicAuthFile   = flag.String("http_basic_auth_file", "", "If set, path to a file holding a user:password line, which HTTP requests other than /healthz and /readyz may authenticate with using basic auth")
+	httpBearerTokenFile = flag.String("http_bearer_token_file", "", "If set, path to a file holding a token, which HTTP requests other than /healthz and /readyz may authenticate with as a bearer token")
+
+	stallCheckInterval = flag.Duration("stall_check_interval", 0, "If set, how often to check all active logs for stalled sequencing")
+	stallThreshold     = flag.Duration("stall_threshold", 0, "Root age beyond which a log with pending leaves is considered stalled, for logs without a max_root_duration (0 means such logs are not checked)")
+	stallGrace         = flag.Duration("stall_grace", 30*time.Second, "Time allowed beyond a log's max_root_duration before it is considered stalled")
+	stallWebhookURL    = flag.String("stall_webhook_url", "", "If set, URL to POST a JSON description of each stalled log to")
+
+	replicationInterval  = flag.Duration("replication_interval", replication.DefaultInterval, "Time between passes replicating all logs to the standby storage, if one is configured (must be positive)")
+	replicationBatchSize = flag.Int("replication_batch_size", replication.DefaultBatchSize, "Max number of leaves to replicate per transaction (must be positive)")
+
+	integrationEventRetention = flag.Duration("integration_event_retention", log.IntegrationEventRetention, "How long the integration events of each log are kept for, as listed by the ListIntegrationEvents admin RPC (0 means events are not recorded)")
+	mergeDelaySLOWindow       = flag.Duration("merge_delay_slo_window", log.MergeDelaySLOWindow, "Rolling window over which the compliance of logs with their merge_delay_target is computed")
+	signerID                  = flag.String("signer_id", "", "If set, the ID of this signer recorded in integration events, rather than its host name and process ID")
+
+	// newStandbyStorage returns the storage that logs are replicated to, or
+	// nil if replication is disabled.
+	newStandbyStorage = func(monitoring.MetricFactory) (*replication.Storage, error) { return nil, nil }
 
 	quotaSystem         = flag.String("quota_system", provider.DefaultQuotaSystem, fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
 	quotaIncreaseFactor = flag.Float64("quota_increase_factor", log.QuotaIncreaseFactor,
 		"Increase factor for tokens replenished by sequencing-based quotas (1 means a 1:1 relationship between sequenced leaves and replenished tokens)."+
 			"Only effective for --quota_system=etcd.")
 
-	storageSystem = flag.String("storage_system", provider.DefaultStorageSystem, fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	storageSystem          = flag.String("storage_system", provider.DefaultStorageSystem, fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	errorMetrics           = flag.Bool("storage_error_metrics", true, "If true, errors returned by storage operations are counted by operation and canonical error code")
+	appendOnlyGuard        = flag.Bool("storage_append_only_guard", false, "If true, storage writes which would modify sequenced leaves, stored Merkle nodes or roll back the log root are rejected")
+	slowOperationThreshold = flag.Duration("storage_slow_operation_threshold", 0, "If positive, storage operations and transactions which take longer than this are logged and counted, with their tree ID and row count")
 
-	electionSystem     = flag.String("election_system", provider.DefaultElectionSystem, fmt.Sprintf("Election system to use. One of: %v", election2.Providers()))
-	preElectionPause   = flag.Duration("pre_election_pause", 1*time.Second, "Maximum time to wait before starting elections")
-	masterHoldInterval = flag.Duration("master_hold_interval", 60*time.Second, "Minimum interval to hold mastership for")
-	masterHoldJitter   = flag.Duration("master_hold_jitter", 120*time.Second, "Maximal random addition to --master_hold_interval")
+	electionSystem          = flag.String("election_system", provider.DefaultElectionSystem, fmt.Sprintf("Election system to use. One of: %v", election2.Providers()))
+	preElectionPause        = flag.Duration("pre_election_pause", 1*time.Second, "Maximum time to wait before starting elections")
+	preElectionBackoff      = flag.Duration("pre_election_backoff", 0, "Maximum time to wait after resigning mastership of a log before standing for its election again, so that other signers have a chance to win it")
+	masterHoldInterval      = flag.Duration("master_hold_interval", 60*time.Second, "Minimum interval to hold mastership for")
+	masterHoldJitter        = flag.Duration("master_hold_jitter", 120*time.Second, "Maximal random addition to --master_hold_interval")
+	masterResignProbability = flag.Float64("master_resign_probability", 1, "Probability of resigning mastership of a log each time its hold interval elapses, rather than holding it for another interval. Applies to all election systems")
+	standbyWarmInterval     = flag.Duration("standby_warm_interval", 0, "If set, how often to pre-load the metadata and compact range of each active log that another signer is master for, so that sequencing resumes without cold reads after a failover")
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
+	selfTest        = flag.Bool("self_test", false, "If true, check the configured storage, quota, election system and TLS keys, print a report of the checks and exit, with status 1 if any failed")
+	selfTestTimeout = flag.Duration("self_test_timeout", 30*time.Second, "Timeout of each check of --self_test")
+
 	// Profiling related flags.
 	cpuProfile = flag.String("cpuprofile", "", "If set, write CPU profile to this file")
 	memProfile = flag.String("memprofile", "", "If set, write memory profile to this file")
@@ -92,12 +139,27 @@ func main() {
 	flag.Parse()
 	defer klog.Flush()
 
+	if *provider.ListProviders {
+		if err := provider.PrintProviders(os.Stdout); err != nil {
+			klog.Exitf("Failed to list providers: %v", err)
+		}
+		return
+	}
+
 	if *configFile != "" {
 		if err := cmd.ParseFlagFile(*configFile); err != nil {
 			klog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
 		}
 	}
 
+	if *selfTest {
+		if !selftest.Run(context.Background(), os.Stdout, *selfTestTimeout, selfTestChecks()) {
+			klog.Flush()
+			os.Exit(1)
+		}
+		return
+	}
+
 	klog.CopyStandardLogTo("WARNING")
 	klog.Info("**** Log Signer Starting ****")
 
@@ -115,7 +177,7 @@ func main() {
 	}()
 
 	var client *clientv3.Client
-	if servers := *etcd.Servers; servers != "" {
+	if servers := *provider.EtcdServers; servers != "" {
 		if client, err = clientv3.New(clientv3.Config{
 			Endpoints:   strings.Split(servers, ","),
 			DialTimeout: 5 * time.Second,
@@ -132,6 +194,10 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go util.AwaitSignal(ctx, cancel)
+	if *runtimeMetricsInterval > 0 {
+		go process.NewExporter(mf).Run(ctx, *runtimeMetricsInterval)
+		go storage.RunStatsExporter(ctx, sp, *runtimeMetricsInterval)
+	}
 
 	var electionFactory election2.Factory
 	switch {
@@ -150,9 +216,20 @@ func main() {
 		klog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	ls := sp.LogStorage()
+	if *errorMetrics {
+		ls = errmetrics.NewLogStorage(ls, *storageSystem, mf)
+	}
+	if *slowOperationThreshold > 0 {
+		ls = slowlog.NewLogStorage(ls, slowlog.Config{Threshold: *slowOperationThreshold}, mf)
+	}
+	if *appendOnlyGuard {
+		ls = guard.NewLogStorage(ls, mf)
+	}
+
 	registry := extension.Registry{
 		AdminStorage:    sp.AdminStorage(),
-		LogStorage:      sp.LogStorage(),
+		LogStorage:      ls,
 		ElectionFactory: electionFactory,
 		QuotaManager:    qm,
 		MetricFactory:   mf,
@@ -169,22 +246,65 @@ func main() {
 	// both sequencing and signing.
 	// TODO(Martin2112): Should respect read only mode and the flags in tree control etc
 	log.QuotaIncreaseFactor = *quotaIncreaseFactor
+	log.IntegrationEventRetention = *integrationEventRetention
+	log.MergeDelaySLOWindow = *mergeDelaySLOWindow
+	if *signerID != "" {
+		log.SignerID = *signerID
+	}
 	sequencerManager := log.NewSequencerManager(registry, *sequencerGuardWindowFlag)
 	info := log.OperationInfo{
-		Registry:    registry,
-		BatchSize:   *batchSizeFlag,
-		NumWorkers:  *numSeqFlag,
-		RunInterval: *sequencerIntervalFlag,
-		TimeSource:  clock.System,
+		Registry:              registry,
+		BatchSize:             *batchSizeFlag,
+		TXBatchSize:           *sequencerTXBatchSizeFlag,
+		MaxRootDurationMargin: *maxRootDurationMargin,
+		NumWorkers:            *numSeqFlag,
+		RunInterval:           *sequencerIntervalFlag,
+		StandbyWarmInterval:   *standbyWarmInterval,
+		TimeSource:            clock.System,
 		ElectionConfig: election.RunnerConfig{
 			PreElectionPause:   *preElectionPause,
+			PreElectionBackoff: *preElectionBackoff,
 			MasterHoldInterval: *masterHoldInterval,
 			MasterHoldJitter:   *masterHoldJitter,
+			ResignProbability:  *masterResignProbability,
 			TimeSource:         clock.System,
 		},
 	}
 	sequencerTask := log.NewOperationManager(info, sequencerManager)
 	go sequencerTask.OperationLoop(ctx)
+	statusServer := log.NewStatusServer(sequencerTask)
+	http.Handle("/signer/status", statusServer)
+
+	// Start the stalled-sequencing watchdog if requested.
+	if *stallCheckInterval > 0 {
+		cfg := log.WatchdogConfig{
+			Interval:         *stallCheckInterval,
+			DefaultThreshold: *stallThreshold,
+			Grace:            *stallGrace,
+			GuardWindow:      *sequencerGuardWindowFlag,
+		}
+		if *stallWebhookURL != "" {
+			cfg.Handlers = append(cfg.Handlers, log.NewWebhookStallHandler(*stallWebhookURL, &http.Client{Timeout: 10 * time.Second}))
+		}
+		go log.NewStallWatchdog(registry, cfg, clock.System).Run(ctx)
+	}
+
+	// Start replicating to the standby storage if one is configured.
+	standby, err := newStandbyStorage(mf)
+	if err != nil {
+		klog.Exitf("Failed to open standby storage: %v", err)
+	}
+	if standby != nil {
+		if *replicationInterval <= 0 {
+			klog.Exitf("--replication_interval must be positive, got %v", *replicationInterval)
+		}
+		if *replicationBatchSize <= 0 {
+			klog.Exitf("--replication_batch_size must be positive, got %d", *replicationBatchSize)
+		}
+		primary := replication.Storage{Admin: sp.AdminStorage(), Log: sp.LogStorage()}
+		cfg := replication.Config{Interval: *replicationInterval, BatchSize: *replicationBatchSize}
+		go replication.NewReplicator(primary, *standby, cfg, mf, clock.System).Run(ctx)
+	}
 
 	// Enable CPU profile if requested
 	if *cpuProfile != "" {
@@ -199,18 +319,56 @@ func main() {
 	if *maxMsgSize > 0 {
 		options = append(options, grpc.MaxRecvMsgSize(*maxMsgSize))
 	}
+	if *debugPages {
+		options = append(options, opencensus.EnableZPages(nil, "/debug")...)
+	}
+	httpAuth, err := serverutil.LoadHTTPAuth(*httpBasicAuthFile, *httpBearerTokenFile)
+	if err != nil {
+		klog.Exitf("Failed to load HTTP credentials: %v", err)
+	}
+
+	// Open the database connections and election sessions before reporting
+	// ready.
+	var warmups []func(context.Context) error
+	if w, ok := sp.(storage.Warmer); ok {
+		warmups = append(warmups, w.Warmup)
+	}
+	if w, ok := electionFactory.(election2.Warmer); ok {
+		warmups = append(warmups, w.Warmup)
+	}
+	warmup := func(ctx context.Context) error {
+		for _, w := range warmups {
+			if err := w(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	m := serverutil.Main{
-		RPCEndpoint:      *rpcEndpoint,
-		HTTPEndpoint:     *httpEndpoint,
-		TLSCertFile:      *tlsCertFile,
-		TLSKeyFile:       *tlsKeyFile,
-		StatsPrefix:      "logsigner",
-		ExtraOptions:     options,
-		DBClose:          sp.Close,
-		Registry:         registry,
-		RegisterServerFn: func(s *grpc.Server, _ extension.Registry) error { return nil },
-		IsHealthy:        sp.AdminStorage().CheckDatabaseAccessible,
-		HealthyDeadline:  *healthzTimeout,
+		RPCEndpoint:     *rpcEndpoint,
+		HTTPEndpoint:    *httpEndpoint,
+		TLSCertFile:     *tlsCertFile,
+		TLSKeyFile:      *tlsKeyFile,
+		HTTPTLSCertFile: *httpTLSCertFile,
+		HTTPTLSKeyFile:  *httpTLSKeyFile,
+		HTTPAuth:        httpAuth,
+		StatsPrefix:     "logsigner",
+		ExtraOptions:    options,
+		DBClose:         sp.Close,
+		Registry:        registry,
+		RegisterServerFn: func(s grpc.ServiceRegistrar, _ extension.Registry) error {
+			signerpb.RegisterSignerServer(s, statusServer)
+			return nil
+		},
+		IsHealthy:           sp.AdminStorage().CheckDatabaseAccessible,
+		HealthyDeadline:     *healthzTimeout,
+		HealthCheckInterval: *healthCheckInterval,
+		Warmup:              warmup,
+		XDS:                 *xdsServing,
+		Channelz:            *debugPages,
+		RecoverPanics:       *recoverPanics,
+		MaxPanics:           *maxPanics,
 	}
 
 	if err := m.Run(ctx); err != nil {
@@ -229,6 +387,28 @@ func main() {
 	time.Sleep(time.Second * 5)
 }
 
+// selfTestChecks returns the checks of --self_test.
+func selfTestChecks() []selftest.Check {
+	checks := selftest.Storage(*storageSystem, func() (storage.Provider, error) {
+		return storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	})
+	system := *electionSystem
+	if *forceMaster {
+		system = "noop"
+	}
+	return append(checks,
+		selftest.Election(system, func() (election2.Factory, error) {
+			if *forceMaster {
+				return election2.NoopFactory{}, nil
+			}
+			return election2.NewProvider(*electionSystem)
+		}),
+		selftest.Quota(*quotaSystem),
+		selftest.KeyPair("tls", *tlsCertFile, *tlsKeyFile),
+		selftest.KeyPair("http_tls", *httpTLSCertFile, *httpTLSKeyFile),
+	)
+}
+
 func mustCreate(fileName string) *os.File {
 	f, err := os.Create(fileName)
 	if err != nil {
@@ -0,0 +1,127 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_prober binary is a black-box monitor for Trillian logs. It
+// periodically submits a canary leaf to each configured log, waits for it to
+// be integrated, fetches and verifies inclusion and consistency proofs, and
+// exports the results as SLO metrics.
+//
+// Example usage:
+// $ ./trillian_prober --log_server=host:port --log_ids=1234,5678
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/rpcflags"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/util"
+	"github.com/google/trillian/util/clock"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logServerAddr = flag.String("log_server", "", "Address of the gRPC Trillian Log Server (host:port)")
+	logIDs        = flag.String("log_ids", "", "Comma-separated list of Trillian LogIDs to probe")
+	httpEndpoint  = flag.String("http_endpoint", "localhost:8093", "Endpoint for HTTP metrics (host:port)")
+	probeInterval = flag.Duration("probe_interval", time.Minute, "Time between probes of each log")
+	probeTimeout  = flag.Duration("probe_timeout", 5*time.Minute, "Time allowed for each probe, including waiting for the canary to be integrated")
+	canaryPrefix  = flag.String("canary_prefix", "", "Prefix of canary leaf data, defaults to trillian_prober and the host name")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	ids, err := parseLogIDs(*logIDs)
+	if err != nil {
+		klog.Exitf("Invalid --log_ids: %v", err)
+	}
+	if *canaryPrefix == "" {
+		host, _ := os.Hostname()
+		*canaryPrefix = "trillian_prober " + host
+	}
+
+	dialOpts, err := rpcflags.NewClientDialOptionsFromFlags()
+	if err != nil {
+		klog.Exitf("Failed to determine dial options: %v", err)
+	}
+	conn, err := grpc.NewClient(*logServerAddr, dialOpts...)
+	if err != nil {
+		klog.Exitf("Failed to dial %v: %v", *logServerAddr, err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Errorf("Close(): %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go util.AwaitSignal(ctx, cancel)
+
+	http.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: *httpEndpoint}
+	go func() {
+		klog.Infof("HTTP server starting on %v", *httpEndpoint)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Exitf("HTTP server failed: %v", err)
+		}
+	}()
+
+	metrics := newProberMetrics(prometheus.MetricFactory{})
+	logClient := trillian.NewTrillianLogClient(conn)
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		p := newProber(id, logClient, *probeTimeout, *canaryPrefix, metrics, clock.System)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.run(ctx, *probeInterval)
+		}()
+	}
+	wg.Wait()
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		klog.Errorf("HTTP server shutdown: %v", err)
+	}
+}
+
+// parseLogIDs parses a comma-separated list of log IDs.
+func parseLogIDs(s string) ([]int64, error) {
+	if s == "" {
+		return nil, errors.New("no log IDs")
+	}
+	var ids []int64
+	for _, f := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(f), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+// Stages of a probe, used to label failures.
+const (
+	stageSubmit      = "submit"
+	stageInclusion   = "inclusion"
+	stageProof       = "proof"
+	stageConsistency = "consistency"
+)
+
+// proberMetrics holds the SLO metrics exported by the prober.
+type proberMetrics struct {
+	probes       monitoring.Counter
+	failures     monitoring.Counter
+	submissions  monitoring.Counter
+	mergeDelay   monitoring.Histogram
+	proofLatency monitoring.Histogram
+}
+
+func newProberMetrics(mf monitoring.MetricFactory) *proberMetrics {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &proberMetrics{
+		probes:       mf.NewCounter("probes", "Number of probes started", "logid"),
+		failures:     mf.NewCounter("probe_failures", "Number of probes which failed, by the stage which failed", "logid", "stage"),
+		submissions:  mf.NewCounter("probe_submissions", "Number of canary leaf submissions, by result", "logid", "result"),
+		mergeDelay:   mf.NewHistogram("probe_merge_delay_seconds", "Time from submitting a canary leaf to verifying its inclusion", "logid"),
+		proofLatency: mf.NewHistogram("probe_proof_latency_seconds", "Time taken to fetch and verify a proof", "logid", "proof"),
+	}
+}
+
+// prober repeatedly submits canary leaves to a single log, and checks that
+// they are integrated and provable.
+type prober struct {
+	logID      int64
+	client     trillian.TrillianLogClient
+	logClient  *client.LogClient
+	timeout    time.Duration
+	prefix     string
+	metrics    *proberMetrics
+	timeSource clock.TimeSource
+}
+
+// newProber returns a prober for the given log. The first root the prober
+// sees is trusted, and each later root is verified to be consistent with it.
+func newProber(logID int64, c trillian.TrillianLogClient, timeout time.Duration, prefix string, metrics *proberMetrics, ts clock.TimeSource) *prober {
+	return &prober{
+		logID:      logID,
+		client:     c,
+		logClient:  client.New(logID, c, client.NewLogVerifier(rfc6962.DefaultHasher), types.LogRootV1{}),
+		timeout:    timeout,
+		prefix:     prefix,
+		metrics:    metrics,
+		timeSource: ts,
+	}
+}
+
+// run probes the log every interval until ctx is done.
+func (p *prober) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.probe(ctx); err != nil {
+			klog.Warningf("%d: probe failed: %v", p.logID, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe submits a single canary leaf, waits for it to be integrated, and
+// fetches inclusion and consistency proofs for it.
+func (p *prober) probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	label := strconv.FormatInt(p.logID, 10)
+	p.metrics.probes.Inc(label)
+
+	if _, err := p.logClient.UpdateRoot(ctx); err != nil {
+		p.metrics.failures.Inc(label, stageConsistency)
+		return fmt.Errorf("failed to update root: %v", err)
+	}
+	prevRoot := p.logClient.GetRoot()
+
+	data, err := p.canary()
+	if err != nil {
+		return err
+	}
+	submitted := p.timeSource.Now()
+	if err := p.logClient.QueueLeaf(ctx, data); err != nil {
+		p.metrics.submissions.Inc(label, "error")
+		p.metrics.failures.Inc(label, stageSubmit)
+		return fmt.Errorf("failed to queue canary: %v", err)
+	}
+	p.metrics.submissions.Inc(label, "ok")
+
+	if err := p.logClient.WaitForInclusion(ctx, data); err != nil {
+		p.metrics.failures.Inc(label, stageInclusion)
+		return fmt.Errorf("canary not integrated: %v", err)
+	}
+	p.metrics.mergeDelay.Observe(clock.SecondsSince(p.timeSource, submitted), label)
+	root := p.logClient.GetRoot()
+
+	start := p.timeSource.Now()
+	leafHash := rfc6962.DefaultHasher.HashLeaf(data)
+	resp, err := p.client.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{
+		LogId:    p.logID,
+		LeafHash: leafHash,
+		TreeSize: int64(root.TreeSize),
+	})
+	if err == nil && len(resp.Proof) == 0 {
+		err = fmt.Errorf("no inclusion proof at tree size %d", root.TreeSize)
+	}
+	for _, proof := range resp.GetProof() {
+		if err != nil {
+			break
+		}
+		err = p.logClient.VerifyInclusionByHash(root, leafHash, proof)
+	}
+	if err != nil {
+		p.metrics.failures.Inc(label, stageProof)
+		return fmt.Errorf("inclusion proof: %v", err)
+	}
+	p.metrics.proofLatency.Observe(clock.SecondsSince(p.timeSource, start), label, "inclusion")
+
+	if prevRoot.TreeSize == 0 || prevRoot.TreeSize >= root.TreeSize {
+		return nil
+	}
+	start = p.timeSource.Now()
+	cResp, err := p.client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          p.logID,
+		FirstTreeSize:  int64(prevRoot.TreeSize),
+		SecondTreeSize: int64(root.TreeSize),
+	})
+	if err == nil {
+		var rootBytes []byte
+		if rootBytes, err = root.MarshalBinary(); err == nil {
+			_, err = p.logClient.VerifyRoot(prevRoot, &trillian.SignedLogRoot{LogRoot: rootBytes}, cResp.GetProof().GetHashes())
+		}
+	}
+	if err != nil {
+		p.metrics.failures.Inc(label, stageConsistency)
+		return fmt.Errorf("consistency proof: %v", err)
+	}
+	p.metrics.proofLatency.Observe(clock.SecondsSince(p.timeSource, start), label, "consistency")
+	return nil
+}
+
+// canary returns unique leaf data for a probe.
+func (p *prober) canary() ([]byte, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return []byte(fmt.Sprintf("%s %d %s", p.prefix, p.timeSource.Now().UnixNano(), hex.EncodeToString(nonce))), nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/trilliantest"
+	"github.com/google/trillian/util/clock"
+)
+
+func TestProbe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := trilliantest.NewLogEnv(ctx, trilliantest.Config{SequencerInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+	tree, err := env.CreateLog(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+
+	metrics := newProberMetrics(monitoring.InertMetricFactory{})
+	p := newProber(tree.TreeId, env.Log, 10*time.Second, "test", metrics, clock.System)
+	for i := 0; i < 2; i++ {
+		if err := p.probe(ctx); err != nil {
+			t.Fatalf("probe() %d: %v", i, err)
+		}
+	}
+
+	label := strconv.FormatInt(tree.TreeId, 10)
+	if got, want := metrics.submissions.(*monitoring.InertFloat).Value(label, "ok"), 2.0; got != want {
+		t.Errorf("ok submissions = %v, want %v", got, want)
+	}
+	if n, _ := metrics.mergeDelay.(*monitoring.InertDistribution).Info(label); n != 2 {
+		t.Errorf("merge delay observations = %d, want 2", n)
+	}
+	// Only the second probe has an earlier non-empty root to be consistent with.
+	if n, _ := metrics.proofLatency.(*monitoring.InertDistribution).Info(label, "consistency"); n != 1 {
+		t.Errorf("consistency proof observations = %d, want 1", n)
+	}
+}
+
+func TestParseLogIDs(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "1", want: 1},
+		{in: "1, 2,3", want: 3},
+		{in: "", wantErr: true},
+		{in: "1,x", wantErr: true},
+	} {
+		got, err := parseLogIDs(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("parseLogIDs(%q) = %v, want error: %v", test.in, err, test.wantErr)
+		}
+		if len(got) != test.want && !test.wantErr {
+			t.Errorf("parseLogIDs(%q) = %v, want %d IDs", test.in, got, test.want)
+		}
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/trillian"
@@ -37,6 +39,7 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"k8s.io/klog/v2"
 )
 
@@ -46,6 +49,19 @@ var (
 	treeID          = flag.Int64("tree_id", 0, "The ID of the tree to be set updated")
 	treeState       = flag.String("tree_state", "", "If set the tree state will be updated")
 	treeType        = flag.String("tree_type", "", "If set the tree type will be updated")
+	autoFreeze      = flag.String("auto_freeze", "", "If set to true or false the tree's auto_freeze setting will be updated")
+	maxTreeSize     = flag.Int64("max_tree_size", -1, "If non-negative the tree's maximum size will be updated; zero means unlimited")
+	owner           = flag.String("owner", "", "If set the tree's owner will be updated")
+	contact         = flag.String("contact", "", "If set the tree's contact will be updated")
+	mutableExtra    = flag.String("mutable_extra_data", "", "If set to true or false the tree's mutable_extra_data setting will be updated")
+	allowRedaction  = flag.String("allow_redaction", "", "If set to true or false the tree's allow_redaction setting will be updated")
+	mergeDelay      = flag.Duration("merge_delay_target", -1, "If non-negative the tree's merge delay target will be updated; zero means none")
+	dequeueOrder    = flag.String("dequeue_order", "", "If set the order in which the tree's queued leaves are integrated will be updated")
+	disabledMethods = flag.String("disabled_methods", "", "If set the comma-separated TrillianLog RPCs which are refused for the tree will be updated; \"none\" enables them all")
+	allowPrehashed  = flag.String("allow_prehashed_leaves", "", "If set to true or false the tree's allow_prehashed_leaves setting will be updated")
+	leafValidators  = flag.String("leaf_validators", "", "If set the comma-separated leaf validators of the tree will be updated; \"none\" removes them all")
+	origin          = flag.String("checkpoint_origin", "", "If set the origin line of the tree's checkpoints will be updated; \"none\" removes it")
+	ecosystemIDs    = flag.String("ecosystem_ids", "", "If set the comma-separated <ecosystem>:<identifier> IDs of the tree will be updated; \"none\" removes them all")
 	printTree       = flag.Bool("print", false, "Print the resulting tree")
 )
 
@@ -84,6 +100,101 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 		paths = append(paths, "tree_type")
 	}
 
+	if len(*autoFreeze) > 0 {
+		v, err := strconv.ParseBool(*autoFreeze)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auto_freeze value: %v", *autoFreeze)
+		}
+		tree.AutoFreeze = v
+		paths = append(paths, "auto_freeze")
+	}
+
+	if *maxTreeSize >= 0 {
+		tree.MaxTreeSize = *maxTreeSize
+		paths = append(paths, "max_tree_size")
+	}
+
+	if len(*owner) > 0 {
+		tree.Owner = *owner
+		paths = append(paths, "owner")
+	}
+
+	if len(*contact) > 0 {
+		tree.Contact = *contact
+		paths = append(paths, "contact")
+	}
+
+	if len(*mutableExtra) > 0 {
+		v, err := strconv.ParseBool(*mutableExtra)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mutable_extra_data value: %v", *mutableExtra)
+		}
+		tree.MutableExtraData = v
+		paths = append(paths, "mutable_extra_data")
+	}
+
+	if len(*allowRedaction) > 0 {
+		v, err := strconv.ParseBool(*allowRedaction)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow_redaction value: %v", *allowRedaction)
+		}
+		tree.AllowRedaction = v
+		paths = append(paths, "allow_redaction")
+	}
+
+	if *mergeDelay >= 0 {
+		if *mergeDelay > 0 {
+			tree.MergeDelayTarget = durationpb.New(*mergeDelay)
+		}
+		paths = append(paths, "merge_delay_target")
+	}
+
+	if len(*dequeueOrder) > 0 {
+		v, ok := trillian.DequeueOrder_value[*dequeueOrder]
+		if !ok {
+			return nil, fmt.Errorf("invalid dequeue order: %v", *dequeueOrder)
+		}
+		tree.DequeueOrder = trillian.DequeueOrder(v)
+		paths = append(paths, "dequeue_order")
+	}
+
+	if len(*disabledMethods) > 0 {
+		if *disabledMethods != "none" {
+			tree.DisabledMethods = strings.Split(*disabledMethods, ",")
+		}
+		paths = append(paths, "disabled_methods")
+	}
+
+	if len(*allowPrehashed) > 0 {
+		v, err := strconv.ParseBool(*allowPrehashed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow_prehashed_leaves value: %v", *allowPrehashed)
+		}
+		tree.AllowPrehashedLeaves = v
+		paths = append(paths, "allow_prehashed_leaves")
+	}
+
+	if len(*leafValidators) > 0 {
+		if *leafValidators != "none" {
+			tree.LeafValidators = strings.Split(*leafValidators, ",")
+		}
+		paths = append(paths, "leaf_validators")
+	}
+
+	if len(*origin) > 0 {
+		if *origin != "none" {
+			tree.CheckpointOrigin = *origin
+		}
+		paths = append(paths, "checkpoint_origin")
+	}
+
+	if len(*ecosystemIDs) > 0 {
+		if *ecosystemIDs != "none" {
+			tree.EcosystemIds = strings.Split(*ecosystemIDs, ",")
+		}
+		paths = append(paths, "ecosystem_ids")
+	}
+
 	if len(paths) == 0 {
 		return nil, errors.New("nothing to change")
 	}
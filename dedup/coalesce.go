@@ -0,0 +1,155 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Coalescer merges concurrent QueueLeaf requests for the same leaf, so that
+// only the first of them reaches storage. The others wait for it, and receive
+// its leaf with an AlreadyExists status, as storage would have returned had
+// they been sent one after the other. If the first request fails, they receive
+// its error, unless it was cancelled or timed out while their own contexts are
+// still live, in which case they are sent to the handler themselves. Waiting
+// requests whose own contexts end stop waiting.
+//
+// Unlike the Interceptor, a Coalescer needs no Cache: it only remembers leaves
+// while their requests are in flight. It must run before any interceptor
+// which charges quota, so that the merged requests don't consume write quota.
+// Like the Interceptor, it checks that the log still accepts QueueLeaf
+// requests before answering a merged request with the leaf of the first.
+type Coalescer struct {
+	admin storage.AdminStorage
+
+	mu    sync.Mutex
+	calls map[string]*call
+
+	coalesced monitoring.Counter
+}
+
+// call is a QueueLeaf request in flight, and the requests waiting for it.
+type call struct {
+	done    chan struct{}
+	waiters int // Guarded by Coalescer.mu.
+
+	// Set before done is closed.
+	resp interface{}
+	err  error
+}
+
+// NewCoalescer returns a new Coalescer, which reads the trees of merged
+// requests from admin.
+func NewCoalescer(admin storage.AdminStorage, mf monitoring.MetricFactory) *Coalescer {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Coalescer{
+		admin: admin,
+		calls: make(map[string]*call),
+		coalesced: mf.NewCounter(
+			"dedup_coalesced_requests",
+			"Number of QueueLeaf requests answered with the result of a concurrent request for the same leaf",
+			"logid",
+		),
+	}
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (c *Coalescer) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	r, ok := req.(*trillian.QueueLeafRequest)
+	if !ok || r.Leaf == nil {
+		return handler(ctx, req)
+	}
+	key := identityKey(r.LogId, identityHash(r.Leaf))
+
+	c.mu.Lock()
+	if cl, ok := c.calls[key]; ok {
+		cl.waiters++
+		c.mu.Unlock()
+		return c.wait(ctx, r, cl, handler)
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	cl.resp, cl.err = handler(ctx, req)
+	c.mu.Lock()
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(cl.done)
+	return cl.resp, cl.err
+}
+
+// wait returns the result of the request r coalesced with cl.
+func (c *Coalescer) wait(ctx context.Context, r *trillian.QueueLeafRequest, cl *call, handler grpc.UnaryHandler) (interface{}, error) {
+	select {
+	case <-cl.done:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	// Requests sent to the handler after all aren't counted as coalesced.
+	label := strconv.FormatInt(r.LogId, 10)
+	if cl.err != nil {
+		if isContextErr(cl.err) && ctx.Err() == nil {
+			return handler(ctx, r)
+		}
+		c.coalesced.Inc(label)
+		return nil, cl.err
+	}
+	qr, ok := cl.resp.(*trillian.QueueLeafResponse)
+	if !ok || qr.QueuedLeaf.GetLeaf() == nil {
+		return handler(ctx, r)
+	}
+	if err := checkTree(ctx, c.admin, r.LogId); err != nil {
+		return nil, err
+	}
+	c.coalesced.Inc(label)
+	// Each caller gets its own response, as other interceptors may modify it.
+	return &trillian.QueueLeafResponse{
+		QueuedLeaf: &trillian.QueuedLogLeaf{
+			Leaf:   proto.Clone(qr.QueuedLeaf.Leaf).(*trillian.LogLeaf),
+			Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", qr.QueuedLeaf.Leaf.LeafIdentityHash).Proto(),
+		},
+	}, nil
+}
+
+// identityKey returns the key of the calls for the leaf with the given
+// identity hash.
+func identityKey(logID int64, id []byte) string {
+	return fmt.Sprintf("%d/%x", logID, id)
+}
+
+// isContextErr returns whether err reports a cancelled or expired context.
+func isContextErr(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	code := status.Code(err)
+	return code == codes.Canceled || code == codes.DeadlineExceeded
+}
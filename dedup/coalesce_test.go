@@ -0,0 +1,168 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// waiters returns the number of requests waiting for the request in flight
+// for the leaf with the given identity hash.
+func (c *Coalescer) waiters(logID int64, id []byte) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := identityKey(logID, id)
+	if cl, ok := c.calls[key]; ok {
+		return cl.waiters
+	}
+	return -1
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
+
+// result is the outcome of a call to UnaryInterceptor.
+type result struct {
+	resp interface{}
+	err  error
+}
+
+func TestCoalescer(t *testing.T) {
+	stored := &trillian.LogLeaf{LeafValue: []byte("value"), LeafIdentityHash: []byte("id"), LeafIndex: 5}
+
+	for _, test := range []struct {
+		desc string
+		// update modifies the tree before it is stored.
+		update func(*trillian.Tree)
+		// leaderErr is returned by the handler for the first request.
+		leaderErr error
+		// cancelLeader cancels the context of the first request.
+		cancelLeader  bool
+		wantHandled   int32
+		wantCode      codes.Code
+		wantCoalesced float64
+	}{
+		{desc: "coalesced", wantHandled: 1, wantCode: codes.AlreadyExists, wantCoalesced: 2},
+		{desc: "error-shared", leaderErr: status.Error(codes.Internal, "failed"), wantHandled: 1, wantCode: codes.Internal, wantCoalesced: 2},
+		{desc: "leader-cancelled", leaderErr: status.Error(codes.Canceled, "cancelled"), cancelLeader: true, wantHandled: 3},
+		// The handler is faked, so only the followers check the tree.
+		{desc: "frozen", update: func(tree *trillian.Tree) { tree.TreeState = trillian.TreeState_FROZEN }, wantHandled: 1, wantCode: codes.PermissionDenied},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			as, logID := newLog(t, test.update)
+			req := &trillian.QueueLeafRequest{LogId: logID, Leaf: &trillian.LogLeaf{LeafValue: []byte("value"), LeafIdentityHash: []byte("id")}}
+			c := NewCoalescer(as, monitoring.InertMetricFactory{})
+			release := make(chan struct{})
+			var handled atomic.Int32
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if handled.Add(1) == 1 {
+					<-release
+					if test.leaderErr != nil {
+						return nil, test.leaderErr
+					}
+				}
+				return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: stored}}, nil
+			}
+
+			leaderCtx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			leader := make(chan result, 1)
+			go func() {
+				resp, err := c.UnaryInterceptor(leaderCtx, req, nil, handler)
+				leader <- result{resp, err}
+			}()
+			waitFor(t, func() bool { return handled.Load() == 1 })
+
+			followers := make(chan result, 2)
+			for n := 0; n < 2; n++ {
+				go func() {
+					resp, err := c.UnaryInterceptor(context.Background(), proto.Clone(req), nil, handler)
+					followers <- result{resp, err}
+				}()
+			}
+			waitFor(t, func() bool { return c.waiters(logID, []byte("id")) == 2 })
+			if test.cancelLeader {
+				cancel()
+			}
+			close(release)
+
+			if r := <-leader; r.err != test.leaderErr {
+				t.Errorf("leader: got error %v, want %v", r.err, test.leaderErr)
+			}
+			for n := 0; n < 2; n++ {
+				r := <-followers
+				code := status.Code(r.err)
+				if qr, ok := r.resp.(*trillian.QueueLeafResponse); ok {
+					code = codes.Code(qr.QueuedLeaf.GetStatus().GetCode())
+					if !proto.Equal(qr.QueuedLeaf.Leaf, stored) {
+						t.Errorf("follower got leaf %v, want %v", qr.QueuedLeaf.Leaf, stored)
+					}
+				}
+				if code != test.wantCode {
+					t.Errorf("follower got code %v, want %v", code, test.wantCode)
+				}
+			}
+			if got := handled.Load(); got != test.wantHandled {
+				t.Errorf("handler called %d times, want %d", got, test.wantHandled)
+			}
+			if got := c.coalesced.Value(strconv.FormatInt(logID, 10)); got != test.wantCoalesced {
+				t.Errorf("%v requests counted as coalesced, want %v", got, test.wantCoalesced)
+			}
+			if got := c.waiters(logID, []byte("id")); got != -1 {
+				t.Errorf("request still in flight with %d waiters", got)
+			}
+		})
+	}
+}
+
+func TestCoalescerFollowerCancelled(t *testing.T) {
+	as, logID := newLog(t, nil)
+	c := NewCoalescer(as, nil)
+	req := &trillian.QueueLeafRequest{LogId: logID, Leaf: &trillian.LogLeaf{LeafValue: []byte("value")}}
+	release, started := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = c.UnaryInterceptor(context.Background(), req, nil, func(context.Context, interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			return &trillian.QueueLeafResponse{}, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.UnaryInterceptor(ctx, req, nil, nil); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("UnaryInterceptor(): %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dedup suppresses duplicate leaf submissions before they reach
+// storage.
+//
+// Popular logs receive many submissions of leaves they already contain. Left
+// alone, each duplicate consumes write quota and a storage transaction before
+// being rejected. The Interceptor in this package instead remembers the
+// LeafIdentityHash of recently submitted leaves in a Cache, and answers
+// duplicate QueueLeaf requests directly, in the same way that storage would.
+//
+// Caches may be layered, so that each server consults a small in-memory cache
+// before a cache shared between all servers, such as the one provided by the
+// mysqldedup package.
+//
+// The Coalescer in this package handles thundering herds of submissions of a
+// new leaf, which arrive before any Cache knows about it, by merging requests
+// for the same leaf while the first of them is in flight.
+package dedup
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// Cache records recently submitted leaves, keyed by tree ID and
+// LeafIdentityHash.
+type Cache interface {
+	// Get returns the leaf stored for the given identity hash, or nil if
+	// there is none.
+	Get(ctx context.Context, treeID int64, identityHash []byte) (*trillian.LogLeaf, error)
+	// Put records the leaf stored for the given identity hash.
+	Put(ctx context.Context, treeID int64, identityHash []byte, leaf *trillian.LogLeaf) error
+}
+
+// Layered returns a Cache which consults each of the given caches in order.
+// Leaves found in a later cache are added to the earlier ones, and new leaves
+// are added to all of them.
+func Layered(caches ...Cache) Cache {
+	return layered(caches)
+}
+
+type layered []Cache
+
+func (l layered) Get(ctx context.Context, treeID int64, identityHash []byte) (*trillian.LogLeaf, error) {
+	for i, c := range l {
+		leaf, err := c.Get(ctx, treeID, identityHash)
+		if err != nil {
+			return nil, err
+		}
+		if leaf == nil {
+			continue
+		}
+		for _, earlier := range l[:i] {
+			if err := earlier.Put(ctx, treeID, identityHash, leaf); err != nil {
+				return nil, err
+			}
+		}
+		return leaf, nil
+	}
+	return nil, nil
+}
+
+func (l layered) Put(ctx context.Context, treeID int64, identityHash []byte, leaf *trillian.LogLeaf) error {
+	for _, c := range l {
+		if err := c.Put(ctx, treeID, identityHash, leaf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
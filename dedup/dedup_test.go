@@ -0,0 +1,260 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	c := NewMemoryCache(2, time.Minute, ts)
+
+	leaf := func(s string) *trillian.LogLeaf { return &trillian.LogLeaf{LeafValue: []byte(s)} }
+	for _, k := range []string{"a", "b"} {
+		if err := c.Put(ctx, 1, []byte(k), leaf(k)); err != nil {
+			t.Fatalf("Put(%s): %v", k, err)
+		}
+	}
+	// Using "a" makes "b" the least recently used, so adding "c" evicts it.
+	if got, _ := c.Get(ctx, 1, []byte("a")); !proto.Equal(got, leaf("a")) {
+		t.Errorf("Get(a) = %v, want %v", got, leaf("a"))
+	}
+	if err := c.Put(ctx, 1, []byte("c"), leaf("c")); err != nil {
+		t.Fatalf("Put(c): %v", err)
+	}
+	if got, _ := c.Get(ctx, 1, []byte("b")); got != nil {
+		t.Errorf("Get(b) = %v, want nil after eviction", got)
+	}
+	if got, _ := c.Get(ctx, 2, []byte("a")); got != nil {
+		t.Errorf("Get(a) for other tree = %v, want nil", got)
+	}
+
+	ts.Set(ts.Now().Add(time.Minute))
+	if got, _ := c.Get(ctx, 1, []byte("c")); got != nil {
+		t.Errorf("Get(c) = %v, want nil after expiry", got)
+	}
+}
+
+// failingCache is a Cache which always fails.
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, int64, []byte) (*trillian.LogLeaf, error) {
+	return nil, errors.New("get failed")
+}
+
+func (failingCache) Put(context.Context, int64, []byte, *trillian.LogLeaf) error {
+	return errors.New("put failed")
+}
+
+// recordingCache is a Cache which records the marshalled leaves it is given,
+// and never finds any.
+type recordingCache struct {
+	stored [][]byte
+}
+
+func (c *recordingCache) Get(context.Context, int64, []byte) (*trillian.LogLeaf, error) {
+	return nil, nil
+}
+
+func (c *recordingCache) Put(_ context.Context, _ int64, _ []byte, leaf *trillian.LogLeaf) error {
+	b, err := proto.Marshal(leaf)
+	if err != nil {
+		return err
+	}
+	c.stored = append(c.stored, b)
+	return nil
+}
+
+func TestLayered(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryCache(10, 0, clock.System)
+	shared := NewMemoryCache(10, 0, clock.System)
+	c := Layered(local, shared)
+
+	leaf := &trillian.LogLeaf{LeafValue: []byte("value")}
+	if err := shared.Put(ctx, 1, []byte("id"), leaf); err != nil {
+		t.Fatalf("Put(): %v", err)
+	}
+	if got, err := c.Get(ctx, 1, []byte("id")); err != nil || !proto.Equal(got, leaf) {
+		t.Fatalf("Get() = %v, %v; want %v, nil", got, err, leaf)
+	}
+	if got, _ := local.Get(ctx, 1, []byte("id")); !proto.Equal(got, leaf) {
+		t.Errorf("Get() didn't populate earlier layer, got %v", got)
+	}
+	if _, err := Layered(local, failingCache{}).Get(ctx, 1, []byte("other")); err == nil {
+		t.Error("Get() with failing layer returned nil error")
+	}
+}
+
+// newLog returns admin storage holding a LOG tree, updated by update if it is
+// not nil, and the ID of the tree.
+func newLog(t *testing.T, update func(*trillian.Tree)) (storage.AdminStorage, int64) {
+	t.Helper()
+	ctx := context.Background()
+	as := memory.NewAdminStorage(memory.NewTreeStorage())
+	tree, err := storage.CreateTree(ctx, as, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if update != nil {
+		if _, err := storage.UpdateTree(ctx, as, tree.TreeId, update); err != nil {
+			t.Fatalf("UpdateTree(): %v", err)
+		}
+	}
+	return as, tree.TreeId
+}
+
+func TestInterceptor(t *testing.T) {
+	ctx := context.Background()
+	value := []byte("value")
+	stored := &trillian.LogLeaf{LeafValue: value, LeafIndex: 5}
+
+	for _, test := range []struct {
+		desc        string
+		cache       Cache
+		update      func(*trillian.Tree)
+		otherReq    bool
+		wantHandled int
+		wantCode    codes.Code
+		wantErr     codes.Code
+	}{
+		{
+			desc:        "other-request",
+			cache:       NewMemoryCache(10, 0, clock.System),
+			otherReq:    true,
+			wantHandled: 2,
+		},
+		{
+			desc:        "duplicate-suppressed",
+			cache:       NewMemoryCache(10, 0, clock.System),
+			wantHandled: 1,
+			wantCode:    codes.AlreadyExists,
+		},
+		{
+			desc:        "cache-failure",
+			cache:       failingCache{},
+			wantHandled: 2,
+		},
+		{
+			desc:        "duplicate-frozen",
+			cache:       NewMemoryCache(10, 0, clock.System),
+			update:      func(tree *trillian.Tree) { tree.TreeState = trillian.TreeState_FROZEN },
+			wantHandled: 1,
+			wantErr:     codes.PermissionDenied,
+		},
+		{
+			desc:        "duplicate-disabled",
+			cache:       NewMemoryCache(10, 0, clock.System),
+			update:      func(tree *trillian.Tree) { tree.DisabledMethods = []string{"QueueLeaf"} },
+			wantHandled: 1,
+			wantErr:     codes.PermissionDenied,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			as, logID := newLog(t, test.update)
+			var req interface{} = &trillian.QueueLeafRequest{LogId: logID, Leaf: &trillian.LogLeaf{LeafValue: value}}
+			if test.otherReq {
+				req = &trillian.GetLatestSignedLogRootRequest{LogId: logID}
+			}
+			i := NewInterceptor(test.cache, as, nil)
+			handled := 0
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled++
+				if _, ok := req.(*trillian.QueueLeafRequest); ok {
+					return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: stored}}, nil
+				}
+				return &trillian.GetLatestSignedLogRootResponse{}, nil
+			}
+
+			// The handler makes the tree checks itself, so the first request
+			// is cached whatever the state of the tree.
+			resp, err := i.UnaryInterceptor(ctx, req, nil, handler)
+			if err != nil {
+				t.Fatalf("UnaryInterceptor(): %v", err)
+			}
+			resp, err = i.UnaryInterceptor(ctx, req, nil, handler)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("UnaryInterceptor(): %v, want code %v", err, test.wantErr)
+			}
+			if handled != test.wantHandled {
+				t.Errorf("handler called %d times, want %d", handled, test.wantHandled)
+			}
+			if qr, ok := resp.(*trillian.QueueLeafResponse); ok {
+				if got := codes.Code(qr.QueuedLeaf.GetStatus().GetCode()); got != test.wantCode {
+					t.Errorf("second response has code %v, want %v", got, test.wantCode)
+				}
+				if !proto.Equal(qr.QueuedLeaf.Leaf, stored) {
+					t.Errorf("second response has leaf %v, want %v", qr.QueuedLeaf.Leaf, stored)
+				}
+			}
+		})
+	}
+
+	// Leaves of trees which are encrypted, or may be redacted, are not
+	// cached, so that their values aren't stored in the clear.
+	for _, update := range []func(*trillian.Tree){
+		func(tree *trillian.Tree) {
+			tree.LeafEncryption = &trillian.LeafEncryption{KeyUri: "local:k1", WrappedKey: []byte("wrapped")}
+		},
+		func(tree *trillian.Tree) { tree.AllowRedaction = true },
+	} {
+		as, logID := newLog(t, update)
+		cache := &recordingCache{}
+		req := &trillian.QueueLeafRequest{LogId: logID, Leaf: &trillian.LogLeaf{LeafValue: value}}
+		if _, err := NewInterceptor(cache, as, nil).UnaryInterceptor(ctx, req, nil, func(context.Context, interface{}) (interface{}, error) {
+			return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: stored}}, nil
+		}); err != nil {
+			t.Fatalf("UnaryInterceptor(): %v", err)
+		}
+		for _, b := range cache.stored {
+			if bytes.Contains(b, value) {
+				t.Errorf("cache stored %x, which holds the leaf value", b)
+			}
+		}
+	}
+
+	// Leaves with an explicit identity hash are keyed by it.
+	as, logID := newLog(t, nil)
+	cache := NewMemoryCache(10, 0, clock.System)
+	req := &trillian.QueueLeafRequest{LogId: logID, Leaf: &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: []byte("id")}}
+	if _, err := NewInterceptor(cache, as, nil).UnaryInterceptor(ctx, req, nil, func(context.Context, interface{}) (interface{}, error) {
+		return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: stored}}, nil
+	}); err != nil {
+		t.Fatalf("UnaryInterceptor(): %v", err)
+	}
+	if got, _ := cache.Get(ctx, logID, []byte("id")); got == nil {
+		t.Error("leaf not cached by identity hash")
+	}
+	if got, _ := cache.Get(ctx, logID, rfc6962.DefaultHasher.HashLeaf(value)); got != nil {
+		t.Error("leaf unexpectedly cached by Merkle leaf hash")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// Interceptor answers QueueLeaf requests for recently submitted leaves from a
+// Cache, without calling the handler.
+//
+// It must run before any interceptor which charges quota, such as the
+// TrillianInterceptor, so that duplicates don't consume write quota. As it
+// answers duplicates without the checks of the TrillianInterceptor and the
+// handler, it makes sure that the log still accepts QueueLeaf requests first.
+// Errors from the Cache are logged, and the request passed on to the handler.
+//
+// Leaves of trees with LeafEncryption or AllowRedaction are not cached, as
+// caches hold leaves in the clear and can't forget redacted leaves.
+type Interceptor struct {
+	cache    Cache
+	admin    storage.AdminStorage
+	requests monitoring.Counter
+}
+
+// NewInterceptor returns an Interceptor backed by cache, which reads the
+// trees of duplicates from admin.
+func NewInterceptor(cache Cache, admin storage.AdminStorage, mf monitoring.MetricFactory) *Interceptor {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Interceptor{
+		cache: cache,
+		admin: admin,
+		requests: mf.NewCounter(
+			"dedup_requests",
+			"Number of QueueLeaf requests checked for duplicates, by result",
+			"logid", "result",
+		),
+	}
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (i *Interceptor) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	r, ok := req.(*trillian.QueueLeafRequest)
+	if !ok || r.Leaf == nil {
+		return handler(ctx, req)
+	}
+	label := strconv.FormatInt(r.LogId, 10)
+	tree, err := storage.GetTree(ctx, i.admin, r.LogId)
+	if err != nil {
+		// The handler reports the error.
+		return handler(ctx, req)
+	}
+	if !cacheable(tree) {
+		i.requests.Inc(label, "uncacheable")
+		return handler(ctx, req)
+	}
+	id := identityHash(r.Leaf)
+
+	leaf, err := i.cache.Get(ctx, r.LogId, id)
+	switch {
+	case err != nil:
+		klog.Warningf("%d: dedup cache lookup failed: %v", r.LogId, err)
+		i.requests.Inc(label, "error")
+	case leaf != nil:
+		if err := checkTree(trees.NewContext(ctx, tree), i.admin, r.LogId); err != nil {
+			i.requests.Inc(label, "rejected")
+			return nil, err
+		}
+		i.requests.Inc(label, "duplicate")
+		return &trillian.QueueLeafResponse{
+			QueuedLeaf: &trillian.QueuedLogLeaf{
+				Leaf:   leaf,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", id).Proto(),
+			},
+		}, nil
+	default:
+		i.requests.Inc(label, "new")
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if qr, ok := resp.(*trillian.QueueLeafResponse); ok && qr.QueuedLeaf.GetLeaf() != nil {
+		if err := i.cache.Put(ctx, r.LogId, id, qr.QueuedLeaf.Leaf); err != nil {
+			klog.Warningf("%d: dedup cache update failed: %v", r.LogId, err)
+		}
+	}
+	return resp, nil
+}
+
+// cacheable returns whether the leaves of tree may be held in a Cache.
+func cacheable(tree *trillian.Tree) bool {
+	return tree.LeafEncryption == nil && !tree.AllowRedaction
+}
+
+// checkTree returns an error if the log with the given ID does not accept
+// QueueLeaf requests, because of its type or state, or because QueueLeaf is
+// one of its disabled_methods. These are the checks which the handler and the
+// TrillianInterceptor would make of a request answered without them. The tree
+// is read from ctx if it holds one.
+func checkTree(ctx context.Context, admin storage.AdminStorage, logID int64) error {
+	tree, err := trees.GetTree(ctx, admin, logID, trees.NewGetOpts(trees.QueueLog, trillian.TreeType_LOG))
+	if err != nil {
+		return err
+	}
+	if slices.Contains(tree.DisabledMethods, "QueueLeaf") {
+		return status.Errorf(codes.PermissionDenied, "QueueLeaf is disabled for tree %d", logID)
+	}
+	return nil
+}
+
+// identityHash returns the key of leaf for caching and coalescing. The key
+// only needs to be consistent within this package, so the RFC 6962 hasher is
+// used for leaves without a LeafIdentityHash, whatever the hasher of the tree.
+// Pre-hashed leaves have no LeafValue, so their MerkleLeafHash is used.
+func identityHash(leaf *trillian.LogLeaf) []byte {
+	if len(leaf.LeafIdentityHash) > 0 {
+		return leaf.LeafIdentityHash
+	}
+	if len(leaf.LeafValue) == 0 && len(leaf.MerkleLeafHash) > 0 {
+		return leaf.MerkleLeafHash
+	}
+	return rfc6962.DefaultHasher.HashLeaf(leaf.LeafValue)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/protobuf/proto"
+)
+
+type memoryKey struct {
+	treeID       int64
+	identityHash string
+}
+
+type memoryEntry struct {
+	key     memoryKey
+	leaf    *trillian.LogLeaf
+	expires time.Time
+}
+
+// MemoryCache is an in-memory Cache which holds up to a fixed number of
+// leaves, evicting the least recently used. It is safe for concurrent use.
+type MemoryCache struct {
+	size       int
+	ttl        time.Duration
+	timeSource clock.TimeSource
+
+	mu      sync.Mutex
+	entries map[memoryKey]*list.Element
+	lru     *list.List // Of *memoryEntry, most recently used first.
+}
+
+// NewMemoryCache returns a MemoryCache holding up to size leaves. Leaves are
+// forgotten ttl after they are added, unless ttl is zero.
+func NewMemoryCache(size int, ttl time.Duration, timeSource clock.TimeSource) *MemoryCache {
+	return &MemoryCache{
+		size:       size,
+		ttl:        ttl,
+		timeSource: timeSource,
+		entries:    make(map[memoryKey]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(_ context.Context, treeID int64, identityHash []byte) (*trillian.LogLeaf, error) {
+	key := memoryKey{treeID: treeID, identityHash: string(identityHash)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	e := elem.Value.(*memoryEntry)
+	if c.ttl > 0 && !c.timeSource.Now().Before(e.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, nil
+	}
+	c.lru.MoveToFront(elem)
+	return proto.Clone(e.leaf).(*trillian.LogLeaf), nil
+}
+
+// Put implements Cache.
+func (c *MemoryCache) Put(_ context.Context, treeID int64, identityHash []byte, leaf *trillian.LogLeaf) error {
+	if c.size <= 0 {
+		return nil
+	}
+	key := memoryKey{treeID: treeID, identityHash: string(identityHash)}
+	e := &memoryEntry{
+		key:     key,
+		leaf:    proto.Clone(leaf).(*trillian.LogLeaf),
+		expires: c.timeSource.Now().Add(c.ttl),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
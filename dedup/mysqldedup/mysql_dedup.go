@@ -0,0 +1,113 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mysqldedup provides a dedup.Cache stored in MySQL, which can be
+// shared between all of the log servers using a database.
+package mysqldedup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+)
+
+const (
+	selectLeafSQL = `SELECT Leaf FROM RecentSubmissions
+		WHERE TreeId = ? AND LeafIdentityHash = ? AND SubmitTimeMillis > ?`
+	replaceLeafSQL = `REPLACE INTO RecentSubmissions(TreeId, LeafIdentityHash, Leaf, SubmitTimeMillis)
+		VALUES(?, ?, ?, ?)`
+	deleteExpiredSQL = "DELETE FROM RecentSubmissions WHERE SubmitTimeMillis <= ?"
+)
+
+// Cache is a dedup.Cache backed by the RecentSubmissions table.
+type Cache struct {
+	db         *sql.DB
+	ttl        time.Duration
+	timeSource clock.TimeSource
+}
+
+// New returns a Cache which remembers leaves for ttl, which must be positive.
+// Expired rows are ignored, but are only removed from the table by Prune.
+func New(db *sql.DB, ttl time.Duration, timeSource clock.TimeSource) (*Cache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("dedup TTL must be positive, got %v", ttl)
+	}
+	return &Cache{db: db, ttl: ttl, timeSource: timeSource}, nil
+}
+
+// Get implements dedup.Cache.
+func (c *Cache) Get(ctx context.Context, treeID int64, identityHash []byte) (*trillian.LogLeaf, error) {
+	var b []byte
+	err := c.db.QueryRowContext(ctx, selectLeafSQL, treeID, identityHash, c.cutoff()).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	leaf := &trillian.LogLeaf{}
+	if err := proto.Unmarshal(b, leaf); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal leaf: %v", err)
+	}
+	return leaf, nil
+}
+
+// Put implements dedup.Cache.
+func (c *Cache) Put(ctx context.Context, treeID int64, identityHash []byte, leaf *trillian.LogLeaf) error {
+	b, err := proto.Marshal(leaf)
+	if err != nil {
+		return fmt.Errorf("failed to marshal leaf: %v", err)
+	}
+	_, err = c.db.ExecContext(ctx, replaceLeafSQL, treeID, identityHash, b, c.timeSource.Now().UnixMilli())
+	return err
+}
+
+// Prune deletes expired rows, and returns how many were deleted.
+func (c *Cache) Prune(ctx context.Context) (int64, error) {
+	res, err := c.db.ExecContext(ctx, deleteExpiredSQL, c.cutoff())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Run calls Prune every interval until ctx is done.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if n, err := c.Prune(ctx); err != nil {
+			klog.Warningf("Failed to prune RecentSubmissions: %v", err)
+		} else if n > 0 {
+			klog.V(1).Infof("Pruned %d expired rows from RecentSubmissions", n)
+		}
+	}
+}
+
+// cutoff returns the submission time in milliseconds at or before which rows
+// have expired.
+func (c *Cache) cutoff() int64 {
+	return c.timeSource.Now().Add(-c.ttl).UnixMilli()
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysqldedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/dedup/mysqldedup"
+	"github.com/google/trillian/storage/testdb"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCache(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+	ctx := context.Background()
+
+	db, done, err := testdb.NewTrillianDB(ctx, testdb.DriverMySQL)
+	if err != nil {
+		t.Fatalf("NewTrillianDB() returned err = %v", err)
+	}
+	defer done(ctx)
+
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	c, err := mysqldedup.New(db, time.Minute, ts)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+
+	id := []byte("identity")
+	leaf := &trillian.LogLeaf{LeafValue: []byte("value"), LeafIdentityHash: id, LeafIndex: 3}
+	if got, err := c.Get(ctx, 1, id); err != nil || got != nil {
+		t.Fatalf("Get() before Put = %v, %v; want nil, nil", got, err)
+	}
+	if err := c.Put(ctx, 1, id, leaf); err != nil {
+		t.Fatalf("Put(): %v", err)
+	}
+	got, err := c.Get(ctx, 1, id)
+	if err != nil {
+		t.Fatalf("Get(): %v", err)
+	}
+	if !proto.Equal(got, leaf) {
+		t.Errorf("Get() = %v, want %v", got, leaf)
+	}
+	if got, err := c.Get(ctx, 2, id); err != nil || got != nil {
+		t.Errorf("Get() for other tree = %v, %v; want nil, nil", got, err)
+	}
+
+	ts.Set(ts.Now().Add(time.Minute))
+	if got, err := c.Get(ctx, 1, id); err != nil || got != nil {
+		t.Errorf("Get() after expiry = %v, %v; want nil, nil", got, err)
+	}
+	if n, err := c.Prune(ctx); err != nil || n != 1 {
+		t.Errorf("Prune() = %d, %v; want 1, nil", n, err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/envelope"
 	"github.com/google/trillian/util/election2"
 )
 
@@ -37,6 +38,9 @@ type Registry struct {
 	QuotaManager quota.Manager
 	// MetricFactory provides metrics for monitoring.
 	monitoring.MetricFactory
+	// LeafKeyWrapper, if set, wraps the data keys of trees created with
+	// LeafEncryption.
+	LeafKeyWrapper envelope.KeyWrapper
 	// SetProcessStatus sets the current process status for diagnostic purposes.
 	SetProcessStatus func(string)
 }
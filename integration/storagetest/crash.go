@@ -0,0 +1,306 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	storageto "github.com/google/trillian/storage/testonly"
+)
+
+// KillPoint identifies the point in an integration transaction at which the
+// signer process is simulated to die.
+type KillPoint int
+
+const (
+	// KillAfterDequeue kills the signer after it dequeues a batch of leaves.
+	KillAfterDequeue KillPoint = iota
+	// KillAfterUpdateSequencedLeaves kills the signer after it assigns
+	// sequence numbers to the dequeued leaves.
+	KillAfterUpdateSequencedLeaves
+	// KillAfterSetMerkleNodes kills the signer after it writes the updated
+	// Merkle tree nodes.
+	KillAfterSetMerkleNodes
+	// KillAfterStoreSignedLogRoot kills the signer after it stores the new
+	// log root, but before the transaction commits.
+	KillAfterStoreSignedLogRoot
+)
+
+// String returns the name of the kill point.
+func (k KillPoint) String() string {
+	switch k {
+	case KillAfterDequeue:
+		return "AfterDequeue"
+	case KillAfterUpdateSequencedLeaves:
+		return "AfterUpdateSequencedLeaves"
+	case KillAfterSetMerkleNodes:
+		return "AfterSetMerkleNodes"
+	case KillAfterStoreSignedLogRoot:
+		return "AfterStoreSignedLogRoot"
+	}
+	return fmt.Sprintf("KillPoint(%d)", int(k))
+}
+
+// crashBatchSize is the number of leaves the signer integrates per batch.
+const crashBatchSize = 5
+
+// errKilled is returned by every transaction operation after the kill point,
+// as if the process had died.
+var errKilled = errors.New("storagetest: simulated process death")
+
+// RunCrashConsistencyTests checks that a log recovers to a consistent tree if
+// the signer dies at any point of an integration transaction. For each
+// KillPoint, it integrates a batch of leaves with the transaction failing at
+// that point, and then checks that the log root is unchanged and that a
+// normal signer run integrates every queued leaf exactly once, with Merkle
+// nodes that match the new root.
+//
+// The storage must roll back every write of a failed transaction, so these
+// tests don't apply to the memory storage.
+func RunCrashConsistencyTests(t *testing.T, storageFactory LogStorageFactory) {
+	ctx := context.Background()
+	log.InitMetrics(nil)
+	for _, kp := range []KillPoint{KillAfterDequeue, KillAfterUpdateSequencedLeaves, KillAfterSetMerkleNodes, KillAfterStoreSignedLogRoot} {
+		s, as := storageFactory(ctx, t)
+		t.Run(kp.String(), func(t *testing.T) { testCrashConsistency(ctx, t, s, as, kp) })
+	}
+}
+
+func testCrashConsistency(ctx context.Context, t *testing.T, s storage.LogStorage, as storage.AdminStorage, kp KillPoint) {
+	const (
+		initialLeaves = 10
+		crashedLeaves = 7
+	)
+	tree := mustCreateTree(ctx, t, as, storageto.LogTree)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, &types.LogRootV1{RootHash: rfc6962.DefaultHasher.EmptyRoot()})
+	ts := clock.System
+	queueTime := ts.Now().Add(-time.Minute)
+
+	leaves := createTestLeaves(initialLeaves+crashedLeaves, 0)
+	if _, err := s.QueueLeaves(ctx, tree, leaves[:initialLeaves], queueTime); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	mustIntegrate(ctx, t, tree, s, initialLeaves)
+	before := mustGetLogRoot(ctx, t, s, tree)
+
+	if _, err := s.QueueLeaves(ctx, tree, leaves[initialLeaves:], queueTime); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	crashing := &crashingLogStorage{LogStorage: s, kp: kp}
+	if _, err := log.IntegrateBatch(ctx, tree, crashBatchSize, 0, 0, ts, crashing, quota.Noop()); err == nil || !crashing.killed {
+		t.Fatalf("IntegrateBatch() with kill point %v: %v, want signer killed", kp, err)
+	}
+	if got := mustGetLogRoot(ctx, t, s, tree); got.TreeSize != before.TreeSize || !bytes.Equal(got.RootHash, before.RootHash) {
+		t.Fatalf("Log root changed by killed signer: got size %d hash %x, want size %d hash %x", got.TreeSize, got.RootHash, before.TreeSize, before.RootHash)
+	}
+
+	mustIntegrate(ctx, t, tree, s, initialLeaves+crashedLeaves)
+	mustBeConsistent(ctx, t, s, tree, leaves)
+}
+
+// mustIntegrate runs the signer until the tree reaches the given size.
+func mustIntegrate(ctx context.Context, t *testing.T, tree *trillian.Tree, s storage.LogStorage, size uint64) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if _, err := log.IntegrateBatch(ctx, tree, crashBatchSize, 0, 0, clock.System, s, quota.Noop()); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		if got := mustGetLogRoot(ctx, t, s, tree).TreeSize; got == size {
+			return
+		} else if got > size {
+			t.Fatalf("Tree size %d, want %d", got, size)
+		}
+	}
+	t.Fatalf("Tree did not reach size %d", size)
+}
+
+func mustGetLogRoot(ctx context.Context, t *testing.T, s storage.LogStorage, tree *trillian.Tree) *types.LogRootV1 {
+	t.Helper()
+	var root types.LogRootV1
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		if slr == nil {
+			return errors.New("no log root")
+		}
+		return root.UnmarshalBinary(slr.LogRoot)
+	}); err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	return &root
+}
+
+// mustBeConsistent checks that the tree contains each of the leaves exactly
+// once, and that its root hash and stored Merkle nodes match its leaves.
+func mustBeConsistent(ctx context.Context, t *testing.T, s storage.LogStorage, tree *trillian.Tree, want []*trillian.LogLeaf) {
+	t.Helper()
+	root := mustGetLogRoot(ctx, t, s, tree)
+	if got, want := root.TreeSize, uint64(len(want)); got != want {
+		t.Fatalf("TreeSize=%d, want %d", got, want)
+	}
+
+	var leaves []*trillian.LogLeaf
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		var err error
+		if leaves, err = tx.GetLeavesByRange(ctx, 0, int64(root.TreeSize)); err != nil {
+			return err
+		}
+		if len(leaves) != len(want) {
+			return fmt.Errorf("got %d leaves, want %d", len(leaves), len(want))
+		}
+
+		rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+		cr := rf.NewEmptyRange(0)
+		var ids []compact.NodeID
+		hashes := make(map[compact.NodeID][]byte)
+		visit := func(id compact.NodeID, hash []byte) {
+			ids = append(ids, id)
+			hashes[id] = hash
+		}
+		for _, leaf := range leaves {
+			if err := cr.Append(leaf.MerkleLeafHash, visit); err != nil {
+				return err
+			}
+		}
+		rootHash, err := cr.GetRootHash(nil)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(rootHash, root.RootHash) {
+			return fmt.Errorf("root hash %x does not match the leaves, want %x", root.RootHash, rootHash)
+		}
+
+		nodes, err := tx.GetMerkleNodes(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for i, node := range nodes {
+			if want := hashes[ids[i]]; !bytes.Equal(node.Hash, want) {
+				return fmt.Errorf("node %+v has hash %x, want %x", ids[i], node.Hash, want)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Tree is inconsistent after recovery: %v", err)
+	}
+
+	seen := make(map[string]int64)
+	for _, leaf := range leaves {
+		if idx, ok := seen[string(leaf.LeafIdentityHash)]; ok {
+			t.Errorf("Leaf %x integrated at both %d and %d", leaf.LeafIdentityHash, idx, leaf.LeafIndex)
+		}
+		seen[string(leaf.LeafIdentityHash)] = leaf.LeafIndex
+	}
+	for _, leaf := range want {
+		if _, ok := seen[string(leaf.LeafIdentityHash)]; !ok {
+			t.Errorf("Leaf %x was lost", leaf.LeafIdentityHash)
+		}
+	}
+}
+
+// crashingLogStorage is a LogStorage whose read-write transactions fail from
+// the kill point onwards. Once killed, it fails all later transactions too,
+// including any retries by the storage implementation.
+type crashingLogStorage struct {
+	storage.LogStorage
+	kp     KillPoint
+	killed bool
+}
+
+func (c *crashingLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return c.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if c.killed {
+			return errKilled
+		}
+		return f(ctx, &crashingLogTX{LogTreeTX: tx, c: c})
+	})
+}
+
+// crashingLogTX performs operations up to and including the one at the kill
+// point, and fails every operation after that. In particular, it fails the
+// operation at the kill point despite performing it, so the caller sees the
+// side effects of the operation without learning of its success.
+type crashingLogTX struct {
+	storage.LogTreeTX
+	c *crashingLogStorage
+}
+
+// after returns errKilled if the storage has reached its kill point, or the
+// result of the operation otherwise.
+func (t *crashingLogTX) after(kp KillPoint, err error) error {
+	if err == nil && kp == t.c.kp {
+		t.c.killed = true
+	}
+	if t.c.killed {
+		return errKilled
+	}
+	return err
+}
+
+func (t *crashingLogTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	if t.c.killed {
+		return nil, errKilled
+	}
+	leaves, err := t.LogTreeTX.DequeueLeaves(ctx, limit, cutoff)
+	if err := t.after(KillAfterDequeue, err); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func (t *crashingLogTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	if t.c.killed {
+		return errKilled
+	}
+	return t.after(KillAfterUpdateSequencedLeaves, t.LogTreeTX.UpdateSequencedLeaves(ctx, leaves))
+}
+
+func (t *crashingLogTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	if t.c.killed {
+		return errKilled
+	}
+	return t.after(KillAfterSetMerkleNodes, t.LogTreeTX.SetMerkleNodes(ctx, nodes))
+}
+
+func (t *crashingLogTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	if t.c.killed {
+		return errKilled
+	}
+	return t.after(KillAfterStoreSignedLogRoot, t.LogTreeTX.StoreSignedLogRoot(ctx, root))
+}
+
+func (t *crashingLogTX) Commit(ctx context.Context) error {
+	if t.c.killed {
+		return errKilled
+	}
+	return t.LogTreeTX.Commit(ctx)
+}
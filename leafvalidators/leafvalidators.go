@@ -0,0 +1,172 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leafvalidators holds the registry of the validators which check the
+// leaves queued to logs, so that personalities can have the log server reject
+// leaves which are obviously malformed rather than queueing them.
+//
+// A validator applies to the trees which name it in their leaf_validators,
+// and to all trees of the types it is registered for.
+package leafvalidators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/trillian"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the domain of the ErrorInfo details of rejected leaves.
+const Domain = "trillian.leafvalidators"
+
+// Validator checks a leaf queued to tree, and returns an error if the leaf is
+// to be rejected, preferably a *Rejection. Validators run before the leaf is
+// hashed, so they must be cheap.
+type Validator func(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error
+
+// Rejection describes why a leaf is rejected.
+type Rejection struct {
+	// Reason is a machine-readable UPPER_SNAKE_CASE identifier of the
+	// problem, e.g. "MALFORMED_CERTIFICATE".
+	Reason string
+	// Field is the path of the invalid field of the leaf, e.g. "leaf_value".
+	Field string
+	// Description explains the problem to humans.
+	Description string
+}
+
+func (r *Rejection) Error() string {
+	if r.Field == "" {
+		return r.Description
+	}
+	return fmt.Sprintf("%s: %s", r.Field, r.Description)
+}
+
+var (
+	vMu     sync.RWMutex
+	vByName = make(map[string]Validator)
+	vByType = make(map[trillian.TreeType][]string)
+)
+
+// Register registers v under name, so that trees which name it in their
+// leaf_validators use it. It is meant to be called from the init function of
+// the package which provides the validator.
+func Register(name string, v Validator) error {
+	vMu.Lock()
+	defer vMu.Unlock()
+	return register(name, v)
+}
+
+// RegisterForTreeType registers v under name, like Register, and also applies
+// it to all trees of type tt, whether or not they name it.
+func RegisterForTreeType(tt trillian.TreeType, name string, v Validator) error {
+	vMu.Lock()
+	defer vMu.Unlock()
+	if err := register(name, v); err != nil {
+		return err
+	}
+	vByType[tt] = append(vByType[tt], name)
+	return nil
+}
+
+func register(name string, v Validator) error {
+	if name == "" {
+		return errors.New("leaf validator name must not be empty")
+	}
+	if _, exists := vByName[name]; exists {
+		return fmt.Errorf("leaf validator %v already registered", name)
+	}
+	vByName[name] = v
+	return nil
+}
+
+// Check returns an error if any of names isn't a registered validator.
+func Check(names []string) error {
+	vMu.RLock()
+	defer vMu.RUnlock()
+	for _, name := range names {
+		if _, ok := vByName[name]; !ok {
+			return fmt.Errorf("no such leaf validator %v", name)
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of all registered validators.
+func Names() []string {
+	vMu.RLock()
+	defer vMu.RUnlock()
+
+	r := make([]string, 0, len(vByName))
+	for k := range vByName {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// Validate runs the validators of tree on leaf, those of its type first. If
+// one rejects the leaf, it returns an InvalidArgument error with ErrorInfo
+// details naming the validator and reason, and BadRequest details naming the
+// invalid field. It returns FailedPrecondition if the tree names a validator
+// which isn't registered in this binary.
+func Validate(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	vMu.RLock()
+	names := append(append([]string(nil), vByType[tree.TreeType]...), tree.LeafValidators...)
+	validators := make([]Validator, len(names))
+	for i, name := range names {
+		validators[i] = vByName[name]
+	}
+	vMu.RUnlock()
+
+	for i, v := range validators {
+		if v == nil {
+			return status.Errorf(codes.FailedPrecondition, "tree %d uses unregistered leaf validator %v", tree.TreeId, names[i])
+		}
+		if err := v(ctx, tree, leaf); err != nil {
+			return rejected(names[i], err)
+		}
+	}
+	return nil
+}
+
+// rejected returns the InvalidArgument error for a leaf which the validator
+// called name rejected with err.
+func rejected(name string, err error) error {
+	var r *Rejection
+	if !errors.As(err, &r) {
+		r = &Rejection{Reason: "INVALID_LEAF", Description: err.Error()}
+	}
+	st := status.Newf(codes.InvalidArgument, "leaf rejected by %v: %v", name, err)
+	info := &errdetails.ErrorInfo{Reason: r.Reason, Domain: Domain, Metadata: map[string]string{"validator": name}}
+	var withDetails *status.Status
+	var detailsErr error
+	if r.Field != "" {
+		withDetails, detailsErr = st.WithDetails(info, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: r.Field, Description: r.Description}},
+		})
+	} else {
+		withDetails, detailsErr = st.WithDetails(info)
+	}
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
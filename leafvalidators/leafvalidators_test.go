@@ -0,0 +1,108 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leafvalidators
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegister(t *testing.T) {
+	v := func(context.Context, *trillian.Tree, *trillian.LogLeaf) error { return nil }
+	if err := Register("Accept", v); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+	if err := Register("Accept", v); err == nil {
+		t.Error("Register() of a duplicate succeeded, want error")
+	}
+	if err := RegisterForTreeType(trillian.TreeType_LOG, "Accept", v); err == nil {
+		t.Error("RegisterForTreeType() of a duplicate succeeded, want error")
+	}
+	if err := Register("", v); err == nil {
+		t.Error("Register() with an empty name succeeded, want error")
+	}
+	if err := Check([]string{"Accept"}); err != nil {
+		t.Errorf("Check(Accept): %v", err)
+	}
+	if err := Check([]string{"Accept", "NoSuchValidator"}); err == nil {
+		t.Error("Check(NoSuchValidator) succeeded, want error")
+	}
+	if names := Names(); !slices.Contains(names, "Accept") || !slices.IsSorted(names) {
+		t.Errorf("Names() = %v, want sorted names including Accept", names)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	if err := RegisterForTreeType(trillian.TreeType_PREORDERED_LOG, "NotEmpty", func(_ context.Context, _ *trillian.Tree, leaf *trillian.LogLeaf) error {
+		if len(leaf.LeafValue) == 0 {
+			return errors.New("empty leaf")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("RegisterForTreeType(): %v", err)
+	}
+	if err := Register("NoLlamas", func(_ context.Context, _ *trillian.Tree, leaf *trillian.LogLeaf) error {
+		if bytes.Contains(leaf.LeafValue, []byte("llama")) {
+			return &Rejection{Reason: "LLAMA", Field: "leaf_value", Description: "llamas aren't allowed"}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc       string
+		tree       *trillian.Tree
+		value      string
+		wantCode   codes.Code
+		wantReason string
+		wantField  string
+	}{
+		{desc: "none", tree: &trillian.Tree{TreeType: trillian.TreeType_LOG}},
+		{desc: "named-ok", tree: &trillian.Tree{TreeType: trillian.TreeType_LOG, LeafValidators: []string{"NoLlamas"}}, value: "alpaca"},
+		{desc: "named-rejected", tree: &trillian.Tree{TreeType: trillian.TreeType_LOG, LeafValidators: []string{"NoLlamas"}}, value: "llama", wantCode: codes.InvalidArgument, wantReason: "LLAMA", wantField: "leaf_value"},
+		{desc: "other-type", tree: &trillian.Tree{TreeType: trillian.TreeType_LOG}, value: ""},
+		{desc: "type-rejected", tree: &trillian.Tree{TreeType: trillian.TreeType_PREORDERED_LOG}, value: "", wantCode: codes.InvalidArgument, wantReason: "INVALID_LEAF"},
+		{desc: "unregistered", tree: &trillian.Tree{TreeType: trillian.TreeType_LOG, LeafValidators: []string{"NoSuchValidator"}}, value: "alpaca", wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := Validate(ctx, test.tree, &trillian.LogLeaf{LeafValue: []byte(test.value)})
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("Validate(): %v, want code %v", err, test.wantCode)
+			}
+			var gotReason, gotField string
+			for _, d := range status.Convert(err).Details() {
+				switch d := d.(type) {
+				case *errdetails.ErrorInfo:
+					gotReason = d.Reason
+				case *errdetails.BadRequest:
+					gotField = d.FieldViolations[0].Field
+				}
+			}
+			if gotReason != test.wantReason || gotField != test.wantField {
+				t.Errorf("Validate() details have reason %q and field %q, want %q and %q", gotReason, gotField, test.wantReason, test.wantField)
+			}
+		})
+	}
+}
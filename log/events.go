@@ -0,0 +1,70 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// SignerID identifies this signer instance in the integration events it
+	// records. It defaults to the host name and process ID.
+	SignerID = defaultSignerID()
+
+	// IntegrationEventRetention is how long the integration events of each
+	// tree are kept for, where the storage records them. Events are not
+	// recorded if it is 0.
+	IntegrationEventRetention = 7 * 24 * time.Hour
+)
+
+func defaultSignerID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s.%d", hostname, os.Getpid())
+}
+
+// recordIntegration records the run which started at start and stored root,
+// having integrated numLeaves leaves, if the storage supports it.
+func recordIntegration(ctx context.Context, tx storage.LogTreeTX, start time.Time, numLeaves int, root *types.LogRootV1) error {
+	w, ok := tx.(storage.IntegrationEventWriter)
+	if !ok || IntegrationEventRetention <= 0 {
+		return nil
+	}
+	now := time.Unix(0, int64(root.TimestampNanos))
+	event := &trillian.IntegrationEvent{
+		StartTime: timestamppb.New(start),
+		Duration:  durationpb.New(now.Sub(start)),
+		BatchSize: int64(numLeaves),
+		TreeSize:  root.TreeSize,
+		RootHash:  root.RootHash,
+		Signer:    SignerID,
+	}
+	err := w.AddIntegrationEvent(ctx, event, now.Add(-IntegrationEventRetention))
+	if status.Code(err) == codes.Unimplemented {
+		// Wrapped storage which doesn't record events.
+		return nil
+	}
+	return err
+}
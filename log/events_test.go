@@ -0,0 +1,87 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+func TestIntegrationEvents(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	defer func(id string, retention time.Duration) {
+		SignerID, IntegrationEventRetention = id, retention
+	}(SignerID, IntegrationEventRetention)
+	SignerID = "signer-1"
+	IntegrationEventRetention = time.Hour
+
+	tree, ls := newMemoryLog(ctx, t, 25)
+	ts := clock.NewFake(time.Now().Add(time.Minute))
+	for _, limit := range []int{10, 5} {
+		if _, err := IntegrateBatch(ctx, tree, limit, 0, 0, ts, ls, quota.Noop()); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		ts.Set(ts.Now().Add(time.Minute))
+	}
+
+	events := listIntegrationEvents(ctx, t, tree, ls, time.Time{})
+	if got, want := len(events), 2; got != want {
+		t.Fatalf("ListIntegrationEvents() returned %d events, want %d", got, want)
+	}
+	// Most recent first.
+	for i, want := range []struct {
+		batchSize int64
+		treeSize  uint64
+	}{{5, 15}, {10, 10}} {
+		if got := events[i]; got.BatchSize != want.batchSize || got.TreeSize != want.treeSize || got.Signer != "signer-1" || len(got.RootHash) == 0 {
+			t.Errorf("ListIntegrationEvents()[%d] = %v, want batch size %d, tree size %d", i, got, want.batchSize, want.treeSize)
+		}
+	}
+	if got := listIntegrationEvents(ctx, t, tree, ls, events[0].StartTime.AsTime()); len(got) != 1 || got[0].TreeSize != 10 {
+		t.Errorf("ListIntegrationEvents(before) = %v, want the first event", got)
+	}
+
+	// Events older than the retention period are dropped as new ones are
+	// added.
+	ts.Set(ts.Now().Add(2 * time.Hour))
+	if _, err := IntegrateBatch(ctx, tree, 10, 0, 0, ts, ls, quota.Noop()); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	if got := listIntegrationEvents(ctx, t, tree, ls, time.Time{}); len(got) != 1 || got[0].TreeSize != 25 {
+		t.Errorf("ListIntegrationEvents() = %v, want only the latest event", got)
+	}
+}
+
+func listIntegrationEvents(ctx context.Context, t *testing.T, tree *trillian.Tree, ls storage.LogStorage, before time.Time) []*trillian.IntegrationEvent {
+	t.Helper()
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	events, err := tx.(storage.IntegrationEventReader).ListIntegrationEvents(ctx, before, 10)
+	if err != nil {
+		t.Fatalf("ListIntegrationEvents(): %v", err)
+	}
+	return events
+}
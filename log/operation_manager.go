@@ -19,6 +19,7 @@ package log
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
@@ -46,6 +47,8 @@ var (
 	failedSigningRuns monitoring.Counter
 	entriesAdded      monitoring.Counter
 	batchesAdded      monitoring.Counter
+	standbyRuns       monitoring.Counter
+	failedStandbyRuns monitoring.Counter
 )
 
 func createMetrics(mf monitoring.MetricFactory) {
@@ -68,6 +71,8 @@ func createMetrics(mf monitoring.MetricFactory) {
 	// entriesAdded / batchesAdded is average batch size. These can be used for
 	// tuning sequencing or evaluating performance.
 	batchesAdded = mf.NewCounter("batches_added", "Number of times a non zero number of entries was added", logIDLabel)
+	standbyRuns = mf.NewCounter("standby_runs", "Number of times a standby pass has prepared for taking over a log", logIDLabel)
+	failedStandbyRuns = mf.NewCounter("failed_standby_runs", "Number of times a standby pass over a log has failed", logIDLabel)
 }
 
 // Operation defines a task that operates on a log. Examples are scheduling, signing,
@@ -78,6 +83,15 @@ type Operation interface {
 	ExecutePass(ctx context.Context, logID int64, info *OperationInfo) (int, error)
 }
 
+// BacklogReporter is implemented by Operations which know how many items are
+// waiting to be processed for each log. The OperationManager uses it to give
+// priority to the logs with the largest backlogs.
+type BacklogReporter interface {
+	// Backlog returns the number of items waiting to be processed for logID,
+	// as of its latest pass, and whether it is known.
+	Backlog(logID int64) (int64, bool)
+}
+
 // OperationInfo bundles up information needed for running a set of Operations.
 type OperationInfo struct {
 	// Registry provides access to Trillian storage.
@@ -87,6 +101,14 @@ type OperationInfo struct {
 
 	// BatchSize is the batch size to be passed to tasks run by this manager.
 	BatchSize int
+	// TXBatchSize, if greater than 0, is the maximum number of leaves
+	// integrated in each storage transaction. Larger batches are split over
+	// several transactions, see IntegrateSplitBatch.
+	TXBatchSize int
+	// MaxRootDurationMargin is how long before the max_root_duration of a
+	// tree lapses that a new root is signed for it, even if there are no new
+	// leaves. It is capped at half of the max_root_duration.
+	MaxRootDurationMargin time.Duration
 	// TimeSource should be used by the Operation to allow mocking for tests.
 	TimeSource clock.TimeSource
 
@@ -105,6 +127,10 @@ type OperationInfo struct {
 	// Timeout sets an optional timeout on each operation run.
 	// If unset, default to the value of DefaultTimeout.
 	Timeout time.Duration
+	// StandbyWarmInterval, if positive, is how often the Operation prepares
+	// for taking over each active log that another instance is master for,
+	// if it implements StandbyWarmer.
+	StandbyWarmInterval time.Duration
 }
 
 // OperationManager controls scheduling activities for logs.
@@ -127,8 +153,27 @@ type OperationManager struct {
 	logNames map[int64]string
 	// A recent list of active logs that this instance is master for.
 	lastHeld []int64
-	// idsMutex guards logNames and lastHeld fields.
+	// A recent list of all active logs.
+	lastActive []int64
+	// idsMutex guards logNames, lastHeld and lastActive fields.
 	idsMutex sync.Mutex
+
+	// runs holds the outcome of the latest passes for each log, and
+	// lastWarm when each log was last prepared for as a standby.
+	runs     map[int64]*logRuns
+	lastWarm map[int64]time.Time
+	runsMu   sync.Mutex
+}
+
+// logRuns describes the latest passes of the operation for a log.
+type logRuns struct {
+	// lastRun is when the last pass started, and lastErr its error.
+	lastRun time.Time
+	lastErr error
+	// lastBatch is when the last pass which processed items started, and
+	// lastBatchSize the number of items it processed.
+	lastBatch     time.Time
+	lastBatchSize int
 }
 
 // NewOperationManager creates a new OperationManager instance.
@@ -153,6 +198,8 @@ func NewOperationManager(info OperationInfo, logOperation Operation) *OperationM
 		pendingResignations: make(chan election.Resignation, 100),
 		tracker:             tracker,
 		logNames:            make(map[int64]string),
+		runs:                make(map[int64]*logRuns),
+		lastWarm:            make(map[int64]time.Time),
 	}
 }
 
@@ -279,6 +326,7 @@ func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs
 	msg := fmt.Sprintf("Acting as master for %d / %d active logs: %s", len(logIDs), len(activeIDs), heldInfo)
 	o.idsMutex.Lock()
 	defer o.idsMutex.Unlock()
+	o.lastActive = activeIDs
 	if !reflect.DeepEqual(logIDs, o.lastHeld) {
 		o.lastHeld = make([]int64, len(logIDs))
 		copy(o.lastHeld, logIDs)
@@ -308,10 +356,103 @@ func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
 	}
 	o.updateHeldIDs(ctx, logIDs, activeIDs)
 
-	executePassForAll(runCtx, &o.info, o.logOperation, logIDs)
+	o.executePassForAll(runCtx, o.prioritize(logIDs))
+	o.warmStandbys(runCtx, o.standbyFor(activeIDs, logIDs))
 	return nil
 }
 
+// standbyFor returns the logs among activeIDs which this instance is not
+// master for, and which are due to be prepared for by a standby pass.
+func (o *OperationManager) standbyFor(activeIDs, logIDs []int64) []int64 {
+	if _, ok := o.logOperation.(StandbyWarmer); !ok || o.info.StandbyWarmInterval <= 0 {
+		return nil
+	}
+	held := make(map[int64]bool, len(logIDs))
+	for _, logID := range logIDs {
+		held[logID] = true
+	}
+	now := o.info.TimeSource.Now()
+	o.runsMu.Lock()
+	defer o.runsMu.Unlock()
+	var ret []int64
+	for _, logID := range activeIDs {
+		if held[logID] {
+			continue
+		}
+		if last, ok := o.lastWarm[logID]; ok && now.Sub(last) < o.info.StandbyWarmInterval {
+			continue
+		}
+		o.lastWarm[logID] = now
+		ret = append(ret, logID)
+	}
+	return ret
+}
+
+// warmStandbys runs WarmStandby of the operation for each of the passed-in
+// logs, using the same number of workers as the passes.
+func (o *OperationManager) warmStandbys(ctx context.Context, logIDs []int64) {
+	if len(logIDs) == 0 {
+		return
+	}
+	w := o.logOperation.(StandbyWarmer)
+	sem := semaphore.NewWeighted(int64(max(o.info.NumWorkers, 1)))
+	var wg sync.WaitGroup
+	for i, logID := range logIDs {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			klog.Warningf("Ran out of time for the standby pass, deferring %d of %d logs", len(logIDs)-i, len(logIDs))
+			o.runsMu.Lock()
+			for _, logID := range logIDs[i:] {
+				delete(o.lastWarm, logID)
+			}
+			o.runsMu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(logID int64) {
+			defer wg.Done()
+			defer sem.Release(1)
+			label := strconv.FormatInt(logID, 10)
+			if err := w.WarmStandby(ctx, logID, &o.info); err != nil {
+				klog.Warningf("WarmStandby(%v) failed: %v", logID, err)
+				failedStandbyRuns.Inc(label)
+				return
+			}
+			standbyRuns.Inc(label)
+		}(logID)
+	}
+	wg.Wait()
+}
+
+// prioritize returns logIDs ordered by the priority of their passes, so that
+// the busiest logs are processed first if the pass runs out of time, or has to
+// wait for workers. Logs which have never been processed come first, and the
+// others are ordered by their backlog, if the Operation reports it, times the
+// time since their last pass. Logs with no backlog still gain priority as they
+// wait, so they are not starved by busier ones.
+func (o *OperationManager) prioritize(logIDs []int64) []int64 {
+	br, _ := o.logOperation.(BacklogReporter)
+	now := o.info.TimeSource.Now()
+	priority := make(map[int64]float64, len(logIDs))
+	o.runsMu.Lock()
+	for _, logID := range logIDs {
+		r, ok := o.runs[logID]
+		if !ok {
+			priority[logID] = math.Inf(1)
+			continue
+		}
+		var backlog int64
+		if br != nil {
+			backlog, _ = br.Backlog(logID)
+		}
+		priority[logID] = float64(backlog+1) * now.Sub(r.lastRun).Seconds()
+	}
+	o.runsMu.Unlock()
+
+	ret := append([]int64(nil), logIDs...)
+	sort.SliceStable(ret, func(i, j int) bool { return priority[ret[i]] > priority[ret[j]] })
+	return ret
+}
+
 // OperationSingle performs a single pass of the manager.
 //
 // TODO(pavelkalinnikov): Deprecate this because it doesn't clean up any state,
@@ -402,9 +543,10 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 	return nil
 }
 
-// executePassForAll runs ExecutePass of the given operation for each of the
+// executePassForAll runs ExecutePass of the operation for each of the
 // passed-in logs, allowing up to a configurable number of parallel operations.
-func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, logIDs []int64) {
+func (o *OperationManager) executePassForAll(ctx context.Context, logIDs []int64) {
+	info := &o.info
 	startBatch := info.TimeSource.Now()
 
 	numWorkers := info.NumWorkers
@@ -416,15 +558,18 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 
 	sem := semaphore.NewWeighted(int64(numWorkers))
 	var wg sync.WaitGroup
-	for _, logID := range logIDs {
+	for i, logID := range logIDs {
 		if err := sem.Acquire(ctx, 1); err != nil {
-			break // Terminate because the context is canceled.
+			// Terminate because the context is canceled. The remaining logs
+			// have the lowest priority, and will gain it by the next pass.
+			klog.Warningf("Ran out of time for the pass, deferring %d of %d logs", len(logIDs)-i, len(logIDs))
+			break
 		}
 		wg.Add(1)
 		go func(logID int64) {
 			defer wg.Done()
 			defer sem.Release(1)
-			if err := executePass(ctx, info, op, logID); err != nil {
+			if err := o.executePass(ctx, logID); err != nil {
 				klog.Errorf("ExecutePass(%v) failed: %v", logID, err)
 			}
 		}(logID)
@@ -436,11 +581,13 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 	klog.V(1).Infof("Group run completed in %.2f seconds", d)
 }
 
-// executePass runs ExecutePass of the given operation for the passed-in log.
-func executePass(ctx context.Context, info *OperationInfo, op Operation, logID int64) error {
+// executePass runs ExecutePass of the operation for the passed-in log.
+func (o *OperationManager) executePass(ctx context.Context, logID int64) error {
+	info := &o.info
 	label := strconv.FormatInt(logID, 10)
 	start := info.TimeSource.Now()
-	count, err := op.ExecutePass(ctx, logID, info)
+	count, err := o.logOperation.ExecutePass(ctx, logID, info)
+	o.recordPass(logID, start, count, err)
 	if err != nil {
 		failedSigningRuns.Inc(label)
 		return err
@@ -458,3 +605,18 @@ func executePass(ctx context.Context, info *OperationInfo, op Operation, logID i
 	}
 	return nil
 }
+
+// recordPass records the outcome of a pass for the status of logID.
+func (o *OperationManager) recordPass(logID int64, start time.Time, count int, err error) {
+	o.runsMu.Lock()
+	defer o.runsMu.Unlock()
+	r, ok := o.runs[logID]
+	if !ok {
+		r = &logRuns{}
+		o.runs[logID] = r
+	}
+	r.lastRun, r.lastErr = start, err
+	if err == nil && count > 0 {
+		r.lastBatch, r.lastBatchSize = start, count
+	}
+}
@@ -346,6 +346,49 @@ func TestOperationManagerOperationLoopExecutePassError(t *testing.T) {
 	}
 }
 
+// backlogOp is an Operation which reports fixed backlogs.
+type backlogOp struct {
+	Operation
+	backlogs map[int64]int64
+}
+
+func (b backlogOp) Backlog(logID int64) (int64, bool) {
+	backlog, ok := b.backlogs[logID]
+	return backlog, ok
+}
+
+func TestPrioritize(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	now := ts.Now()
+	info := defaultOperationInfo(extension.Registry{})
+	info.TimeSource = ts
+	op := backlogOp{backlogs: map[int64]int64{1: 0, 2: 100, 3: 5, 5: 1000}}
+	lom := NewOperationManager(info, op)
+	for logID, ago := range map[int64]time.Duration{
+		1: time.Hour,        // Idle, but starving: 3600.
+		2: time.Second,      // Busy: 101.
+		3: 10 * time.Second, // Quieter, and waiting: 60.
+		4: time.Minute,      // Unknown backlog: 60.
+		5: 0,                // Just run: 0.
+	} {
+		lom.recordPass(logID, now.Add(-ago), 0, nil)
+	}
+
+	// Log 6 has never been run.
+	got := lom.prioritize([]int64{1, 2, 3, 4, 5, 6})
+	if want := []int64{6, 1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("prioritize() = %v, want %v", got, want)
+	}
+
+	// Without backlogs, the logs which waited the longest come first.
+	lom = NewOperationManager(info, NewMockOperation(gomock.NewController(t)))
+	lom.recordPass(1, now.Add(-time.Second), 0, nil)
+	lom.recordPass(2, now.Add(-time.Minute), 0, nil)
+	if got, want := lom.prioritize([]int64{1, 2}), []int64{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("prioritize(no backlogs) = %v, want %v", got, want)
+	}
+}
+
 func TestHeldInfo(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -0,0 +1,99 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// StuckLeafAction is what RepairStuckLeaves does with the stuck leaves.
+type StuckLeafAction int
+
+const (
+	// ListStuckLeaves only returns the stuck leaves.
+	ListStuckLeaves StuckLeafAction = iota
+	// RequeueStuckLeaves moves the stuck leaves to the back of the queue, by
+	// setting their QueueTimestamp to the current time.
+	RequeueStuckLeaves
+	// DiscardStuckLeaves removes the stuck leaves from the queue, so that they
+	// can be submitted again.
+	DiscardStuckLeaves
+)
+
+// String returns the name of the action.
+func (a StuckLeafAction) String() string {
+	switch a {
+	case ListStuckLeaves:
+		return "list"
+	case RequeueStuckLeaves:
+		return "requeue"
+	case DiscardStuckLeaves:
+		return "discard"
+	}
+	return fmt.Sprintf("StuckLeafAction(%d)", int(a))
+}
+
+// RepairStuckLeaves finds up to limit leaves of a log which have been queued
+// since before the given time without being sequenced, e.g. because of a
+// historic bug or a misconfigured guard window, and applies action to them in
+// a single transaction. The affected leaves are returned, with only their
+// LeafIdentityHash, MerkleLeafHash and original QueueTimestamp set.
+//
+// The storage must implement storage.QueueRepairer, and the wrappers of the
+// log server, such as --storage_append_only_guard, are bypassed.
+func RepairStuckLeaves(ctx context.Context, tree *trillian.Tree, before time.Time, limit int, action StuckLeafAction, ts clock.TimeSource, ls storage.LogStorage) ([]*trillian.LogLeaf, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	var leaves []*trillian.LogLeaf
+	err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		qr, ok := tx.(storage.QueueRepairer)
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "storage doesn't support repairing the queue of tree %d", tree.TreeId)
+		}
+		var err error
+		if leaves, err = qr.ListQueuedLeaves(ctx, before, limit); err != nil {
+			return fmt.Errorf("failed to list queued leaves: %w", err)
+		}
+		if len(leaves) == 0 {
+			return nil
+		}
+		switch action {
+		case ListStuckLeaves:
+			return nil
+		case RequeueStuckLeaves:
+			return qr.RequeueLeaves(ctx, leaves, ts.Now())
+		case DiscardStuckLeaves:
+			return qr.DiscardQueuedLeaves(ctx, leaves)
+		}
+		return fmt.Errorf("unknown action %v", action)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", tree.TreeId, err)
+	}
+	if action != ListStuckLeaves {
+		klog.Infof("%v: applied %v to %d stuck leaves", tree.TreeId, action, len(leaves))
+	}
+	return leaves, nil
+}
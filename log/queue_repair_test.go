@@ -0,0 +1,78 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+func TestRepairStuckLeaves(t *testing.T) {
+	ctx := context.Background()
+	lt, ls := newMemoryLog(ctx, t, 5)
+	now := clock.System.Now().Add(time.Minute)
+	ts := clock.NewFake(now)
+
+	for _, test := range []struct {
+		action    StuckLeafAction
+		limit     int
+		wantCount int
+		wantQueue int
+	}{
+		{action: ListStuckLeaves, limit: 10, wantCount: 5, wantQueue: 5},
+		{action: RequeueStuckLeaves, limit: 2, wantCount: 2, wantQueue: 5},
+		{action: DiscardStuckLeaves, limit: 2, wantCount: 2, wantQueue: 3},
+		{action: ListStuckLeaves, limit: 10, wantCount: 1, wantQueue: 3},
+	} {
+		t.Run(test.action.String(), func(t *testing.T) {
+			leaves, err := RepairStuckLeaves(ctx, lt, now, test.limit, test.action, ts, ls)
+			if err != nil {
+				t.Fatalf("RepairStuckLeaves(): %v", err)
+			}
+			if got := len(leaves); got != test.wantCount {
+				t.Errorf("RepairStuckLeaves() returned %d leaves, want %d", got, test.wantCount)
+			}
+			if got := len(dequeueAll(ctx, t, lt, ls)); got != test.wantQueue {
+				t.Errorf("%d leaves queued, want %d", got, test.wantQueue)
+			}
+		})
+	}
+
+	// Only the requeued leaves are left, queued at the time of requeueing.
+	for _, leaf := range dequeueAll(ctx, t, lt, ls)[1:] {
+		if got := leaf.QueueTimestamp.AsTime(); !got.Equal(now) {
+			t.Errorf("leaf %x queued at %v, want %v", leaf.LeafIdentityHash, got, now)
+		}
+	}
+}
+
+// dequeueAll returns the queued leaves of the tree, without removing them.
+func dequeueAll(ctx context.Context, t *testing.T, lt *trillian.Tree, ls storage.LogStorage) []*trillian.LogLeaf {
+	t.Helper()
+	var leaves []*trillian.LogLeaf
+	if err := ls.ReadWriteTransaction(ctx, lt, func(ctx context.Context, tx storage.LogTreeTX) error {
+		var err error
+		leaves, err = tx.DequeueLeaves(ctx, 100, time.Now().Add(time.Hour))
+		return err
+	}); err != nil {
+		t.Fatalf("DequeueLeaves(): %v", err)
+	}
+	return leaves
+}
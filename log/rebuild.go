@@ -0,0 +1,196 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// RebuildMerkleNodes recomputes all the Merkle nodes of a log from its
+// integrated leaves, and rewrites them. This recovers from the loss or
+// corruption of stored nodes, as long as the leaves are intact.
+//
+// The leaves are first checked against the log's latest root, and nothing is
+// written unless they match it. The nodes are then rewritten in transactions
+// covering up to batchSize leaves each, and each transaction stores a copy of
+// the latest root with a new timestamp, so that the nodes become visible to
+// readers. The log may keep growing meanwhile, but only the nodes within the
+// root read at the start are rewritten. Finally, the rewritten nodes are read
+// back and checked against the root.
+//
+// If dryRun is set, only the leaves are checked. The root which the nodes were
+// checked against is returned.
+func RebuildMerkleNodes(ctx context.Context, tree *trillian.Tree, batchSize int, dryRun bool, ts clock.TimeSource, ls storage.LogStorage) (*types.LogRootV1, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", batchSize)
+	}
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", tree.TreeId, err)
+	}
+	rf := hashers.RangeFactory(hasher)
+	var root *types.LogRootV1
+	if err := inSnapshot(ctx, tree, ls, func(tx storage.ReadOnlyLogTreeTX) error {
+		var err error
+		root, err = readRoot(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("%v: failed to read latest root: %v", tree.TreeId, err)
+	}
+
+	// Check the leaves before writing anything.
+	cr := rf.NewEmptyRange(0)
+	if err := forEachLeafBatch(ctx, tree, ls, root.TreeSize, batchSize, func(leaves []*trillian.LogLeaf) error {
+		return appendLeaves(cr, leaves, nil)
+	}); err != nil {
+		return nil, err
+	}
+	if err := checkRootHash(cr, root); err != nil {
+		return nil, fmt.Errorf("%v: leaves don't match the latest root: %w", tree.TreeId, err)
+	}
+	klog.Infof("%v: %d leaves match the latest root", tree.TreeId, root.TreeSize)
+	if dryRun {
+		return root, nil
+	}
+
+	cr = rf.NewEmptyRange(0)
+	if err := forEachLeafBatch(ctx, tree, ls, root.TreeSize, batchSize, func(leaves []*trillian.LogLeaf) error {
+		nodeMap := make(map[compact.NodeID][]byte)
+		if err := appendLeaves(cr, leaves, func(id compact.NodeID, hash []byte) { nodeMap[id] = hash }); err != nil {
+			return err
+		}
+		return ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			if err := tx.SetMerkleNodes(ctx, buildNodesFromNodeMap(nodeMap)); err != nil {
+				return fmt.Errorf("failed to set Merkle nodes: %v", err)
+			}
+			return republishRoot(ctx, tx, ts)
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("%v: failed to rewrite nodes: %v", tree.TreeId, err)
+	}
+	klog.Infof("%v: rewrote the nodes of %d leaves", tree.TreeId, root.TreeSize)
+
+	// Check what was written.
+	if err := inSnapshot(ctx, tree, ls, func(tx storage.ReadOnlyLogTreeTX) error {
+		cr, err := initCompactRangeFromStorage(ctx, rf, root, tx)
+		if err != nil {
+			return err
+		}
+		return checkRootHash(cr, root)
+	}); err != nil {
+		return nil, fmt.Errorf("%v: rewritten nodes don't match the latest root: %w", tree.TreeId, err)
+	}
+	return root, nil
+}
+
+// forEachLeafBatch calls f with the integrated leaves [0, size) of the log, in
+// order, in batches of up to batchSize leaves.
+func forEachLeafBatch(ctx context.Context, tree *trillian.Tree, ls storage.LogStorage, size uint64, batchSize int, f func([]*trillian.LogLeaf) error) error {
+	for begin := uint64(0); begin < size; begin += uint64(batchSize) {
+		count := min(uint64(batchSize), size-begin)
+		var leaves []*trillian.LogLeaf
+		if err := inSnapshot(ctx, tree, ls, func(tx storage.ReadOnlyLogTreeTX) error {
+			var err error
+			leaves, err = tx.GetLeavesByRange(ctx, int64(begin), int64(count))
+			return err
+		}); err != nil {
+			return fmt.Errorf("%v: failed to read leaves [%d, %d): %v", tree.TreeId, begin, begin+count, err)
+		}
+		if got := uint64(len(leaves)); got != count {
+			return status.Errorf(codes.DataLoss, "%v: got %d leaves in [%d, %d), want %d", tree.TreeId, got, begin, begin+count, count)
+		}
+		if err := f(leaves); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// republishRoot stores a copy of the latest root of the log with the current
+// time, making the nodes written at the new revision visible.
+func republishRoot(ctx context.Context, tx storage.LogTreeTX, ts clock.TimeSource) error {
+	root, err := readRoot(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if now := uint64(ts.Now().UnixNano()); now > root.TimestampNanos {
+		root.TimestampNanos = now
+	} else {
+		root.TimestampNanos++
+	}
+	logRoot, err := root.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+}
+
+// checkRootHash returns an error if cr doesn't match root.
+func checkRootHash(cr *compact.Range, root *types.LogRootV1) error {
+	if got, want := cr.End(), root.TreeSize; got != want {
+		return fmt.Errorf("size mismatch: got %d, want %d", got, want)
+	}
+	if root.TreeSize == 0 {
+		return nil
+	}
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		return status.Errorf(codes.DataLoss, "root hash mismatch at size %d: got %x, want %x", root.TreeSize, hash, root.RootHash)
+	}
+	return nil
+}
+
+// readRoot returns the latest root of the log read by tx.
+func readRoot(ctx context.Context, tx storage.ReadOnlyLogTreeTX) (*types.LogRootV1, error) {
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	} else if slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// inSnapshot runs f in a read-only transaction of the log.
+func inSnapshot(ctx context.Context, tree *trillian.Tree, ls storage.LogStorage, f func(storage.ReadOnlyLogTreeTX) error) error {
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRebuildMerkleNodes(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 45
+	lt, ls := newMemoryLog(ctx, t, leafCount)
+	if _, err := IntegrateBatch(ctx, lt, leafCount, 0, 0, clock.System, ls, quota.Noop()); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+
+	// Corrupt some nodes, and store a root so that they become visible.
+	bad := []byte("not a hash")
+	corrupted := []compact.NodeID{compact.NewNodeID(0, 5), compact.NewNodeID(2, 3), compact.NewNodeID(5, 0)}
+	write := func(nodes []tree.Node, root func(*types.LogRootV1)) {
+		t.Helper()
+		if err := ls.ReadWriteTransaction(ctx, lt, func(ctx context.Context, tx storage.LogTreeTX) error {
+			if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+				return err
+			}
+			r, err := readRoot(ctx, tx)
+			if err != nil {
+				return err
+			}
+			r.TimestampNanos++
+			root(r)
+			logRoot, err := r.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+		}); err != nil {
+			t.Fatalf("ReadWriteTransaction(): %v", err)
+		}
+	}
+	var nodes []tree.Node
+	for _, id := range corrupted {
+		nodes = append(nodes, tree.Node{ID: id, Hash: bad})
+	}
+	write(nodes, func(*types.LogRootV1) {})
+
+	for _, dryRun := range []bool{true, false} {
+		root, err := RebuildMerkleNodes(ctx, lt, 10, dryRun, clock.System, ls)
+		if err != nil {
+			t.Fatalf("RebuildMerkleNodes(dryRun=%v): %v", dryRun, err)
+		}
+		if root.TreeSize != leafCount {
+			t.Errorf("RebuildMerkleNodes(dryRun=%v): TreeSize = %d, want %d", dryRun, root.TreeSize, leafCount)
+		}
+		if err := inSnapshot(ctx, lt, ls, func(tx storage.ReadOnlyLogTreeTX) error {
+			got, err := tx.GetMerkleNodes(ctx, corrupted)
+			if err != nil {
+				return err
+			}
+			for _, n := range got {
+				if isBad := bytes.Equal(n.Hash, bad); isBad != dryRun {
+					t.Errorf("RebuildMerkleNodes(dryRun=%v): node %+v has hash %x", dryRun, n.ID, n.Hash)
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("Failed to read nodes: %v", err)
+		}
+	}
+
+	// Nothing is written if the leaves don't match the root.
+	write(nil, func(r *types.LogRootV1) { r.RootHash = bad })
+	if _, err := RebuildMerkleNodes(ctx, lt, 10, false, clock.System, ls); status.Code(err) != codes.DataLoss {
+		t.Errorf("RebuildMerkleNodes() with a mismatched root returned err = %v, want DataLoss", err)
+	}
+}
@@ -17,21 +17,24 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle"
 	"github.com/transparency-dev/merkle/compact"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
@@ -39,20 +42,26 @@ import (
 const logIDLabel = "logid"
 
 var (
-	sequencerOnce          sync.Once
-	seqBatches             monitoring.Counter
-	seqTreeSize            monitoring.Gauge
-	seqLatency             monitoring.Histogram
-	seqDequeueLatency      monitoring.Histogram
-	seqGetRootLatency      monitoring.Histogram
-	seqInitTreeLatency     monitoring.Histogram
-	seqWriteTreeLatency    monitoring.Histogram
-	seqUpdateLeavesLatency monitoring.Histogram
-	seqSetNodesLatency     monitoring.Histogram
-	seqStoreRootLatency    monitoring.Histogram
-	seqCounter             monitoring.Counter
-	seqMergeDelay          monitoring.Histogram
-	seqTimestamp           monitoring.Gauge
+	sequencerOnce           sync.Once
+	seqBatches              monitoring.Counter
+	seqTreeSize             monitoring.Gauge
+	seqLatency              monitoring.Histogram
+	seqDequeueLatency       monitoring.Histogram
+	seqGetRootLatency       monitoring.Histogram
+	seqInitTreeLatency      monitoring.Histogram
+	seqWriteTreeLatency     monitoring.Histogram
+	seqUpdateLeavesLatency  monitoring.Histogram
+	seqSetNodesLatency      monitoring.Histogram
+	seqStoreRootLatency     monitoring.Histogram
+	seqCounter              monitoring.Counter
+	seqMergeDelay           monitoring.Histogram
+	seqTimestamp            monitoring.Gauge
+	seqAutoFrozen           monitoring.Counter
+	seqBacklog              monitoring.Gauge
+	seqIdleSkips            monitoring.Counter
+	seqRootExpiry           monitoring.Gauge
+	seqMergeDelayCompliance monitoring.Gauge
+	seqStandbyRangeHits     monitoring.Counter
 
 	// QuotaIncreaseFactor is the multiplier used for the number of tokens added back to
 	// sequencing-based quotas. The resulting PutTokens call is equivalent to
@@ -98,15 +107,21 @@ func InitMetrics(mf monitoring.MetricFactory) {
 		seqStoreRootLatency = mf.NewHistogram("sequencer_latency_store_root", "Latency of store-root part of sequencer batch operation in seconds", logIDLabel)
 		seqCounter = mf.NewCounter("sequencer_sequenced", "Number of leaves sequenced", logIDLabel)
 		seqMergeDelay = mf.NewHistogram("sequencer_merge_delay", "Delay between queuing and integration of leaves", logIDLabel)
+		seqAutoFrozen = mf.NewCounter("sequencer_auto_frozen", "Number of DRAINING trees automatically transitioned to FROZEN", logIDLabel)
+		seqBacklog = mf.NewGauge("sequencer_backlog", "Number of leaves queued but not yet integrated, as of the last sequencing pass", logIDLabel)
+		seqIdleSkips = mf.NewCounter("sequencer_idle_skips", "Number of sequencing passes skipped because the queue was empty", logIDLabel)
+		seqMergeDelayCompliance = mf.NewGauge("sequencer_merge_delay_compliance", "Percentage of leaves integrated within the merge_delay_target of the tree, over the window set by --merge_delay_slo_window", logIDLabel)
+		seqStandbyRangeHits = mf.NewCounter("sequencer_standby_range_hits", "Number of sequencing passes which used the compact range read by a standby pass, rather than reading it from storage", logIDLabel)
+		seqRootExpiry = mf.NewGauge("sequencer_root_expiry_seconds", "Time left until the latest root exceeds the max_root_duration of the tree, negative once it has, as of the last sequencing pass", logIDLabel)
 	})
 }
 
-// initCompactRangeFromStorage builds a compact range that matches the latest
-// data in the database. Ensures that the root hash matches the passed in root.
-func initCompactRangeFromStorage(ctx context.Context, root *types.LogRootV1, tx storage.LogTreeTX) (*compact.Range, error) {
-	fact := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+// initCompactRangeFromStorage builds a compact range, created by rf, that
+// matches the latest data in the database. Ensures that the root hash matches
+// the passed in root.
+func initCompactRangeFromStorage(ctx context.Context, rf *compact.RangeFactory, root *types.LogRootV1, tx storage.ReadOnlyLogTreeTX) (*compact.Range, error) {
 	if root.TreeSize == 0 {
-		return fact.NewEmptyRange(0), nil
+		return rf.NewEmptyRange(0), nil
 	}
 
 	ids := compact.RangeNodes(0, root.TreeSize, nil)
@@ -122,7 +137,7 @@ func initCompactRangeFromStorage(ctx context.Context, root *types.LogRootV1, tx
 	for i, node := range nodes {
 		hashes[i] = node.Hash
 	}
-	cr, err := fact.NewRange(0, root.TreeSize, hashes)
+	cr, err := rf.NewRange(0, root.TreeSize, hashes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compact.Range: %v", err)
 	}
@@ -178,16 +193,8 @@ func updateCompactRange(cr *compact.Range, leaves []*trillian.LogLeaf, label str
 	nodeMap := make(map[compact.NodeID][]byte)
 	store := func(id compact.NodeID, hash []byte) { nodeMap[id] = hash }
 
-	// Update the tree state by integrating the leaves one by one.
-	for _, leaf := range leaves {
-		idx := leaf.LeafIndex
-		if size := cr.End(); idx < 0 || idx != int64(size) {
-			return nil, nil, fmt.Errorf("leaf index mismatch: got %d, want %d", idx, size)
-		}
-		// Store all the new internal nodes, including the added leaf.
-		if err := cr.Append(leaf.MerkleLeafHash, store); err != nil {
-			return nil, nil, err
-		}
+	if err := appendLeaves(cr, leaves, store); err != nil {
+		return nil, nil, err
 	}
 
 	// Note: Ephemeral nodes are not stored.
@@ -198,6 +205,22 @@ func updateCompactRange(cr *compact.Range, leaves []*trillian.LogLeaf, label str
 	return nodeMap, hash, nil
 }
 
+// appendLeaves integrates the passed in leaves into the compact range one by
+// one, passing all the new internal nodes, including the added leaves, to
+// store.
+func appendLeaves(cr *compact.Range, leaves []*trillian.LogLeaf, store compact.VisitFn) error {
+	for _, leaf := range leaves {
+		idx := leaf.LeafIndex
+		if size := cr.End(); idx < 0 || idx != int64(size) {
+			return fmt.Errorf("leaf index mismatch: got %d, want %d", idx, size)
+		}
+		if err := cr.Append(leaf.MerkleLeafHash, store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // sequencingTask provides sequenced LogLeaf entries, and updates storage
 // according to their ordering if needed.
 type sequencingTask interface {
@@ -215,6 +238,8 @@ type sequencingTaskData struct {
 	treeSize   uint64
 	timeSource clock.TimeSource
 	tx         storage.LogTreeTX
+	// fifo is set if leaves must be integrated in queue timestamp order.
+	fifo bool
 }
 
 // logSequencingTask is a sequencingTask implementation for "normal" Log mode,
@@ -231,6 +256,11 @@ func (s *logSequencingTask) fetch(ctx context.Context, limit int, cutoff time.Ti
 	}
 	seqDequeueLatency.Observe(clock.SecondsSince(s.timeSource, start), s.label)
 
+	// Storage returns the oldest leaves first, but not necessarily in order.
+	if s.fifo {
+		sortByQueueTimestamp(leaves)
+	}
+
 	// Assign leaf sequence numbers.
 	for i, leaf := range leaves {
 		leaf.LeafIndex = int64(s.treeSize + uint64(i))
@@ -241,6 +271,18 @@ func (s *logSequencingTask) fetch(ctx context.Context, limit int, cutoff time.Ti
 	return leaves, nil
 }
 
+// sortByQueueTimestamp sorts leaves by queue timestamp, then by identity
+// hash, which is the order of FIFO_DEQUEUE_ORDER trees.
+func sortByQueueTimestamp(leaves []*trillian.LogLeaf) {
+	sort.SliceStable(leaves, func(i, j int) bool {
+		ti, tj := leaves[i].QueueTimestamp.AsTime(), leaves[j].QueueTimestamp.AsTime()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return bytes.Compare(leaves[i].LeafIdentityHash, leaves[j].LeafIdentityHash) < 0
+	})
+}
+
 func (s *logSequencingTask) update(ctx context.Context, leaves []*trillian.LogLeaf) error {
 	start := s.timeSource.Now()
 	// Write the new sequence numbers to the leaves in the DB.
@@ -273,29 +315,31 @@ func (s *preorderedLogSequencingTask) update(ctx context.Context, leaves []*tril
 
 // IntegrateBatch wraps up all the operations needed to take a batch of queued
 // or sequenced leaves and integrate them into the tree.
+//
+// If an interrupted IntegrateSplitBatch has left leaves staged beyond the
+// latest root, the batch is integrated by IntegrateSplitBatch instead, which
+// picks them up first.
 func IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWindow, maxRootDurationInterval time.Duration, ts clock.TimeSource, ls storage.LogStorage, qm quota.Manager) (int, error) {
 	start := ts.Now()
 	label := strconv.FormatInt(tree.TreeId, 10)
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", tree.TreeId, err)
+	}
+	rf := hashers.RangeFactory(hasher)
 
 	numLeaves := 0
-	var newLogRoot *types.LogRootV1
-	var newSLR *trillian.SignedLogRoot
-	err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+	var sequencedLeaves []*trillian.LogLeaf
+	var currentRoot, newLogRoot *types.LogRootV1
+	err = ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
 		stageStart := ts.Now()
 		defer seqBatches.Inc(label)
 		defer func() { seqLatency.Observe(clock.SecondsSince(ts, start), label) }()
 
-		// Get the latest known root from storage
-		sth, err := tx.LatestSignedLogRoot(ctx)
-		if err != nil || sth == nil {
-			return fmt.Errorf("%v: Sequencer failed to get latest root: %v", tree.TreeId, err)
-		}
-		// There is no trust boundary between the signer and the
-		// database, so we skip signature verification.
-		// TODO(gbelvin): Add signature checking as a santity check.
-		var currentRoot types.LogRootV1
-		if err := currentRoot.UnmarshalBinary(sth.LogRoot); err != nil {
-			return fmt.Errorf("%v: Sequencer failed to unmarshal latest root: %v", tree.TreeId, err)
+		var err error
+		currentRoot, err = latestRoot(ctx, tree.TreeId, tx)
+		if err != nil {
+			return err
 		}
 		seqGetRootLatency.Observe(clock.SecondsSince(ts, stageStart), label)
 		seqTreeSize.Set(float64(currentRoot.TreeSize), label)
@@ -305,11 +349,24 @@ func IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWi
 			return storage.ErrTreeNeedsInit
 		}
 
+		// The leaves of a LOG tree staged by an interrupted split batch have
+		// already been dequeued, so they must be integrated before any more.
+		if tree.TreeType == trillian.TreeType_LOG && limit > 0 {
+			staged, err := hasStagedLeaves(ctx, tx, currentRoot.TreeSize)
+			if err != nil {
+				return fmt.Errorf("%v: Sequencer failed to load staged leaves: %v", tree.TreeId, err)
+			}
+			if staged {
+				return errStagedLeaves
+			}
+		}
+
 		taskData := &sequencingTaskData{
 			label:      label,
 			treeSize:   currentRoot.TreeSize,
 			timeSource: ts,
 			tx:         tx,
+			fifo:       tree.DequeueOrder == trillian.DequeueOrder_FIFO_DEQUEUE_ORDER,
 		}
 		var st sequencingTask
 		switch tree.TreeType {
@@ -321,9 +378,21 @@ func IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWi
 			return fmt.Errorf("IntegrateBatch not supported for TreeType %v", tree.TreeType)
 		}
 
-		sequencedLeaves, err := st.fetch(ctx, limit, start.Add(-guardWindow))
-		if err != nil {
-			return fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", tree.TreeId, err)
+		// Never grow the tree beyond its maximum size. Any leaves queued
+		// beyond the limit are left in the queue.
+		if maxSize := tree.MaxTreeSize; maxSize > 0 {
+			if remaining := maxSize - int64(currentRoot.TreeSize); remaining < int64(limit) {
+				klog.V(1).Infof("%v: Tree size %d is close to its maximum of %d, limiting batch", tree.TreeId, currentRoot.TreeSize, maxSize)
+				limit = int(max(remaining, 0))
+			}
+		}
+
+		sequencedLeaves = nil
+		if limit > 0 {
+			sequencedLeaves, err = st.fetch(ctx, limit, start.Add(-guardWindow))
+			if err != nil {
+				return fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", tree.TreeId, err)
+			}
 		}
 		numLeaves = len(sequencedLeaves)
 
@@ -341,7 +410,7 @@ func IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWi
 		}
 
 		stageStart = ts.Now()
-		cr, err := initCompactRangeFromStorage(ctx, &currentRoot, tx)
+		cr, err := initCompactRange(ctx, tree.TreeId, rf, currentRoot, tx)
 		if err != nil {
 			return fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
 		}
@@ -376,52 +445,101 @@ func IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWi
 			return fmt.Errorf("%v: Sequencer failed to set Merkle nodes: %v", tree.TreeId, err)
 		}
 		seqSetNodesLatency.Observe(clock.SecondsSince(ts, stageStart), label)
-		stageStart = ts.Now()
-
-		// Create the log root ready for signing.
-		if cr.End() == 0 {
-			// Override the nil root hash returned by the compact range.
-			newRoot = rfc6962.DefaultHasher.EmptyRoot()
-		}
-		newLogRoot = &types.LogRootV1{
-			RootHash:       newRoot,
-			TimestampNanos: uint64(ts.Now().UnixNano()),
-			TreeSize:       cr.End(),
-		}
-		seqTreeSize.Set(float64(newLogRoot.TreeSize), label)
-		seqTimestamp.Set(float64(time.Duration(newLogRoot.TimestampNanos)*time.Nanosecond/
-			time.Millisecond), label)
-
-		if newLogRoot.TimestampNanos <= currentRoot.TimestampNanos {
-			return fmt.Errorf("%v: refusing to sign root with timestamp earlier than previous root (%d <= %d)", tree.TreeId, newLogRoot.TimestampNanos, currentRoot.TimestampNanos)
-		}
 
-		logRoot, err := newLogRoot.MarshalBinary()
-		if err != nil {
-			return fmt.Errorf("%v: signer failed to marshal root: %v", tree.TreeId, err)
-		}
-		newSLR := &trillian.SignedLogRoot{LogRoot: logRoot}
-
-		if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
-			return fmt.Errorf("%v: failed to write updated tree root: %v", tree.TreeId, err)
-		}
-		seqStoreRootLatency.Observe(clock.SecondsSince(ts, stageStart), label)
-		return nil
+		newLogRoot, err = storeRoot(ctx, tree.TreeId, label, tx, hasher, cr, newRoot, currentRoot, start, numLeaves, ts)
+		return err
 	})
+	if errors.Is(err, errStagedLeaves) {
+		klog.Infof("%v: picking up the leaves staged by an interrupted split batch", tree.TreeId)
+		return IntegrateSplitBatch(ctx, tree, limit, limit, guardWindow, maxRootDurationInterval, ts, ls, qm)
+	}
 	if err != nil {
 		return 0, err
 	}
+	if newLogRoot != nil {
+		setRootExpiry(tree, label, newLogRoot, ts)
+	} else {
+		setRootExpiry(tree, label, currentRoot, ts)
+	}
+	recordMergeDelays(tree, label, sequencedLeaves, ts.Now())
 
 	// Let quota.Manager know about newly-sequenced entries.
 	replenishQuota(ctx, numLeaves, tree.TreeId, qm)
 
 	seqCounter.Add(float64(numLeaves), label)
-	if newSLR != nil {
+	if newLogRoot != nil {
 		klog.Infof("%v: sequenced %v leaves, size %v", tree.TreeId, numLeaves, newLogRoot.TreeSize)
 	}
 	return numLeaves, nil
 }
 
+// setRootExpiry exports the time left until root, the latest root of tree,
+// exceeds the max_root_duration of the tree, if it has one.
+func setRootExpiry(tree *trillian.Tree, label string, root *types.LogRootV1, ts clock.TimeSource) {
+	maxRootDuration := tree.MaxRootDuration.AsDuration()
+	if root == nil || maxRootDuration <= 0 || !tree.MaxRootDuration.IsValid() {
+		return
+	}
+	age := ts.Now().Sub(time.Unix(0, int64(root.TimestampNanos)))
+	seqRootExpiry.Set((maxRootDuration - age).Seconds(), label)
+}
+
+// latestRoot returns the latest root of the tree, as read by tx.
+func latestRoot(ctx context.Context, treeID int64, tx storage.ReadOnlyLogTreeTX) (*types.LogRootV1, error) {
+	sth, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil || sth == nil {
+		return nil, fmt.Errorf("%v: Sequencer failed to get latest root: %v", treeID, err)
+	}
+	// There is no trust boundary between the signer and the
+	// database, so we skip signature verification.
+	// TODO(gbelvin): Add signature checking as a santity check.
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(sth.LogRoot); err != nil {
+		return nil, fmt.Errorf("%v: Sequencer failed to unmarshal latest root: %v", treeID, err)
+	}
+	return &root, nil
+}
+
+// storeRoot stores the root of the tree covered by cr, whose root hash is
+// rootHash, as the successor of currentRoot, and records the integration run
+// which started at start and integrated numLeaves leaves. The hasher of the
+// tree provides the root hash of an empty tree.
+func storeRoot(ctx context.Context, treeID int64, label string, tx storage.LogTreeTX, hasher merkle.LogHasher, cr *compact.Range, rootHash []byte, currentRoot *types.LogRootV1, start time.Time, numLeaves int, ts clock.TimeSource) (*types.LogRootV1, error) {
+	stageStart := ts.Now()
+	// Create the log root ready for signing.
+	if cr.End() == 0 {
+		// Override the nil root hash returned by the compact range.
+		rootHash = hasher.EmptyRoot()
+	}
+	newLogRoot := &types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(ts.Now().UnixNano()),
+		TreeSize:       cr.End(),
+	}
+	seqTreeSize.Set(float64(newLogRoot.TreeSize), label)
+	seqTimestamp.Set(float64(time.Duration(newLogRoot.TimestampNanos)*time.Nanosecond/
+		time.Millisecond), label)
+
+	if newLogRoot.TimestampNanos <= currentRoot.TimestampNanos {
+		return nil, fmt.Errorf("%v: refusing to sign root with timestamp earlier than previous root (%d <= %d)", treeID, newLogRoot.TimestampNanos, currentRoot.TimestampNanos)
+	}
+
+	logRoot, err := newLogRoot.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%v: signer failed to marshal root: %v", treeID, err)
+	}
+	newSLR := &trillian.SignedLogRoot{LogRoot: logRoot}
+
+	if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
+		return nil, fmt.Errorf("%v: failed to write updated tree root: %v", treeID, err)
+	}
+	if err := recordIntegration(ctx, tx, start, numLeaves, newLogRoot); err != nil {
+		return nil, fmt.Errorf("%v: failed to record integration event: %v", treeID, err)
+	}
+	seqStoreRootLatency.Observe(clock.SecondsSince(ts, stageStart), label)
+	return newLogRoot, nil
+}
+
 // replenishQuota replenishes all quotas, such as {Tree/Global, Read/Write},
 // that are possibly influenced by sequencing numLeaves entries for the passed
 // in tree ID. Implementations are tasked with filtering quotas that shouldn't
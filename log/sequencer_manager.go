@@ -17,11 +17,18 @@ package log
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
 
@@ -29,6 +36,15 @@ import (
 type SequencerManager struct {
 	guardWindow time.Duration
 	registry    extension.Registry
+
+	// backlogs holds the number of leaves left queued in each log after its
+	// latest pass, where the storage can count them.
+	backlogs   map[int64]int64
+	backlogsMu sync.Mutex
+
+	// ranges holds the compact ranges read by standby passes, see
+	// WarmStandby.
+	ranges *rangeCache
 }
 
 var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -40,6 +56,8 @@ func NewSequencerManager(registry extension.Registry, gw time.Duration) *Sequenc
 	return &SequencerManager{
 		guardWindow: gw,
 		registry:    registry,
+		backlogs:    make(map[int64]int64),
+		ranges:      newRangeCache(),
 	}
 }
 
@@ -59,9 +77,162 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *O
 		klog.Warning("failed to parse tree.MaxRootDuration, using zero")
 		maxRootDuration = 0
 	}
-	leaves, err := IntegrateBatch(ctx, tree, info.BatchSize, s.guardWindow, maxRootDuration, info.TimeSource, s.registry.LogStorage, s.registry.QuotaManager)
+	guardWindow := s.guardWindow
+	backlog := int64(-1)
+	requested := tree.SequencingRequestedTime
+	if requested != nil {
+		// Sequencing was triggered through the admin API, so the queue is
+		// flushed, including the leaves still within the guard window.
+		guardWindow = 0
+	} else {
+		var idle bool
+		if backlog, idle = s.idle(ctx, tree, maxRootDuration); idle {
+			return 0, nil
+		}
+	}
+	refresh := refreshInterval(maxRootDuration, info.MaxRootDurationMargin)
+	ctx = withRangeCache(ctx, s.ranges)
+	var leaves int
+	if info.TXBatchSize > 0 {
+		leaves, err = IntegrateSplitBatch(ctx, tree, info.BatchSize, info.TXBatchSize, guardWindow, refresh, info.TimeSource, s.registry.LogStorage, s.registry.QuotaManager)
+	} else {
+		leaves, err = IntegrateBatch(ctx, tree, info.BatchSize, guardWindow, refresh, info.TimeSource, s.registry.LogStorage, s.registry.QuotaManager)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to integrate batch for %v: %v", logID, err)
 	}
+	if backlog >= 0 {
+		s.setBacklog(logID, max(backlog-int64(leaves), 0))
+	}
+	// A full batch may have left more leaves queued, in which case the
+	// request is kept for the next pass.
+	if requested != nil && leaves < info.BatchSize {
+		if err := s.clearSequencingRequest(ctx, logID, requested); err != nil {
+			return 0, fmt.Errorf("failed to clear sequencing request for %v: %v", logID, err)
+		}
+		klog.Infof("%v: queue flushed as requested at %v", logID, requested.AsTime())
+	}
+	if leaves == 0 && tree.AutoFreeze && tree.TreeState == trillian.TreeState_DRAINING {
+		if err := s.freezeIfDrained(ctx, tree, info.TimeSource.Now()); err != nil {
+			return 0, fmt.Errorf("failed to auto-freeze log %v: %v", logID, err)
+		}
+	}
 	return leaves, nil
 }
+
+// refreshInterval returns the age at which the root of a tree with the given
+// max_root_duration is replaced, even if there are no new leaves. The root is
+// replaced margin before it lapses, so that it is still fresh if the next pass
+// is late, but no earlier than half way through the max_root_duration.
+func refreshInterval(maxRootDuration, margin time.Duration) time.Duration {
+	if maxRootDuration <= 0 {
+		return 0
+	}
+	return maxRootDuration - min(margin, maxRootDuration/2)
+}
+
+// idle returns the size of the queue of the given tree, or -1 if it can't be
+// counted, and whether the sequencing pass over the tree can be skipped
+// because its queue is empty. Skipping saves a transaction for each of the
+// quiet trees on every pass.
+//
+// A pass is never skipped for trees which may need a new root with no leaves
+// to integrate, and PREORDERED_LOG trees are not queued, so their passes are
+// not skipped either.
+func (s *SequencerManager) idle(ctx context.Context, tree *trillian.Tree, maxRootDuration time.Duration) (int64, bool) {
+	if tree.TreeType != trillian.TreeType_LOG {
+		return -1, false
+	}
+	label := strconv.FormatInt(tree.TreeId, 10)
+	count, err := s.registry.LogStorage.CountUnsequenced(ctx, tree)
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			klog.Warningf("%v: failed to count unsequenced leaves: %v", tree.TreeId, err)
+		}
+		return -1, false
+	}
+	seqBacklog.Set(float64(count), label)
+	if count > 0 || maxRootDuration > 0 || (tree.AutoFreeze && tree.TreeState == trillian.TreeState_DRAINING) {
+		return count, false
+	}
+	klog.V(1).Infof("%v: No leaves queued, skipping sequencing pass", tree.TreeId)
+	seqIdleSkips.Inc(label)
+	s.setBacklog(tree.TreeId, 0)
+	return 0, true
+}
+
+func (s *SequencerManager) setBacklog(logID, backlog int64) {
+	s.backlogsMu.Lock()
+	defer s.backlogsMu.Unlock()
+	s.backlogs[logID] = backlog
+}
+
+// Backlog implements BacklogReporter.
+func (s *SequencerManager) Backlog(logID int64) (int64, bool) {
+	s.backlogsMu.Lock()
+	defer s.backlogsMu.Unlock()
+	backlog, ok := s.backlogs[logID]
+	return backlog, ok
+}
+
+// clearSequencingRequest clears the sequencing_requested_time of the given
+// tree, once its queue has been flushed. A request made since the tree was
+// read may not be covered by the flush, so it is kept.
+func (s *SequencerManager) clearSequencingRequest(ctx context.Context, treeID int64, requested *timestamppb.Timestamp) error {
+	return s.registry.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		_, err := tx.UpdateTree(ctx, treeID, func(t *trillian.Tree) {
+			if proto.Equal(t.SequencingRequestedTime, requested) {
+				t.SequencingRequestedTime = nil
+			}
+		})
+		return err
+	})
+}
+
+// freezeIfDrained transitions the given DRAINING tree to FROZEN if it has no
+// leaves left waiting to be integrated, either in the queue or staged beyond
+// the latest root by an interrupted split batch. Otherwise, the latest root
+// covers all of the tree's leaves.
+func (s *SequencerManager) freezeIfDrained(ctx context.Context, tree *trillian.Tree, now time.Time) error {
+	var drained bool
+	if err := s.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		// Ignore the guard window: a DRAINING tree accepts no new leaves, so
+		// anything still queued must be integrated before it can be frozen.
+		leaves, err := tx.DequeueLeaves(ctx, 1, now)
+		if err != nil {
+			return err
+		}
+		if drained = len(leaves) == 0; !drained {
+			return nil
+		}
+		if _, ok := tx.(storage.StagedLeafReader); !ok {
+			return nil
+		}
+		root, err := latestRoot(ctx, tree.TreeId, tx)
+		if err != nil {
+			return err
+		}
+		staged, err := hasStagedLeaves(ctx, tx, root.TreeSize)
+		drained = !staged
+		return err
+	}); err != nil {
+		return err
+	}
+	if !drained {
+		return nil
+	}
+
+	if err := s.registry.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		_, err := tx.UpdateTree(ctx, tree.TreeId, func(t *trillian.Tree) {
+			if t.TreeState == trillian.TreeState_DRAINING {
+				t.TreeState = trillian.TreeState_FROZEN
+			}
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+	klog.Infof("%v: log drained, tree state changed from DRAINING to FROZEN", tree.TreeId)
+	seqAutoFrozen.Inc(strconv.FormatInt(tree.TreeId, 10))
+	return nil
+}
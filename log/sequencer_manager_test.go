@@ -16,6 +16,7 @@ package log
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -24,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	mtestonly "github.com/google/trillian/monitoring/testonly"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
 	stestonly "github.com/google/trillian/storage/testonly"
@@ -34,6 +36,8 @@ import (
 	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Arbitrary time for use in tests
@@ -110,6 +114,68 @@ func TestSequencerManagerSingleLogNoLeaves(t *testing.T) {
 	}
 }
 
+func TestSequencerManagerSkipsIdleLog(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		desc     string
+		tree     *trillian.Tree
+		count    int64
+		countErr error
+		wantPass bool
+		// wantBacklog is the backlog reported after the pass, or -1 if none.
+		wantBacklog int64
+	}{
+		{desc: "idle", tree: stestonly.LogTree},
+		{desc: "queued", tree: stestonly.LogTree, count: 3, wantPass: true, wantBacklog: 3},
+		{desc: "count-error", tree: stestonly.LogTree, countErr: errors.New("no database"), wantPass: true, wantBacklog: -1},
+		{desc: "max-root-duration", tree: withMaxRootDuration(stestonly.LogTree, time.Hour), wantPass: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			logID := test.tree.TreeId
+
+			mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(test.tree, nil)
+			mockAdminTx.EXPECT().Commit().Return(nil)
+			mockAdminTx.EXPECT().Close().Return(nil)
+			mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+
+			mockStorage := storage.NewMockLogStorage(mockCtrl)
+			mockStorage.EXPECT().CountUnsequenced(gomock.Any(), gomock.Any()).Return(test.count, test.countErr)
+			if test.wantPass {
+				mockStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			}
+			registry := extension.Registry{
+				AdminStorage: mockAdmin,
+				LogStorage:   mockStorage,
+				QuotaManager: quota.Noop(),
+			}
+
+			sm := NewSequencerManager(registry, zeroDuration)
+			skips := mtestonly.NewCounterSnapshot(seqIdleSkips, fmt.Sprint(logID))
+			if _, err := sm.ExecutePass(ctx, logID, createTestInfo(registry)); err != nil {
+				t.Fatalf("ExecutePass(): %v", err)
+			}
+			if got, want := skips.Delta() == 1, !test.wantPass; got != want {
+				t.Errorf("skipped = %v, want %v", got, want)
+			}
+			backlog, ok := sm.Backlog(logID)
+			if !ok {
+				backlog = -1
+			}
+			if backlog != test.wantBacklog {
+				t.Errorf("Backlog() = %d, want %d", backlog, test.wantBacklog)
+			}
+		})
+	}
+}
+
+func withMaxRootDuration(tree *trillian.Tree, d time.Duration) *trillian.Tree {
+	tree = proto.Clone(tree).(*trillian.Tree)
+	tree.MaxRootDuration = durationpb.New(d)
+	return tree
+}
+
 func TestSequencerManagerCachesSigners(t *testing.T) {
 	ctx := context.Background()
 	mockCtrl := gomock.NewController(t)
@@ -233,6 +299,67 @@ func TestSequencerManagerGuardWindow(t *testing.T) {
 	}
 }
 
+func TestSequencerManagerTriggeredSequencing(t *testing.T) {
+	requested := timestamppb.New(fakeTime.Add(-time.Minute))
+	for _, test := range []struct {
+		desc       string
+		storedTime *timestamppb.Timestamp
+		wantClear  bool
+	}{
+		{desc: "flushed", storedTime: requested, wantClear: true},
+		{desc: "requested-again", storedTime: timestamppb.New(fakeTime)},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+			tree.SequencingRequestedTime = requested
+			logID := tree.TreeId
+
+			mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+			mockAdminTx.EXPECT().Commit().Return(nil)
+			mockAdminTx.EXPECT().Close().Return(nil)
+			mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+
+			mockTx := storage.NewMockLogTreeTX(mockCtrl)
+			mockTx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(testSignedRoot0, nil)
+			// The guard window is ignored when flushing the queue.
+			mockTx.EXPECT().DequeueLeaves(gomock.Any(), 50, fakeTime).Return([]*trillian.LogLeaf{}, nil)
+			mockTx.EXPECT().Commit(gomock.Any()).Return(nil)
+			mockTx.EXPECT().Close().Return(nil)
+
+			var updatedTree *trillian.Tree
+			mockAdminRWTx := storage.NewMockAdminTX(mockCtrl)
+			mockAdminRWTx.EXPECT().UpdateTree(gomock.Any(), logID, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ int64, f func(*trillian.Tree)) (*trillian.Tree, error) {
+					updatedTree = proto.Clone(tree).(*trillian.Tree)
+					updatedTree.SequencingRequestedTime = test.storedTime
+					f(updatedTree)
+					return updatedTree, nil
+				})
+			mockAdminRWTx.EXPECT().Commit().Return(nil)
+			mockAdminRWTx.EXPECT().Close().Return(nil)
+			mockAdmin.TX = []storage.AdminTX{mockAdminRWTx}
+
+			registry := extension.Registry{
+				AdminStorage: mockAdmin,
+				LogStorage:   &stestonly.FakeLogStorage{TX: mockTx},
+				QuotaManager: quota.Noop(),
+			}
+			sm := NewSequencerManager(registry, time.Second*5)
+			if _, err := sm.ExecutePass(ctx, logID, createTestInfo(registry)); err != nil {
+				t.Fatalf("ExecutePass(): %v", err)
+			}
+			if got := updatedTree.SequencingRequestedTime == nil; got != test.wantClear {
+				t.Errorf("sequencing_requested_time cleared: %v, want %v", got, test.wantClear)
+			}
+		})
+	}
+}
+
 func createTestInfo(registry extension.Registry) *OperationInfo {
 	// Set sign interval to 100 years so it won't trigger a root expiry signing unless overridden
 	return &OperationInfo{
@@ -242,3 +369,117 @@ func createTestInfo(registry extension.Registry) *OperationInfo {
 		TimeSource:  fakeTimeSource,
 	}
 }
+
+func TestSequencerManagerAutoFreeze(t *testing.T) {
+	for _, test := range []struct {
+		desc       string
+		autoFreeze bool
+		queued     []*trillian.LogLeaf
+		wantFreeze bool
+	}{
+		{desc: "drained", autoFreeze: true, wantFreeze: true},
+		{desc: "not-drained", autoFreeze: true, queued: []*trillian.LogLeaf{testLeaf0}},
+		{desc: "disabled"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+			tree.TreeState = trillian.TreeState_DRAINING
+			tree.AutoFreeze = test.autoFreeze
+			logID := tree.TreeId
+
+			mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+			mockAdminTx.EXPECT().Commit().Return(nil)
+			mockAdminTx.EXPECT().Close().Return(nil)
+			mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+
+			mockTx := storage.NewMockLogTreeTX(mockCtrl)
+			mockTx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(testSignedRoot0, nil)
+			mockTx.EXPECT().DequeueLeaves(gomock.Any(), 50, fakeTime).Return([]*trillian.LogLeaf{}, nil)
+			numTX := 1
+			if test.autoFreeze {
+				numTX++
+				mockTx.EXPECT().DequeueLeaves(gomock.Any(), 1, fakeTime).Return(test.queued, nil)
+			}
+			mockTx.EXPECT().Commit(gomock.Any()).Times(numTX).Return(nil)
+			mockTx.EXPECT().Close().Times(numTX).Return(nil)
+
+			var updatedTree *trillian.Tree
+			if test.wantFreeze {
+				mockAdminRWTx := storage.NewMockAdminTX(mockCtrl)
+				mockAdminRWTx.EXPECT().UpdateTree(gomock.Any(), logID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, f func(*trillian.Tree)) (*trillian.Tree, error) {
+						updatedTree = proto.Clone(tree).(*trillian.Tree)
+						f(updatedTree)
+						return updatedTree, nil
+					})
+				mockAdminRWTx.EXPECT().Commit().Return(nil)
+				mockAdminRWTx.EXPECT().Close().Return(nil)
+				mockAdmin.TX = []storage.AdminTX{mockAdminRWTx}
+			}
+
+			registry := extension.Registry{
+				AdminStorage: mockAdmin,
+				LogStorage:   &stestonly.FakeLogStorage{TX: mockTx},
+				QuotaManager: quota.Noop(),
+			}
+			sm := NewSequencerManager(registry, zeroDuration)
+			if _, err := sm.ExecutePass(ctx, logID, createTestInfo(registry)); err != nil {
+				t.Fatalf("ExecutePass(): %v", err)
+			}
+			if test.wantFreeze {
+				if got, want := updatedTree.TreeState, trillian.TreeState_FROZEN; got != want {
+					t.Errorf("TreeState = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	for _, test := range []struct {
+		maxRootDuration, margin, want time.Duration
+	}{
+		{maxRootDuration: 0, margin: time.Second, want: 0},
+		{maxRootDuration: time.Hour, margin: 0, want: time.Hour},
+		{maxRootDuration: time.Hour, margin: time.Minute, want: 59 * time.Minute},
+		{maxRootDuration: time.Hour, margin: 2 * time.Hour, want: 30 * time.Minute},
+	} {
+		if got := refreshInterval(test.maxRootDuration, test.margin); got != test.want {
+			t.Errorf("refreshInterval(%v, %v) = %v, want %v", test.maxRootDuration, test.margin, got, test.want)
+		}
+	}
+}
+
+func TestSequencerRefreshesRootBeforeExpiry(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	tree, ls := newMemoryLog(ctx, t, 0)
+	tree.MaxRootDuration = durationpb.New(time.Hour)
+	label := fmt.Sprint(tree.TreeId)
+	// The initial root is signed at time.Unix(0, 1).
+	ts := clock.NewFake(time.Unix(0, 1))
+	refresh := refreshInterval(time.Hour, 15*time.Minute)
+
+	for _, test := range []struct {
+		desc       string
+		age        time.Duration
+		wantExpiry float64
+	}{
+		{desc: "fresh", age: 30 * time.Minute, wantExpiry: (30 * time.Minute).Seconds()},
+		{desc: "about-to-lapse", age: 50 * time.Minute, wantExpiry: time.Hour.Seconds()},
+	} {
+		ts.Set(time.Unix(0, 1).Add(test.age))
+		if _, err := IntegrateBatch(ctx, tree, 10, 0, refresh, ts, ls, quota.Noop()); err != nil {
+			t.Fatalf("%s: IntegrateBatch(): %v", test.desc, err)
+		}
+		if got := seqRootExpiry.Value(label); got != test.wantExpiry {
+			t.Errorf("%s: root expiry = %v, want %v", test.desc, got, test.wantExpiry)
+		}
+	}
+}
@@ -15,7 +15,9 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strings"
@@ -24,13 +26,16 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
 	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/protobuf/proto"
 
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
@@ -301,6 +306,7 @@ func TestIntegrateBatch(t *testing.T) {
 		params          testParameters
 		guardWindow     time.Duration
 		maxRootDuration time.Duration
+		maxTreeSize     int64
 		wantCount       int
 		errStr          string
 	}{
@@ -523,6 +529,33 @@ func TestIntegrateBatch(t *testing.T) {
 			},
 			wantCount: 1,
 		},
+		{
+			desc: "sequence-leaf-16-below-max-tree-size",
+			params: testParameters{
+				logID:            154035,
+				dequeueLimit:     1,
+				shouldCommit:     true,
+				dequeuedLeaves:   []*trillian.LogLeaf{getLeaf42()},
+				latestSignedRoot: testSignedRoot16,
+				merkleNodesGet:   &compactTree16,
+				updatedLeaves:    &leaves16,
+				merkleNodesSet:   &updatedNodes,
+				storeSignedRoot:  testSignedRoot,
+			},
+			maxTreeSize: 17,
+			wantCount:   1,
+		},
+		{
+			desc: "at-max-tree-size",
+			params: testParameters{
+				logID:               154035,
+				shouldCommit:        true,
+				skipDequeue:         true,
+				latestSignedRoot:    testSignedRoot16,
+				skipStoreSignedRoot: true,
+			},
+			maxTreeSize: 16,
+		},
 		{
 			desc: "prev-root-timestamp-equals",
 			params: testParameters{
@@ -564,7 +597,7 @@ func TestIntegrateBatch(t *testing.T) {
 				qm.EXPECT().PutTokens(gomock.Any(), test.wantCount, specs).Return(nil)
 			}
 			c, ctx := createTestContext(ctrl, test.params)
-			tree := &trillian.Tree{TreeId: test.params.logID, TreeType: trillian.TreeType_LOG}
+			tree := &trillian.Tree{TreeId: test.params.logID, TreeType: trillian.TreeType_LOG, MaxTreeSize: test.maxTreeSize}
 
 			got, err := IntegrateBatch(ctx, tree, 1, test.guardWindow, test.maxRootDuration, c.timeSource, c.fakeStorage, c.qm)
 			if err != nil {
@@ -691,3 +724,130 @@ func TestIntegrateBatch_PutTokens(t *testing.T) {
 		}()
 	}
 }
+
+func TestSortByQueueTimestamp(t *testing.T) {
+	leaf := func(id string, offset time.Duration) *trillian.LogLeaf {
+		return &trillian.LogLeaf{
+			LeafIdentityHash: []byte(id),
+			QueueTimestamp:   testonly.MustToTimestampProto(fakeTime.Add(offset)),
+		}
+	}
+	leaves := []*trillian.LogLeaf{
+		leaf("d", 2*time.Second),
+		leaf("c", time.Second),
+		leaf("a", 2*time.Second),
+		leaf("b", 0),
+	}
+	sortByQueueTimestamp(leaves)
+
+	var got []string
+	for _, l := range leaves {
+		got = append(got, string(l.LeafIdentityHash))
+	}
+	if want := []string{"b", "c", "a", "d"}; strings.Join(got, "") != strings.Join(want, "") {
+		t.Errorf("sortByQueueTimestamp() order = %v, want %v", got, want)
+	}
+}
+
+// rangeFactory creates the compact ranges of the test trees, which use the
+// default hasher.
+var rangeFactory = hashers.RangeFactory(rfc6962.DefaultHasher)
+
+func TestIntegrateBatchHasher(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 20
+	tree, ls := newMemoryLogWithHasher(ctx, t, leafCount, hashers.RFC6962SHA512_256)
+	n, err := IntegrateBatch(ctx, tree, 2*leafCount, 0, 0, clock.System, ls, quota.Noop())
+	if err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	if n != leafCount {
+		t.Fatalf("IntegrateBatch() = %d, want %d", n, leafCount)
+	}
+
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		t.Fatalf("ForTree(): %v", err)
+	}
+	rf := hashers.RangeFactory(hasher)
+	if _, err := initCompactRangeFromStorage(ctx, rf, &root, tx); err != nil {
+		t.Errorf("initCompactRangeFromStorage(): %v", err)
+	}
+	sequenced, err := tx.GetLeavesByRange(ctx, 0, leafCount)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	cr := rf.NewEmptyRange(0)
+	for _, leaf := range sequenced {
+		if err := cr.Append(leaf.MerkleLeafHash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+	}
+	want, err := cr.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash(): %v", err)
+	}
+	if !bytes.Equal(root.RootHash, want) {
+		t.Errorf("root hash = %x, want %x", root.RootHash, want)
+	}
+}
+
+// newMemoryLog returns an initialised log in memory storage, with leafCount
+// leaves queued.
+func newMemoryLog(ctx context.Context, t *testing.T, leafCount int) (*trillian.Tree, storage.LogStorage) {
+	t.Helper()
+	return newMemoryLogWithHasher(ctx, t, leafCount, "")
+}
+
+// newMemoryLogWithHasher is like newMemoryLog, but the log uses the hasher
+// registered as hasherID.
+func newMemoryLogWithHasher(ctx context.Context, t *testing.T, leafCount int, hasherID string) (*trillian.Tree, storage.LogStorage) {
+	t.Helper()
+	hasher, err := hashers.Get(hasherID)
+	if err != nil {
+		t.Fatalf("Get(): %v", err)
+	}
+	ts := memory.NewTreeStorage()
+	ls := memory.NewLogStorage(ts, nil)
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.HasherId = hasherID
+	tree, err = storage.CreateTree(ctx, memory.NewAdminStorage(ts), tree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		logRoot, err := (&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: 1}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}); err != nil {
+		t.Fatalf("Failed to initialise log: %v", err)
+	}
+
+	leaves := make([]*trillian.LogLeaf, 0, leafCount)
+	for i := 0; i < leafCount; i++ {
+		value := []byte(fmt.Sprintf("leaf %d", i))
+		idHash := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: idHash[:], MerkleLeafHash: hasher.HashLeaf(value)})
+	}
+	if _, err := ls.QueueLeaves(ctx, tree, leaves, clock.System.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	return tree, ls
+}
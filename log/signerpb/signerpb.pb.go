@@ -0,0 +1,425 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v3.20.1
+// source: signerpb.proto
+
+package signerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetSignerStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// If set, only the status of the trees this signer is master for is
+	// returned.
+	MasterOnly    bool `protobuf:"varint,1,opt,name=master_only,json=masterOnly,proto3" json:"master_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSignerStatusRequest) Reset() {
+	*x = GetSignerStatusRequest{}
+	mi := &file_signerpb_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSignerStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignerStatusRequest) ProtoMessage() {}
+
+func (x *GetSignerStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signerpb_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignerStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSignerStatusRequest) Descriptor() ([]byte, []int) {
+	return file_signerpb_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetSignerStatusRequest) GetMasterOnly() bool {
+	if x != nil {
+		return x.MasterOnly
+	}
+	return false
+}
+
+type GetSignerStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The status of each tree, ordered by tree ID.
+	Trees         []*TreeStatus `protobuf:"bytes,1,rep,name=trees,proto3" json:"trees,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSignerStatusResponse) Reset() {
+	*x = GetSignerStatusResponse{}
+	mi := &file_signerpb_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSignerStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignerStatusResponse) ProtoMessage() {}
+
+func (x *GetSignerStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_signerpb_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignerStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetSignerStatusResponse) Descriptor() ([]byte, []int) {
+	return file_signerpb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSignerStatusResponse) GetTrees() []*TreeStatus {
+	if x != nil {
+		return x.Trees
+	}
+	return nil
+}
+
+// The status of a tree, as seen by a signer.
+type TreeStatus struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TreeId      int64                  `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	DisplayName string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// Whether this signer holds mastership for the tree.
+	Master bool `protobuf:"varint,3,opt,name=master,proto3" json:"master,omitempty"`
+	// When this signer last ran a sequencing pass for the tree, whether or not
+	// it succeeded. Unset if it never has.
+	LastRunTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_run_time,json=lastRunTime,proto3" json:"last_run_time,omitempty"`
+	// The error of the last sequencing pass, if it failed.
+	LastRunError string `protobuf:"bytes,5,opt,name=last_run_error,json=lastRunError,proto3" json:"last_run_error,omitempty"`
+	// When this signer last integrated a non-empty batch of leaves into the
+	// tree, and the number of leaves in it. Unset if it never has.
+	LastBatchTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_batch_time,json=lastBatchTime,proto3" json:"last_batch_time,omitempty"`
+	LastBatchSize int64                  `protobuf:"varint,7,opt,name=last_batch_size,json=lastBatchSize,proto3" json:"last_batch_size,omitempty"`
+	// The number of leaves queued for the tree, or -1 if the storage can't
+	// count them.
+	Backlog int64 `protobuf:"varint,8,opt,name=backlog,proto3" json:"backlog,omitempty"`
+	// The compliance of the tree with its merge delay target. Unset if the tree
+	// has no merge_delay_target.
+	MergeDelaySlo *MergeDelaySLO `protobuf:"bytes,9,opt,name=merge_delay_slo,json=mergeDelaySlo,proto3" json:"merge_delay_slo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TreeStatus) Reset() {
+	*x = TreeStatus{}
+	mi := &file_signerpb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TreeStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeStatus) ProtoMessage() {}
+
+func (x *TreeStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_signerpb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeStatus.ProtoReflect.Descriptor instead.
+func (*TreeStatus) Descriptor() ([]byte, []int) {
+	return file_signerpb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TreeStatus) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *TreeStatus) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *TreeStatus) GetMaster() bool {
+	if x != nil {
+		return x.Master
+	}
+	return false
+}
+
+func (x *TreeStatus) GetLastRunTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRunTime
+	}
+	return nil
+}
+
+func (x *TreeStatus) GetLastRunError() string {
+	if x != nil {
+		return x.LastRunError
+	}
+	return ""
+}
+
+func (x *TreeStatus) GetLastBatchTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastBatchTime
+	}
+	return nil
+}
+
+func (x *TreeStatus) GetLastBatchSize() int64 {
+	if x != nil {
+		return x.LastBatchSize
+	}
+	return 0
+}
+
+func (x *TreeStatus) GetBacklog() int64 {
+	if x != nil {
+		return x.Backlog
+	}
+	return 0
+}
+
+func (x *TreeStatus) GetMergeDelaySlo() *MergeDelaySLO {
+	if x != nil {
+		return x.MergeDelaySlo
+	}
+	return nil
+}
+
+// The number of leaves of a tree integrated by a signer within the
+// merge_delay_target of the tree, over a rolling window.
+type MergeDelaySLO struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The merge_delay_target of the tree.
+	Target *durationpb.Duration `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// The length of the window.
+	Window *durationpb.Duration `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	// The number of leaves integrated during the window, and how many of them
+	// were integrated within the target of being queued.
+	Leaves             int64 `protobuf:"varint,3,opt,name=leaves,proto3" json:"leaves,omitempty"`
+	LeavesWithinTarget int64 `protobuf:"varint,4,opt,name=leaves_within_target,json=leavesWithinTarget,proto3" json:"leaves_within_target,omitempty"`
+	// The percentage of the leaves integrated within the target, or 100 if no
+	// leaves were integrated.
+	Compliance    float64 `protobuf:"fixed64,5,opt,name=compliance,proto3" json:"compliance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeDelaySLO) Reset() {
+	*x = MergeDelaySLO{}
+	mi := &file_signerpb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeDelaySLO) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeDelaySLO) ProtoMessage() {}
+
+func (x *MergeDelaySLO) ProtoReflect() protoreflect.Message {
+	mi := &file_signerpb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeDelaySLO.ProtoReflect.Descriptor instead.
+func (*MergeDelaySLO) Descriptor() ([]byte, []int) {
+	return file_signerpb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MergeDelaySLO) GetTarget() *durationpb.Duration {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *MergeDelaySLO) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *MergeDelaySLO) GetLeaves() int64 {
+	if x != nil {
+		return x.Leaves
+	}
+	return 0
+}
+
+func (x *MergeDelaySLO) GetLeavesWithinTarget() int64 {
+	if x != nil {
+		return x.LeavesWithinTarget
+	}
+	return 0
+}
+
+func (x *MergeDelaySLO) GetCompliance() float64 {
+	if x != nil {
+		return x.Compliance
+	}
+	return 0
+}
+
+var File_signerpb_proto protoreflect.FileDescriptor
+
+const file_signerpb_proto_rawDesc = "" +
+	"\n" +
+	"\x0esignerpb.proto\x12\bsignerpb\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"9\n" +
+	"\x16GetSignerStatusRequest\x12\x1f\n" +
+	"\vmaster_only\x18\x01 \x01(\bR\n" +
+	"masterOnly\"E\n" +
+	"\x17GetSignerStatusResponse\x12*\n" +
+	"\x05trees\x18\x01 \x03(\v2\x14.signerpb.TreeStatusR\x05trees\"\x8d\x03\n" +
+	"\n" +
+	"TreeStatus\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x16\n" +
+	"\x06master\x18\x03 \x01(\bR\x06master\x12>\n" +
+	"\rlast_run_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vlastRunTime\x12$\n" +
+	"\x0elast_run_error\x18\x05 \x01(\tR\flastRunError\x12B\n" +
+	"\x0flast_batch_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rlastBatchTime\x12&\n" +
+	"\x0flast_batch_size\x18\a \x01(\x03R\rlastBatchSize\x12\x18\n" +
+	"\abacklog\x18\b \x01(\x03R\abacklog\x12?\n" +
+	"\x0fmerge_delay_slo\x18\t \x01(\v2\x17.signerpb.MergeDelaySLOR\rmergeDelaySlo\"\xdf\x01\n" +
+	"\rMergeDelaySLO\x121\n" +
+	"\x06target\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06target\x121\n" +
+	"\x06window\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06window\x12\x16\n" +
+	"\x06leaves\x18\x03 \x01(\x03R\x06leaves\x120\n" +
+	"\x14leaves_within_target\x18\x04 \x01(\x03R\x12leavesWithinTarget\x12\x1e\n" +
+	"\n" +
+	"compliance\x18\x05 \x01(\x01R\n" +
+	"compliance2b\n" +
+	"\x06Signer\x12X\n" +
+	"\x0fGetSignerStatus\x12 .signerpb.GetSignerStatusRequest\x1a!.signerpb.GetSignerStatusResponse\"\x00B)Z'github.com/google/trillian/log/signerpbb\x06proto3"
+
+var (
+	file_signerpb_proto_rawDescOnce sync.Once
+	file_signerpb_proto_rawDescData []byte
+)
+
+func file_signerpb_proto_rawDescGZIP() []byte {
+	file_signerpb_proto_rawDescOnce.Do(func() {
+		file_signerpb_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_signerpb_proto_rawDesc), len(file_signerpb_proto_rawDesc)))
+	})
+	return file_signerpb_proto_rawDescData
+}
+
+var file_signerpb_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_signerpb_proto_goTypes = []any{
+	(*GetSignerStatusRequest)(nil),  // 0: signerpb.GetSignerStatusRequest
+	(*GetSignerStatusResponse)(nil), // 1: signerpb.GetSignerStatusResponse
+	(*TreeStatus)(nil),              // 2: signerpb.TreeStatus
+	(*MergeDelaySLO)(nil),           // 3: signerpb.MergeDelaySLO
+	(*timestamppb.Timestamp)(nil),   // 4: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),     // 5: google.protobuf.Duration
+}
+var file_signerpb_proto_depIdxs = []int32{
+	2, // 0: signerpb.GetSignerStatusResponse.trees:type_name -> signerpb.TreeStatus
+	4, // 1: signerpb.TreeStatus.last_run_time:type_name -> google.protobuf.Timestamp
+	4, // 2: signerpb.TreeStatus.last_batch_time:type_name -> google.protobuf.Timestamp
+	3, // 3: signerpb.TreeStatus.merge_delay_slo:type_name -> signerpb.MergeDelaySLO
+	5, // 4: signerpb.MergeDelaySLO.target:type_name -> google.protobuf.Duration
+	5, // 5: signerpb.MergeDelaySLO.window:type_name -> google.protobuf.Duration
+	0, // 6: signerpb.Signer.GetSignerStatus:input_type -> signerpb.GetSignerStatusRequest
+	1, // 7: signerpb.Signer.GetSignerStatus:output_type -> signerpb.GetSignerStatusResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_signerpb_proto_init() }
+func file_signerpb_proto_init() {
+	if File_signerpb_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_signerpb_proto_rawDesc), len(file_signerpb_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_signerpb_proto_goTypes,
+		DependencyIndexes: file_signerpb_proto_depIdxs,
+		MessageInfos:      file_signerpb_proto_msgTypes,
+	}.Build()
+	File_signerpb_proto = out.File
+	file_signerpb_proto_goTypes = nil
+	file_signerpb_proto_depIdxs = nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/log/signerpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// MergeDelaySLOWindow is the rolling window over which the compliance of
+// trees with their merge_delay_target is computed.
+var MergeDelaySLOWindow = 24 * time.Hour
+
+// sloBuckets is the number of buckets the window is split into. Leaves drop
+// out of the window a bucket at a time.
+const sloBuckets = 96
+
+var (
+	slosMu sync.Mutex
+	// slos holds the merge delay SLO of each tree with a merge_delay_target,
+	// keyed by tree ID.
+	slos = make(map[int64]*mergeDelaySLO)
+)
+
+// mergeDelaySLO counts the leaves of a tree integrated within its
+// merge_delay_target, in buckets covering the window.
+type mergeDelaySLO struct {
+	mu      sync.Mutex
+	target  time.Duration
+	buckets [sloBuckets]sloBucket
+}
+
+type sloBucket struct {
+	// n is the number of the bucket since the epoch.
+	n                    int64
+	leaves, withinTarget int64
+}
+
+// sloBucketWidth returns the length of time covered by each bucket.
+func sloBucketWidth() time.Duration {
+	return max(MergeDelaySLOWindow/sloBuckets, time.Nanosecond)
+}
+
+// recordMergeDelays adds the leaves integrated into tree at now to its merge
+// delay SLO, if it has a merge_delay_target, and exports the resulting
+// compliance. Leaves without a queue timestamp are not counted. It should be
+// called once the leaves are committed, even if there are none, so that the
+// compliance follows the window as it moves on.
+func recordMergeDelays(tree *trillian.Tree, label string, leaves []*trillian.LogLeaf, now time.Time) {
+	target := tree.MergeDelayTarget.AsDuration()
+	if target <= 0 || !tree.MergeDelayTarget.IsValid() {
+		slosMu.Lock()
+		delete(slos, tree.TreeId)
+		slosMu.Unlock()
+		return
+	}
+	slosMu.Lock()
+	slo, ok := slos[tree.TreeId]
+	if !ok {
+		slo = &mergeDelaySLO{}
+		slos[tree.TreeId] = slo
+	}
+	slosMu.Unlock()
+
+	slo.mu.Lock()
+	defer slo.mu.Unlock()
+	slo.target = target
+	n := now.UnixNano() / int64(sloBucketWidth())
+	b := &slo.buckets[n%sloBuckets]
+	if b.n != n {
+		*b = sloBucket{n: n}
+	}
+	for _, leaf := range leaves {
+		if leaf.QueueTimestamp == nil || leaf.QueueTimestamp.Seconds == 0 || leaf.IntegrateTimestamp == nil {
+			continue
+		}
+		b.leaves++
+		if leaf.IntegrateTimestamp.AsTime().Sub(leaf.QueueTimestamp.AsTime()) <= target {
+			b.withinTarget++
+		}
+	}
+	seqMergeDelayCompliance.Set(slo.status(n).Compliance, label)
+}
+
+// status returns the SLO as of bucket n. The caller must hold slo.mu.
+func (slo *mergeDelaySLO) status(n int64) *signerpb.MergeDelaySLO {
+	ret := &signerpb.MergeDelaySLO{
+		Target: durationpb.New(slo.target),
+		Window: durationpb.New(MergeDelaySLOWindow),
+	}
+	for _, b := range slo.buckets {
+		if b.n > n-sloBuckets && b.n <= n {
+			ret.Leaves += b.leaves
+			ret.LeavesWithinTarget += b.withinTarget
+		}
+	}
+	ret.Compliance = 100
+	if ret.Leaves > 0 {
+		ret.Compliance = 100 * float64(ret.LeavesWithinTarget) / float64(ret.Leaves)
+	}
+	return ret
+}
+
+// mergeDelaySLOStatus returns the merge delay SLO of the given tree as of now,
+// or nil if it has none.
+func mergeDelaySLOStatus(treeID int64, now time.Time) *signerpb.MergeDelaySLO {
+	slosMu.Lock()
+	slo, ok := slos[treeID]
+	slosMu.Unlock()
+	if !ok {
+		return nil
+	}
+	slo.mu.Lock()
+	defer slo.mu.Unlock()
+	return slo.status(now.UnixNano() / int64(sloBucketWidth()))
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMergeDelaySLO(t *testing.T) {
+	InitMetrics(nil)
+	tree := &trillian.Tree{TreeId: 1712, MergeDelayTarget: durationpb.New(time.Minute)}
+	now := time.Unix(1700000000, 0)
+	leaf := func(delay time.Duration) *trillian.LogLeaf {
+		return &trillian.LogLeaf{
+			QueueTimestamp:     timestamppb.New(now.Add(-delay)),
+			IntegrateTimestamp: timestamppb.New(now),
+		}
+	}
+
+	if got := mergeDelaySLOStatus(tree.TreeId, now); got != nil {
+		t.Fatalf("mergeDelaySLOStatus() before any leaves = %v, want nil", got)
+	}
+	recordMergeDelays(tree, "1712", nil, now)
+	if got := mergeDelaySLOStatus(tree.TreeId, now); got.GetLeaves() != 0 || got.GetCompliance() != 100 {
+		t.Errorf("mergeDelaySLOStatus() with no leaves = %v, want 100%% compliance", got)
+	}
+
+	recordMergeDelays(tree, "1712", []*trillian.LogLeaf{leaf(time.Second), leaf(time.Minute), leaf(time.Hour), {}}, now)
+	got := mergeDelaySLOStatus(tree.TreeId, now)
+	if got.GetLeaves() != 3 || got.GetLeavesWithinTarget() != 2 {
+		t.Errorf("mergeDelaySLOStatus() = %v, want 2 of 3 leaves within target", got)
+	}
+	if want := 200.0 / 3; got.GetCompliance() != want {
+		t.Errorf("Compliance = %v, want %v", got.GetCompliance(), want)
+	}
+	if v := seqMergeDelayCompliance.Value("1712"); v != got.GetCompliance() {
+		t.Errorf("sequencer_merge_delay_compliance = %v, want %v", v, got.GetCompliance())
+	}
+
+	// The leaves drop out of the window once it has passed.
+	if got := mergeDelaySLOStatus(tree.TreeId, now.Add(MergeDelaySLOWindow)); got.GetLeaves() != 0 {
+		t.Errorf("mergeDelaySLOStatus() after the window = %v, want no leaves", got)
+	}
+
+	// The SLO is dropped with the target.
+	tree.MergeDelayTarget = nil
+	recordMergeDelays(tree, "1712", nil, now)
+	if got := mergeDelaySLOStatus(tree.TreeId, now); got != nil {
+		t.Errorf("mergeDelaySLOStatus() without a target = %v, want nil", got)
+	}
+}
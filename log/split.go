@@ -0,0 +1,315 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// errStagingUnsupported is returned when the storage does not implement
+// storage.StagedLeafReader.
+var errStagingUnsupported = errors.New("storage does not support staged leaves")
+
+// errStagedLeaves is returned by the transaction of IntegrateBatch when
+// it finds leaves staged by an interrupted split batch.
+var errStagedLeaves = errors.New("leaves are staged beyond the latest root")
+
+// IntegrateSplitBatch is like IntegrateBatch, but it spreads the writes of the
+// batch over several storage transactions, each of which integrates at most
+// txLimit leaves, so that a large batch can't exceed the transaction size
+// limits of the database. The leaves and Merkle nodes of all but the last
+// chunk are staged beyond the tree size of the latest root, where they are not
+// visible to readers, and the transaction of the last chunk stores the root
+// which publishes the whole batch.
+//
+// If a batch is interrupted before its root is stored, the next batch picks up
+// the staged leaves before dequeuing any more. If the storage does not
+// implement storage.StagedLeafReader, the batch is integrated in a single
+// transaction, as by IntegrateBatch.
+func IntegrateSplitBatch(ctx context.Context, tree *trillian.Tree, limit, txLimit int, guardWindow, maxRootDurationInterval time.Duration, ts clock.TimeSource, ls storage.LogStorage, qm quota.Manager) (int, error) {
+	if txLimit <= 0 || limit <= 0 {
+		return IntegrateBatch(ctx, tree, limit, guardWindow, maxRootDurationInterval, ts, ls, qm)
+	}
+	switch tree.TreeType {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+	default:
+		return 0, fmt.Errorf("IntegrateBatch not supported for TreeType %v", tree.TreeType)
+	}
+
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", tree.TreeId, err)
+	}
+	start := ts.Now()
+	b := &splitBatch{
+		tree:            tree,
+		hasher:          hasher,
+		rf:              hashers.RangeFactory(hasher),
+		label:           strconv.FormatInt(tree.TreeId, 10),
+		timeSource:      ts,
+		start:           start,
+		cutoff:          start.Add(-guardWindow),
+		limit:           limit,
+		txLimit:         txLimit,
+		maxRootDuration: maxRootDurationInterval,
+	}
+	newLogRoot, err := b.integrateChunk(ctx, ls)
+	if errors.Is(err, errStagingUnsupported) {
+		klog.V(1).Infof("%v: %v, integrating the batch in a single transaction", tree.TreeId, err)
+		return IntegrateBatch(ctx, tree, limit, guardWindow, maxRootDurationInterval, ts, ls, qm)
+	}
+	defer seqBatches.Inc(b.label)
+	defer func() { seqLatency.Observe(clock.SecondsSince(ts, start), b.label) }()
+	for err == nil && !b.done {
+		newLogRoot, err = b.integrateChunk(ctx, ls)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if newLogRoot != nil {
+		setRootExpiry(tree, b.label, newLogRoot, ts)
+	} else {
+		setRootExpiry(tree, b.label, b.root, ts)
+	}
+
+	// Let quota.Manager know about newly-sequenced entries.
+	numLeaves := int(b.cr.End() - b.root.TreeSize)
+	replenishQuota(ctx, numLeaves, tree.TreeId, qm)
+
+	seqCounter.Add(float64(numLeaves), b.label)
+	if newLogRoot != nil {
+		klog.Infof("%v: sequenced %v leaves in %d transactions, size %v", tree.TreeId, numLeaves, b.chunks, newLogRoot.TreeSize)
+	}
+	return numLeaves, nil
+}
+
+// splitBatch holds the state of a batch integrated by IntegrateSplitBatch
+// between its transactions.
+type splitBatch struct {
+	tree            *trillian.Tree
+	hasher          merkle.LogHasher
+	rf              *compact.RangeFactory
+	label           string
+	timeSource      clock.TimeSource
+	start           time.Time
+	cutoff          time.Time
+	limit           int
+	txLimit         int
+	maxRootDuration time.Duration
+
+	// root is the latest root, which the batch is integrated on top of.
+	root *types.LogRootV1
+	// cr is the compact range of the tree including the staged leaves.
+	cr *compact.Range
+	// chunks is the number of transactions committed.
+	chunks int
+	// done is set once the root of the batch is stored, or found unneeded.
+	done bool
+	// staged holds the leaves newly staged by the latest call to stage, which
+	// are only integrated if its transaction commits.
+	staged []*trillian.LogLeaf
+}
+
+// integrateChunk runs a transaction integrating the next chunk of the batch.
+// If the chunk is the last one, it also stores the new root, and returns it.
+func (b *splitBatch) integrateChunk(ctx context.Context, ls storage.LogStorage) (*types.LogRootV1, error) {
+	var newLogRoot *types.LogRootV1
+	var root *types.LogRootV1
+	var cr *compact.Range
+	var done bool
+	err := ls.ReadWriteTransaction(ctx, b.tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		var err error
+		b.staged = nil
+		root, cr, done, err = b.stage(ctx, tx)
+		if err != nil || !done {
+			return err
+		}
+		newLogRoot, err = b.publish(ctx, tx, root, cr)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Only update the state once the transaction has committed, as it may
+	// be retried.
+	b.root, b.cr, b.done = root, cr, done
+	b.chunks++
+	recordMergeDelays(b.tree, b.label, b.staged, b.timeSource.Now())
+	return newLogRoot, nil
+}
+
+// stage integrates the next chunk of up to txLimit leaves into a copy of the
+// compact range of the batch, and writes the leaves and nodes of the chunk
+// within tx. It returns the latest root, the updated compact range, and
+// whether the chunk completes the batch.
+func (b *splitBatch) stage(ctx context.Context, tx storage.LogTreeTX) (*types.LogRootV1, *compact.Range, bool, error) {
+	r, ok := tx.(storage.StagedLeafReader)
+	if !ok {
+		return nil, nil, false, errStagingUnsupported
+	}
+	treeID := b.tree.TreeId
+	stageStart := b.timeSource.Now()
+	root, err := latestRoot(ctx, treeID, tx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	seqGetRootLatency.Observe(clock.SecondsSince(b.timeSource, stageStart), b.label)
+
+	var cr *compact.Range
+	if b.cr == nil {
+		seqTreeSize.Set(float64(root.TreeSize), b.label)
+		if root.RootHash == nil {
+			klog.Warningf("%v: Fresh log - no previous TreeHeads exist.", treeID)
+			return nil, nil, false, storage.ErrTreeNeedsInit
+		}
+		stageStart = b.timeSource.Now()
+		if cr, err = initCompactRange(ctx, treeID, b.rf, root, tx); err != nil {
+			return nil, nil, false, fmt.Errorf("%v: compact range init failed: %v", treeID, err)
+		}
+		seqInitTreeLatency.Observe(clock.SecondsSince(b.timeSource, stageStart), b.label)
+	} else {
+		// The staged leaves and nodes are only valid on top of the root which
+		// the batch started from.
+		if root.TreeSize != b.root.TreeSize || !bytes.Equal(root.RootHash, b.root.RootHash) {
+			return nil, nil, false, fmt.Errorf("%v: root changed to size %d while integrating a batch on top of size %d", treeID, root.TreeSize, b.root.TreeSize)
+		}
+		if cr, err = b.rf.NewRange(b.cr.Begin(), b.cr.End(), append([][]byte(nil), b.cr.Hashes()...)); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	// Never grow the tree beyond its maximum size. The root is the same for
+	// all the chunks of the batch, so this limits the batch as a whole.
+	limit := b.limit
+	if maxSize := b.tree.MaxTreeSize; maxSize > 0 {
+		if remaining := maxSize - int64(root.TreeSize); remaining < int64(limit) {
+			klog.V(1).Infof("%v: Tree size %d is close to its maximum of %d, limiting batch", treeID, root.TreeSize, maxSize)
+			limit = int(max(remaining, 0))
+		}
+	}
+	n := min(b.txLimit, limit-int(cr.End()-root.TreeSize))
+	if n <= 0 {
+		return root, cr, true, nil
+	}
+
+	// Pick up the leaves staged by an earlier, interrupted, batch first. For
+	// PREORDERED_LOG trees, these are all the leaves to be integrated.
+	stageStart = b.timeSource.Now()
+	leaves, err := r.GetStagedLeaves(ctx, int64(cr.End()), int64(n))
+	if status.Code(err) == codes.Unimplemented {
+		return nil, nil, false, fmt.Errorf("%w: %v", errStagingUnsupported, err)
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("%v: Sequencer failed to load staged leaves: %v", treeID, err)
+	}
+	seqDequeueLatency.Observe(clock.SecondsSince(b.timeSource, stageStart), b.label)
+	var queued []*trillian.LogLeaf
+	var st *logSequencingTask
+	if b.tree.TreeType == trillian.TreeType_LOG && len(leaves) < n {
+		st = &logSequencingTask{
+			label:      b.label,
+			treeSize:   cr.End() + uint64(len(leaves)),
+			timeSource: b.timeSource,
+			tx:         tx,
+			fifo:       b.tree.DequeueOrder == trillian.DequeueOrder_FIFO_DEQUEUE_ORDER,
+		}
+		if queued, err = st.fetch(ctx, n-len(leaves), b.cutoff); err != nil {
+			return nil, nil, false, fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", treeID, err)
+		}
+		leaves = append(leaves, queued...)
+	}
+	if len(leaves) == 0 {
+		return root, cr, true, nil
+	}
+
+	stageStart = b.timeSource.Now()
+	if err := prepareLeaves(leaves, cr.End(), b.label, b.timeSource); err != nil {
+		return nil, nil, false, err
+	}
+	nodeMap, _, err := updateCompactRange(cr, leaves, b.label)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	seqWriteTreeLatency.Observe(clock.SecondsSince(b.timeSource, stageStart), b.label)
+
+	if len(queued) > 0 {
+		if err := st.update(ctx, queued); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	stageStart = b.timeSource.Now()
+	if err := tx.SetMerkleNodes(ctx, buildNodesFromNodeMap(nodeMap)); err != nil {
+		return nil, nil, false, fmt.Errorf("%v: Sequencer failed to set Merkle nodes: %v", treeID, err)
+	}
+	seqSetNodesLatency.Observe(clock.SecondsSince(b.timeSource, stageStart), b.label)
+	// The leaves of LOG trees staged by an earlier batch were counted when it
+	// staged them.
+	b.staged = leaves
+	if b.tree.TreeType == trillian.TreeType_LOG {
+		b.staged = queued
+	}
+	return root, cr, len(leaves) < n, nil
+}
+
+// publish stores the root covering cr, on top of root, if any leaves have been
+// integrated or root is too old. It returns the new root, or nil if none was
+// needed.
+func (b *splitBatch) publish(ctx context.Context, tx storage.LogTreeTX, root *types.LogRootV1, cr *compact.Range) (*types.LogRootV1, error) {
+	if cr.End() == root.TreeSize {
+		interval := time.Duration(b.timeSource.Now().UnixNano() - int64(root.TimestampNanos))
+		if b.maxRootDuration == 0 || interval < b.maxRootDuration {
+			klog.V(1).Infof("%v: No leaves sequenced in this signing operation", b.tree.TreeId)
+			return nil, nil
+		}
+		klog.Infof("%v: Force new root generation as %v since last root", b.tree.TreeId, interval)
+	}
+	rootHash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return nil, err
+	}
+	return storeRoot(ctx, b.tree.TreeId, b.label, tx, b.hasher, cr, rootHash, root, b.start, int(cr.End()-root.TreeSize), b.timeSource)
+}
+
+// hasStagedLeaves reports whether tx holds leaves staged beyond treeSize, the
+// size of the latest root, by an interrupted split batch.
+func hasStagedLeaves(ctx context.Context, tx storage.LogTreeTX, treeSize uint64) (bool, error) {
+	r, ok := tx.(storage.StagedLeafReader)
+	if !ok {
+		return false, nil
+	}
+	leaves, err := r.GetStagedLeaves(ctx, int64(treeSize), 1)
+	if status.Code(err) == codes.Unimplemented {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return len(leaves) > 0, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// failingLogStorage fails all read-write transactions after the first ok.
+type failingLogStorage struct {
+	storage.LogStorage
+	ok int
+}
+
+func (s *failingLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	if s.ok <= 0 {
+		return errors.New("transaction failed")
+	}
+	s.ok--
+	return s.LogStorage.ReadWriteTransaction(ctx, tree, f)
+}
+
+// unstagedLogStorage hides the optional interfaces of the transactions of
+// the underlying storage.
+type unstagedLogStorage struct {
+	storage.LogStorage
+	txs int
+}
+
+func (s *unstagedLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	s.txs++
+	return s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, struct{ storage.LogTreeTX }{tx})
+	})
+}
+
+func TestIntegrateSplitBatch(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 50
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+
+	for _, want := range []int{40, 10, 0} {
+		n, err := IntegrateSplitBatch(ctx, tree, 40, 7, 0, 0, clock.System, ls, quota.Noop())
+		if err != nil {
+			t.Fatalf("IntegrateSplitBatch(): %v", err)
+		}
+		if n != want {
+			t.Fatalf("IntegrateSplitBatch() = %d, want %d", n, want)
+		}
+	}
+	checkSplitLog(ctx, t, tree, ls, leafCount)
+}
+
+func TestIntegrateSplitBatchMaxTreeSize(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 30
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+	tree.MaxTreeSize = 17
+
+	n, err := IntegrateSplitBatch(ctx, tree, leafCount, 4, 0, 0, clock.System, ls, quota.Noop())
+	if err != nil {
+		t.Fatalf("IntegrateSplitBatch(): %v", err)
+	}
+	if n != 17 {
+		t.Fatalf("IntegrateSplitBatch() = %d, want %d", n, 17)
+	}
+	checkSplitLog(ctx, t, tree, ls, 17)
+}
+
+func TestIntegrateSplitBatchResumes(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 30
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+
+	// Stage two chunks, and fail before the root is stored.
+	failing := &failingLogStorage{LogStorage: ls, ok: 2}
+	if _, err := IntegrateSplitBatch(ctx, tree, 20, 4, 0, 0, clock.System, failing, quota.Noop()); err == nil {
+		t.Fatal("IntegrateSplitBatch() succeeded, want error")
+	}
+	checkSplitLog(ctx, t, tree, ls, 0)
+
+	// The next batch picks up the staged leaves, and then dequeues the rest.
+	n, err := IntegrateSplitBatch(ctx, tree, leafCount, 4, 0, 0, clock.System, ls, quota.Noop())
+	if err != nil {
+		t.Fatalf("IntegrateSplitBatch(): %v", err)
+	}
+	if n != leafCount {
+		t.Fatalf("IntegrateSplitBatch() = %d, want %d", n, leafCount)
+	}
+	checkSplitLog(ctx, t, tree, ls, leafCount)
+}
+
+func TestIntegrateBatchAfterInterruptedSplitBatch(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 30
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+
+	// Stage two chunks, and fail before the root is stored.
+	failing := &failingLogStorage{LogStorage: ls, ok: 2}
+	if _, err := IntegrateSplitBatch(ctx, tree, 20, 4, 0, 0, clock.System, failing, quota.Noop()); err == nil {
+		t.Fatal("IntegrateSplitBatch() succeeded, want error")
+	}
+
+	// The staged leaves are no longer queued, but the tree isn't drained.
+	tree.TreeState = trillian.TreeState_DRAINING
+	registry := extension.Registry{
+		AdminStorage: &stestonly.FakeAdminStorage{TXErr: []error{errors.New("tree frozen")}},
+		LogStorage:   ls,
+	}
+	if err := NewSequencerManager(registry, 0).freezeIfDrained(ctx, tree, clock.System.Now()); err != nil {
+		t.Errorf("freezeIfDrained() with staged leaves: %v", err)
+	}
+	tree.TreeState = trillian.TreeState_ACTIVE
+
+	// An unsplit batch picks up the staged leaves, rather than sequencing
+	// the rest of the queue at their indices.
+	n, err := IntegrateBatch(ctx, tree, leafCount, 0, 0, clock.System, ls, quota.Noop())
+	if err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	if n != leafCount {
+		t.Fatalf("IntegrateBatch() = %d, want %d", n, leafCount)
+	}
+	checkSplitLog(ctx, t, tree, ls, leafCount)
+}
+
+func TestIntegrateSplitBatchUnsupported(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 20
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+
+	unstaged := &unstagedLogStorage{LogStorage: ls}
+	n, err := IntegrateSplitBatch(ctx, tree, leafCount, 3, 0, 0, clock.System, unstaged, quota.Noop())
+	if err != nil {
+		t.Fatalf("IntegrateSplitBatch(): %v", err)
+	}
+	if n != leafCount {
+		t.Fatalf("IntegrateSplitBatch() = %d, want %d", n, leafCount)
+	}
+	// One transaction to find out that staging is unsupported, and one to
+	// integrate the whole batch.
+	if got, want := unstaged.txs, 2; got != want {
+		t.Errorf("IntegrateSplitBatch() ran %d transactions, want %d", got, want)
+	}
+	checkSplitLog(ctx, t, tree, ls, leafCount)
+}
+
+// checkSplitLog checks that the latest root of the log has the given size,
+// and that it matches both the stored Merkle nodes, and the sequenced leaves.
+func checkSplitLog(ctx context.Context, t *testing.T, tree *trillian.Tree, ls storage.LogStorage, size uint64) {
+	t.Helper()
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if root.TreeSize != size {
+		t.Fatalf("root size = %d, want %d", root.TreeSize, size)
+	}
+	if size == 0 {
+		return
+	}
+	if _, err := initCompactRangeFromStorage(ctx, rangeFactory, &root, tx); err != nil {
+		t.Errorf("initCompactRangeFromStorage(): %v", err)
+	}
+
+	sequenced, err := tx.GetLeavesByRange(ctx, 0, int64(size))
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	cr := rangeFactory.NewEmptyRange(0)
+	for _, leaf := range sequenced {
+		if err := cr.Append(leaf.MerkleLeafHash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+	}
+	want, err := cr.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash(): %v", err)
+	}
+	if cr.End() != size || !bytes.Equal(root.RootHash, want) {
+		t.Errorf("root = {size %d, hash %x}, want {size %d, hash %x}", root.TreeSize, root.RootHash, cr.End(), want)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/transparency-dev/merkle/compact"
+)
+
+// StandbyWarmer is implemented by Operations which can prepare to take over
+// the logs that another instance is master for, so that the first pass over
+// a log after a mastership failover doesn't start cold. The OperationManager
+// calls it for such logs every OperationInfo.StandbyWarmInterval.
+type StandbyWarmer interface {
+	// WarmStandby prepares for passes over logID, without modifying it.
+	WarmStandby(ctx context.Context, logID int64, info *OperationInfo) error
+}
+
+// WarmStandby implements StandbyWarmer. It reads the tree and the compact
+// range of its latest root, which is kept for the first pass over the log
+// once this instance becomes its master, provided that the root hasn't
+// changed by then.
+func (s *SequencerManager) WarmStandby(ctx context.Context, logID int64, info *OperationInfo) error {
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, logID, seqOpts)
+	if err != nil {
+		return fmt.Errorf("error retrieving log %v: %v", logID, err)
+	}
+	return s.ranges.load(trees.NewContext(ctx, tree), tree, s.registry.LogStorage)
+}
+
+// rangeCache holds the compact ranges of logs as of their latest roots, as
+// read by standby passes.
+type rangeCache struct {
+	mu     sync.Mutex
+	ranges map[int64]cachedRange
+}
+
+// cachedRange is the compact range of a log as of root.
+type cachedRange struct {
+	root   *types.LogRootV1
+	hashes [][]byte
+}
+
+func newRangeCache() *rangeCache {
+	return &rangeCache{ranges: make(map[int64]cachedRange)}
+}
+
+// load reads the latest root of tree from ls, and caches its compact range
+// unless the range of that root is already cached.
+func (c *rangeCache) load(ctx context.Context, tree *trillian.Tree, ls storage.LogStorage) error {
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return fmt.Errorf("%v: %v", tree.TreeId, err)
+	}
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	root, err := latestRoot(ctx, tree.TreeId, tx)
+	if err != nil {
+		return err
+	}
+	if root.RootHash == nil {
+		// The log needs to be initialized before it can be sequenced.
+		return nil
+	}
+
+	c.mu.Lock()
+	cached, ok := c.ranges[tree.TreeId]
+	c.mu.Unlock()
+	if ok && sameRoot(cached.root, root) {
+		return tx.Commit(ctx)
+	}
+	cr, err := initCompactRangeFromStorage(ctx, hashers.RangeFactory(hasher), root, tx)
+	if err != nil {
+		return fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges[tree.TreeId] = cachedRange{root: root, hashes: cr.Hashes()}
+	return nil
+}
+
+// take removes the cached compact range of treeID, and returns its hashes if
+// it is the range of root.
+func (c *rangeCache) take(treeID int64, root *types.LogRootV1) ([][]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.ranges[treeID]
+	if !ok {
+		return nil, false
+	}
+	delete(c.ranges, treeID)
+	if !sameRoot(cached.root, root) {
+		return nil, false
+	}
+	return cached.hashes, true
+}
+
+// sameRoot returns whether a and b have the same size and root hash.
+func sameRoot(a, b *types.LogRootV1) bool {
+	return a.TreeSize == b.TreeSize && bytes.Equal(a.RootHash, b.RootHash)
+}
+
+type rangeCacheKey struct{}
+
+// withRangeCache returns a copy of ctx which carries c, for initCompactRange.
+func withRangeCache(ctx context.Context, c *rangeCache) context.Context {
+	return context.WithValue(ctx, rangeCacheKey{}, c)
+}
+
+// initCompactRange is like initCompactRangeFromStorage, except that the range
+// is taken from the cache carried by ctx, if it holds the range of root.
+func initCompactRange(ctx context.Context, treeID int64, rf *compact.RangeFactory, root *types.LogRootV1, tx storage.ReadOnlyLogTreeTX) (*compact.Range, error) {
+	if c, ok := ctx.Value(rangeCacheKey{}).(*rangeCache); ok {
+		if hashes, ok := c.take(treeID, root); ok {
+			if cr, err := rf.NewRange(0, root.TreeSize, hashes); err == nil {
+				seqStandbyRangeHits.Inc(strconv.FormatInt(treeID, 10))
+				return cr, nil
+			}
+		}
+	}
+	return initCompactRangeFromStorage(ctx, rf, root, tx)
+}
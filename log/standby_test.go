@@ -0,0 +1,160 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+)
+
+// checkRoot checks that the latest root of tree covers its first size leaves.
+func checkRoot(ctx context.Context, t *testing.T, tree *trillian.Tree, ls storage.LogStorage, size uint64) {
+	t.Helper()
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	leaves, err := tx.GetLeavesByRange(ctx, 0, int64(size))
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	cr := rangeFactory.NewEmptyRange(0)
+	for _, leaf := range leaves {
+		if err := cr.Append(leaf.MerkleLeafHash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+	}
+	want, err := cr.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash(): %v", err)
+	}
+	if root.TreeSize != size || !bytes.Equal(root.RootHash, want) {
+		t.Errorf("root = {size %d, hash %x}, want {size %d, hash %x}", root.TreeSize, root.RootHash, size, want)
+	}
+}
+
+func TestRangeCache(t *testing.T) {
+	ctx := context.Background()
+	InitMetrics(nil)
+
+	const leafCount = 30
+	tree, ls := newMemoryLog(ctx, t, leafCount)
+	integrate := func(ctx context.Context, want int) {
+		t.Helper()
+		if n, err := IntegrateBatch(ctx, tree, want, 0, 0, clock.System, ls, quota.Noop()); err != nil || n != want {
+			t.Fatalf("IntegrateBatch() = %d, %v, want %d", n, err, want)
+		}
+	}
+	integrate(ctx, 10)
+
+	// The cached range of the latest root is used, once.
+	c := newRangeCache()
+	if err := c.load(ctx, tree, ls); err != nil {
+		t.Fatalf("load(): %v", err)
+	}
+	if got := c.ranges[tree.TreeId].root.TreeSize; got != 10 {
+		t.Errorf("cached range of size %d, want 10", got)
+	}
+	integrate(withRangeCache(ctx, c), 10)
+	if len(c.ranges) != 0 {
+		t.Errorf("cached ranges %v not taken", c.ranges)
+	}
+	checkRoot(ctx, t, tree, ls, 20)
+
+	// A range cached before the root moved on is dropped.
+	if err := c.load(ctx, tree, ls); err != nil {
+		t.Fatalf("load(): %v", err)
+	}
+	integrate(ctx, 5)
+	integrate(withRangeCache(ctx, c), 5)
+	if len(c.ranges) != 0 {
+		t.Errorf("stale ranges %v not dropped", c.ranges)
+	}
+	checkRoot(ctx, t, tree, ls, leafCount)
+}
+
+// warmOp is an Operation which records the logs it warms.
+type warmOp struct {
+	Operation
+	mu     sync.Mutex
+	warmed []int64
+}
+
+func (w *warmOp) WarmStandby(_ context.Context, logID int64, _ *OperationInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warmed = append(w.warmed, logID)
+	return nil
+}
+
+func TestStandbyFor(t *testing.T) {
+	ctx := context.Background()
+	ts := clock.NewFake(time.Unix(1000, 0))
+	info := defaultOperationInfo(extension.Registry{})
+	info.TimeSource = ts
+	info.NumWorkers = 2
+	info.StandbyWarmInterval = time.Minute
+	op := &warmOp{}
+	lom := NewOperationManager(info, op)
+
+	active, held := []int64{1, 2, 3, 4}, []int64{2}
+	lom.warmStandbys(ctx, lom.standbyFor(active, held))
+	sort.Slice(op.warmed, func(i, j int) bool { return op.warmed[i] < op.warmed[j] })
+	if want := []int64{1, 3, 4}; !reflect.DeepEqual(op.warmed, want) {
+		t.Errorf("warmed %v, want %v", op.warmed, want)
+	}
+
+	// Logs are warmed once per interval.
+	ts.Set(ts.Now().Add(time.Second))
+	if got := lom.standbyFor(active, held); len(got) != 0 {
+		t.Errorf("standbyFor() within interval = %v, want none", got)
+	}
+	ts.Set(ts.Now().Add(time.Minute))
+	if got, want := lom.standbyFor(active, []int64{1, 2}), []int64{3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("standbyFor() after interval = %v, want %v", got, want)
+	}
+
+	// Operations which aren't StandbyWarmers, or have no interval, aren't
+	// asked to warm any logs.
+	info.StandbyWarmInterval = 0
+	if got := NewOperationManager(info, op).standbyFor(active, held); len(got) != 0 {
+		t.Errorf("standbyFor() without interval = %v, want none", got)
+	}
+	info.StandbyWarmInterval = time.Minute
+	if got := NewOperationManager(info, backlogOp{}).standbyFor(active, held); len(got) != 0 {
+		t.Errorf("standbyFor() without StandbyWarmer = %v, want none", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/google/trillian/log/signerpb"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+)
+
+// Status returns the status of each active log, as of the latest pass of the
+// manager, ordered by log ID. If masterOnly is set, only the logs which this
+// instance is master for are included.
+func (o *OperationManager) Status(ctx context.Context, masterOnly bool) []*signerpb.TreeStatus {
+	o.idsMutex.Lock()
+	active := append([]int64(nil), o.lastActive...)
+	held := make(map[int64]bool)
+	for _, id := range o.lastHeld {
+		held[id] = true
+	}
+	o.idsMutex.Unlock()
+	sort.Slice(active, func(i, j int) bool { return active[i] < active[j] })
+
+	var ret []*signerpb.TreeStatus
+	for _, logID := range active {
+		if masterOnly && !held[logID] {
+			continue
+		}
+		ts := &signerpb.TreeStatus{
+			TreeId:        logID,
+			DisplayName:   o.logName(ctx, logID),
+			Master:        held[logID],
+			Backlog:       o.backlog(ctx, logID),
+			MergeDelaySlo: mergeDelaySLOStatus(logID, o.info.TimeSource.Now()),
+		}
+		o.runsMu.Lock()
+		if r, ok := o.runs[logID]; ok {
+			ts.LastRunTime = timestamppb.New(r.lastRun)
+			if r.lastErr != nil {
+				ts.LastRunError = r.lastErr.Error()
+			}
+			if r.lastBatchSize > 0 {
+				ts.LastBatchTime = timestamppb.New(r.lastBatch)
+				ts.LastBatchSize = int64(r.lastBatchSize)
+			}
+		}
+		o.runsMu.Unlock()
+		ret = append(ret, ts)
+	}
+	return ret
+}
+
+// backlog returns the number of leaves queued for logID, or -1 if it can't be
+// counted.
+func (o *OperationManager) backlog(ctx context.Context, logID int64) int64 {
+	tree, err := storage.GetTree(ctx, o.info.Registry.AdminStorage, logID)
+	if err != nil {
+		klog.Warningf("%v: failed to get tree: %v", logID, err)
+		return -1
+	}
+	count, err := o.info.Registry.LogStorage.CountUnsequenced(ctx, tree)
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			klog.Warningf("%v: failed to count unsequenced leaves: %v", logID, err)
+		}
+		return -1
+	}
+	return count
+}
+
+// StatusServer serves the status of the logs handled by an OperationManager,
+// over the signerpb.Signer API and HTTP.
+type StatusServer struct {
+	o *OperationManager
+}
+
+// NewStatusServer returns a StatusServer for o.
+func NewStatusServer(o *OperationManager) *StatusServer {
+	return &StatusServer{o: o}
+}
+
+// GetSignerStatus implements signerpb.SignerServer.
+func (s *StatusServer) GetSignerStatus(ctx context.Context, req *signerpb.GetSignerStatusRequest) (*signerpb.GetSignerStatusResponse, error) {
+	return &signerpb.GetSignerStatusResponse{Trees: s.o.Status(ctx, req.GetMasterOnly())}, nil
+}
+
+// ServeHTTP writes the status as a JSON GetSignerStatusResponse. The
+// master_only query parameter may be set to true.
+func (s *StatusServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var masterOnly bool
+	if v := req.URL.Query().Get("master_only"); v != "" {
+		var err error
+		if masterOnly, err = strconv.ParseBool(v); err != nil {
+			http.Error(rw, "invalid master_only", http.StatusBadRequest)
+			return
+		}
+	}
+	rsp, err := s.GetSignerStatus(req.Context(), &signerpb.GetSignerStatusRequest{MasterOnly: masterOnly})
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(rsp)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if _, err := rw.Write(data); err != nil {
+		klog.Errorf("Write(): %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log/signerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	const logID1, logID2 = int64(451), int64(145)
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{logID1: "LogID1", logID2: "LogID2"})
+	fakeStorage.EXPECT().CountUnsequenced(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, tree *trillian.Tree) (int64, error) {
+			if tree.TreeId == logID1 {
+				return 7, nil
+			}
+			return 0, status.Error(codes.Unimplemented, "no counting")
+		})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+
+	mockLogOp := NewMockOperation(ctrl)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), logID1, gomock.Any()).Return(5, nil)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), logID2, gomock.Any()).Return(0, errors.New("test error"))
+
+	lom := NewOperationManager(defaultOperationInfo(registry), mockLogOp)
+	if got := lom.Status(ctx, false); len(got) != 0 {
+		t.Errorf("Status() before first pass = %v, want none", got)
+	}
+	lom.OperationSingle(ctx)
+
+	now := timestamppb.New(fakeTimeSource.Now())
+	want := []*signerpb.TreeStatus{
+		{TreeId: logID2, DisplayName: "LogID2", Master: true, LastRunTime: now, LastRunError: "test error", Backlog: -1},
+		{TreeId: logID1, DisplayName: "LogID1", Master: true, LastRunTime: now, LastBatchTime: now, LastBatchSize: 5, Backlog: 7},
+	}
+	if diff := cmp.Diff(want, lom.Status(ctx, false), protocmp.Transform()); diff != "" {
+		t.Errorf("Status() diff (-want +got):\n%s", diff)
+	}
+
+	s := NewStatusServer(lom)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/signer/status?master_only=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d: %s", rec.Code, rec.Body)
+	}
+	var rsp signerpb.GetSignerStatusResponse
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("Unmarshal(): %v", err)
+	}
+	if diff := cmp.Diff(want, rsp.Trees, protocmp.Transform()); diff != "" {
+		t.Errorf("ServeHTTP() diff (-want +got):\n%s", diff)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/signer/status?master_only=maybe", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "master_only") {
+		t.Errorf("ServeHTTP(invalid) = %d %q, want 400", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"k8s.io/klog/v2"
+)
+
+var (
+	watchdogOnce sync.Once
+	stalledLogs  monitoring.Gauge
+)
+
+func createWatchdogMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	stalledLogs = mf.NewGauge("sequencer_stalled", "Set to 1 for logs whose sequencing appears to have stalled", logIDLabel)
+}
+
+// StallEvent describes a log which has leaves waiting to be integrated, but
+// whose latest root is older than its stall threshold.
+type StallEvent struct {
+	TreeID int64 `json:"tree_id"`
+	// TreeSize is the size of the log's latest root.
+	TreeSize uint64 `json:"tree_size"`
+	// RootTime is the timestamp of the log's latest root.
+	RootTime time.Time `json:"root_time"`
+	// Threshold is the root age beyond which the log is considered stalled.
+	Threshold time.Duration `json:"threshold"`
+	// Detected is when the stall was detected.
+	Detected time.Time `json:"detected"`
+	// Owner and Contact identify who is responsible for the log, if set on
+	// its tree.
+	Owner   string `json:"owner,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+// StallHandler is notified about each stalled log found by a StallWatchdog.
+type StallHandler func(ctx context.Context, ev StallEvent)
+
+// WatchdogConfig configures a StallWatchdog.
+type WatchdogConfig struct {
+	// Interval is the time between checks of all active logs.
+	Interval time.Duration
+	// DefaultThreshold is the stall threshold for logs which don't have a
+	// max_root_duration. If zero, such logs are not checked.
+	DefaultThreshold time.Duration
+	// Grace is added to each log's max_root_duration, to allow for the time
+	// taken by the signer to notice that a new root is due.
+	Grace time.Duration
+	// GuardWindow should match the sequencer's guard window. A log is only
+	// reported once its queue has been seen non-empty, with no new root, for
+	// at least this long, as more recently queued leaves may not be eligible
+	// for sequencing yet.
+	GuardWindow time.Duration
+	// Handlers are notified about stalled logs, in addition to the log
+	// message and the sequencer_stalled metric.
+	Handlers []StallHandler
+}
+
+// StallWatchdog periodically checks that logs with queued leaves are
+// producing new roots, so that a wedged signer is noticed quickly.
+//
+// The watchdog checks every active log regardless of which signer is master
+// for it, so it also detects logs that no signer is sequencing. If it runs in
+// several signers then each of them will report the same stalls.
+type StallWatchdog struct {
+	registry   extension.Registry
+	cfg        WatchdogConfig
+	timeSource clock.TimeSource
+
+	mu sync.Mutex
+	// queued holds when each log was first seen with a stale root and a
+	// non-empty queue.
+	queued map[int64]time.Time
+}
+
+// NewStallWatchdog creates a StallWatchdog for the logs in the given registry.
+func NewStallWatchdog(registry extension.Registry, cfg WatchdogConfig, timeSource clock.TimeSource) *StallWatchdog {
+	watchdogOnce.Do(func() { createWatchdogMetrics(registry.MetricFactory) })
+	return &StallWatchdog{registry: registry, cfg: cfg, timeSource: timeSource, queued: make(map[int64]time.Time)}
+}
+
+// Run checks all active logs every configured interval until ctx is done.
+func (w *StallWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := w.Check(ctx); err != nil {
+			klog.Warningf("Stall watchdog: %v", err)
+		}
+	}
+}
+
+// Check inspects all active logs once, notifies the handlers about those which
+// have stalled, and returns them.
+func (w *StallWatchdog) Check(ctx context.Context) ([]StallEvent, error) {
+	logIDs, err := w.registry.GetActiveLogIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active log IDs: %v", err)
+	}
+	w.forgetInactive(logIDs)
+	var stalls []StallEvent
+	for _, logID := range logIDs {
+		ev, err := w.checkLog(ctx, logID)
+		if err != nil {
+			klog.Warningf("%v: stall check failed: %v", logID, err)
+			continue
+		}
+		label := strconv.FormatInt(logID, 10)
+		if ev == nil {
+			stalledLogs.Set(0, label)
+			continue
+		}
+		stalledLogs.Set(1, label)
+		klog.Errorf("%v: sequencing stalled: %d leaves integrated as of %v, which is more than %v ago (owner: %q, contact: %q)", logID, ev.TreeSize, ev.RootTime, ev.Threshold, ev.Owner, ev.Contact)
+		for _, h := range w.cfg.Handlers {
+			h(ctx, *ev)
+		}
+		stalls = append(stalls, *ev)
+	}
+	return stalls, nil
+}
+
+// checkLog returns a StallEvent if the given log has stalled, or nil if not.
+func (w *StallWatchdog) checkLog(ctx context.Context, logID int64) (*StallEvent, error) {
+	tree, err := trees.GetTree(ctx, w.registry.AdminStorage, logID, seqOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving log: %v", err)
+	}
+	threshold := w.cfg.DefaultThreshold
+	if d := tree.MaxRootDuration.AsDuration(); tree.MaxRootDuration.IsValid() && d > 0 {
+		threshold = d + w.cfg.Grace
+	}
+	if threshold <= 0 {
+		return nil, nil
+	}
+
+	// The check only reads, so it uses a snapshot and counts the queue
+	// rather than taking locks on the queued leaves.
+	now := w.timeSource.Now()
+	tx, err := w.registry.LogStorage.SnapshotForTree(trees.NewContext(ctx, tree), tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal latest root: %v", err)
+	}
+	rootTime := time.Unix(0, int64(root.TimestampNanos))
+	if now.Sub(rootTime) <= threshold {
+		w.setQueued(logID, false, now)
+		return nil, nil
+	}
+	count, err := w.registry.LogStorage.CountUnsequenced(ctx, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect queue: %v", err)
+	}
+	if since := w.setQueued(logID, count > 0, now); count == 0 || now.Sub(since) < w.cfg.GuardWindow {
+		return nil, nil
+	}
+	return &StallEvent{
+		TreeID:    logID,
+		TreeSize:  root.TreeSize,
+		RootTime:  rootTime,
+		Threshold: threshold,
+		Detected:  now,
+		Owner:     tree.Owner,
+		Contact:   tree.Contact,
+	}, nil
+}
+
+// forgetInactive drops the queue state of logs which are no longer active.
+func (w *StallWatchdog) forgetInactive(logIDs []int64) {
+	active := make(map[int64]bool, len(logIDs))
+	for _, id := range logIDs {
+		active[id] = true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id := range w.queued {
+		if !active[id] {
+			delete(w.queued, id)
+		}
+	}
+}
+
+// setQueued records whether logID has a stale root and a non-empty queue as
+// of now, and returns when it was first seen in that state.
+func (w *StallWatchdog) setQueued(logID int64, queued bool, now time.Time) time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !queued {
+		delete(w.queued, logID)
+		return now
+	}
+	since, ok := w.queued[logID]
+	if !ok {
+		since = now
+		w.queued[logID] = since
+	}
+	return since
+}
+
+// NewWebhookStallHandler returns a StallHandler which POSTs each StallEvent as
+// JSON to the given URL. If client is nil, http.DefaultClient is used.
+func NewWebhookStallHandler(url string, client *http.Client) StallHandler {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, ev StallEvent) {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			klog.Errorf("%v: failed to marshal stall event: %v", ev.TreeID, err)
+			return
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			klog.Errorf("%v: failed to create stall webhook request: %v", ev.TreeID, err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			klog.Warningf("%v: stall webhook failed: %v", ev.TreeID, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			klog.Warningf("%v: stall webhook returned %s", ev.TreeID, resp.Status)
+		}
+	}
+}
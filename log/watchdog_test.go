@@ -0,0 +1,175 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestStallWatchdog(t *testing.T) {
+	for _, test := range []struct {
+		desc            string
+		maxRootDuration time.Duration
+		rootAge         time.Duration
+		queued          int64
+		wantStall       bool
+	}{
+		{desc: "fresh-root", maxRootDuration: time.Minute, rootAge: time.Minute},
+		{desc: "stalled", maxRootDuration: time.Minute, rootAge: 2 * time.Minute, queued: 1, wantStall: true},
+		{desc: "old-root-empty-queue", maxRootDuration: time.Minute, rootAge: 2 * time.Minute},
+		{desc: "no-threshold", rootAge: time.Hour},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+			tree.MaxRootDuration = durationpb.New(test.maxRootDuration)
+			tree.Owner = "llama-team"
+			logID := tree.TreeId
+
+			mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+			mockAdminTx.EXPECT().Commit().Return(nil)
+			mockAdminTx.EXPECT().Close().Return(nil)
+			mockAdmin := &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTx}}
+
+			mockStorage := storage.NewMockLogStorage(mockCtrl)
+			mockStorage.EXPECT().GetActiveLogIDs(gomock.Any()).Return([]int64{logID}, nil)
+			if test.maxRootDuration > 0 {
+				root := *testRoot0
+				root.TimestampNanos = uint64(fakeTime.Add(-test.rootAge).UnixNano())
+				rootBytes, _ := root.MarshalBinary()
+				mockTx := storage.NewMockLogTreeTX(mockCtrl)
+				mockTx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(&trillian.SignedLogRoot{LogRoot: rootBytes}, nil)
+				mockTx.EXPECT().Commit(gomock.Any()).Return(nil)
+				mockTx.EXPECT().Close().Return(nil)
+				mockStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+				if test.rootAge > test.maxRootDuration {
+					mockStorage.EXPECT().CountUnsequenced(gomock.Any(), gomock.Any()).Return(test.queued, nil)
+				}
+			}
+
+			var handled []StallEvent
+			cfg := WatchdogConfig{
+				Handlers: []StallHandler{func(_ context.Context, ev StallEvent) { handled = append(handled, ev) }},
+			}
+			registry := extension.Registry{AdminStorage: mockAdmin, LogStorage: mockStorage}
+			stalls, err := NewStallWatchdog(registry, cfg, fakeTimeSource).Check(ctx)
+			if err != nil {
+				t.Fatalf("Check(): %v", err)
+			}
+			if got := len(stalls) > 0; got != test.wantStall {
+				t.Fatalf("Check() = %v, want stall: %v", stalls, test.wantStall)
+			}
+			if len(handled) != len(stalls) {
+				t.Errorf("handler called %d times, want %d", len(handled), len(stalls))
+			}
+			if test.wantStall {
+				if got, want := stalls[0].Threshold, test.maxRootDuration; got != want {
+					t.Errorf("Threshold = %v, want %v", got, want)
+				}
+				if got, want := stalls[0].Owner, tree.Owner; got != want {
+					t.Errorf("Owner = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStallWatchdogGuardWindow(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.MaxRootDuration = durationpb.New(time.Minute)
+	logID := tree.TreeId
+	root := *testRoot0
+	root.TimestampNanos = uint64(fakeTime.Add(-time.Hour).UnixNano())
+	rootBytes, _ := root.MarshalBinary()
+
+	var adminTXs []storage.ReadOnlyAdminTX
+	mockStorage := storage.NewMockLogStorage(mockCtrl)
+	for i := 0; i < 3; i++ {
+		mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+		mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(tree, nil)
+		mockAdminTx.EXPECT().Commit().Return(nil)
+		mockAdminTx.EXPECT().Close().Return(nil)
+		adminTXs = append(adminTXs, mockAdminTx)
+
+		mockTx := storage.NewMockLogTreeTX(mockCtrl)
+		mockTx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(&trillian.SignedLogRoot{LogRoot: rootBytes}, nil)
+		mockTx.EXPECT().Commit(gomock.Any()).Return(nil)
+		mockTx.EXPECT().Close().Return(nil)
+		mockStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+	}
+	mockStorage.EXPECT().GetActiveLogIDs(gomock.Any()).Return([]int64{logID}, nil).Times(3)
+	mockStorage.EXPECT().CountUnsequenced(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(3)
+
+	ts := clock.NewFake(fakeTime)
+	registry := extension.Registry{AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: adminTXs}, LogStorage: mockStorage}
+	w := NewStallWatchdog(registry, WatchdogConfig{GuardWindow: 10 * time.Second}, ts)
+	for _, step := range []struct {
+		advance   time.Duration
+		wantStall bool
+	}{
+		{advance: 0, wantStall: false},
+		{advance: 5 * time.Second, wantStall: false},
+		{advance: 5 * time.Second, wantStall: true},
+	} {
+		ts.Set(ts.Now().Add(step.advance))
+		stalls, err := w.Check(ctx)
+		if err != nil {
+			t.Fatalf("Check(): %v", err)
+		}
+		if got := len(stalls) > 0; got != step.wantStall {
+			t.Errorf("Check() at %v = %v, want stall: %v", ts.Now(), stalls, step.wantStall)
+		}
+	}
+}
+
+func TestWebhookStallHandler(t *testing.T) {
+	got := make(chan StallEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev StallEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("failed to decode webhook body: %v", err)
+		}
+		got <- ev
+	}))
+	defer srv.Close()
+
+	want := StallEvent{TreeID: 12345, TreeSize: 10, RootTime: fakeTime.UTC(), Threshold: time.Minute, Detected: fakeTime.UTC(), Owner: "llama-team", Contact: "llamas@example.com"}
+	NewWebhookStallHandler(srv.URL, srv.Client())(context.Background(), want)
+	if ev := <-got; ev != want {
+		t.Errorf("webhook received %+v, want %+v", ev, want)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hashers holds the registry of the Merkle tree hashers which logs
+// can use, keyed by the hasher_id of their trillian.Tree.
+package hashers
+
+import (
+	"crypto"
+	_ "crypto/sha512" // Register SHA-512/256.
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/transparency-dev/merkle"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+const (
+	// RFC6962SHA256 is the ID of the RFC 6962 hasher with SHA-256, which is
+	// also used by trees without a hasher_id.
+	RFC6962SHA256 = "RFC6962_SHA256"
+	// RFC6962SHA512_256 is the ID of the RFC 6962 hasher with SHA-512/256.
+	RFC6962SHA512_256 = "RFC6962_SHA512_256"
+)
+
+var (
+	hMu   sync.RWMutex
+	hByID = map[string]merkle.LogHasher{
+		RFC6962SHA256:     rfc6962.DefaultHasher,
+		RFC6962SHA512_256: rfc6962.New(crypto.SHA512_256),
+	}
+
+	// factories holds the compact.RangeFactory of each hasher.
+	factories sync.Map
+)
+
+// Register registers hasher under id, so that trees whose hasher_id is id use
+// it. It is meant to be called from the init function of the package which
+// provides the hasher.
+func Register(id string, hasher merkle.LogHasher) error {
+	hMu.Lock()
+	defer hMu.Unlock()
+
+	if id == "" {
+		return fmt.Errorf("hasher ID must not be empty")
+	}
+	if _, exists := hByID[id]; exists {
+		return fmt.Errorf("hasher %v already registered", id)
+	}
+	hByID[id] = hasher
+	return nil
+}
+
+// Get returns the hasher registered under id, or the RFC 6962 hasher with
+// SHA-256 if id is empty.
+func Get(id string) (merkle.LogHasher, error) {
+	if id == "" {
+		id = RFC6962SHA256
+	}
+	hMu.RLock()
+	defer hMu.RUnlock()
+
+	hasher := hByID[id]
+	if hasher == nil {
+		return nil, fmt.Errorf("no such hasher %v", id)
+	}
+	return hasher, nil
+}
+
+// ForTree returns the hasher of tree.
+func ForTree(tree *trillian.Tree) (merkle.LogHasher, error) {
+	return Get(tree.GetHasherId())
+}
+
+// IDs returns the sorted IDs of all registered hashers.
+func IDs() []string {
+	hMu.RLock()
+	defer hMu.RUnlock()
+
+	r := make([]string, 0, len(hByID))
+	for k := range hByID {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// RangeFactory returns the compact.RangeFactory which hashes the nodes of
+// compact ranges with hasher. Compact ranges can only be merged if they are
+// created by the same factory, so the same one is returned for each hasher.
+func RangeFactory(hasher merkle.LogHasher) *compact.RangeFactory {
+	if f, ok := factories.Load(hasher); ok {
+		return f.(*compact.RangeFactory)
+	}
+	f, _ := factories.LoadOrStore(hasher, &compact.RangeFactory{Hash: hasher.HashChildren})
+	return f.(*compact.RangeFactory)
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha512"
+	"slices"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+func TestGet(t *testing.T) {
+	for _, test := range []struct {
+		id      string
+		want    crypto.Hash
+		wantErr bool
+	}{
+		{id: "", want: crypto.SHA256},
+		{id: RFC6962SHA256, want: crypto.SHA256},
+		{id: RFC6962SHA512_256, want: crypto.SHA512_256},
+		{id: "llama", wantErr: true},
+	} {
+		t.Run(test.id, func(t *testing.T) {
+			h, err := ForTree(&trillian.Tree{HasherId: test.id})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("ForTree(%q) = %v, wantErr %v", test.id, err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := h.EmptyRoot(), test.want.New().Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("ForTree(%q).EmptyRoot() = %x, want %x", test.id, got, want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	hasher := rfc6962.New(crypto.SHA512)
+	if err := Register("RFC6962_SHA512", hasher); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+	if err := Register("RFC6962_SHA512", hasher); err == nil {
+		t.Error("Register() of a duplicate succeeded, want error")
+	}
+	if err := Register("", hasher); err == nil {
+		t.Error("Register() with an empty ID succeeded, want error")
+	}
+
+	got, err := Get("RFC6962_SHA512")
+	if err != nil {
+		t.Fatalf("Get(): %v", err)
+	}
+	if got.Size() != sha512.Size {
+		t.Errorf("Get().Size() = %d, want %d", got.Size(), sha512.Size)
+	}
+	if ids := IDs(); !slices.Contains(ids, "RFC6962_SHA512") || !slices.IsSorted(ids) {
+		t.Errorf("IDs() = %v, want sorted IDs including RFC6962_SHA512", ids)
+	}
+}
+
+func TestRangeFactory(t *testing.T) {
+	sha256, _ := Get(RFC6962SHA256)
+	sha512256, _ := Get(RFC6962SHA512_256)
+	if RangeFactory(sha256) != RangeFactory(sha256) {
+		t.Error("RangeFactory() returned different factories for the same hasher")
+	}
+	if RangeFactory(sha256) == RangeFactory(sha512256) {
+		t.Error("RangeFactory() returned the same factory for different hashers")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensus
+
+import (
+	"net/http"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/zpages"
+	"google.golang.org/grpc"
+)
+
+// EnableZPages serves the OpenCensus zPages, which show live RPC statistics
+// (rpcz) and samples of recent traces (tracez), under pathPrefix on mux, or
+// on the default ServeMux if mux is nil. No exporter is needed. The returned
+// options must be passed to the GRPC server for its RPCs to be shown, unless
+// those returned by EnableRPCServerTracing already are.
+func EnableZPages(mux *http.ServeMux, pathPrefix string) []grpc.ServerOption {
+	zpages.Handle(mux, pathPrefix)
+	return []grpc.ServerOption{grpc.StatsHandler(&ocgrpc.ServerHandler{})}
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package process exports Go runtime and process statistics through a
+// monitoring.MetricFactory, so that they are available under every metrics
+// backend rather than only through the default Prometheus registry.
+package process
+
+import (
+	"context"
+	"math"
+	"os"
+	"runtime/metrics"
+	"strconv"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+)
+
+const (
+	goroutinesMetric   = "/sched/goroutines:goroutines"
+	gomaxprocsMetric   = "/sched/gomaxprocs:threads"
+	heapObjectsMetric  = "/memory/classes/heap/objects:bytes"
+	heapGoalMetric     = "/gc/heap/goal:bytes"
+	memoryTotalMetric  = "/memory/classes/total:bytes"
+	gcCyclesMetric     = "/gc/cycles/total:gc-cycles"
+	gcPausesMetric     = "/sched/pauses/total/gc:seconds"
+	schedLatencyMetric = "/sched/latencies:seconds"
+
+	quantileLabel = "quantile"
+)
+
+// quantiles are those of the GC pause and scheduling latency distributions
+// which are exported.
+var quantiles = []float64{0.5, 0.9, 0.99, 1}
+
+// Exporter periodically reads the Go runtime metrics and the statistics of
+// the process, and sets the corresponding metrics.
+type Exporter struct {
+	samples []metrics.Sample
+	start   time.Time
+
+	goroutines   monitoring.Gauge
+	gomaxprocs   monitoring.Gauge
+	heapObjects  monitoring.Gauge
+	heapGoal     monitoring.Gauge
+	memoryTotal  monitoring.Gauge
+	gcCycles     monitoring.Counter
+	gcPauses     monitoring.Gauge
+	schedLatency monitoring.Gauge
+	cpuSeconds   monitoring.Counter
+	maxRSS       monitoring.Gauge
+	openFDs      monitoring.Gauge
+	uptime       monitoring.Gauge
+
+	lastGCCycles   uint64
+	lastCPUSeconds float64
+}
+
+// NewExporter creates the metrics in mf, and returns an Exporter which sets
+// them. The metric names are prefixed with "runtime_" so that they don't
+// clash with those of the collectors of the Prometheus default registry.
+func NewExporter(mf monitoring.MetricFactory) *Exporter {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Exporter{
+		samples: []metrics.Sample{
+			{Name: goroutinesMetric},
+			{Name: gomaxprocsMetric},
+			{Name: heapObjectsMetric},
+			{Name: heapGoalMetric},
+			{Name: memoryTotalMetric},
+			{Name: gcCyclesMetric},
+			{Name: gcPausesMetric},
+			{Name: schedLatencyMetric},
+		},
+		start: time.Now(),
+
+		goroutines:   mf.NewGauge("runtime_goroutines", "Number of live goroutines"),
+		gomaxprocs:   mf.NewGauge("runtime_gomaxprocs", "Current value of GOMAXPROCS"),
+		heapObjects:  mf.NewGauge("runtime_heap_objects_bytes", "Bytes of heap memory occupied by live objects and dead objects not yet freed"),
+		heapGoal:     mf.NewGauge("runtime_heap_goal_bytes", "Heap size target for the end of the current GC cycle"),
+		memoryTotal:  mf.NewGauge("runtime_memory_total_bytes", "Bytes of memory mapped by the Go runtime"),
+		gcCycles:     mf.NewCounter("runtime_gc_cycles", "Number of completed GC cycles"),
+		gcPauses:     mf.NewGauge("runtime_gc_pause_seconds", "Quantiles of the stop-the-world pauses for garbage collection since the process started", quantileLabel),
+		schedLatency: mf.NewGauge("runtime_sched_latency_seconds", "Quantiles of the time goroutines have spent runnable before running, since the process started", quantileLabel),
+		cpuSeconds:   mf.NewCounter("runtime_process_cpu_seconds", "User and system CPU time used by the process"),
+		maxRSS:       mf.NewGauge("runtime_process_max_resident_memory_bytes", "Maximum resident set size of the process"),
+		openFDs:      mf.NewGauge("runtime_process_open_fds", "Number of open file descriptors of the process"),
+		uptime:       mf.NewGauge("runtime_process_uptime_seconds", "Time since the exporter was created, which is usually when the process started"),
+	}
+}
+
+// Run updates the metrics every interval until ctx is done.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	e.Update()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		e.Update()
+	}
+}
+
+// Update reads the runtime and process statistics, and sets the metrics.
+// Statistics which aren't supported on the platform are skipped.
+func (e *Exporter) Update() {
+	metrics.Read(e.samples)
+	for _, s := range e.samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			v := s.Value.Uint64()
+			switch s.Name {
+			case goroutinesMetric:
+				e.goroutines.Set(float64(v))
+			case gomaxprocsMetric:
+				e.gomaxprocs.Set(float64(v))
+			case heapObjectsMetric:
+				e.heapObjects.Set(float64(v))
+			case heapGoalMetric:
+				e.heapGoal.Set(float64(v))
+			case memoryTotalMetric:
+				e.memoryTotal.Set(float64(v))
+			case gcCyclesMetric:
+				if v > e.lastGCCycles {
+					e.gcCycles.Add(float64(v - e.lastGCCycles))
+					e.lastGCCycles = v
+				}
+			}
+		case metrics.KindFloat64Histogram:
+			g := e.gcPauses
+			if s.Name == schedLatencyMetric {
+				g = e.schedLatency
+			}
+			h := s.Value.Float64Histogram()
+			for _, q := range quantiles {
+				g.Set(quantile(h, q), strconv.FormatFloat(q, 'g', -1, 64))
+			}
+		}
+	}
+
+	if st, ok := readStats(); ok {
+		if st.cpuSeconds > e.lastCPUSeconds {
+			e.cpuSeconds.Add(st.cpuSeconds - e.lastCPUSeconds)
+			e.lastCPUSeconds = st.cpuSeconds
+		}
+		e.maxRSS.Set(float64(st.maxRSSBytes))
+	}
+	// This only works on Linux, where /proc/self/fd lists the descriptors.
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		e.openFDs.Set(float64(len(fds)))
+	}
+	e.uptime.Set(time.Since(e.start).Seconds())
+}
+
+// quantile returns an upper bound of the q quantile of h, which is the upper
+// boundary of the bucket containing it, or its lower boundary if that is the
+// last bucket. It returns 0 if h is empty.
+func quantile(h *metrics.Float64Histogram, q float64) float64 {
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= rank && c > 0 {
+			if upper := h.Buckets[i+1]; !math.IsInf(upper, 1) {
+				return upper
+			}
+			return h.Buckets[i]
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+// stats are the statistics of the process from the operating system.
+type stats struct {
+	cpuSeconds  float64
+	maxRSSBytes int64
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+)
+
+func TestExporterUpdate(t *testing.T) {
+	e := NewExporter(monitoring.InertMetricFactory{})
+	runtime.GC()
+	e.Update()
+
+	if got := e.goroutines.Value(); got < 1 {
+		t.Errorf("goroutines = %v, want at least 1", got)
+	}
+	if got, want := e.gomaxprocs.Value(), float64(runtime.GOMAXPROCS(0)); got != want {
+		t.Errorf("gomaxprocs = %v, want %v", got, want)
+	}
+	if got := e.heapObjects.Value(); got <= 0 {
+		t.Errorf("heap objects = %v, want positive", got)
+	}
+	gcCycles := e.gcCycles.Value()
+	if gcCycles < 1 {
+		t.Errorf("GC cycles = %v, want at least 1", gcCycles)
+	}
+
+	// Counters only grow by the difference since the last update.
+	runtime.GC()
+	e.Update()
+	if got := e.gcCycles.Value(); got <= gcCycles {
+		t.Errorf("GC cycles after another GC = %v, want more than %v", got, gcCycles)
+	}
+	if _, ok := readStats(); ok && e.cpuSeconds.Value() <= 0 {
+		t.Errorf("CPU seconds = %v, want positive", e.cpuSeconds.Value())
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{0, 5, 4, 1},
+		Buckets: []float64{0, 1, 2, 3, math.Inf(1)},
+	}
+	for _, tc := range []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0, want: 2},
+		{q: 0.5, want: 2},
+		{q: 0.9, want: 3},
+		{q: 0.99, want: 3},
+		{q: 1, want: 3},
+	} {
+		if got := quantile(h, tc.q); got != tc.want {
+			t.Errorf("quantile(%v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+	if got := quantile(&metrics.Float64Histogram{Counts: []uint64{0}, Buckets: []float64{0, 1}}, 0.5); got != 0 {
+		t.Errorf("quantile() of empty histogram = %v, want 0", got)
+	}
+}
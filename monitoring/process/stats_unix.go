@@ -0,0 +1,38 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package process
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// readStats returns the CPU time and maximum resident set size of the
+// process, from getrusage(2).
+func readStats() (stats, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return stats{}, false
+	}
+	cpu := float64(ru.Utime.Nano()+ru.Stime.Nano()) / 1e9
+	// Maxrss is in kilobytes on Linux, but in bytes on Darwin.
+	maxRSS := int64(ru.Maxrss)
+	if runtime.GOOS != "darwin" && runtime.GOOS != "ios" {
+		maxRSS *= 1024
+	}
+	return stats{cpuSeconds: cpu, maxRSSBytes: maxRSS}, true
+}
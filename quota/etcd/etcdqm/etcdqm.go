@@ -34,6 +34,12 @@ func New(client *clientv3.Client) *Manager {
 	return &Manager{qs: &storage.QuotaStorage{Client: client}}
 }
 
+// WatchConfigs keeps the quota configs in memory, up to date with etcd, until
+// ctx is done. See storage.QuotaStorage.WatchConfigs.
+func (m *Manager) WatchConfigs(ctx context.Context) error {
+	return m.qs.WatchConfigs(ctx)
+}
+
 // GetTokens implements the quota.Manager API.
 func (m *Manager) GetTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
 	return m.qs.Get(ctx, configNames(specs), int64(numTokens))
@@ -17,6 +17,7 @@
 package etcd
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"strings"
@@ -33,13 +34,12 @@ import (
 const QuotaManagerName = "etcd"
 
 var (
-	// Servers is a flag containing the address(es) of etcd servers
-	Servers = flag.String("etcd_servers", "", "A comma-separated list of etcd servers; no etcd registration if empty")
 	// TODO(Martin2112): suggested renaming these to etc_... to avoid clashes, but will it break existing deploys?
 	quotaMinBatchSize = flag.Int("quota_min_batch_size", cacheqm.DefaultMinBatchSize, "Minimum number of tokens to request from the quota system. "+
 		"Zero or lower means batching is disabled. Applicable for etcd quotas.")
 	quotaMaxCacheEntries = flag.Int("quota_max_cache_entries", cacheqm.DefaultMaxCacheEntries, "Max number of quota specs in the quota cache. "+
 		"Zero or lower means batching is disabled. Applicable for etcd quotas.")
+	quotaWatchConfigs = flag.Bool("etcd_quota_watch_configs", true, "If true, the etcd quota configs are kept in memory and updated by watching etcd, rather than being read for every quota request. Applicable for etcd quotas.")
 )
 
 func init() {
@@ -49,18 +49,31 @@ func init() {
 }
 
 func newEtcdQuotaManager() (quota.Manager, error) {
-	if *Servers == "" {
+	// The etcd_servers flag is defined by the binary, as the etcd election
+	// and service announcement use it too.
+	var servers string
+	if f := flag.Lookup("etcd_servers"); f != nil {
+		servers = f.Value.String()
+	}
+	if servers == "" {
 		return nil, fmt.Errorf("can't create etcd quotamanager - etcd_servers flag is unset")
 	}
 	client, err := clientv3.New(clientv3.Config{
-		Endpoints:   strings.Split(*Servers, ","),
+		Endpoints:   strings.Split(servers, ","),
 		DialTimeout: 5 * time.Second,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to etcd at %v: %v", *Servers, err)
+		return nil, fmt.Errorf("failed to connect to etcd at %v: %v", servers, err)
 	}
 
-	var qm quota.Manager = etcdqm.New(client)
+	etcdQM := etcdqm.New(client)
+	if *quotaWatchConfigs {
+		// The manager lives as long as the process.
+		if err := etcdQM.WatchConfigs(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to watch etcd quota configs: %v", err)
+		}
+	}
+	var qm quota.Manager = etcdQM
 	if *quotaMinBatchSize > 0 && *quotaMaxCacheEntries > 0 {
 		cachedQM, err := cacheqm.NewCachedManager(qm, *quotaMinBatchSize, *quotaMaxCacheEntries)
 		if err != nil {
@@ -19,6 +19,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/google/trillian/quota/etcd/storage"
 )
 
 const (
@@ -74,7 +76,7 @@ func newNameFilter(name string) (nameFilter, error) {
 	switch collection := nf[1]; collection {
 	case collectionTrees:
 		id := nf[2]
-		if id == wildcard {
+		if id == wildcard || id == storage.DefaultID {
 			break
 		}
 		// treeID must be an int64
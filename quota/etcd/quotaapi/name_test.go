@@ -30,10 +30,11 @@ func TestNewNameFilter(t *testing.T) {
 		{name: "quotas/trees/1/read/config"},
 		{name: "quotas/trees/12345/read/config"},
 		{name: "quotas/trees/12345/write/config"},
-		{name: "quotas/trees/-/read/config"},  // all trees/read
-		{name: "quotas/trees/-/write/config"}, // all trees/write
-		{name: "quotas/trees/12345/-/config"}, // all quotas for tree 12345
-		{name: "quotas/trees/-/-/config"},     // all trees
+		{name: "quotas/trees/default/write/config"}, // default for trees without a config
+		{name: "quotas/trees/-/read/config"},        // all trees/read
+		{name: "quotas/trees/-/write/config"},       // all trees/write
+		{name: "quotas/trees/12345/-/config"},       // all quotas for tree 12345
+		{name: "quotas/trees/-/-/config"},           // all trees
 
 		{name: "quotas/users/u/read/config"},
 		{name: "quotas/users/llama/read/config"},
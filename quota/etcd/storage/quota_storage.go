@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/trillian/quota"
@@ -37,6 +38,12 @@ import (
 
 const (
 	configsKey = "quotas/configs"
+
+	// DefaultID is the tree ID or user of the default configs, which apply to
+	// the trees and users without a config of their own, e.g.
+	// "quotas/trees/default/write/config". Each tree or user still has a bucket
+	// of its own.
+	DefaultID = "default"
 )
 
 var (
@@ -53,7 +60,7 @@ func init() {
 	if err != nil {
 		klog.Fatalf("bad global pattern: %v", err)
 	}
-	treesPattern, err = regexp.Compile(`^quotas/trees/\d+/(read|write)/config$`)
+	treesPattern, err = regexp.Compile(`^quotas/trees/(\d+|default)/(read|write)/config$`)
 	if err != nil {
 		klog.Fatalf("bad trees pattern: %v", err)
 	}
@@ -73,16 +80,110 @@ func IsNameValid(name string) bool {
 	case treesPattern.MatchString(name):
 		// Tree ID must fit on an int64
 		id := strings.Split(name, "/")[2]
+		if id == DefaultID {
+			return true
+		}
 		_, err := strconv.ParseInt(id, 10, 64)
 		return err == nil
 	}
 	return false
 }
 
+// defaultName returns the name of the default config for the tree or user
+// config name, or "" if name is a global or default config.
+func defaultName(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) != 5 || parts[2] == DefaultID {
+		return ""
+	}
+	parts[2] = DefaultID
+	return strings.Join(parts, "/")
+}
+
 // QuotaStorage is the interface between the etcd-based quota implementations (quota.Manager and
 // RPCs) and etcd itself.
 type QuotaStorage struct {
 	Client *clientv3.Client
+
+	// mu guards watched and watchedRev, which hold the configs kept up to
+	// date by WatchConfigs while it runs, and the etcd revision they are from.
+	mu         sync.RWMutex
+	watched    *storagepb.Configs
+	watchedRev int64
+}
+
+// WatchConfigs loads the configs, and then keeps them up to date by watching
+// etcd for changes until ctx is done, so that changes to the refill rates and
+// capacities of quotas take effect without the configs being read by every
+// Get, Peek, Put and Reset. It returns once the configs are loaded, and they
+// are read from etcd again once it stops.
+func (qs *QuotaStorage) WatchConfigs(ctx context.Context) error {
+	rsp, err := qs.Client.Get(ctx, configsKey)
+	if err != nil {
+		return err
+	}
+	var val []byte
+	if len(rsp.Kvs) > 0 {
+		val = rsp.Kvs[0].Value
+	}
+	if err := qs.setWatched(val, rsp.Header.Revision); err != nil {
+		return err
+	}
+
+	wch := qs.Client.Watch(ctx, configsKey, clientv3.WithRev(rsp.Header.Revision+1))
+	go func() {
+		defer qs.clearWatched()
+		for wrsp := range wch {
+			if err := wrsp.Err(); err != nil {
+				klog.Errorf("Watching quota configs: %v", err)
+				return
+			}
+			for _, ev := range wrsp.Events {
+				var val []byte
+				if ev.Type == clientv3.EventTypePut {
+					val = ev.Kv.Value
+				}
+				if err := qs.setWatched(val, ev.Kv.ModRevision); err != nil {
+					klog.Errorf("Watching quota configs: %v", err)
+					return
+				}
+				klog.Infof("Quota configs updated at revision %d", ev.Kv.ModRevision)
+			}
+		}
+	}()
+	return nil
+}
+
+// setWatched records val, the serialized configs at etcd revision rev, unless
+// newer ones are known already.
+func (qs *QuotaStorage) setWatched(val []byte, rev int64) error {
+	cfgs := &storagepb.Configs{}
+	if err := proto.Unmarshal(val, cfgs); err != nil {
+		return fmt.Errorf("error unmarshaling %v: %v", configsKey, err)
+	}
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	if rev > qs.watchedRev {
+		qs.watched, qs.watchedRev = cfgs, rev
+	}
+	return nil
+}
+
+func (qs *QuotaStorage) clearWatched() {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.watched, qs.watchedRev = nil, 0
+}
+
+// configs returns the configs, from WatchConfigs if it's running, or from s.
+func (qs *QuotaStorage) configs(s concurrency.STM) (*storagepb.Configs, error) {
+	qs.mu.RLock()
+	cfgs := qs.watched
+	qs.mu.RUnlock()
+	if cfgs != nil {
+		return cfgs, nil
+	}
+	return getConfigs(s)
 }
 
 // UpdateConfigs creates or updates the supplied configs in etcd.
@@ -99,7 +200,7 @@ func (qs *QuotaStorage) UpdateConfigs(ctx context.Context, reset bool, update fu
 	}
 
 	var updated *storagepb.Configs
-	_, err := concurrency.NewSTMSerializable(ctx, qs.Client, func(s concurrency.STM) error {
+	rsp, err := concurrency.NewSTMSerializable(ctx, qs.Client, func(s concurrency.STM) error {
 		previous, err := getConfigs(s)
 		if err != nil {
 			return err
@@ -135,7 +236,7 @@ func (qs *QuotaStorage) UpdateConfigs(ctx context.Context, reset bool, update fu
 			// Make no distinction between enabled and disabled configs here. Get/Peek/Put are
 			// prepared to handle it, and recording the bucket as if it were enabled allows us to
 			// take advantage of the already-existing reset and lowering logic.
-			key := bucketKey(cfg)
+			key := bucketKey(cfg.Name)
 
 			var prev *storagepb.Config
 			for _, p := range previous.Configs {
@@ -158,7 +259,7 @@ func (qs *QuotaStorage) UpdateConfigs(ctx context.Context, reset bool, update fu
 				s.Put(key, string(pb))
 			case cfg.MaxTokens < prev.MaxTokens: // lowered bucket
 				// modBucket will coerce tokens to cfg.MaxTokens, if necessary
-				if _, err := modBucket(s, cfg, now, 0 /* add */); err != nil {
+				if _, err := modBucket(s, cfg.Name, cfg, now, 0 /* add */); err != nil {
 					return err
 				}
 			}
@@ -166,7 +267,24 @@ func (qs *QuotaStorage) UpdateConfigs(ctx context.Context, reset bool, update fu
 
 		return nil
 	})
-	return updated, err
+	if err != nil {
+		return nil, err
+	}
+	// Let the changes take effect right away, rather than when the watch
+	// catches up with them.
+	qs.mu.Lock()
+	watching := qs.watched != nil
+	qs.mu.Unlock()
+	if watching {
+		pb, err := proto.Marshal(updated)
+		if err != nil {
+			return nil, err
+		}
+		if err := qs.setWatched(pb, rsp.Header.Revision); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
 }
 
 func validate(cfgs *storagepb.Configs) error {
@@ -235,7 +353,7 @@ func (qs *QuotaStorage) Get(ctx context.Context, names []string, tokens int64) e
 func (qs *QuotaStorage) mod(ctx context.Context, names []string, add int64) error {
 	now := timeSource.Now()
 	return qs.forNames(ctx, names, defaultMode, func(s concurrency.STM, name string, cfg *storagepb.Config) error {
-		_, err := modBucket(s, cfg, now, add)
+		_, err := modBucket(s, name, cfg, now, add)
 		return err
 	})
 }
@@ -252,7 +370,7 @@ func (qs *QuotaStorage) Peek(ctx context.Context, names []string) (map[string]in
 		if cfg == nil {
 			t = int64(quota.MaxTokens)
 		} else {
-			t, err = modBucket(s, cfg, now, 0 /* add */)
+			t, err = modBucket(s, name, cfg, now, 0 /* add */)
 		}
 		tokens[name] = t
 		return err
@@ -283,7 +401,7 @@ func (qs *QuotaStorage) Reset(ctx context.Context, names []string) error {
 		if err != nil {
 			return err
 		}
-		s.Put(bucketKey(cfg), string(pb))
+		s.Put(bucketKey(name), string(pb))
 		return nil
 	})
 }
@@ -304,7 +422,8 @@ const (
 // forNames calls fn for all configs specified by names. Execution is performed in a single etcd
 // transaction.
 // By default, fn is only called for known, enabled configs. See forNamesMode for other behaviors.
-// Names are validated and de-duped automatically.
+// Tree and user names without a config of their own get the default config of their collection, if
+// there is one. Names are validated and de-duped automatically.
 func (qs *QuotaStorage) forNames(ctx context.Context, names []string, mode forNamesMode, fn func(concurrency.STM, string, *storagepb.Config) error) error {
 	for _, name := range names {
 		if !IsNameValid(name) {
@@ -313,7 +432,7 @@ func (qs *QuotaStorage) forNames(ctx context.Context, names []string, mode forNa
 	}
 
 	_, err := concurrency.NewSTMSerializable(ctx, qs.Client, func(s concurrency.STM) error {
-		cfgs, err := getConfigs(s)
+		cfgs, err := qs.configs(s)
 		if err != nil {
 			return err
 		}
@@ -326,16 +445,17 @@ func (qs *QuotaStorage) forNames(ctx context.Context, names []string, mode forNa
 			seenNames[name] = true
 
 			emitted := false
-			for _, cfg := range cfgs.Configs {
-				if cfg.Name == name {
-					if cfg.State == storagepb.Config_ENABLED {
-						if err := fn(s, name, cfg); err != nil {
-							return err
-						}
-						emitted = true
-					}
-					break
+			cfg := findConfig(cfgs, name)
+			if cfg == nil {
+				if dn := defaultName(name); dn != "" {
+					cfg = findConfig(cfgs, dn)
+				}
+			}
+			if cfg != nil && cfg.State == storagepb.Config_ENABLED {
+				if err := fn(s, name, cfg); err != nil {
+					return err
 				}
+				emitted = true
 			}
 			if !emitted && mode == emitInfinite {
 				if err := fn(s, name, nil); err != nil {
@@ -348,8 +468,17 @@ func (qs *QuotaStorage) forNames(ctx context.Context, names []string, mode forNa
 	return err
 }
 
+// findConfig returns the config called name from cfgs, or nil if there's none.
+func findConfig(cfgs *storagepb.Configs, name string) *storagepb.Config {
+	for _, cfg := range cfgs.Configs {
+		if cfg.Name == name {
+			return cfg
+		}
+	}
+	return nil
+}
+
 func getConfigs(s concurrency.STM) (*storagepb.Configs, error) {
-	// TODO(codingllama): Consider watching configs instead of re-reading
 	cfgs := &storagepb.Configs{}
 	val := s.Get(configsKey)
 	if val == "" {
@@ -363,21 +492,29 @@ func getConfigs(s concurrency.STM) (*storagepb.Configs, error) {
 	return cfgs, nil
 }
 
-// modBucket adds "add" tokens to the specified quota. Add may be negative or zero.
+// modBucket adds "add" tokens to the bucket of the named quota, whose config is cfg. Add may be
+// negative or zero. Buckets which don't exist yet, i.e. those of trees and users which use a default
+// config, start out full.
 // Time-based quotas that are due replenishment will be replenished before the add operation. Quotas
 // that are above ceiling (eg, due to lowered max tokens) will also be constrained to the
 // appropriate ceiling. As a consequence, calls with add = 0 are still useful for peeking and the
 // explained side-effects.
 // modBucket returns the current token count for cfg.
-func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int64) (int64, error) {
-	key := bucketKey(cfg)
+func modBucket(s concurrency.STM, name string, cfg *storagepb.Config, now time.Time, add int64) (int64, error) {
+	key := bucketKey(name)
 
 	val := s.Get(key)
-	var prevBucket storagepb.Bucket
-	if err := proto.Unmarshal([]byte(val), &prevBucket); err != nil {
-		return 0, fmt.Errorf("error unmarshaling %v: %v", key, err)
+	prevBucket := &storagepb.Bucket{
+		Tokens:                        cfg.MaxTokens,
+		LastReplenishMillisSinceEpoch: now.UnixNano() / 1e6,
+	}
+	if val != "" {
+		prevBucket = &storagepb.Bucket{}
+		if err := proto.Unmarshal([]byte(val), prevBucket); err != nil {
+			return 0, fmt.Errorf("error unmarshaling %v: %v", key, err)
+		}
 	}
-	newBucket := proto.Clone(&prevBucket).(*storagepb.Bucket)
+	newBucket := proto.Clone(prevBucket).(*storagepb.Bucket)
 
 	if tb := cfg.GetTimeBased(); tb != nil {
 		if now.Unix() >= newBucket.LastReplenishMillisSinceEpoch/1e3+tb.ReplenishIntervalSeconds {
@@ -400,7 +537,7 @@ func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int6
 		newBucket.Tokens = cfg.MaxTokens
 	}
 
-	if !proto.Equal(&prevBucket, newBucket) {
+	if val == "" || !proto.Equal(prevBucket, newBucket) {
 		pb, err := proto.Marshal(newBucket)
 		if err != nil {
 			return 0, err
@@ -411,6 +548,6 @@ func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int6
 	return newBucket.Tokens, nil
 }
 
-func bucketKey(cfg *storagepb.Config) string {
-	return fmt.Sprintf("%v/0", cfg.Name)
+func bucketKey(name string) string {
+	return fmt.Sprintf("%v/0", name)
 }
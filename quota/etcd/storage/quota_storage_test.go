@@ -102,6 +102,8 @@ func TestIsNameValid(t *testing.T) {
 		{name: "quotas/global/write/config", want: true},
 		{name: "quotas/trees/12356/read/config", want: true},
 		{name: "quotas/users/llama/write/config", want: true},
+		{name: "quotas/trees/default/write/config", want: true},
+		{name: "quotas/users/default/read/config", want: true},
 
 		{name: "bad/quota/name"},
 		{name: "badprefix/quotas/global/read/config"},
@@ -501,6 +503,126 @@ func TestQuotaStorage_DisabledConfig(t *testing.T) {
 	}
 }
 
+func TestQuotaStorage_DefaultConfigs(t *testing.T) {
+	defer setupTimeSource(fixedTimeSource)()
+
+	ctx := context.Background()
+	qs := &QuotaStorage{Client: client}
+
+	const (
+		tree1, tree2 = "quotas/trees/9001/write/config", "quotas/trees/9002/write/config"
+		tree3, tree4 = "quotas/trees/9003/write/config", "quotas/trees/9004/write/config"
+	)
+	cfgs := &storagepb.Configs{
+		Configs: []*storagepb.Config{
+			{
+				Name:      "quotas/trees/default/write/config",
+				State:     storagepb.Config_ENABLED,
+				MaxTokens: 100,
+				ReplenishmentStrategy: &storagepb.Config_SequencingBased{
+					SequencingBased: &storagepb.SequencingBasedStrategy{},
+				},
+			},
+			{
+				Name:      tree3,
+				State:     storagepb.Config_ENABLED,
+				MaxTokens: 10,
+				ReplenishmentStrategy: &storagepb.Config_SequencingBased{
+					SequencingBased: &storagepb.SequencingBasedStrategy{},
+				},
+			},
+			{
+				Name:      tree4,
+				State:     storagepb.Config_DISABLED,
+				MaxTokens: 10,
+				ReplenishmentStrategy: &storagepb.Config_SequencingBased{
+					SequencingBased: &storagepb.SequencingBasedStrategy{},
+				},
+			},
+		},
+	}
+	if _, err := qs.UpdateConfigs(ctx, true /* reset */, updater(cfgs)); err != nil {
+		t.Fatalf("UpdateConfigs() returned err = %v", err)
+	}
+
+	// Trees without a config of their own start out with full buckets of their own.
+	if err := peekAndDiff(ctx, qs, map[string]int64{tree1: 100, tree2: 100, tree3: 10, tree4: quotaMaxTokens}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+	if err := qs.Get(ctx, []string{tree1, tree3, tree4}, 5); err != nil {
+		t.Fatalf("Get() returned err = %v", err)
+	}
+	if err := qs.Get(ctx, []string{tree2}, 100); err != nil {
+		t.Fatalf("Get() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, qs, map[string]int64{tree1: 95, tree2: 0, tree3: 5, tree4: quotaMaxTokens}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+	if err := qs.Get(ctx, []string{tree2}, 1); err == nil {
+		t.Error("Get() on an exhausted default quota returned err = nil")
+	}
+
+	// Lowering the default applies to all of the trees using it.
+	cfgs.Configs[0].MaxTokens = 50
+	if _, err := qs.UpdateConfigs(ctx, false /* reset */, updater(cfgs)); err != nil {
+		t.Fatalf("UpdateConfigs() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, qs, map[string]int64{tree1: 50, tree2: 0}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+	if err := qs.Reset(ctx, []string{tree2}); err != nil {
+		t.Fatalf("Reset() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, qs, map[string]int64{tree1: 50, tree2: 50}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+}
+
+func TestQuotaStorage_WatchConfigs(t *testing.T) {
+	defer setupTimeSource(fixedTimeSource)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	writer := &QuotaStorage{Client: client}
+	watcher := &QuotaStorage{Client: client}
+
+	cfgs := deepCopy(cfgs)
+	cfgs.Configs = cfgs.Configs[1:2] // Only global/write
+	globalWrite := cfgs.Configs[0]
+	if _, err := writer.UpdateConfigs(ctx, true /* reset */, updater(cfgs)); err != nil {
+		t.Fatalf("UpdateConfigs() returned err = %v", err)
+	}
+	if err := watcher.WatchConfigs(ctx); err != nil {
+		t.Fatalf("WatchConfigs() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, watcher, map[string]int64{globalWrite.Name: globalWrite.MaxTokens}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+
+	// Changes made elsewhere are picked up by the watch.
+	globalWrite.MaxTokens /= 2
+	if _, err := writer.UpdateConfigs(ctx, false /* reset */, updater(cfgs)); err != nil {
+		t.Fatalf("UpdateConfigs() returned err = %v", err)
+	}
+	want := map[string]int64{globalWrite.Name: globalWrite.MaxTokens}
+	for err := peekAndDiff(ctx, watcher, want); err != nil; err = peekAndDiff(ctx, watcher, want) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Watched configs not updated: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// Changes made through the watching instance apply right away.
+	globalWrite.State = storagepb.Config_DISABLED
+	if _, err := watcher.UpdateConfigs(ctx, false /* reset */, updater(cfgs)); err != nil {
+		t.Fatalf("UpdateConfigs() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, watcher, map[string]int64{globalWrite.Name: quotaMaxTokens}); err != nil {
+		t.Fatalf("peekAndDiff returned err = %v", err)
+	}
+}
+
 func TestQuotaStorage_Get(t *testing.T) {
 	fakeTime := clock.NewFake(time.Now())
 	setupTimeSource(fakeTime)
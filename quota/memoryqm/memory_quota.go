@@ -0,0 +1,157 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memoryqm defines an in-memory quota.Manager implementation, for
+// single-instance deployments which don't want to depend on etcd or a
+// database for quotas.
+package memoryqm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/util/clock"
+)
+
+// ParameterFunc returns the burst size and the rate, in tokens per second, of
+// the token bucket for a quota spec. A burst of quota.MaxTokens means the spec
+// is unlimited.
+type ParameterFunc func(spec quota.Spec) (burst int, rate float64)
+
+// LoadFunc reports whether the process is overloaded.
+type LoadFunc func() bool
+
+// ManagerOptions holds the parameters for a Manager.
+type ManagerOptions struct {
+	// Parameters returns the parameters for a given quota.Spec. This value
+	// must not be nil.
+	Parameters ParameterFunc
+	// Overloaded, if set, is consulted on every GetTokens call. While it
+	// returns true, requests for Read tokens are denied, so that writes and
+	// sequencing keep their share of the process's resources.
+	Overloaded LoadFunc
+	// TimeSource is used to refill buckets. Defaults to clock.System.
+	TimeSource clock.TimeSource
+}
+
+// Manager is a quota.Manager which keeps a token bucket per spec in memory.
+// Buckets start full, and are refilled continuously at their rate up to their
+// burst size. Quotas are not shared between processes.
+type Manager struct {
+	opts ManagerOptions
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+var _ quota.Manager = &Manager{}
+
+type bucket struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+// New returns a new in-memory quota.Manager.
+func New(opts ManagerOptions) *Manager {
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	return &Manager{opts: opts, buckets: make(map[string]*bucket)}
+}
+
+// GetTokens implements quota.Manager.GetTokens. Tokens are only taken if all
+// specs have enough of them.
+func (m *Manager) GetTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
+	if m.opts.Overloaded != nil && m.opts.Overloaded() {
+		for _, spec := range specs {
+			if spec.Kind == quota.Read {
+				return fmt.Errorf("server overloaded, shedding %v requests", spec.Name())
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.opts.TimeSource.Now()
+	var taken []*bucket
+	for _, spec := range specs {
+		b, _ := m.refill(spec, now)
+		if b == nil {
+			continue
+		}
+		if b.tokens < float64(numTokens) {
+			for _, t := range taken {
+				t.tokens += float64(numTokens)
+			}
+			err := &quota.InsufficientTokensError{Spec: spec, Available: int(b.tokens), Requested: numTokens}
+			if _, rate := m.opts.Parameters(spec); rate > 0 {
+				err.RetryAfter = time.Duration((float64(numTokens) - b.tokens) / rate * float64(time.Second))
+			}
+			return err
+		}
+		b.tokens -= float64(numTokens)
+		taken = append(taken, b)
+	}
+	return nil
+}
+
+// PutTokens implements quota.Manager.PutTokens. Buckets never hold more than
+// their burst size.
+func (m *Manager) PutTokens(ctx context.Context, numTokens int, specs []quota.Spec) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.opts.TimeSource.Now()
+	for _, spec := range specs {
+		if b, burst := m.refill(spec, now); b != nil {
+			b.tokens = math.Min(b.tokens+float64(numTokens), float64(burst))
+		}
+	}
+	return nil
+}
+
+// ResetQuota implements quota.Manager.ResetQuota, refilling the buckets.
+func (m *Manager) ResetQuota(ctx context.Context, specs []quota.Spec) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, spec := range specs {
+		delete(m.buckets, spec.Name())
+	}
+	return nil
+}
+
+// refill returns the up-to-date bucket for spec and its burst size, creating
+// the bucket if necessary. It returns a nil bucket for unlimited specs. Must
+// be called with m.mu held.
+func (m *Manager) refill(spec quota.Spec, now time.Time) (*bucket, int) {
+	burst, rate := m.opts.Parameters(spec)
+	if burst == quota.MaxTokens {
+		return nil, burst
+	}
+	name := spec.Name()
+	b, ok := m.buckets[name]
+	if !ok {
+		b = &bucket{tokens: float64(burst), lastUpdate: now}
+		m.buckets[name] = b
+		return b, burst
+	}
+	if elapsed := now.Sub(b.lastUpdate).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed*rate, float64(burst))
+		b.lastUpdate = now
+	}
+	return b, burst
+}
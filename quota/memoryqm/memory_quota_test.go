@@ -0,0 +1,131 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memoryqm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/util/clock"
+)
+
+var (
+	globalWrite = quota.Spec{Group: quota.Global, Kind: quota.Write}
+	treeWrite   = quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: 12345}
+	treeRead    = quota.Spec{Group: quota.Tree, Kind: quota.Read, TreeID: 12345}
+)
+
+func TestParseSpecs(t *testing.T) {
+	params, err := ParseSpecs("global/write=100:500, trees/write=10, trees/12345/read=1:2")
+	if err != nil {
+		t.Fatalf("ParseSpecs(): %v", err)
+	}
+	for _, test := range []struct {
+		spec      quota.Spec
+		wantBurst int
+		wantRate  float64
+	}{
+		{spec: globalWrite, wantBurst: 500, wantRate: 100},
+		{spec: treeWrite, wantBurst: 10, wantRate: 10},
+		{spec: treeRead, wantBurst: 2, wantRate: 1},
+		{spec: quota.Spec{Group: quota.Tree, Kind: quota.Read, TreeID: 1}, wantBurst: quota.MaxTokens},
+		{spec: quota.Spec{Group: quota.User, Kind: quota.Write, User: "alice"}, wantBurst: quota.MaxTokens},
+	} {
+		if burst, rate := params(test.spec); burst != test.wantBurst || rate != test.wantRate {
+			t.Errorf("params(%v) = %v, %v; want %v, %v", test.spec, burst, rate, test.wantBurst, test.wantRate)
+		}
+	}
+
+	for _, bad := range []string{"global/write", "trees/write=x", "trees/write=1:x", "forest/write=1", "trees/delete=1", "global/1/write=1"} {
+		if _, err := ParseSpecs(bad); err == nil {
+			t.Errorf("ParseSpecs(%q) returned nil error", bad)
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	params, err := ParseSpecs("global/write=10:20,trees/write=100")
+	if err != nil {
+		t.Fatalf("ParseSpecs(): %v", err)
+	}
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	m := New(ManagerOptions{Parameters: params, TimeSource: ts})
+	specs := []quota.Spec{treeWrite, globalWrite}
+
+	if err := m.GetTokens(ctx, 15, specs); err != nil {
+		t.Fatalf("GetTokens(15): %v", err)
+	}
+	// The global bucket only has 5 tokens left, so the tree's tokens must not
+	// be taken either.
+	err = m.GetTokens(ctx, 10, specs)
+	var ite *quota.InsufficientTokensError
+	if !errors.As(err, &ite) {
+		t.Fatalf("GetTokens(10) = %v, want InsufficientTokensError", err)
+	}
+	if ite.Spec != globalWrite || ite.RetryAfter != 500*time.Millisecond {
+		t.Errorf("GetTokens(10) denied %v, retry after %v; want %v, 500ms", ite.Spec, ite.RetryAfter, globalWrite)
+	}
+	if b := m.buckets[treeWrite.Name()]; b.tokens != 85 {
+		t.Errorf("tree bucket has %v tokens after failed request, want 85", b.tokens)
+	}
+
+	// One second refills 10 global tokens.
+	ts.Set(ts.Now().Add(time.Second))
+	if err := m.GetTokens(ctx, 15, specs); err != nil {
+		t.Fatalf("GetTokens(15) after refill: %v", err)
+	}
+
+	// Refunds are capped at the burst size.
+	if err := m.PutTokens(ctx, 100, specs); err != nil {
+		t.Fatalf("PutTokens(): %v", err)
+	}
+	if b := m.buckets[globalWrite.Name()]; b.tokens != 20 {
+		t.Errorf("global bucket has %v tokens after refund, want 20", b.tokens)
+	}
+
+	if err := m.GetTokens(ctx, 20, specs); err != nil {
+		t.Fatalf("GetTokens(20): %v", err)
+	}
+	if err := m.ResetQuota(ctx, specs); err != nil {
+		t.Fatalf("ResetQuota(): %v", err)
+	}
+	if err := m.GetTokens(ctx, 20, specs); err != nil {
+		t.Errorf("GetTokens(20) after reset: %v", err)
+	}
+}
+
+func TestManagerShedding(t *testing.T) {
+	ctx := context.Background()
+	overloaded := true
+	m := New(ManagerOptions{
+		Parameters: func(quota.Spec) (int, float64) { return quota.MaxTokens, 0 },
+		Overloaded: func() bool { return overloaded },
+	})
+
+	if err := m.GetTokens(ctx, 1, []quota.Spec{treeRead}); err == nil {
+		t.Error("GetTokens(read) succeeded while overloaded")
+	}
+	if err := m.GetTokens(ctx, 1, []quota.Spec{treeWrite}); err != nil {
+		t.Errorf("GetTokens(write) while overloaded: %v", err)
+	}
+	overloaded = false
+	if err := m.GetTokens(ctx, 1, []quota.Spec{treeRead}); err != nil {
+		t.Errorf("GetTokens(read): %v", err)
+	}
+}
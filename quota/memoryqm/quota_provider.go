@@ -0,0 +1,129 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memoryqm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/util/load"
+	"k8s.io/klog/v2"
+)
+
+// QuotaManagerName identifies the in-memory quota implementation.
+const QuotaManagerName = "memory"
+
+var (
+	specsFlag = flag.String("memory_quota_specs", "", "Comma-separated list of <spec>=<rate>[:<burst>] in-memory quotas, in tokens per second. "+
+		"<spec> is global/read, global/write, trees/read, trees/write, users/read or users/write, which apply to each tree or user, "+
+		"or a single tree or user such as trees/1234/write. The burst defaults to the rate. Specs not listed are unlimited. "+
+		"Only effective for --quota_system=memory.")
+	maxHeapBytes = flag.Uint64("memory_quota_max_heap_bytes", 0, "If set, read requests are denied while the live heap exceeds this many bytes. "+
+		"Only effective for --quota_system=memory.")
+	maxCPU = flag.Float64("memory_quota_max_cpu", 0, "If set, read requests are denied while the fraction of available CPU used exceeds this, e.g. 0.9. "+
+		"Only effective for --quota_system=memory.")
+	loadInterval = flag.Duration("memory_quota_load_interval", time.Second, "How often to sample heap and CPU usage. "+
+		"Only effective for --quota_system=memory.")
+)
+
+func init() {
+	if err := quota.RegisterProvider(QuotaManagerName, newMemoryQuotaManager); err != nil {
+		klog.Fatalf("Failed to register quota manager %v: %v", QuotaManagerName, err)
+	}
+}
+
+func newMemoryQuotaManager() (quota.Manager, error) {
+	params, err := ParseSpecs(*specsFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --memory_quota_specs: %v", err)
+	}
+	opts := ManagerOptions{Parameters: params}
+	if *maxHeapBytes > 0 || *maxCPU > 0 {
+		l := load.NewMonitor(*maxHeapBytes, *maxCPU)
+		go l.Run(context.Background(), *loadInterval)
+		opts.Overloaded = l.Overloaded
+	}
+	klog.Info("Using in-memory QuotaManager")
+	return New(opts), nil
+}
+
+type bucketParams struct {
+	burst int
+	rate  float64
+}
+
+// ParseSpecs parses a comma-separated list of <spec>=<rate>[:<burst>] quota
+// definitions, as described by the --memory_quota_specs flag, and returns a
+// ParameterFunc for them.
+func ParseSpecs(s string) (ParameterFunc, error) {
+	params := make(map[string]bucketParams)
+	for _, def := range strings.Split(s, ",") {
+		if def = strings.TrimSpace(def); def == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(def, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: want <spec>=<rate>[:<burst>]", def)
+		}
+		if err := validateSpecName(name); err != nil {
+			return nil, fmt.Errorf("%q: %v", def, err)
+		}
+		rateStr, burstStr, hasBurst := strings.Cut(value, ":")
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("%q: invalid rate", def)
+		}
+		burst := int(rate)
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstStr); err != nil || burst < 0 {
+				return nil, fmt.Errorf("%q: invalid burst", def)
+			}
+		}
+		params[name] = bucketParams{burst: burst, rate: rate}
+	}
+
+	return func(spec quota.Spec) (int, float64) {
+		if p, ok := params[spec.Name()]; ok {
+			return p.burst, p.rate
+		}
+		// Fall back to the definition for the group, e.g. trees/write.
+		group, _, _ := strings.Cut(spec.Name(), "/")
+		if p, ok := params[group+"/"+strings.ToLower(spec.Kind.String())]; ok && spec.Group != quota.Global {
+			return p.burst, p.rate
+		}
+		return quota.MaxTokens, 0
+	}, nil
+}
+
+// validateSpecName checks that name is of the form <group>/<kind> or
+// <group>/<id>/<kind>.
+func validateSpecName(name string) error {
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 2 && (parts[0] == "global" || parts[0] == "trees" || parts[0] == "users"):
+	case len(parts) == 3 && (parts[0] == "trees" || parts[0] == "users") && parts[1] != "":
+	default:
+		return fmt.Errorf("invalid spec name %q", name)
+	}
+	if kind := parts[len(parts)-1]; kind != "read" && kind != "write" {
+		return fmt.Errorf("invalid kind %q", kind)
+	}
+	return nil
+}
@@ -37,6 +37,7 @@ const (
 			AND table_name = ?
 			AND table_type = ?`
 	countFromUnsequencedQuery = "SELECT COUNT(*) FROM Unsequenced"
+	countFromCountsQuery      = "SELECT COALESCE(SUM(Count), 0) FROM UnsequencedCounts"
 )
 
 // ErrTooManyUnsequencedRows is returned when tokens are requested but Unsequenced has grown
@@ -45,10 +46,13 @@ var ErrTooManyUnsequencedRows = errors.New("too many unsequenced rows")
 
 // QuotaManager is a MySQL-based quota.Manager implementation.
 //
-// It has two working modes: one queries the information schema for the number of Unsequenced rows,
-// the other does a select count(*) on the Unsequenced table. Information schema queries are
+// It has three working modes: one queries the information schema for the number of Unsequenced rows,
+// another does a select count(*) on the Unsequenced table. Information schema queries are
 // default, even though they are approximate, as they're constant time (select count(*) on InnoDB
 // based MySQL needs to traverse the index and may take quite a while to complete).
+// The third sums the exact counts in the UnsequencedCounts table, which the MySQL log storage
+// maintains if its MaintainUnsequencedCounts option is set, at the cost of an extra write in each
+// transaction which queues or dequeues leaves.
 //
 // QuotaManager only implements Global/Write quotas, which is based on the number of Unsequenced
 // rows (to be exact, tokens = MaxUnsequencedRows - actualUnsequencedRows).
@@ -57,6 +61,9 @@ type QuotaManager struct {
 	DB                 *sql.DB
 	MaxUnsequencedRows int
 	UseSelectCount     bool
+	// UseCounts selects the UnsequencedCounts mode, and takes precedence
+	// over UseSelectCount.
+	UseCounts bool
 }
 
 // GetTokens implements quota.Manager.GetTokens.
@@ -92,6 +99,9 @@ func (m *QuotaManager) ResetQuota(ctx context.Context, specs []quota.Spec) error
 }
 
 func (m *QuotaManager) countUnsequenced(ctx context.Context) (int, error) {
+	if m.UseCounts {
+		return countFromCounts(ctx, m.DB)
+	}
 	if m.UseSelectCount {
 		return countFromTable(ctx, m.DB)
 	}
@@ -135,6 +145,14 @@ func countFromTable(ctx context.Context, db *sql.DB) (int, error) {
 	return count, nil
 }
 
+func countFromCounts(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	if err := db.QueryRowContext(ctx, countFromCountsQuery).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // turnOffInformationSchemaCache turn off statistics caching for MySQL 8
 // To always retrieve the latest statistics directly from the storage engine and bypass cached values, set information_schema_stats_expiry to 0.
 // See https://dev.mysql.com/doc/refman/8.0/en/server-system-variables.html#sysvar_information_schema_stats_expiry
@@ -121,15 +121,16 @@ func TestQuotaManager_GetTokens_InformationSchema(t *testing.T) {
 	maxUnsequenced := 20
 	globalWriteSpec := []quota.Spec{{Group: quota.Global, Kind: quota.Write}}
 
-	// Make both variants go through the test.
+	// Make all variants go through the test.
 	tests := []struct {
-		useSelectCount bool
+		useSelectCount, useCounts bool
 	}{
 		{useSelectCount: true},
 		{useSelectCount: false},
+		{useCounts: true},
 	}
 	for _, test := range tests {
-		desc := fmt.Sprintf("useSelectCount = %v", test.useSelectCount)
+		desc := fmt.Sprintf("useSelectCount = %v, useCounts = %v", test.useSelectCount, test.useCounts)
 		t.Run(desc, func(t *testing.T) {
 			db, done, err := testdb.NewTrillianDB(ctx, testdb.DriverMySQL)
 			if err != nil {
@@ -142,7 +143,7 @@ func TestQuotaManager_GetTokens_InformationSchema(t *testing.T) {
 				t.Fatalf("createTree() returned err = %v", err)
 			}
 
-			qm := &mysqlqm.QuotaManager{DB: db, MaxUnsequencedRows: maxUnsequenced, UseSelectCount: test.useSelectCount}
+			qm := &mysqlqm.QuotaManager{DB: db, MaxUnsequencedRows: maxUnsequenced, UseSelectCount: test.useSelectCount, UseCounts: test.useCounts}
 
 			// All GetTokens() calls where leaves < maxUnsequenced should succeed:
 			// information_schema may be outdated, but it should refer to a valid point in the
@@ -178,6 +179,50 @@ func TestQuotaManager_GetTokens_InformationSchema(t *testing.T) {
 	}
 }
 
+// BenchmarkQuotaManager_GetTokens compares the cost of counting 100k
+// unsequenced rows in each of the modes of the QuotaManager.
+func BenchmarkQuotaManager_GetTokens(b *testing.B) {
+	if !testdb.MySQLAvailable() {
+		b.Skip("Skipping benchmark as MySQL not available")
+	}
+	ctx := context.Background()
+	const leafCount, batchSize = 100000, 1000
+
+	db, done, err := testdb.NewTrillianDB(ctx, testdb.DriverMySQL)
+	if err != nil {
+		b.Fatalf("NewTrillianDB() returned err = %v", err)
+	}
+	defer done(ctx)
+	tree, err := createTree(ctx, db)
+	if err != nil {
+		b.Fatalf("createTree() returned err = %v", err)
+	}
+	for i := 0; i < leafCount; i += batchSize {
+		if err := queueLeaves(ctx, db, tree, i /* firstID */, batchSize); err != nil {
+			b.Fatalf("queueLeaves() returned err = %v", err)
+		}
+	}
+
+	globalWriteSpec := []quota.Spec{{Group: quota.Global, Kind: quota.Write}}
+	for _, test := range []struct {
+		desc                      string
+		useSelectCount, useCounts bool
+	}{
+		{desc: "information-schema"},
+		{desc: "select-count", useSelectCount: true},
+		{desc: "counts", useCounts: true},
+	} {
+		b.Run(test.desc, func(b *testing.B) {
+			qm := &mysqlqm.QuotaManager{DB: db, MaxUnsequencedRows: 2 * leafCount, UseSelectCount: test.useSelectCount, UseCounts: test.useCounts}
+			for n := 0; n < b.N; n++ {
+				if err := qm.GetTokens(ctx, 1 /* numTokens */, globalWriteSpec); err != nil {
+					b.Fatalf("GetTokens() returned err = %v", err)
+				}
+			}
+		})
+	}
+}
+
 func TestQuotaManager_Noops(t *testing.T) {
 	testdb.SkipIfNoMySQL(t)
 	ctx := context.Background()
@@ -286,7 +331,7 @@ func queueLeaves(ctx context.Context, db *sql.DB, tree *trillian.Tree, firstID,
 		})
 	}
 
-	ls := mysql.NewLogStorage(db, nil)
+	ls := mysql.NewLogStorageWithOpts(db, nil, mysql.LogStorageOptions{MaintainUnsequencedCounts: true})
 	_, err := ls.QueueLeaves(ctx, tree, leaves, time.Now())
 	return err
 }
@@ -308,6 +353,9 @@ func setUnsequencedRows(ctx context.Context, db *sql.DB, tree *trillian.Tree, wa
 	if _, err := db.ExecContext(ctx, "DELETE FROM Unsequenced"); err != nil {
 		return err
 	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM UnsequencedCounts"); err != nil {
+		return err
+	}
 	if err := queueLeaves(ctx, db, tree, 0 /* firstID */, wantRows); err != nil {
 		return err
 	}
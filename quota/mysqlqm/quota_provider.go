@@ -27,6 +27,8 @@ const QuotaManagerName = "mysql"
 
 var maxUnsequencedRows = flag.Int("max_unsequenced_rows", DefaultMaxUnsequenced, "Max number of unsequenced rows before rate limiting kicks in. "+
 	"Only effective for quota_system=mysql.")
+var exactCount = flag.Bool("mysql_quota_exact_count", false, "If true, the unsequenced rows are counted exactly from the UnsequencedCounts table, rather than approximately from the information schema. "+
+	"Requires --mysql_maintain_unsequenced_counts on all log servers and signers. Only effective for quota_system=mysql.")
 
 func init() {
 	if err := quota.RegisterProvider(QuotaManagerName, newMySQLQuotaManager); err != nil {
@@ -42,6 +44,7 @@ func newMySQLQuotaManager() (quota.Manager, error) {
 	qm := &QuotaManager{
 		DB:                 db,
 		MaxUnsequencedRows: *maxUnsequencedRows,
+		UseCounts:          *exactCount,
 	}
 	klog.Info("Using MySQL QuotaManager")
 	return qm, nil
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // MaxTokens is the maximum number of available tokens a quota may have.
@@ -101,6 +102,24 @@ func (s Spec) String() string {
 	return s.Name()
 }
 
+// InsufficientTokensError is returned by Managers which deny tokens because a
+// spec doesn't have enough of them, so that callers can tell which.
+type InsufficientTokensError struct {
+	// Spec is the spec which doesn't have enough tokens.
+	Spec Spec
+	// Available and Requested are the numbers of tokens of Spec which are
+	// available and were requested.
+	Available, Requested int
+	// RetryAfter, if positive, estimates how long it will be until enough
+	// tokens are available.
+	RetryAfter time.Duration
+}
+
+// Error returns a description of e.
+func (e *InsufficientTokensError) Error() string {
+	return fmt.Sprintf("insufficient tokens on %v (%v vs %v)", e.Spec.Name(), e.Available, e.Requested)
+}
+
 // Manager is the component responsible for the management of tokens.
 type Manager interface {
 	// GetTokens acquires numTokens from all specs. Tokens are taken in the order specified by
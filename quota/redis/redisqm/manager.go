@@ -17,7 +17,7 @@ package redisqm
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/redis/redistb"
@@ -94,7 +94,11 @@ func (m *Manager) getTokensSingle(ctx context.Context, numTokens int, spec quota
 		return err
 	}
 	if !allowed {
-		return fmt.Errorf("insufficient tokens on %v (%v vs %v)", name, remaining, numTokens)
+		err := &quota.InsufficientTokensError{Spec: spec, Available: int(remaining), Requested: numTokens}
+		if rate > 0 {
+			err.RetryAfter = time.Duration(float64(int64(numTokens)-remaining) / rate * float64(time.Second))
+		}
+		return err
 	}
 
 	return nil
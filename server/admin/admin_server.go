@@ -17,20 +17,35 @@ package admin
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/envelope"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// defaultIntegrationEventPageSize is the number of integration events
+	// returned by ListIntegrationEvents if the request doesn't set a page
+	// size.
+	defaultIntegrationEventPageSize = 100
+	// maxIntegrationEventPageSize caps the page size of ListIntegrationEvents.
+	maxIntegrationEventPageSize = 1000
+)
+
 // Server is an implementation of trillian.TrillianAdminServer.
 type Server struct {
 	registry         extension.Registry
 	allowedTreeTypes []trillian.TreeType
+	opsCfg           OperationsConfig
+	ops              *operations
 }
 
 // New returns a trillian.TrillianAdminServer implementation.
@@ -38,9 +53,20 @@ type Server struct {
 // allowedTreeTypes defines which tree types may be created through this server,
 // with nil meaning unrestricted.
 func New(registry extension.Registry, allowedTreeTypes []trillian.TreeType) *Server {
+	return NewWithOperations(registry, allowedTreeTypes, OperationsConfig{})
+}
+
+// NewWithOperations is like New, but the long-running operations of the
+// Server are configured by cfg.
+func NewWithOperations(registry extension.Registry, allowedTreeTypes []trillian.TreeType, cfg OperationsConfig) *Server {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultOperationBatchSize
+	}
 	return &Server{
 		registry:         registry,
 		allowedTreeTypes: allowedTreeTypes,
+		opsCfg:           cfg,
+		ops:              newOperations(cfg.Retention),
 	}
 }
 
@@ -88,11 +114,30 @@ func (s *Server) CreateTree(ctx context.Context, req *trillian.CreateTreeRequest
 	tree.UpdateTime = nil
 	tree.Deleted = false
 	tree.DeleteTime = nil
+	tree.SequencingRequestedTime = nil
+
+	if enc := tree.LeafEncryption; enc != nil && len(enc.WrappedKey) == 0 {
+		if enc.KeyUri == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "leaf_encryption requires a key_uri")
+		}
+		if s.registry.LeafKeyWrapper == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaf encryption is not configured on this server")
+		}
+		var err error
+		if tree.LeafEncryption, err = envelope.NewDataKey(ctx, s.registry.LeafKeyWrapper, enc.KeyUri); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to create data key: %v", err)
+		}
+	}
+
+	if err := s.checkIdentifiersUnique(ctx, tree); err != nil {
+		return nil, err
+	}
 
 	createdTree, err := storage.CreateTree(ctx, s.registry.AdminStorage, tree)
 	if err != nil {
 		return nil, err
 	}
+	auditTree("created", createdTree)
 	return createdTree, nil
 }
 
@@ -119,6 +164,16 @@ func (s *Server) UpdateTree(ctx context.Context, req *trillian.UpdateTreeRequest
 	if err := applyUpdateMask(&trillian.Tree{}, &trillian.Tree{}, mask); err != nil {
 		return nil, err
 	}
+	ids := &trillian.Tree{TreeId: tree.TreeId}
+	if slices.Contains(mask.Paths, "checkpoint_origin") {
+		ids.CheckpointOrigin = tree.CheckpointOrigin
+	}
+	if slices.Contains(mask.Paths, "ecosystem_ids") {
+		ids.EcosystemIds = tree.EcosystemIds
+	}
+	if err := s.checkIdentifiersUnique(ctx, ids); err != nil {
+		return nil, err
+	}
 
 	updatedTree, err := storage.UpdateTree(ctx, s.registry.AdminStorage, tree.TreeId, func(other *trillian.Tree) {
 		if err := applyUpdateMask(tree, other, mask); err != nil {
@@ -129,6 +184,7 @@ func (s *Server) UpdateTree(ctx context.Context, req *trillian.UpdateTreeRequest
 	if err != nil {
 		return nil, err
 	}
+	auditTree(fmt.Sprintf("updated %v of", mask.GetPaths()), updatedTree)
 	return updatedTree, nil
 }
 
@@ -150,6 +206,32 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 			to.StorageSettings = from.StorageSettings
 		case "max_root_duration":
 			to.MaxRootDuration = from.MaxRootDuration
+		case "auto_freeze":
+			to.AutoFreeze = from.AutoFreeze
+		case "max_tree_size":
+			to.MaxTreeSize = from.MaxTreeSize
+		case "owner":
+			to.Owner = from.Owner
+		case "contact":
+			to.Contact = from.Contact
+		case "mutable_extra_data":
+			to.MutableExtraData = from.MutableExtraData
+		case "allow_redaction":
+			to.AllowRedaction = from.AllowRedaction
+		case "merge_delay_target":
+			to.MergeDelayTarget = from.MergeDelayTarget
+		case "dequeue_order":
+			to.DequeueOrder = from.DequeueOrder
+		case "disabled_methods":
+			to.DisabledMethods = from.DisabledMethods
+		case "allow_prehashed_leaves":
+			to.AllowPrehashedLeaves = from.AllowPrehashedLeaves
+		case "leaf_validators":
+			to.LeafValidators = from.LeafValidators
+		case "checkpoint_origin":
+			to.CheckpointOrigin = from.CheckpointOrigin
+		case "ecosystem_ids":
+			to.EcosystemIds = from.EcosystemIds
 		default:
 			return status.Errorf(codes.InvalidArgument, "invalid update_mask path: %q", path)
 		}
@@ -157,12 +239,42 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 	return nil
 }
 
+// checkIdentifiersUnique returns an AlreadyExists error if a tree other than
+// tree has its checkpoint origin or one of its ecosystem IDs. Deleted trees
+// count, as they may be undeleted. The check isn't atomic with the write which
+// follows it, so concurrent admin requests for different trees could still
+// give them the same identifiers.
+func (s *Server) checkIdentifiersUnique(ctx context.Context, tree *trillian.Tree) error {
+	if tree.CheckpointOrigin == "" && len(tree.EcosystemIds) == 0 {
+		return nil
+	}
+	trees, err := storage.ListTrees(ctx, s.registry.AdminStorage, true /* includeDeleted */)
+	if err != nil {
+		return err
+	}
+	for _, other := range trees {
+		if other.TreeId == tree.TreeId {
+			continue
+		}
+		if tree.CheckpointOrigin != "" && other.CheckpointOrigin == tree.CheckpointOrigin {
+			return status.Errorf(codes.AlreadyExists, "checkpoint_origin %q is already used by tree %d", tree.CheckpointOrigin, other.TreeId)
+		}
+		for _, id := range other.EcosystemIds {
+			if slices.Contains(tree.EcosystemIds, id) {
+				return status.Errorf(codes.AlreadyExists, "ecosystem ID %q is already used by tree %d", id, other.TreeId)
+			}
+		}
+	}
+	return nil
+}
+
 // DeleteTree implements trillian.TrillianAdminServer.DeleteTree.
 func (s *Server) DeleteTree(ctx context.Context, req *trillian.DeleteTreeRequest) (*trillian.Tree, error) {
 	tree, err := storage.SoftDeleteTree(ctx, s.registry.AdminStorage, req.GetTreeId())
 	if err != nil {
 		return nil, err
 	}
+	auditTree("deleted", tree)
 	return tree, nil
 }
 
@@ -172,5 +284,88 @@ func (s *Server) UndeleteTree(ctx context.Context, req *trillian.UndeleteTreeReq
 	if err != nil {
 		return nil, err
 	}
+	auditTree("undeleted", tree)
 	return tree, nil
 }
+
+// TriggerSequencing implements trillian.TrillianAdminServer.TriggerSequencing.
+func (s *Server) TriggerSequencing(ctx context.Context, req *trillian.TriggerSequencingRequest) (*trillian.Tree, error) {
+	var tree *trillian.Tree
+	err := s.registry.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		stored, err := tx.GetTree(ctx, req.GetTreeId())
+		if err != nil {
+			return err
+		}
+		switch {
+		case stored.TreeType != trillian.TreeType_LOG && stored.TreeType != trillian.TreeType_PREORDERED_LOG:
+			return status.Errorf(codes.FailedPrecondition, "tree %d is a %s, not a log", stored.TreeId, stored.TreeType)
+		case stored.TreeState != trillian.TreeState_ACTIVE && stored.TreeState != trillian.TreeState_DRAINING:
+			return status.Errorf(codes.FailedPrecondition, "tree %d is %s, want ACTIVE or DRAINING", stored.TreeId, stored.TreeState)
+		}
+		tree, err = tx.UpdateTree(ctx, stored.TreeId, func(t *trillian.Tree) {
+			t.SequencingRequestedTime = timestamppb.Now()
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	auditTree("triggered sequencing of", tree)
+	return tree, nil
+}
+
+// ListIntegrationEvents implements trillian.TrillianAdminServer.ListIntegrationEvents.
+func (s *Server) ListIntegrationEvents(ctx context.Context, req *trillian.ListIntegrationEventsRequest) (*trillian.ListIntegrationEventsResponse, error) {
+	if s.registry.LogStorage == nil {
+		return nil, status.Error(codes.Unimplemented, "no log storage configured")
+	}
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_size %d, want >= 0", pageSize)
+	case pageSize == 0:
+		pageSize = defaultIntegrationEventPageSize
+	case pageSize > maxIntegrationEventPageSize:
+		pageSize = maxIntegrationEventPageSize
+	}
+	var before time.Time
+	if req.Before != nil {
+		if err := req.Before.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid before: %v", err)
+		}
+		before = req.Before.AsTime()
+	}
+
+	tree, err := storage.GetTree(ctx, s.registry.AdminStorage, req.GetTreeId())
+	if err != nil {
+		return nil, err
+	}
+	switch tree.TreeType {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "tree %d is not a log", tree.TreeId)
+	}
+	tx, err := s.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	r, ok := tx.(storage.IntegrationEventReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	events, err := r.ListIntegrationEvents(ctx, before, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &trillian.ListIntegrationEventsResponse{Events: events}, nil
+}
+
+// auditTree logs a change to tree along with its owner and contact, so that
+// whoever operates the tree can be identified from the logs.
+func auditTree(action string, tree *trillian.Tree) {
+	klog.Infof("Admin audit: %s tree %d (%q), owner: %q, contact: %q", action, tree.GetTreeId(), tree.GetDisplayName(), tree.GetOwner(), tree.GetContact())
+}
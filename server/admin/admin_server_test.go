@@ -26,6 +26,8 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/envelope"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/storage/testonly"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
@@ -391,6 +393,93 @@ func TestServer_CreateTree_AllowedTreeTypes(t *testing.T) {
 	}
 }
 
+func TestServer_CreateTree_LeafEncryption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	const keyURI = "local:kek"
+	wrapper, err := envelope.NewLocalKeyWrapper(map[string][]byte{keyURI: make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("NewLocalKeyWrapper(): %v", err)
+	}
+	tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	tree.LeafEncryption = &trillian.LeafEncryption{KeyUri: keyURI}
+
+	setup := setupAdminServer(ctrl, false /* snapshot */, false /* shouldCommit */, false)
+	if _, err := setup.server.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: proto.Clone(tree).(*trillian.Tree)}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateTree() without key wrapper returned err = %v, want FailedPrecondition", err)
+	}
+
+	setup = setupAdminServer(ctrl, false /* snapshot */, true /* shouldCommit */, false)
+	setup.server.registry.LeafKeyWrapper = wrapper
+	setup.tx.EXPECT().CreateTree(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tree *trillian.Tree) (*trillian.Tree, error) { return tree, nil })
+	created, err := setup.server.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: proto.Clone(tree).(*trillian.Tree)})
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	enc := created.GetLeafEncryption()
+	if enc.GetKeyUri() != keyURI {
+		t.Errorf("CreateTree() key URI = %q, want %q", enc.GetKeyUri(), keyURI)
+	}
+	if _, err := wrapper.UnwrapKey(ctx, keyURI, enc.GetWrappedKey()); err != nil {
+		t.Errorf("UnwrapKey() of created tree's data key: %v", err)
+	}
+}
+
+func TestServer_CheckpointIdentifiersUnique(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	s := New(extension.Registry{AdminStorage: memory.NewAdminStorage(ts)}, nil)
+
+	newTree := func(origin string, ids ...string) *trillian.Tree {
+		tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+		tree.CheckpointOrigin = origin
+		tree.EcosystemIds = ids
+		return tree
+	}
+	if _, err := s.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: newTree("example.com/log1", "ct:a2V5MQ==")}); err != nil {
+		t.Fatalf("CreateTree(first): %v", err)
+	}
+	second, err := s.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: newTree("example.com/log2")})
+	if err != nil {
+		t.Fatalf("CreateTree(second): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc string
+		tree *trillian.Tree
+	}{
+		{desc: "origin", tree: newTree("example.com/log1")},
+		{desc: "ecosystemID", tree: newTree("example.com/log3", "example:1", "ct:a2V5MQ==")},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := s.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: test.tree}); status.Code(err) != codes.AlreadyExists {
+				t.Errorf("CreateTree() returned err = %v, want AlreadyExists", err)
+			}
+		})
+	}
+
+	// Updating a tree to identifiers of another fails, but updating it
+	// without changing its own doesn't.
+	mask := &field_mask.FieldMask{Paths: []string{"checkpoint_origin"}}
+	second.CheckpointOrigin = "example.com/log1"
+	if _, err := s.UpdateTree(ctx, &trillian.UpdateTreeRequest{Tree: second, UpdateMask: mask}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("UpdateTree(origin of first) returned err = %v, want AlreadyExists", err)
+	}
+	second.CheckpointOrigin = "example.com/log2"
+	second.EcosystemIds = []string{"ct:a2V5Mg=="}
+	mask.Paths = append(mask.Paths, "ecosystem_ids")
+	updated, err := s.UpdateTree(ctx, &trillian.UpdateTreeRequest{Tree: second, UpdateMask: mask})
+	if err != nil {
+		t.Fatalf("UpdateTree(own origin): %v", err)
+	}
+	if got, want := updated.EcosystemIds, second.EcosystemIds; !cmp.Equal(got, want) {
+		t.Errorf("UpdateTree() ecosystem_ids = %v, want %v", got, want)
+	}
+}
+
 func TestServer_UpdateTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -407,14 +496,24 @@ func TestServer_UpdateTree(t *testing.T) {
 
 	// successTree specifies changes in all rw fields
 	successTree := &trillian.Tree{
-		TreeState:       trillian.TreeState_FROZEN,
-		DisplayName:     "Brand New Tree Name",
-		Description:     "Brand New Tree Desc",
-		StorageSettings: settings,
-		MaxRootDuration: durationpb.New(2 * time.Nanosecond),
+		TreeState:            trillian.TreeState_FROZEN,
+		DisplayName:          "Brand New Tree Name",
+		Description:          "Brand New Tree Desc",
+		StorageSettings:      settings,
+		MaxRootDuration:      durationpb.New(2 * time.Nanosecond),
+		AutoFreeze:           true,
+		MaxTreeSize:          1000,
+		Owner:                "llama-team",
+		Contact:              "llamas@example.com",
+		MutableExtraData:     true,
+		AllowRedaction:       true,
+		MergeDelayTarget:     durationpb.New(24 * time.Hour),
+		DequeueOrder:         trillian.DequeueOrder_FIFO_DEQUEUE_ORDER,
+		DisabledMethods:      []string{"GetLeavesByRange"},
+		AllowPrehashedLeaves: true,
 	}
 	successMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration"},
+		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "auto_freeze", "max_tree_size", "owner", "contact", "mutable_extra_data", "allow_redaction", "merge_delay_target", "dequeue_order", "disabled_methods", "allow_prehashed_leaves"},
 	}
 
 	successWant := proto.Clone(existingTree).(*trillian.Tree)
@@ -423,6 +522,16 @@ func TestServer_UpdateTree(t *testing.T) {
 	successWant.Description = successTree.Description
 	successWant.StorageSettings = successTree.StorageSettings
 	successWant.MaxRootDuration = successTree.MaxRootDuration
+	successWant.AutoFreeze = successTree.AutoFreeze
+	successWant.MaxTreeSize = successTree.MaxTreeSize
+	successWant.Owner = successTree.Owner
+	successWant.Contact = successTree.Contact
+	successWant.MutableExtraData = successTree.MutableExtraData
+	successWant.AllowRedaction = successTree.AllowRedaction
+	successWant.MergeDelayTarget = successTree.MergeDelayTarget
+	successWant.DequeueOrder = successTree.DequeueOrder
+	successWant.DisabledMethods = successTree.DisabledMethods
+	successWant.AllowPrehashedLeaves = successTree.AllowPrehashedLeaves
 
 	tests := []struct {
 		desc                           string
@@ -673,6 +782,64 @@ func TestServer_UndeleteTreeErrors(t *testing.T) {
 	}
 }
 
+func TestServer_TriggerSequencing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	activeLog := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	drainingLog := proto.Clone(testonly.PreorderedLogTree).(*trillian.Tree)
+	drainingLog.TreeState = trillian.TreeState_DRAINING
+	frozenLog := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	frozenLog.TreeState = trillian.TreeState_FROZEN
+	for i, tree := range []*trillian.Tree{activeLog, drainingLog, frozenLog} {
+		tree.TreeId = int64(i) + 10
+	}
+
+	tests := []struct {
+		desc     string
+		tree     *trillian.Tree
+		getErr   error
+		wantCode codes.Code
+	}{
+		{desc: "activeLog", tree: activeLog},
+		{desc: "drainingPreorderedLog", tree: drainingLog},
+		{desc: "frozenLog", tree: frozenLog, wantCode: codes.FailedPrecondition},
+		{desc: "getErr", tree: activeLog, getErr: status.Error(codes.NotFound, "unknown tree"), wantCode: codes.NotFound},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(
+				ctrl,
+				false, /* snapshot */
+				test.wantCode == codes.OK,
+				false)
+			tree := proto.Clone(test.tree).(*trillian.Tree)
+
+			tx := setup.tx
+			tx.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, test.getErr)
+			if test.wantCode == codes.OK {
+				tx.EXPECT().UpdateTree(gomock.Any(), tree.TreeId, gomock.Any()).DoAndReturn(func(ctx context.Context, treeID int64, updateFn func(*trillian.Tree)) (*trillian.Tree, error) {
+					updateFn(tree)
+					return tree, nil
+				})
+			}
+
+			before := time.Now()
+			got, err := setup.server.TriggerSequencing(ctx, &trillian.TriggerSequencingRequest{TreeId: tree.TreeId})
+			if status.Code(err) != test.wantCode {
+				t.Fatalf("TriggerSequencing() returned err = %v, want code %v", err, test.wantCode)
+			} else if err != nil {
+				return
+			}
+			if requested := got.SequencingRequestedTime.AsTime(); requested.Before(before) {
+				t.Errorf("TriggerSequencing() set sequencing_requested_time = %v, want >= %v", requested, before)
+			}
+		})
+	}
+}
+
 // adminTestSetup contains an operational Server and required dependencies.
 // It's created via setupAdminServer.
 type adminTestSetup struct {
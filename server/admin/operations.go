@@ -0,0 +1,407 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/replication"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultOperationBatchSize = 1000
+	defaultOperationRetention = 24 * time.Hour
+)
+
+// OperationsConfig configures the long-running operations of a Server.
+type OperationsConfig struct {
+	// ExportDir is the directory which ExportTree writes to. ExportTree is
+	// unavailable if it is empty.
+	ExportDir string
+	// MigrationSource and MigrationTarget are the storages which MigrateTree
+	// copies logs from and to, as done by a replication.Replicator. Leaf data
+	// is copied as it is stored, so neither should be wrapped with a
+	// storage/envelope.LogStorage. MigrateTree is unavailable if either is
+	// nil.
+	MigrationSource, MigrationTarget *replication.Storage
+	// BatchSize is the maximum number of leaves read or copied in each
+	// transaction. Defaults to 1000.
+	BatchSize int
+	// Retention is how long finished operations are kept for. Defaults to
+	// 24 hours.
+	Retention time.Duration
+}
+
+// operations keeps track of the long-running operations started by a Server.
+// Operations run in the background until they finish or are cancelled, and
+// are only kept in memory.
+type operations struct {
+	retention time.Duration
+
+	mu  sync.Mutex
+	ops map[string]*operation
+}
+
+func newOperations(retention time.Duration) *operations {
+	if retention <= 0 {
+		retention = defaultOperationRetention
+	}
+	return &operations{retention: retention, ops: make(map[string]*operation)}
+}
+
+// operation is a running or finished long-running operation.
+type operation struct {
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	op *trillian.Operation
+}
+
+// progress records the units of work done by the operation so far.
+func (o *operation) progress(done, total int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.op.DoneUnits, o.op.TotalUnits = done, total
+	o.op.UpdateTime = timestamppb.New(timeNow())
+}
+
+// finish marks the operation as done, with the given result or error.
+func (o *operation) finish(result string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.op.Done = true
+	o.op.UpdateTime = timestamppb.New(timeNow())
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = status.Error(codes.Canceled, "operation cancelled")
+		}
+		o.op.Error = status.Convert(err).Proto()
+	} else {
+		o.op.Result = result
+	}
+}
+
+// snapshot returns a copy of the current state of the operation.
+func (o *operation) snapshot() *trillian.Operation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return proto.Clone(o.op).(*trillian.Operation)
+}
+
+// start runs fn in the background as a new operation of the given kind, and
+// returns its initial state. The context passed to fn is cancelled if the
+// operation is.
+func (ops *operations) start(treeID int64, kind trillian.Operation_Kind, fn func(ctx context.Context, o *operation) (string, error)) *trillian.Operation {
+	now := timestamppb.New(timeNow())
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &operation{
+		cancel: cancel,
+		op: &trillian.Operation{
+			Name:       fmt.Sprintf("operations/%d", rand.Int63()),
+			TreeId:     treeID,
+			Kind:       kind,
+			CreateTime: now,
+			UpdateTime: now,
+		},
+	}
+	ops.mu.Lock()
+	ops.prune()
+	ops.ops[o.op.Name] = o
+	ops.mu.Unlock()
+
+	klog.Infof("Admin operation %s: started %s of tree %d", o.op.Name, kind, treeID)
+	go func() {
+		defer cancel()
+		result, err := fn(ctx, o)
+		o.finish(result, err)
+		if err != nil {
+			klog.Warningf("Admin operation %s: %s of tree %d failed: %v", o.op.Name, kind, treeID, err)
+		} else {
+			klog.Infof("Admin operation %s: %s of tree %d done", o.op.Name, kind, treeID)
+		}
+	}()
+	return o.snapshot()
+}
+
+// get returns the named operation.
+func (ops *operations) get(name string) (*operation, error) {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	o, ok := ops.ops[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", name)
+	}
+	return o, nil
+}
+
+// list returns the operations on the given tree, or on all trees if treeID
+// is 0, most recently started first.
+func (ops *operations) list(treeID int64) []*trillian.Operation {
+	ops.mu.Lock()
+	ops.prune()
+	all := make([]*operation, 0, len(ops.ops))
+	for _, o := range ops.ops {
+		all = append(all, o)
+	}
+	ops.mu.Unlock()
+
+	ret := make([]*trillian.Operation, 0, len(all))
+	for _, o := range all {
+		if op := o.snapshot(); treeID == 0 || op.TreeId == treeID {
+			ret = append(ret, op)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].CreateTime.AsTime().After(ret[j].CreateTime.AsTime())
+	})
+	return ret
+}
+
+// prune forgets the operations which finished more than the retention period
+// ago. ops.mu must be held.
+func (ops *operations) prune() {
+	cutoff := timeNow().Add(-ops.retention)
+	for name, o := range ops.ops {
+		if op := o.snapshot(); op.Done && op.UpdateTime.AsTime().Before(cutoff) {
+			delete(ops.ops, name)
+		}
+	}
+}
+
+// HardDeleteTree implements trillian.TrillianAdminServer.HardDeleteTree.
+func (s *Server) HardDeleteTree(ctx context.Context, req *trillian.HardDeleteTreeRequest) (*trillian.Operation, error) {
+	tree, err := storage.GetTree(ctx, s.registry.AdminStorage, req.GetTreeId())
+	if err != nil {
+		return nil, err
+	}
+	if !tree.Deleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d is not soft-deleted", tree.TreeId)
+	}
+	auditTree("started hard deletion of", tree)
+	return s.ops.start(tree.TreeId, trillian.Operation_HARD_DELETE, func(ctx context.Context, o *operation) (string, error) {
+		o.progress(0, 1)
+		if err := storage.HardDeleteTree(ctx, s.registry.AdminStorage, tree.TreeId); err != nil {
+			return "", err
+		}
+		o.progress(1, 1)
+		return "", nil
+	}), nil
+}
+
+// ExportTree implements trillian.TrillianAdminServer.ExportTree.
+func (s *Server) ExportTree(ctx context.Context, req *trillian.ExportTreeRequest) (*trillian.Operation, error) {
+	if s.opsCfg.ExportDir == "" {
+		return nil, status.Error(codes.FailedPrecondition, "no export directory configured")
+	}
+	if s.registry.LogStorage == nil {
+		return nil, status.Error(codes.Unimplemented, "no log storage configured")
+	}
+	tree, err := s.getLog(ctx, req.GetTreeId())
+	if err != nil {
+		return nil, err
+	}
+	auditTree("started export of", tree)
+	return s.ops.start(tree.TreeId, trillian.Operation_EXPORT, func(ctx context.Context, o *operation) (string, error) {
+		return s.export(ctx, o, tree)
+	}), nil
+}
+
+// export writes the leaves of tree, up to its latest root, to a file in the
+// export directory, as one JSON-encoded LogLeaf per line. The file is only
+// given its final name once it is complete, and is returned.
+func (s *Server) export(ctx context.Context, o *operation, tree *trillian.Tree) (string, error) {
+	size, err := logSize(ctx, s.registry.LogStorage, tree)
+	if err != nil {
+		return "", err
+	}
+	o.progress(0, int64(size))
+
+	path := filepath.Join(s.opsCfg.ExportDir, fmt.Sprintf("%d-%s.jsonl", tree.TreeId, filepath.Base(o.op.Name)))
+	f, err := os.CreateTemp(s.opsCfg.ExportDir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	w := bufio.NewWriter(f)
+	for start := uint64(0); start < size; {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		count := min(size-start, uint64(s.opsCfg.BatchSize))
+		leaves, err := readLeaves(ctx, s.registry.LogStorage, tree, start, count)
+		if err != nil {
+			return "", fmt.Errorf("failed to read leaves from %d: %v", start, err)
+		}
+		if len(leaves) == 0 {
+			return "", fmt.Errorf("no leaves returned from %d", start)
+		}
+		for _, leaf := range leaves {
+			b, err := protojson.Marshal(leaf)
+			if err != nil {
+				return "", err
+			}
+			if _, err := w.Write(append(b, '\n')); err != nil {
+				return "", err
+			}
+		}
+		start += uint64(len(leaves))
+		o.progress(int64(start), int64(size))
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+	f = nil
+	return path, nil
+}
+
+// MigrateTree implements trillian.TrillianAdminServer.MigrateTree.
+func (s *Server) MigrateTree(ctx context.Context, req *trillian.MigrateTreeRequest) (*trillian.Operation, error) {
+	src, dst := s.opsCfg.MigrationSource, s.opsCfg.MigrationTarget
+	if src == nil || dst == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no migration storage configured")
+	}
+	tree, err := s.getLog(ctx, req.GetTreeId())
+	if err != nil {
+		return nil, err
+	}
+	auditTree("started migration of", tree)
+	return s.ops.start(tree.TreeId, trillian.Operation_MIGRATE, func(ctx context.Context, o *operation) (string, error) {
+		size, err := logSize(ctx, src.Log, tree)
+		if err != nil {
+			return "", err
+		}
+		o.progress(0, int64(size))
+		r := replication.NewReplicator(*src, *dst, replication.Config{BatchSize: s.opsCfg.BatchSize}, s.registry.MetricFactory, clock.System)
+		var copied int64
+		for {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			n, err := r.ReplicateLog(ctx, tree)
+			if err != nil {
+				return "", err
+			}
+			// The log may have grown since the migration started.
+			copied += int64(n)
+			o.progress(min(copied, int64(size)), int64(size))
+			if n < s.opsCfg.BatchSize {
+				break
+			}
+		}
+		o.progress(int64(size), int64(size))
+		return "", nil
+	}), nil
+}
+
+// getLog returns the given tree, if it is a log.
+func (s *Server) getLog(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tree, err := storage.GetTree(ctx, s.registry.AdminStorage, treeID)
+	if err != nil {
+		return nil, err
+	}
+	if tree.TreeType != trillian.TreeType_LOG && tree.TreeType != trillian.TreeType_PREORDERED_LOG {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d is a %s, not a log", tree.TreeId, tree.TreeType)
+	}
+	return tree, nil
+}
+
+// logSize returns the size of the latest root of tree in ls.
+func logSize(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree) (uint64, error) {
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latest root: %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return 0, fmt.Errorf("failed to read latest root: %v", err)
+	}
+	return root.TreeSize, tx.Commit(ctx)
+}
+
+// readLeaves returns up to count leaves of tree in ls, starting at index
+// start.
+func readLeaves(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree, start, count uint64) ([]*trillian.LogLeaf, error) {
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	leaves, err := tx.GetLeavesByRange(ctx, int64(start), int64(count))
+	if err != nil {
+		return nil, err
+	}
+	return leaves, tx.Commit(ctx)
+}
+
+// GetOperation implements trillian.TrillianAdminServer.GetOperation.
+func (s *Server) GetOperation(ctx context.Context, req *trillian.GetOperationRequest) (*trillian.Operation, error) {
+	o, err := s.ops.get(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return o.snapshot(), nil
+}
+
+// ListOperations implements trillian.TrillianAdminServer.ListOperations.
+func (s *Server) ListOperations(ctx context.Context, req *trillian.ListOperationsRequest) (*trillian.ListOperationsResponse, error) {
+	return &trillian.ListOperationsResponse{Operations: s.ops.list(req.GetTreeId())}, nil
+}
+
+// CancelOperation implements trillian.TrillianAdminServer.CancelOperation.
+func (s *Server) CancelOperation(ctx context.Context, req *trillian.CancelOperationRequest) (*emptypb.Empty, error) {
+	o, err := s.ops.get(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("Admin operation %s: cancellation requested", req.GetName())
+	o.cancel()
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/replication"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newMemoryLog creates an initialised log with leafCount integrated leaves in
+// a new memory storage.
+func newMemoryLog(ctx context.Context, t *testing.T, leafCount int) (extension.Registry, *trillian.Tree) {
+	t.Helper()
+	log.InitMetrics(nil)
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+		QuotaManager: quota.Noop(),
+	}
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	logServer := server.NewTrillianLogRPCServer(registry, clock.System)
+	if _, err := logServer.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+	for i := 0; i < leafCount; i++ {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d", i))}
+		if _, err := logServer.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf}); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+	}
+	if _, err := log.IntegrateBatch(ctx, tree, leafCount, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	return registry, tree
+}
+
+// waitForOperation polls the named operation until it is done.
+func waitForOperation(ctx context.Context, t *testing.T, s *Server, name string) *trillian.Operation {
+	t.Helper()
+	for {
+		op, err := s.GetOperation(ctx, &trillian.GetOperationRequest{Name: name})
+		if err != nil {
+			t.Fatalf("GetOperation(%q): %v", name, err)
+		}
+		if op.Done {
+			return op
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_HardDeleteTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	activeTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	activeTree.TreeId = 10
+	deletedTree := proto.Clone(activeTree).(*trillian.Tree)
+	deletedTree.Deleted = true
+	deletedTree.DeleteTime = timestamppb.Now()
+
+	for _, test := range []struct {
+		desc      string
+		tree      *trillian.Tree
+		deleteErr error
+		wantCode  codes.Code
+		wantErr   codes.Code
+	}{
+		{desc: "deleted", tree: deletedTree},
+		{desc: "active", tree: activeTree, wantCode: codes.FailedPrecondition},
+		{desc: "deleteErr", tree: deletedTree, deleteErr: status.Error(codes.Internal, "oops"), wantErr: codes.Internal},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, true /* snapshot */, true /* shouldCommit */, false)
+			setup.snapshotTX.EXPECT().GetTree(gomock.Any(), test.tree.TreeId).Return(test.tree, nil)
+			s := NewWithOperations(setup.registry, nil, OperationsConfig{})
+			if test.wantCode == codes.OK {
+				tx := storage.NewMockAdminTX(ctrl)
+				tx.EXPECT().HardDeleteTree(gomock.Any(), test.tree.TreeId).Return(test.deleteErr)
+				if test.deleteErr == nil {
+					tx.EXPECT().Commit().Return(nil)
+				}
+				tx.EXPECT().Close().MaxTimes(1).Return(nil)
+				setup.as.(*testonly.FakeAdminStorage).TX = []storage.AdminTX{tx}
+			}
+
+			op, err := s.HardDeleteTree(ctx, &trillian.HardDeleteTreeRequest{TreeId: test.tree.TreeId})
+			if status.Code(err) != test.wantCode {
+				t.Fatalf("HardDeleteTree() returned err = %v, want code %v", err, test.wantCode)
+			} else if err != nil {
+				return
+			}
+			if op.Kind != trillian.Operation_HARD_DELETE || op.TreeId != test.tree.TreeId {
+				t.Errorf("HardDeleteTree() returned %v, want HARD_DELETE of tree %d", op, test.tree.TreeId)
+			}
+			op = waitForOperation(ctx, t, s, op.Name)
+			if got := codes.Code(op.GetError().GetCode()); got != test.wantErr {
+				t.Errorf("operation error = %v, want code %v", op.GetError(), test.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_ExportTree(t *testing.T) {
+	ctx := context.Background()
+	const leafCount = 10
+	registry, tree := newMemoryLog(ctx, t, leafCount)
+
+	if _, err := New(registry, nil).ExportTree(ctx, &trillian.ExportTreeRequest{TreeId: tree.TreeId}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ExportTree() without an export directory returned err = %v, want code %v", err, codes.FailedPrecondition)
+	}
+
+	dir := t.TempDir()
+	s := NewWithOperations(registry, nil, OperationsConfig{ExportDir: dir, BatchSize: 3})
+	op, err := s.ExportTree(ctx, &trillian.ExportTreeRequest{TreeId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("ExportTree(): %v", err)
+	}
+	op = waitForOperation(ctx, t, s, op.Name)
+	if op.Error != nil {
+		t.Fatalf("export failed: %v", op.Error)
+	}
+	if op.DoneUnits != leafCount || op.TotalUnits != leafCount {
+		t.Errorf("export progress = %d/%d, want %d/%d", op.DoneUnits, op.TotalUnits, leafCount, leafCount)
+	}
+
+	f, err := os.Open(op.Result)
+	if err != nil {
+		t.Fatalf("Failed to open export: %v", err)
+	}
+	defer f.Close()
+	var got []string
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var leaf trillian.LogLeaf
+		if err := protojson.Unmarshal(sc.Bytes(), &leaf); err != nil {
+			t.Fatalf("Failed to parse exported leaf: %v", err)
+		}
+		if want := int64(len(got)); leaf.LeafIndex != want {
+			t.Errorf("exported leaf index %d, want %d", leaf.LeafIndex, want)
+		}
+		got = append(got, string(leaf.LeafValue))
+	}
+	if len(got) != leafCount {
+		t.Errorf("exported %d leaves, want %d", len(got), leafCount)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("export directory has %v (err = %v), want only the export", entries, err)
+	}
+}
+
+func TestServer_MigrateTree(t *testing.T) {
+	ctx := context.Background()
+	const leafCount = 10
+	registry, tree := newMemoryLog(ctx, t, leafCount)
+	target := memory.NewTreeStorage()
+	cfg := OperationsConfig{
+		MigrationSource: &replication.Storage{Admin: registry.AdminStorage, Log: registry.LogStorage},
+		MigrationTarget: &replication.Storage{Admin: memory.NewAdminStorage(target), Log: memory.NewLogStorage(target, nil)},
+		BatchSize:       4,
+	}
+	s := NewWithOperations(registry, nil, cfg)
+
+	op, err := s.MigrateTree(ctx, &trillian.MigrateTreeRequest{TreeId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("MigrateTree(): %v", err)
+	}
+	op = waitForOperation(ctx, t, s, op.Name)
+	if op.Error != nil {
+		t.Fatalf("migration failed: %v", op.Error)
+	}
+	if op.DoneUnits != leafCount || op.TotalUnits != leafCount {
+		t.Errorf("migration progress = %d/%d, want %d/%d", op.DoneUnits, op.TotalUnits, leafCount, leafCount)
+	}
+
+	src := server.NewTrillianLogRPCServer(registry, clock.System)
+	dst := server.NewTrillianLogRPCServer(extension.Registry{
+		AdminStorage: cfg.MigrationTarget.Admin,
+		LogStorage:   cfg.MigrationTarget.Log,
+		QuotaManager: quota.Noop(),
+	}, clock.System)
+	req := &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId}
+	want, err := src.GetLatestSignedLogRoot(ctx, req)
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(source): %v", err)
+	}
+	got, err := dst.GetLatestSignedLogRoot(ctx, req)
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(target): %v", err)
+	}
+	if diff := cmp.Diff(want.SignedLogRoot, got.SignedLogRoot, protocmp.Transform()); diff != "" {
+		t.Errorf("target root diff (-source +target):\n%s", diff)
+	}
+}
+
+func TestServer_Operations(t *testing.T) {
+	ctx := context.Background()
+	s := New(extension.Registry{}, nil)
+
+	started := make(chan struct{})
+	blocked := s.ops.start(1, trillian.Operation_EXPORT, func(ctx context.Context, o *operation) (string, error) {
+		o.progress(1, 2)
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	finished := s.ops.start(2, trillian.Operation_MIGRATE, func(ctx context.Context, o *operation) (string, error) {
+		return "ok", nil
+	})
+	<-started
+
+	if _, err := s.GetOperation(ctx, &trillian.GetOperationRequest{Name: "operations/0"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetOperation(unknown) returned err = %v, want code %v", err, codes.NotFound)
+	}
+	op, err := s.GetOperation(ctx, &trillian.GetOperationRequest{Name: blocked.Name})
+	if err != nil {
+		t.Fatalf("GetOperation(): %v", err)
+	}
+	if op.Done || op.DoneUnits != 1 || op.TotalUnits != 2 {
+		t.Errorf("GetOperation() = %v, want running with progress 1/2", op)
+	}
+
+	rsp, err := s.ListOperations(ctx, &trillian.ListOperationsRequest{TreeId: 2})
+	if err != nil {
+		t.Fatalf("ListOperations(): %v", err)
+	}
+	if len(rsp.Operations) != 1 || rsp.Operations[0].Name != finished.Name {
+		t.Errorf("ListOperations(tree 2) = %v, want only %s", rsp.Operations, finished.Name)
+	}
+	if rsp, err := s.ListOperations(ctx, &trillian.ListOperationsRequest{}); err != nil || len(rsp.Operations) != 2 {
+		t.Errorf("ListOperations() = %v, %v, want 2 operations", rsp, err)
+	}
+
+	if _, err := s.CancelOperation(ctx, &trillian.CancelOperationRequest{Name: blocked.Name}); err != nil {
+		t.Fatalf("CancelOperation(): %v", err)
+	}
+	op = waitForOperation(ctx, t, s, blocked.Name)
+	if got := codes.Code(op.GetError().GetCode()); got != codes.Canceled {
+		t.Errorf("cancelled operation error = %v, want code %v", op.GetError(), codes.Canceled)
+	}
+	if op := waitForOperation(ctx, t, s, finished.Name); op.Result != "ok" || op.Error != nil {
+		t.Errorf("finished operation = %v, want result %q", op, "ok")
+	}
+
+	// Finished operations are forgotten after the retention period.
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Now().Add(defaultOperationRetention + time.Minute) }
+	if rsp, err := s.ListOperations(ctx, &trillian.ListOperationsRequest{}); err != nil || len(rsp.Operations) != 0 {
+		t.Errorf("ListOperations() after retention = %v, %v, want none", rsp, err)
+	}
+}
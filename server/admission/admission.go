@@ -0,0 +1,190 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission provides a gRPC interceptor which sheds low-priority
+// requests while the server is overloaded, so that writes and sequencing stay
+// healthy when many clients read at once.
+package admission
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultLowPriorityMethods are the log read RPCs, which monitors and
+// auditors call in bulk.
+var DefaultLowPriorityMethods = []string{
+	"GetConsistencyProof",
+	"GetConsistencyProofByRootHash",
+	"GetEntryAndProof",
+	"GetInclusionProof",
+	"GetInclusionProofByHash",
+	"GetInclusionProofByIdentityHash",
+	"GetLatestSignedLogRoot",
+	"GetLeavesByIndices",
+	"GetLeavesByRange",
+	"GetRangeInclusionProof",
+	"GetSequencedLeafCount",
+	"GetTreeStats",
+	"ListSignedLogRoots",
+	"WatchSignedLogRoots",
+}
+
+// Config holds the parameters for a Controller. Zero thresholds are disabled.
+type Config struct {
+	// LowPriorityMethods are the names of the RPCs which may be shed, without
+	// their service, e.g. "GetLeavesByRange".
+	LowPriorityMethods []string
+	// MaxInFlight is the number of requests, of any priority, being handled
+	// above which low-priority requests are queued.
+	MaxInFlight int
+	// MaxQueueDepth is the number of low-priority requests which may wait for
+	// the number in flight to drop. Further low-priority requests are shed.
+	MaxQueueDepth int
+	// Overloaded, if set, sheds all low-priority requests while it returns
+	// true. See load.Monitor.
+	Overloaded func() bool
+}
+
+// Controller is a gRPC interceptor which admits or sheds requests.
+type Controller struct {
+	cfg         Config
+	lowPriority map[string]bool
+
+	mu       sync.Mutex
+	inFlight int
+	// waiters are the channels of queued low-priority requests, in arrival
+	// order. A waiter's channel is closed once it has been given a slot.
+	waiters []chan struct{}
+
+	inFlightGauge monitoring.Gauge
+	queueGauge    monitoring.Gauge
+	shed          monitoring.Counter
+}
+
+// New returns a Controller for cfg.
+func New(cfg Config, mf monitoring.MetricFactory) *Controller {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	c := &Controller{
+		cfg:           cfg,
+		lowPriority:   make(map[string]bool),
+		inFlightGauge: mf.NewGauge("admission_in_flight", "Number of requests being handled"),
+		queueGauge:    mf.NewGauge("admission_queue_depth", "Number of low-priority requests waiting to be admitted"),
+		shed:          mf.NewCounter("admission_shed", "Number of low-priority requests shed, by method and reason", "method", "reason"),
+	}
+	for _, m := range cfg.LowPriorityMethods {
+		c.lowPriority[m] = true
+	}
+	return c
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (c *Controller) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := c.admit(ctx, methodName(info.FullMethod)); err != nil {
+		return nil, err
+	}
+	defer c.release()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor implements grpc.StreamServerInterceptor. Streams are
+// admitted or shed when they open, like unary requests, but don't hold their
+// slot while they stay open, as a long-lived stream would otherwise starve
+// the requests queued behind it.
+func (c *Controller) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.admit(ss.Context(), methodName(info.FullMethod)); err != nil {
+		return err
+	}
+	c.release()
+	return handler(srv, ss)
+}
+
+// admit returns nil once the request may run, after which release must be
+// called.
+func (c *Controller) admit(ctx context.Context, method string) error {
+	low := c.lowPriority[method]
+	if low && c.cfg.Overloaded != nil && c.cfg.Overloaded() {
+		return c.reject(method, "load")
+	}
+
+	c.mu.Lock()
+	if !low || c.cfg.MaxInFlight <= 0 || (c.inFlight < c.cfg.MaxInFlight && len(c.waiters) == 0) {
+		c.inFlight++
+		c.inFlightGauge.Set(float64(c.inFlight))
+		c.mu.Unlock()
+		return nil
+	}
+	if len(c.waiters) >= c.cfg.MaxQueueDepth {
+		c.mu.Unlock()
+		return c.reject(method, "queue")
+	}
+	ready := make(chan struct{})
+	c.waiters = append(c.waiters, ready)
+	c.queueGauge.Set(float64(len(c.waiters)))
+	c.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w == ready {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.queueGauge.Set(float64(len(c.waiters)))
+			c.shed.Inc(method, "deadline")
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	// The slot was handed over just as the context was done; the request
+	// still owns it.
+	return nil
+}
+
+// release frees the slot of a finished request, and hands it to the oldest
+// queued request if there is room.
+func (c *Controller) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+	for len(c.waiters) > 0 && c.inFlight < c.cfg.MaxInFlight {
+		close(c.waiters[0])
+		c.waiters = c.waiters[1:]
+		c.inFlight++
+	}
+	c.inFlightGauge.Set(float64(c.inFlight))
+	c.queueGauge.Set(float64(len(c.waiters)))
+}
+
+func (c *Controller) reject(method, reason string) error {
+	c.shed.Inc(method, reason)
+	return status.Errorf(codes.Unavailable, "server overloaded, %s request shed", method)
+}
+
+// methodName returns the name of the RPC without its service, e.g.
+// "GetLeavesByRange" for "/trillian.TrillianLog/GetLeavesByRange".
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	readMethod  = "/trillian.TrillianLog/GetLeavesByRange"
+	writeMethod = "/trillian.TrillianLog/QueueLeaf"
+)
+
+// call runs a request for method through c, returning a channel which
+// receives its result, and a function which finishes the handler.
+func call(ctx context.Context, c *Controller, method string) (<-chan error, func()) {
+	finish := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		_, err := c.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+			<-finish
+			return nil, nil
+		})
+		result <- err
+	}()
+	return result, func() { close(finish) }
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
+
+func (c *Controller) counts() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inFlight, len(c.waiters)
+}
+
+func TestControllerQueue(t *testing.T) {
+	ctx := context.Background()
+	c := New(Config{LowPriorityMethods: DefaultLowPriorityMethods, MaxInFlight: 1, MaxQueueDepth: 1}, nil)
+
+	_, finishWrite := call(ctx, c, writeMethod)
+	waitFor(t, func() bool { n, _ := c.counts(); return n == 1 })
+
+	// Writes are admitted regardless of the number in flight.
+	res, finish := call(ctx, c, writeMethod)
+	finish()
+	if err := <-res; err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	queued, finishQueued := call(ctx, c, readMethod)
+	waitFor(t, func() bool { _, q := c.counts(); return q == 1 })
+
+	shed, _ := call(ctx, c, readMethod)
+	if err := <-shed; status.Code(err) != codes.Unavailable {
+		t.Fatalf("read with full queue = %v, want Unavailable", err)
+	}
+
+	// Finishing the first write admits the queued read.
+	finishWrite()
+	waitFor(t, func() bool { n, q := c.counts(); return n == 1 && q == 0 })
+	finishQueued()
+	if err := <-queued; err != nil {
+		t.Fatalf("queued read: %v", err)
+	}
+	if n, q := c.counts(); n != 0 || q != 0 {
+		t.Errorf("counts() = %d, %d; want 0, 0", n, q)
+	}
+}
+
+func TestControllerQueueDeadline(t *testing.T) {
+	c := New(Config{LowPriorityMethods: DefaultLowPriorityMethods, MaxInFlight: 1, MaxQueueDepth: 1}, nil)
+	_, finish := call(context.Background(), c, readMethod)
+	defer finish()
+	waitFor(t, func() bool { n, _ := c.counts(); return n == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, _ := call(ctx, c, readMethod)
+	if err := <-res; status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("queued read = %v, want DeadlineExceeded", err)
+	}
+	if _, q := c.counts(); q != 0 {
+		t.Errorf("queue depth = %d, want 0", q)
+	}
+}
+
+func TestControllerOverloaded(t *testing.T) {
+	ctx := context.Background()
+	overloaded := true
+	c := New(Config{LowPriorityMethods: DefaultLowPriorityMethods, Overloaded: func() bool { return overloaded }}, nil)
+
+	res, _ := call(ctx, c, readMethod)
+	if err := <-res; status.Code(err) != codes.Unavailable {
+		t.Errorf("read while overloaded = %v, want Unavailable", err)
+	}
+	res, finish := call(ctx, c, writeMethod)
+	finish()
+	if err := <-res; err != nil {
+		t.Errorf("write while overloaded = %v", err)
+	}
+	overloaded = false
+	res, finish = call(ctx, c, readMethod)
+	finish()
+	if err := <-res; err != nil {
+		t.Errorf("read = %v", err)
+	}
+}
+
+// fakeServerStream is a grpc.ServerStream with a context.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func TestControllerStreams(t *testing.T) {
+	overloaded := true
+	c := New(Config{LowPriorityMethods: DefaultLowPriorityMethods, MaxInFlight: 1, MaxQueueDepth: 1, Overloaded: func() bool { return overloaded }}, nil)
+	info := &grpc.StreamServerInfo{FullMethod: "/trillian.TrillianLog/WatchSignedLogRoots", IsServerStream: true}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	opened := false
+	if err := c.StreamInterceptor(nil, ss, info, func(interface{}, grpc.ServerStream) error { opened = true; return nil }); status.Code(err) != codes.Unavailable || opened {
+		t.Fatalf("stream while overloaded = %v, opened %v; want Unavailable, false", err, opened)
+	}
+
+	// An open stream doesn't hold a slot, so reads aren't queued behind it.
+	overloaded = false
+	if err := c.StreamInterceptor(nil, ss, info, func(interface{}, grpc.ServerStream) error {
+		if n, q := c.counts(); n != 0 || q != 0 {
+			t.Errorf("counts() in stream = %d, %d; want 0, 0", n, q)
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("stream = %v", err)
+	}
+}
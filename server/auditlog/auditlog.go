@@ -0,0 +1,304 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auditlog provides a gRPC interceptor which writes a structured
+// summary of each request to a Sink, such as a file, a fluentd HTTP input or
+// a Pub/Sub topic, for operators who must retain access logs.
+package auditlog
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// maxBatch is the maximum number of records written to a Sink at once.
+const maxBatch = 100
+
+// Record summarizes a request.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	TreeID int64     `json:"tree_id,omitempty"`
+	// Peer is the network address of the caller, and Identity the subject
+	// of its TLS client certificate, if it presented one.
+	Peer     string `json:"peer,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	// LatencyMillis is the time taken to handle the request.
+	LatencyMillis float64 `json:"latency_ms"`
+	// Code is the canonical status code of the response, and Error its
+	// message if the request failed.
+	Code  string `json:"code"`
+	Error string `json:"error,omitempty"`
+	// LeafHashes holds the hex encoded leaf identity or Merkle leaf hashes
+	// which the request names.
+	LeafHashes []string `json:"leaf_hashes,omitempty"`
+	// Stream is "open" or "close" for the records of streaming requests,
+	// which are written when the stream receives its request and when it
+	// ends. The latency of the close record is the lifetime of the stream.
+	Stream string `json:"stream,omitempty"`
+}
+
+// Sink stores records.
+type Sink interface {
+	// Write stores the given records, in order.
+	Write(ctx context.Context, records []*Record) error
+	// Close flushes and releases the resources of the sink.
+	Close() error
+}
+
+// Config holds the parameters of a Logger.
+type Config struct {
+	// Sink is where records are written.
+	Sink Sink
+	// SampleRate and ErrorSampleRate are the fractions of successful and
+	// failed requests which are recorded.
+	SampleRate, ErrorSampleRate float64
+	// QueueSize is the number of records which may wait to be written to
+	// the sink, beyond which requests wait for space. Defaults to 1000.
+	QueueSize int
+}
+
+// Logger is a gRPC interceptor which records a sample of requests in a Sink.
+// Records are written to the sink in the background, in batches. Requests
+// wait for their records to be queued, so that none is lost while the sink
+// is slow, unless their contexts end first.
+type Logger struct {
+	cfg     Config
+	records chan *Record
+	done    chan struct{}
+	close   sync.Once
+
+	written, dropped, failed monitoring.Counter
+}
+
+// New returns a Logger for cfg, which must be closed to flush its records.
+func New(cfg Config, mf monitoring.MetricFactory) *Logger {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	l := &Logger{
+		cfg:     cfg,
+		records: make(chan *Record, cfg.QueueSize),
+		done:    make(chan struct{}),
+		written: mf.NewCounter("audit_log_records_written", "Number of audit log records written to the sink"),
+		dropped: mf.NewCounter("audit_log_records_dropped", "Number of audit log records dropped because their request ended while the queue was full"),
+		failed:  mf.NewCounter("audit_log_records_failed", "Number of audit log records which the sink failed to write"),
+	}
+	go l.run()
+	return l
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (l *Logger) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	rate := l.cfg.SampleRate
+	if err != nil {
+		rate = l.cfg.ErrorSampleRate
+	}
+	if !sample(rate) {
+		return resp, err
+	}
+
+	r := newRecord(ctx, info.FullMethod, req, resp, err)
+	r.Time = start
+	r.LatencyMillis = float64(time.Since(start)) / float64(time.Millisecond)
+	l.write(ctx, r)
+	return resp, err
+}
+
+// StreamInterceptor implements grpc.StreamServerInterceptor. A sampled
+// stream is recorded when it receives its first request, and again when it
+// ends. Streams which fail are sampled at ErrorSampleRate when they end.
+func (l *Logger) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	s := &loggedStream{ServerStream: ss, l: l, method: info.FullMethod, start: start, sampled: sample(l.cfg.SampleRate)}
+	err := handler(srv, s)
+	if !s.sampled && (err == nil || !sample(l.cfg.ErrorSampleRate)) {
+		return err
+	}
+
+	r := newRecord(ss.Context(), info.FullMethod, s.req, nil, err)
+	r.Time = start
+	r.LatencyMillis = float64(time.Since(start)) / float64(time.Millisecond)
+	r.Stream = "close"
+	l.write(ss.Context(), r)
+	return err
+}
+
+// loggedStream is a grpc.ServerStream which records the first request it
+// receives, if it is sampled.
+type loggedStream struct {
+	grpc.ServerStream
+	l       *Logger
+	method  string
+	start   time.Time
+	sampled bool
+	req     interface{}
+}
+
+// RecvMsg implements grpc.ServerStream.
+func (s *loggedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.req != nil {
+		return nil
+	}
+	s.req = m
+	if s.sampled {
+		r := newRecord(s.Context(), s.method, m, nil, nil)
+		r.Time = s.start
+		r.Stream = "open"
+		s.l.write(s.Context(), r)
+	}
+	return nil
+}
+
+// sample returns whether a request is recorded at the given rate.
+func sample(rate float64) bool {
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// write queues r to be written to the sink, waiting for space in the queue
+// unless ctx ends first.
+func (l *Logger) write(ctx context.Context, r *Record) {
+	// A stream's context may already be done when it ends, but its record
+	// is still queued if there is space.
+	select {
+	case l.records <- r:
+		return
+	default:
+	}
+	select {
+	case l.records <- r:
+	case <-ctx.Done():
+		l.dropped.Inc()
+	}
+}
+
+// Close writes the queued records, and closes the sink. Requests must not be
+// intercepted once Close is called.
+func (l *Logger) Close() error {
+	l.close.Do(func() { close(l.records) })
+	<-l.done
+	return l.cfg.Sink.Close()
+}
+
+// run writes the queued records to the sink until the queue is closed.
+func (l *Logger) run() {
+	defer close(l.done)
+	batch := make([]*Record, 0, maxBatch)
+	for r := range l.records {
+		batch = append(batch[:0], r)
+	fill:
+		for len(batch) < maxBatch {
+			select {
+			case r, ok := <-l.records:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, r)
+			default:
+				break fill
+			}
+		}
+		if err := l.cfg.Sink.Write(context.Background(), batch); err != nil {
+			klog.Errorf("Failed to write %d audit log records: %v", len(batch), err)
+			l.failed.Add(float64(len(batch)))
+			continue
+		}
+		l.written.Add(float64(len(batch)))
+	}
+}
+
+// newRecord returns the record of a request for method which returned resp
+// and err.
+func newRecord(ctx context.Context, method string, req, resp interface{}, err error) *Record {
+	s := status.Convert(err)
+	r := &Record{
+		Method:     method,
+		TreeID:     treeID(req),
+		Code:       s.Code().String(),
+		LeafHashes: leafHashes(req, resp),
+	}
+	if err != nil {
+		r.Error = s.Message()
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			r.Peer = p.Addr.String()
+		}
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			r.Identity = tlsInfo.State.PeerCertificates[0].Subject.String()
+		}
+	}
+	return r
+}
+
+// treeID returns the ID of the tree addressed by req, or zero.
+func treeID(req interface{}) int64 {
+	switch req := req.(type) {
+	case interface{ GetLogId() int64 }:
+		return req.GetLogId()
+	case interface{ GetTreeId() int64 }:
+		return req.GetTreeId()
+	case interface{ GetTree() *trillian.Tree }:
+		return req.GetTree().GetTreeId()
+	}
+	return 0
+}
+
+// leafHashes returns the hashes of the leaves named by req. The identity hash
+// of a queued leaf is taken from resp if it was computed by the server.
+func leafHashes(req, resp interface{}) []string {
+	var hashes [][]byte
+	switch req := req.(type) {
+	case *trillian.QueueLeafRequest:
+		id := req.GetLeaf().GetLeafIdentityHash()
+		if qr, ok := resp.(*trillian.QueueLeafResponse); ok && len(id) == 0 {
+			id = qr.GetQueuedLeaf().GetLeaf().GetLeafIdentityHash()
+		}
+		hashes = append(hashes, id)
+	case *trillian.AddSequencedLeavesRequest:
+		for _, leaf := range req.GetLeaves() {
+			hashes = append(hashes, leaf.GetLeafIdentityHash())
+		}
+	case *trillian.GetInclusionProofByHashRequest:
+		hashes = append(hashes, req.GetLeafHash())
+	case *trillian.GetInclusionProofByIdentityHashRequest:
+		hashes = append(hashes, req.GetLeafIdentityHash())
+	}
+	var ret []string
+	for _, h := range hashes {
+		if len(h) > 0 {
+			ret = append(ret, hex.EncodeToString(h))
+		}
+	}
+	return ret
+}
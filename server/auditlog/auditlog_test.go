@@ -0,0 +1,323 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/trillian"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// memorySink is a Sink which keeps records in memory.
+type memorySink struct {
+	mu      sync.Mutex
+	records []*Record
+	closed  bool
+}
+
+func (s *memorySink) Write(_ context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+	ok := func(context.Context, interface{}) (interface{}, error) {
+		return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: &trillian.LogLeaf{LeafIdentityHash: []byte{0xab}}}}, nil
+	}
+	failed := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such leaf")
+	}
+	queue := &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/QueueLeaf"}
+	proof := &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/GetInclusionProofByHash"}
+
+	for _, test := range []struct {
+		desc                        string
+		sampleRate, errorSampleRate float64
+		want                        []*Record
+	}{
+		{
+			desc:            "all",
+			sampleRate:      1,
+			errorSampleRate: 1,
+			want: []*Record{
+				{Method: queue.FullMethod, TreeID: 1, Peer: "192.0.2.1:1234", Code: "OK", LeafHashes: []string{"ab"}},
+				{Method: proof.FullMethod, TreeID: 2, Peer: "192.0.2.1:1234", Code: "NotFound", Error: "no such leaf", LeafHashes: []string{"cd"}},
+			},
+		},
+		{
+			desc:            "errors-only",
+			errorSampleRate: 1,
+			want: []*Record{
+				{Method: proof.FullMethod, TreeID: 2, Peer: "192.0.2.1:1234", Code: "NotFound", Error: "no such leaf", LeafHashes: []string{"cd"}},
+			},
+		},
+		{
+			desc: "none",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			sink := &memorySink{}
+			l := New(Config{Sink: sink, SampleRate: test.sampleRate, ErrorSampleRate: test.errorSampleRate}, nil)
+			if _, err := l.UnaryInterceptor(ctx, &trillian.QueueLeafRequest{LogId: 1, Leaf: &trillian.LogLeaf{LeafValue: []byte("value")}}, queue, ok); err != nil {
+				t.Fatalf("QueueLeaf: %v", err)
+			}
+			if _, err := l.UnaryInterceptor(ctx, &trillian.GetInclusionProofByHashRequest{LogId: 2, LeafHash: []byte{0xcd}}, proof, failed); status.Code(err) != codes.NotFound {
+				t.Fatalf("GetInclusionProofByHash: %v, want NotFound", err)
+			}
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close(): %v", err)
+			}
+			if !sink.closed {
+				t.Error("sink not closed")
+			}
+			if diff := cmp.Diff(test.want, sink.records, cmpopts.IgnoreFields(Record{}, "Time", "LatencyMillis"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("records diff (-want +got):\n%s", diff)
+			}
+			for _, r := range sink.records {
+				if r.Time.IsZero() || r.LatencyMillis < 0 {
+					t.Errorf("record %+v lacks time or latency", r)
+				}
+			}
+		})
+	}
+}
+
+// fakeServerStream is a grpc.ServerStream which receives a single request.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req proto.Message
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func TestLoggerStreams(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+	info := &grpc.StreamServerInfo{FullMethod: "/trillian.TrillianLog/WatchSignedLogRoots", IsServerStream: true}
+	handler := func(end error) grpc.StreamHandler {
+		return func(_ interface{}, ss grpc.ServerStream) error {
+			if err := ss.RecvMsg(&trillian.WatchSignedLogRootsRequest{}); err != nil {
+				return err
+			}
+			return end
+		}
+	}
+	cancelled := status.Error(codes.Canceled, "context canceled")
+
+	for _, test := range []struct {
+		desc                        string
+		sampleRate, errorSampleRate float64
+		err                         error
+		want                        []*Record
+	}{
+		{
+			desc:       "open-and-close",
+			sampleRate: 1,
+			err:        cancelled,
+			want: []*Record{
+				{Method: info.FullMethod, TreeID: 3, Peer: "192.0.2.1:1234", Code: "OK", Stream: "open"},
+				{Method: info.FullMethod, TreeID: 3, Peer: "192.0.2.1:1234", Code: "Canceled", Error: "context canceled", Stream: "close"},
+			},
+		},
+		{
+			desc:            "failed-only",
+			errorSampleRate: 1,
+			err:             cancelled,
+			want: []*Record{
+				{Method: info.FullMethod, TreeID: 3, Peer: "192.0.2.1:1234", Code: "Canceled", Error: "context canceled", Stream: "close"},
+			},
+		},
+		{
+			desc:            "unsampled",
+			errorSampleRate: 1,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			sink := &memorySink{}
+			l := New(Config{Sink: sink, SampleRate: test.sampleRate, ErrorSampleRate: test.errorSampleRate}, nil)
+			ss := &fakeServerStream{ctx: ctx, req: &trillian.WatchSignedLogRootsRequest{LogId: 3}}
+			if err := l.StreamInterceptor(nil, ss, info, handler(test.err)); err != test.err {
+				t.Fatalf("StreamInterceptor() = %v, want %v", err, test.err)
+			}
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close(): %v", err)
+			}
+			if diff := cmp.Diff(test.want, sink.records, cmpopts.IgnoreFields(Record{}, "Time", "LatencyMillis"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("records diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+var testRecords = []*Record{
+	{Method: "/trillian.TrillianLog/QueueLeaf", TreeID: 1, Code: "OK", LeafHashes: []string{"ab"}},
+	{Method: "/trillian.TrillianLog/GetLeavesByRange", TreeID: 2, Code: "PermissionDenied", Error: "disabled"},
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for _, records := range [][]*Record{testRecords[:1], testRecords[1:]} {
+		// The file is appended to by each sink.
+		sink, err := NewSink(ctx, "file://"+path)
+		if err != nil {
+			t.Fatalf("NewSink(): %v", err)
+		}
+		if err := sink.Write(ctx, records); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Close(): %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	defer f.Close()
+	var got []*Record
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var r Record
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", s.Bytes(), err)
+		}
+		got = append(got, &r)
+	}
+	if diff := cmp.Diff(testRecords, got); diff != "" {
+		t.Errorf("records diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	ctx := context.Background()
+	var got []*Record
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trillian.audit" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(ctx, srv.URL+"/trillian.audit")
+	if err != nil {
+		t.Fatalf("NewSink(): %v", err)
+	}
+	if err := sink.Write(ctx, testRecords); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if diff := cmp.Diff(testRecords, got); diff != "" {
+		t.Errorf("records diff (-want +got):\n%s", diff)
+	}
+
+	if err := NewHTTPSink(srv.URL+"/other", srv.Client()).Write(ctx, testRecords); err == nil {
+		t.Error("Write() to missing path: got nil error")
+	}
+}
+
+func TestPubSubSink(t *testing.T) {
+	ctx := context.Background()
+	const topic = "projects/llamas/topics/audit"
+	var got []*Record
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/"+topic+":publish" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Messages []struct {
+				Data       string
+				Attributes map[string]string
+			}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, m := range req.Messages {
+			data, err := base64.StdEncoding.DecodeString(m.Data)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			var rec Record
+			if err := json.Unmarshal(data, &rec); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if m.Attributes["method"] != rec.Method {
+				http.Error(w, "wrong method attribute", http.StatusBadRequest)
+				return
+			}
+			got = append(got, &rec)
+		}
+		_, _ = w.Write([]byte(`{"messageIds": ["1", "2"]}`))
+	}))
+	defer srv.Close()
+
+	sink, err := NewPubSubSink(ctx, topic, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewPubSubSink(): %v", err)
+	}
+	if err := sink.Write(ctx, testRecords); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if diff := cmp.Diff(testRecords, got); diff != "" {
+		t.Errorf("records diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewSinkErrors(t *testing.T) {
+	for _, uri := range []string{"ftp://example.com/audit", "audit.log", "%zz"} {
+		if _, err := NewSink(context.Background(), uri); err == nil {
+			t.Errorf("NewSink(%q): got nil error", uri)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// NewSink returns the Sink described by uri, which is one of:
+//   - file:///path/to/file, which appends records to the file as JSON lines;
+//   - http://host:port/tag or https://..., which posts batches of records as
+//     JSON arrays, as accepted by the fluentd in_http input plugin;
+//   - pubsub://projects/<project>/topics/<topic>, which publishes each record
+//     as a message to the Google Cloud Pub/Sub topic, using application
+//     default credentials.
+func NewSink(ctx context.Context, uri string) (Sink, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit log sink %q: %v", uri, err)
+	}
+	switch u.Scheme {
+	case "file":
+		return NewFileSink(u.Path)
+	case "http", "https":
+		return NewHTTPSink(uri, http.DefaultClient), nil
+	case "pubsub":
+		return NewPubSubSink(ctx, strings.TrimPrefix(uri, "pubsub://"))
+	}
+	return nil, fmt.Errorf("unsupported audit log sink %q", uri)
+}
+
+// FileSink appends records to a file, one JSON object per line.
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+// NewFileSink returns a FileSink which appends to the file at path, creating
+// it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Write implements Sink. The records are flushed to the file before it
+// returns.
+func (s *FileSink) Write(_ context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return s.w.Flush()
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil {
+		_ = s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// HTTPSink posts batches of records to a URL as JSON arrays.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink returns an HTTPSink which posts to url with client.
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	return &HTTPSink{url: url, client: client}
+}
+
+// Write implements Sink.
+func (s *HTTPSink) Write(ctx context.Context, records []*Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST %s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *HTTPSink) Close() error { return nil }
+
+// PubSubSink publishes records to a Google Cloud Pub/Sub topic, one message
+// per record, with the method and tree ID as attributes.
+type PubSubSink struct {
+	topic string
+	svc   *pubsub.Service
+}
+
+// NewPubSubSink returns a PubSubSink which publishes to topic, of the form
+// projects/<project>/topics/<topic>.
+func NewPubSubSink(ctx context.Context, topic string, opts ...option.ClientOption) (*PubSubSink, error) {
+	svc, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %v", err)
+	}
+	return &PubSubSink{topic: topic, svc: svc}, nil
+}
+
+// Write implements Sink.
+func (s *PubSubSink) Write(ctx context.Context, records []*Record) error {
+	req := &pubsub.PublishRequest{Messages: make([]*pubsub.PubsubMessage, 0, len(records))}
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		req.Messages = append(req.Messages, &pubsub.PubsubMessage{
+			Data: base64.StdEncoding.EncodeToString(data),
+			Attributes: map[string]string{
+				"method":  r.Method,
+				"tree_id": strconv.FormatInt(r.TreeID, 10),
+			},
+		})
+	}
+	_, err := s.svc.Projects.Topics.Publish(s.topic, req).Context(ctx).Do()
+	return err
+}
+
+// Close implements Sink.
+func (s *PubSubSink) Close() error { return nil }
@@ -0,0 +1,71 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	requestBytes  monitoring.Counter
+	responseBytes monitoring.Counter
+	bandwidthOnce sync.Once
+)
+
+// Bandwidth returns a grpc.UnaryServerInterceptor which counts the bytes of
+// the encoded requests and responses of each tree and method, so that hosted
+// deployments can attribute their traffic to tenants. Requests which don't
+// address a tree are counted under tree 0.
+func Bandwidth(mf monitoring.MetricFactory) grpc.UnaryServerInterceptor {
+	bandwidthOnce.Do(func() {
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		requestBytes = mf.NewCounter("interceptor_request_bytes", "Total size of received requests, by tree and method", monitoring.TreeIDLabel, "method")
+		responseBytes = mf.NewCounter("interceptor_response_bytes", "Total size of sent responses, by tree and method", monitoring.TreeIDLabel, "method")
+	})
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Handlers may fill in fields of the request, e.g. leaf hashes, so
+		// it is measured before they run.
+		treeID := fmt.Sprint(requestTreeID(req))
+		if m, ok := req.(proto.Message); ok {
+			requestBytes.Add(float64(proto.Size(m)), treeID, info.FullMethod)
+		}
+		rsp, err := handler(ctx, req)
+		if m, ok := rsp.(proto.Message); ok && err == nil {
+			responseBytes.Add(float64(proto.Size(m)), treeID, info.FullMethod)
+		}
+		return rsp, err
+	}
+}
+
+// requestTreeID returns the ID of the tree addressed by req, or zero.
+func requestTreeID(req interface{}) int64 {
+	switch r := req.(type) {
+	case logIDRequest:
+		return r.GetLogId()
+	case treeIDRequest:
+		return r.GetTreeId()
+	case treeRequest:
+		return r.GetTree().GetTreeId()
+	}
+	return 0
+}
@@ -17,8 +17,11 @@ package interceptor
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,15 +32,18 @@ import (
 	"github.com/google/trillian/server/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"k8s.io/klog/v2"
 )
 
 const (
 	badInfoReason            = "bad_info"
 	badTreeReason            = "bad_tree"
+	disabledMethodReason     = "disabled_method"
 	insufficientTokensReason = "insufficient_tokens"
 	getTreeStage             = "get_tree"
 	getTokensStage           = "get_tokens"
@@ -50,6 +56,15 @@ var (
 	// its own timeout, separate from the RPC that causes the calls.
 	PutTokensTimeout = 5 * time.Second
 
+	// putTokensMinBackoff and putTokensMaxBackoff bound the pauses between retries of failed
+	// PutTokens calls.
+	putTokensMinBackoff = 50 * time.Millisecond
+	putTokensMaxBackoff = time.Second
+
+	// QuotaRetryDelay is the delay suggested to clients whose requests are denied for lack of
+	// tokens, unless the quota.Manager estimates when enough will be available.
+	QuotaRetryDelay = time.Second
+
 	requestCounter       monitoring.Counter
 	requestDeniedCounter monitoring.Counter
 	contextErrCounter    monitoring.Counter
@@ -140,6 +155,53 @@ func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interfac
 	return resp, err
 }
 
+// StreamInterceptor executes the TrillianInterceptor logic for server
+// streaming RPCs. The checks are made, and quota charged, once for the
+// request which opens the stream.
+func (i *TrillianInterceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s := &processedStream{ServerStream: ss, ctx: ss.Context(), rp: i.NewProcessor(), method: info.FullMethod}
+	err := handler(srv, s)
+	if s.processed {
+		s.rp.After(s.ctx, nil, info.FullMethod, err)
+	}
+	return err
+}
+
+// processedStream is a grpc.ServerStream which runs the Before stage of a
+// RequestProcessor on the first message received.
+type processedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	rp        RequestProcessor
+	method    string
+	received  bool
+	processed bool
+}
+
+// Context returns the context returned by the RequestProcessor, once the first
+// message has been received.
+func (s *processedStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg implements grpc.ServerStream.
+func (s *processedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.received {
+		return nil
+	}
+	s.received = true
+	ctx, err := s.rp.Before(s.ctx, m, s.method)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	s.processed = true
+	return nil
+}
+
 // NewProcessor returns a RequestProcessor for the TrillianInterceptor logic.
 func (i *TrillianInterceptor) NewProcessor() RequestProcessor {
 	return &trillianProcessor{parent: i}
@@ -181,6 +243,10 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method
 			contextErrCounter.Inc(getTreeStage)
 			return ctx, err
 		}
+		if name := methodName(method); slices.Contains(tree.DisabledMethods, name) {
+			incRequestDeniedCounter(disabledMethodReason, info.treeID, info.quotaUsers)
+			return ctx, status.Errorf(codes.PermissionDenied, "%s is disabled for tree %d", name, info.treeID)
+		}
 		ctx = trees.NewContext(ctx, tree)
 	}
 
@@ -189,20 +255,97 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method
 		if err != nil {
 			if !tp.parent.quotaDryRun {
 				incRequestDeniedCounter(insufficientTokensReason, info.treeID, info.quotaUsers)
-				return ctx, status.Errorf(codes.ResourceExhausted, "quota exhausted: %v", err)
+				return ctx, quotaExhausted(err, info.specs)
 			}
 			klog.Warningf("(quotaDryRun) Request %+v not denied due to dry run mode: %v", req, err)
 		}
 		quota.Metrics.IncAcquired(info.tokens, info.specs, err == nil)
-		if err = innerCtx.Err(); err != nil {
+		if len(info.leafSpecs) > 0 {
+			if leafErr := tp.getLeafTokens(innerCtx, err == nil); leafErr != nil {
+				return ctx, leafErr
+			}
+		}
+		if ctxErr := innerCtx.Err(); ctxErr != nil {
 			contextErrCounter.Inc(getTokensStage)
-			return ctx, err
+			if err == nil {
+				// The handler won't run, so treat this like an invalid request.
+				tp.parent.putRefundableTokens(info.tokens, info.specs)
+			}
+			return ctx, ctxErr
 		}
 	}
 
 	return ctx, nil
 }
 
+// getLeafTokens acquires tokens from the users charged for individual leaves of
+// the request, one for each leaf they are charged for. If the quota of any of
+// them is exhausted, the request is denied and the tokens acquired for it so
+// far are returned, including those of the whole request if gotRequestTokens.
+func (tp *trillianProcessor) getLeafTokens(ctx context.Context, gotRequestTokens bool) error {
+	info := tp.info
+	var got []specTokens
+	for _, st := range sumLeafTokens(info.leafSpecs, nil) {
+		specs := []quota.Spec{st.spec}
+		err := tp.parent.qm.GetTokens(ctx, st.tokens, specs)
+		if err != nil {
+			if !tp.parent.quotaDryRun {
+				incRequestDeniedCounter(insufficientTokensReason, info.treeID, st.spec.User)
+				if gotRequestTokens {
+					tp.parent.putTokens(info.tokens, info.specs)
+				}
+				for _, g := range got {
+					tp.parent.putTokens(g.tokens, []quota.Spec{g.spec})
+				}
+				return quotaExhausted(err, specs)
+			}
+			klog.Warningf("(quotaDryRun) Leaves charged to %q not denied due to dry run mode: %v", st.spec.User, err)
+		}
+		quota.Metrics.IncAcquired(st.tokens, specs, err == nil)
+		if err == nil {
+			got = append(got, st)
+		}
+	}
+	return nil
+}
+
+// putLeafTokens returns the tokens acquired from the users charged for the
+// leaves of the request which include returns true for.
+func (tp *trillianProcessor) putLeafTokens(include func(int) bool) {
+	for _, st := range sumLeafTokens(tp.info.leafSpecs, include) {
+		tp.parent.putTokens(st.tokens, []quota.Spec{st.spec})
+	}
+}
+
+// specTokens is a number of tokens of a quota spec.
+type specTokens struct {
+	spec   quota.Spec
+	tokens int
+}
+
+// sumLeafTokens returns the number of tokens to charge to each of the specs
+// in leafSpecs, for the leaves which include returns true for, or all leaves
+// if include is nil. Specs are returned in the order they first appear.
+func sumLeafTokens(leafSpecs [][]quota.Spec, include func(int) bool) []specTokens {
+	var ret []specTokens
+	pos := make(map[quota.Spec]int)
+	for i, specs := range leafSpecs {
+		if include != nil && !include(i) {
+			continue
+		}
+		for _, spec := range specs {
+			j, ok := pos[spec]
+			if !ok {
+				j = len(ret)
+				pos[spec] = j
+				ret = append(ret, specTokens{spec: spec})
+			}
+			ret[j].tokens++
+		}
+	}
+	return ret
+}
+
 func (tp *trillianProcessor) After(ctx context.Context, resp interface{}, method string, handlerErr error) {
 	if !enabledServices[serviceName(method)] {
 		return
@@ -220,64 +363,147 @@ func (tp *trillianProcessor) After(ctx context.Context, resp interface{}, method
 
 	// Decide if we have to replenish tokens. There are a few situations that require tokens to
 	// be replenished:
-	// * Invalid requests (a bad request shouldn't spend sequencing-based tokens, as it won't
-	//   cause a corresponding sequencing to happen)
+	// * Requests, or leaves within them, which failed due to a transient server-side error,
+	//   such as storage being unavailable. These shouldn't burn any quota of the caller, so all
+	//   specs are refunded. Other server errors, such as Internal, may be triggered by the
+	//   request itself, so they are treated like invalid requests.
+	// * Invalid requests and leaves (a bad request shouldn't spend sequencing-based tokens, as
+	//   it won't cause a corresponding sequencing to happen)
 	// * Requests that filter out duplicates (e.g., QueueLeaf, for the same reason as above:
 	//   duplicates aren't queued for sequencing)
-	// These are only applied for Refundable specs.
-	refunds := make([]quota.Spec, 0)
-	for _, s := range tp.info.specs {
-		if s.Refundable {
-			refunds = append(refunds, s)
-		}
-	}
-	if len(refunds) == 0 {
-		return
-	}
-
-	tokens := 0
+	// The latter two are only applied for Refundable specs, so that failing requests can't be
+	// sent at an unlimited rate.
+	// The users charged for individual leaves are never Refundable, so only
+	// the leaves which failed due to a transient error are refunded to them.
+	all, refundable := 0, 0
 	if handlerErr != nil {
-		// Return the tokens spent by invalid requests
-		tokens = tp.info.tokens
+		if isTransientError(status.Code(handlerErr)) {
+			all = tp.info.tokens
+			tp.putLeafTokens(nil)
+		} else {
+			refundable = tp.info.tokens
+		}
 	} else {
+		var leaves []*trillian.QueuedLogLeaf
 		switch resp := resp.(type) {
 		case *trillian.QueueLeafResponse:
-			if !isLeafOK(resp.GetQueuedLeaf()) {
-				tokens = 1
-			}
+			leaves = []*trillian.QueuedLogLeaf{resp.GetQueuedLeaf()}
 		case *trillian.AddSequencedLeavesResponse:
-			for _, leaf := range resp.GetResults() {
-				if !isLeafOK(leaf) {
-					tokens++
-				}
+			leaves = resp.GetResults()
+		}
+		failed := make(map[int]bool)
+		for i, leaf := range leaves {
+			switch code := leafCode(leaf); {
+			case code == codes.OK:
+			case isTransientError(code):
+				all++
+				failed[i] = true
+			default:
+				refundable++
 			}
 		}
+		if len(failed) > 0 {
+			tp.putLeafTokens(func(i int) bool { return failed[i] })
+		}
 	}
-	if tokens > 0 {
-		// Run PutTokens in a separate goroutine and with a separate context.
-		// It shouldn't block RPC completion, nor should it share the RPC's context deadline.
-		go func() {
-			ctx, spanEnd := spanFor(context.Background(), "After.PutTokens")
-			defer spanEnd()
-			ctx, cancel := context.WithTimeout(ctx, PutTokensTimeout)
-			defer cancel()
+	if all > 0 {
+		tp.parent.putTokens(all, tp.info.specs)
+	}
+	if refundable > 0 {
+		tp.parent.putRefundableTokens(refundable, tp.info.specs)
+	}
+}
+
+// quotaExhausted returns the ResourceExhausted error for a request denied tokens of specs by
+// err. It has QuotaFailure details saying which spec was denied, if known, and RetryInfo details
+// suggesting when to retry.
+func quotaExhausted(err error, specs []quota.Spec) error {
+	st := status.Newf(codes.ResourceExhausted, "quota exhausted: %v", err)
+	violation := &errdetails.QuotaFailure_Violation{Description: err.Error()}
+	delay := QuotaRetryDelay
+	var ite *quota.InsufficientTokensError
+	if goerrors.As(err, &ite) {
+		violation.Subject = ite.Spec.Name()
+		if ite.RetryAfter > 0 {
+			delay = ite.RetryAfter
+		}
+	} else {
+		names := make([]string, 0, len(specs))
+		for _, s := range specs {
+			names = append(names, s.Name())
+		}
+		violation.Subject = strings.Join(names, ",")
+	}
+	withDetails, detailsErr := st.WithDetails(
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{violation}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// isTransientError returns whether code is that of a transient failure of the server rather
+// than of the request, e.g. due to storage being unavailable, which a caller can't trigger at
+// will.
+func isTransientError(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	}
+	return false
+}
 
-			// TODO(codingllama): If PutTokens turns out to be unreliable we can still leak tokens. In
-			// this case, we may want to keep tabs on how many tokens we failed to replenish and bundle
-			// them up in the next PutTokens call (possibly as a QuotaManager decorator, or internally
-			// in its impl).
-			err := tp.parent.qm.PutTokens(ctx, tokens, refunds)
-			if err != nil {
+// putRefundableTokens returns tokens to the Refundable specs among specs, if any.
+func (i *TrillianInterceptor) putRefundableTokens(tokens int, specs []quota.Spec) {
+	refunds := make([]quota.Spec, 0)
+	for _, s := range specs {
+		if s.Refundable {
+			refunds = append(refunds, s)
+		}
+	}
+	if len(refunds) > 0 {
+		i.putTokens(tokens, refunds)
+	}
+}
+
+// putTokens returns tokens to specs. It runs in a separate goroutine and with a separate
+// context, as it shouldn't block RPC completion, nor should it share the RPC's context deadline.
+// Failed calls are retried with backoff until PutTokensTimeout, so that transient quota storage
+// errors don't leak tokens.
+func (i *TrillianInterceptor) putTokens(tokens int, specs []quota.Spec) {
+	go func() {
+		ctx, spanEnd := spanFor(context.Background(), "After.PutTokens")
+		defer spanEnd()
+		ctx, cancel := context.WithTimeout(ctx, PutTokensTimeout)
+		defer cancel()
+
+		pause := putTokensMinBackoff
+		for {
+			err := i.qm.PutTokens(ctx, tokens, specs)
+			if err == nil {
+				quota.Metrics.IncReturned(tokens, specs, true)
+				return
+			}
+			select {
+			case <-ctx.Done():
 				klog.Warningf("Failed to replenish %v tokens: %v", tokens, err)
+				quota.Metrics.IncReturned(tokens, specs, false)
+				return
+			case <-time.After(pause):
 			}
-			quota.Metrics.IncReturned(tokens, refunds, err == nil)
-		}()
-	}
+			pause = min(2*pause, putTokensMaxBackoff)
+		}
+	}()
 }
 
-func isLeafOK(leaf *trillian.QueuedLogLeaf) bool {
-	// Be biased in favor of OK, as that matches TrillianLogRPCServer's behavior.
-	return leaf == nil || leaf.Status == nil || leaf.Status.Code == int32(codes.OK)
+// leafCode returns the status code of a queued leaf. Be biased in favor of OK, as that matches
+// TrillianLogRPCServer's behavior.
+func leafCode(leaf *trillian.QueuedLogLeaf) codes.Code {
+	if leaf == nil || leaf.Status == nil {
+		return codes.OK
+	}
+	return codes.Code(leaf.Status.Code)
 }
 
 var (
@@ -298,6 +524,18 @@ func serviceName(fullMethod string) string {
 	return ""
 }
 
+// methodName returns the method name "method" for "/some.package.service/method"
+// or "/service.method".
+func methodName(fullMethod string) string {
+	if matches := fullyQualifiedRE.FindStringSubmatch(fullMethod); len(matches) == 3 {
+		return matches[2]
+	}
+	if matches := unqualifiedRE.FindStringSubmatch(fullMethod); len(matches) == 3 {
+		return matches[2]
+	}
+	return ""
+}
+
 type rpcInfo struct {
 	// getTree indicates whether the interceptor should populate treeID.
 	getTree bool
@@ -308,6 +546,9 @@ type rpcInfo struct {
 
 	specs  []quota.Spec
 	tokens int
+	// leafSpecs holds the specs of the users charged for each of the leaves
+	// of the request, on top of specs, if the request charges per leaf.
+	leafSpecs [][]quota.Spec
 	// Single string describing all of the users against which quota is requested.
 	quotaUsers string
 }
@@ -332,6 +573,20 @@ func chargedUsers(req interface{}) []string {
 	return chargeTo.User
 }
 
+// leafChargedUsers returns the user identifiers charged for each of the leaves
+// of a request, on top of chargedUsers, if it charges per leaf.
+func leafChargedUsers(req interface{}) [][]string {
+	r, ok := req.(*trillian.AddSequencedLeavesRequest)
+	if !ok || len(r.GetLeafChargeTo()) == 0 {
+		return nil
+	}
+	ret := make([][]string, len(r.GetLeafChargeTo()))
+	for i, c := range r.GetLeafChargeTo() {
+		ret[i] = c.GetUser()
+	}
+	return ret
+}
+
 func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 	// Set "safe" defaults: enable all interception and assume requests are readonly.
 	info := &rpcInfo{
@@ -364,22 +619,36 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		info.getTree = false // Zero to many trees
 
 	// Admin / readonly
-	case *trillian.GetTreeRequest:
+	case *trillian.GetTreeRequest,
+		*trillian.ListIntegrationEventsRequest,
+		*trillian.GetOperationRequest,
+		*trillian.ListOperationsRequest:
 		info.getTree = false // Read done within RPC handler
 
 	// Admin / readwrite
 	case *trillian.DeleteTreeRequest,
 		*trillian.UndeleteTreeRequest,
-		*trillian.UpdateTreeRequest:
+		*trillian.UpdateTreeRequest,
+		*trillian.TriggerSequencingRequest,
+		*trillian.HardDeleteTreeRequest,
+		*trillian.ExportTreeRequest,
+		*trillian.MigrateTreeRequest,
+		*trillian.CancelOperationRequest:
 		info.getTree = false // Read-modify-write done within RPC handler
 		info.readonly = false
 
 	// (Log + Pre-ordered Log) / readonly
 	case *trillian.GetConsistencyProofRequest,
-		*trillian.GetEntryAndProofRequest,
+		*trillian.GetConsistencyProofByRootHashRequest,
 		*trillian.GetInclusionProofByHashRequest,
+		*trillian.GetInclusionProofByIdentityHashRequest,
 		*trillian.GetInclusionProofRequest,
-		*trillian.GetLatestSignedLogRootRequest:
+		*trillian.GetLatestSignedLogRootRequest,
+		*trillian.GetRangeInclusionProofRequest,
+		*trillian.GetSequencedLeafCountRequest,
+		*trillian.GetTreeStatsRequest,
+		*trillian.ListSignedLogRootsRequest,
+		*trillian.WatchSignedLogRootsRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 		info.tokens = 1
 	case *trillian.GetLeavesByRangeRequest:
@@ -388,6 +657,15 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		if c := req.GetCount(); c > 1 {
 			info.tokens = int(c)
 		}
+	case *trillian.GetEntryAndProofRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1 + len(req.GetAdditionalLeafIndices())
+	case *trillian.GetLeavesByIndicesRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1
+		if c := len(req.GetLeafIndex()); c > 1 {
+			info.tokens = c
+		}
 	// Log / readwrite
 	case *trillian.QueueLeafRequest:
 		info.readonly = false
@@ -401,7 +679,9 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		info.tokens = len(req.GetLeaves())
 
 	// (Log + Pre-ordered Log) / readwrite
-	case *trillian.InitLogRequest:
+	case *trillian.InitLogRequest,
+		*trillian.UpdateLeafExtraDataRequest,
+		*trillian.RedactLeafRequest:
 		info.readonly = false
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 		info.tokens = 1
@@ -449,6 +729,18 @@ func newRPCInfo(req interface{}) (*rpcInfo, error) {
 			{Group: quota.Tree, Kind: kind, TreeID: info.treeID},
 			{Group: quota.Global, Kind: kind, Refundable: true}, // Only Global tokens are refunded.
 		}...)
+
+		if users := leafChargedUsers(req); users != nil {
+			if len(users) != info.tokens {
+				return nil, status.Errorf(codes.InvalidArgument, "leaf_charge_to has %d entries, want one for each of the %d leaves", len(users), info.tokens)
+			}
+			info.leafSpecs = make([][]quota.Spec, len(users))
+			for i, leafUsers := range users {
+				for _, user := range leafUsers {
+					info.leafSpecs[i] = append(info.leafSpecs[i], quota.Spec{Group: quota.User, Kind: kind, User: user})
+				}
+			}
+		}
 	}
 
 	return info, nil
@@ -474,6 +766,35 @@ func ErrorWrapper(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
 	return rsp, errors.WrapError(err)
 }
 
+// Deadline returns a grpc.UnaryServerInterceptor which gives requests without
+// a deadline one of defaultTimeout, and shortens deadlines further away than
+// maxTimeout, so that the handler's storage operations are abandoned when the
+// request is. Zero durations are ignored.
+//
+// It isn't applied to streaming RPCs, such as WatchSignedLogRoots, which are
+// expected to stay open for as long as their clients want.
+func Deadline(defaultTimeout, maxTimeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var timeout time.Duration
+		switch deadline, ok := ctx.Deadline(); {
+		case ok:
+			if maxTimeout > 0 && time.Until(deadline) > maxTimeout {
+				timeout = maxTimeout
+			}
+		case defaultTimeout > 0 && (maxTimeout <= 0 || defaultTimeout < maxTimeout):
+			timeout = defaultTimeout
+		default:
+			timeout = maxTimeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
+
 func spanFor(ctx context.Context, name string) (context.Context, func()) {
 	return monitoring.StartSpan(ctx, fmt.Sprintf("%s.%s", traceSpanRoot, name))
 }
@@ -29,12 +29,14 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/trees"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	mtestonly "github.com/google/trillian/monitoring/testonly"
 	serrors "github.com/google/trillian/server/errors"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 )
@@ -59,6 +61,24 @@ func TestServiceName(t *testing.T) {
 	}
 }
 
+func TestMethodName(t *testing.T) {
+	for _, tc := range []struct {
+		desc   string
+		method string
+		want   string
+	}{
+		{desc: "trillian", method: "/trillian.TrillianLog/QueueLeaf", want: "QueueLeaf"},
+		{desc: "unqualified", method: "/service.method", want: "method"},
+		{desc: "malformed", method: "/package.service.method"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got, want := methodName(tc.method), tc.want; got != want {
+				t.Errorf("methodName(%v): %v, want %v", tc.method, got, want)
+			}
+		})
+	}
+}
+
 func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
 	logTree.TreeId = 10
@@ -66,6 +86,9 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 	deletedTree.TreeId = 12
 	deletedTree.Deleted = true
 	deletedTree.DeleteTime = timestamppb.Now()
+	restrictedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	restrictedTree.TreeId = 13
+	restrictedTree.DisabledMethods = []string{"GetLeavesByRange"}
 	unknownTreeID := int64(999)
 
 	tests := []struct {
@@ -84,6 +107,11 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 			method: "/trillian.TrillianAdmin/GetTree",
 			req:    &trillian.GetTreeRequest{TreeId: logTree.TreeId},
 		},
+		{
+			desc:   "adminListIntegrationEvents",
+			method: "/trillian.TrillianAdmin/ListIntegrationEvents",
+			req:    &trillian.ListIntegrationEventsRequest{TreeId: logTree.TreeId},
+		},
 		{
 			desc:   "adminWriteByID",
 			method: "/trillian.TrillianAdmin/DeleteTree",
@@ -117,6 +145,18 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 			req:     &trillian.GetLatestSignedLogRootRequest{LogId: deletedTree.TreeId},
 			wantErr: true,
 		},
+		{
+			desc:    "disabledMethod",
+			method:  "/trillian.TrillianLog/GetLeavesByRange",
+			req:     &trillian.GetLeavesByRangeRequest{LogId: restrictedTree.TreeId},
+			wantErr: true,
+		},
+		{
+			desc:     "enabledMethod",
+			method:   "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			req:      &trillian.GetLatestSignedLogRootRequest{LogId: restrictedTree.TreeId},
+			wantTree: restrictedTree,
+		},
 		{
 			desc:      "cancelled",
 			method:    "/trillian.TrillianLog/GetLatestSignedLogRoot",
@@ -136,6 +176,7 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
 			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
 			adminTX.EXPECT().GetTree(gomock.Any(), deletedTree.TreeId).AnyTimes().Return(deletedTree, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), restrictedTree.TreeId).AnyTimes().Return(restrictedTree, nil)
 			adminTX.EXPECT().GetTree(gomock.Any(), unknownTreeID).AnyTimes().Return(nil, errors.New("not found"))
 			adminTX.EXPECT().Close().AnyTimes().Return(nil)
 			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
@@ -182,6 +223,80 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 	}
 }
 
+// fakeServerStream is a grpc.ServerStream which receives a single request.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req proto.Message
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func TestTrillianInterceptor_StreamInterception(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+	restrictedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	restrictedTree.TreeId = 13
+	restrictedTree.DisabledMethods = []string{"WatchSignedLogRoots"}
+	frozenTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	frozenTree.TreeId = 14
+	frozenTree.TreeState = trillian.TreeState_FROZEN
+
+	for _, test := range []struct {
+		desc     string
+		treeID   int64
+		wantCode codes.Code
+	}{
+		{desc: "ok", treeID: logTree.TreeId},
+		{desc: "frozen", treeID: frozenTree.TreeId},
+		{desc: "disabledMethod", treeID: restrictedTree.TreeId, wantCode: codes.PermissionDenied},
+		{desc: "unknownTree", treeID: 999, wantCode: codes.NotFound},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			for _, tree := range []*trillian.Tree{logTree, restrictedTree, frozenTree} {
+				adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
+			}
+			adminTX.EXPECT().GetTree(gomock.Any(), int64(999)).AnyTimes().Return(nil, status.Error(codes.NotFound, "not found"))
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			qm := quota.NewMockManager(ctrl)
+			if test.wantCode == codes.OK {
+				qm.EXPECT().GetTokens(gomock.Any(), 1, gomock.Any()).Return(nil)
+			}
+			intercept := New(admin, qm, false /* quotaDryRun */, nil /* mf */)
+
+			stream := &fakeServerStream{ctx: context.Background(), req: &trillian.WatchSignedLogRootsRequest{LogId: test.treeID}}
+			var gotTree *trillian.Tree
+			handler := func(srv interface{}, ss grpc.ServerStream) error {
+				var req trillian.WatchSignedLogRootsRequest
+				if err := ss.RecvMsg(&req); err != nil {
+					return err
+				}
+				gotTree, _ = trees.FromContext(ss.Context())
+				return nil
+			}
+			err := intercept.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/trillian.TrillianLog/WatchSignedLogRoots", IsServerStream: true}, handler)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("StreamInterceptor() returned err = %v, want code %v", err, test.wantCode)
+			}
+			if test.wantCode == codes.OK && gotTree.GetTreeId() != test.treeID {
+				t.Errorf("tree in handler ctx = %v, want ID %d", gotTree, test.treeID)
+			}
+		})
+	}
+}
+
 func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
 	logTree.TreeId = 10
@@ -222,6 +337,16 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 123,
 		},
+		{
+			desc:   "logReadEntriesAndProofs",
+			method: "/trillian.TrillianLog/GetEntryAndProof",
+			req:    &trillian.GetEntryAndProofRequest{LogId: logTree.TreeId, AdditionalLeafIndices: []int64{4, 5, 6}},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
+				{Group: quota.Global, Kind: quota.Read, Refundable: true},
+			},
+			wantTokens: 4,
+		},
 		{
 			desc:   "logReadNegativeRange",
 			method: "/trillian.TrillianLog/GetLeavesByRange",
@@ -276,6 +401,30 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 1,
 		},
+		{
+			desc:   "updateLeafExtraData",
+			method: "/trillian.TrillianLog/UpdateLeafExtraData",
+			req:    &trillian.UpdateLeafExtraDataRequest{LogId: logTree.TreeId, ChargeTo: charges},
+			specs: []quota.Spec{
+				{Group: quota.User, Kind: quota.Write, User: charge1},
+				{Group: quota.User, Kind: quota.Write, User: charge2},
+				{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
+				{Group: quota.Global, Kind: quota.Write, Refundable: true},
+			},
+			wantTokens: 1,
+		},
+		{
+			desc:   "redactLeaf",
+			method: "/trillian.TrillianLog/RedactLeaf",
+			req:    &trillian.RedactLeafRequest{LogId: logTree.TreeId, ChargeTo: charges},
+			specs: []quota.Spec{
+				{Group: quota.User, Kind: quota.Write, User: charge1},
+				{Group: quota.User, Kind: quota.Write, User: charge2},
+				{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
+				{Group: quota.Global, Kind: quota.Write, Refundable: true},
+			},
+			wantTokens: 1,
+		},
 		{
 			desc:   "batchSequencedLogLeavesRequest",
 			method: "/trillian.TrillianLog/AddSequencedLeaves",
@@ -364,39 +513,139 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 	}
 }
 
+func TestQuotaExhaustedDetails(t *testing.T) {
+	treeSpec := quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: 10}
+	globalSpec := quota.Spec{Group: quota.Global, Kind: quota.Write, Refundable: true}
+	specs := []quota.Spec{treeSpec, globalSpec}
+
+	for _, test := range []struct {
+		desc        string
+		err         error
+		wantSubject string
+		wantDelay   time.Duration
+	}{
+		{
+			desc:        "insufficientTokens",
+			err:         &quota.InsufficientTokensError{Spec: globalSpec, Available: 2, Requested: 5, RetryAfter: 3 * time.Second},
+			wantSubject: "global/write",
+			wantDelay:   3 * time.Second,
+		},
+		{
+			desc:        "insufficientTokensNoEstimate",
+			err:         fmt.Errorf("wrapped: %w", &quota.InsufficientTokensError{Spec: treeSpec, Available: 0, Requested: 1}),
+			wantSubject: "trees/10/write",
+			wantDelay:   QuotaRetryDelay,
+		},
+		{
+			desc:        "otherError",
+			err:         errors.New("too many unsequenced rows"),
+			wantSubject: "trees/10/write,global/write",
+			wantDelay:   QuotaRetryDelay,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			st := status.Convert(quotaExhausted(test.err, specs))
+			if got, want := st.Code(), codes.ResourceExhausted; got != want {
+				t.Errorf("quotaExhausted() code = %v, want %v", got, want)
+			}
+			var gotFailure, gotRetry bool
+			for _, d := range st.Details() {
+				switch d := d.(type) {
+				case *errdetails.QuotaFailure:
+					gotFailure = true
+					if len(d.Violations) != 1 || d.Violations[0].Subject != test.wantSubject {
+						t.Errorf("QuotaFailure = %v, want one violation of %q", d, test.wantSubject)
+					}
+				case *errdetails.RetryInfo:
+					gotRetry = true
+					if got := d.RetryDelay.AsDuration(); got != test.wantDelay {
+						t.Errorf("RetryInfo delay = %v, want %v", got, test.wantDelay)
+					}
+				}
+			}
+			if !gotFailure || !gotRetry {
+				t.Errorf("quotaExhausted() details = %v, want QuotaFailure and RetryInfo", st.Details())
+			}
+		})
+	}
+}
+
 func TestTrillianInterceptor_QuotaInterception_ReturnsTokens(t *testing.T) {
 	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
 	logTree.TreeId = 10
+	preorderedTree := proto.Clone(testonly.PreorderedLogTree).(*trillian.Tree)
+	preorderedTree.TreeId = 11
 
+	readSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Read, Refundable: true},
+	}
+	writeSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Write, Refundable: true},
+	}
+	preorderedSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Write, TreeID: preorderedTree.TreeId},
+		{Group: quota.Global, Kind: quota.Write, Refundable: true},
+	}
+	type refund struct {
+		tokens int
+		specs  []quota.Spec
+	}
 	tests := []struct {
-		desc                         string
-		method                       string
-		req, resp                    interface{}
-		specs                        []quota.Spec
-		handlerErr                   error
-		wantGetTokens, wantPutTokens int
+		desc          string
+		tree          *trillian.Tree
+		method        string
+		req, resp     interface{}
+		specs         []quota.Spec
+		handlerErr    error
+		wantGetTokens int
+		wantRefunds   []refund
+		putTokensErrs int
 	}{
 		{
-			desc:   "badRequest",
-			method: "/trillian.TrillianLog/GetLatestSignedLogRoot",
-			req:    &trillian.GetLatestSignedLogRootRequest{LogId: logTree.TreeId},
-			specs: []quota.Spec{
-				{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
-				{Group: quota.Global, Kind: quota.Read, Refundable: true},
-			},
-			handlerErr:    errors.New("bad request"),
+			desc:          "badRequest",
+			method:        "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			req:           &trillian.GetLatestSignedLogRootRequest{LogId: logTree.TreeId},
+			specs:         readSpecs,
+			handlerErr:    status.Error(codes.InvalidArgument, "bad request"),
 			wantGetTokens: 1,
-			wantPutTokens: 1,
+			wantRefunds:   []refund{{tokens: 1, specs: readSpecs[1:]}},
 		},
 		{
-			desc:   "newLeaf",
-			method: "/trillian.TrillianLog/QueueLeaf",
-			req:    &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}},
-			resp:   &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{}},
-			specs: []quota.Spec{
-				{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
-				{Group: quota.Global, Kind: quota.Write, Refundable: true},
-			},
+			desc:          "storageError",
+			method:        "/trillian.TrillianLog/QueueLeaf",
+			req:           &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}},
+			specs:         writeSpecs,
+			handlerErr:    status.Error(codes.Unavailable, "storage unavailable"),
+			wantGetTokens: 1,
+			wantRefunds:   []refund{{tokens: 1, specs: writeSpecs}},
+		},
+		{
+			desc:          "storageErrorRetried",
+			method:        "/trillian.TrillianLog/QueueLeaf",
+			req:           &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}},
+			specs:         writeSpecs,
+			handlerErr:    status.Error(codes.Unavailable, "storage unavailable"),
+			wantGetTokens: 1,
+			wantRefunds:   []refund{{tokens: 1, specs: writeSpecs}},
+			putTokensErrs: 2,
+		},
+		{
+			desc:          "internalError",
+			method:        "/trillian.TrillianLog/QueueLeaf",
+			req:           &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}},
+			specs:         writeSpecs,
+			handlerErr:    status.Error(codes.Internal, "bad leaf"),
+			wantGetTokens: 1,
+			wantRefunds:   []refund{{tokens: 1, specs: writeSpecs[1:]}},
+		},
+		{
+			desc:          "newLeaf",
+			method:        "/trillian.TrillianLog/QueueLeaf",
+			req:           &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}},
+			resp:          &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{}},
+			specs:         writeSpecs,
 			wantGetTokens: 1,
 		},
 		{
@@ -408,19 +657,38 @@ func TestTrillianInterceptor_QuotaInterception_ReturnsTokens(t *testing.T) {
 					Status: status.New(codes.AlreadyExists, "duplicate leaf").Proto(),
 				},
 			},
-			specs: []quota.Spec{
-				{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
-				{Group: quota.Global, Kind: quota.Write, Refundable: true},
-			},
+			specs:         writeSpecs,
 			wantGetTokens: 1,
-			wantPutTokens: 1,
+			wantRefunds:   []refund{{tokens: 1, specs: writeSpecs[1:]}},
+		},
+		{
+			desc:   "failedAndDuplicateLeaves",
+			tree:   preorderedTree,
+			method: "/trillian.TrillianLog/AddSequencedLeaves",
+			req: &trillian.AddSequencedLeavesRequest{LogId: preorderedTree.TreeId, Leaves: []*trillian.LogLeaf{
+				{LeafIndex: 0}, {LeafIndex: 1}, {LeafIndex: 2}, {LeafIndex: 3},
+			}},
+			resp: &trillian.AddSequencedLeavesResponse{Results: []*trillian.QueuedLogLeaf{
+				{},
+				{Status: status.New(codes.AlreadyExists, "duplicate leaf").Proto()},
+				{Status: status.New(codes.Internal, "failed").Proto()},
+				{Status: status.New(codes.Unavailable, "failed").Proto()},
+			}},
+			specs:         preorderedSpecs,
+			wantGetTokens: 4,
+			wantRefunds: []refund{
+				{tokens: 1, specs: preorderedSpecs},
+				{tokens: 2, specs: preorderedSpecs[1:]},
+			},
 		},
 	}
 
-	defer func(timeout time.Duration) {
+	defer func(timeout, minBackoff time.Duration) {
 		PutTokensTimeout = timeout
-	}(PutTokensTimeout)
+		putTokensMinBackoff = minBackoff
+	}(PutTokensTimeout, putTokensMinBackoff)
 	PutTokensTimeout = 5 * time.Second
+	putTokensMinBackoff = time.Millisecond
 
 	// Use a ctx with a timeout smaller than PutTokensTimeout. Not too short or
 	// spurious failures will occur when the deadline expires.
@@ -429,29 +697,30 @@ func TestTrillianInterceptor_QuotaInterception_ReturnsTokens(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
+			tree := test.tree
+			if tree == nil {
+				tree = logTree
+			}
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			admin := storage.NewMockAdminStorage(ctrl)
 			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
 			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
-			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
 			adminTX.EXPECT().Close().AnyTimes().Return(nil)
 			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
-			putTokensCh := make(chan bool, 1)
+			putTokensCh := make(chan bool, len(test.wantRefunds))
 			wantDeadline := time.Now().Add(PutTokensTimeout)
 
 			qm := quota.NewMockManager(ctrl)
 			if test.wantGetTokens > 0 {
 				qm.EXPECT().GetTokens(gomock.Any(), test.wantGetTokens, test.specs).Return(nil)
 			}
-			if test.wantPutTokens > 0 {
-				refunds := make([]quota.Spec, 0)
-				for _, s := range test.specs {
-					if s.Refundable {
-						refunds = append(refunds, s)
-					}
+			for _, r := range test.wantRefunds {
+				if test.putTokensErrs > 0 {
+					qm.EXPECT().PutTokens(gomock.Any(), r.tokens, r.specs).Times(test.putTokensErrs).Return(errors.New("quota storage unavailable"))
 				}
-				qm.EXPECT().PutTokens(gomock.Any(), test.wantPutTokens, refunds).Do(func(ctx context.Context, numTokens int, specs []quota.Spec) {
+				qm.EXPECT().PutTokens(gomock.Any(), r.tokens, r.specs).Do(func(ctx context.Context, numTokens int, specs []quota.Spec) {
 					switch d, ok := ctx.Deadline(); {
 					case !ok:
 						t.Errorf("PutTokens() ctx has no deadline: %v", ctx)
@@ -472,16 +741,183 @@ func TestTrillianInterceptor_QuotaInterception_ReturnsTokens(t *testing.T) {
 			}
 
 			// PutTokens may be delegated to a separate goroutine. Give it some time to complete.
-			select {
-			case <-putTokensCh:
-				// OK
-			case <-time.After(1 * time.Second):
-				// No need to error here, gomock will fail if the call is missing.
+			for range test.wantRefunds {
+				select {
+				case <-putTokensCh:
+					// OK
+				case <-time.After(1 * time.Second):
+					// No need to error here, gomock will fail if the call is missing.
+				}
+			}
+		})
+	}
+}
+
+func TestTrillianInterceptor_QuotaInterception_LeafChargeTo(t *testing.T) {
+	tree := proto.Clone(testonly.PreorderedLogTree).(*trillian.Tree)
+	tree.TreeId = 11
+
+	userSpec := func(user string) quota.Spec {
+		return quota.Spec{Group: quota.User, Kind: quota.Write, User: user}
+	}
+	specs := []quota.Spec{
+		userSpec("frontend"),
+		{Group: quota.Tree, Kind: quota.Write, TreeID: tree.TreeId},
+		{Group: quota.Global, Kind: quota.Write, Refundable: true},
+	}
+	leaves := []*trillian.LogLeaf{{LeafIndex: 0}, {LeafIndex: 1}, {LeafIndex: 2}}
+	req := &trillian.AddSequencedLeavesRequest{
+		LogId:    tree.TreeId,
+		Leaves:   leaves,
+		ChargeTo: &trillian.ChargeTo{User: []string{"frontend"}},
+		LeafChargeTo: []*trillian.ChargeTo{
+			{User: []string{"alpaca"}},
+			{User: []string{"llama"}},
+			{User: []string{"alpaca"}},
+		},
+	}
+	type tokens struct {
+		n     int
+		specs []quota.Spec
+		err   error
+	}
+	for _, test := range []struct {
+		desc        string
+		req         *trillian.AddSequencedLeavesRequest
+		resp        *trillian.AddSequencedLeavesResponse
+		wantGets    []tokens
+		wantPuts    []tokens
+		wantCode    codes.Code
+		wantHandled bool
+	}{
+		{
+			desc: "charged",
+			req:  req,
+			resp: &trillian.AddSequencedLeavesResponse{Results: []*trillian.QueuedLogLeaf{
+				{}, {Status: status.New(codes.Unavailable, "storage unavailable").Proto()}, {},
+			}},
+			wantGets: []tokens{
+				{n: 3, specs: specs},
+				{n: 2, specs: []quota.Spec{userSpec("alpaca")}},
+				{n: 1, specs: []quota.Spec{userSpec("llama")}},
+			},
+			// Only the leaf which failed due to storage is refunded.
+			wantPuts: []tokens{
+				{n: 1, specs: specs},
+				{n: 1, specs: []quota.Spec{userSpec("llama")}},
+			},
+			wantHandled: true,
+		},
+		{
+			desc: "leafQuotaExhausted",
+			req:  req,
+			wantGets: []tokens{
+				{n: 3, specs: specs},
+				{n: 2, specs: []quota.Spec{userSpec("alpaca")}},
+				{n: 1, specs: []quota.Spec{userSpec("llama")}, err: errors.New("not enough tokens")},
+			},
+			wantPuts: []tokens{
+				{n: 3, specs: specs},
+				{n: 2, specs: []quota.Spec{userSpec("alpaca")}},
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			desc: "mismatchedLeafChargeTo",
+			req: &trillian.AddSequencedLeavesRequest{
+				LogId:        tree.TreeId,
+				Leaves:       leaves,
+				LeafChargeTo: []*trillian.ChargeTo{{User: []string{"alpaca"}}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			qm := quota.NewMockManager(ctrl)
+			var calls []*gomock.Call
+			for _, g := range test.wantGets {
+				calls = append(calls, qm.EXPECT().GetTokens(gomock.Any(), g.n, g.specs).Return(g.err))
+			}
+			gomock.InOrder(calls...)
+			putTokensCh := make(chan bool, len(test.wantPuts))
+			for _, p := range test.wantPuts {
+				qm.EXPECT().PutTokens(gomock.Any(), p.n, p.specs).Do(func(context.Context, int, []quota.Spec) {
+					putTokensCh <- true
+				}).Return(nil)
+			}
+
+			handler := &fakeHandler{resp: test.resp}
+			intercept := New(admin, qm, false /* quotaDryRun */, nil /* mf */)
+			_, err := intercept.UnaryInterceptor(ctx, test.req,
+				&grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/AddSequencedLeaves"},
+				handler.run)
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("UnaryInterceptor() returned err = %v, want code %v", err, test.wantCode)
+			}
+			if handler.called != test.wantHandled {
+				t.Errorf("handler called = %v, want %v", handler.called, test.wantHandled)
+			}
+
+			// PutTokens is delegated to a separate goroutine. Give it some time to complete.
+			for range test.wantPuts {
+				select {
+				case <-putTokensCh:
+				case <-time.After(1 * time.Second):
+				}
 			}
 		})
 	}
 }
 
+func TestTrillianInterceptor_QuotaInterception_ReturnsTokensOnCancel(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+	specs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Write, Refundable: true},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	admin := storage.NewMockAdminStorage(ctrl)
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+	adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	putTokensCh := make(chan bool, 1)
+	qm := quota.NewMockManager(ctrl)
+	// The request is cancelled while tokens are acquired, after which they must be returned.
+	qm.EXPECT().GetTokens(gomock.Any(), 1, specs).Do(func(context.Context, int, []quota.Spec) { cancel() }).Return(nil)
+	qm.EXPECT().PutTokens(gomock.Any(), 1, specs[1:]).Do(func(context.Context, int, []quota.Spec) { putTokensCh <- true }).Return(nil)
+
+	handler := &fakeHandler{resp: &trillian.QueueLeafResponse{}}
+	intercept := New(admin, qm, false /* quotaDryRun */, nil /* mf */)
+	req := &trillian.QueueLeafRequest{LogId: logTree.TreeId, Leaf: &trillian.LogLeaf{}}
+	if _, err := intercept.UnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/QueueLeaf"}, handler.run); !errors.Is(err, context.Canceled) {
+		t.Errorf("UnaryInterceptor() returned err = [%v], want Canceled", err)
+	}
+	if handler.called {
+		t.Error("handler called after cancellation")
+	}
+	select {
+	case <-putTokensCh:
+	case <-time.After(1 * time.Second):
+	}
+}
+
 func TestTrillianInterceptor_NotIntercepted(t *testing.T) {
 	tests := []struct {
 		method string
@@ -758,3 +1194,106 @@ func (f *fakeInterceptor) run(ctx context.Context, req interface{}, info *grpc.U
 	}
 	return handler(context.WithValue(ctx, f.key, f.val), req)
 }
+
+func TestDeadline(t *testing.T) {
+	for _, test := range []struct {
+		desc                string
+		clientTimeout       time.Duration
+		defaultTimeout, max time.Duration
+		wantTimeout         time.Duration // Zero means no deadline.
+	}{
+		{desc: "disabled"},
+		{desc: "disabled-client-deadline", clientTimeout: time.Hour, wantTimeout: time.Hour},
+		{desc: "default", defaultTimeout: time.Minute, max: time.Hour, wantTimeout: time.Minute},
+		{desc: "default-above-max", defaultTimeout: time.Hour, max: time.Minute, wantTimeout: time.Minute},
+		{desc: "max-only", max: time.Minute, wantTimeout: time.Minute},
+		{desc: "client-within-max", clientTimeout: time.Second, defaultTimeout: time.Minute, max: time.Hour, wantTimeout: time.Second},
+		{desc: "client-above-max", clientTimeout: 2 * time.Hour, defaultTimeout: time.Minute, max: time.Hour, wantTimeout: time.Hour},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			if test.clientTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.clientTimeout)
+				defer cancel()
+			}
+			handler := &fakeHandler{}
+			if _, err := Deadline(test.defaultTimeout, test.max)(ctx, "req", &grpc.UnaryServerInfo{}, handler.run); err != nil {
+				t.Fatalf("Deadline() returned error: %v", err)
+			}
+			deadline, ok := handler.ctx.Deadline()
+			if wantOK := test.wantTimeout > 0; ok != wantOK {
+				t.Fatalf("handler has deadline: %v, want %v", ok, wantOK)
+			}
+			if got := time.Until(deadline); ok && (got > test.wantTimeout || got < test.wantTimeout-time.Minute/2) {
+				t.Errorf("handler timeout = %v, want %v", got, test.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var crashes int
+	defer func(old func(string, ...interface{})) { crash = old }(crash)
+	crash = func(string, ...interface{}) { crashes++ }
+
+	recovery := NewRecovery(2, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/GetLatestSignedLogRoot"}
+	req := &trillian.GetLatestSignedLogRootRequest{LogId: 12345}
+	panicky := func(context.Context, interface{}) (interface{}, error) { panic("oops") }
+
+	rsp, err := recovery.UnaryInterceptor(context.Background(), req, info, func(context.Context, interface{}) (interface{}, error) { return "ok", nil })
+	if rsp != "ok" || err != nil {
+		t.Errorf("UnaryInterceptor() = %v, %v, want ok, nil", rsp, err)
+	}
+	rsp, err = recovery.UnaryInterceptor(context.Background(), req, info, panicky)
+	if rsp != nil || status.Code(err) != codes.Internal {
+		t.Errorf("UnaryInterceptor() = %v, %v, want nil, Internal", rsp, err)
+	}
+	if crashes != 0 {
+		t.Errorf("crashed %d times, want 0", crashes)
+	}
+
+	// Panics of stream handlers count towards the same limit.
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/trillian.TrillianLog/WatchSignedLogRoots", IsServerStream: true}
+	err = recovery.StreamInterceptor(nil, &fakeServerStream{ctx: context.Background()}, streamInfo, func(interface{}, grpc.ServerStream) error { panic("oops") })
+	if status.Code(err) != codes.Internal {
+		t.Errorf("StreamInterceptor() = %v, want Internal", err)
+	}
+	if crashes != 1 {
+		t.Errorf("crashed %d times, want 1", crashes)
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	bandwidth := Bandwidth(nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/QueueLeaf"}
+	req := &trillian.QueueLeafRequest{LogId: 12345, Leaf: &trillian.LogLeaf{LeafValue: []byte("value")}}
+	rsp := &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: &trillian.LogLeaf{LeafValue: []byte("value"), MerkleLeafHash: []byte("hash")}}}
+	in := mtestonly.NewCounterSnapshot(requestBytes, "12345", info.FullMethod)
+	out := mtestonly.NewCounterSnapshot(responseBytes, "12345", info.FullMethod)
+	wantIn, wantOut := proto.Size(req), proto.Size(rsp)
+
+	handler := func(_ context.Context, req interface{}) (interface{}, error) {
+		// Growing the request mustn't change the bytes counted for it.
+		req.(*trillian.QueueLeafRequest).Leaf.MerkleLeafHash = []byte("hash")
+		return rsp, nil
+	}
+	if _, err := bandwidth(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("Bandwidth() = %v", err)
+	}
+	failing := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such leaf")
+	}
+	if _, err := bandwidth(context.Background(), req, info, failing); status.Code(err) != codes.NotFound {
+		t.Fatalf("Bandwidth() = %v, want NotFound", err)
+	}
+	wantIn += proto.Size(req)
+
+	if got := in.Delta(); got != float64(wantIn) {
+		t.Errorf("request bytes = %v, want %v", got, wantIn)
+	}
+	if got := out.Delta(); got != float64(wantOut) {
+		t.Errorf("response bytes = %v, want %v", got, wantOut)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+var (
+	panicCounter monitoring.Counter
+	panicOnce    sync.Once
+
+	// crash is called when the panic limit of a Recovery is reached. It is
+	// a variable for testing.
+	crash = klog.Fatalf
+)
+
+// Recovery provides interceptors which turn panics of the handler into
+// codes.Internal errors, so that a request triggering a bug doesn't take the
+// whole server down. Each panic is counted, and logged with its stack and the
+// method, peer, tree and metadata of the request.
+type Recovery struct {
+	maxPanics int
+	panics    atomic.Int64
+}
+
+// NewRecovery returns a Recovery whose unary and stream interceptors share
+// the same count of panics. If maxPanics is positive, the process crashes on
+// that many panics, as the state of a server which keeps panicking may be too
+// broken to serve.
+func NewRecovery(maxPanics int, mf monitoring.MetricFactory) *Recovery {
+	panicOnce.Do(func() {
+		if mf == nil {
+			mf = monitoring.InertMetricFactory{}
+		}
+		panicCounter = mf.NewCounter("interceptor_panics", "Number of requests whose handler panicked, by method", "method")
+	})
+	return &Recovery{maxPanics: maxPanics}
+}
+
+// UnaryInterceptor recovers from panics of unary RPC handlers.
+func (r *Recovery) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (rsp interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			rsp, err = nil, r.handle(ctx, info.FullMethod, req, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor recovers from panics of streaming RPC handlers. The
+// request which opened the stream isn't known to it, so only the peer and
+// metadata of the stream are logged.
+func (r *Recovery) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = r.handle(ss.Context(), info.FullMethod, nil, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// handle counts and logs the panic p of a handler of method, crashing if the
+// limit is reached, and returns the error to send to the client.
+func (r *Recovery) handle(ctx context.Context, method string, req, p interface{}) error {
+	panicCounter.Inc(method)
+	klog.Errorf("Panic in %s (%s): %v\n%s", method, describeRequest(ctx, req), p, debug.Stack())
+	if n := r.panics.Add(1); r.maxPanics > 0 && n >= int64(r.maxPanics) {
+		crash("Exiting after %d panics in RPC handlers", n)
+	}
+	return status.Errorf(codes.Internal, "internal error in %s", method)
+}
+
+// describeRequest returns the peer, tree and metadata of a request, for
+// logging.
+func describeRequest(ctx context.Context, req interface{}) string {
+	var parts []string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		parts = append(parts, "peer="+p.Addr.String())
+	}
+	switch r := req.(type) {
+	case logIDRequest:
+		parts = append(parts, fmt.Sprintf("tree=%d", r.GetLogId()))
+	case treeIDRequest:
+		parts = append(parts, fmt.Sprintf("tree=%d", r.GetTreeId()))
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{"user-agent", "x-forwarded-for"} {
+			if v := md.Get(key); len(v) > 0 {
+				parts = append(parts, fmt.Sprintf("%s=%q", key, strings.Join(v, ",")))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -15,35 +15,58 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/leafvalidators"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
 	"github.com/transparency-dev/merkle"
+	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/proof"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
 
 // TODO: There is no access control in the server yet and clients could easily modify
 // any tree.
 
-const traceSpanRoot = "/trillian"
+const (
+	traceSpanRoot = "/trillian"
+
+	// defaultWatchInterval is how often WatchSignedLogRoots checks storage for
+	// a new log root.
+	defaultWatchInterval = time.Second
+
+	// sizeCacheInterval is how long the count of leaves of a tree with a
+	// maximum size is cached for by QueueLeaf.
+	sizeCacheInterval = time.Second
+
+	// defaultRootPageSize is the number of roots returned by
+	// ListSignedLogRoots if the request doesn't set a page size.
+	defaultRootPageSize = 100
+	// maxRootPageSize caps the page size of ListSignedLogRoots.
+	maxRootPageSize = 1000
+)
 
 var (
 	optsLogInit            = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
 	optsLogRead            = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
 	optsLogWrite           = trees.NewGetOpts(trees.QueueLog, trillian.TreeType_LOG)
 	optsPreorderedLogWrite = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_PREORDERED_LOG)
+	optsLogUpdate          = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
 )
 
 // TrillianLogRPCServer implements the RPC API defined in the proto
@@ -53,17 +76,43 @@ type TrillianLogRPCServer struct {
 	leafCounter           monitoring.Counter
 	proofIndexPercentiles monitoring.Histogram
 	fetchedLeaves         monitoring.Counter
+	watchInterval         time.Duration
+	watchersMu            sync.Mutex
+	watchers              map[int64]*rootWatcher
+	sizesMu               sync.Mutex
+	sizes                 map[int64]*treeSize
+	// proofs builds the proofs, or is nil if WithProofWorkers is not used.
+	proofs *proofPool
+}
+
+// LogServerOption configures a TrillianLogRPCServer.
+type LogServerOption func(*logServerOptions)
+
+// logServerOptions holds the settings made by LogServerOptions.
+type logServerOptions struct {
+	proofWorkers int
 }
 
 // NewTrillianLogRPCServer creates a new RPC server backed by a LogStorageProvider.
-func NewTrillianLogRPCServer(registry extension.Registry, timeSource clock.TimeSource) *TrillianLogRPCServer {
+func NewTrillianLogRPCServer(registry extension.Registry, timeSource clock.TimeSource, opts ...LogServerOption) *TrillianLogRPCServer {
+	var o logServerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	mf := registry.MetricFactory
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
+	var proofs *proofPool
+	if o.proofWorkers > 0 {
+		proofs = newProofPool(o.proofWorkers, mf)
+	}
 	return &TrillianLogRPCServer{
-		registry:   registry,
-		timeSource: timeSource,
+		registry:      registry,
+		timeSource:    timeSource,
+		watchInterval: defaultWatchInterval,
+		watchers:      make(map[int64]*rootWatcher),
+		sizes:         make(map[int64]*treeSize),
 		leafCounter: mf.NewCounter(
 			"added_leaves",
 			"Number of leaves requested to be added",
@@ -79,6 +128,7 @@ func NewTrillianLogRPCServer(registry extension.Registry, timeSource clock.TimeS
 			"Count of individual leaves fetched through GetLeaves* calls",
 			"logid",
 		),
+		proofs: proofs,
 	}
 }
 
@@ -102,9 +152,16 @@ func (t *TrillianLogRPCServer) QueueLeaf(ctx context.Context, req *trillian.Queu
 		return nil, err
 	}
 
-	req.Leaf.MerkleLeafHash = hasher.HashLeaf(req.Leaf.LeafValue)
-	if len(req.Leaf.LeafIdentityHash) == 0 {
-		req.Leaf.LeafIdentityHash = req.Leaf.MerkleLeafHash
+	if err := leafvalidators.Validate(ctx, tree, req.Leaf); err != nil {
+		return nil, err
+	}
+
+	if err := hashLeaf(tree, req.Leaf, hasher, "QueueLeafRequest.Leaf"); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkMaxTreeSize(ctx, tree, req.Leaf); err != nil {
+		return nil, err
 	}
 
 	ret, err := t.registry.QueueLeaves(trees.NewContext(ctx, tree), tree, []*trillian.LogLeaf{req.Leaf}, t.timeSource.Now())
@@ -123,6 +180,7 @@ func (t *TrillianLogRPCServer) QueueLeaf(ctx context.Context, req *trillian.Queu
 	label := strconv.FormatInt(req.LogId, 10)
 	if s := ret[0].Status; s == nil || s.Code == int32(codes.OK) {
 		t.leafCounter.Inc(label, "inserted")
+		t.addQueued(tree.TreeId, 1)
 	} else {
 		t.leafCounter.Inc(label, "skipped")
 	}
@@ -130,13 +188,30 @@ func (t *TrillianLogRPCServer) QueueLeaf(ctx context.Context, req *trillian.Queu
 	return &trillian.QueueLeafResponse{QueuedLeaf: ret[0]}, nil
 }
 
-func hashLeaves(leaves []*trillian.LogLeaf, hasher merkle.LogHasher) {
-	for _, leaf := range leaves {
+// hashLeaf sets the MerkleLeafHash of leaf to the hash of its LeafValue, and
+// its LeafIdentityHash to the same if it is unset. A leaf without a LeafValue
+// is pre-hashed, and keeps its MerkleLeafHash if the tree allows that.
+func hashLeaf(tree *trillian.Tree, leaf *trillian.LogLeaf, hasher merkle.LogHasher, errPrefix string) error {
+	if len(leaf.LeafValue) > 0 {
 		leaf.MerkleLeafHash = hasher.HashLeaf(leaf.LeafValue)
-		if len(leaf.LeafIdentityHash) == 0 {
-			leaf.LeafIdentityHash = leaf.MerkleLeafHash
+	} else if !tree.AllowPrehashedLeaves {
+		return status.Errorf(codes.FailedPrecondition, "%v.LeafValue: empty, and tree %d doesn't allow pre-hashed leaves", errPrefix, tree.TreeId)
+	} else if err := validateLeafHash(leaf.MerkleLeafHash, hasher); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v.MerkleLeafHash: %v", errPrefix, err)
+	}
+	if len(leaf.LeafIdentityHash) == 0 {
+		leaf.LeafIdentityHash = leaf.MerkleLeafHash
+	}
+	return nil
+}
+
+func hashLeaves(tree *trillian.Tree, leaves []*trillian.LogLeaf, hasher merkle.LogHasher, errPrefix string) error {
+	for i, leaf := range leaves {
+		if err := hashLeaf(tree, leaf, hasher, ""); err != nil {
+			return status.Errorf(status.Code(err), "%v.Leaves[%d]%v", errPrefix, i, status.Convert(err).Message())
 		}
 	}
+	return nil
 }
 
 // AddSequencedLeaves submits a batch of sequenced leaves to a pre-ordered log
@@ -153,7 +228,17 @@ func (t *TrillianLogRPCServer) AddSequencedLeaves(ctx context.Context, req *tril
 		return nil, err
 	}
 
-	hashLeaves(req.Leaves, hasher)
+	if maxSize := tree.MaxTreeSize; maxSize > 0 {
+		for _, leaf := range req.Leaves {
+			if leaf.LeafIndex >= maxSize {
+				return nil, status.Errorf(codes.FailedPrecondition, "leaf index %d is beyond the maximum tree size of %d", leaf.LeafIndex, maxSize)
+			}
+		}
+	}
+
+	if err := hashLeaves(tree, req.Leaves, hasher, "AddSequencedLeavesRequest"); err != nil {
+		return nil, err
+	}
 
 	ctx = trees.NewContext(ctx, tree)
 	leaves, err := t.registry.AddSequencedLeaves(ctx, tree, req.Leaves, t.timeSource.Now())
@@ -215,11 +300,16 @@ func (t *TrillianLogRPCServer) GetInclusionProof(ctx context.Context, req *trill
 		return r, nil
 	}
 
-	proof, err := getInclusionProofForLeafIndex(ctx, tx, hasher, uint64(req.TreeSize), uint64(req.LeafIndex))
+	proof, err := t.getInclusionProofForLeafIndex(ctx, tree.TreeId, tx, hasher, uint64(req.TreeSize), uint64(req.LeafIndex))
 	if err != nil {
 		return nil, err
 	}
 	t.recordIndexPercent(req.LeafIndex, root.TreeSize)
+	if req.IncludeProofRoot {
+		if r.ProofRoot, err = getProofRoot(ctx, tx, slr, &root, uint64(req.TreeSize)); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
@@ -277,7 +367,7 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 		if leaf.LeafIndex >= req.TreeSize {
 			continue
 		}
-		proof, err := getInclusionProofForLeafIndex(ctx, tx, hasher, uint64(req.TreeSize), uint64(leaf.LeafIndex))
+		proof, err := t.getInclusionProofForLeafIndex(ctx, tree.TreeId, tx, hasher, uint64(req.TreeSize), uint64(leaf.LeafIndex))
 		if err != nil {
 			return nil, err
 		}
@@ -300,6 +390,74 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 	}, nil
 }
 
+// GetInclusionProofByIdentityHash obtains proofs of inclusion by leaf
+// identity hash, for storage implementations which support looking leaves up
+// that way.
+func (t *TrillianLogRPCServer) GetInclusionProofByIdentityHash(ctx context.Context, req *trillian.GetInclusionProofByIdentityHashRequest) (*trillian.GetInclusionProofByIdentityHashResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetInclusionProofByIdentityHash")
+	defer spanEnd()
+	if err := validateGetInclusionProofByIdentityHashRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	tx, err := t.snapshotForTree(ctx, tree, "GetInclusionProofByIdentityHash")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetInclusionProofByIdentityHash")
+
+	reader, ok := tx.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	leaves, err := reader.GetLeavesByIdentityHash(ctx, [][]byte{req.LeafIdentityHash}, req.OrderBySequence)
+	if err != nil {
+		return nil, err
+	}
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+
+	proofs := make([]*trillian.Proof, 0, len(leaves))
+	for _, leaf := range leaves {
+		// Don't include leaves that aren't in the requested TreeSize.
+		if leaf.LeafIndex >= req.TreeSize {
+			continue
+		}
+		proof, err := t.getInclusionProofForLeafIndex(ctx, tree.TreeId, tx, hasher, uint64(req.TreeSize), uint64(leaf.LeafIndex))
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, proof)
+		t.recordIndexPercent(leaf.LeafIndex, root.TreeSize)
+	}
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetInclusionProofByIdentityHash"); err != nil {
+		return nil, err
+	}
+	if len(proofs) < 1 {
+		return nil, status.Errorf(codes.NotFound,
+			"No leaf found for identity hash: %x in tree size %v", req.LeafIdentityHash, req.TreeSize)
+	}
+
+	return &trillian.GetInclusionProofByIdentityHashResponse{
+		SignedLogRoot: slr,
+		Proof:         proofs,
+	}, nil
+}
+
 // GetConsistencyProof obtains a proof that two versions of the tree are consistent with each
 // other and that the later tree includes all the entries of the prior one. For more details
 // see the example trees in RFC 6962.
@@ -337,7 +495,7 @@ func (t *TrillianLogRPCServer) GetConsistencyProof(ctx context.Context, req *tri
 		return r, nil
 	}
 	// Try to get consistency proof
-	proof, err := tryGetConsistencyProof(ctx, uint64(req.FirstTreeSize), uint64(req.SecondTreeSize), tx, hasher)
+	proof, err := t.tryGetConsistencyProof(ctx, tree.TreeId, uint64(req.FirstTreeSize), uint64(req.SecondTreeSize), tx, hasher)
 	if err != nil {
 		return nil, err
 	}
@@ -351,6 +509,133 @@ func (t *TrillianLogRPCServer) GetConsistencyProof(ctx context.Context, req *tri
 	return r, nil
 }
 
+// GetConsistencyProofByRootHash obtains a consistency proof between two roots
+// of a log, which are looked up by their root hashes among the roots stored
+// for it.
+func (t *TrillianLogRPCServer) GetConsistencyProofByRootHash(ctx context.Context, req *trillian.GetConsistencyProofByRootHashRequest) (*trillian.GetConsistencyProofByRootHashResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetConsistencyProofByRootHash")
+	defer spanEnd()
+	if err := validateGetConsistencyProofByRootHashRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	tx, err := t.snapshotForTree(ctx, tree, "GetConsistencyProofByRootHash")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetConsistencyProofByRootHash")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := tx.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	first, firstRoot, err := getRootByHash(ctx, r, req.FirstRootHash)
+	if err != nil {
+		return nil, err
+	}
+	second, secondRoot, err := getRootByHash(ctx, r, req.SecondRootHash)
+	if err != nil {
+		return nil, err
+	}
+	if firstRoot.TreeSize > secondRoot.TreeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "GetConsistencyProofByRootHashRequest: first root has tree size %d > %d of second root", firstRoot.TreeSize, secondRoot.TreeSize)
+	}
+
+	proof, err := t.tryGetConsistencyProof(ctx, tree.TreeId, firstRoot.TreeSize, secondRoot.TreeSize, tx, hasher)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetConsistencyProofByRootHash"); err != nil {
+		return nil, err
+	}
+	return &trillian.GetConsistencyProofByRootHashResponse{
+		Proof:         proof,
+		FirstRoot:     first,
+		SecondRoot:    second,
+		SignedLogRoot: slr,
+	}, nil
+}
+
+// getRootByHash returns the most recent root stored by r with the given root
+// hash, and its parsed form.
+func getRootByHash(ctx context.Context, r storage.RootHistoryReader, rootHash []byte) (*trillian.SignedLogRoot, *types.LogRootV1, error) {
+	slr, err := r.GetSignedLogRootByHash(ctx, rootHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "Could not read log root: %v", err)
+	}
+	return slr, &root, nil
+}
+
+// GetRangeInclusionProof obtains a proof of inclusion of a contiguous range
+// of leaves, made up of the compact ranges on either side of it.
+func (t *TrillianLogRPCServer) GetRangeInclusionProof(ctx context.Context, req *trillian.GetRangeInclusionProofRequest) (*trillian.GetRangeInclusionProofResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetRangeInclusionProof")
+	defer spanEnd()
+	if err := validateGetRangeInclusionProofRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetRangeInclusionProof")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetRangeInclusionProof")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+
+	r := &trillian.GetRangeInclusionProofResponse{SignedLogRoot: slr}
+	if uint64(req.TreeSize) > root.TreeSize {
+		return r, nil
+	}
+
+	// Both compact ranges are made of perfect subtrees, so their nodes are
+	// all in storage and don't need rehashing.
+	left := compact.RangeNodes(0, uint64(req.StartIndex), nil)
+	ids := compact.RangeNodes(uint64(req.EndIndex), uint64(req.TreeSize), left)
+	nodes, err := fetchNodes(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		if i < len(left) {
+			r.LeftHashes = append(r.LeftHashes, node.Hash)
+		} else {
+			r.RightHashes = append(r.RightHashes, node.Hash)
+		}
+	}
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetRangeInclusionProof"); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 // GetLatestSignedLogRoot obtains the latest published tree root for the Merkle Tree that
 // underlies the log.
 func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
@@ -396,7 +681,7 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 		return nil, err
 	}
 	// Try to get consistency proof
-	proof, err := tryGetConsistencyProof(ctx, uint64(reqProof.FirstTreeSize), uint64(reqProof.SecondTreeSize), tx, hasher)
+	proof, err := t.tryGetConsistencyProof(ctx, tree.TreeId, uint64(reqProof.FirstTreeSize), uint64(reqProof.SecondTreeSize), tx, hasher)
 	if err != nil {
 		return nil, err
 	}
@@ -408,18 +693,267 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 	return r, nil
 }
 
-func tryGetConsistencyProof(ctx context.Context, firstTreeSize, secondTreeSize uint64, tx storage.ReadOnlyLogTreeTX, hasher merkle.LogHasher) (*trillian.Proof, error) {
+// ListSignedLogRoots returns a page of the stored roots of a log, oldest
+// first. The page token is the timestamp of the last root of the previous
+// page, after which the listing continues.
+func (t *TrillianLogRPCServer) ListSignedLogRoots(ctx context.Context, req *trillian.ListSignedLogRootsRequest) (*trillian.ListSignedLogRootsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "ListSignedLogRoots")
+	defer spanEnd()
+	if err := validateListSignedLogRootsRequest(req); err != nil {
+		return nil, err
+	}
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize == 0:
+		pageSize = defaultRootPageSize
+	case pageSize > maxRootPageSize:
+		pageSize = maxRootPageSize
+	}
+	rng := storage.RootRange{
+		StartTreeSize: uint64(req.StartTreeSize),
+		EndTreeSize:   uint64(req.EndTreeSize),
+	}
+	if req.StartTime != nil {
+		rng.StartTimestampNanos = timestampNanos(req.StartTime.AsTime())
+	}
+	if req.EndTime != nil {
+		if rng.EndTimestampNanos = timestampNanos(req.EndTime.AsTime()); rng.EndTimestampNanos == 0 {
+			// There are no roots before the epoch.
+			return &trillian.ListSignedLogRootsResponse{}, nil
+		}
+	}
+	if req.PageToken != "" {
+		last, err := strconv.ParseUint(req.PageToken, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.PageToken: %q is not a page token", req.PageToken)
+		}
+		if last >= rng.StartTimestampNanos {
+			rng.StartTimestampNanos = last + 1
+		}
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "ListSignedLogRoots")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "ListSignedLogRoots")
+
+	r, ok := tx.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	roots, err := r.ListSignedLogRoots(ctx, rng, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, req.LogId, tx, "ListSignedLogRoots"); err != nil {
+		return nil, err
+	}
+
+	resp := &trillian.ListSignedLogRootsResponse{SignedLogRoots: roots}
+	if len(roots) == pageSize {
+		var last types.LogRootV1
+		if err := last.UnmarshalBinary(roots[len(roots)-1].LogRoot); err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not read log root: %v", err)
+		}
+		resp.NextPageToken = strconv.FormatUint(last.TimestampNanos, 10)
+	}
+	return resp, nil
+}
+
+// timestampNanos returns the nanoseconds since the epoch of ts, or zero if
+// it is before the epoch.
+func timestampNanos(ts time.Time) uint64 {
+	if n := ts.UnixNano(); n > 0 {
+		return uint64(n)
+	}
+	return 0
+}
+
+// GetTreeStats returns cheap freshness data about a tree, read in a single
+// snapshot. The unsequenced count is -1 if the storage can't count the queue.
+func (t *TrillianLogRPCServer) GetTreeStats(ctx context.Context, req *trillian.GetTreeStatsRequest) (*trillian.GetTreeStatsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetTreeStats")
+	defer spanEnd()
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetTreeStats")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetTreeStats")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	r := &trillian.GetTreeStatsResponse{
+		TreeSize:         root.TreeSize,
+		RootTimestamp:    timestamppb.New(time.Unix(0, int64(root.TimestampNanos))),
+		UnsequencedCount: -1,
+	}
+
+	if c, ok := tx.(storage.UnsequencedCounter); ok {
+		// Storage wrappers implement the interface whatever they wrap.
+		count, err := c.CountUnsequenced(ctx)
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return nil, err
+		}
+		if err == nil {
+			r.UnsequencedCount = count
+		}
+	}
+	if root.TreeSize > 0 {
+		leaves, err := tx.GetLeavesByRange(ctx, int64(root.TreeSize)-1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(leaves) != 1 {
+			return nil, status.Errorf(codes.Internal, "leaf %d is missing from storage", root.TreeSize-1)
+		}
+		r.LastIntegrationTimestamp = leaves[0].IntegrateTimestamp
+	}
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetTreeStats"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetSequencedLeafCount returns the size of a tree, and the range of indices
+// at which its leaves are stored, read in a single snapshot.
+func (t *TrillianLogRPCServer) GetSequencedLeafCount(ctx context.Context, req *trillian.GetSequencedLeafCountRequest) (*trillian.GetSequencedLeafCountResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetSequencedLeafCount")
+	defer spanEnd()
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetSequencedLeafCount")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetSequencedLeafCount")
+
+	r, ok := tx.(storage.SequencedIndexReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support finding the range of sequenced leaves")
+	}
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	first, last, err := r.GetSequencedIndexRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetSequencedLeafCount"); err != nil {
+		return nil, err
+	}
+	return &trillian.GetSequencedLeafCountResponse{
+		TreeSize:   root.TreeSize,
+		FirstIndex: first,
+		LastIndex:  last,
+	}, nil
+}
+
+func (t *TrillianLogRPCServer) tryGetConsistencyProof(ctx context.Context, treeID int64, firstTreeSize, secondTreeSize uint64, tx storage.ReadOnlyLogTreeTX, hasher merkle.LogHasher) (*trillian.Proof, error) {
 	nodes, err := proof.Consistency(firstTreeSize, secondTreeSize)
 	if err != nil {
 		return nil, err
 	}
-	proof, err := fetchNodesAndBuildProof(ctx, tx, hasher.HashChildren, 0, nodes)
+	proof, err := t.proofs.build(ctx, treeID, tx, hasher.HashChildren, 0, nodes)
 	if err != nil {
 		return nil, err
 	}
 	return proof, nil
 }
 
+// WatchSignedLogRoots streams the log roots of a tree to the client as they
+// are published, until the client goes away.
+func (t *TrillianLogRPCServer) WatchSignedLogRoots(req *trillian.WatchSignedLogRootsRequest, stream trillian.TrillianLog_WatchSignedLogRootsServer) error {
+	ctx, spanEnd := spanFor(stream.Context(), "WatchSignedLogRoots")
+	defer spanEnd()
+	if req.FirstTreeSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "WatchSignedLogRootsRequest.FirstTreeSize: %v, want >= 0", req.FirstTreeSize)
+	}
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return err
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	w := t.watchRoots(tree)
+	defer t.unwatchRoots(tree.TreeId, w)
+	size := uint64(req.FirstTreeSize)
+	var lastRoot []byte
+	for {
+		slr, changed, err := w.latest()
+		if err != nil {
+			return err
+		}
+		if slr != nil && !bytes.Equal(slr.GetLogRoot(), lastRoot) {
+			var root types.LogRootV1
+			if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+				return status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+			}
+			// Roots smaller than the client's last-known size are held back
+			// until this server has caught up.
+			if root.TreeSize >= size {
+				r := &trillian.WatchSignedLogRootsResponse{SignedLogRoot: slr}
+				if req.FirstTreeSize > 0 {
+					if r.Proof, err = t.watchProof(ctx, tree, hasher, size, root.TreeSize); err != nil {
+						return err
+					}
+				}
+				if err := stream.Send(r); err != nil {
+					return err
+				}
+				lastRoot = slr.GetLogRoot()
+				size = root.TreeSize
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-changed:
+		}
+	}
+}
+
+// watchProof returns a consistency proof between the given tree sizes for
+// WatchSignedLogRoots.
+func (t *TrillianLogRPCServer) watchProof(ctx context.Context, tree *trillian.Tree, hasher merkle.LogHasher, firstTreeSize, secondTreeSize uint64) (*trillian.Proof, error) {
+	tx, err := t.snapshotForTree(ctx, tree, "WatchSignedLogRoots")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "WatchSignedLogRoots")
+	proof, err := t.tryGetConsistencyProof(ctx, tree.TreeId, firstTreeSize, secondTreeSize, tx, hasher)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "WatchSignedLogRoots"); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
 // GetLeavesByRange obtains leaves based on a range of sequence numbers within the tree.
 // This only fetches sequenced leaves; leaves that have been queued but not yet integrated
 // are not visible.
@@ -468,6 +1002,159 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	return r, nil
 }
 
+// GetLeavesByIndices obtains leaves with arbitrary indices, in the order they
+// were requested.
+func (t *TrillianLogRPCServer) GetLeavesByIndices(ctx context.Context, req *trillian.GetLeavesByIndicesRequest) (*trillian.GetLeavesByIndicesResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetLeavesByIndices")
+	defer spanEnd()
+	if err := validateGetLeavesByIndicesRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByIndices")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeavesByIndices")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+
+	// Only fetch each leaf once, however many times it was requested.
+	indices := make([]int64, 0, len(req.LeafIndex))
+	byIndex := make(map[int64]*trillian.LogLeaf, len(req.LeafIndex))
+	for _, index := range req.LeafIndex {
+		if uint64(index) >= root.TreeSize {
+			return nil, status.Errorf(codes.OutOfRange, "leaf index %d is beyond tree size %d", index, root.TreeSize)
+		}
+		if _, ok := byIndex[index]; !ok {
+			byIndex[index] = nil
+			indices = append(indices, index)
+		}
+	}
+	leaves, err := storage.GetLeavesByIndices(ctx, tx, indices)
+	if err != nil {
+		return nil, err
+	}
+	for _, leaf := range leaves {
+		byIndex[leaf.LeafIndex] = leaf
+	}
+
+	r := &trillian.GetLeavesByIndicesResponse{SignedLogRoot: slr, Leaves: make([]*trillian.LogLeaf, 0, len(req.LeafIndex))}
+	for _, index := range req.LeafIndex {
+		leaf := byIndex[index]
+		if leaf == nil {
+			return nil, status.Errorf(codes.Internal, "leaf %d is missing from storage", index)
+		}
+		r.Leaves = append(r.Leaves, leaf)
+	}
+	t.fetchedLeaves.Add(float64(len(leaves)), strconv.FormatInt(req.LogId, 10))
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetLeavesByIndices"); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UpdateLeafExtraData replaces the ExtraData of an integrated leaf, keeping
+// the previous value in the storage's audit trail.
+func (t *TrillianLogRPCServer) UpdateLeafExtraData(ctx context.Context, req *trillian.UpdateLeafExtraDataRequest) (*trillian.UpdateLeafExtraDataResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "UpdateLeafExtraData")
+	defer spanEnd()
+	if err := validateUpdateLeafExtraDataRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !tree.MutableExtraData {
+		return nil, status.Errorf(codes.FailedPrecondition, "log %d does not allow ExtraData to be updated", tree.TreeId)
+	}
+
+	var leaf *trillian.LogLeaf
+	err = t.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		updater, ok := tx.(storage.ExtraDataUpdater)
+		if !ok {
+			return status.Error(codes.Unimplemented, "storage does not support updating ExtraData")
+		}
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+		}
+		if uint64(req.LeafIndex) >= root.TreeSize {
+			return status.Errorf(codes.OutOfRange, "leaf index %d is beyond tree size %d", req.LeafIndex, root.TreeSize)
+		}
+		leaf, err = updater.UpdateLeafExtraData(ctx, req.LeafIndex, req.ExtraData, req.Reason, t.timeSource.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("%d: updated ExtraData of leaf %d: %q", tree.TreeId, req.LeafIndex, req.Reason)
+	return &trillian.UpdateLeafExtraDataResponse{Leaf: leaf}, nil
+}
+
+// RedactLeaf replaces the LeafValue of an integrated leaf with a tombstone,
+// keeping its Merkle leaf hash so that the tree is unchanged.
+func (t *TrillianLogRPCServer) RedactLeaf(ctx context.Context, req *trillian.RedactLeafRequest) (*trillian.RedactLeafResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "RedactLeaf")
+	defer spanEnd()
+	if err := validateRedactLeafRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !tree.AllowRedaction {
+		return nil, status.Errorf(codes.FailedPrecondition, "log %d does not allow leaves to be redacted", tree.TreeId)
+	}
+
+	var leaf *trillian.LogLeaf
+	err = t.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		redactor, ok := tx.(storage.LeafRedactor)
+		if !ok {
+			return status.Error(codes.Unimplemented, "storage does not support redacting leaves")
+		}
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+		}
+		if uint64(req.LeafIndex) >= root.TreeSize {
+			return status.Errorf(codes.OutOfRange, "leaf index %d is beyond tree size %d", req.LeafIndex, root.TreeSize)
+		}
+		leaf, err = redactor.RedactLeaf(ctx, req.LeafIndex, req.Reason, t.timeSource.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("%d: redacted leaf %d: %q", tree.TreeId, req.LeafIndex, req.Reason)
+	return &trillian.RedactLeafResponse{Leaf: leaf}, nil
+}
+
 // GetEntryAndProof returns both a Merkle Leaf entry and an inclusion proof for a given index
 // and tree size.
 func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
@@ -503,13 +1190,25 @@ func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trilli
 
 	r := &trillian.GetEntryAndProofResponse{SignedLogRoot: slr}
 
-	if req.TreeSize > int64(root.TreeSize) && req.LeafIndex < int64(root.TreeSize) {
-		// return latest proof we can manage
-		req.TreeSize = int64(root.TreeSize)
+	if req.TreeSize > int64(root.TreeSize) {
+		if req.PinTreeSize {
+			return nil, status.Errorf(codes.OutOfRange, "tree size %d is beyond the size %d of the current root", req.TreeSize, root.TreeSize)
+		}
+		if req.LeafIndex < int64(root.TreeSize) {
+			// return latest proof we can manage
+			req.TreeSize = int64(root.TreeSize)
+		} else if len(req.AdditionalLeafIndices) > 0 {
+			return nil, status.Errorf(codes.OutOfRange, "leaf index %d is beyond tree size %d", req.LeafIndex, root.TreeSize)
+		}
+	}
+	for _, index := range req.AdditionalLeafIndices {
+		if index >= req.TreeSize {
+			return nil, status.Errorf(codes.OutOfRange, "leaf index %d is beyond tree size %d", index, req.TreeSize)
+		}
 	}
 
 	if req.TreeSize <= int64(root.TreeSize) {
-		proof, err := getInclusionProofForLeafIndex(ctx, tx, hasher, uint64(req.TreeSize), uint64(req.LeafIndex))
+		proof, err := t.getInclusionProofForLeafIndex(ctx, tree.TreeId, tx, hasher, uint64(req.TreeSize), uint64(req.LeafIndex))
 		if err != nil {
 			return nil, err
 		}
@@ -525,10 +1224,21 @@ func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trilli
 		}
 
 		t.recordIndexPercent(req.LeafIndex, root.TreeSize)
+		if req.IncludeProofRoot {
+			if r.ProofRoot, err = getProofRoot(ctx, tx, slr, &root, uint64(req.TreeSize)); err != nil {
+				return nil, err
+			}
+		}
 
 		// Work is complete, we have everything we need for the response
 		r.Proof = proof
 		r.Leaf = leaves[0]
+
+		if len(req.AdditionalLeafIndices) > 0 {
+			if r.AdditionalEntries, err = t.getEntriesAndProofs(ctx, tree.TreeId, tx, hasher, uint64(req.TreeSize), req.AdditionalLeafIndices); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -553,15 +1263,193 @@ func (t *TrillianLogRPCServer) closeAndLog(ctx context.Context, logID int64, tx
 	}
 }
 
+// getEntriesAndProofs returns the leaves with the given indices, which must be
+// less than size, with their inclusion proofs to size, in the order of the
+// indices. Each leaf and proof is only read once, however many times its
+// index is given.
+func (t *TrillianLogRPCServer) getEntriesAndProofs(ctx context.Context, treeID int64, tx storage.ReadOnlyLogTreeTX, hasher merkle.LogHasher, size uint64, indices []int64) ([]*trillian.EntryAndProof, error) {
+	unique := make([]int64, 0, len(indices))
+	byIndex := make(map[int64]*trillian.EntryAndProof, len(indices))
+	for _, index := range indices {
+		if _, ok := byIndex[index]; !ok {
+			byIndex[index] = &trillian.EntryAndProof{}
+			unique = append(unique, index)
+		}
+	}
+	leaves, err := storage.GetLeavesByIndices(ctx, tx, unique)
+	if err != nil {
+		return nil, err
+	}
+	for _, leaf := range leaves {
+		if e := byIndex[leaf.LeafIndex]; e != nil {
+			e.Leaf = leaf
+		}
+	}
+	for _, index := range unique {
+		e := byIndex[index]
+		if e.Leaf == nil {
+			return nil, status.Errorf(codes.Internal, "leaf %d is missing from storage", index)
+		}
+		if e.Proof, err = t.getInclusionProofForLeafIndex(ctx, treeID, tx, hasher, size, uint64(index)); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]*trillian.EntryAndProof, 0, len(indices))
+	for _, index := range indices {
+		entries = append(entries, byIndex[index])
+	}
+	return entries, nil
+}
+
+// getProofRoot returns the root of the tree with the given size, which is at
+// most that of the latest root, or nil if there is no such root. Earlier roots
+// can only be returned if the storage keeps them.
+func getProofRoot(ctx context.Context, tx storage.ReadOnlyLogTreeTX, slr *trillian.SignedLogRoot, root *types.LogRootV1, size uint64) (*trillian.SignedLogRoot, error) {
+	if size == root.TreeSize {
+		return slr, nil
+	}
+	r, ok := tx.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	proofRoot, err := r.GetSignedLogRootBySize(ctx, size)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	return proofRoot, err
+}
+
 // getInclusionProofForLeafIndex is used by multiple handlers. It does the storage fetching
 // and makes additional checks on the returned proof. Returns a Proof suitable for inclusion in
 // an RPC response
-func getInclusionProofForLeafIndex(ctx context.Context, tx storage.ReadOnlyLogTreeTX, hasher merkle.LogHasher, size, leafIndex uint64) (*trillian.Proof, error) {
+func (t *TrillianLogRPCServer) getInclusionProofForLeafIndex(ctx context.Context, treeID int64, tx storage.ReadOnlyLogTreeTX, hasher merkle.LogHasher, size, leafIndex uint64) (*trillian.Proof, error) {
 	nodes, err := proof.Inclusion(leafIndex, size)
 	if err != nil {
 		return nil, err
 	}
-	return fetchNodesAndBuildProof(ctx, tx, hasher.HashChildren, leafIndex, nodes)
+	return t.proofs.build(ctx, treeID, tx, hasher.HashChildren, leafIndex, nodes)
+}
+
+// treeSize is a cached count of the leaves of a tree with a maximum size,
+// integrated or queued.
+type treeSize struct {
+	leaves uint64
+	// read is when the count was read from storage. Leaves queued through
+	// this server since then are added to it.
+	read time.Time
+}
+
+// checkMaxTreeSize returns a FailedPrecondition error if the tree has a
+// maximum size which its leaves, together with those queued for it, have
+// already reached, unless leaf is already integrated. Otherwise the leaves
+// queued beyond the maximum size would never be integrated.
+//
+// The count of leaves is read from storage at most every sizeCacheInterval,
+// so other servers may queue a few leaves beyond the maximum before this one
+// notices. Those are left in the queue by the signer. Duplicates of leaves
+// which are still queued are rejected until they are integrated.
+func (t *TrillianLogRPCServer) checkMaxTreeSize(ctx context.Context, tree *trillian.Tree, leaf *trillian.LogLeaf) error {
+	maxSize := tree.MaxTreeSize
+	if maxSize <= 0 {
+		return nil
+	}
+	ctx = trees.NewContext(ctx, tree)
+	size, err := t.treeSize(ctx, tree)
+	if err != nil {
+		return err
+	}
+	if size < uint64(maxSize) {
+		return nil
+	}
+	// A duplicate doesn't grow the tree, so it is passed on to QueueLeaves,
+	// which returns the leaf already in the tree.
+	if integrated, err := t.isIntegrated(ctx, tree, leaf.LeafIdentityHash); err != nil {
+		return err
+	} else if integrated {
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition, "log %d has reached its maximum size of %d, counting the leaves queued for it", tree.TreeId, maxSize)
+}
+
+// treeSize returns the number of leaves of the tree, integrated or queued,
+// reading it from storage if the cached count is older than
+// sizeCacheInterval.
+func (t *TrillianLogRPCServer) treeSize(ctx context.Context, tree *trillian.Tree) (uint64, error) {
+	now := t.timeSource.Now()
+	t.sizesMu.Lock()
+	if ts, ok := t.sizes[tree.TreeId]; ok && now.Sub(ts.read) < sizeCacheInterval {
+		t.sizesMu.Unlock()
+		return ts.leaves, nil
+	}
+	t.sizesMu.Unlock()
+
+	tx, err := t.snapshotForTree(ctx, tree, "treeSize")
+	if err != nil {
+		return 0, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "treeSize")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return 0, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	leaves := root.TreeSize
+	if c, ok := tx.(storage.UnsequencedCounter); ok {
+		// Storage wrappers implement the interface whatever they wrap.
+		count, err := c.CountUnsequenced(ctx)
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			return 0, err
+		}
+		if err == nil {
+			leaves += uint64(count)
+		}
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "treeSize"); err != nil {
+		return 0, err
+	}
+
+	t.sizesMu.Lock()
+	t.sizes[tree.TreeId] = &treeSize{leaves: leaves, read: now}
+	t.sizesMu.Unlock()
+	return leaves, nil
+}
+
+// addQueued adds n newly queued leaves to the cached size of a tree.
+func (t *TrillianLogRPCServer) addQueued(treeID int64, n int) {
+	t.sizesMu.Lock()
+	defer t.sizesMu.Unlock()
+	if ts, ok := t.sizes[treeID]; ok {
+		ts.leaves += uint64(n)
+	}
+}
+
+// isIntegrated returns whether a leaf with the given identity hash is
+// integrated into the tree. It returns false if the storage can't look leaves
+// up by identity hash.
+func (t *TrillianLogRPCServer) isIntegrated(ctx context.Context, tree *trillian.Tree, identityHash []byte) (bool, error) {
+	tx, err := t.snapshotForTree(ctx, tree, "isIntegrated")
+	if err != nil {
+		return false, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "isIntegrated")
+
+	reader, ok := tx.(storage.IdentityHashReader)
+	if !ok {
+		return false, nil
+	}
+	leaves, err := reader.GetLeavesByIdentityHash(ctx, [][]byte{identityHash}, false)
+	if err != nil {
+		return false, err
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "isIntegrated"); err != nil {
+		return false, err
+	}
+	return len(leaves) > 0, nil
 }
 
 func (t *TrillianLogRPCServer) getTreeAndHasher(ctx context.Context, treeID int64, opts trees.GetOpts) (*trillian.Tree, merkle.LogHasher, error) {
@@ -569,7 +1457,11 @@ func (t *TrillianLogRPCServer) getTreeAndHasher(ctx context.Context, treeID int6
 	if err != nil {
 		return nil, nil, err
 	}
-	return tree, rfc6962.DefaultHasher, nil
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "%d: %v", treeID, err)
+	}
+	return tree, hasher, nil
 }
 
 func (t *TrillianLogRPCServer) getTreeAndContext(ctx context.Context, treeID int64, opts trees.GetOpts) (*trillian.Tree, context.Context, error) {
@@ -15,7 +15,9 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strconv"
@@ -25,22 +27,32 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/trillian"
+	"github.com/google/trillian/client"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/leafvalidators"
+	"github.com/google/trillian/log"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/testonly"
+	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
 	"github.com/transparency-dev/merkle/compact"
 	"github.com/transparency-dev/merkle/rfc6962"
+	inmemory "github.com/transparency-dev/merkle/testonly"
 	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // cmpMatcher is a custom gomock.Matcher that uses cmp.Equal combined with a
@@ -414,6 +426,18 @@ func TestAddSequencedLeavesInvalidLogId(t *testing.T) {
 	test.executeInvalidLogIDTest(t, false /* snapshot */)
 }
 
+func TestAddSequencedLeavesMismatchedLeafChargeTo(t *testing.T) {
+	server := NewTrillianLogRPCServer(extension.Registry{}, fakeTimeSource)
+	req := &trillian.AddSequencedLeavesRequest{
+		LogId:        logID3,
+		Leaves:       []*trillian.LogLeaf{leaf1},
+		LeafChargeTo: []*trillian.ChargeTo{{User: []string{"alpaca"}}, {User: []string{"llama"}}},
+	}
+	if _, err := server.AddSequencedLeaves(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("AddSequencedLeaves() = %v, want InvalidArgument", err)
+	}
+}
+
 func TestAddSequencedLeaves(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -446,6 +470,152 @@ func TestAddSequencedLeaves(t *testing.T) {
 	}
 }
 
+func TestMaxTreeSize(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		desc        string
+		maxTreeSize int64
+		wantCode    codes.Code
+	}{
+		{desc: "unlimited"},
+		{desc: "below-limit", maxTreeSize: 8},
+		{desc: "at-limit", maxTreeSize: 7, wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tree := proto.Clone(tree1).(*trillian.Tree)
+			tree.MaxTreeSize = test.maxTreeSize
+			adminStorage := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			adminStorage.EXPECT().Snapshot(gomock.Any()).Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, nil)
+			adminTX.EXPECT().Close().Return(nil)
+			adminTX.EXPECT().Commit().Return(nil)
+
+			mockStorage := storage.NewMockLogStorage(ctrl)
+			if test.maxTreeSize > 0 {
+				mockTX := storage.NewMockLogTreeTX(ctrl)
+				mockStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree}).Return(mockTX, nil)
+				mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(signedRoot1, nil)
+				mockTX.EXPECT().Commit(gomock.Any()).Return(nil)
+				mockTX.EXPECT().Close().Return(nil)
+			}
+			if test.wantCode != codes.OK {
+				// The leaf is looked up in case it is a duplicate, which the
+				// mock storage can't do.
+				lookupTX := storage.NewMockLogTreeTX(ctrl)
+				mockStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree}).Return(lookupTX, nil)
+				lookupTX.EXPECT().Close().Return(nil)
+			}
+			if test.wantCode == codes.OK {
+				mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree}, gomock.Any(), fakeTime).Return([]*trillian.QueuedLogLeaf{okQueuedLeaf(leaf1)}, nil)
+			}
+
+			registry := extension.Registry{
+				AdminStorage: adminStorage,
+				LogStorage:   mockStorage,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+			req := &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: proto.Clone(leaf1).(*trillian.LogLeaf)}
+			_, err := server.QueueLeaf(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("QueueLeaf()=%v, want code %v", err, test.wantCode)
+			}
+		})
+	}
+}
+
+// countingLogStorage is a storage.LogStorage which counts the snapshots
+// taken of it.
+type countingLogStorage struct {
+	storage.LogStorage
+	snapshots int
+}
+
+func (s *countingLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	s.snapshots++
+	return s.LogStorage.SnapshotForTree(ctx, tree)
+}
+
+func TestMaxTreeSizeQueued(t *testing.T) {
+	ctx := context.Background()
+	log.InitMetrics(nil)
+	ts := memory.NewTreeStorage()
+	ls := &countingLogStorage{LogStorage: memory.NewLogStorage(ts, nil)}
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   ls,
+		QuotaManager: quota.Noop(),
+	}
+	timeSource := clock.NewFake(time.Now())
+	s := NewTrillianLogRPCServer(registry, timeSource)
+	template := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	template.MaxTreeSize = 2
+	tree := initLog(ctx, t, s, template)
+	queue := func(i int) error {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d", i))}
+		_, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf})
+		return err
+	}
+
+	// Nothing has been integrated, but the queued leaves fill the tree. The
+	// size of the tree is read once, and then counted by the server.
+	ls.snapshots = 0
+	for i, wantCode := range []codes.Code{codes.OK, codes.OK, codes.FailedPrecondition} {
+		if err := queue(i); status.Code(err) != wantCode {
+			t.Errorf("QueueLeaf(%d)=%v, want code %v", i, err, wantCode)
+		}
+	}
+	// The rejected leaf is looked up in case it is a duplicate.
+	if got, want := ls.snapshots, 2; got != want {
+		t.Errorf("QueueLeaf() took %d snapshots, want %d", got, want)
+	}
+
+	if _, err := log.IntegrateBatch(ctx, tree, 2, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	timeSource.Set(timeSource.Now().Add(sizeCacheInterval))
+	// Duplicates of integrated leaves don't grow the tree, so they are let
+	// through to storage.
+	if err := queue(0); err != nil {
+		t.Errorf("QueueLeaf(duplicate)=%v, want nil", err)
+	}
+	if err := queue(2); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("QueueLeaf(2)=%v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestAddSequencedLeavesMaxTreeSize(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree := addTreeID(stestonly.PreorderedLogTree, addSeqRequest0.LogId)
+	tree.MaxTreeSize = 1
+	adminStorage := storage.NewMockAdminStorage(ctrl)
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	adminStorage.EXPECT().Snapshot(gomock.Any()).Return(adminTX, nil)
+	adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, nil)
+	adminTX.EXPECT().Close().Return(nil)
+	adminTX.EXPECT().Commit().Return(nil)
+
+	registry := extension.Registry{
+		AdminStorage: adminStorage,
+		LogStorage:   storage.NewMockLogStorage(ctrl),
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	leaf := proto.Clone(leaf1).(*trillian.LogLeaf)
+	leaf.LeafIndex = 1
+	req := &trillian.AddSequencedLeavesRequest{LogId: tree.TreeId, Leaves: []*trillian.LogLeaf{leaf}}
+	if _, err := server.AddSequencedLeaves(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("AddSequencedLeaves()=%v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
 type latestRootTest struct {
 	desc        string
 	req         *trillian.GetLatestSignedLogRootRequest
@@ -541,6 +711,854 @@ func TestGetLatestSignedLogRoot(t *testing.T) {
 	}
 }
 
+// fakeWatchStream is a TrillianLog_WatchSignedLogRootsServer which passes the
+// responses sent on it to a channel.
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	resps chan *trillian.WatchSignedLogRootsResponse
+}
+
+func (s *fakeWatchStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWatchStream) Send(r *trillian.WatchSignedLogRootsResponse) error {
+	s.resps <- r
+	return nil
+}
+
+// newMemoryLogServer returns a log server backed by fresh in-memory storage.
+func newMemoryLogServer(ts clock.TimeSource) *TrillianLogRPCServer {
+	log.InitMetrics(nil)
+	treeStorage := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(treeStorage),
+		LogStorage:   memory.NewLogStorage(treeStorage, nil),
+		QuotaManager: quota.Noop(),
+	}
+	return NewTrillianLogRPCServer(registry, ts)
+}
+
+// initLog creates a log from template in the storage of s, and initialises
+// it.
+func initLog(ctx context.Context, t *testing.T, s *TrillianLogRPCServer, template *trillian.Tree) *trillian.Tree {
+	t.Helper()
+	tree, err := storage.CreateTree(ctx, s.registry.AdminStorage, template)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if _, err := s.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+	return tree
+}
+
+// integrateLeaf queues leaf through s, and integrates it on its own, so that
+// the leaves of the tree are in a known order and there is a root of each
+// size.
+func integrateLeaf(ctx context.Context, t *testing.T, s *TrillianLogRPCServer, tree *trillian.Tree, leaf *trillian.LogLeaf) {
+	t.Helper()
+	if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf}); err != nil {
+		t.Fatalf("QueueLeaf(): %v", err)
+	}
+	if _, err := log.IntegrateBatch(ctx, tree, 1, 0, 0, clock.System, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+}
+
+// newIntegratedLog returns a log server backed by in-memory storage, and a log
+// in it with n leaves, "leaf-0" onwards, integrated one at a time. It also
+// returns a reference tree of the leaves.
+func newIntegratedLog(ctx context.Context, t *testing.T, n int) (*TrillianLogRPCServer, *trillian.Tree, *inmemory.Tree) {
+	t.Helper()
+	s := newMemoryLogServer(clock.System)
+	tree := initLog(ctx, t, s, stestonly.LogTree)
+	ref := inmemory.New(rfc6962.DefaultHasher)
+	for i := 0; i < n; i++ {
+		data := []byte(fmt.Sprintf("leaf-%d", i))
+		ref.AppendData(data)
+		integrateLeaf(ctx, t, s, tree, &trillian.LogLeaf{LeafValue: data})
+	}
+	return s, tree, ref
+}
+
+func TestWatchSignedLogRoots(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s, tree, ref := newIntegratedLog(ctx, t, 4)
+	s.watchInterval = 10 * time.Millisecond
+	// Grow the log a batch at a time, so that the watcher sees each batch as
+	// one new root.
+	grow := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			data := []byte(fmt.Sprintf("leaf-%d", ref.Size()))
+			ref.AppendData(data)
+			if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: &trillian.LogLeaf{LeafValue: data}}); err != nil {
+				t.Fatalf("QueueLeaf(): %v", err)
+			}
+		}
+		if _, err := log.IntegrateBatch(ctx, tree, n, 0, 0, clock.System, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+	}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	stream := &fakeWatchStream{ctx: watchCtx, resps: make(chan *trillian.WatchSignedLogRootsResponse)}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchSignedLogRoots(&trillian.WatchSignedLogRootsRequest{LogId: tree.TreeId, FirstTreeSize: 2}, stream)
+	}()
+
+	// Each root should arrive with a consistency proof from the previous one.
+	for _, sizes := range [][2]uint64{{2, 4}, {4, 7}} {
+		var r *trillian.WatchSignedLogRootsResponse
+		select {
+		case r = <-stream.resps:
+		case err := <-done:
+			t.Fatalf("WatchSignedLogRoots() returned early: %v", err)
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(r.SignedLogRoot.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		if root.TreeSize != sizes[1] {
+			t.Errorf("WatchSignedLogRoots() sent root of size %d, want %d", root.TreeSize, sizes[1])
+		}
+		want, err := ref.ConsistencyProof(sizes[0], sizes[1])
+		if err != nil {
+			t.Fatalf("ConsistencyProof(): %v", err)
+		}
+		if diff := cmp.Diff(r.Proof.GetHashes(), want); diff != "" {
+			t.Errorf("WatchSignedLogRoots() proof from %d to %d diff (-got +want):\n%s", sizes[0], sizes[1], diff)
+		}
+		if sizes[1] == 4 {
+			grow(3)
+		}
+	}
+
+	stop()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("WatchSignedLogRoots()=%v, want code %v", err, codes.Canceled)
+	}
+}
+
+func TestWatchSignedLogRootsSharesPoller(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s, tree, _ := newIntegratedLog(ctx, t, 0)
+	s.watchInterval = 10 * time.Millisecond
+
+	watchCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		stream := &fakeWatchStream{ctx: watchCtx, resps: make(chan *trillian.WatchSignedLogRootsResponse)}
+		go func() {
+			done <- s.WatchSignedLogRoots(&trillian.WatchSignedLogRootsRequest{LogId: tree.TreeId}, stream)
+		}()
+		select {
+		case <-stream.resps:
+		case err := <-done:
+			t.Fatalf("WatchSignedLogRoots() returned early: %v", err)
+		}
+	}
+
+	s.watchersMu.Lock()
+	if got, want := len(s.watchers), 1; got != want {
+		t.Errorf("got %d root watchers, want %d", got, want)
+	}
+	if w := s.watchers[tree.TreeId]; w == nil || w.subs != 2 {
+		t.Errorf("root watcher = %+v, want one with 2 streams", w)
+	}
+	s.watchersMu.Unlock()
+
+	stop()
+	for i := 0; i < 2; i++ {
+		if err := <-done; status.Code(err) != codes.Canceled {
+			t.Errorf("WatchSignedLogRoots()=%v, want code %v", err, codes.Canceled)
+		}
+	}
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	if got := len(s.watchers); got != 0 {
+		t.Errorf("got %d root watchers after the streams ended, want 0", got)
+	}
+}
+
+func TestGetRangeInclusionProof(t *testing.T) {
+	ctx := context.Background()
+	s, tree, ref := newIntegratedLog(ctx, t, 11)
+
+	for _, test := range []struct {
+		start, end, size int64
+		wantCode         codes.Code
+		wantEmpty        bool
+	}{
+		{start: 0, end: 11, size: 11},
+		{start: 3, end: 8, size: 11},
+		{start: 4, end: 5, size: 6},
+		{start: 10, end: 11, size: 11},
+		{start: 1, end: 2, size: 12, wantEmpty: true},
+		{start: 5, end: 5, size: 11, wantCode: codes.InvalidArgument},
+		{start: 5, end: 9, size: 8, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(fmt.Sprintf("%d-%d-%d", test.start, test.end, test.size), func(t *testing.T) {
+			req := &trillian.GetRangeInclusionProofRequest{LogId: tree.TreeId, StartIndex: test.start, EndIndex: test.end, TreeSize: test.size}
+			rsp, err := s.GetRangeInclusionProof(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetRangeInclusionProof()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if test.wantEmpty {
+				if len(rsp.LeftHashes)+len(rsp.RightHashes) != 0 {
+					t.Errorf("GetRangeInclusionProof() returned a proof for an unknown tree size")
+				}
+				return
+			}
+			var leafHashes [][]byte
+			for i := test.start; i < test.end; i++ {
+				leafHashes = append(leafHashes, ref.LeafHash(uint64(i)))
+			}
+			root := &types.LogRootV1{TreeSize: uint64(test.size), RootHash: ref.HashAt(uint64(test.size))}
+			v := client.NewLogVerifier(rfc6962.DefaultHasher)
+			if err := v.VerifyRangeInclusion(root, uint64(test.start), leafHashes, rsp.LeftHashes, rsp.RightHashes); err != nil {
+				t.Errorf("VerifyRangeInclusion(): %v", err)
+			}
+		})
+	}
+}
+
+func TestGetConsistencyProofByRootHash(t *testing.T) {
+	ctx := context.Background()
+	s, tree, ref := newIntegratedLog(ctx, t, 7)
+
+	for _, test := range []struct {
+		desc          string
+		first, second []byte
+		wantSizes     [2]uint64
+		wantCode      codes.Code
+	}{
+		{desc: "ok", first: ref.HashAt(2), second: ref.HashAt(7), wantSizes: [2]uint64{2, 7}},
+		{desc: "earlier", first: ref.HashAt(3), second: ref.HashAt(5), wantSizes: [2]uint64{3, 5}},
+		{desc: "same", first: ref.HashAt(4), second: ref.HashAt(4), wantSizes: [2]uint64{4, 4}},
+		{desc: "empty-tree", first: ref.HashAt(0), second: ref.HashAt(6), wantSizes: [2]uint64{0, 6}},
+		{desc: "reversed", first: ref.HashAt(5), second: ref.HashAt(2), wantCode: codes.InvalidArgument},
+		{desc: "unknown", first: ref.HashAt(2), second: []byte("unknown"), wantCode: codes.NotFound},
+		{desc: "missing", second: ref.HashAt(2), wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &trillian.GetConsistencyProofByRootHashRequest{LogId: tree.TreeId, FirstRootHash: test.first, SecondRootHash: test.second}
+			rsp, err := s.GetConsistencyProofByRootHash(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetConsistencyProofByRootHash()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			var first, second types.LogRootV1
+			if err := first.UnmarshalBinary(rsp.FirstRoot.LogRoot); err != nil {
+				t.Fatalf("UnmarshalBinary(): %v", err)
+			}
+			if err := second.UnmarshalBinary(rsp.SecondRoot.LogRoot); err != nil {
+				t.Fatalf("UnmarshalBinary(): %v", err)
+			}
+			if got := [2]uint64{first.TreeSize, second.TreeSize}; got != test.wantSizes {
+				t.Errorf("GetConsistencyProofByRootHash() resolved sizes %v, want %v", got, test.wantSizes)
+			}
+			want, err := ref.ConsistencyProof(test.wantSizes[0], test.wantSizes[1])
+			if err != nil {
+				t.Fatalf("ConsistencyProof(): %v", err)
+			}
+			if diff := cmp.Diff(rsp.Proof.GetHashes(), want, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("GetConsistencyProofByRootHash() proof diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListSignedLogRoots(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryLogServer(clock.System)
+	tree := initLog(ctx, t, s, stestonly.LogTree)
+	// timestamps holds the timestamp of the root of each size.
+	var timestamps []time.Time
+	addRoot := func() {
+		t.Helper()
+		rsp, err := s.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(rsp.SignedLogRoot.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		timestamps = append(timestamps, time.Unix(0, int64(root.TimestampNanos)))
+	}
+	addRoot()
+	for i := 0; i < 5; i++ {
+		integrateLeaf(ctx, t, s, tree, &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf-%d", i))})
+		addRoot()
+	}
+
+	// list returns the sizes of the roots listed by pages of req.
+	list := func(req *trillian.ListSignedLogRootsRequest) ([][]uint64, error) {
+		req.LogId = tree.TreeId
+		var pages [][]uint64
+		for {
+			rsp, err := s.ListSignedLogRoots(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			var sizes []uint64
+			for _, slr := range rsp.SignedLogRoots {
+				var root types.LogRootV1
+				if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+					t.Fatalf("UnmarshalBinary(): %v", err)
+				}
+				sizes = append(sizes, root.TreeSize)
+			}
+			pages = append(pages, sizes)
+			if rsp.NextPageToken == "" {
+				return pages, nil
+			}
+			req.PageToken = rsp.NextPageToken
+		}
+	}
+
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.ListSignedLogRootsRequest
+		want     [][]uint64
+		wantCode codes.Code
+	}{
+		{desc: "all", req: &trillian.ListSignedLogRootsRequest{}, want: [][]uint64{{0, 1, 2, 3, 4, 5}}},
+		{desc: "pages", req: &trillian.ListSignedLogRootsRequest{PageSize: 4}, want: [][]uint64{{0, 1, 2, 3}, {4, 5}}},
+		{desc: "whole-pages", req: &trillian.ListSignedLogRootsRequest{PageSize: 3}, want: [][]uint64{{0, 1, 2}, {3, 4, 5}, nil}},
+		{desc: "sizes", req: &trillian.ListSignedLogRootsRequest{StartTreeSize: 2, EndTreeSize: 4}, want: [][]uint64{{2, 3}}},
+		{
+			desc: "times",
+			req:  &trillian.ListSignedLogRootsRequest{StartTime: timestamppb.New(timestamps[3]), PageSize: 2},
+			want: [][]uint64{{3, 4}, {5}},
+		},
+		{
+			desc: "before-epoch",
+			req:  &trillian.ListSignedLogRootsRequest{EndTime: timestamppb.New(time.Unix(-1, 0))},
+			want: [][]uint64{nil},
+		},
+		{desc: "bad-page-size", req: &trillian.ListSignedLogRootsRequest{PageSize: -1}, wantCode: codes.InvalidArgument},
+		{desc: "bad-page-token", req: &trillian.ListSignedLogRootsRequest{PageToken: "next"}, wantCode: codes.InvalidArgument},
+		{desc: "bad-sizes", req: &trillian.ListSignedLogRootsRequest{EndTreeSize: -1}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := list(test.req)
+			if gotCode := status.Code(err); gotCode != test.wantCode {
+				t.Fatalf("ListSignedLogRoots()=%v, want code %v", err, test.wantCode)
+			}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("ListSignedLogRoots() pages diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetLeavesByIndices(t *testing.T) {
+	ctx := context.Background()
+	s, tree, _ := newIntegratedLog(ctx, t, 5)
+
+	for _, test := range []struct {
+		desc     string
+		indices  []int64
+		wantCode codes.Code
+	}{
+		{desc: "ok", indices: []int64{4, 0, 2}},
+		{desc: "duplicates", indices: []int64{1, 3, 1}},
+		{desc: "empty", wantCode: codes.InvalidArgument},
+		{desc: "negative", indices: []int64{1, -1}, wantCode: codes.InvalidArgument},
+		{desc: "too-many", indices: make([]int64, maxLeafIndices+1), wantCode: codes.InvalidArgument},
+		{desc: "beyond-tree", indices: []int64{1, 5}, wantCode: codes.OutOfRange},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			rsp, err := s.GetLeavesByIndices(ctx, &trillian.GetLeavesByIndicesRequest{LogId: tree.TreeId, LeafIndex: test.indices})
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetLeavesByIndices()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, leaf := range rsp.Leaves {
+				got = append(got, fmt.Sprintf("%d:%s", leaf.LeafIndex, leaf.LeafValue))
+			}
+			var want []string
+			for _, index := range test.indices {
+				want = append(want, fmt.Sprintf("%d:leaf-%d", index, index))
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("GetLeavesByIndices() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetEntryAndProofBatch(t *testing.T) {
+	ctx := context.Background()
+	s, tree, ref := newIntegratedLog(ctx, t, 7)
+
+	for _, test := range []struct {
+		desc       string
+		index      int64
+		additional []int64
+		size       int64
+		pin        bool
+		wantSize   uint64
+		wantCode   codes.Code
+	}{
+		{desc: "window", index: 2, additional: []int64{3, 4, 5}, size: 7, wantSize: 7},
+		{desc: "earlier-size", index: 0, additional: []int64{4, 1, 4}, size: 5, wantSize: 5},
+		{desc: "pinned", index: 1, additional: []int64{2}, size: 6, pin: true, wantSize: 6},
+		{desc: "beyond-root", index: 1, additional: []int64{6}, size: 9, wantSize: 7},
+		{desc: "pinned-beyond-root", index: 1, size: 8, pin: true, wantCode: codes.OutOfRange},
+		{desc: "additional-beyond-root", index: 1, additional: []int64{7}, size: 9, wantCode: codes.OutOfRange},
+		{desc: "leaf-beyond-root", index: 7, additional: []int64{1}, size: 9, wantCode: codes.OutOfRange},
+		{desc: "additional-beyond-size", index: 1, additional: []int64{5}, size: 5, wantCode: codes.InvalidArgument},
+		{desc: "negative", index: 1, additional: []int64{-1}, size: 5, wantCode: codes.InvalidArgument},
+		{desc: "too-many", index: 1, additional: make([]int64, maxLeafIndices), size: 5, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &trillian.GetEntryAndProofRequest{
+				LogId:                 tree.TreeId,
+				LeafIndex:             test.index,
+				TreeSize:              test.size,
+				AdditionalLeafIndices: test.additional,
+				PinTreeSize:           test.pin,
+			}
+			rsp, err := s.GetEntryAndProof(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetEntryAndProof()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			entries := append([]*trillian.EntryAndProof{{Leaf: rsp.Leaf, Proof: rsp.Proof}}, rsp.AdditionalEntries...)
+			indices := append([]int64{test.index}, test.additional...)
+			if got, want := len(entries), len(indices); got != want {
+				t.Fatalf("GetEntryAndProof() returned %d entries, want %d", got, want)
+			}
+			root := &types.LogRootV1{TreeSize: test.wantSize, RootHash: ref.HashAt(test.wantSize)}
+			v := client.NewLogVerifier(rfc6962.DefaultHasher)
+			for i, e := range entries {
+				if got, want := string(e.Leaf.LeafValue), fmt.Sprintf("leaf-%d", indices[i]); got != want {
+					t.Errorf("entry %d has leaf %q, want %q", i, got, want)
+				}
+				if err := v.VerifyInclusionByHash(root, ref.LeafHash(uint64(indices[i])), e.Proof); err != nil {
+					t.Errorf("VerifyInclusionByHash(entry %d): %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTreeStats(t *testing.T) {
+	ctx := context.Background()
+	fakeTime := clock.NewFake(time.Unix(1700000000, 0))
+	s := newMemoryLogServer(fakeTime)
+	tree := initLog(ctx, t, s, stestonly.LogTree)
+
+	check := func(want *trillian.GetTreeStatsResponse) {
+		t.Helper()
+		got, err := s.GetTreeStats(ctx, &trillian.GetTreeStatsRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetTreeStats(): %v", err)
+		}
+		if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+			t.Errorf("GetTreeStats() diff (-got +want):\n%s", diff)
+		}
+	}
+	check(&trillian.GetTreeStatsResponse{RootTimestamp: timestamppb.New(fakeTime.Now())})
+
+	for i := 0; i < 3; i++ {
+		data := []byte(fmt.Sprintf("leaf-%d", i))
+		if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: &trillian.LogLeaf{LeafValue: data}}); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+	}
+	fakeTime.Set(fakeTime.Now().Add(time.Minute))
+	if _, err := log.IntegrateBatch(ctx, tree, 2, 0, 0, fakeTime, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	check(&trillian.GetTreeStatsResponse{
+		TreeSize:                 2,
+		RootTimestamp:            timestamppb.New(fakeTime.Now()),
+		UnsequencedCount:         1,
+		LastIntegrationTimestamp: timestamppb.New(fakeTime.Now()),
+	})
+}
+
+func TestGetSequencedLeafCount(t *testing.T) {
+	ctx := context.Background()
+	fakeTime := clock.NewFake(time.Unix(1700000000, 0))
+	s := newMemoryLogServer(fakeTime)
+	tree := initLog(ctx, t, s, stestonly.LogTree)
+
+	check := func(want *trillian.GetSequencedLeafCountResponse) {
+		t.Helper()
+		got, err := s.GetSequencedLeafCount(ctx, &trillian.GetSequencedLeafCountRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetSequencedLeafCount(): %v", err)
+		}
+		if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+			t.Errorf("GetSequencedLeafCount() diff (-got +want):\n%s", diff)
+		}
+	}
+	check(&trillian.GetSequencedLeafCountResponse{FirstIndex: -1, LastIndex: -1})
+
+	for i := 0; i < 3; i++ {
+		data := []byte(fmt.Sprintf("leaf-%d", i))
+		if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: &trillian.LogLeaf{LeafValue: data}}); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+	}
+	fakeTime.Set(fakeTime.Now().Add(time.Minute))
+	if _, err := log.IntegrateBatch(ctx, tree, 2, 0, 0, fakeTime, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	check(&trillian.GetSequencedLeafCountResponse{TreeSize: 2, FirstIndex: 0, LastIndex: 1})
+
+	// Leaves stored beyond the tree size, e.g. by replication, are included.
+	hash := sha256.Sum256([]byte("leaf-5"))
+	if err := s.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.(storage.SequencedLeafWriter).WriteSequencedLeaves(ctx, []*trillian.LogLeaf{{
+			LeafIndex:          5,
+			LeafValue:          []byte("leaf-5"),
+			LeafIdentityHash:   hash[:],
+			MerkleLeafHash:     hash[:],
+			IntegrateTimestamp: timestamppb.New(fakeTime.Now()),
+		}})
+	}); err != nil {
+		t.Fatalf("WriteSequencedLeaves(): %v", err)
+	}
+	check(&trillian.GetSequencedLeafCountResponse{TreeSize: 2, FirstIndex: 0, LastIndex: 5})
+}
+
+func TestIncludeProofRoot(t *testing.T) {
+	ctx := context.Background()
+	s, tree, ref := newIntegratedLog(ctx, t, 5)
+
+	// checkRoot checks that proofRoot has the given size, and is the root
+	// which the reference tree had at that size.
+	checkRoot := func(t *testing.T, proofRoot *trillian.SignedLogRoot, size uint64) {
+		t.Helper()
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(proofRoot.GetLogRoot()); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		if want := ref.HashAt(size); root.TreeSize != size || !bytes.Equal(root.RootHash, want) {
+			t.Errorf("proof root has size %d and hash %x, want %d and %x", root.TreeSize, root.RootHash, size, want)
+		}
+	}
+
+	for _, test := range []struct {
+		desc     string
+		treeSize int64
+		wantSize uint64
+	}{
+		{desc: "latest", treeSize: 5, wantSize: 5},
+		{desc: "earlier", treeSize: 3, wantSize: 3},
+		{desc: "beyond-tree", treeSize: 9, wantSize: 5},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			rsp, err := s.GetEntryAndProof(ctx, &trillian.GetEntryAndProofRequest{LogId: tree.TreeId, LeafIndex: 1, TreeSize: test.treeSize, IncludeProofRoot: true})
+			if err != nil {
+				t.Fatalf("GetEntryAndProof(): %v", err)
+			}
+			checkRoot(t, rsp.ProofRoot, test.wantSize)
+
+			if test.treeSize > 5 {
+				return
+			}
+			ipRsp, err := s.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: tree.TreeId, LeafIndex: 1, TreeSize: test.treeSize, IncludeProofRoot: true})
+			if err != nil {
+				t.Fatalf("GetInclusionProof(): %v", err)
+			}
+			checkRoot(t, ipRsp.ProofRoot, test.wantSize)
+		})
+	}
+
+	rsp, err := s.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: tree.TreeId, LeafIndex: 1, TreeSize: 3})
+	if err != nil {
+		t.Fatalf("GetInclusionProof(): %v", err)
+	}
+	if rsp.ProofRoot != nil {
+		t.Errorf("GetInclusionProof() without IncludeProofRoot returned proof root %v", rsp.ProofRoot)
+	}
+}
+
+func TestGetInclusionProofByIdentityHash(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryLogServer(clock.System)
+	tree := initLog(ctx, t, s, stestonly.LogTree)
+
+	idHash := func(id string) []byte {
+		if id == "" {
+			return nil
+		}
+		h := sha256.Sum256([]byte(id))
+		return h[:]
+	}
+	ref := inmemory.New(rfc6962.DefaultHasher)
+	for i := 0; i < 5; i++ {
+		data := []byte(fmt.Sprintf("leaf-%d", i))
+		ref.AppendData(data)
+		integrateLeaf(ctx, t, s, tree, &trillian.LogLeaf{LeafValue: data, LeafIdentityHash: idHash(fmt.Sprintf("id-%d", i))})
+	}
+
+	for _, test := range []struct {
+		desc      string
+		id        string
+		treeSize  int64
+		wantIndex int64
+		wantCode  codes.Code
+	}{
+		{desc: "ok", id: "id-2", treeSize: 5, wantIndex: 2},
+		{desc: "smaller-tree", id: "id-2", treeSize: 3, wantIndex: 2},
+		{desc: "beyond-tree-size", id: "id-3", treeSize: 3, wantCode: codes.NotFound},
+		{desc: "unknown", id: "id-9", treeSize: 5, wantCode: codes.NotFound},
+		{desc: "empty", treeSize: 5, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &trillian.GetInclusionProofByIdentityHashRequest{LogId: tree.TreeId, LeafIdentityHash: idHash(test.id), TreeSize: test.treeSize}
+			rsp, err := s.GetInclusionProofByIdentityHash(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetInclusionProofByIdentityHash()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if len(rsp.Proof) != 1 {
+				t.Fatalf("GetInclusionProofByIdentityHash() returned %d proofs, want 1", len(rsp.Proof))
+			}
+			want, err := ref.InclusionProof(uint64(test.wantIndex), uint64(test.treeSize))
+			if err != nil {
+				t.Fatalf("InclusionProof(): %v", err)
+			}
+			if got := rsp.Proof[0]; got.LeafIndex != test.wantIndex || !cmp.Equal(got.Hashes, want) {
+				t.Errorf("GetInclusionProofByIdentityHash()=%v, want index %d and hashes %x", got, test.wantIndex, want)
+			}
+		})
+	}
+}
+
+func TestUpdateLeafExtraData(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryLogServer(clock.System)
+	createLog := func(mutable bool) *trillian.Tree {
+		t.Helper()
+		template := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+		template.MutableExtraData = mutable
+		tree := initLog(ctx, t, s, template)
+		leaf := &trillian.LogLeaf{LeafValue: []byte("leaf"), ExtraData: []byte("old")}
+		integrateLeaf(ctx, t, s, tree, leaf)
+		return tree
+	}
+	mutable, immutable := createLog(true), createLog(false)
+
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.UpdateLeafExtraDataRequest
+		wantCode codes.Code
+	}{
+		{desc: "ok", req: &trillian.UpdateLeafExtraDataRequest{LogId: mutable.TreeId, ExtraData: []byte("new"), Reason: "test"}},
+		{desc: "no-reason", req: &trillian.UpdateLeafExtraDataRequest{LogId: mutable.TreeId, ExtraData: []byte("new")}, wantCode: codes.InvalidArgument},
+		{desc: "negative-index", req: &trillian.UpdateLeafExtraDataRequest{LogId: mutable.TreeId, LeafIndex: -1, Reason: "test"}, wantCode: codes.InvalidArgument},
+		{desc: "beyond-tree", req: &trillian.UpdateLeafExtraDataRequest{LogId: mutable.TreeId, LeafIndex: 1, Reason: "test"}, wantCode: codes.OutOfRange},
+		{desc: "immutable", req: &trillian.UpdateLeafExtraDataRequest{LogId: immutable.TreeId, ExtraData: []byte("new"), Reason: "test"}, wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			rsp, err := s.UpdateLeafExtraData(ctx, test.req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("UpdateLeafExtraData()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := rsp.Leaf.ExtraData, test.req.ExtraData; !bytes.Equal(got, want) {
+				t.Errorf("UpdateLeafExtraData().Leaf.ExtraData=%q, want %q", got, want)
+			}
+			got, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: test.req.LogId, Count: 1})
+			if err != nil {
+				t.Fatalf("GetLeavesByRange(): %v", err)
+			}
+			if diff := cmp.Diff(got.Leaves[0], rsp.Leaf, protocmp.Transform()); diff != "" {
+				t.Errorf("GetLeavesByRange() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRedactLeaf(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryLogServer(clock.System)
+	createLog := func(allow bool) *trillian.Tree {
+		t.Helper()
+		template := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+		template.AllowRedaction = allow
+		tree := initLog(ctx, t, s, template)
+		leaf := &trillian.LogLeaf{LeafValue: []byte("unlawful content"), ExtraData: []byte("extra")}
+		integrateLeaf(ctx, t, s, tree, leaf)
+		return tree
+	}
+	allowed, disallowed := createLog(true), createLog(false)
+	before, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: allowed.TreeId, Count: 1})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.RedactLeafRequest
+		wantCode codes.Code
+	}{
+		{desc: "no-reason", req: &trillian.RedactLeafRequest{LogId: allowed.TreeId}, wantCode: codes.InvalidArgument},
+		{desc: "negative-index", req: &trillian.RedactLeafRequest{LogId: allowed.TreeId, LeafIndex: -1, Reason: "takedown"}, wantCode: codes.InvalidArgument},
+		{desc: "beyond-tree", req: &trillian.RedactLeafRequest{LogId: allowed.TreeId, LeafIndex: 1, Reason: "takedown"}, wantCode: codes.OutOfRange},
+		{desc: "disallowed", req: &trillian.RedactLeafRequest{LogId: disallowed.TreeId, Reason: "takedown"}, wantCode: codes.FailedPrecondition},
+		{desc: "ok", req: &trillian.RedactLeafRequest{LogId: allowed.TreeId, Reason: "takedown"}},
+		{desc: "already-redacted", req: &trillian.RedactLeafRequest{LogId: allowed.TreeId, Reason: "takedown"}, wantCode: codes.AlreadyExists},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			rsp, err := s.RedactLeaf(ctx, test.req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("RedactLeaf()=%v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if !rsp.Leaf.Redacted || len(rsp.Leaf.LeafValue) != 0 {
+				t.Errorf("RedactLeaf().Leaf has Redacted=%v, LeafValue=%q; want true, empty", rsp.Leaf.Redacted, rsp.Leaf.LeafValue)
+			}
+			got, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: test.req.LogId, Count: 1})
+			if err != nil {
+				t.Fatalf("GetLeavesByRange(): %v", err)
+			}
+			if diff := cmp.Diff(got.Leaves[0], rsp.Leaf, protocmp.Transform()); diff != "" {
+				t.Errorf("GetLeavesByRange() diff (-got +want):\n%s", diff)
+			}
+			// The leaf hash is kept, so the leaf's inclusion can still be proven.
+			if !bytes.Equal(got.Leaves[0].MerkleLeafHash, before.Leaves[0].MerkleLeafHash) {
+				t.Errorf("MerkleLeafHash changed from %x to %x", before.Leaves[0].MerkleLeafHash, got.Leaves[0].MerkleLeafHash)
+			}
+			if _, err := s.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: test.req.LogId, LeafHash: got.Leaves[0].MerkleLeafHash, TreeSize: 1}); err != nil {
+				t.Errorf("GetInclusionProofByHash(): %v", err)
+			}
+		})
+	}
+}
+
+func TestQueuePrehashedLeaves(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryLogServer(clock.System)
+	createLog := func(template *trillian.Tree, allow bool) *trillian.Tree {
+		t.Helper()
+		template = proto.Clone(template).(*trillian.Tree)
+		template.AllowPrehashedLeaves = allow
+		return initLog(ctx, t, s, template)
+	}
+	allowed, disallowed := createLog(stestonly.LogTree, true), createLog(stestonly.LogTree, false)
+	// The in-memory storage can't add sequenced leaves, so pre-ordered logs
+	// are only checked to reject pre-hashed leaves.
+	preordered := createLog(stestonly.PreorderedLogTree, false)
+	leafHash := rfc6962.DefaultHasher.HashLeaf([]byte("kept elsewhere"))
+
+	for _, test := range []struct {
+		desc     string
+		leaf     *trillian.LogLeaf
+		tree     *trillian.Tree
+		wantCode codes.Code
+	}{
+		{desc: "disallowed", tree: disallowed, leaf: &trillian.LogLeaf{MerkleLeafHash: leafHash}, wantCode: codes.FailedPrecondition},
+		{desc: "bad-hash-length", tree: allowed, leaf: &trillian.LogLeaf{MerkleLeafHash: leafHash[1:]}, wantCode: codes.InvalidArgument},
+		{desc: "no-hash", tree: allowed, leaf: &trillian.LogLeaf{}, wantCode: codes.InvalidArgument},
+		{desc: "queued", tree: allowed, leaf: &trillian.LogLeaf{MerkleLeafHash: leafHash}},
+		{desc: "sequenced-disallowed", tree: preordered, leaf: &trillian.LogLeaf{MerkleLeafHash: leafHash}, wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var err error
+			if test.tree.TreeType == trillian.TreeType_PREORDERED_LOG {
+				_, err = s.AddSequencedLeaves(ctx, &trillian.AddSequencedLeavesRequest{LogId: test.tree.TreeId, Leaves: []*trillian.LogLeaf{test.leaf}})
+			} else {
+				_, err = s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: test.tree.TreeId, Leaf: test.leaf})
+			}
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("adding leaf: %v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := log.IntegrateBatch(ctx, test.tree, 1, 0, 0, clock.System, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+				t.Fatalf("IntegrateBatch(): %v", err)
+			}
+			got, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: test.tree.TreeId, Count: 1})
+			if err != nil {
+				t.Fatalf("GetLeavesByRange(): %v", err)
+			}
+			if l := got.Leaves[0]; !bytes.Equal(l.MerkleLeafHash, leafHash) || !bytes.Equal(l.LeafIdentityHash, leafHash) || len(l.LeafValue) != 0 {
+				t.Errorf("GetLeavesByRange().Leaves[0]=%v, want MerkleLeafHash and LeafIdentityHash %x, and no LeafValue", l, leafHash)
+			}
+			if _, err := s.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: test.tree.TreeId, LeafHash: leafHash, TreeSize: 1}); err != nil {
+				t.Errorf("GetInclusionProofByHash(): %v", err)
+			}
+		})
+	}
+}
+
+func TestQueueLeafValidators(t *testing.T) {
+	ctx := context.Background()
+	if err := leafvalidators.Register("ServerTestNoLlamas", func(_ context.Context, _ *trillian.Tree, leaf *trillian.LogLeaf) error {
+		if bytes.Equal(leaf.LeafValue, []byte("llama")) {
+			return &leafvalidators.Rejection{Reason: "LLAMA", Field: "leaf_value", Description: "llamas aren't allowed"}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	s := newMemoryLogServer(clock.System)
+	template := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	template.LeafValidators = []string{"ServerTestNoLlamas"}
+	tree := initLog(ctx, t, s, template)
+
+	for _, test := range []struct {
+		value    string
+		wantCode codes.Code
+	}{
+		{value: "alpaca"},
+		{value: "llama", wantCode: codes.InvalidArgument},
+	} {
+		t.Run(test.value, func(t *testing.T) {
+			_, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: &trillian.LogLeaf{LeafValue: []byte(test.value)}})
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("QueueLeaf(%q): %v, want code %v", test.value, err, test.wantCode)
+			}
+		})
+	}
+	// Only the accepted leaf is queued.
+	if _, err := log.IntegrateBatch(ctx, tree, 10, 0, 0, clock.System, s.registry.LogStorage, s.registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+	rsp, err := s.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(rsp.SignedLogRoot.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if root.TreeSize != 1 {
+		t.Errorf("TreeSize = %d, want 1", root.TreeSize)
+	}
+}
+
 func TestGetProofByHashErrors(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -0,0 +1,216 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package peerquota provides a gRPC interceptor which rate limits log
+// requests per network peer, so that a single abusive source can't exhaust
+// the global quota of a public log.
+package peerquota
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// logService is the name of the service whose requests are limited.
+const logService = "trillian.TrillianLog"
+
+// Config holds the parameters for a Limiter. Zero rates are unlimited.
+type Config struct {
+	// ReadRate and WriteRate are the number of tokens per second which each
+	// peer may spend on read and write requests. Write requests cost one token
+	// per leaf, and read requests one token each.
+	ReadRate, WriteRate float64
+	// Burst is the number of tokens of each kind which each peer may spend
+	// at once. It defaults to the corresponding rate, and is at least 1.
+	Burst int
+	// IPv4PrefixLen and IPv6PrefixLen are the lengths of the address prefixes
+	// which peers are grouped by, e.g. 32 and 64. Zero values default to 32
+	// and 128, i.e. single addresses.
+	IPv4PrefixLen, IPv6PrefixLen int
+	// Allowlist holds the prefixes of peers which aren't limited.
+	Allowlist []netip.Prefix
+	// MaxPeers is the number of peers tracked above which idle ones are
+	// forgotten. Defaults to 100000.
+	MaxPeers int
+}
+
+// peerLimiters holds the token buckets of a peer.
+type peerLimiters struct {
+	read, write *rate.Limiter
+}
+
+// Limiter is a gRPC interceptor which rate limits requests per peer.
+type Limiter struct {
+	cfg Config
+	ts  clock.TimeSource
+
+	mu    sync.Mutex
+	peers map[netip.Prefix]*peerLimiters
+
+	denied monitoring.Counter
+}
+
+// New returns a Limiter for cfg.
+func New(cfg Config, mf monitoring.MetricFactory, ts clock.TimeSource) *Limiter {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	if cfg.IPv4PrefixLen <= 0 || cfg.IPv4PrefixLen > 32 {
+		cfg.IPv4PrefixLen = 32
+	}
+	if cfg.IPv6PrefixLen <= 0 || cfg.IPv6PrefixLen > 128 {
+		cfg.IPv6PrefixLen = 128
+	}
+	if cfg.MaxPeers <= 0 {
+		cfg.MaxPeers = 100000
+	}
+	return &Limiter{
+		cfg:    cfg,
+		ts:     ts,
+		peers:  make(map[netip.Prefix]*peerLimiters),
+		denied: mf.NewCounter("peer_quota_denied", "Number of requests denied by per-peer quota, by kind", "kind"),
+	}
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (l *Limiter) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+logService+"/") {
+		return handler(ctx, req)
+	}
+	if p, ok := l.peerPrefix(ctx); ok {
+		if err := l.allow(p, req); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor implements grpc.StreamServerInterceptor. Opening a stream
+// costs the peer one read token, whatever the stream goes on to carry.
+func (l *Limiter) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !strings.HasPrefix(info.FullMethod, "/"+logService+"/") {
+		return handler(srv, ss)
+	}
+	if p, ok := l.peerPrefix(ss.Context()); ok {
+		if err := l.allow(p, nil); err != nil {
+			return err
+		}
+	}
+	return handler(srv, ss)
+}
+
+// peerPrefix returns the prefix which the peer of the request is grouped by,
+// or false if it is allowlisted or not an IP peer.
+func (l *Limiter) peerPrefix(ctx context.Context) (netip.Prefix, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return netip.Prefix{}, false
+	}
+	ap, err := netip.ParseAddrPort(p.Addr.String())
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr := ap.Addr().Unmap()
+	for _, a := range l.cfg.Allowlist {
+		if a.Contains(addr) {
+			return netip.Prefix{}, false
+		}
+	}
+	bits := l.cfg.IPv6PrefixLen
+	if addr.Is4() {
+		bits = l.cfg.IPv4PrefixLen
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix, true
+}
+
+// allow spends the tokens of the request from the buckets of the peer, or
+// returns a ResourceExhausted error if there aren't enough.
+func (l *Limiter) allow(p netip.Prefix, req interface{}) error {
+	kind, r, tokens := "read", l.cfg.ReadRate, 1
+	switch req := req.(type) {
+	case *trillian.QueueLeafRequest:
+		kind, r = "write", l.cfg.WriteRate
+	case *trillian.AddSequencedLeavesRequest:
+		kind, r, tokens = "write", l.cfg.WriteRate, len(req.GetLeaves())
+	}
+	if r <= 0 {
+		return nil
+	}
+
+	now := l.ts.Now()
+	l.mu.Lock()
+	pl := l.limitersFor(p)
+	lim := pl.read
+	if kind == "write" {
+		lim = pl.write
+	}
+	// Batches larger than the burst would never be allowed, so they cost it.
+	ok := lim.AllowN(now, min(tokens, lim.Burst()))
+	l.mu.Unlock()
+	if !ok {
+		l.denied.Inc(kind)
+		klog.V(1).Infof("Peer quota exhausted for %v (%s)", p, kind)
+		return status.Errorf(codes.ResourceExhausted, "peer quota exhausted for %v (%s)", p, kind)
+	}
+	return nil
+}
+
+// limitersFor returns the buckets of the peer, creating them if needed. It
+// must be called with mu held.
+func (l *Limiter) limitersFor(p netip.Prefix) *peerLimiters {
+	if pl, ok := l.peers[p]; ok {
+		return pl
+	}
+	if len(l.peers) >= l.cfg.MaxPeers {
+		l.forgetIdle()
+	}
+	pl := &peerLimiters{read: l.newLimiter(l.cfg.ReadRate), write: l.newLimiter(l.cfg.WriteRate)}
+	l.peers[p] = pl
+	return pl
+}
+
+func (l *Limiter) newLimiter(r float64) *rate.Limiter {
+	burst := l.cfg.Burst
+	if burst <= 0 {
+		burst = int(r)
+	}
+	return rate.NewLimiter(rate.Limit(r), max(burst, 1))
+}
+
+// forgetIdle drops the peers whose buckets are full, which behave the same
+// as new ones. It must be called with mu held.
+func (l *Limiter) forgetIdle() {
+	now := l.ts.Now()
+	for p, pl := range l.peers {
+		if pl.read.TokensAt(now) >= float64(pl.read.Burst()) && pl.write.TokensAt(now) >= float64(pl.write.Burst()) {
+			delete(l.peers, p)
+		}
+	}
+}
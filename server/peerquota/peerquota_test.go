@@ -0,0 +1,145 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peerquota
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	readMethod  = "/trillian.TrillianLog/GetLeavesByRange"
+	writeMethod = "/trillian.TrillianLog/QueueLeaf"
+	adminMethod = "/trillian.TrillianAdmin/ListTrees"
+)
+
+// call runs a request for method from the peer at addr through l.
+func call(l *Limiter, addr, method string, req interface{}) error {
+	ctx := context.Background()
+	if addr != "" {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(addr))})
+	}
+	_, err := l.UnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestLimiter(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	l := New(Config{
+		ReadRate:      2,
+		WriteRate:     1,
+		IPv4PrefixLen: 24,
+		IPv6PrefixLen: 64,
+		Allowlist:     []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, nil, ts)
+	write := &trillian.QueueLeafRequest{}
+
+	for _, test := range []struct {
+		desc, addr, method string
+		req                interface{}
+		want               codes.Code
+	}{
+		{desc: "write", addr: "192.0.2.1:1234", method: writeMethod, req: write},
+		{desc: "write-exhausted", addr: "192.0.2.1:1234", method: writeMethod, req: write, want: codes.ResourceExhausted},
+		{desc: "write-same-prefix", addr: "192.0.2.200:1", method: writeMethod, req: write, want: codes.ResourceExhausted},
+		{desc: "write-other-prefix", addr: "192.0.3.1:1234", method: writeMethod, req: write},
+		{desc: "read-separate-bucket", addr: "192.0.2.1:1234", method: readMethod},
+		{desc: "read-burst", addr: "192.0.2.1:1234", method: readMethod},
+		{desc: "read-exhausted", addr: "192.0.2.1:1234", method: readMethod, want: codes.ResourceExhausted},
+		{desc: "ipv6", addr: "[2001:db8::1]:1", method: writeMethod, req: write},
+		{desc: "ipv6-same-prefix", addr: "[2001:db8::2]:1", method: writeMethod, req: write, want: codes.ResourceExhausted},
+		{desc: "allowlisted", addr: "10.1.2.3:1", method: writeMethod, req: write},
+		{desc: "allowlisted-again", addr: "10.1.2.3:1", method: writeMethod, req: write},
+		{desc: "admin-unlimited", addr: "192.0.2.1:1234", method: adminMethod},
+		{desc: "no-peer", method: writeMethod, req: write},
+		{desc: "no-peer-again", method: writeMethod, req: write},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := status.Code(call(l, test.addr, test.method, test.req)); got != test.want {
+				t.Errorf("UnaryInterceptor() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	ts.Set(ts.Now().Add(time.Second))
+	if err := call(l, "192.0.2.1:1234", writeMethod, write); err != nil {
+		t.Errorf("UnaryInterceptor() after refill: %v", err)
+	}
+}
+
+// fakeServerStream is a grpc.ServerStream with a context.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func TestLimiterStreams(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	l := New(Config{ReadRate: 1}, nil, ts)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort("192.0.2.1:1234"))})
+	info := &grpc.StreamServerInfo{FullMethod: "/trillian.TrillianLog/WatchSignedLogRoots", IsServerStream: true}
+	var opened int
+	handler := func(interface{}, grpc.ServerStream) error {
+		opened++
+		return nil
+	}
+
+	if err := l.StreamInterceptor(nil, &fakeServerStream{ctx: ctx}, info, handler); err != nil {
+		t.Fatalf("StreamInterceptor(): %v", err)
+	}
+	// Opening a stream spends the read tokens of the peer.
+	if got := status.Code(call(l, "192.0.2.1:1234", readMethod, nil)); got != codes.ResourceExhausted {
+		t.Errorf("UnaryInterceptor() after stream = %v, want %v", got, codes.ResourceExhausted)
+	}
+	if got := status.Code(l.StreamInterceptor(nil, &fakeServerStream{ctx: ctx}, info, handler)); got != codes.ResourceExhausted {
+		t.Errorf("StreamInterceptor() = %v, want %v", got, codes.ResourceExhausted)
+	}
+	if opened != 1 {
+		t.Errorf("opened %d streams, want 1", opened)
+	}
+}
+
+func TestLimiterForgetsIdlePeers(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	l := New(Config{WriteRate: 1, MaxPeers: 2}, nil, ts)
+	write := &trillian.QueueLeafRequest{}
+
+	for _, addr := range []string{"192.0.2.1:1", "192.0.2.2:1"} {
+		if err := call(l, addr, writeMethod, write); err != nil {
+			t.Fatalf("UnaryInterceptor(%s): %v", addr, err)
+		}
+	}
+	ts.Set(ts.Now().Add(time.Second))
+	if err := call(l, "192.0.2.3:1", writeMethod, write); err != nil {
+		t.Fatalf("UnaryInterceptor(): %v", err)
+	}
+	if got, want := len(l.peers), 1; got != want {
+		t.Errorf("tracked %d peers, want %d", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/proof"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithProofWorkers sets the number of proofs which the log server fetches and
+// hashes at once, if n is positive. Requests for proofs beyond that wait for
+// a worker, and concurrent requests for identical proofs share the work of the
+// first of them. By default, each proof is built by its request,
+// independently of the others.
+func WithProofWorkers(n int) LogServerOption {
+	return func(o *logServerOptions) { o.proofWorkers = n }
+}
+
+// proofPool builds proofs with a bounded number of workers, and coalesces
+// concurrent requests for identical proofs. The nodes of a log below a given
+// tree size never change, so a proof built from any snapshot which covers its
+// tree size is valid for all requests for it.
+type proofPool struct {
+	workers *semaphore.Weighted
+
+	mu    sync.Mutex
+	calls map[string]*proofCall
+
+	coalesced monitoring.Counter
+}
+
+// proofCall is a proof being built, and the requests waiting for it.
+type proofCall struct {
+	done    chan struct{}
+	waiters int // Guarded by proofPool.mu.
+
+	// Set before done is closed.
+	proof *trillian.Proof
+	err   error
+}
+
+// newProofPool returns a proofPool with the given number of workers.
+func newProofPool(workers int, mf monitoring.MetricFactory) *proofPool {
+	return &proofPool{
+		workers: semaphore.NewWeighted(int64(workers)),
+		calls:   make(map[string]*proofCall),
+		coalesced: mf.NewCounter(
+			"proof_requests_coalesced",
+			"Number of proofs returned from a concurrent request for the same proof",
+			"logid",
+		),
+	}
+}
+
+// build returns the proof made of the nodes pn of tree treeID, read from nr.
+// It is built by fetchNodesAndBuildProof once a worker is available, unless
+// an identical proof is already being built. A nil pool builds the proof
+// directly.
+func (p *proofPool) build(ctx context.Context, treeID int64, nr nodeReader, hasher compact.HashFn, leafIndex uint64, pn proof.Nodes) (*trillian.Proof, error) {
+	if p == nil {
+		return fetchNodesAndBuildProof(ctx, nr, hasher, leafIndex, pn)
+	}
+	key := fmt.Sprintf("%d/%d/%v", treeID, leafIndex, pn)
+
+	p.mu.Lock()
+	if c, ok := p.calls[key]; ok {
+		c.waiters++
+		p.mu.Unlock()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		// The first request may have given up, while this one can still
+		// build the proof itself.
+		if isContextErr(c.err) && ctx.Err() == nil {
+			return p.run(ctx, nr, hasher, leafIndex, pn)
+		}
+		if c.err != nil {
+			return nil, c.err
+		}
+		p.coalesced.Inc(strconv.FormatInt(treeID, 10))
+		// Each request gets its own proof, which may be modified later.
+		return proto.Clone(c.proof).(*trillian.Proof), nil
+	}
+	c := &proofCall{done: make(chan struct{})}
+	p.calls[key] = c
+	p.mu.Unlock()
+
+	c.proof, c.err = p.run(ctx, nr, hasher, leafIndex, pn)
+	p.mu.Lock()
+	delete(p.calls, key)
+	p.mu.Unlock()
+	close(c.done)
+	return c.proof, c.err
+}
+
+// run builds a proof once a worker is available.
+func (p *proofPool) run(ctx context.Context, nr nodeReader, hasher compact.HashFn, leafIndex uint64, pn proof.Nodes) (*trillian.Proof, error) {
+	if err := p.workers.Acquire(ctx, 1); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	defer p.workers.Release(1)
+	return fetchNodesAndBuildProof(ctx, nr, hasher, leafIndex, pn)
+}
+
+// isContextErr returns whether err reports a cancelled or expired context.
+func isContextErr(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	code := status.Code(err)
+	return code == codes.Canceled || code == codes.DeadlineExceeded
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// gatedReader is a nodeReader whose reads wait for its gate to be opened.
+type gatedReader struct {
+	nodeReader
+	gate  chan struct{}
+	reads atomic.Int32
+}
+
+func (r *gatedReader) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]tree.Node, error) {
+	r.reads.Add(1)
+	<-r.gate
+	return r.nodeReader.GetMerkleNodes(ctx, ids)
+}
+
+// waitFor waits for cond to hold, failing the test if it doesn't soon.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
+
+// result is the outcome of a call to proofPool.build.
+type result struct {
+	proof *trillian.Proof
+	err   error
+}
+
+func TestProofPool(t *testing.T) {
+	ctx := context.Background()
+	hasher := rfc6962.DefaultHasher.HashChildren
+	const size = 32
+	r := &gatedReader{
+		nodeReader: testonly.NewMultiFakeNodeReaderFromLeaves([]testonly.LeafBatch{
+			{TreeRevision: testTreeRevision, Leaves: expandLeaves(0, size-1), ExpectedRoot: treeAtSize(size).Hash()},
+		}),
+		gate: make(chan struct{}),
+	}
+	nodes, err := proof.Inclusion(5, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newProofPool(1, monitoring.InertMetricFactory{})
+	build := func(ctx context.Context, leafIndex uint64, pn proof.Nodes) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			proof, err := p.build(ctx, 1, r, hasher, leafIndex, pn)
+			ch <- result{proof, err}
+		}()
+		return ch
+	}
+
+	// The first request takes the only worker, and the identical second
+	// request waits for it.
+	first := build(ctx, 5, nodes)
+	waitFor(t, func() bool { return r.reads.Load() == 1 })
+	second := build(ctx, 5, nodes)
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, c := range p.calls {
+			return c.waiters == 1
+		}
+		return false
+	})
+
+	// Other proofs wait for a worker.
+	other, err := proof.Inclusion(6, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if res := <-build(shortCtx, 6, other); status.Code(res.err) != codes.DeadlineExceeded {
+		t.Errorf("build() without a free worker: %v, want DeadlineExceeded", res.err)
+	}
+
+	close(r.gate)
+	want, err := fetchNodesAndBuildProof(ctx, r, hasher, 5, nodes)
+	if err != nil {
+		t.Fatalf("fetchNodesAndBuildProof(): %v", err)
+	}
+	res1, res2 := <-first, <-second
+	for _, res := range []result{res1, res2} {
+		if res.err != nil {
+			t.Fatalf("build(): %v", res.err)
+		}
+		if diff := cmp.Diff(want, res.proof, protocmp.Transform()); diff != "" {
+			t.Errorf("build() diff (-want +got):\n%s", diff)
+		}
+	}
+	if res1.proof == res2.proof {
+		t.Error("coalesced requests share a proof")
+	}
+	// The two requests for the same proof read the nodes once, and so did
+	// fetchNodesAndBuildProof.
+	if got := r.reads.Load(); got != 2 {
+		t.Errorf("%d reads, want 2", got)
+	}
+	if len(p.calls) != 0 {
+		t.Errorf("calls %v left behind", p.calls)
+	}
+}
+
+func TestProofPoolFollowerRetries(t *testing.T) {
+	ctx := context.Background()
+	hasher := rfc6962.DefaultHasher.HashChildren
+	const size = 16
+	r := &gatedReader{
+		nodeReader: testonly.NewMultiFakeNodeReaderFromLeaves([]testonly.LeafBatch{
+			{TreeRevision: testTreeRevision, Leaves: expandLeaves(0, size-1), ExpectedRoot: treeAtSize(size).Hash()},
+		}),
+		gate: make(chan struct{}),
+	}
+	nodes, err := proof.Consistency(3, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newProofPool(2, monitoring.InertMetricFactory{})
+
+	// The first request waits for a worker, while both are busy, until its
+	// context ends; the request waiting for it builds the proof itself.
+	blockers, err := proof.Inclusion(0, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		go func(i int) { _, _ = p.build(ctx, int64(i+10), r, hasher, 0, blockers) }(i)
+	}
+	waitFor(t, func() bool { return r.reads.Load() == 2 })
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader := make(chan error, 1)
+	go func() {
+		_, err := p.build(leaderCtx, 1, r, hasher, 0, nodes)
+		leader <- err
+	}()
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.calls) == 3
+	})
+	follower := make(chan result, 1)
+	go func() {
+		proof, err := p.build(ctx, 1, r, hasher, 0, nodes)
+		follower <- result{proof, err}
+	}()
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, c := range p.calls {
+			if c.waiters == 1 {
+				return true
+			}
+		}
+		return false
+	})
+	cancel()
+	if err := <-leader; status.Code(err) != codes.Canceled {
+		t.Errorf("leader: %v, want Canceled", err)
+	}
+	close(r.gate)
+	res := <-follower
+	if res.err != nil {
+		t.Fatalf("follower: %v", res.err)
+	}
+	want, err := fetchNodesAndBuildProof(ctx, r, hasher, 0, nodes)
+	if err != nil {
+		t.Fatalf("fetchNodesAndBuildProof(): %v", err)
+	}
+	if diff := cmp.Diff(want, res.proof, protocmp.Transform()); diff != "" {
+		t.Errorf("follower diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithProofWorkers(t *testing.T) {
+	registry := extension.Registry{}
+	if s := NewTrillianLogRPCServer(registry, clock.System); s.proofs != nil {
+		t.Error("NewTrillianLogRPCServer() has a proof pool, want none by default")
+	}
+	s := NewTrillianLogRPCServer(registry, clock.System, WithProofWorkers(2))
+	if s.proofs == nil {
+		t.Fatal("NewTrillianLogRPCServer(WithProofWorkers(2)) has no proof pool")
+	}
+	for i := 0; i < 2; i++ {
+		if !s.proofs.workers.TryAcquire(1) {
+			t.Fatalf("proof pool has %d workers, want 2", i)
+		}
+	}
+	if s.proofs.workers.TryAcquire(1) {
+		t.Error("proof pool has more than 2 workers")
+	}
+}
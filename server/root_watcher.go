@@ -0,0 +1,126 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
+	"k8s.io/klog/v2"
+)
+
+// rootWatcher polls storage for the latest root of a tree on behalf of all of
+// the WatchSignedLogRoots streams for it, so that the number of streams
+// doesn't multiply the load on storage.
+type rootWatcher struct {
+	cancel context.CancelFunc
+	subs   int // Guarded by TrillianLogRPCServer.watchersMu.
+
+	mu  sync.Mutex
+	slr *trillian.SignedLogRoot
+	err error
+	// changed is closed, and replaced, when slr or err changes.
+	changed chan struct{}
+}
+
+// latest returns the latest root read by w, or the error of the last read if
+// it failed, and a channel which is closed when either changes. The root is
+// nil until the first read has been made.
+func (w *rootWatcher) latest() (*trillian.SignedLogRoot, <-chan struct{}, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.slr, w.changed, w.err
+}
+
+// set records the result of a read, and wakes the streams up if it differs
+// from the previous one.
+func (w *rootWatcher) set(slr *trillian.SignedLogRoot, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil && w.err == nil && w.slr != nil && bytes.Equal(slr.GetLogRoot(), w.slr.GetLogRoot()) {
+		return
+	}
+	if err != nil {
+		slr = w.slr
+	}
+	w.slr, w.err = slr, err
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// watchRoots returns the rootWatcher for tree, starting it if the tree isn't
+// being watched yet. Each call must be paired with a call to unwatchRoots.
+func (t *TrillianLogRPCServer) watchRoots(tree *trillian.Tree) *rootWatcher {
+	t.watchersMu.Lock()
+	defer t.watchersMu.Unlock()
+	if w, ok := t.watchers[tree.TreeId]; ok {
+		w.subs++
+		return w
+	}
+	ctx, cancel := context.WithCancel(trees.NewContext(context.Background(), tree))
+	w := &rootWatcher{cancel: cancel, subs: 1, changed: make(chan struct{})}
+	t.watchers[tree.TreeId] = w
+	go t.pollRoots(ctx, tree, w)
+	return w
+}
+
+// unwatchRoots stops the rootWatcher for the tree once it has no streams left.
+func (t *TrillianLogRPCServer) unwatchRoots(treeID int64, w *rootWatcher) {
+	t.watchersMu.Lock()
+	defer t.watchersMu.Unlock()
+	if w.subs--; w.subs == 0 {
+		w.cancel()
+		delete(t.watchers, treeID)
+	}
+}
+
+// pollRoots reads the latest root of tree into w every watchInterval, until
+// ctx is done.
+func (t *TrillianLogRPCServer) pollRoots(ctx context.Context, tree *trillian.Tree, w *rootWatcher) {
+	for {
+		slr, err := t.latestRoot(ctx, tree)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			klog.Warningf("%v: failed to read latest root for WatchSignedLogRoots: %v", tree.TreeId, err)
+		}
+		w.set(slr, err)
+		if err := clock.SleepSource(ctx, t.watchInterval, t.timeSource); err != nil {
+			return
+		}
+	}
+}
+
+// latestRoot reads the latest root of tree.
+func (t *TrillianLogRPCServer) latestRoot(ctx context.Context, tree *trillian.Tree) (*trillian.SignedLogRoot, error) {
+	tx, err := t.snapshotForTree(ctx, tree, "WatchSignedLogRoots")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "WatchSignedLogRoots")
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "WatchSignedLogRoots"); err != nil {
+		return nil, err
+	}
+	return slr, nil
+}
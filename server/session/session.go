@@ -0,0 +1,200 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session provides a gRPC interceptor which issues session tokens on
+// log writes and checks them on reads, so that clients of a log served by
+// replicas of differing freshness can read their own writes.
+package session
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tokenVersion is the first byte of tokens in the current format.
+	tokenVersion = 1
+	// tokenLen is the length of tokens in the current format: the version,
+	// followed by the tree ID, tree size and root timestamp.
+	tokenLen = 1 + 3*8
+)
+
+// Token identifies the view of a log which a server had, by its latest root.
+type Token struct {
+	TreeID         int64
+	TreeSize       uint64
+	TimestampNanos uint64
+}
+
+// Marshal returns the opaque wire form of t.
+func (t Token) Marshal() []byte {
+	b := make([]byte, 1, tokenLen)
+	b[0] = tokenVersion
+	b = binary.BigEndian.AppendUint64(b, uint64(t.TreeID))
+	b = binary.BigEndian.AppendUint64(b, t.TreeSize)
+	return binary.BigEndian.AppendUint64(b, t.TimestampNanos)
+}
+
+// ParseToken parses a token in the form returned by Marshal.
+func ParseToken(b []byte) (Token, error) {
+	if len(b) != tokenLen || b[0] != tokenVersion {
+		return Token{}, errors.New("malformed session token")
+	}
+	return Token{
+		TreeID:         int64(binary.BigEndian.Uint64(b[1:])),
+		TreeSize:       binary.BigEndian.Uint64(b[9:]),
+		TimestampNanos: binary.BigEndian.Uint64(b[17:]),
+	}, nil
+}
+
+// covers returns whether root is at least as fresh as the view of t.
+func (t Token) covers(root *types.LogRootV1) bool {
+	return root.TreeSize >= t.TreeSize && root.TimestampNanos >= t.TimestampNanos
+}
+
+// readRequest is implemented by the read requests which accept tokens.
+type readRequest interface {
+	GetLogId() int64
+	GetSessionToken() []byte
+}
+
+// Interceptor sets the session token of successful QueueLeaf and
+// AddSequencedLeaves responses to the latest root of the log, and fails read
+// requests carrying a token with Unavailable if the latest root of the log
+// is older than the token's, e.g. because the storage is a lagging replica.
+// Clients may then retry the read on another server, or later.
+//
+// Issuing a token costs a read of the latest root after each write. If the
+// read fails the error is logged, and the response returned without a token.
+type Interceptor struct {
+	admin    storage.AdminStorage
+	logs     storage.LogStorage
+	requests monitoring.Counter
+}
+
+// NewInterceptor returns an Interceptor which reads the roots of logs from
+// the given storage.
+func NewInterceptor(admin storage.AdminStorage, logs storage.LogStorage, mf monitoring.MetricFactory) *Interceptor {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Interceptor{
+		admin: admin,
+		logs:  logs,
+		requests: mf.NewCounter(
+			"session_token_requests",
+			"Number of requests which were issued or presented session tokens, by result",
+			"logid", "result",
+		),
+	}
+}
+
+// UnaryInterceptor implements grpc.UnaryServerInterceptor.
+func (i *Interceptor) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if r, ok := req.(readRequest); ok && len(r.GetSessionToken()) > 0 {
+		if err := i.check(ctx, r.GetLogId(), r.GetSessionToken()); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	switch r := resp.(type) {
+	case *trillian.QueueLeafResponse:
+		r.SessionToken = i.issue(ctx, req.(*trillian.QueueLeafRequest).LogId)
+	case *trillian.AddSequencedLeavesResponse:
+		r.SessionToken = i.issue(ctx, req.(*trillian.AddSequencedLeavesRequest).LogId)
+	}
+	return resp, nil
+}
+
+// issue returns the token for the latest root of the log, or nil if it can't
+// be read.
+func (i *Interceptor) issue(ctx context.Context, logID int64) []byte {
+	label := strconv.FormatInt(logID, 10)
+	root, err := i.latestRoot(ctx, logID)
+	if err != nil {
+		klog.Warningf("%d: failed to read root for session token: %v", logID, err)
+		i.requests.Inc(label, "issue_error")
+		return nil
+	}
+	i.requests.Inc(label, "issued")
+	return Token{TreeID: logID, TreeSize: root.TreeSize, TimestampNanos: root.TimestampNanos}.Marshal()
+}
+
+// check returns an error if the latest root of the log is older than the
+// view of the token b.
+func (i *Interceptor) check(ctx context.Context, logID int64, b []byte) error {
+	label := strconv.FormatInt(logID, 10)
+	tok, err := ParseToken(b)
+	if err != nil {
+		i.requests.Inc(label, "invalid")
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if tok.TreeID != logID {
+		i.requests.Inc(label, "invalid")
+		return status.Errorf(codes.InvalidArgument, "session token is for tree %d, not %d", tok.TreeID, logID)
+	}
+	root, err := i.latestRoot(ctx, logID)
+	if err != nil {
+		i.requests.Inc(label, "check_error")
+		return err
+	}
+	if !tok.covers(root) {
+		i.requests.Inc(label, "stale")
+		return status.Errorf(codes.Unavailable, "log %d: the view of this server (size %d) is older than the session's (size %d)", logID, root.TreeSize, tok.TreeSize)
+	}
+	i.requests.Inc(label, "fresh")
+	return nil
+}
+
+// latestRoot reads the latest root of the log from storage.
+func (i *Interceptor) latestRoot(ctx context.Context, logID int64) (*types.LogRootV1, error) {
+	tree, err := storage.GetTree(ctx, i.admin, logID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := i.logs.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal log root: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// newLog returns an Interceptor for a memory log, the log, and a function
+// which stores a new root of the log.
+func newLog(ctx context.Context, t *testing.T) (*Interceptor, *trillian.Tree, func(size, ts uint64)) {
+	t.Helper()
+	ms := memory.NewTreeStorage()
+	as := memory.NewAdminStorage(ms)
+	ls := memory.NewLogStorage(ms, nil)
+	tree, err := storage.CreateTree(ctx, as, proto.Clone(stestonly.LogTree).(*trillian.Tree))
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	setRoot := func(size, ts uint64) {
+		t.Helper()
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			root, err := (&types.LogRootV1{TreeSize: size, RootHash: []byte{0}, TimestampNanos: ts}).MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+		}); err != nil {
+			t.Fatalf("Failed to store root: %v", err)
+		}
+	}
+	setRoot(0, 1)
+	return NewInterceptor(as, ls, nil), tree, setRoot
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	want := Token{TreeID: -3, TreeSize: 1 << 40, TimestampNanos: 12345}
+	got, err := ParseToken(want.Marshal())
+	if err != nil {
+		t.Fatalf("ParseToken(): %v", err)
+	}
+	if got != want {
+		t.Errorf("ParseToken() = %+v, want %+v", got, want)
+	}
+	for _, b := range [][]byte{nil, {tokenVersion}, append([]byte{2}, want.Marshal()[1:]...), append(want.Marshal(), 0)} {
+		if _, err := ParseToken(b); err == nil {
+			t.Errorf("ParseToken(%x): got nil error, want malformed", b)
+		}
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	ctx := context.Background()
+	i, tree, setRoot := newLog(ctx, t)
+	setRoot(10, 100)
+	info := &grpc.UnaryServerInfo{}
+
+	// Writes are issued the token of the latest root.
+	queue := func(context.Context, interface{}) (interface{}, error) {
+		return &trillian.QueueLeafResponse{}, nil
+	}
+	resp, err := i.UnaryInterceptor(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId}, info, queue)
+	if err != nil {
+		t.Fatalf("QueueLeaf: %v", err)
+	}
+	tok := resp.(*trillian.QueueLeafResponse).SessionToken
+	if want := (Token{TreeID: tree.TreeId, TreeSize: 10, TimestampNanos: 100}).Marshal(); !bytes.Equal(tok, want) {
+		t.Errorf("QueueLeaf token = %x, want %x", tok, want)
+	}
+	add := func(context.Context, interface{}) (interface{}, error) {
+		return &trillian.AddSequencedLeavesResponse{}, nil
+	}
+	resp, err = i.UnaryInterceptor(ctx, &trillian.AddSequencedLeavesRequest{LogId: tree.TreeId}, info, add)
+	if err != nil {
+		t.Fatalf("AddSequencedLeaves: %v", err)
+	}
+	if got := resp.(*trillian.AddSequencedLeavesResponse).SessionToken; !bytes.Equal(got, tok) {
+		t.Errorf("AddSequencedLeaves token = %x, want %x", got, tok)
+	}
+	failed := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "failed")
+	}
+	if _, err := i.UnaryInterceptor(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId}, info, failed); status.Code(err) != codes.Internal {
+		t.Errorf("failed QueueLeaf: got %v, want Internal", err)
+	}
+
+	for _, test := range []struct {
+		desc  string
+		token []byte
+		want  codes.Code
+	}{
+		{desc: "no-token"},
+		{desc: "fresh", token: tok},
+		{desc: "older", token: Token{TreeID: tree.TreeId, TreeSize: 5, TimestampNanos: 50}.Marshal()},
+		{desc: "larger", token: Token{TreeID: tree.TreeId, TreeSize: 11, TimestampNanos: 100}.Marshal(), want: codes.Unavailable},
+		{desc: "later", token: Token{TreeID: tree.TreeId, TreeSize: 10, TimestampNanos: 101}.Marshal(), want: codes.Unavailable},
+		{desc: "other-tree", token: Token{TreeID: tree.TreeId + 1}.Marshal(), want: codes.InvalidArgument},
+		{desc: "malformed", token: []byte("token"), want: codes.InvalidArgument},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			called := false
+			read := func(context.Context, interface{}) (interface{}, error) {
+				called = true
+				return &trillian.GetLeavesByRangeResponse{}, nil
+			}
+			req := &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, Count: 1, SessionToken: test.token}
+			_, err := i.UnaryInterceptor(ctx, req, info, read)
+			if got := status.Code(err); got != test.want {
+				t.Errorf("UnaryInterceptor(): %v, want code %v", err, test.want)
+			}
+			if want := test.want == codes.OK; called != want {
+				t.Errorf("handler called = %v, want %v", called, want)
+			}
+		})
+	}
+
+	// Once the server catches up, the read succeeds.
+	setRoot(11, 101)
+	req := &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId, SessionToken: Token{TreeID: tree.TreeId, TreeSize: 11, TimestampNanos: 101}.Marshal()}
+	if _, err := i.UnaryInterceptor(ctx, req, info, func(context.Context, interface{}) (interface{}, error) { return nil, nil }); err != nil {
+		t.Errorf("read after catching up: %v", err)
+	}
+}
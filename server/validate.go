@@ -46,6 +46,16 @@ func validateGetInclusionProofByHashRequest(req *trillian.GetInclusionProofByHas
 	return nil
 }
 
+func validateGetInclusionProofByIdentityHashRequest(req *trillian.GetInclusionProofByIdentityHashRequest) error {
+	if req.TreeSize <= 0 {
+		return status.Errorf(codes.InvalidArgument, "GetInclusionProofByIdentityHashRequest.TreeSize: %v, want > 0", req.TreeSize)
+	}
+	if len(req.LeafIdentityHash) == 0 {
+		return status.Error(codes.InvalidArgument, "GetInclusionProofByIdentityHashRequest.LeafIdentityHash: empty, want non-empty")
+	}
+	return nil
+}
+
 func validateGetLeavesByRangeRequest(req *trillian.GetLeavesByRangeRequest) error {
 	if req.StartIndex < 0 {
 		return status.Errorf(codes.InvalidArgument, "GetLeavesByRangeRequest.StartIndex: %v, want >= 0", req.StartIndex)
@@ -56,6 +66,25 @@ func validateGetLeavesByRangeRequest(req *trillian.GetLeavesByRangeRequest) erro
 	return nil
 }
 
+// maxLeafIndices is the most leaves which may be requested with
+// GetLeavesByIndices or GetEntryAndProof.
+const maxLeafIndices = 1000
+
+func validateGetLeavesByIndicesRequest(req *trillian.GetLeavesByIndicesRequest) error {
+	if len(req.LeafIndex) == 0 {
+		return status.Error(codes.InvalidArgument, "GetLeavesByIndicesRequest.LeafIndex: empty, want non-empty")
+	}
+	if len(req.LeafIndex) > maxLeafIndices {
+		return status.Errorf(codes.InvalidArgument, "GetLeavesByIndicesRequest.LeafIndex: %d indices, want <= %d", len(req.LeafIndex), maxLeafIndices)
+	}
+	for i, index := range req.LeafIndex {
+		if index < 0 {
+			return status.Errorf(codes.InvalidArgument, "GetLeavesByIndicesRequest.LeafIndex[%d]: %v, want >= 0", i, index)
+		}
+	}
+	return nil
+}
+
 func validateGetConsistencyProofRequest(req *trillian.GetConsistencyProofRequest) error {
 	if req.FirstTreeSize <= 0 {
 		return status.Errorf(codes.InvalidArgument, "GetConsistencyProofRequest.FirstTreeSize: %v, want > 0", req.FirstTreeSize)
@@ -69,6 +98,52 @@ func validateGetConsistencyProofRequest(req *trillian.GetConsistencyProofRequest
 	return nil
 }
 
+func validateGetConsistencyProofByRootHashRequest(req *trillian.GetConsistencyProofByRootHashRequest) error {
+	if len(req.FirstRootHash) == 0 {
+		return status.Error(codes.InvalidArgument, "GetConsistencyProofByRootHashRequest.FirstRootHash: empty, want non-empty")
+	}
+	if len(req.SecondRootHash) == 0 {
+		return status.Error(codes.InvalidArgument, "GetConsistencyProofByRootHashRequest.SecondRootHash: empty, want non-empty")
+	}
+	return nil
+}
+
+func validateListSignedLogRootsRequest(req *trillian.ListSignedLogRootsRequest) error {
+	if req.PageSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.PageSize: %v, want >= 0", req.PageSize)
+	}
+	if req.StartTreeSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.StartTreeSize: %v, want >= 0", req.StartTreeSize)
+	}
+	if req.EndTreeSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.EndTreeSize: %v, want >= 0", req.EndTreeSize)
+	}
+	if req.StartTime != nil {
+		if err := req.StartTime.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.StartTime: %v", err)
+		}
+	}
+	if req.EndTime != nil {
+		if err := req.EndTime.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "ListSignedLogRootsRequest.EndTime: %v", err)
+		}
+	}
+	return nil
+}
+
+func validateGetRangeInclusionProofRequest(req *trillian.GetRangeInclusionProofRequest) error {
+	if req.StartIndex < 0 {
+		return status.Errorf(codes.InvalidArgument, "GetRangeInclusionProofRequest.StartIndex: %v, want >= 0", req.StartIndex)
+	}
+	if req.EndIndex <= req.StartIndex {
+		return status.Errorf(codes.InvalidArgument, "GetRangeInclusionProofRequest.EndIndex: %v <= StartIndex: %v, want > ", req.EndIndex, req.StartIndex)
+	}
+	if req.TreeSize < req.EndIndex {
+		return status.Errorf(codes.InvalidArgument, "GetRangeInclusionProofRequest.TreeSize: %v < EndIndex: %v, want >= ", req.TreeSize, req.EndIndex)
+	}
+	return nil
+}
+
 func validateGetEntryAndProofRequest(req *trillian.GetEntryAndProofRequest) error {
 	if req.TreeSize <= 0 {
 		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.TreeSize: %v, want > 0", req.TreeSize)
@@ -79,6 +154,37 @@ func validateGetEntryAndProofRequest(req *trillian.GetEntryAndProofRequest) erro
 	if req.LeafIndex >= req.TreeSize {
 		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.LeafIndex: %v >= TreeSize: %v, want < ", req.LeafIndex, req.TreeSize)
 	}
+	if len(req.AdditionalLeafIndices) >= maxLeafIndices {
+		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.AdditionalLeafIndices: %d indices, want < %d", len(req.AdditionalLeafIndices), maxLeafIndices)
+	}
+	for i, index := range req.AdditionalLeafIndices {
+		if index < 0 {
+			return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.AdditionalLeafIndices[%d]: %v, want >= 0", i, index)
+		}
+		if index >= req.TreeSize {
+			return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.AdditionalLeafIndices[%d]: %v >= TreeSize: %v, want < ", i, index, req.TreeSize)
+		}
+	}
+	return nil
+}
+
+func validateUpdateLeafExtraDataRequest(req *trillian.UpdateLeafExtraDataRequest) error {
+	if req.LeafIndex < 0 {
+		return status.Errorf(codes.InvalidArgument, "UpdateLeafExtraDataRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
+	}
+	if req.Reason == "" {
+		return status.Error(codes.InvalidArgument, "UpdateLeafExtraDataRequest.Reason: empty, want non-empty")
+	}
+	return nil
+}
+
+func validateRedactLeafRequest(req *trillian.RedactLeafRequest) error {
+	if req.LeafIndex < 0 {
+		return status.Errorf(codes.InvalidArgument, "RedactLeafRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
+	}
+	if req.Reason == "" {
+		return status.Error(codes.InvalidArgument, "RedactLeafRequest.Reason: empty, want non-empty")
+	}
 	return nil
 }
 
@@ -87,6 +193,9 @@ func validateAddSequencedLeavesRequest(req *trillian.AddSequencedLeavesRequest)
 	if err := validateLogLeaves(req.Leaves, prefix); err != nil {
 		return err
 	}
+	if n := len(req.LeafChargeTo); n > 0 && n != len(req.Leaves) {
+		return status.Errorf(codes.InvalidArgument, "%v.LeafChargeTo has %d entries, want %d", prefix, n, len(req.Leaves))
+	}
 
 	// Note: Not empty, as verified by validateLogLeaves.
 	nextIndex := req.Leaves[0].LeafIndex
@@ -116,7 +225,8 @@ func validateLogLeaf(leaf *trillian.LogLeaf, errPrefix string) error {
 		return status.Errorf(codes.InvalidArgument, "%v empty", errPrefix)
 	}
 	switch {
-	case len(leaf.LeafValue) == 0:
+	case len(leaf.LeafValue) == 0 && len(leaf.MerkleLeafHash) == 0:
+		// Only pre-hashed leaves may have an empty LeafValue.
 		return status.Errorf(codes.InvalidArgument, "%v.LeafValue: empty", errPrefix)
 	case leaf.LeafIndex < 0:
 		return status.Errorf(codes.InvalidArgument, "%v.LeafIndex: %v, want >= 0", errPrefix, leaf.LeafIndex)
@@ -68,6 +68,16 @@ type AdminStorage interface {
 	CheckDatabaseAccessible(ctx context.Context) error
 }
 
+// TreeImporter is implemented by AdminTX implementations which can insert a
+// tree with a given ID, as needed to replicate trees to standby storage.
+type TreeImporter interface {
+	// ImportTree inserts a copy of tree in storage, keeping its TreeId,
+	// TreeState, CreateTime and UpdateTime. The tree is validated with
+	// ValidateTreeForImport. Returns an AlreadyExists error if a tree with
+	// the same ID exists.
+	ImportTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error)
+}
+
 // AdminWriter provides a write-only interface for tree data.
 type AdminWriter interface {
 	// CreateTree inserts the specified tree in storage, returning a tree
@@ -0,0 +1,457 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package breaker provides a storage.LogStorage wrapper which stops calling
+// the underlying storage for a tree after repeated failures, so that requests
+// fail fast instead of each waiting for a database timeout.
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/klog/v2"
+)
+
+// State is the state of a tree's circuit breaker.
+type State int
+
+const (
+	// Closed means storage operations are allowed.
+	Closed State = iota
+	// Open means storage operations fail immediately.
+	Open
+	// HalfOpen means a single probe operation is allowed, to find out whether
+	// the storage has recovered.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+const logIDLabel = "logid"
+
+var (
+	once        sync.Once
+	stateGauge  monitoring.Gauge
+	trips       monitoring.Counter
+	rejections  monitoring.Counter
+	probeResult monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	stateGauge = mf.NewGauge("storage_breaker_state", "State of the storage circuit breaker for a tree: 0 closed, 1 open, 2 half-open", logIDLabel)
+	trips = mf.NewCounter("storage_breaker_trips", "Number of times the storage circuit breaker for a tree opened", logIDLabel)
+	rejections = mf.NewCounter("storage_breaker_rejections", "Number of storage operations rejected by an open circuit breaker", logIDLabel)
+	probeResult = mf.NewCounter("storage_breaker_probes", "Number of half-open probe operations, by result", logIDLabel, "result")
+}
+
+// Config holds the parameters for the circuit breakers.
+type Config struct {
+	// FailureThreshold is the number of consecutive failed operations on a
+	// tree which opens its breaker. Must be positive.
+	FailureThreshold int
+	// OpenDuration is how long a breaker stays open before allowing a probe
+	// operation. Must be positive.
+	OpenDuration time.Duration
+	// IsFailure reports whether an error returned by storage counts as a
+	// storage failure. Defaults to DefaultIsFailure.
+	IsFailure func(error) bool
+	// TimeSource defaults to clock.System.
+	TimeSource clock.TimeSource
+}
+
+// DefaultIsFailure treats errors which indicate that the storage or the
+// connection to it is unhealthy as failures: the Unavailable, DeadlineExceeded,
+// Aborted and DataLoss codes, network errors and broken database connections.
+// Other errors, including plain errors without a code, which may be caused by
+// the request, count as successes, so that bad requests can't open the breaker
+// for everyone.
+func DefaultIsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, storage.ErrTreeNeedsInit) {
+		return false
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Unavailable, codes.Aborted, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// breaker is the circuit breaker for a single tree.
+type breaker struct {
+	state    State
+	failures int
+	// openUntil is when an Open breaker moves to HalfOpen.
+	openUntil time.Time
+	// probing is set while the HalfOpen probe operation is running.
+	probing bool
+}
+
+// LogStorage wraps a storage.LogStorage with a circuit breaker per tree.
+type LogStorage struct {
+	storage.LogStorage
+	cfg Config
+
+	mu       sync.Mutex
+	breakers map[int64]*breaker
+}
+
+// NewLogStorage returns a LogStorage which guards the tree operations of ls
+// with circuit breakers. While a tree's breaker is open, its operations fail
+// with codes.Unavailable and an errdetails.RetryInfo saying when to retry.
+func NewLogStorage(ls storage.LogStorage, cfg Config, mf monitoring.MetricFactory) *LogStorage {
+	once.Do(func() { createMetrics(mf) })
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = DefaultIsFailure
+	}
+	if cfg.TimeSource == nil {
+		cfg.TimeSource = clock.System
+	}
+	return &LogStorage{LogStorage: ls, cfg: cfg, breakers: make(map[int64]*breaker)}
+}
+
+// State returns the current state of the breaker for treeID.
+func (s *LogStorage) State(treeID int64) State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[treeID]
+	if !ok {
+		return Closed
+	}
+	if b.state == Open && !s.cfg.TimeSource.Now().Before(b.openUntil) {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// allow returns an error if the breaker for treeID rejects the operation.
+// Otherwise, the caller must call the returned function with the outcome of
+// the operation.
+func (s *LogStorage) allow(treeID int64) (func(error), error) {
+	label := strconv.FormatInt(treeID, 10)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[treeID]
+	if !ok {
+		b = &breaker{}
+		s.breakers[treeID] = b
+	}
+
+	now := s.cfg.TimeSource.Now()
+	probe := false
+	switch b.state {
+	case Open:
+		if now.Before(b.openUntil) {
+			rejections.Inc(label)
+			return nil, unavailable(treeID, b.openUntil.Sub(now))
+		}
+		b.state = HalfOpen
+		stateGauge.Set(float64(HalfOpen), label)
+		fallthrough
+	case HalfOpen:
+		if b.probing {
+			rejections.Inc(label)
+			return nil, unavailable(treeID, s.cfg.OpenDuration)
+		}
+		b.probing = true
+		probe = true
+	}
+
+	var doneOnce sync.Once
+	return func(err error) {
+		doneOnce.Do(func() { s.record(treeID, b, probe, s.cfg.IsFailure(err)) })
+	}, nil
+}
+
+func (s *LogStorage) record(treeID int64, b *breaker, probe, failed bool) {
+	label := strconv.FormatInt(treeID, 10)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if probe {
+		b.probing = false
+		result := "success"
+		if failed {
+			result = "failure"
+		}
+		probeResult.Inc(label, result)
+	}
+
+	if !failed {
+		if b.state != Closed {
+			klog.Infof("%d: storage circuit breaker closed", treeID)
+			stateGauge.Set(float64(Closed), label)
+		}
+		b.state, b.failures = Closed, 0
+		return
+	}
+	b.failures++
+	// Only the probe decides whether a breaker which is not closed reopens;
+	// failures of operations started before it opened are ignored.
+	if (!probe && b.state != Closed) || (b.state == Closed && b.failures < s.cfg.FailureThreshold) {
+		return
+	}
+	klog.Warningf("%d: storage circuit breaker opened after %d consecutive failures", treeID, b.failures)
+	b.state = Open
+	b.openUntil = s.cfg.TimeSource.Now().Add(s.cfg.OpenDuration)
+	stateGauge.Set(float64(Open), label)
+	trips.Inc(label)
+}
+
+// unavailable returns the error for a rejected operation, with a hint to
+// retry after delay.
+func unavailable(treeID int64, delay time.Duration) error {
+	st := status.Newf(codes.Unavailable, "storage for tree %d is failing, retry later", treeID)
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// SnapshotForTree implements storage.LogStorage. The returned transaction
+// reports the first failure of any of its reads, or of Commit, to the
+// breaker when it is committed or closed.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	done, err := s.allow(tree.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	return &snapshot{ReadOnlyLogTreeTX: tx, isFailure: s.cfg.IsFailure, done: done}, nil
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	done, err := s.allow(tree.TreeId)
+	if err != nil {
+		return err
+	}
+	err = s.LogStorage.ReadWriteTransaction(ctx, tree, f)
+	done(err)
+	return err
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	done, err := s.allow(tree.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	done(err)
+	return ret, err
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	done, err := s.allow(tree.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	done(err)
+	return ret, err
+}
+
+// CountUnsequenced implements storage.LogStorage.
+func (s *LogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	done, err := s.allow(tree.TreeId)
+	if err != nil {
+		return 0, err
+	}
+	ret, err := s.LogStorage.CountUnsequenced(ctx, tree)
+	done(err)
+	return ret, err
+}
+
+// snapshot records the outcome of a read-only transaction.
+type snapshot struct {
+	storage.ReadOnlyLogTreeTX
+	isFailure func(error) bool
+	done      func(error)
+
+	mu      sync.Mutex
+	failure error
+}
+
+func (t *snapshot) check(err error) error {
+	if err != nil && t.isFailure(err) {
+		t.mu.Lock()
+		if t.failure == nil {
+			t.failure = err
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
+
+func (t *snapshot) finish(err error) {
+	t.mu.Lock()
+	failure := t.failure
+	t.mu.Unlock()
+	if failure == nil {
+		failure = err
+	}
+	t.done(failure)
+}
+
+func (t *snapshot) Commit(ctx context.Context) error {
+	err := t.check(t.ReadOnlyLogTreeTX.Commit(ctx))
+	t.finish(err)
+	return err
+}
+
+func (t *snapshot) Close() error {
+	err := t.ReadOnlyLogTreeTX.Close()
+	t.finish(nil)
+	return err
+}
+
+func (t *snapshot) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]tree.Node, error) {
+	nodes, err := t.ReadOnlyLogTreeTX.GetMerkleNodes(ctx, ids)
+	return nodes, t.check(err)
+}
+
+func (t *snapshot) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByRange(ctx, start, count)
+	return leaves, t.check(err)
+}
+
+func (t *snapshot) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByHash(ctx, leafHashes, orderBySequence)
+	return leaves, t.check(err)
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *snapshot) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := storage.GetLeavesByIndices(ctx, t.ReadOnlyLogTreeTX, indices)
+	return leaves, t.check(err)
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *snapshot) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	leaves, err := r.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence)
+	return leaves, t.check(err)
+}
+
+// GetSignedLogRootBySize implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	root, err := r.GetSignedLogRootBySize(ctx, treeSize)
+	return root, t.check(err)
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	root, err := r.GetSignedLogRootByHash(ctx, rootHash)
+	return root, t.check(err)
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) ListSignedLogRoots(ctx context.Context, rng storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	roots, err := r.ListSignedLogRoots(ctx, rng, limit)
+	return roots, t.check(err)
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader if the
+// underlying transaction does.
+func (t *snapshot) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IntegrationEventReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	events, err := r.ListIntegrationEvents(ctx, before, limit)
+	return events, t.check(err)
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter if the underlying
+// transaction does.
+func (t *snapshot) CountUnsequenced(ctx context.Context) (int64, error) {
+	c, ok := t.ReadOnlyLogTreeTX.(storage.UnsequencedCounter)
+	if !ok {
+		return 0, status.Error(codes.Unimplemented, "storage does not support counting unsequenced leaves")
+	}
+	count, err := c.CountUnsequenced(ctx)
+	return count, t.check(err)
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader if the
+// underlying transaction does.
+func (t *snapshot) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.SequencedIndexReader)
+	if !ok {
+		return 0, 0, status.Error(codes.Unimplemented, "storage does not support finding the range of sequenced leaves")
+	}
+	first, last, err := r.GetSequencedIndexRange(ctx)
+	return first, last, t.check(err)
+}
+
+func (t *snapshot) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	root, err := t.ReadOnlyLogTreeTX.LatestSignedLogRoot(ctx)
+	return root, t.check(err)
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testTree = &trillian.Tree{TreeId: 1234}
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	s := NewLogStorage(ls, Config{FailureThreshold: 2, OpenDuration: time.Minute, TimeSource: ts}, nil)
+
+	var storageErr error
+	ls.EXPECT().ReadWriteTransaction(gomock.Any(), testTree, gomock.Any()).DoAndReturn(
+		func(context.Context, *trillian.Tree, storage.LogTXFunc) error { return storageErr }).AnyTimes()
+	rw := func() error {
+		return s.ReadWriteTransaction(ctx, testTree, func(context.Context, storage.LogTreeTX) error { return nil })
+	}
+	wantState := func(want State) {
+		t.Helper()
+		if got := s.State(testTree.TreeId); got != want {
+			t.Fatalf("State() = %v, want %v", got, want)
+		}
+	}
+
+	// Errors caused by the request don't count.
+	storageErr = status.Error(codes.NotFound, "no such leaf")
+	for i := 0; i < 3; i++ {
+		if err := rw(); status.Code(err) != codes.NotFound {
+			t.Fatalf("ReadWriteTransaction() = %v, want NotFound", err)
+		}
+	}
+	wantState(Closed)
+
+	// Neither do plain errors, which may be caused by the request too.
+	storageErr = errors.New("invalid leaf")
+	for i := 0; i < 3; i++ {
+		if err := rw(); err != storageErr {
+			t.Fatalf("ReadWriteTransaction() = %v, want %v", err, storageErr)
+		}
+	}
+	wantState(Closed)
+
+	storageErr = status.Error(codes.Unavailable, "connection refused")
+	for i := 0; i < 2; i++ {
+		if err := rw(); err != storageErr {
+			t.Fatalf("ReadWriteTransaction() = %v, want %v", err, storageErr)
+		}
+	}
+	wantState(Open)
+
+	ts.Set(ts.Now().Add(20 * time.Second))
+	err := rw()
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		t.Fatalf("ReadWriteTransaction() = %v, want Unavailable", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("Details() = %v, want RetryInfo", st.Details())
+	}
+	if ri, ok := st.Details()[0].(*errdetails.RetryInfo); !ok || ri.RetryDelay.AsDuration() != 40*time.Second {
+		t.Errorf("Details() = %v, want retry after 40s", st.Details())
+	}
+
+	// The probe fails, so the breaker opens again.
+	ts.Set(ts.Now().Add(time.Minute))
+	wantState(HalfOpen)
+	if err := rw(); err != storageErr {
+		t.Fatalf("ReadWriteTransaction() = %v, want %v", err, storageErr)
+	}
+	wantState(Open)
+
+	// The probe succeeds, so the breaker closes.
+	storageErr = nil
+	ts.Set(ts.Now().Add(time.Minute))
+	if err := rw(); err != nil {
+		t.Fatalf("ReadWriteTransaction() = %v", err)
+	}
+	wantState(Closed)
+}
+
+func TestBreakerHalfOpenSingleProbe(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	s := NewLogStorage(ls, Config{FailureThreshold: 1, OpenDuration: time.Minute, TimeSource: ts}, nil)
+
+	storageErr := status.Error(codes.DeadlineExceeded, "timeout")
+	ls.EXPECT().SnapshotForTree(gomock.Any(), testTree).Return(nil, storageErr)
+	if _, err := s.SnapshotForTree(ctx, testTree); err != storageErr {
+		t.Fatalf("SnapshotForTree() = %v, want %v", err, storageErr)
+	}
+
+	ts.Set(ts.Now().Add(time.Minute))
+	tx := storage.NewMockReadOnlyLogTreeTX(ctrl)
+	ls.EXPECT().SnapshotForTree(gomock.Any(), testTree).Return(tx, nil)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(&trillian.SignedLogRoot{}, nil)
+	tx.EXPECT().Commit(gomock.Any()).Return(nil)
+	tx.EXPECT().Close().Return(nil)
+
+	probe, err := s.SnapshotForTree(ctx, testTree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(probe) = %v", err)
+	}
+	// Only one probe may run at a time.
+	if _, err := s.SnapshotForTree(ctx, testTree); status.Code(err) != codes.Unavailable {
+		t.Fatalf("SnapshotForTree() during probe = %v, want Unavailable", err)
+	}
+	if _, err := probe.LatestSignedLogRoot(ctx); err != nil {
+		t.Fatalf("LatestSignedLogRoot() = %v", err)
+	}
+	if err := probe.Commit(ctx); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+	if err := probe.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if got := s.State(testTree.TreeId); got != Closed {
+		t.Errorf("State() = %v, want %v", got, Closed)
+	}
+}
+
+func TestDefaultIsFailure(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: context.Canceled, want: false},
+		{err: storage.ErrTreeNeedsInit, want: false},
+		{err: status.Error(codes.InvalidArgument, "bad"), want: false},
+		{err: status.Error(codes.OutOfRange, "too big"), want: false},
+		{err: errors.New("invalid leaf"), want: false},
+		{err: status.Error(codes.Unknown, "invalid leaf"), want: false},
+		{err: status.Error(codes.Internal, "unexpected leaf count"), want: false},
+		{err: fmt.Errorf("query: %w", driver.ErrBadConn), want: true},
+		{err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: true},
+		{err: status.Error(codes.Aborted, "deadlock"), want: true},
+		{err: context.DeadlineExceeded, want: true},
+		{err: status.Error(codes.Unavailable, "down"), want: true},
+	} {
+		if got := DefaultIsFailure(test.err); got != test.want {
+			t.Errorf("DefaultIsFailure(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -38,6 +38,17 @@ func getTileID(id compact.NodeID) []byte {
 	return bytes[8-bytesCount:]
 }
 
+// TileIDs returns the IDs of the tile that the given node belongs to, and of
+// all the tiles above it, ending with the "virtual" root's pseudo tile.
+func TileIDs(id compact.NodeID) [][]byte {
+	tileID := getTileID(id)
+	ids := make([][]byte, 0, len(tileID)+1)
+	for l := len(tileID); l >= 0; l-- {
+		ids = append(ids, tileID[:l])
+	}
+	return ids
+}
+
 // splitID returns the path from the "virtual" root at level 64 to the root of
 // the tile that the given node belongs to, and the corresponding local address
 // of this node within this tile.
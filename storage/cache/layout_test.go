@@ -52,6 +52,30 @@ func TestGetTileID(t *testing.T) {
 	}
 }
 
+func TestTileIDs(t *testing.T) {
+	for _, tc := range []struct {
+		id   compact.NodeID
+		want [][]byte
+	}{
+		{id: nID(0, 12345), want: [][]byte{{0, 0, 0, 0, 0, 0, 48}, {0, 0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0}, {0, 0}, {0}, {}}},
+		{id: nID(20, 0x14B8DC5C), want: [][]byte{{0x00, 0x01, 0x4B, 0x8D, 0xC5}, {0x00, 0x01, 0x4B, 0x8D}, {0x00, 0x01, 0x4B}, {0x00, 0x01}, {0x00}, {}}},
+		{id: nID(48, 1234), want: [][]byte{{4}, {}}},
+		{id: nID(64, 0), want: [][]byte{{}}},
+	} {
+		t.Run(fmt.Sprintf("%d:%d", tc.id.Level, tc.id.Index), func(t *testing.T) {
+			got := TileIDs(tc.id)
+			if len(got) != len(tc.want) {
+				t.Fatalf("TileIDs: got %x, want %x", got, tc.want)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tc.want[i]) {
+					t.Errorf("TileIDs: got %x, want %x", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
 func TestSplitID(t *testing.T) {
 	for _, tc := range []struct {
 		id            compact.NodeID
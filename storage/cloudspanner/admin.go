@@ -327,6 +327,11 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 	}
 	maxRootDuration := tree.MaxRootDuration.AsDuration()
 
+	options, err := storage.MarshalTreeOptions(tree)
+	if err != nil {
+		return nil, err
+	}
+
 	info := &spannerpb.TreeInfo{
 		TreeId:                treeID,
 		Name:                  tree.DisplayName,
@@ -336,6 +341,7 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		CreateTimeNanos:       now.UnixNano(),
 		UpdateTimeNanos:       now.UnixNano(),
 		MaxRootDurationMillis: int64(maxRootDuration / time.Millisecond),
+		TreeOptions:           options,
 	}
 
 	switch tt := tree.TreeType; tt {
@@ -405,6 +411,11 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	}
 	maxRootDuration := tree.MaxRootDuration.AsDuration()
 
+	options, err := storage.MarshalTreeOptions(tree)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update (just) the mutable fields in treeInfo.
 	now := TimeNow()
 	info.TreeState = ts
@@ -412,6 +423,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	info.Description = tree.Description
 	info.UpdateTimeNanos = now.UnixNano()
 	info.MaxRootDurationMillis = int64(maxRootDuration / time.Millisecond)
+	info.TreeOptions = options
 
 	if err := t.updateTreeInfo(ctx, info); err != nil {
 		return nil, err
@@ -573,6 +585,10 @@ func toTrillianTree(info *spannerpb.TreeInfo) (*trillian.Tree, error) {
 		}
 	}
 
+	if err := storage.UnmarshalTreeOptions(info.TreeOptions, tree); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
 	return tree, nil
 }
 
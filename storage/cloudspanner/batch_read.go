@@ -0,0 +1,99 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"cloud.google.com/go/spanner"
+	"github.com/google/trillian"
+	"golang.org/x/sync/errgroup"
+)
+
+// LeafAuditor is implemented by the LogStorage returned by NewLogStorage, for
+// tools which need to read every leaf of a tree, such as full-tree audits.
+type LeafAuditor interface {
+	// AuditLeaves calls f for every sequenced leaf of the given tree, in no
+	// particular order. The leaves are read with a partitioned batch read, so
+	// that large trees are read in parallel across Spanner splits. Calls to f
+	// are not concurrent. If f returns an error the read is abandoned and the
+	// error returned.
+	AuditLeaves(ctx context.Context, tree *trillian.Tree, f func(*trillian.LogLeaf) error) error
+}
+
+// AuditLeaves implements LeafAuditor.
+func (ls *logStorage) AuditLeaves(ctx context.Context, tree *trillian.Tree, f func(*trillian.LogLeaf) error) error {
+	tb := spanner.StrongRead()
+	if ls.opts.ReadOnlyStaleness > 0 {
+		tb = spanner.ExactStaleness(ls.opts.ReadOnlyStaleness)
+	}
+	return ls.batchReadLeaves(ctx, tree.TreeId, 0, math.MaxInt64, tb, f)
+}
+
+// useBatchRead returns whether a range read of count leaves should use a
+// partitioned batch read.
+func (ls *logStorage) useBatchRead(count int64) bool {
+	return ls.opts.BatchReadThreshold > 0 && count >= ls.opts.BatchReadThreshold
+}
+
+// batchReadLeaves reads the sequenced leaves of a tree with indices in
+// [start, xend) using a BatchReadOnlyTransaction with the given timestamp
+// bound, and calls f for each of them in no particular order. Each partition
+// of the range is read, and its leaf data looked up, independently; at most
+// LogStorageOptions.BatchReadParallelism partitions are processed at once.
+func (ls *logStorage) batchReadLeaves(ctx context.Context, treeID, start, xend int64, tb spanner.TimestampBound, f func(*trillian.LogLeaf) error) error {
+	btx, err := ls.ts.client.BatchReadOnlyTransaction(ctx, tb)
+	if err != nil {
+		return err
+	}
+	defer btx.Cleanup(ctx)
+
+	partitions, err := btx.PartitionQuery(ctx, leafRangeStatement(treeID, start, xend), spanner.PartitionOptions{})
+	if err != nil {
+		return err
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	if p := ls.opts.BatchReadParallelism; p > 0 {
+		g.SetLimit(p)
+	}
+	var mu sync.Mutex
+	for _, p := range partitions {
+		g.Go(func() error {
+			seqLeaves := make(map[string]sequencedLeafDataCols)
+			if err := btx.Execute(gctx, p).Do(addSequencedRow(seqLeaves)); err != nil {
+				return err
+			}
+			if len(seqLeaves) == 0 {
+				return nil
+			}
+			leaves := make(leafmap)
+			if err := readLeafData(gctx, &btx.ReadOnlyTransaction, treeID, seqLeaves, leaves); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, l := range leaves {
+				if err := f(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -22,16 +22,17 @@ import (
 	"math"
 	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/spanner"
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/cloudspanner/spannerpb"
 	"github.com/google/trillian/types"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"go.opencensus.io/trace"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc/codes"
@@ -52,6 +53,28 @@ const (
 WHERE (t.TreeType = 1 OR t.TreeType = 3)
 AND (t.TreeState = 1 OR t.TreeState = 5)
 AND t.Deleted=false`
+
+	countUnsequencedSQL = "SELECT COUNT(*) FROM Unsequenced WHERE TreeID = @tree_id"
+	// countUndequeuedSQL counts the Unsequenced rows which haven't been
+	// marked as dequeued, when the option to expire them is used.
+	countUndequeuedSQL = countUnsequencedSQL + " AND DequeuedTime IS NULL"
+
+	// dequeueFIFOSQL selects the given columns of the oldest queued leaves of a
+	// FIFO_DEQUEUE_ORDER tree, across all of its buckets.
+	dequeueFIFOSQL = `SELECT %s FROM Unsequenced
+WHERE TreeID = @tree_id AND QueueTimestampNanos <= @cutoff
+ORDER BY QueueTimestampNanos, LeafIdentityHash LIMIT @limit`
+	// dequeueUndequeuedFIFOSQL is dequeueFIFOSQL skipping the rows which have
+	// been marked as dequeued, when the option to expire them is used.
+	dequeueUndequeuedFIFOSQL = `SELECT %s FROM Unsequenced
+WHERE TreeID = @tree_id AND QueueTimestampNanos <= @cutoff AND DequeuedTime IS NULL
+ORDER BY QueueTimestampNanos, LeafIdentityHash LIMIT @limit`
+	// dequeueUndequeuedBucketsSQL selects the given columns of the rows in the
+	// given bucket ranges which haven't been marked as dequeued, in key order,
+	// when the option to expire them is used.
+	dequeueUndequeuedBucketsSQL = `SELECT %s FROM Unsequenced
+WHERE TreeID = @tree_id AND DequeuedTime IS NULL AND (%s)
+ORDER BY Bucket, QueueTimestampNanos, MerkleLeafHash`
 )
 
 // LogStorageOptions are tuning, experiments and workarounds that can be used.
@@ -66,6 +89,28 @@ type LogStorageOptions struct {
 	// DequeueAcrossMerkleBucketsRangeFraction specifies the fraction of Merkle
 	// keyspace to dequeue from when using multi-bucket-dequeue.
 	DequeueAcrossMerkleBucketsRangeFraction float64
+	// BatchReadThreshold is the number of leaves at or above which
+	// GetLeavesByRange, when called on a snapshot, uses a partitioned
+	// BatchReadOnlyTransaction so that the read is parallelized across
+	// splits. Zero disables batch reads.
+	BatchReadThreshold int64
+	// BatchReadParallelism bounds the number of partitions of a batch read
+	// which are read concurrently. Zero means no limit.
+	BatchReadParallelism int
+	// ExpireDequeuedEntries controls whether UpdateSequencedLeaves sets the
+	// DequeuedTime of the Unsequenced rows of the leaves it sequences, leaving
+	// them to the table's row deletion policy, rather than deleting them.
+	// DequeueLeaves and CountUnsequenced skip the rows which have been marked.
+	ExpireDequeuedEntries bool
+	// ExpireSupersededTreeHeads controls whether StoreSignedLogRoot sets the
+	// SupersededTime of the tree head it supersedes, so that old tree heads are
+	// removed by the table's row deletion policy rather than kept forever.
+	//
+	// As earlier roots may then be gone, the storage doesn't implement
+	// storage.RootHistoryReader, so GetConsistencyProofByRootHash and
+	// ListSignedLogRoots fail with Unimplemented whether or not this is set.
+	// If that interface is ever implemented, it must fail while this is set.
+	ExpireSupersededTreeHeads bool
 }
 
 var (
@@ -76,6 +121,8 @@ var (
 	colMerkleLeafHash      = "MerkleLeafHash"
 	colSequenceNumber      = "SequenceNumber"
 	colQueueTimestampNanos = "QueueTimestampNanos"
+	colDequeuedTime        = "DequeuedTime"
+	colSupersededTime      = "SupersededTime"
 )
 
 type leafDataCols struct {
@@ -170,8 +217,29 @@ func (ls *logStorage) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	return ids, nil
 }
 
+// CountUnsequenced implements storage.LogStorage.
+func (ls *logStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	sql := countUnsequencedSQL
+	if ls.opts.ExpireDequeuedEntries {
+		sql = countUndequeuedSQL
+	}
+	stmt := spanner.NewStatement(sql)
+	stmt.Params["tree_id"] = tree.TreeId
+	var count int64
+	if err := ls.readOnlyTX().Query(ctx, stmt).Do(func(r *spanner.Row) error {
+		return r.Columns(&count)
+	}); err != nil {
+		return 0, fmt.Errorf("problem executing countUnsequencedSQL: %v", err)
+	}
+	return count, nil
+}
+
 func newLogCache(tree *trillian.Tree) (*cache.SubtreeCache, error) {
-	return cache.NewLogSubtreeCache(rfc6962.DefaultHasher), nil
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewLogSubtreeCache(hasher), nil
 }
 
 func (ls *logStorage) begin(ctx context.Context, tree *trillian.Tree, readonly bool, stx spanRead) (*logTX, error) {
@@ -232,8 +300,9 @@ func (ls *logStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leav
 		return nil, status.Errorf(codes.Internal, "got unexpected config type for Log operation: %T", treeConfig)
 	}
 
-	now := time.Now().UTC().Unix()
-	bucketPrefix := (now % config.NumUnseqBuckets) << 8
+	// Bucket by the queue timestamp, rather than the local clock, so that the
+	// caller's time source decides where leaves are queued.
+	bucketPrefix := (qTimestamp.UTC().Unix() % config.NumUnseqBuckets) << 8
 
 	results := make([]*trillian.QueuedLogLeaf, len(leaves))
 	writeDupes := make(map[string][]int)
@@ -522,12 +591,26 @@ func (tx *logTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLo
 			writeRev,
 			logRoot.Metadata,
 		})
+	ms := []*spanner.Mutation{m}
+
+	if tx.ls.opts.ExpireSupersededTreeHeads {
+		sth, err := tx.currentSTH(ctx)
+		if err != nil && err != storage.ErrTreeNeedsInit {
+			return err
+		}
+		if sth != nil && sth.TreeRevision != writeRev {
+			ms = append(ms, spanner.Update(
+				"TreeHeads",
+				[]string{"TreeID", "TreeRevision", colSupersededTime},
+				[]interface{}{int64(tx.treeID), sth.TreeRevision, spanner.CommitTimestamp}))
+		}
+	}
 
 	stx, ok := tx.stx.(*spanner.ReadWriteTransaction)
 	if !ok {
 		return ErrWrongTXType
 	}
-	return stx.BufferWrite([]*spanner.Mutation{m})
+	return stx.BufferWrite(ms)
 }
 
 func readLeaves(ctx context.Context, stx *spanner.ReadOnlyTransaction, logID int64, ids [][]byte, f func(*trillian.LogLeaf)) error {
@@ -577,6 +660,22 @@ func (tx *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time)
 		return tx.GetLeavesByRange(ctx, sth.TreeSize, int64(limit))
 	}
 
+	cols := []string{"Bucket", colQueueTimestampNanos, colMerkleLeafHash, colLeafIdentityHash}
+
+	// FIFO trees are dequeued from all buckets at once, oldest first, at the
+	// cost of a query over the whole of the tree's queue.
+	if tx.dequeueOrder == trillian.DequeueOrder_FIFO_DEQUEUE_ORDER {
+		sql := fmt.Sprintf(dequeueFIFOSQL, strings.Join(cols, ","))
+		if tx.ls.opts.ExpireDequeuedEntries {
+			sql = fmt.Sprintf(dequeueUndequeuedFIFOSQL, strings.Join(cols, ","))
+		}
+		stmt := spanner.NewStatement(sql)
+		stmt.Params["tree_id"] = tx.treeID
+		stmt.Params["cutoff"] = cutoff.UnixNano()
+		stmt.Params["limit"] = int64(limit)
+		return tx.dequeueRows(tx.stx.Query(ctx, stmt), limit)
+	}
+
 	// Decide which bucket(s) to dequeue from.
 	// The high 8 bits of the bucket key is a time based ring - at any given
 	// moment, FEs queueing entries will be adding them to different buckets
@@ -600,37 +699,52 @@ func (tx *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time)
 	}
 	suffixEnd := suffixStart + int64(math.Ceil(suffixBuckets*suffixFraction))
 
-	keysets := []spanner.KeySet{}
+	// The bucket ranges to dequeue from, inclusive.
+	var ranges [][2]int64
 	if suffixEnd < suffixBuckets {
-		keysets = append(keysets,
-			spanner.KeyRange{
-				Start: spanner.Key{tx.treeID, prefix | suffixStart},
-				End:   spanner.Key{tx.treeID, prefix | suffixEnd},
-				Kind:  spanner.ClosedClosed,
-			})
+		ranges = append(ranges, [2]int64{prefix | suffixStart, prefix | suffixEnd})
 	} else {
 		// The range is too big and wraps around, overflowing a byte value, so we'll
 		// start the second range at 0 and end at the upper limit modulo suffixBuckets:
 		suffixEnd %= suffixBuckets
-		keysets = append(keysets,
-			spanner.KeyRange{
-				Start: spanner.Key{tx.treeID, prefix | suffixStart},
-				End:   spanner.Key{tx.treeID, prefix | suffixBuckets - 1},
-				Kind:  spanner.ClosedClosed,
-			},
-			spanner.KeyRange{
-				Start: spanner.Key{tx.treeID, prefix},
-				// XXX: When suffixFraction = 1, this produces an overlapping range at suffixStart
-				End:  spanner.Key{tx.treeID, prefix | suffixEnd},
-				Kind: spanner.ClosedClosed,
-			})
+		ranges = append(ranges,
+			[2]int64{prefix | suffixStart, prefix | suffixBuckets - 1},
+			// XXX: When suffixFraction = 1, this produces an overlapping range at suffixStart
+			[2]int64{prefix, prefix | suffixEnd})
+	}
+
+	// The rows marked as dequeued, waiting to be expired, can't be skipped by
+	// a read of the key ranges, so they are filtered out by a query instead.
+	if tx.ls.opts.ExpireDequeuedEntries {
+		conds := make([]string, 0, len(ranges))
+		stmt := spanner.Statement{Params: map[string]interface{}{"tree_id": tx.treeID}}
+		for i, r := range ranges {
+			conds = append(conds, fmt.Sprintf("Bucket BETWEEN @start%d AND @end%d", i, i))
+			stmt.Params[fmt.Sprintf("start%d", i)] = r[0]
+			stmt.Params[fmt.Sprintf("end%d", i)] = r[1]
+		}
+		stmt.SQL = fmt.Sprintf(dequeueUndequeuedBucketsSQL, strings.Join(cols, ","), strings.Join(conds, " OR "))
+		return tx.dequeueRows(tx.stx.Query(ctx, stmt), limit)
 	}
 
+	keysets := make([]spanner.KeySet, 0, len(ranges))
+	for _, r := range ranges {
+		keysets = append(keysets, spanner.KeyRange{
+			Start: spanner.Key{tx.treeID, r[0]},
+			End:   spanner.Key{tx.treeID, r[1]},
+			Kind:  spanner.ClosedClosed,
+		})
+	}
+	return tx.dequeueRows(tx.stx.Read(ctx, unseqTable, spanner.KeySets(keysets...), cols), limit)
+}
+
+// dequeueRows reads up to limit queued leaves from rows, which must have the
+// columns selected by DequeueLeaves, and remembers them for
+// UpdateSequencedLeaves.
+func (tx *logTX) dequeueRows(rows *spanner.RowIterator, limit int) ([]*trillian.LogLeaf, error) {
 	errBreak := errors.New("break")
 	ret := make([]*trillian.LogLeaf, 0, limit)
-	if err := tx.stx.Read(ctx, unseqTable, spanner.KeySets(keysets...),
-		[]string{"Bucket", colQueueTimestampNanos, colMerkleLeafHash, colLeafIdentityHash},
-	).Do(func(r *spanner.Row) error {
+	if err := rows.Do(func(r *spanner.Row) error {
 		var l trillian.LogLeaf
 		var qe QueuedEntry
 		if err := r.Columns(&qe.bucket, &qe.timestamp, &l.MerkleLeafHash, &l.LeafIdentityHash); err != nil {
@@ -703,6 +817,11 @@ func (tx *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		}
 
 		m2 := spanner.Delete(unseqTable, spanner.Key{tx.treeID, qe.bucket, qe.timestamp, l.MerkleLeafHash})
+		if tx.ls.opts.ExpireDequeuedEntries {
+			m2 = spanner.Update(unseqTable,
+				[]string{"TreeID", "Bucket", colQueueTimestampNanos, colMerkleLeafHash, colDequeuedTime},
+				[]interface{}{tx.treeID, qe.bucket, qe.timestamp, l.MerkleLeafHash, spanner.CommitTimestamp})
+		}
 
 		tx.numSequenced++
 		if err := stx.BufferWrite([]*spanner.Mutation{m1, m2}); err != nil {
@@ -832,6 +951,59 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 		count = xend - start
 	}
 
+	leaves := make(leafmap)
+	if err := tx.readLeafRange(ctx, start, xend, leaves); err != nil {
+		return nil, err
+	}
+
+	// Results need to be returned in order [start, end), all of which
+	// should be available (as we restricted xend/count to TreeSize).
+	if got := int64(len(leaves)); got > count {
+		return nil, fmt.Errorf("unexpected number of leaves %d, want <= %d", got, count)
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, count)
+	for i := start; i < (start + count); i++ {
+		l, ok := leaves[i]
+		if !ok {
+			if i < int64(currentSTH.TreeSize) {
+				return nil, fmt.Errorf("missing expected index %d", i)
+			}
+			break
+		}
+		ret = append(ret, l)
+	}
+	return ret, nil
+}
+
+// readLeafRange reads the sequenced leaves with indices in [start, xend) into
+// leaves. Large ranges read through a snapshot are read with a partitioned
+// batch read if that is enabled.
+func (tx *logTX) readLeafRange(ctx context.Context, start, xend int64, leaves leafmap) error {
+	if ro, ok := tx.stx.(*spanner.ReadOnlyTransaction); ok && tx.ls.useBatchRead(xend-start) {
+		// The batch read must see the same data as the rest of the snapshot,
+		// whose timestamp was fixed when the latest root was read.
+		ts, err := ro.Timestamp()
+		if err != nil {
+			return err
+		}
+		return tx.ls.batchReadLeaves(ctx, tx.treeID, start, xend, spanner.ReadTimestamp(ts), func(l *trillian.LogLeaf) error {
+			leaves[l.LeafIndex] = l
+			return nil
+		})
+	}
+
+	seqLeaves := make(map[string]sequencedLeafDataCols)
+	if err := tx.stx.Query(ctx, leafRangeStatement(tx.treeID, start, xend)).Do(addSequencedRow(seqLeaves)); err != nil {
+		return err
+	}
+	return readLeafData(ctx, tx.stx, tx.treeID, seqLeaves, leaves)
+}
+
+// leafRangeStatement returns a query for the SequencedLeafData rows with
+// indices in [start, xend). The query is root-partitionable, so it can also be
+// used for batch reads.
+func leafRangeStatement(treeID, start, xend int64) spanner.Statement {
 	// TODO: replace with INNER JOIN when spannertest supports JOINs
 	// https://github.com/googleapis/google-cloud-go/tree/master/spanner/spannertest
 	stmt := spanner.NewStatement(
@@ -847,27 +1019,34 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 		   TreeID = @tree_id AND 
 		   SequenceNumber >= @start AND 
 		   SequenceNumber < @xend`)
-	stmt.Params["tree_id"] = tx.treeID
+	stmt.Params["tree_id"] = treeID
 	stmt.Params["start"] = start
 	stmt.Params["xend"] = xend
-	seqLeaves := make(map[string]sequencedLeafDataCols)
-	if err := tx.stx.Query(ctx, stmt).Do(func(r *spanner.Row) error {
+	return stmt
+}
+
+// addSequencedRow returns a function which adds a SequencedLeafData row to
+// seqLeaves, keyed by LeafIdentityHash.
+func addSequencedRow(seqLeaves map[string]sequencedLeafDataCols) func(r *spanner.Row) error {
+	return func(r *spanner.Row) error {
 		var seqLeaf sequencedLeafDataCols
 		if err := r.ToStruct(&seqLeaf); err != nil {
 			return err
 		}
 		seqLeaves[string(seqLeaf.LeafIdentityHash)] = seqLeaf
 		return nil
-	}); err != nil {
-		return nil, err
 	}
+}
 
+// readLeafData reads the LeafData rows for seqLeaves, and adds the resulting
+// leaves to leaves.
+func readLeafData(ctx context.Context, stx spanRead, treeID int64, seqLeaves map[string]sequencedLeafDataCols, leaves leafmap) error {
 	idHashes := make([][]byte, 0, len(seqLeaves))
 	for _, l := range seqLeaves {
 		idHashes = append(idHashes, l.LeafIdentityHash)
 	}
 
-	stmt = spanner.NewStatement(
+	stmt := spanner.NewStatement(
 		`SELECT 
 		   TreeID,
 		   LeafIdentityHash, 
@@ -879,33 +1058,9 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 		 WHERE 
 		   TreeID = @tree_id AND 
 		   LeafIdentityHash IN UNNEST(@id_hashes)`)
-	stmt.Params["tree_id"] = tx.treeID
+	stmt.Params["tree_id"] = treeID
 	stmt.Params["id_hashes"] = idHashes
-
-	// Results need to be returned in order [start, end), all of which
-	// should be available (as we restricted xend/count to TreeSize).
-	leaves := make(leafmap)
-	if err := tx.stx.Query(ctx, stmt).
-		Do(leaves.addFullRow(seqLeaves)); err != nil {
-		return nil, err
-	}
-
-	if got := int64(len(leaves)); got > count {
-		return nil, fmt.Errorf("unexpected number of leaves %d, want <= %d", got, count)
-	}
-
-	ret := make([]*trillian.LogLeaf, 0, count)
-	for i := start; i < (start + count); i++ {
-		l, ok := leaves[i]
-		if !ok {
-			if i < int64(currentSTH.TreeSize) {
-				return nil, fmt.Errorf("missing expected index %d", i)
-			}
-			break
-		}
-		ret = append(ret, l)
-	}
-	return ret, nil
+	return stx.Query(ctx, stmt).Do(leaves.addFullRow(seqLeaves))
 }
 
 // leafSlice is a slice of LogLeaf which knows how to populate itself from
@@ -15,11 +15,19 @@
 package cloudspanner
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"reflect"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/spanner"
+	"github.com/google/trillian"
 	"github.com/google/trillian/integration/storagetest"
 	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
 )
 
 func TestLogSuite(t *testing.T) {
@@ -33,3 +41,108 @@ func TestLogSuite(t *testing.T) {
 
 	storagetest.RunLogStorageTests(t, storageFactory)
 }
+
+func TestLogSuiteWithExpiry(t *testing.T) {
+	ctx := context.Background()
+	db := GetTestDB(ctx, t)
+
+	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		t.Cleanup(func() { cleanTestDB(ctx, t, db) })
+		opts := LogStorageOptions{ExpireDequeuedEntries: true, ExpireSupersededTreeHeads: true}
+		return NewLogStorageWithOpts(db, opts), NewAdminStorage(db)
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
+
+func TestGetLeavesByRangeBatchRead(t *testing.T) {
+	if *cloudDBPath == ":memory:" {
+		t.Skip("The in-memory fake doesn't support batch reads")
+	}
+	ctx := context.Background()
+	db := GetTestDB(ctx, t)
+	t.Cleanup(func() { cleanTestDB(ctx, t, db) })
+
+	s := NewLogStorageWithOpts(db, LogStorageOptions{BatchReadThreshold: 2, BatchReadParallelism: 2})
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(db), stestonly.PreorderedLogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	const leafCount = 10
+	leaves := make([]*trillian.LogLeaf, 0, leafCount)
+	for i := int64(0); i < leafCount; i++ {
+		data := []byte{byte(i)}
+		hash := sha256.Sum256(data)
+		leaves = append(leaves, &trillian.LogLeaf{LeafIndex: i, LeafValue: data, LeafIdentityHash: hash[:], MerkleLeafHash: hash[:]})
+	}
+	if _, err := s.AddSequencedLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	root, err := (&types.LogRootV1{TreeSize: leafCount, RootHash: []byte{0}}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer func() { _ = tx.Close() }()
+	got, err := tx.GetLeavesByRange(ctx, 2, 5)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("GetLeavesByRange() returned %d leaves, want 5", len(got))
+	}
+	for i, leaf := range got {
+		if want := leaves[i+2]; leaf.LeafIndex != want.LeafIndex || !bytes.Equal(leaf.LeafValue, want.LeafValue) {
+			t.Errorf("leaf %d = %v, want %v", i, leaf, want)
+		}
+	}
+}
+
+func TestExpireSupersededTreeHeads(t *testing.T) {
+	ctx := context.Background()
+	db := GetTestDB(ctx, t)
+	t.Cleanup(func() { cleanTestDB(ctx, t, db) })
+
+	s := NewLogStorageWithOpts(db, LogStorageOptions{ExpireSupersededTreeHeads: true})
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(db), stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	for size := uint64(0); size < 3; size++ {
+		root, err := (&types.LogRootV1{TreeSize: size, RootHash: []byte{byte(size)}}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(): %v", err)
+		}
+		if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+		}); err != nil {
+			t.Fatalf("ReadWriteTransaction(): %v", err)
+		}
+	}
+
+	superseded := make(map[int64]bool)
+	if err := db.Single().Read(ctx, "TreeHeads", spanner.Key{tree.TreeId}.AsPrefix(), []string{"TreeRevision", colSupersededTime}).Do(func(r *spanner.Row) error {
+		var rev int64
+		var ts spanner.NullTime
+		if err := r.Columns(&rev, &ts); err != nil {
+			return err
+		}
+		superseded[rev] = ts.Valid
+		return nil
+	}); err != nil {
+		t.Fatalf("Read(TreeHeads): %v", err)
+	}
+	if want := map[int64]bool{0: true, 1: true, 2: false}; !reflect.DeepEqual(superseded, want) {
+		t.Errorf("superseded tree heads = %v, want %v", superseded, want)
+	}
+}
@@ -14,39 +14,53 @@ aWVyYXJjaHkpICsgKGFsbCByb3dzIG9mIHRoYXQgdGFibGUncwotLSAgfCBkZXNjZW5kZW50IHRh
 YmxlcyB0aGF0IHNoYXJlIHRoZSByb3cncyBwcmltYXJ5IGtleSkgKyAoYWxsIHJvd3Mgb2YKLS0g
 IHwgaW50ZXJsZWF2ZWQgaW5kZXhlcyB0aGF0IHNoYXJlIHRoZSByb3cncyBwcmltYXJ5IGtleSku
 Ci0tICB8IChodHRwczovL2Nsb3VkLmdvb2dsZS5jb20vc3Bhbm5lci9kb2NzL3NjaGVtYS1hbmQt
-ZGF0YS1tb2RlbCkKLS0gc28gd2UgZG9uJ3QgaW50ZXJsZWF2ZSBvdXIgdGFibGVzLgoKQ1JFQVRF
-IFRBQkxFIFRyZWVSb290cygKICBUcmVlSUQgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEws
-CiAgVHJlZVN0YXRlICAgICAgICAgICAgIElOVDY0IE5PVCBOVUxMLAogIFRyZWVUeXBlICAgICAg
-ICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBUcmVlSW5mbyAgICAgICAgICAgICAgQllURVMoMjA5
-NzE1MikgTk9UIE5VTEwsCiAgRGVsZXRlZCAgICAgICAgICAgICAgIEJPT0wgTk9UIE5VTEwsCiAg
-RGVsZXRlVGltZU1pbGxpcyAgICAgIElOVDY0LAopIFBSSU1BUlkgS0VZKFRyZWVJRCk7CgpDUkVB
-VEUgSU5ERVggVHJlZVJvb3RzQnlEZWxldGVkCiAgT04gVHJlZVJvb3RzIChEZWxldGVkKTsKCkNS
-RUFURSBUQUJMRSBUcmVlSGVhZHMoCiAgVHJlZUlEICAgICAgICAgICAgICAgICAgSU5UNjQgTk9U
-IE5VTEwsCiAgVGltZXN0YW1wTmFub3MgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgVHJlZVNp
-emUgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgUm9vdEhhc2ggICAgICAgICAgICAg
-ICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBSb290U2lnbmF0dXJlICAgICAgICAgICBCWVRFUygx
-MDI0KSBOT1QgTlVMTCwKICBUcmVlUmV2aXNpb24gICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwK
-ICBUcmVlTWV0YWRhdGEgICAgICAgICAgICBCWVRFUygyMDk3MTUyKSwKKSBQUklNQVJZIEtFWShU
-cmVlSUQsIFRyZWVSZXZpc2lvbiBERVNDKTsKCkNSRUFURSBUQUJMRSBTdWJ0cmVlRGF0YSgKICBU
-cmVlSUQgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBTdWJ0cmVlSUQgICBCWVRFUygyNTYpIE5PVCBO
-VUxMLAogIFJldmlzaW9uICAgIElOVDY0IE5PVCBOVUxMLAogIFN1YnRyZWUgICAgIEJZVEVTKE1B
-WCkgTk9UIE5VTEwKKSBQUklNQVJZIEtFWShUcmVlSUQsIFN1YnRyZWVJRCwgUmV2aXNpb24gREVT
-Qyk7CgpDUkVBVEUgVEFCTEUgTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICBJTlQ2NCBO
-T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTGVh
-ZlZhbHVlICAgICAgICAgICBCWVRFUyhNQVgpIE5PVCBOVUxMLAogIEV4dHJhRGF0YSAgICAgICAg
-ICAgQllURVMoTUFYKSwKICBRdWV1ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBOVUxMLAopIFBS
-SU1BUlkgS0VZKFRyZWVJRCwgTGVhZklkZW50aXR5SGFzaCk7CgpDUkVBVEUgVEFCTEUgU2VxdWVu
-Y2VkTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAg
-U2VxdWVuY2VOdW1iZXIgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFz
-aCAgICAgICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBNZXJrbGVMZWFmSGFzaCAgICAgICAgICBC
-WVRFUygyNTYpIE5PVCBOVUxMLAogIEludGVncmF0ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBO
-VUxMLAopIFBSSU1BUlkgS0VZKFRyZWVJRCwgU2VxdWVuY2VOdW1iZXIpOwoKQ1JFQVRFIElOREVY
-IFNlcXVlbmNlQnlNZXJrbGVIYXNoCiAgT04gU2VxdWVuY2VkTGVhZkRhdGEoVHJlZUlELCBNZXJr
-bGVMZWFmSGFzaCkKICBTVE9SSU5HKExlYWZJZGVudGl0eUhhc2gpOwoKQ1JFQVRFIFRBQkxFIFVu
-c2VxdWVuY2VkKAogIFRyZWVJRCAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgQnVj
-a2V0ICAgICAgICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBRdWV1ZVRpbWVzdGFtcE5hbm9z
-ICAgIElOVDY0IE5PVCBOVUxMLAogIE1lcmtsZUxlYWZIYXNoICAgICAgICAgQllURVMoMjU2KSBO
-T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCikg
-UFJJTUFSWSBLRVkgKFRyZWVJRCwgQnVja2V0LCBRdWV1ZVRpbWVzdGFtcE5hbm9zLCBNZXJrbGVM
-ZWFmSGFzaCk7Cg==
+ZGF0YS1tb2RlbCkKLS0gc28gd2UgZG9uJ3QgaW50ZXJsZWF2ZSBvdXIgdGFibGVzLgotLQotLSBS
+b3dzIHdoaWNoIGFyZSBubyBsb25nZXIgbmVlZGVkIGNhbiBiZSBsZWZ0IGZvciBTcGFubmVyIHRv
+IGdhcmJhZ2UgY29sbGVjdCwKLS0gcmF0aGVyIHRoYW4gYmVpbmcgZGVsZXRlZCBieSBUcmlsbGlh
+biwgYnkgc2V0dGluZyBhIHRpbWVzdGFtcCBjb2x1bW4gd2hpY2gKLS0gaXMgdGhlIHN1YmplY3Qg
+b2YgdGhlIHRhYmxlJ3Mgcm93IGRlbGV0aW9uIHBvbGljeS4gVHJpbGxpYW4gb25seSBzZXRzIHRo
+ZXNlCi0tIGNvbHVtbnMgaWYgYXNrZWQgdG8gKHNlZSBMb2dTdG9yYWdlT3B0aW9ucyksIG90aGVy
+d2lzZSB0aGV5J3JlIE5VTEwgYW5kIHRoZQotLSBwb2xpY2llcyBkb24ndCBhcHBseS4gRXhwaXJp
+bmcgc3VwZXJzZWRlZCBUcmVlSGVhZHMgbWVhbnMgdGhhdCBlYXJsaWVyIHJvb3RzCi0tIGNhbid0
+IGJlIGxvb2tlZCB1cCwgd2hpY2ggaXMgd2h5IHRoaXMgc3RvcmFnZSBkb2Vzbid0IHNlcnZlIHRo
+ZSBSUENzIHdoaWNoCi0tIG5lZWQgdGhlbSwgc3VjaCBhcyBHZXRDb25zaXN0ZW5jeVByb29mQnlS
+b290SGFzaCBhbmQgTGlzdFNpZ25lZExvZ1Jvb3RzLgoKQ1JFQVRFIFRBQkxFIFRyZWVSb290cygK
+ICBUcmVlSUQgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgVHJlZVN0YXRlICAgICAg
+ICAgICAgIElOVDY0IE5PVCBOVUxMLAogIFRyZWVUeXBlICAgICAgICAgICAgICBJTlQ2NCBOT1Qg
+TlVMTCwKICBUcmVlSW5mbyAgICAgICAgICAgICAgQllURVMoMjA5NzE1MikgTk9UIE5VTEwsCiAg
+RGVsZXRlZCAgICAgICAgICAgICAgIEJPT0wgTk9UIE5VTEwsCiAgRGVsZXRlVGltZU1pbGxpcyAg
+ICAgIElOVDY0LAopIFBSSU1BUlkgS0VZKFRyZWVJRCk7CgpDUkVBVEUgSU5ERVggVHJlZVJvb3Rz
+QnlEZWxldGVkCiAgT04gVHJlZVJvb3RzIChEZWxldGVkKTsKCkNSRUFURSBUQUJMRSBUcmVlSGVh
+ZHMoCiAgVHJlZUlEICAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgVGltZXN0YW1w
+TmFub3MgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgVHJlZVNpemUgICAgICAgICAgICAgICAg
+SU5UNjQgTk9UIE5VTEwsCiAgUm9vdEhhc2ggICAgICAgICAgICAgICAgQllURVMoMjU2KSBOT1Qg
+TlVMTCwKICBSb290U2lnbmF0dXJlICAgICAgICAgICBCWVRFUygxMDI0KSBOT1QgTlVMTCwKICBU
+cmVlUmV2aXNpb24gICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBUcmVlTWV0YWRhdGEgICAg
+ICAgICAgICBCWVRFUygyMDk3MTUyKSwKICBTdXBlcnNlZGVkVGltZSAgICAgICAgICBUSU1FU1RB
+TVAgT1BUSU9OUyAoYWxsb3dfY29tbWl0X3RpbWVzdGFtcCA9IHRydWUpLAopIFBSSU1BUlkgS0VZ
+KFRyZWVJRCwgVHJlZVJldmlzaW9uIERFU0MpLAogIFJPVyBERUxFVElPTiBQT0xJQ1kgKE9MREVS
+X1RIQU4oU3VwZXJzZWRlZFRpbWUsIElOVEVSVkFMIDcgREFZKSk7CgpDUkVBVEUgVEFCTEUgU3Vi
+dHJlZURhdGEoCiAgVHJlZUlEICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgU3VidHJlZUlEICAgQllU
+RVMoMjU2KSBOT1QgTlVMTCwKICBSZXZpc2lvbiAgICBJTlQ2NCBOT1QgTlVMTCwKICBTdWJ0cmVl
+ICAgICBCWVRFUyhNQVgpIE5PVCBOVUxMCikgUFJJTUFSWSBLRVkoVHJlZUlELCBTdWJ0cmVlSUQs
+IFJldmlzaW9uIERFU0MpOwoKQ1JFQVRFIFRBQkxFIExlYWZEYXRhKAogIFRyZWVJRCAgICAgICAg
+ICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFzaCAgICBCWVRFUygyNTYpIE5P
+VCBOVUxMLAogIExlYWZWYWx1ZSAgICAgICAgICAgQllURVMoTUFYKSBOT1QgTlVMTCwKICBFeHRy
+YURhdGEgICAgICAgICAgIEJZVEVTKE1BWCksCiAgUXVldWVUaW1lc3RhbXBOYW5vcyBJTlQ2NCBO
+T1QgTlVMTCwKKSBQUklNQVJZIEtFWShUcmVlSUQsIExlYWZJZGVudGl0eUhhc2gpOwoKQ1JFQVRF
+IFRBQkxFIFNlcXVlbmNlZExlYWZEYXRhKAogIFRyZWVJRCAgICAgICAgICAgICAgICAgIElOVDY0
+IE5PVCBOVUxMLAogIFNlcXVlbmNlTnVtYmVyICAgICAgICAgIElOVDY0IE5PVCBOVUxMLAogIExl
+YWZJZGVudGl0eUhhc2ggICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTWVya2xlTGVhZkhh
+c2ggICAgICAgICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBJbnRlZ3JhdGVUaW1lc3RhbXBOYW5v
+cyBJTlQ2NCBOT1QgTlVMTCwKKSBQUklNQVJZIEtFWShUcmVlSUQsIFNlcXVlbmNlTnVtYmVyKTsK
+CkNSRUFURSBJTkRFWCBTZXF1ZW5jZUJ5TWVya2xlSGFzaAogIE9OIFNlcXVlbmNlZExlYWZEYXRh
+KFRyZWVJRCwgTWVya2xlTGVhZkhhc2gpCiAgU1RPUklORyhMZWFmSWRlbnRpdHlIYXNoKTsKCkNS
+RUFURSBUQUJMRSBVbnNlcXVlbmNlZCgKICBUcmVlSUQgICAgICAgICAgICAgICAgIElOVDY0IE5P
+VCBOVUxMLAogIEJ1Y2tldCAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgUXVldWVU
+aW1lc3RhbXBOYW5vcyAgICBJTlQ2NCBOT1QgTlVMTCwKICBNZXJrbGVMZWFmSGFzaCAgICAgICAg
+IEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFzaCAgICAgICBCWVRFUygyNTYp
+IE5PVCBOVUxMLAogIERlcXVldWVkVGltZSAgICAgICAgICAgVElNRVNUQU1QIE9QVElPTlMgKGFs
+bG93X2NvbW1pdF90aW1lc3RhbXAgPSB0cnVlKSwKKSBQUklNQVJZIEtFWSAoVHJlZUlELCBCdWNr
+ZXQsIFF1ZXVlVGltZXN0YW1wTmFub3MsIE1lcmtsZUxlYWZIYXNoKSwKICBST1cgREVMRVRJT04g
+UE9MSUNZIChPTERFUl9USEFOKERlcXVldWVkVGltZSwgSU5URVJWQUwgMSBEQVkpKTsK
 `
@@ -444,8 +444,11 @@ type TreeInfo struct {
 	Deleted bool `protobuf:"varint,18,opt,name=deleted,proto3" json:"deleted,omitempty"`
 	// Time of tree deletion, if any.
 	DeleteTimeNanos int64 `protobuf:"varint,19,opt,name=delete_time_nanos,json=deleteTimeNanos,proto3" json:"delete_time_nanos,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// tree_options holds the serialized per-tree options which have no
+	// dedicated field in TreeInfo, see storage.MarshalTreeOptions.
+	TreeOptions   []byte `protobuf:"bytes,20,opt,name=tree_options,json=treeOptions,proto3" json:"tree_options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TreeInfo) Reset() {
@@ -615,6 +618,13 @@ func (x *TreeInfo) GetDeleteTimeNanos() int64 {
 	return 0
 }
 
+func (x *TreeInfo) GetTreeOptions() []byte {
+	if x != nil {
+		return x.TreeOptions
+	}
+	return nil
+}
+
 type isTreeInfo_StorageConfig interface {
 	isTreeInfo_StorageConfig()
 }
@@ -741,7 +751,7 @@ const file_spanner_proto_rawDesc = "" +
 	"\x10LogStorageConfig\x12*\n" +
 	"\x11num_unseq_buckets\x18\x01 \x01(\x03R\x0fnumUnseqBuckets\x12,\n" +
 	"\x12num_merkle_buckets\x18\x02 \x01(\x03R\x10numMerkleBuckets\"\x12\n" +
-	"\x10MapStorageConfig\"\x8c\a\n" +
+	"\x10MapStorageConfig\"\xaf\a\n" +
 	"\bTreeInfo\x12\x17\n" +
 	"\atree_id\x18\x01 \x01(\x03R\x06treeId\x12\x15\n" +
 	"\x06key_id\x18\x02 \x01(\x03R\x05keyId\x12\x12\n" +
@@ -763,7 +773,8 @@ const file_spanner_proto_rawDesc = "" +
 	"\x12map_storage_config\x18\a \x01(\v2\x1b.spannerpb.MapStorageConfigH\x00R\x10mapStorageConfig\x127\n" +
 	"\x18max_root_duration_millis\x18\x11 \x01(\x03R\x15maxRootDurationMillis\x12\x18\n" +
 	"\adeleted\x18\x12 \x01(\bR\adeleted\x12*\n" +
-	"\x11delete_time_nanos\x18\x13 \x01(\x03R\x0fdeleteTimeNanosB\x10\n" +
+	"\x11delete_time_nanos\x18\x13 \x01(\x03R\x0fdeleteTimeNanos\x12!\n" +
+	"\ftree_options\x18\x14 \x01(\fR\vtreeOptionsB\x10\n" +
 	"\x0estorage_configJ\x04\b\f\x10\r\"\xe9\x01\n" +
 	"\bTreeHead\x12\x17\n" +
 	"\atree_id\x18\x01 \x01(\x03R\x06treeId\x12\x19\n" +
@@ -20,11 +20,14 @@ import (
 	"context"
 	"encoding/base64"
 	"flag"
+	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"google.golang.org/api/option"
@@ -44,6 +47,13 @@ var (
 	csDequeueAcrossMerkleBucketsFraction = flag.Float64("cloudspanner_dequeue_bucket_fraction", 0.75, "Fraction of merkle keyspace to dequeue from, set to zero to disable.")
 	csReadOnlyStaleness                  = flag.Duration("cloudspanner_readonly_staleness", time.Minute, "How far in the past to perform readonly operations. Within limits, raising this should help to increase performance/reduce latency.")
 	_                                    = flag.Uint64("cloudspanner_max_burst_sessions", 0, "No longer used")
+	csBatchReadThreshold                 = flag.Int64("cloudspanner_batch_read_threshold", 0, "Number of leaves at or above which range reads use a partitioned batch read, set to zero to disable.")
+	csBatchReadParallelism               = flag.Int("cloudspanner_batch_read_parallelism", 8, "Maximum number of partitions of a batch read to read concurrently, set to zero for no limit.")
+	csDirectedReadLocations              = flag.String("cloudspanner_directed_read_locations", "", "Comma-separated list of Spanner regions to direct read-only transactions to, in order of preference.")
+	csDirectedReadReplicaType            = flag.String("cloudspanner_directed_read_replica_type", "", "Type of replica to direct read-only transactions to: READ_ONLY or READ_WRITE. If set without --cloudspanner_directed_read_locations, the nearest replica of this type is used.")
+	csDirectedReadNoFailover             = flag.Bool("cloudspanner_directed_read_disable_auto_failover", false, "If true, directed reads fail rather than using other replicas when the selected replicas are unavailable.")
+	csExpireDequeuedEntries              = flag.Bool("cloudspanner_expire_dequeued_entries", false, "If true, sequenced entries of the Unsequenced table are left to its row deletion policy rather than deleted.")
+	csExpireSupersededTreeHeads          = flag.Bool("cloudspanner_expire_superseded_tree_heads", false, "If true, tree heads are left to the row deletion policy of the TreeHeads table once they're superseded, rather than kept forever. CloudSpanner storage never serves earlier roots, e.g. to GetConsistencyProofByRootHash or ListSignedLogRoots.")
 
 	csMu              sync.RWMutex
 	csStorageInstance *cloudSpannerProvider
@@ -87,6 +97,42 @@ func configFromFlags() spanner.ClientConfig {
 	return r
 }
 
+// directedReadOptions returns the options which direct read-only
+// transactions, such as those serving proofs, to replicas in the given
+// comma-separated locations and/or of the given type. Spanner picks the
+// nearest of the matching replicas. Read-write transactions are unaffected.
+// Returns nil if neither locations nor replicaType is set.
+func directedReadOptions(locations, replicaType string, autoFailoverDisabled bool) (*sppb.DirectedReadOptions, error) {
+	if locations == "" && replicaType == "" {
+		return nil, nil
+	}
+	t := sppb.DirectedReadOptions_ReplicaSelection_TYPE_UNSPECIFIED
+	if replicaType != "" {
+		v, ok := sppb.DirectedReadOptions_ReplicaSelection_Type_value[strings.ToUpper(replicaType)]
+		if !ok {
+			return nil, fmt.Errorf("unknown directed read replica type %q", replicaType)
+		}
+		t = sppb.DirectedReadOptions_ReplicaSelection_Type(v)
+	}
+	var selections []*sppb.DirectedReadOptions_ReplicaSelection
+	if locations == "" {
+		selections = append(selections, &sppb.DirectedReadOptions_ReplicaSelection{Type: t})
+	}
+	for _, l := range strings.Split(locations, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			selections = append(selections, &sppb.DirectedReadOptions_ReplicaSelection{Location: l, Type: t})
+		}
+	}
+	return &sppb.DirectedReadOptions{
+		Replicas: &sppb.DirectedReadOptions_IncludeReplicas_{
+			IncludeReplicas: &sppb.DirectedReadOptions_IncludeReplicas{
+				ReplicaSelections:    selections,
+				AutoFailoverDisabled: autoFailoverDisabled,
+			},
+		},
+	}, nil
+}
+
 func optionsFromFlags() []option.ClientOption {
 	opts := []option.ClientOption{}
 	if numConns := *csNumChannels; numConns != 0 {
@@ -103,7 +149,13 @@ func newCloudSpannerStorageProvider(_ monitoring.MetricFactory) (storage.Provide
 		return csStorageInstance, nil
 	}
 
-	client, err := spanner.NewClientWithConfig(context.TODO(), *csURI, configFromFlags(), optionsFromFlags()...)
+	cfg := configFromFlags()
+	dro, err := directedReadOptions(*csDirectedReadLocations, *csDirectedReadReplicaType, *csDirectedReadNoFailover)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DirectedReadOptions = dro
+	client, err := spanner.NewClientWithConfig(context.TODO(), *csURI, cfg, optionsFromFlags()...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +180,10 @@ func (s *cloudSpannerProvider) LogStorage() storage.LogStorage {
 	if *csReadOnlyStaleness > 0 {
 		opts.ReadOnlyStaleness = *csReadOnlyStaleness
 	}
+	opts.BatchReadThreshold = *csBatchReadThreshold
+	opts.BatchReadParallelism = *csBatchReadParallelism
+	opts.ExpireDequeuedEntries = *csExpireDequeuedEntries
+	opts.ExpireSupersededTreeHeads = *csExpireSupersededTreeHeads
 	return NewLogStorageWithOpts(s.client, opts)
 }
 
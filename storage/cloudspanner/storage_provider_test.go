@@ -0,0 +1,72 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDirectedReadOptions(t *testing.T) {
+	include := func(noFailover bool, selections ...*sppb.DirectedReadOptions_ReplicaSelection) *sppb.DirectedReadOptions {
+		return &sppb.DirectedReadOptions{
+			Replicas: &sppb.DirectedReadOptions_IncludeReplicas_{
+				IncludeReplicas: &sppb.DirectedReadOptions_IncludeReplicas{
+					ReplicaSelections:    selections,
+					AutoFailoverDisabled: noFailover,
+				},
+			},
+		}
+	}
+	readOnly := sppb.DirectedReadOptions_ReplicaSelection_READ_ONLY
+
+	for _, test := range []struct {
+		desc        string
+		locations   string
+		replicaType string
+		noFailover  bool
+		want        *sppb.DirectedReadOptions
+		wantErr     bool
+	}{
+		{desc: "unset"},
+		{
+			desc:        "nearest-read-only",
+			replicaType: "read_only",
+			want:        include(false, &sppb.DirectedReadOptions_ReplicaSelection{Type: readOnly}),
+		},
+		{
+			desc:        "locations",
+			locations:   "us-east1, europe-west1",
+			replicaType: "READ_ONLY",
+			noFailover:  true,
+			want: include(true,
+				&sppb.DirectedReadOptions_ReplicaSelection{Location: "us-east1", Type: readOnly},
+				&sppb.DirectedReadOptions_ReplicaSelection{Location: "europe-west1", Type: readOnly}),
+		},
+		{desc: "bad-type", replicaType: "WITNESS", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := directedReadOptions(test.locations, test.replicaType, test.noFailover)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("directedReadOptions() = %v, want error: %v", err, test.wantErr)
+			}
+			if !proto.Equal(got, test.want) {
+				t.Errorf("directedReadOptions() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -147,13 +147,14 @@ func (t *treeStorage) begin(ctx context.Context, tree *trillian.Tree, newCache n
 		return nil, err
 	}
 	treeTX := &treeTX{
-		treeID:    tree.TreeId,
-		treeType:  tree.TreeType,
-		ts:        t,
-		stx:       stx,
-		cache:     subtreeCache,
-		config:    config,
-		_writeRev: -1,
+		treeID:       tree.TreeId,
+		treeType:     tree.TreeType,
+		dequeueOrder: tree.DequeueOrder,
+		ts:           t,
+		stx:          stx,
+		cache:        subtreeCache,
+		config:       config,
+		_writeRev:    -1,
 	}
 
 	return treeTX, nil
@@ -175,8 +176,9 @@ func (t *treeTX) getLatestRoot(ctx context.Context) error {
 // treeTX is a concrete implementation of the part of storage.LogTreeTX
 // interface formerly known as storage.TreeTX.
 type treeTX struct {
-	treeID   int64
-	treeType trillian.TreeType
+	treeID       int64
+	treeType     trillian.TreeType
+	dequeueOrder trillian.DequeueOrder
 
 	ts *treeStorage
 
@@ -0,0 +1,58 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudsqlauth provides the tokens with which clients authenticate to
+// Google Cloud SQL databases using IAM database authentication, in place of a
+// password.
+package cloudsqlauth
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// loginScope is the OAuth2 scope which Cloud SQL needs of the access tokens
+// used to log in to databases.
+const loginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+// TokenSource provides access tokens for logging in to Cloud SQL databases.
+// It fetches a new token when the last one expires, so it can be asked for
+// one each time a connection is opened.
+type TokenSource struct {
+	ts oauth2.TokenSource
+}
+
+// NewTokenSource returns a TokenSource for the Application Default
+// Credentials, such as the service account of the GCE instance or GKE
+// workload. The database user must be the IAM user or service account of
+// these credentials.
+func NewTokenSource(ctx context.Context) (*TokenSource, error) {
+	ts, err := google.DefaultTokenSource(ctx, loginScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Google credentials: %v", err)
+	}
+	return &TokenSource{ts: ts}, nil
+}
+
+// Token returns an access token which hasn't expired.
+func (s *TokenSource) Token() (string, error) {
+	tok, err := s.ts.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get Cloud SQL access token: %v", err)
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudsqlauth
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+type errTokenSource struct{}
+
+func (errTokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("no credentials")
+}
+
+func TestToken(t *testing.T) {
+	s := &TokenSource{ts: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ya29.token"})}
+	if got, err := s.Token(); err != nil || got != "ya29.token" {
+		t.Errorf("Token() = %q, %v, want %q", got, err, "ya29.token")
+	}
+
+	s = &TokenSource{ts: errTokenSource{}}
+	if _, err := s.Token(); err == nil {
+		t.Error("Token() succeeded without credentials, want error")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changefeed allows downstream consumers, such as indexing pipelines,
+// to follow Trillian logs stored in CockroachDB using changefeeds rather than
+// by polling the public API.
+//
+// A changefeed over the SequencedLeafData and TreeHead tables emits an event
+// each time a leaf is integrated into a log, and each time a new tree head is
+// stored. Create sets up an enterprise changefeed which emits these events to
+// an external sink such as Kafka or cloud storage, and ParseMessage decodes
+// the messages it produces. Setting the --crdb_changefeed_sink flag makes the
+// crdb storage provider do this at startup if necessary. Follow instead runs a
+// core changefeed over a SQL connection, which requires no external
+// infrastructure.
+//
+// Changefeeds require rangefeeds to be enabled on the cluster:
+//
+//	SET CLUSTER SETTING kv.rangefeed.enabled = true;
+//
+// Events for a single table row are emitted in order, but there is no
+// ordering between rows. In particular, a tree head may be emitted before the
+// leaves it covers; consumers needing a consistent view should wait for a
+// resolved timestamp beyond the tree head's update time.
+package changefeed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SequencedLeafTable is the table holding sequenced leaves.
+	SequencedLeafTable = "sequencedleafdata"
+	// TreeHeadTable is the table holding tree heads.
+	TreeHeadTable = "treehead"
+)
+
+// cursorRE matches a CockroachDB HLC timestamp as used in changefeed cursors.
+var cursorRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// SequencedLeaf is emitted when a leaf is integrated into a log.
+type SequencedLeaf struct {
+	TreeID           int64
+	LeafIndex        int64
+	LeafIdentityHash []byte
+	MerkleLeafHash   []byte
+	IntegrateTime    time.Time
+}
+
+// TreeHead is emitted when a new log root is stored.
+type TreeHead struct {
+	TreeID    int64
+	TreeSize  int64
+	RootHash  []byte
+	Timestamp time.Time
+	Revision  int64
+}
+
+// Event is a single changefeed event. Exactly one of SequencedLeaf, TreeHead
+// and Resolved is set.
+type Event struct {
+	SequencedLeaf *SequencedLeaf
+	TreeHead      *TreeHead
+	// Resolved is a timestamp which the changefeed guarantees no further
+	// events will precede. Its Cursor may be used to resume the feed.
+	Resolved time.Time
+	// Updated is the commit time of the change, if known.
+	Updated time.Time
+	// Cursor is the raw HLC timestamp of the change or resolved timestamp,
+	// which may be passed to Follow to resume from this point.
+	Cursor string
+}
+
+// CreateStatement returns the SQL statement which creates an enterprise
+// changefeed emitting sequenced leaves and tree heads to sinkURI.
+func CreateStatement(sinkURI string) string {
+	return fmt.Sprintf("CREATE CHANGEFEED FOR TABLE SequencedLeafData, TreeHead INTO %s WITH format = json, updated, resolved", quote(sinkURI))
+}
+
+// Create creates an enterprise changefeed emitting sequenced leaves and tree
+// heads to sinkURI, and returns its job ID. Each call creates a new
+// changefeed, so callers should only call it once per sink.
+func Create(ctx context.Context, db *sql.DB, sinkURI string) (int64, error) {
+	var jobID int64
+	if err := db.QueryRowContext(ctx, CreateStatement(sinkURI)).Scan(&jobID); err != nil {
+		return 0, fmt.Errorf("failed to create changefeed: %v", err)
+	}
+	return jobID, nil
+}
+
+// Ensure creates an enterprise changefeed emitting sequenced leaves to sinkURI
+// unless a changefeed over the SequencedLeafData table is already running or
+// paused, and returns the job ID of the changefeed. The existing changefeed
+// may use a different sink.
+func Ensure(ctx context.Context, db *sql.DB, sinkURI string) (int64, error) {
+	var jobID int64
+	err := db.QueryRowContext(ctx, `SELECT job_id FROM [SHOW CHANGEFEED JOBS]
+		WHERE status IN ('running', 'paused', 'pending')
+		AND array_to_string(full_table_names, ',') ILIKE '%sequencedleafdata%'
+		ORDER BY created LIMIT 1`).Scan(&jobID)
+	switch {
+	case err == nil:
+		return jobID, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return Create(ctx, db, sinkURI)
+	default:
+		return 0, fmt.Errorf("failed to list changefeeds: %v", err)
+	}
+}
+
+// Follow runs a core changefeed over the given database connection, calling
+// fn with each event until ctx is done or fn returns an error. If cursor is
+// not empty, only changes after it are emitted; otherwise the feed starts
+// from the current time. Follow returns the error returned by fn, if any.
+func Follow(ctx context.Context, db *sql.DB, cursor string, fn func(*Event) error) error {
+	stmt := "EXPERIMENTAL CHANGEFEED FOR TABLE SequencedLeafData, TreeHead WITH format = json, updated, resolved"
+	if cursor != "" {
+		if !cursorRE.MatchString(cursor) {
+			return fmt.Errorf("invalid changefeed cursor %q", cursor)
+		}
+		stmt += fmt.Sprintf(", cursor = '%s'", cursor)
+	}
+	rows, err := db.QueryContext(ctx, stmt)
+	if err != nil {
+		return fmt.Errorf("failed to start changefeed: %v", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var table sql.NullString
+		var key, value []byte
+		if err := rows.Scan(&table, &key, &value); err != nil {
+			return fmt.Errorf("failed to read changefeed row: %v", err)
+		}
+		ev, err := ParseMessage(table.String, value)
+		if err != nil {
+			return err
+		}
+		if ev == nil {
+			continue
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("changefeed failed: %v", err)
+	}
+	return ctx.Err()
+}
+
+// message is the JSON envelope of a wrapped changefeed message.
+type message struct {
+	After    json.RawMessage `json:"after"`
+	Updated  string          `json:"updated"`
+	Resolved string          `json:"resolved"`
+}
+
+type sequencedLeafRow struct {
+	TreeID                  int64  `json:"treeid"`
+	SequenceNumber          int64  `json:"sequencenumber"`
+	LeafIdentityHash        string `json:"leafidentityhash"`
+	MerkleLeafHash          string `json:"merkleleafhash"`
+	IntegrateTimestampNanos int64  `json:"integratetimestampnanos"`
+}
+
+type treeHeadRow struct {
+	TreeID            int64  `json:"treeid"`
+	TreeHeadTimestamp int64  `json:"treeheadtimestamp"`
+	TreeSize          int64  `json:"treesize"`
+	RootHash          string `json:"roothash"`
+	TreeRevision      int64  `json:"treerevision"`
+}
+
+// ParseMessage decodes a JSON changefeed message for the given table, as
+// produced by the changefeed created by Create. The table name may be
+// qualified with a database and schema, and resolved timestamp messages have
+// no table. ParseMessage returns nil for deletions, and for messages about
+// other tables.
+func ParseMessage(table string, value []byte) (*Event, error) {
+	var msg message
+	if err := json.Unmarshal(value, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse changefeed message: %v", err)
+	}
+	if msg.Resolved != "" {
+		t, err := parseHLC(msg.Resolved)
+		if err != nil {
+			return nil, err
+		}
+		return &Event{Resolved: t, Cursor: msg.Resolved}, nil
+	}
+	if len(msg.After) == 0 || bytes.Equal(msg.After, []byte("null")) {
+		return nil, nil
+	}
+
+	ev := &Event{Cursor: msg.Updated}
+	if msg.Updated != "" {
+		t, err := parseHLC(msg.Updated)
+		if err != nil {
+			return nil, err
+		}
+		ev.Updated = t
+	}
+
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	var err error
+	switch strings.ToLower(table) {
+	case SequencedLeafTable:
+		var row sequencedLeafRow
+		if err := json.Unmarshal(msg.After, &row); err != nil {
+			return nil, fmt.Errorf("failed to parse sequenced leaf: %v", err)
+		}
+		leaf := &SequencedLeaf{
+			TreeID:        row.TreeID,
+			LeafIndex:     row.SequenceNumber,
+			IntegrateTime: time.Unix(0, row.IntegrateTimestampNanos).UTC(),
+		}
+		if leaf.LeafIdentityHash, err = decodeBytes(row.LeafIdentityHash); err != nil {
+			return nil, err
+		}
+		if leaf.MerkleLeafHash, err = decodeBytes(row.MerkleLeafHash); err != nil {
+			return nil, err
+		}
+		ev.SequencedLeaf = leaf
+	case TreeHeadTable:
+		var row treeHeadRow
+		if err := json.Unmarshal(msg.After, &row); err != nil {
+			return nil, fmt.Errorf("failed to parse tree head: %v", err)
+		}
+		head := &TreeHead{
+			TreeID:    row.TreeID,
+			TreeSize:  row.TreeSize,
+			Timestamp: time.Unix(0, row.TreeHeadTimestamp).UTC(),
+			Revision:  row.TreeRevision,
+		}
+		if head.RootHash, err = decodeBytes(row.RootHash); err != nil {
+			return nil, err
+		}
+		ev.TreeHead = head
+	default:
+		return nil, nil
+	}
+	return ev, nil
+}
+
+// decodeBytes decodes a BYTES column from a JSON changefeed message. These
+// are hex encoded with a "\x" prefix, though base64 is accepted too.
+func decodeBytes(s string) ([]byte, error) {
+	if h, ok := strings.CutPrefix(s, `\x`); ok {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex bytes %q: %v", s, err)
+		}
+		return b, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 bytes %q: %v", s, err)
+	}
+	return b, nil
+}
+
+// parseHLC returns the wall time of a CockroachDB HLC timestamp, which has
+// the form "<wall nanos>.<logical>".
+func parseHLC(s string) (time.Time, error) {
+	if !cursorRE.MatchString(s) {
+		return time.Time{}, errors.New("invalid HLC timestamp " + strconv.Quote(s))
+	}
+	wall, _, _ := strings.Cut(s, ".")
+	nanos, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid HLC timestamp %q: %v", s, err)
+	}
+	return time.Unix(0, nanos).UTC(), nil
+}
+
+// quote returns s as an SQL string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
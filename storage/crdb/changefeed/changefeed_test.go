@@ -0,0 +1,114 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changefeed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMessage(t *testing.T) {
+	updated := time.Unix(0, 1700000000123456789).UTC()
+	for _, test := range []struct {
+		desc    string
+		table   string
+		value   string
+		want    *Event
+		wantErr bool
+	}{
+		{
+			desc:  "sequenced-leaf",
+			table: "trillian.public.sequencedleafdata",
+			value: `{"after": {"treeid": 42, "sequencenumber": 7, "leafidentityhash": "\\x0102", "merkleleafhash": "\\xa0b0", "integratetimestampnanos": 1000}, "updated": "1700000000123456789.0000000001"}`,
+			want: &Event{
+				SequencedLeaf: &SequencedLeaf{
+					TreeID:           42,
+					LeafIndex:        7,
+					LeafIdentityHash: []byte{1, 2},
+					MerkleLeafHash:   []byte{0xa0, 0xb0},
+					IntegrateTime:    time.Unix(0, 1000).UTC(),
+				},
+				Updated: updated,
+				Cursor:  "1700000000123456789.0000000001",
+			},
+		},
+		{
+			desc:  "tree-head",
+			table: "TreeHead",
+			value: `{"after": {"treeid": 42, "treeheadtimestamp": 2000, "treesize": 8, "roothash": "AQI=", "rootsignature": "", "treerevision": 3}}`,
+			want: &Event{
+				TreeHead: &TreeHead{
+					TreeID:    42,
+					TreeSize:  8,
+					RootHash:  []byte{1, 2},
+					Timestamp: time.Unix(0, 2000).UTC(),
+					Revision:  3,
+				},
+			},
+		},
+		{
+			desc:  "resolved",
+			value: `{"resolved": "1700000000123456789.0000000000"}`,
+			want:  &Event{Resolved: updated, Cursor: "1700000000123456789.0000000000"},
+		},
+		{
+			desc:  "deletion",
+			table: "treehead",
+			value: `{"after": null}`,
+		},
+		{
+			desc:  "other-table",
+			table: "leafdata",
+			value: `{"after": {"treeid": 42}}`,
+		},
+		{
+			desc:    "bad-json",
+			table:   "treehead",
+			value:   `{`,
+			wantErr: true,
+		},
+		{
+			desc:    "bad-bytes",
+			table:   "treehead",
+			value:   `{"after": {"roothash": "\\xzz"}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "bad-resolved",
+			value:   `{"resolved": "yesterday"}`,
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := ParseMessage(test.table, []byte(test.value))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("ParseMessage()=%v, want err: %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("ParseMessage() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateStatement(t *testing.T) {
+	got := CreateStatement("kafka://broker:9092?topic_prefix=trillian_'x")
+	want := "CREATE CHANGEFEED FOR TABLE SequencedLeafData, TreeHead INTO 'kafka://broker:9092?topic_prefix=trillian_''x' WITH format = json, updated, resolved"
+	if got != want {
+		t.Errorf("CreateStatement()=%q, want %q", got, want)
+	}
+}
@@ -26,13 +26,13 @@ import (
 	"time"
 
 	"github.com/transparency-dev/merkle/compact"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
@@ -83,6 +83,8 @@ const (
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
 	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
 
+	countUnsequencedSQL = "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=$1"
+
 	logIDLabel = "logid"
 )
 
@@ -149,6 +151,15 @@ func (m *crdbLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return m.db.PingContext(ctx)
 }
 
+// CountUnsequenced implements storage.LogStorage.
+func (m *crdbLogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	var count int64
+	if err := m.db.QueryRowContext(ctx, countUnsequencedSQL, tree.TreeId).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (m *crdbLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence bool) (*sql.Stmt, error) {
 	if orderBySequence {
 		return m.getStmt(ctx, selectLeavesByMerkleHashOrderedBySequenceSQL, num, "?", "?")
@@ -192,8 +203,12 @@ func (m *crdbLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree)
 		createMetrics(m.metricFactory)
 	})
 
-	stCache := cache.NewLogSubtreeCache(rfc6962.DefaultHasher)
-	ttx, err := m.beginTreeTx(ctx, tree, rfc6962.DefaultHasher.Size(), stCache)
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	stCache := cache.NewLogSubtreeCache(hasher)
+	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -660,7 +675,27 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		}
 	}
 	// TODO(pavelkalinnikov): Further clip `count` to a safe upper bound like 64k.
+	return t.scanLeavesByRange(ctx, start, count)
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader.
+func (t *logTreeTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if treeSize := int64(t.root.TreeSize); start < treeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= TreeSize(%d)", start, treeSize)
+	}
+	return t.scanLeavesByRange(ctx, start, count)
+}
 
+// scanLeavesByRange reads the leaves with consecutive indices in
+// [start, start+count), stopping at the first missing index beyond the tree
+// size.
+func (t *logTreeTX) scanLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
 	args := []interface{}{start, start + count, t.treeID}
 	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, args...)
 	if err != nil {
@@ -128,6 +128,17 @@ func TestLogSuite(t *testing.T) {
 	storagetest.RunLogStorageTests(t, storageFactory)
 }
 
+func TestCrashConsistency(t *testing.T) {
+	t.Parallel()
+
+	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		handle := openTestDBOrDie(t)
+		return NewLogStorage(handle.db, nil), NewSQLAdminStorage(handle.db)
+	}
+
+	storagetest.RunCrashConsistencyTests(t, storageFactory)
+}
+
 func TestQueueDuplicateLeaf(t *testing.T) {
 	t.Parallel()
 
@@ -15,12 +15,16 @@
 package crdb
 
 import (
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
 	"sync"
 
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/crdb/changefeed"
+	"github.com/google/trillian/storage/dbstats"
 	"k8s.io/klog/v2"
 
 	_ "github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx" // crdb retries and postgres interface
@@ -36,6 +40,11 @@ var (
 	crdbURI  = flag.String("crdb_uri", "postgresql://root@localhost:26257?sslmode=disable", "Connection URI for CockroachDB database")
 	maxConns = flag.Int("crdb_max_conns", 0, "Maximum connections to the database")
 	maxIdle  = flag.Int("crdb_max_idle_conns", -1, "Maximum idle database connections in the connection pool")
+	cfSink   = flag.String("crdb_changefeed_sink", "", "If set, a changefeed emitting sequenced leaves and tree heads to this sink URI is created unless one already exists")
+
+	createSchema = flag.Bool("crdb_create_schema_if_missing", false, "If true, the storage schema of this version of Trillian is created when the database has no Trees table. Meant for test and development environments")
+
+	warmupConns = flag.Int("crdb_warmup_conns", 0, "Number of database connections to open and check before the server reports itself as ready. If --crdb_max_idle_conns is unset, that many are kept idle in the connection pool")
 
 	crdbErr             error
 	crdbHandle          *sql.DB
@@ -57,8 +66,9 @@ func init() {
 }
 
 type crdbProvider struct {
-	db *sql.DB
-	mf monitoring.MetricFactory
+	db    *sql.DB
+	mf    monitoring.MetricFactory
+	stats *dbstats.Exporter
 }
 
 func newCRDBStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
@@ -69,9 +79,22 @@ func newCRDBStorageProvider(mf monitoring.MetricFactory) (storage.Provider, erro
 		if err != nil {
 			return nil, err
 		}
+		if *createSchema {
+			if _, err := CreateSchemaIfMissing(context.Background(), db); err != nil {
+				return nil, err
+			}
+		}
+		if *cfSink != "" {
+			jobID, err := changefeed.Ensure(context.Background(), db, *cfSink)
+			if err != nil {
+				return nil, err
+			}
+			klog.Infof("Sequenced leaves are being emitted by changefeed job %d", jobID)
+		}
 		crdbStorageInstance = &crdbProvider{
-			db: db,
-			mf: mf,
+			db:    db,
+			mf:    mf,
+			stats: dbstats.NewExporter(mf, "crdb"),
 		}
 	}
 
@@ -94,6 +117,8 @@ func getCRDBDatabaseLocked() (*sql.DB, error) {
 	}
 	if *maxIdle >= 0 {
 		db.SetMaxIdleConns(*maxIdle)
+	} else if *warmupConns > 0 {
+		db.SetMaxIdleConns(*warmupConns)
 	}
 	crdbHandle, crdbErr = db, nil
 	return db, nil
@@ -103,6 +128,48 @@ func (p *crdbProvider) Close() error {
 	return p.db.Close()
 }
 
+// ExportStats implements storage.StatsExporter. It exports the statistics of
+// the connection pool.
+func (p *crdbProvider) ExportStats() {
+	p.stats.Update(p.db)
+}
+
+// Warmup implements storage.Warmer.
+func (p *crdbProvider) Warmup(ctx context.Context) error {
+	n := *warmupConns
+	if *maxConns > 0 {
+		n = min(n, *maxConns)
+	}
+	return warmup(ctx, p.db, n)
+}
+
+// warmup opens n connections to db, checks each of them with a ping, and
+// then returns them all to the connection pool. The connections are held
+// until all of them are open, so that the pool doesn't hand out the same
+// connection twice.
+func warmup(ctx context.Context, db *sql.DB, n int) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, c := range conns {
+			if err := c.Close(); err != nil {
+				klog.Warningf("Close(): %v", err)
+			}
+		}
+	}()
+	for len(conns) < n {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open connection %d of %d: %v", len(conns)+1, n, err)
+		}
+		conns = append(conns, c)
+		if err := c.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to ping connection %d of %d: %v", len(conns), n, err)
+		}
+	}
+	klog.Infof("Opened %d database connections", n)
+	return nil
+}
+
 func (p *crdbProvider) LogStorage() storage.LogStorage {
 	return NewLogStorage(p.db, p.mf)
 }
@@ -0,0 +1,77 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdb
+
+import (
+	"context"
+	"database/sql"
+	_ "embed" // For the schema.
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// schemaSQL is the schema of the storage for this version of Trillian.
+//
+//go:embed schema/storage.sql
+var schemaSQL string
+
+// CreateSchemaIfMissing applies the schema in schema/storage.sql, which is
+// the one of this version of Trillian, to db unless it already has a Trees
+// table. It returns whether the schema was applied.
+//
+// It is meant for test and development environments starting from an empty
+// database. A database with an older schema is left alone, as it needs the
+// changes described in the CHANGELOG instead.
+func CreateSchemaIfMissing(ctx context.Context, db *sql.DB) (bool, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_catalog = current_database() AND table_schema = current_schema() AND table_name = 'trees')").Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look for the Trees table: %v", err)
+	}
+	if exists {
+		return false, nil
+	}
+	// The statements are run one at a time, as CockroachDB restricts schema
+	// changes within a transaction. A failure part way leaves a partial
+	// schema, which must be dropped with drop_storage.sql before trying again.
+	for _, stmt := range schemaStatements(schemaSQL) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return false, fmt.Errorf("error running statement %q: %v", stmt, err)
+		}
+	}
+	klog.Info("Created the CockroachDB storage schema")
+	return true, nil
+}
+
+// schemaStatements splits script into its statements, dropping comment lines.
+func schemaStatements(script string) []string {
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	var stmts []string
+	for _, stmt := range strings.Split(b.String(), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
@@ -28,6 +28,7 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             BYTES NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  Options               BYTES, -- Serialized per-tree options, see storage.MarshalTreeOptions.
   PRIMARY KEY(TreeId)
 );
 
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -54,7 +55,7 @@ func readTree(r row) (*trillian.Tree, error) {
 	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
 	var createMillis, updateMillis, maxRootDurationMillis int64
 	var displayName, description sql.NullString
-	var privateKey, publicKey []byte
+	var privateKey, publicKey, options []byte
 	var deleted sql.NullBool
 	var deleteMillis sql.NullInt64
 	err := r.Scan(
@@ -73,6 +74,7 @@ func readTree(r row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&options,
 	)
 	if err != nil {
 		return nil, err
@@ -124,5 +126,9 @@ func readTree(r row) (*trillian.Tree, error) {
 		}
 	}
 
+	if err := storage.UnmarshalTreeOptions(options, tree); err != nil {
+		return nil, err
+	}
+
 	return tree, nil
 }
@@ -51,14 +51,15 @@ const (
 			PublicKey,
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			Options
 		FROM Trees`
 	selectNonDeletedTrees = selectTrees + nonDeletedWhere
 	selectTreeByID        = selectTrees + " WHERE TreeId = $1"
 
 	updateTreeSQL = `UPDATE Trees
-		SET TreeState = $1, TreeType = $2, DisplayName = $3, Description = $4, UpdateTimeMillis = $5, MaxRootDurationMillis = $6, PrivateKey = $7
-		WHERE TreeId = $8`
+		SET TreeState = $1, TreeType = $2, DisplayName = $3, Description = $4, UpdateTimeMillis = $5, MaxRootDurationMillis = $6, PrivateKey = $7, Options = $8
+		WHERE TreeId = $9`
 )
 
 // NewSQLAdminStorage returns a SQL storage.AdminStorage implementation backed by DB.
@@ -223,6 +224,10 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, fmt.Errorf("could not parse MaxRootDuration: %w", err)
 	}
 	rootDuration := newTree.MaxRootDuration.AsDuration()
+	options, err := storage.MarshalTreeOptions(newTree)
+	if err != nil {
+		return nil, err
+	}
 
 	insertTreeStmt, err := t.tx.PrepareContext(
 		ctx,
@@ -239,8 +244,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 			UpdateTimeMillis,
 			PrivateKey,
 			PublicKey,
-			MaxRootDurationMillis)
-		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`)
+			MaxRootDurationMillis,
+			Options)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`)
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +271,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		[]byte{}, // Unused, filling in for backward compatibility.
 		[]byte{}, // Unused, filling in for backward compatibility.
 		rootDuration/time.Millisecond,
+		options,
 	)
 	if err != nil {
 		return nil, err
@@ -338,6 +345,10 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		return nil, fmt.Errorf("could not parse MaxRootDuration: %w", err)
 	}
 	rootDuration := tree.MaxRootDuration.AsDuration()
+	options, err := storage.MarshalTreeOptions(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
 	if err != nil {
@@ -358,6 +369,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		[]byte{}, // Unused, filling in for backward compatibility.
+		options,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
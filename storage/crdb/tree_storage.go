@@ -351,7 +351,10 @@ func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtr
 func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	rev := t.writeRevision - 1
+	// Read the tiles as of the write revision, rather than the latest root, so
+	// that the nodes staged by earlier transactions of a split batch are kept.
+	// Otherwise nothing has been written at the write revision yet.
+	rev := t.writeRevision
 	return t.subtreeCache.SetNodes(nodes, t.getSubtreesAtRev(ctx, rev))
 }
 
@@ -0,0 +1,170 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbsecret fetches the passwords of storage databases from secret
+// stores, so that they needn't be given in connection URIs on the command
+// line, where process listings show them, and so that they can be rotated
+// without restarting Trillian.
+//
+// A secret is named by a reference SCHEME:NAME, optionally followed by
+// #FIELD, which picks a string field out of a secret holding a JSON object,
+// such as the secrets which Amazon RDS manages. The built-in schemes are:
+//
+//	file:PATH       The contents of a file, less a trailing newline.
+//	vault:PATH      A HashiCorp Vault KV secret, such as
+//	                vault:secret/data/trillian#password, read from
+//	                $VAULT_ADDR with the token in $VAULT_TOKEN.
+//	awssm:ID        An AWS Secrets Manager secret, with the credentials of
+//	                the default AWS credential chain.
+//	gcpsm:VERSION   A Google Secret Manager secret version, such as
+//	                gcpsm:projects/p/secrets/s/versions/latest, with the
+//	                Application Default Credentials.
+//
+// Further schemes can be added with RegisterSource.
+package dbsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/util/clock"
+	"k8s.io/klog/v2"
+)
+
+// Source fetches the secrets of one scheme.
+type Source interface {
+	// Fetch returns the current value of the named secret.
+	Fetch(ctx context.Context, name string) (string, error)
+}
+
+// NewSourceFunc creates a Source.
+type NewSourceFunc func(ctx context.Context) (Source, error)
+
+var (
+	srcMu       sync.RWMutex
+	srcByScheme = map[string]NewSourceFunc{
+		"file":  newFileSource,
+		"vault": newVaultSource,
+		"awssm": newAWSSource,
+		"gcpsm": newGCPSource,
+	}
+)
+
+// RegisterSource registers the Source of the secrets with references which
+// start with scheme and a colon.
+func RegisterSource(scheme string, f NewSourceFunc) error {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+
+	if _, exists := srcByScheme[scheme]; exists {
+		return fmt.Errorf("secret source %v already registered", scheme)
+	}
+	srcByScheme[scheme] = f
+	return nil
+}
+
+// Secret is a secret whose value is fetched again when it's older than the
+// refresh interval, so that rotations of it are picked up.
+type Secret struct {
+	src     Source
+	name    string
+	field   string
+	refresh time.Duration
+	ts      clock.TimeSource
+
+	mu      sync.Mutex
+	value   string
+	fetched time.Time
+}
+
+// Open returns the secret with the reference ref, whose value is reused for
+// refresh after each fetch. The secret is fetched once, so that mistakes are
+// found on startup.
+func Open(ctx context.Context, ref string, refresh time.Duration) (*Secret, error) {
+	scheme, name, ok := strings.Cut(ref, ":")
+	if !ok {
+		return nil, fmt.Errorf("secret reference %q has no scheme", ref)
+	}
+	name, field, _ := strings.Cut(name, "#")
+	srcMu.RLock()
+	newSource, ok := srcByScheme[scheme]
+	srcMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown secret source %q", scheme)
+	}
+	src, err := newSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s secret source: %v", scheme, err)
+	}
+	s := newSecret(src, name, field, refresh, clock.System)
+	if _, err := s.Value(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newSecret(src Source, name, field string, refresh time.Duration, ts clock.TimeSource) *Secret {
+	return &Secret{
+		src:     src,
+		name:    name,
+		field:   field,
+		refresh: refresh,
+		ts:      ts,
+	}
+}
+
+// Value returns the value of the secret. If the secret can't be fetched
+// again, the last value is returned, so that connections can still be opened
+// while the secret store is unavailable.
+func (s *Secret) Value(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.ts.Now()
+	if !s.fetched.IsZero() && now.Sub(s.fetched) < s.refresh {
+		return s.value, nil
+	}
+	value, err := s.fetch(ctx)
+	if err != nil {
+		if s.fetched.IsZero() {
+			return "", err
+		}
+		klog.Warningf("Using the value of secret %s fetched at %v: %v", s.name, s.fetched, err)
+		return s.value, nil
+	}
+	s.value, s.fetched = value, now
+	return value, nil
+}
+
+func (s *Secret) fetch(ctx context.Context) (string, error) {
+	value, err := s.src.Fetch(ctx, s.name)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret %s: %v", s.name, err)
+	}
+	if s.field == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s isn't a JSON object: %v", s.name, err)
+	}
+	f, ok := fields[s.field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s has no string field %q", s.name, s.field)
+	}
+	return f, nil
+}
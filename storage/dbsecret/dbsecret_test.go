@@ -0,0 +1,171 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbsecret
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/util/clock"
+)
+
+type fakeSource struct {
+	value string
+	err   error
+	calls int
+}
+
+func (s *fakeSource) Fetch(context.Context, string) (string, error) {
+	s.calls++
+	return s.value, s.err
+}
+
+func TestSecretValue(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeSource{value: "one"}
+	ts := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := newSecret(src, "db", "", time.Minute, ts)
+
+	for _, step := range []struct {
+		desc      string
+		advance   time.Duration
+		value     string
+		err       error
+		want      string
+		wantCalls int
+	}{
+		{desc: "first", value: "one", want: "one", wantCalls: 1},
+		{desc: "cached", advance: 59 * time.Second, value: "two", want: "one", wantCalls: 1},
+		{desc: "refreshed", advance: time.Second, value: "two", want: "two", wantCalls: 2},
+		{desc: "unavailable", advance: time.Minute, err: errors.New("unavailable"), want: "two", wantCalls: 3},
+		{desc: "retried", value: "three", want: "three", wantCalls: 4},
+	} {
+		ts.Set(ts.Now().Add(step.advance))
+		src.value, src.err = step.value, step.err
+		got, err := s.Value(ctx)
+		if err != nil {
+			t.Fatalf("%s: Value(): %v", step.desc, err)
+		}
+		if got != step.want || src.calls != step.wantCalls {
+			t.Errorf("%s: Value() = %q after %d fetches, want %q after %d", step.desc, got, src.calls, step.want, step.wantCalls)
+		}
+	}
+}
+
+func TestSecretField(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		value   string
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{value: "hunter2", want: "hunter2"},
+		{value: `{"username":"trillian","password":"hunter2"}`, field: "password", want: "hunter2"},
+		{value: `{"username":"trillian"}`, field: "password", wantErr: true},
+		{value: `{"password":42}`, field: "password", wantErr: true},
+		{value: "hunter2", field: "password", wantErr: true},
+	} {
+		s := newSecret(&fakeSource{value: test.value}, "db", test.field, time.Minute, clock.System)
+		got, err := s.Value(ctx)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Value() of %q#%s = %q, %v, want error %v", test.value, test.field, got, err, test.wantErr)
+		} else if got != test.want {
+			t.Errorf("Value() of %q#%s = %q, want %q", test.value, test.field, got, test.want)
+		}
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(ctx, "file:"+path, 0)
+	if err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	if got, err := s.Value(ctx); err != nil || got != "hunter2" {
+		t.Errorf("Value() = %q, %v, want %q", got, err, "hunter2")
+	}
+	if err := os.WriteFile(path, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Value(ctx); err != nil || got != "correct horse" {
+		t.Errorf("Value() after rotation = %q, %v, want %q", got, err, "correct horse")
+	}
+
+	for _, ref := range []string{"", "nosuchscheme:x", "file:" + path + ".missing"} {
+		if _, err := Open(ctx, ref, 0); err == nil {
+			t.Errorf("Open(%q) succeeded, want error", ref)
+		}
+	}
+}
+
+func TestOpenVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/trillian":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"kv2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/trillian":
+			_, _ = w.Write([]byte(`{"data":{"password":"kv1"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	ctx := context.Background()
+	for ref, want := range map[string]string{
+		"vault:secret/data/trillian#password": "kv2",
+		"vault:kv/trillian#password":          "kv1",
+	} {
+		s, err := Open(ctx, ref, time.Minute)
+		if err != nil {
+			t.Errorf("Open(%q): %v", ref, err)
+			continue
+		}
+		if got, err := s.Value(ctx); err != nil || got != want {
+			t.Errorf("Value() of %q = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+	if _, err := Open(ctx, "vault:secret/data/missing#password", time.Minute); err == nil {
+		t.Error("Open() of a missing secret succeeded, want error")
+	}
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := Open(ctx, "vault:kv/trillian#password", time.Minute); err == nil {
+		t.Error("Open() with a wrong token succeeded, want error")
+	}
+}
+
+func TestRegisterSource(t *testing.T) {
+	if err := RegisterSource("file", newFileSource); err == nil {
+		t.Error("RegisterSource() of a built-in scheme succeeded, want error")
+	}
+}
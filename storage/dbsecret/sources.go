@@ -0,0 +1,153 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbsecret
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// fileSource reads secrets from files, such as those which Kubernetes mounts.
+type fileSource struct{}
+
+func newFileSource(context.Context) (Source, error) {
+	return fileSource{}, nil
+}
+
+func (fileSource) Fetch(_ context.Context, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), nil
+}
+
+// vaultSource reads secrets from the HTTP API of a Vault server. A secret of
+// the KV version 2 engine is returned as the JSON object of its data.
+type vaultSource struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func newVaultSource(context.Context) (Source, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, errors.New("VAULT_TOKEN is not set")
+	}
+	return &vaultSource{addr: strings.TrimSuffix(addr, "/"), token: token, client: http.DefaultClient}, nil
+}
+
+func (s *vaultSource) Fetch(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s: %s", resp.Status, body)
+	}
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %v", err)
+	}
+	// KV version 2 nests the secret in data.data, beside data.metadata.
+	var kv2 struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(secret.Data, &kv2); err == nil && kv2.Data != nil && kv2.Metadata != nil {
+		return string(kv2.Data), nil
+	}
+	return string(secret.Data), nil
+}
+
+// awsSource reads secrets from AWS Secrets Manager.
+type awsSource struct {
+	client *secretsmanager.SecretsManager
+}
+
+func newAWSSource(context.Context) (Source, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+	return &awsSource{client: secretsmanager.New(sess)}, nil
+}
+
+func (s *awsSource) Fetch(ctx context.Context, id string) (string, error) {
+	out, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", errors.New("binary secrets are not supported")
+	}
+	return *out.SecretString, nil
+}
+
+// gcpSource reads secret versions from Google Secret Manager.
+type gcpSource struct {
+	svc *secretmanager.Service
+}
+
+func newGCPSource(ctx context.Context) (Source, error) {
+	svc, err := secretmanager.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gcpSource{svc: svc}, nil
+}
+
+func (s *gcpSource) Fetch(ctx context.Context, version string) (string, error) {
+	resp, err := s.svc.Projects.Secrets.Versions.Access(version).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	b, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret payload: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbstats exports the statistics of database/sql connection pools
+// through a monitoring.MetricFactory, for the storage providers built on
+// database/sql.
+package dbstats
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+)
+
+// Exporter sets metrics to the statistics of a connection pool.
+type Exporter struct {
+	maxOpen, open, inUse, idle    monitoring.Gauge
+	waits, waitSeconds            monitoring.Counter
+	closedMaxIdle, closedLifetime monitoring.Counter
+	closedMaxIdleTime             monitoring.Counter
+
+	// mu guards last, the statistics of the previous update.
+	mu   sync.Mutex
+	last sql.DBStats
+}
+
+// NewExporter creates the metrics in mf, with names starting with prefix,
+// such as "mysql", and returns an Exporter which sets them.
+func NewExporter(mf monitoring.MetricFactory, prefix string) *Exporter {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Exporter{
+		maxOpen:           mf.NewGauge(prefix+"_pool_max_open_connections", "Maximum number of open connections to the database, or 0 if unlimited"),
+		open:              mf.NewGauge(prefix+"_pool_open_connections", "Number of established connections to the database, both in use and idle"),
+		inUse:             mf.NewGauge(prefix+"_pool_in_use_connections", "Number of connections to the database currently in use"),
+		idle:              mf.NewGauge(prefix+"_pool_idle_connections", "Number of idle connections to the database"),
+		waits:             mf.NewCounter(prefix+"_pool_waits", "Number of times a connection to the database was waited for"),
+		waitSeconds:       mf.NewCounter(prefix+"_pool_wait_seconds", "Total time spent waiting for connections to the database"),
+		closedMaxIdle:     mf.NewCounter(prefix+"_pool_closed_max_idle", "Number of connections closed because of the maximum number of idle connections"),
+		closedLifetime:    mf.NewCounter(prefix+"_pool_closed_max_lifetime", "Number of connections closed because of their maximum lifetime"),
+		closedMaxIdleTime: mf.NewCounter(prefix+"_pool_closed_max_idle_time", "Number of connections closed because of their maximum idle time"),
+	}
+}
+
+// Update sets the metrics to the statistics of db.
+func (e *Exporter) Update(db *sql.DB) {
+	e.UpdateStats(db.Stats())
+}
+
+// UpdateStats sets the metrics to s. The counters are advanced by the
+// increase of the cumulative statistics since the previous update.
+func (e *Exporter) UpdateStats(s sql.DBStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxOpen.Set(float64(s.MaxOpenConnections))
+	e.open.Set(float64(s.OpenConnections))
+	e.inUse.Set(float64(s.InUse))
+	e.idle.Set(float64(s.Idle))
+	if d := s.WaitCount - e.last.WaitCount; d > 0 {
+		e.waits.Add(float64(d))
+	}
+	if d := s.WaitDuration - e.last.WaitDuration; d > 0 {
+		e.waitSeconds.Add(d.Seconds())
+	}
+	if d := s.MaxIdleClosed - e.last.MaxIdleClosed; d > 0 {
+		e.closedMaxIdle.Add(float64(d))
+	}
+	if d := s.MaxLifetimeClosed - e.last.MaxLifetimeClosed; d > 0 {
+		e.closedLifetime.Add(float64(d))
+	}
+	if d := s.MaxIdleTimeClosed - e.last.MaxIdleTimeClosed; d > 0 {
+		e.closedMaxIdleTime.Add(float64(d))
+	}
+	e.last = s
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbstats
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+)
+
+// value returns the value of a metric created by an InertMetricFactory.
+func value(m interface{}) float64 {
+	return m.(*monitoring.InertFloat).Value()
+}
+
+func TestUpdateStats(t *testing.T) {
+	e := NewExporter(monitoring.InertMetricFactory{}, "test")
+	for _, s := range []sql.DBStats{
+		{MaxOpenConnections: 10, OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 2, WaitDuration: time.Second, MaxIdleClosed: 1},
+		{MaxOpenConnections: 10, OpenConnections: 6, InUse: 6, WaitCount: 5, WaitDuration: 2500 * time.Millisecond, MaxIdleClosed: 1, MaxLifetimeClosed: 2},
+	} {
+		e.UpdateStats(s)
+	}
+
+	for _, test := range []struct {
+		name string
+		m    interface{}
+		want float64
+	}{
+		{name: "max_open", m: e.maxOpen, want: 10},
+		{name: "open", m: e.open, want: 6},
+		{name: "in_use", m: e.inUse, want: 6},
+		{name: "idle", m: e.idle, want: 0},
+		{name: "waits", m: e.waits, want: 5},
+		{name: "wait_seconds", m: e.waitSeconds, want: 2.5},
+		{name: "closed_max_idle", m: e.closedMaxIdle, want: 1},
+		{name: "closed_max_lifetime", m: e.closedLifetime, want: 2},
+		{name: "closed_max_idle_time", m: e.closedMaxIdleTime, want: 0},
+	} {
+		if got := value(test.m); got != test.want {
+			t.Errorf("%s = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
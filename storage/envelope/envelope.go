@@ -0,0 +1,452 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope provides a storage.LogStorage wrapper which encrypts the
+// LeafValue and ExtraData of leaves at rest, for trees with LeafEncryption
+// set. Each tree has its own data key, which is stored in the tree wrapped by
+// a key encryption key.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// formatVersion is the first byte of encrypted values, so that the format can
+// be changed later.
+const formatVersion = 1
+
+// Tags of the encrypted fields, which bind a ciphertext to its field.
+const (
+	leafValueTag = 'v'
+	extraDataTag = 'e'
+)
+
+// LogStorage wraps a storage.LogStorage, encrypting leaf data before it is
+// written and decrypting it when it is read. Leaf hashes must already be set
+// by the caller, so they are computed over the plaintext. Trees without
+// LeafEncryption are passed through unchanged.
+type LogStorage struct {
+	storage.LogStorage
+	wrapper KeyWrapper
+
+	mu    sync.Mutex
+	trees map[int64]*treeKey
+}
+
+// treeKey is the unwrapped data key of a tree.
+type treeKey struct {
+	treeID  int64
+	wrapped []byte
+	aead    cipher.AEAD
+}
+
+// NewLogStorage returns a LogStorage which unwraps data keys with w. If w is
+// nil, operations on trees with LeafEncryption fail.
+func NewLogStorage(ls storage.LogStorage, w KeyWrapper) *LogStorage {
+	return &LogStorage{LogStorage: ls, wrapper: w, trees: make(map[int64]*treeKey)}
+}
+
+// keyFor returns the data key of tree, or nil if its leaves aren't encrypted.
+func (s *LogStorage) keyFor(ctx context.Context, tree *trillian.Tree) (*treeKey, error) {
+	enc := tree.GetLeafEncryption()
+	if enc == nil {
+		return nil, nil
+	}
+	if s.wrapper == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d has encrypted leaves, but no key wrapper is configured", tree.TreeId)
+	}
+	if len(enc.WrappedKey) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d has no wrapped data key", tree.TreeId)
+	}
+
+	s.mu.Lock()
+	k, ok := s.trees[tree.TreeId]
+	s.mu.Unlock()
+	if ok && bytes.Equal(k.wrapped, enc.WrappedKey) {
+		return k, nil
+	}
+
+	dataKey, err := s.wrapper.UnwrapKey(ctx, enc.KeyUri, enc.WrappedKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to unwrap data key of tree %d: %v", tree.TreeId, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "bad data key for tree %d: %v", tree.TreeId, err)
+	}
+	k = &treeKey{treeID: tree.TreeId, wrapped: enc.WrappedKey, aead: aead}
+	s.mu.Lock()
+	s.trees[tree.TreeId] = k
+	s.mu.Unlock()
+	return k, nil
+}
+
+// additionalData binds a ciphertext to the tree, leaf and field it belongs
+// to, so that ciphertexts moved between leaves fail to decrypt.
+func (k *treeKey) additionalData(tag byte, identityHash []byte) []byte {
+	ad := make([]byte, 9, 9+len(identityHash))
+	binary.BigEndian.PutUint64(ad, uint64(k.treeID))
+	ad[8] = tag
+	return append(ad, identityHash...)
+}
+
+func (k *treeKey) encrypt(value []byte, tag byte, identityHash []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+	ciphertext, err := seal(k.aead, value, k.additionalData(tag, identityHash))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encrypt leaf data: %v", err)
+	}
+	return append([]byte{formatVersion}, ciphertext...), nil
+}
+
+func (k *treeKey) decrypt(value []byte, tag byte, identityHash []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+	if value[0] != formatVersion {
+		return nil, status.Errorf(codes.DataLoss, "unknown encrypted leaf data version %d", value[0])
+	}
+	plaintext, err := open(k.aead, value[1:], k.additionalData(tag, identityHash))
+	if err != nil {
+		return nil, status.Errorf(codes.DataLoss, "failed to decrypt leaf data: %v", err)
+	}
+	return plaintext, nil
+}
+
+// encryptLeaf returns a copy of leaf with its data encrypted.
+func (k *treeKey) encryptLeaf(leaf *trillian.LogLeaf) (*trillian.LogLeaf, error) {
+	if leaf == nil {
+		return nil, nil
+	}
+	value, err := k.encrypt(leaf.LeafValue, leafValueTag, leaf.LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	extraData, err := k.encrypt(leaf.ExtraData, extraDataTag, leaf.LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	ret := proto.Clone(leaf).(*trillian.LogLeaf)
+	ret.LeafValue, ret.ExtraData = value, extraData
+	return ret, nil
+}
+
+// decryptLeaf returns a copy of leaf with its data decrypted. The leaf itself
+// may be owned by the storage, so it isn't modified.
+func (k *treeKey) decryptLeaf(leaf *trillian.LogLeaf) (*trillian.LogLeaf, error) {
+	if leaf == nil {
+		return nil, nil
+	}
+	value, err := k.decrypt(leaf.LeafValue, leafValueTag, leaf.LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	extraData, err := k.decrypt(leaf.ExtraData, extraDataTag, leaf.LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	ret := proto.Clone(leaf).(*trillian.LogLeaf)
+	ret.LeafValue, ret.ExtraData = value, extraData
+	return ret, nil
+}
+
+func (k *treeKey) decryptLeaves(leaves []*trillian.LogLeaf, err error) ([]*trillian.LogLeaf, error) {
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*trillian.LogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		if ret[i], err = k.decryptLeaf(leaf); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// SnapshotForTree implements storage.LogStorage.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	k, err := s.keyFor(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil || k == nil {
+		return tx, err
+	}
+	return &snapshot{ReadOnlyLogTreeTX: tx, key: k}, nil
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	k, err := s.keyFor(ctx, tree)
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		return s.LogStorage.ReadWriteTransaction(ctx, tree, f)
+	}
+	return s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, &logTX{LogTreeTX: tx, snapshot: &snapshot{ReadOnlyLogTreeTX: tx, key: k}})
+	})
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	k, err := s.keyFor(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	}
+	encrypted, err := k.encryptLeaves(leaves)
+	if err != nil {
+		return nil, err
+	}
+	return k.decryptQueued(s.LogStorage.QueueLeaves(ctx, tree, encrypted, queueTimestamp))
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	k, err := s.keyFor(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	}
+	encrypted, err := k.encryptLeaves(leaves)
+	if err != nil {
+		return nil, err
+	}
+	return k.decryptQueued(s.LogStorage.AddSequencedLeaves(ctx, tree, encrypted, timestamp))
+}
+
+func (k *treeKey) encryptLeaves(leaves []*trillian.LogLeaf) ([]*trillian.LogLeaf, error) {
+	ret := make([]*trillian.LogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		var err error
+		if ret[i], err = k.encryptLeaf(leaf); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// decryptQueued decrypts the leaves of a QueueLeaves or AddSequencedLeaves
+// result, which may be those passed in or ones which were already stored.
+func (k *treeKey) decryptQueued(queued []*trillian.QueuedLogLeaf, err error) ([]*trillian.QueuedLogLeaf, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queued {
+		if q == nil {
+			continue
+		}
+		if q.Leaf, err = k.decryptLeaf(q.Leaf); err != nil {
+			return nil, err
+		}
+	}
+	return queued, nil
+}
+
+// snapshot decrypts the leaves read by a transaction.
+type snapshot struct {
+	storage.ReadOnlyLogTreeTX
+	key *treeKey
+}
+
+func (t *snapshot) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	return t.key.decryptLeaves(t.ReadOnlyLogTreeTX.GetLeavesByRange(ctx, start, count))
+}
+
+func (t *snapshot) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	return t.key.decryptLeaves(t.ReadOnlyLogTreeTX.GetLeavesByHash(ctx, leafHashes, orderBySequence))
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *snapshot) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	return t.key.decryptLeaves(storage.GetLeavesByIndices(ctx, t.ReadOnlyLogTreeTX, indices))
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *snapshot) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	return t.key.decryptLeaves(r.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence))
+}
+
+// GetSignedLogRootBySize implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	return r.GetSignedLogRootBySize(ctx, treeSize)
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	return r.GetSignedLogRootByHash(ctx, rootHash)
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) ListSignedLogRoots(ctx context.Context, rng storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	return r.ListSignedLogRoots(ctx, rng, limit)
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader if the
+// underlying transaction does.
+func (t *snapshot) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IntegrationEventReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	return r.ListIntegrationEvents(ctx, before, limit)
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter if the underlying
+// transaction does.
+func (t *snapshot) CountUnsequenced(ctx context.Context) (int64, error) {
+	c, ok := t.ReadOnlyLogTreeTX.(storage.UnsequencedCounter)
+	if !ok {
+		return 0, status.Error(codes.Unimplemented, "storage does not support counting unsequenced leaves")
+	}
+	return c.CountUnsequenced(ctx)
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader if the
+// underlying transaction does.
+func (t *snapshot) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.SequencedIndexReader)
+	if !ok {
+		return 0, 0, status.Error(codes.Unimplemented, "storage does not support finding the range of sequenced leaves")
+	}
+	return r.GetSequencedIndexRange(ctx)
+}
+
+// logTX decrypts the leaves read by a read-write transaction. Leaves passed
+// between DequeueLeaves and UpdateSequencedLeaves stay encrypted, as they
+// are only moved within the storage.
+type logTX struct {
+	storage.LogTreeTX
+	snapshot *snapshot
+}
+
+func (t *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	return t.snapshot.GetLeavesByRange(ctx, start, count)
+}
+
+func (t *logTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	return t.snapshot.GetLeavesByHash(ctx, leafHashes, orderBySequence)
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *logTX) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	return t.snapshot.GetLeavesByIndices(ctx, indices)
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *logTX) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	return t.snapshot.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence)
+}
+
+// UpdateLeafExtraData implements storage.ExtraDataUpdater if the underlying
+// transaction does.
+func (t *logTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	u, ok := t.LogTreeTX.(storage.ExtraDataUpdater)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support updating leaf extra data")
+	}
+	// The ciphertext is bound to the leaf's identity hash, so read it first.
+	stored, err := t.LogTreeTX.GetLeavesByRange(ctx, index, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) != 1 {
+		return nil, status.Errorf(codes.NotFound, "no leaf at index %d", index)
+	}
+	k := t.snapshot.key
+	encrypted, err := k.encrypt(extraData, extraDataTag, stored[0].LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := u.UpdateLeafExtraData(ctx, index, encrypted, reason, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return k.decryptLeaf(leaf)
+}
+
+// RedactLeaf implements storage.LeafRedactor if the underlying transaction
+// does.
+func (t *logTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	r, ok := t.LogTreeTX.(storage.LeafRedactor)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support redacting leaves")
+	}
+	leaf, err := r.RedactLeaf(ctx, index, reason, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return t.snapshot.key.decryptLeaf(leaf)
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader if the underlying
+// transaction does. Like DequeueLeaves, it returns the leaves encrypted.
+func (t *logTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	r, ok := t.LogTreeTX.(storage.StagedLeafReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support staged leaves")
+	}
+	return r.GetStagedLeaves(ctx, start, count)
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter if the
+// underlying transaction does.
+func (t *logTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	w, ok := t.LogTreeTX.(storage.IntegrationEventWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	return w.AddIntegrationEvent(ctx, event, cutoff)
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/envelope"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+const keyURI = "local:test"
+
+// newEncryptedLog returns a log server backed by envelope-encrypted memory
+// storage, the underlying storage, and an initialized tree with encrypted
+// leaves.
+func newEncryptedLog(ctx context.Context, t *testing.T) (*server.TrillianLogRPCServer, extension.Registry, storage.LogStorage, *trillian.Tree) {
+	t.Helper()
+	log.InitMetrics(nil)
+
+	wrapper, err := envelope.NewLocalKeyWrapper(map[string][]byte{keyURI: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatalf("NewLocalKeyWrapper(): %v", err)
+	}
+	ts := memory.NewTreeStorage()
+	raw := memory.NewLogStorage(ts, nil)
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   envelope.NewLogStorage(raw, wrapper),
+		QuotaManager: quota.Noop(),
+	}
+	s := server.NewTrillianLogRPCServer(registry, clock.System)
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	if tree.LeafEncryption, err = envelope.NewDataKey(ctx, wrapper, keyURI); err != nil {
+		t.Fatalf("NewDataKey(): %v", err)
+	}
+	if tree, err = storage.CreateTree(ctx, registry.AdminStorage, tree); err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if _, err := s.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+	return s, registry, raw, tree
+}
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	s, registry, raw, tree := newEncryptedLog(ctx, t)
+
+	leaf := &trillian.LogLeaf{LeafValue: []byte("secret value"), ExtraData: []byte("secret extra")}
+	for i := 0; i < 2; i++ {
+		// The second request is a duplicate, so it returns the stored leaf.
+		rsp, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf})
+		if err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+		if got := rsp.QueuedLeaf.Leaf; !bytes.Equal(got.LeafValue, leaf.LeafValue) || !bytes.Equal(got.ExtraData, leaf.ExtraData) {
+			t.Errorf("QueueLeaf() returned leaf %+v, want plaintext %+v", got, leaf)
+		}
+	}
+	if _, err := log.IntegrateBatch(ctx, tree, 1, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+
+	rsp, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, Count: 1})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	got := rsp.Leaves[0]
+	if !bytes.Equal(got.LeafValue, leaf.LeafValue) || !bytes.Equal(got.ExtraData, leaf.ExtraData) {
+		t.Errorf("GetLeavesByRange() returned leaf %+v, want plaintext %+v", got, leaf)
+	}
+	if _, err := s.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: tree.TreeId, LeafHash: got.MerkleLeafHash, TreeSize: 1}); err != nil {
+		t.Errorf("GetInclusionProofByHash(): %v", err)
+	}
+
+	// The underlying storage only holds ciphertext.
+	tx, err := raw.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	stored, err := tx.GetLeavesByRange(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	for _, b := range [][]byte{stored[0].LeafValue, stored[0].ExtraData} {
+		if bytes.Contains(b, []byte("secret")) {
+			t.Errorf("stored leaf data %q contains plaintext", b)
+		}
+	}
+
+	// Without a key wrapper, the tree's leaves can't be read.
+	if _, err := envelope.NewLogStorage(raw, nil).SnapshotForTree(ctx, tree); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("SnapshotForTree() without key wrapper returned err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestLogStorageSwappedCiphertext(t *testing.T) {
+	ctx := context.Background()
+	s, registry, raw, tree := newEncryptedLog(ctx, t)
+
+	for _, v := range []string{"first", "second"} {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(v), ExtraData: []byte(v + " extra")}
+		if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf}); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+	}
+	if _, err := log.IntegrateBatch(ctx, tree, 2, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager); err != nil {
+		t.Fatalf("IntegrateBatch(): %v", err)
+	}
+
+	// Swap the encrypted extra data of the two leaves in the underlying
+	// storage, as someone with write access to the database could.
+	err := raw.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.GetLeavesByRange(ctx, 0, 2)
+		if err != nil {
+			return err
+		}
+		u := tx.(storage.ExtraDataUpdater)
+		for i := range leaves {
+			if _, err := u.UpdateLeafExtraData(ctx, int64(i), leaves[1-i].ExtraData, "swap", clock.System.Now()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	for i := int64(0); i < 2; i++ {
+		_, err := s.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, StartIndex: i, Count: 1})
+		if status.Code(err) != codes.DataLoss {
+			t.Errorf("GetLeavesByRange(%d) of swapped leaf returned err = %v, want DataLoss", i, err)
+		}
+	}
+}
+
+func TestLogStorageUnencrypted(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	raw := storage.NewMockLogStorage(ctrl)
+	ls := envelope.NewLogStorage(raw, nil)
+
+	tree := stestonly.LogTree
+	leaves := []*trillian.LogLeaf{{LeafValue: []byte("value")}}
+	now := clock.System.Now()
+	want := []*trillian.QueuedLogLeaf{{Leaf: leaves[0]}}
+	raw.EXPECT().QueueLeaves(gomock.Any(), tree, leaves, now).Return(want, nil)
+	got, err := ls.QueueLeaves(ctx, tree, leaves, now)
+	if err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if got[0].Leaf != leaves[0] {
+		t.Errorf("QueueLeaves() returned leaf %+v, want the passed-in leaf", got[0].Leaf)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/trillian"
+)
+
+// dataKeySize is the size of the AES-256 data keys generated by NewDataKey.
+const dataKeySize = 32
+
+// KeyWrapper encrypts and decrypts data keys with key encryption keys, which
+// are typically held by a KMS.
+type KeyWrapper interface {
+	// WrapKey encrypts dataKey with the key encryption key named by keyURI.
+	WrapKey(ctx context.Context, keyURI string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key returned by WrapKey.
+	UnwrapKey(ctx context.Context, keyURI string, wrapped []byte) ([]byte, error)
+}
+
+// NewDataKey generates a data key, and returns it wrapped by the key
+// encryption key named by keyURI.
+func NewDataKey(ctx context.Context, w KeyWrapper, keyURI string) (*trillian.LeafEncryption, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err := w.WrapKey(ctx, keyURI, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key with %q: %v", keyURI, err)
+	}
+	return &trillian.LeafEncryption{KeyUri: keyURI, WrappedKey: wrapped}, nil
+}
+
+// LocalKeyWrapper is a KeyWrapper whose key encryption keys are held in
+// memory. It is intended for testing, and for deployments which keep the keys
+// in a file outside the database.
+type LocalKeyWrapper struct {
+	keys map[string]cipher.AEAD
+}
+
+// NewLocalKeyWrapper returns a LocalKeyWrapper for the given AES key
+// encryption keys, keyed by URI.
+func NewLocalKeyWrapper(keys map[string][]byte) (*LocalKeyWrapper, error) {
+	w := &LocalKeyWrapper{keys: make(map[string]cipher.AEAD)}
+	for uri, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", uri, err)
+		}
+		w.keys[uri] = aead
+	}
+	return w, nil
+}
+
+// ParseLocalKeys reads key encryption keys for NewLocalKeyWrapper from r.
+// Each non-empty line which is not a "#" comment holds a key URI and a
+// hex-encoded AES key, separated by whitespace.
+func ParseLocalKeys(r io.Reader) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want <key-uri> <hex-key>", n)
+		}
+		key, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if _, ok := keys[fields[0]]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n, fields[0])
+		}
+		keys[fields[0]] = key
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// WrapKey implements KeyWrapper.
+func (w *LocalKeyWrapper) WrapKey(_ context.Context, keyURI string, dataKey []byte) ([]byte, error) {
+	aead, ok := w.keys[keyURI]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyURI)
+	}
+	return seal(aead, dataKey, []byte(keyURI))
+}
+
+// UnwrapKey implements KeyWrapper.
+func (w *LocalKeyWrapper) UnwrapKey(_ context.Context, keyURI string, wrapped []byte) ([]byte, error) {
+	aead, ok := w.keys[keyURI]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyURI)
+	}
+	return open(aead, wrapped, []byte(keyURI))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the
+// returned ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts ciphertext returned by seal.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
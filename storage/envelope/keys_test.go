@@ -0,0 +1,75 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseLocalKeys(t *testing.T) {
+	keys, err := ParseLocalKeys(strings.NewReader(`
+# Key encryption keys.
+local:a 000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f
+local:b   0f0e0d0c0b0a09080706050403020100
+`))
+	if err != nil {
+		t.Fatalf("ParseLocalKeys(): %v", err)
+	}
+	if got, want := len(keys), 2; got != want {
+		t.Fatalf("ParseLocalKeys() returned %d keys, want %d", got, want)
+	}
+	if got, want := len(keys["local:b"]), 16; got != want {
+		t.Errorf("len(keys[local:b]) = %d, want %d", got, want)
+	}
+
+	for _, bad := range []string{"local:a", "local:a xyz", "local:a 00 01", "local:a 00\nlocal:a 01"} {
+		if _, err := ParseLocalKeys(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseLocalKeys(%q) returned nil error", bad)
+		}
+	}
+}
+
+func TestLocalKeyWrapper(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewLocalKeyWrapper(map[string][]byte{"local:a": {1, 2, 3}}); err == nil {
+		t.Error("NewLocalKeyWrapper() with bad key size returned nil error")
+	}
+	w, err := NewLocalKeyWrapper(map[string][]byte{"local:a": make([]byte, 32), "local:b": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("NewLocalKeyWrapper(): %v", err)
+	}
+
+	enc, err := NewDataKey(ctx, w, "local:a")
+	if err != nil {
+		t.Fatalf("NewDataKey(): %v", err)
+	}
+	dataKey, err := w.UnwrapKey(ctx, "local:a", enc.WrappedKey)
+	if err != nil {
+		t.Fatalf("UnwrapKey(): %v", err)
+	}
+	if len(dataKey) != dataKeySize || bytes.Contains(enc.WrappedKey, dataKey) {
+		t.Errorf("UnwrapKey() = %x from wrapped key %x", dataKey, enc.WrappedKey)
+	}
+	// The wrapped key is bound to its key encryption key's URI.
+	if _, err := w.UnwrapKey(ctx, "local:b", enc.WrappedKey); err == nil {
+		t.Error("UnwrapKey() with another key returned nil error")
+	}
+	if _, err := NewDataKey(ctx, w, "local:c"); err == nil {
+		t.Error("NewDataKey() with unknown key returned nil error")
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errmetrics provides a storage.LogStorage wrapper which counts the
+// errors returned by storage operations by their canonical error code, for any
+// storage provider, so that contention (Aborted), overload or outage
+// (DeadlineExceeded, Unavailable) and misconfiguration (NotFound,
+// FailedPrecondition, PermissionDenied) can be told apart on dashboards.
+package errmetrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	logIDLabel   = "logid"
+	backendLabel = "backend"
+	opLabel      = "operation"
+	codeLabel    = "code"
+)
+
+var (
+	once     sync.Once
+	opErrors monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	opErrors = mf.NewCounter("storage_errors", "Number of storage operations which failed, by storage backend, operation and canonical error code", logIDLabel, backendLabel, opLabel, codeLabel)
+}
+
+// Code returns the canonical error code of an error returned by storage.
+// Context errors which haven't been converted to a status are mapped to their
+// codes, and other errors without a status are Unknown.
+func Code(err error) codes.Code {
+	switch {
+	case err == nil:
+		return codes.OK
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	return status.Code(err)
+}
+
+// LogStorage wraps a storage.LogStorage, counting the errors of its
+// operations.
+//
+// Each operation of a transaction is counted on its own, and so is the
+// failure of the whole transaction, under the name of the method which
+// started it.
+type LogStorage struct {
+	storage.LogStorage
+	backend string
+}
+
+// NewLogStorage returns a LogStorage which counts the errors returned by ls,
+// labelled with backend, which is usually the name of the storage provider.
+func NewLogStorage(ls storage.LogStorage, backend string, mf monitoring.MetricFactory) *LogStorage {
+	once.Do(func() { createMetrics(mf) })
+	return &LogStorage{LogStorage: ls, backend: backend}
+}
+
+// record counts err, if not nil, as an error of operation op on treeID.
+func (s *LogStorage) record(treeID int64, op string, err error) {
+	if err == nil {
+		return
+	}
+	opErrors.Inc(strconv.FormatInt(treeID, 10), s.backend, op, Code(err).String())
+}
+
+// SnapshotForTree implements storage.LogStorage.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		s.record(tree.TreeId, "SnapshotForTree", err)
+		return nil, err
+	}
+	return &snapshot{ReadOnlyLogTreeTX: tx, s: s, treeID: tree.TreeId}, nil
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, &logTX{snapshot: &snapshot{ReadOnlyLogTreeTX: tx, s: s, treeID: tree.TreeId}, tx: tx})
+	})
+	s.record(tree.TreeId, "ReadWriteTransaction", err)
+	return err
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ret, err := s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	s.record(tree.TreeId, "QueueLeaves", err)
+	return ret, err
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ret, err := s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	s.record(tree.TreeId, "AddSequencedLeaves", err)
+	return ret, err
+}
+
+// CountUnsequenced implements storage.LogStorage.
+func (s *LogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	ret, err := s.LogStorage.CountUnsequenced(ctx, tree)
+	s.record(tree.TreeId, "CountUnsequenced", err)
+	return ret, err
+}
+
+// snapshot counts the errors of the reads made through a transaction.
+type snapshot struct {
+	storage.ReadOnlyLogTreeTX
+	s      *LogStorage
+	treeID int64
+}
+
+// record counts an error of an operation of the transaction.
+func (t *snapshot) record(op string, err error) {
+	t.s.record(t.treeID, op, err)
+}
+
+func (t *snapshot) Commit(ctx context.Context) error {
+	err := t.ReadOnlyLogTreeTX.Commit(ctx)
+	t.record("Commit", err)
+	return err
+}
+
+func (t *snapshot) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]tree.Node, error) {
+	nodes, err := t.ReadOnlyLogTreeTX.GetMerkleNodes(ctx, ids)
+	t.record("GetMerkleNodes", err)
+	return nodes, err
+}
+
+func (t *snapshot) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByRange(ctx, start, count)
+	t.record("GetLeavesByRange", err)
+	return leaves, err
+}
+
+func (t *snapshot) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByHash(ctx, leafHashes, orderBySequence)
+	t.record("GetLeavesByHash", err)
+	return leaves, err
+}
+
+func (t *snapshot) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	root, err := t.ReadOnlyLogTreeTX.LatestSignedLogRoot(ctx)
+	t.record("LatestSignedLogRoot", err)
+	return root, err
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *snapshot) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := storage.GetLeavesByIndices(ctx, t.ReadOnlyLogTreeTX, indices)
+	t.record("GetLeavesByIndices", err)
+	return leaves, err
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *snapshot) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	leaves, err := r.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence)
+	t.record("GetLeavesByIdentityHash", err)
+	return leaves, err
+}
+
+// GetSignedLogRootBySize implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	root, err := r.GetSignedLogRootBySize(ctx, treeSize)
+	t.record("GetSignedLogRootBySize", err)
+	return root, err
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	root, err := r.GetSignedLogRootByHash(ctx, rootHash)
+	t.record("GetSignedLogRootByHash", err)
+	return root, err
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) ListSignedLogRoots(ctx context.Context, rng storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	roots, err := r.ListSignedLogRoots(ctx, rng, limit)
+	t.record("ListSignedLogRoots", err)
+	return roots, err
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader if the
+// underlying transaction does.
+func (t *snapshot) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IntegrationEventReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	events, err := r.ListIntegrationEvents(ctx, before, limit)
+	t.record("ListIntegrationEvents", err)
+	return events, err
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter if the underlying
+// transaction does.
+func (t *snapshot) CountUnsequenced(ctx context.Context) (int64, error) {
+	c, ok := t.ReadOnlyLogTreeTX.(storage.UnsequencedCounter)
+	if !ok {
+		return 0, status.Error(codes.Unimplemented, "storage does not support counting unsequenced leaves")
+	}
+	count, err := c.CountUnsequenced(ctx)
+	t.record("CountUnsequenced", err)
+	return count, err
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader if the
+// underlying transaction does.
+func (t *snapshot) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.SequencedIndexReader)
+	if !ok {
+		return 0, 0, status.Error(codes.Unimplemented, "storage does not support finding the range of sequenced leaves")
+	}
+	first, last, err := r.GetSequencedIndexRange(ctx)
+	t.record("GetSequencedIndexRange", err)
+	return first, last, err
+}
+
+// logTX counts the errors of the reads and writes made through a read-write
+// transaction.
+type logTX struct {
+	*snapshot
+	tx storage.LogTreeTX
+}
+
+func (t *logTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	err := t.tx.SetMerkleNodes(ctx, nodes)
+	t.record("SetMerkleNodes", err)
+	return err
+}
+
+func (t *logTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	err := t.tx.StoreSignedLogRoot(ctx, root)
+	t.record("StoreSignedLogRoot", err)
+	return err
+}
+
+func (t *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.tx.DequeueLeaves(ctx, limit, cutoff)
+	t.record("DequeueLeaves", err)
+	return leaves, err
+}
+
+func (t *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	err := t.tx.UpdateSequencedLeaves(ctx, leaves)
+	t.record("UpdateSequencedLeaves", err)
+	return err
+}
+
+// Commit implements storage.LogTreeTX.
+func (t *logTX) Commit(ctx context.Context) error {
+	err := t.tx.Commit(ctx)
+	t.record("Commit", err)
+	return err
+}
+
+// Close implements storage.LogTreeTX.
+func (t *logTX) Close() error {
+	return t.tx.Close()
+}
+
+// WriteSequencedLeaves implements storage.SequencedLeafWriter if the
+// underlying transaction does.
+func (t *logTX) WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	w, ok := t.tx.(storage.SequencedLeafWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not support writing sequenced leaves")
+	}
+	err := w.WriteSequencedLeaves(ctx, leaves)
+	t.record("WriteSequencedLeaves", err)
+	return err
+}
+
+// UpdateLeafExtraData implements storage.ExtraDataUpdater if the underlying
+// transaction does.
+func (t *logTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	u, ok := t.tx.(storage.ExtraDataUpdater)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support updating leaf extra data")
+	}
+	leaf, err := u.UpdateLeafExtraData(ctx, index, extraData, reason, timestamp)
+	t.record("UpdateLeafExtraData", err)
+	return leaf, err
+}
+
+// RedactLeaf implements storage.LeafRedactor if the underlying transaction
+// does.
+func (t *logTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	r, ok := t.tx.(storage.LeafRedactor)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support redacting leaves")
+	}
+	leaf, err := r.RedactLeaf(ctx, index, reason, timestamp)
+	t.record("RedactLeaf", err)
+	return leaf, err
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader if the underlying
+// transaction does.
+func (t *logTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	r, ok := t.tx.(storage.StagedLeafReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support staged leaves")
+	}
+	leaves, err := r.GetStagedLeaves(ctx, start, count)
+	t.record("GetStagedLeaves", err)
+	return leaves, err
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter if the
+// underlying transaction does.
+func (t *logTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	w, ok := t.tx.(storage.IntegrationEventWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	err := w.AddIntegrationEvent(ctx, event, cutoff)
+	t.record("AddIntegrationEvent", err)
+	return err
+}
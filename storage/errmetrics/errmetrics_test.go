@@ -0,0 +1,130 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring/testonly"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testTree = &trillian.Tree{TreeId: 1234}
+
+func TestCode(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want codes.Code
+	}{
+		{err: nil, want: codes.OK},
+		{err: status.Error(codes.Aborted, "deadlock"), want: codes.Aborted},
+		{err: storage.ErrTreeNeedsInit, want: codes.FailedPrecondition},
+		{err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: codes.DeadlineExceeded},
+		{err: context.Canceled, want: codes.Canceled},
+		{err: errors.New("oops"), want: codes.Unknown},
+	} {
+		if got := Code(test.err); got != test.want {
+			t.Errorf("Code(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	tx := storage.NewMockLogTreeTX(ctrl)
+	s := NewLogStorage(ls, "mysql", nil)
+
+	aborted := status.Error(codes.Aborted, "deadlock")
+	ls.EXPECT().ReadWriteTransaction(gomock.Any(), testTree, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *trillian.Tree, f storage.LogTXFunc) error { return f(ctx, tx) })
+	tx.EXPECT().DequeueLeaves(gomock.Any(), 10, gomock.Any()).Return(nil, nil)
+	tx.EXPECT().UpdateSequencedLeaves(gomock.Any(), gomock.Any()).Return(aborted)
+	ls.EXPECT().QueueLeaves(gomock.Any(), testTree, gomock.Any(), gomock.Any()).Return(nil, context.DeadlineExceeded)
+
+	label := "1234"
+	dequeueErrs := testonly.NewCounterSnapshot(opErrors, label, "mysql", "DequeueLeaves", "Aborted")
+	updateErrs := testonly.NewCounterSnapshot(opErrors, label, "mysql", "UpdateSequencedLeaves", "Aborted")
+	txErrs := testonly.NewCounterSnapshot(opErrors, label, "mysql", "ReadWriteTransaction", "Aborted")
+	queueErrs := testonly.NewCounterSnapshot(opErrors, label, "mysql", "QueueLeaves", "DeadlineExceeded")
+	err := s.ReadWriteTransaction(ctx, testTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.DequeueLeaves(ctx, 10, time.Now())
+		if err != nil {
+			return err
+		}
+		// Optional interfaces are forwarded, if the storage implements them.
+		if err := tx.(storage.SequencedLeafWriter).WriteSequencedLeaves(ctx, nil); status.Code(err) != codes.Unimplemented {
+			t.Errorf("WriteSequencedLeaves() = %v, want Unimplemented", err)
+		}
+		return tx.UpdateSequencedLeaves(ctx, leaves)
+	})
+	if err != aborted {
+		t.Fatalf("ReadWriteTransaction() = %v, want %v", err, aborted)
+	}
+	if _, err := s.QueueLeaves(ctx, testTree, nil, time.Now()); err != context.DeadlineExceeded {
+		t.Fatalf("QueueLeaves() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	for _, test := range []struct {
+		op    string
+		delta float64
+		want  float64
+	}{
+		{op: "DequeueLeaves", delta: dequeueErrs.Delta(), want: 0},
+		{op: "UpdateSequencedLeaves", delta: updateErrs.Delta(), want: 1},
+		{op: "ReadWriteTransaction", delta: txErrs.Delta(), want: 1},
+		{op: "QueueLeaves", delta: queueErrs.Delta(), want: 1},
+	} {
+		if test.delta != test.want {
+			t.Errorf("%s errors = %v, want %v", test.op, test.delta, test.want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	tx := storage.NewMockReadOnlyLogTreeTX(ctrl)
+	s := NewLogStorage(ls, "mysql", nil)
+
+	ls.EXPECT().SnapshotForTree(gomock.Any(), testTree).Return(tx, nil)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(nil, storage.ErrTreeNeedsInit)
+	tx.EXPECT().Close().Return(nil)
+
+	rootErrs := testonly.NewCounterSnapshot(opErrors, "1234", "mysql", "LatestSignedLogRoot", "FailedPrecondition")
+	snap, err := s.SnapshotForTree(ctx, testTree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree() = %v", err)
+	}
+	if _, err := snap.LatestSignedLogRoot(ctx); err != storage.ErrTreeNeedsInit {
+		t.Errorf("LatestSignedLogRoot() = %v, want %v", err, storage.ErrTreeNeedsInit)
+	}
+	if err := snap.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if got := rootErrs.Delta(); got != 1 {
+		t.Errorf("LatestSignedLogRoot errors = %v, want 1", got)
+	}
+}
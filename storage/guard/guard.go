@@ -0,0 +1,256 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package guard provides a storage.LogStorage wrapper which enforces that
+// logs are append-only: writes which would change an already sequenced leaf,
+// an already stored Merkle node, or roll back the published root fail loudly
+// instead of silently corrupting the log.
+package guard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const logIDLabel = "logid"
+
+var (
+	once       sync.Once
+	violations monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	violations = mf.NewCounter("storage_append_only_violations", "Number of storage writes rejected because they would modify existing log data, by kind", logIDLabel, "kind")
+}
+
+// LogStorage wraps a storage.LogStorage, checking the writes made in its
+// read-write transactions.
+//
+// The ExtraData of leaves, and the LeafValue of leaves being redacted, may
+// still be changed, as they are not covered by the Merkle tree.
+type LogStorage struct {
+	storage.LogStorage
+}
+
+// NewLogStorage returns a LogStorage which guards the writes to ls.
+func NewLogStorage(ls storage.LogStorage, mf monitoring.MetricFactory) *LogStorage {
+	once.Do(func() { createMetrics(mf) })
+	return &LogStorage{LogStorage: ls}
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, &logTX{LogTreeTX: tx, treeID: tree.TreeId})
+	})
+}
+
+// logTX checks the writes made through a transaction.
+type logTX struct {
+	storage.LogTreeTX
+	treeID int64
+}
+
+// violation records and returns an error for a rejected write.
+func (t *logTX) violation(kind, format string, args ...interface{}) error {
+	violations.Inc(strconv.FormatInt(t.treeID, 10), kind)
+	err := status.Errorf(codes.Internal, "append-only violation in tree %d: "+format, append([]interface{}{t.treeID}, args...)...)
+	klog.Errorf("%v", err)
+	return err
+}
+
+// currentRoot returns the latest root of the tree, or nil if the tree is not
+// initialised.
+func (t *logTX) currentRoot(ctx context.Context) (*types.LogRootV1, error) {
+	slr, err := t.LogTreeTX.LatestSignedLogRoot(ctx)
+	if errors.Is(err, storage.ErrTreeNeedsInit) || (err == nil && slr == nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "could not read current log root: %v", err)
+	}
+	return &root, nil
+}
+
+// UpdateSequencedLeaves implements storage.LogTreeTX. It rejects leaves with
+// indices within the current tree, which would replace published leaves.
+func (t *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	root, err := t.currentRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if root != nil {
+		for _, leaf := range leaves {
+			if leaf.LeafIndex < int64(root.TreeSize) {
+				return t.violation("leaf", "leaf index %d is within tree size %d", leaf.LeafIndex, root.TreeSize)
+			}
+		}
+	}
+	return t.LogTreeTX.UpdateSequencedLeaves(ctx, leaves)
+}
+
+// WriteSequencedLeaves implements storage.SequencedLeafWriter if the
+// underlying transaction does. Like UpdateSequencedLeaves, it rejects leaves
+// with indices within the current tree.
+func (t *logTX) WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	w, ok := t.LogTreeTX.(storage.SequencedLeafWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not support writing sequenced leaves")
+	}
+	root, err := t.currentRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if root != nil {
+		for _, leaf := range leaves {
+			if leaf.LeafIndex < int64(root.TreeSize) {
+				return t.violation("leaf", "leaf index %d is within tree size %d", leaf.LeafIndex, root.TreeSize)
+			}
+		}
+	}
+	return w.WriteSequencedLeaves(ctx, leaves)
+}
+
+// SetMerkleNodes implements storage.LogTreeTX. Log nodes never change once
+// written, so it rejects nodes within the current tree which are already
+// stored with another hash.
+func (t *logTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	root, err := t.currentRoot(ctx)
+	if err != nil {
+		return err
+	}
+	var ids []compact.NodeID
+	if root != nil {
+		for _, n := range nodes {
+			// A node is complete, and so stored, once the tree covers all of
+			// its leaves.
+			if _, end := n.ID.Coverage(); end <= root.TreeSize {
+				ids = append(ids, n.ID)
+			}
+		}
+	}
+	if len(ids) > 0 {
+		existing, err := t.LogTreeTX.GetMerkleNodes(ctx, ids)
+		if err != nil {
+			return err
+		}
+		hashes := make(map[compact.NodeID][]byte, len(existing))
+		for _, n := range existing {
+			hashes[n.ID] = n.Hash
+		}
+		for _, n := range nodes {
+			if h, ok := hashes[n.ID]; ok && !bytes.Equal(h, n.Hash) {
+				return t.violation("node", "node %+v already has hash %x, not %x", n.ID, h, n.Hash)
+			}
+		}
+	}
+	return t.LogTreeTX.SetMerkleNodes(ctx, nodes)
+}
+
+// StoreSignedLogRoot implements storage.LogTreeTX. It rejects roots which are
+// smaller than the current one, or the same size with a different hash.
+func (t *logTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.SignedLogRoot) error {
+	current, err := t.currentRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return status.Errorf(codes.InvalidArgument, "could not read new log root: %v", err)
+		}
+		if root.TreeSize < current.TreeSize {
+			return t.violation("root", "new root size %d is smaller than current size %d", root.TreeSize, current.TreeSize)
+		}
+		if root.TreeSize == current.TreeSize && !bytes.Equal(root.RootHash, current.RootHash) {
+			return t.violation("root", "new root hash %x for size %d differs from current hash %x", root.RootHash, root.TreeSize, current.RootHash)
+		}
+	}
+	return t.LogTreeTX.StoreSignedLogRoot(ctx, slr)
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *logTX) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	return storage.GetLeavesByIndices(ctx, t.LogTreeTX, indices)
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *logTX) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	r, ok := t.LogTreeTX.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	return r.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence)
+}
+
+// UpdateLeafExtraData implements storage.ExtraDataUpdater if the underlying
+// transaction does.
+func (t *logTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	u, ok := t.LogTreeTX.(storage.ExtraDataUpdater)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support updating leaf extra data")
+	}
+	return u.UpdateLeafExtraData(ctx, index, extraData, reason, timestamp)
+}
+
+// RedactLeaf implements storage.LeafRedactor if the underlying transaction
+// does.
+func (t *logTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	r, ok := t.LogTreeTX.(storage.LeafRedactor)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support redacting leaves")
+	}
+	return r.RedactLeaf(ctx, index, reason, timestamp)
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader if the underlying
+// transaction does.
+func (t *logTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	r, ok := t.LogTreeTX.(storage.StagedLeafReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support staged leaves")
+	}
+	return r.GetStagedLeaves(ctx, start, count)
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter if the
+// underlying transaction does.
+func (t *logTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	w, ok := t.LogTreeTX.(storage.IntegrationEventWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	return w.AddIntegrationEvent(ctx, event, cutoff)
+}
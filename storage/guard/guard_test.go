@@ -0,0 +1,143 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/guard"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+	stree "github.com/google/trillian/storage/tree"
+)
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	log.InitMetrics(nil)
+
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   guard.NewLogStorage(memory.NewLogStorage(ts, nil), nil),
+		QuotaManager: quota.Noop(),
+	}
+	s := server.NewTrillianLogRPCServer(registry, clock.System)
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if _, err := s.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+
+	// Normal sequencing, in batches of various sizes, is allowed.
+	const leafCount = 70
+	for i := 0; i < leafCount; i++ {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d", i))}
+		if _, err := s.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf}); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+		if i%7 != 6 {
+			continue
+		}
+		if _, err := log.IntegrateBatch(ctx, tree, 1+i%3, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+	}
+	for {
+		n, err := log.IntegrateBatch(ctx, tree, 10, 0, 0, clock.System, registry.LogStorage, registry.QuotaManager)
+		if err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	otherHash := sha256.Sum256([]byte("other"))
+	for _, test := range []struct {
+		desc  string
+		write func(context.Context, storage.LogTreeTX, *types.LogRootV1) error
+	}{
+		{
+			desc: "resequence-leaf",
+			write: func(ctx context.Context, tx storage.LogTreeTX, _ *types.LogRootV1) error {
+				return tx.UpdateSequencedLeaves(ctx, []*trillian.LogLeaf{{LeafIndex: 3, MerkleLeafHash: otherHash[:], LeafIdentityHash: otherHash[:]}})
+			},
+		},
+		{
+			desc: "change-node",
+			write: func(ctx context.Context, tx storage.LogTreeTX, _ *types.LogRootV1) error {
+				return tx.SetMerkleNodes(ctx, []stree.Node{{ID: compact.NewNodeID(1, 2), Hash: otherHash[:]}})
+			},
+		},
+		{
+			desc: "shrink-root",
+			write: func(ctx context.Context, tx storage.LogTreeTX, root *types.LogRootV1) error {
+				return storeRoot(ctx, tx, &types.LogRootV1{TreeSize: root.TreeSize - 1, RootHash: otherHash[:], Revision: root.Revision + 1})
+			},
+		},
+		{
+			desc: "fork-root",
+			write: func(ctx context.Context, tx storage.LogTreeTX, root *types.LogRootV1) error {
+				return storeRoot(ctx, tx, &types.LogRootV1{TreeSize: root.TreeSize, RootHash: otherHash[:], Revision: root.Revision + 1})
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+				slr, err := tx.LatestSignedLogRoot(ctx)
+				if err != nil {
+					return err
+				}
+				var root types.LogRootV1
+				if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+					return err
+				}
+				if root.TreeSize != leafCount {
+					t.Fatalf("TreeSize = %d, want %d", root.TreeSize, leafCount)
+				}
+				return test.write(ctx, tx, &root)
+			})
+			if status.Code(err) != codes.Internal || !strings.Contains(err.Error(), "append-only violation") {
+				t.Errorf("ReadWriteTransaction() = %v, want append-only violation", err)
+			}
+		})
+	}
+}
+
+func storeRoot(ctx context.Context, tx storage.LogTreeTX, root *types.LogRootV1) error {
+	logRoot, err := root.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+}
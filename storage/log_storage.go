@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
 	"github.com/transparency-dev/merkle/compact"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -103,6 +105,180 @@ type LogTreeTX interface {
 	UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
 }
 
+// IndexedLeafReader is implemented by ReadOnlyLogTreeTX implementations
+// which can read leaves with arbitrary indices in one batch. See
+// GetLeavesByIndices.
+type IndexedLeafReader interface {
+	// GetLeavesByIndices returns the integrated leaves with the given indices,
+	// in any order. Indices without a leaf are skipped.
+	GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error)
+}
+
+// GetLeavesByIndices returns the integrated leaves of tx with the given
+// indices, in any order, using a single batch if tx is an IndexedLeafReader
+// and reading the leaves one at a time otherwise.
+func GetLeavesByIndices(ctx context.Context, tx ReadOnlyLogTreeTX, indices []int64) ([]*trillian.LogLeaf, error) {
+	if r, ok := tx.(IndexedLeafReader); ok {
+		return r.GetLeavesByIndices(ctx, indices)
+	}
+	leaves := make([]*trillian.LogLeaf, 0, len(indices))
+	for _, index := range indices {
+		l, err := tx.GetLeavesByRange(ctx, index, 1)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, l...)
+	}
+	return leaves, nil
+}
+
+// IdentityHashReader is implemented by ReadOnlyLogTreeTX implementations
+// which can look up integrated leaves by LeafIdentityHash.
+type IdentityHashReader interface {
+	// GetLeavesByIdentityHash returns the integrated leaves with any of the
+	// given identity hashes, with their LeafIndex set.
+	GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error)
+}
+
+// RootHistoryReader is implemented by ReadOnlyLogTreeTX implementations which
+// keep the earlier roots of the tree, as well as the latest.
+type RootHistoryReader interface {
+	// GetSignedLogRootBySize returns the most recent root of the tree, up to
+	// the latest, with the given tree size, or a NotFound error if there is
+	// none.
+	GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error)
+	// GetSignedLogRootByHash returns the most recent root of the tree, up to
+	// the latest, with the given root hash, or a NotFound error if there is
+	// none.
+	GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error)
+	// ListSignedLogRoots returns up to limit of the roots of the tree, up to
+	// the latest, which are in the given range, in increasing order of their
+	// timestamps.
+	ListSignedLogRoots(ctx context.Context, r RootRange, limit int) ([]*trillian.SignedLogRoot, error)
+}
+
+// RootRange selects roots of a tree by their timestamps and tree sizes. Both
+// ranges are half-open, and unbounded above if their end is zero.
+type RootRange struct {
+	StartTimestampNanos, EndTimestampNanos uint64
+	StartTreeSize, EndTreeSize             uint64
+}
+
+// Contains returns whether root is in the range.
+func (r RootRange) Contains(root *types.LogRootV1) bool {
+	return root.TimestampNanos >= r.StartTimestampNanos && (r.EndTimestampNanos == 0 || root.TimestampNanos < r.EndTimestampNanos) &&
+		root.TreeSize >= r.StartTreeSize && (r.EndTreeSize == 0 || root.TreeSize < r.EndTreeSize)
+}
+
+// IntegrationEventReader is implemented by ReadOnlyLogTreeTX implementations
+// which keep a history of the integration runs of the tree.
+type IntegrationEventReader interface {
+	// ListIntegrationEvents returns up to limit of the recorded integration
+	// events which started before the given time, or any time if it is zero,
+	// most recent first.
+	ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error)
+}
+
+// IntegrationEventWriter is implemented by LogTreeTX implementations which
+// keep a history of the integration runs of the tree.
+type IntegrationEventWriter interface {
+	// AddIntegrationEvent records an integration run of the tree, and drops
+	// the recorded events which started before the cutoff time, so that the
+	// history doesn't grow without bound.
+	AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error
+}
+
+// UnsequencedCounter is implemented by ReadOnlyLogTreeTX implementations which
+// can count the leaves queued in the tree but not yet integrated.
+type UnsequencedCounter interface {
+	// CountUnsequenced returns the number of leaves in the queue of the tree.
+	CountUnsequenced(ctx context.Context) (int64, error)
+}
+
+// SequencedIndexReader is implemented by ReadOnlyLogTreeTX implementations
+// which can find the range of indices at which leaves are stored without
+// reading or counting the leaves.
+type SequencedIndexReader interface {
+	// GetSequencedIndexRange returns the lowest and highest indices at which
+	// leaves of the tree are stored, including any beyond the size of the
+	// latest root, or -1 for both if there are none.
+	GetSequencedIndexRange(ctx context.Context) (first, last int64, err error)
+}
+
+// ExtraDataUpdater is implemented by LogTreeTX implementations which support
+// replacing the ExtraData of integrated leaves.
+type ExtraDataUpdater interface {
+	// UpdateLeafExtraData replaces the ExtraData of the leaf at index, and
+	// records its previous ExtraData and the reason for the change in an audit
+	// trail. It returns the updated leaf, or a NotFound error if there is no
+	// leaf at index.
+	UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error)
+}
+
+// LeafRedactor is implemented by LogTreeTX implementations which support
+// redacting the LeafValue of integrated leaves.
+type LeafRedactor interface {
+	// RedactLeaf replaces the LeafValue of the leaf at index with an empty
+	// tombstone, keeping its MerkleLeafHash, and records the reason for the
+	// redaction in an audit trail. Leaves read afterwards have Redacted set.
+	// It returns the redacted leaf, a NotFound error if there is no leaf at
+	// index, or an AlreadyExists error if it is already redacted.
+	RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error)
+}
+
+// SequencedLeafWriter is implemented by LogTreeTX implementations which can
+// store integrated leaves directly, rather than through the queue, as needed
+// to replicate logs to standby storage.
+type SequencedLeafWriter interface {
+	// WriteSequencedLeaves stores the leaves, which must have their LeafIndex
+	// and IntegrateTimestamp set, as integrated leaves of the tree. Storing a
+	// leaf at an index which already has one is an error.
+	WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
+}
+
+// StagedLeafReader is implemented by LogTreeTX implementations which allow a
+// batch to be integrated over several transactions. Leaves sequenced, and
+// Merkle nodes written, beyond the tree size of the latest root are not
+// visible to readers, so they can be staged ahead of the root which publishes
+// them, as long as that root is stored last.
+type StagedLeafReader interface {
+	// GetStagedLeaves returns up to count leaves sequenced at consecutive
+	// indices from start, which must not be less than the size of the latest
+	// root, stopping at the first missing index. These are the leaves of a
+	// batch whose integration has been started, but not yet published.
+	GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error)
+}
+
+// QueueRepairer is implemented by LogTreeTX implementations which can list and
+// repair leaves stuck in the queue of unsequenced leaves, e.g. because of a
+// historic bug or a misconfigured guard window. Queued leaves are identified
+// by their LeafIdentityHash and QueueTimestamp.
+type QueueRepairer interface {
+	// ListQueuedLeaves returns up to limit queued leaves whose QueueTimestamp
+	// is before the given time, oldest first. Only their LeafIdentityHash,
+	// MerkleLeafHash and QueueTimestamp are set.
+	ListQueuedLeaves(ctx context.Context, before time.Time, limit int) ([]*trillian.LogLeaf, error)
+	// RequeueLeaves replaces the QueueTimestamp of the given queued leaves
+	// with queueTimestamp. It returns a NotFound error if any of them isn't
+	// queued.
+	RequeueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) error
+	// DiscardQueuedLeaves removes the given queued leaves from the queue,
+	// along with any data stored for deduplicating them, so that they can be
+	// submitted again. It returns a NotFound error if any of them isn't
+	// queued.
+	DiscardQueuedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
+}
+
+// SubtreeReader is implemented by ReadOnlyLogTreeTX implementations which can
+// return the tiles of Merkle nodes exactly as they are stored, for debugging.
+type SubtreeReader interface {
+	// ReadSubtrees returns the stored tiles with the given IDs, as of the
+	// given tree revision, or of the transaction's read revision if revision
+	// is negative. Tiles which aren't stored are omitted. The internal nodes
+	// of the returned tiles are only those which are stored, if any.
+	ReadSubtrees(ctx context.Context, ids [][]byte, revision int64) ([]*storagepb.SubtreeProto, error)
+}
+
 // ReadOnlyLogStorage represents a narrowed read-only view into a LogStorage.
 type ReadOnlyLogStorage interface {
 	// CheckDatabaseAccessible returns nil if the database is accessible, or an
@@ -169,4 +345,11 @@ type LogStorage interface {
 	// TODO(pavelkalinnikov): Not checking values of the occupied indices might
 	// be a good optimization. Could also be optional.
 	AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error)
+
+	// CountUnsequenced returns the number of leaves queued in the tree but not
+	// yet integrated, without starting a transaction where the storage allows
+	// it. It is cheap enough to be called on every sequencing pass, so that
+	// idle trees can be skipped. Storage implementations which can't count
+	// the queue return an Unimplemented error.
+	CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error)
 }
@@ -18,10 +18,11 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
@@ -118,7 +119,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 		return nil, err
 	}
 
-	now := time.Now()
+	now := t.ms.timeSource.Now()
 
 	meta := proto.Clone(tr).(*trillian.Tree)
 	meta.TreeId = id
@@ -140,6 +141,25 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 	return meta, nil
 }
 
+// ImportTree implements storage.TreeImporter.
+func (t *adminTX) ImportTree(ctx context.Context, tr *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForImport(ctx, tr); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tr); err != nil {
+		return nil, err
+	}
+	meta := proto.Clone(tr).(*trillian.Tree)
+
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+	if _, ok := t.ms.trees[meta.TreeId]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %d already exists", meta.TreeId)
+	}
+	t.ms.trees[meta.TreeId] = newTree(meta)
+	return meta, nil
+}
+
 func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
 	mTree := t.ms.getTree(treeID)
 	mTree.mu.Lock()
@@ -155,7 +175,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		return nil, err
 	}
 
-	tree.UpdateTime = timestamppb.New(time.Now())
+	tree.UpdateTime = timestamppb.New(t.ms.timeSource.Now())
 	if err := tree.UpdateTime.CheckValid(); err != nil {
 		return nil, err
 	}
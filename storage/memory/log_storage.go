@@ -15,24 +15,30 @@
 package memory
 
 import (
+	"bytes"
 	"container/list"
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/btree"
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
 	stree "github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"github.com/transparency-dev/merkle/compact"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
 
@@ -72,6 +78,40 @@ func hashToSeqKey(treeID int64) btree.Item {
 	return &kv{k: fmt.Sprintf("/%d/h2s", treeID)}
 }
 
+// idToSeqKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the sequence numbers of the leaves with
+// the given identity hash.
+func idToSeqKey(treeID int64) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/i2s", treeID)}
+}
+
+// extraDataKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the extraDataChange made to the leaf at
+// the given sequence number at the given time.
+func extraDataKey(treeID, seq int64, timestamp time.Time) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/xd/%020d/%020d", treeID, seq, timestamp.UnixNano())}
+}
+
+// extraDataChange is an audit trail entry for an UpdateLeafExtraData call.
+type extraDataChange struct {
+	previous, extraData []byte
+	reason              string
+}
+
+// redactionKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the reason the leaf at the given
+// sequence number was redacted.
+func redactionKey(treeID, seq int64) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/redact/%020d", treeID, seq)}
+}
+
+// eventKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the IntegrationEvent which started at the
+// given time.
+func eventKey(treeID int64, start time.Time) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/event/%020d", treeID, start.UnixNano())}
+}
+
 // sthKey formats a key for use in a tree's BTree store.
 // The associated Item value will be the STH with the given timestamp.
 func sthKey(treeID int64, timestamp uint64) btree.Item {
@@ -133,8 +173,12 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, tree *trillian.Tre
 		createMetrics(m.metricFactory)
 	})
 
-	stCache := cache.NewLogSubtreeCache(rfc6962.DefaultHasher)
-	ttx, err := m.beginTreeTX(ctx, tree.TreeId, rfc6962.DefaultHasher.Size(), stCache, readonly)
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	stCache := cache.NewLogSubtreeCache(hasher)
+	ttx, err := m.beginTreeTX(ctx, tree.TreeId, hasher.Size(), stCache, readonly)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +191,7 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, tree *trillian.Tre
 	var rev int64
 	ltx.slr, rev, err = ltx.fetchLatestRoot(ctx)
 	if err == storage.ErrTreeNeedsInit {
+		ltx.writeRevision = 0
 		return ltx, err
 	} else if err != nil {
 		if err := ttx.Close(); err != nil {
@@ -187,6 +232,20 @@ func (m *memoryLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillia
 	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
 }
 
+// CountUnsequenced implements storage.LogStorage.
+func (m *memoryLogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* readonly */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return 0, err
+	}
+	defer func() {
+		if err := tx.Close(); err != nil {
+			klog.Errorf("tx.Close(): %v", err)
+		}
+	}()
+	return tx.CountUnsequenced(ctx)
+}
+
 func (m *memoryLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
 	tx, err := m.beginInternal(ctx, tree, true /* readonly */)
 	if err != nil {
@@ -246,15 +305,24 @@ func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([
 	return t.subtreeCache.GetNodes(ids, t.getSubtreesAtRev(ctx, rev))
 }
 
+// ReadSubtrees implements storage.SubtreeReader.
+func (t *logTreeTX) ReadSubtrees(ctx context.Context, ids [][]byte, revision int64) ([]*storagepb.SubtreeProto, error) {
+	if revision < 0 {
+		revision = t.writeRevision - 1
+	}
+	return t.getSubtrees(ctx, revision, ids)
+}
+
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
 	leaves := make([]*trillian.LogLeaf, 0, limit)
 
 	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
-	e := q.Front()
-	for i := 0; i < limit && e != nil; i++ {
-		// TODO(al): consider cutoffTime
-		leaves = append(leaves, e.Value.(*trillian.LogLeaf))
-		e = e.Next()
+	for e := q.Front(); len(leaves) < limit && e != nil; e = e.Next() {
+		leaf := e.Value.(*trillian.LogLeaf)
+		if leaf.QueueTimestamp.AsTime().After(cutoffTime) {
+			continue
+		}
+		leaves = append(leaves, leaf)
 	}
 
 	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
@@ -267,6 +335,10 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
 			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
 		}
+		leaf.QueueTimestamp = timestamppb.New(queueTimestamp)
+		if err := leaf.QueueTimestamp.CheckValid(); err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %w", err)
+		}
 	}
 	queuedCounter.Add(float64(len(leaves)), labelForTX(t))
 	// No deduping in this storage!
@@ -278,6 +350,84 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	return make([]*trillian.LogLeaf, len(leaves)), nil
 }
 
+// ListQueuedLeaves implements storage.QueueRepairer.
+func (t *logTreeTX) ListQueuedLeaves(ctx context.Context, before time.Time, limit int) ([]*trillian.LogLeaf, error) {
+	var leaves []*trillian.LogLeaf
+	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
+	for e := q.Front(); e != nil; e = e.Next() {
+		leaf := e.Value.(*trillian.LogLeaf)
+		if leaf.QueueTimestamp.AsTime().Before(before) {
+			leaves = append(leaves, &trillian.LogLeaf{
+				LeafIdentityHash: leaf.LeafIdentityHash,
+				MerkleLeafHash:   leaf.MerkleLeafHash,
+				QueueTimestamp:   leaf.QueueTimestamp,
+			})
+		}
+	}
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].QueueTimestamp.AsTime().Before(leaves[j].QueueTimestamp.AsTime())
+	})
+	if len(leaves) > limit {
+		leaves = leaves[:limit]
+	}
+	return leaves, nil
+}
+
+// RequeueLeaves implements storage.QueueRepairer. Requeued leaves move to the
+// back of the queue.
+func (t *logTreeTX) RequeueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) error {
+	ts := timestamppb.New(queueTimestamp)
+	if err := ts.CheckValid(); err != nil {
+		return fmt.Errorf("got invalid queue timestamp: %w", err)
+	}
+	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
+	elems, err := findQueued(q, leaves)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		leaf := proto.Clone(e.Value.(*trillian.LogLeaf)).(*trillian.LogLeaf)
+		leaf.QueueTimestamp = ts
+		q.Remove(e)
+		q.PushBack(leaf)
+	}
+	return nil
+}
+
+// DiscardQueuedLeaves implements storage.QueueRepairer. This storage doesn't
+// deduplicate leaves.
+func (t *logTreeTX) DiscardQueuedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
+	elems, err := findQueued(q, leaves)
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		q.Remove(e)
+	}
+	return nil
+}
+
+// findQueued returns the elements of q holding the given leaves, or a NotFound
+// error if any of them isn't queued.
+func findQueued(q *list.List, leaves []*trillian.LogLeaf) ([]*list.Element, error) {
+	elems := make([]*list.Element, 0, len(leaves))
+	for _, leaf := range leaves {
+		var found *list.Element
+		for e := q.Front(); e != nil && found == nil; e = e.Next() {
+			queued := e.Value.(*trillian.LogLeaf)
+			if bytes.Equal(queued.LeafIdentityHash, leaf.LeafIdentityHash) && queued.QueueTimestamp.AsTime().Equal(leaf.QueueTimestamp.AsTime()) {
+				found = e
+			}
+		}
+		if found == nil {
+			return nil, status.Errorf(codes.NotFound, "leaf %x queued at %v not found", leaf.LeafIdentityHash, leaf.QueueTimestamp.AsTime())
+		}
+		elems = append(elems, found)
+	}
+	return elems, nil
+}
+
 func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
 	return nil, status.Errorf(codes.Unimplemented, "AddSequencedLeaves is not implemented")
 }
@@ -293,8 +443,184 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 	return ret, nil
 }
 
+// GetStagedLeaves implements storage.StagedLeafReader.
+func (t *logTreeTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	if treeSize := int64(t.root.TreeSize); start < treeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= TreeSize(%d)", start, treeSize)
+	}
+	ret := make([]*trillian.LogLeaf, 0, count)
+	for i := int64(0); i < count; i++ {
+		leaf := t.tx.Get(seqLeafKey(t.treeID, start+i))
+		if leaf == nil {
+			break
+		}
+		ret = append(ret, leaf.(*kv).v.(*trillian.LogLeaf))
+	}
+	return ret, nil
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader.
+func (t *logTreeTX) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	from := eventKey(t.treeID, time.Unix(0, math.MaxInt64))
+	if !before.IsZero() {
+		from = eventKey(t.treeID, before.Add(-time.Nanosecond))
+	}
+	var ret []*trillian.IntegrationEvent
+	t.tx.DescendRange(from, &kv{k: fmt.Sprintf("/%d/event/", t.treeID)}, func(i btree.Item) bool {
+		ret = append(ret, proto.Clone(i.(*kv).v.(*trillian.IntegrationEvent)).(*trillian.IntegrationEvent))
+		return len(ret) < limit
+	})
+	return ret, nil
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter.
+func (t *logTreeTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	var expired []btree.Item
+	t.tx.AscendRange(eventKey(t.treeID, time.Unix(0, 0)), eventKey(t.treeID, cutoff), func(i btree.Item) bool {
+		expired = append(expired, i)
+		return true
+	})
+	for _, i := range expired {
+		t.tx.Delete(i)
+	}
+	k := eventKey(t.treeID, event.StartTime.AsTime())
+	k.(*kv).v = proto.Clone(event)
+	t.tx.ReplaceOrInsert(k)
+	return nil
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *logTreeTX) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	ret := make([]*trillian.LogLeaf, 0, len(indices))
+	for _, index := range indices {
+		if leaf := t.tx.Get(seqLeafKey(t.treeID, index)); leaf != nil {
+			ret = append(ret, leaf.(*kv).v.(*trillian.LogLeaf))
+		}
+	}
+	return ret, nil
+}
+
 func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
-	m := t.tx.Get(hashToSeqKey(t.treeID)).(*kv).v.(map[string][]int64)
+	return t.getLeavesBySeqMap(hashToSeqKey(t.treeID), leafHashes), nil
+}
+
+// GetSignedLogRootBySize implements storage.RootHistoryReader.
+func (t *logTreeTX) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	var latest types.LogRootV1
+	if err := latest.UnmarshalBinary(t.slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	var slr *trillian.SignedLogRoot
+	var err error
+	t.tx.DescendRange(sthKey(t.treeID, latest.TimestampNanos), &kv{k: fmt.Sprintf("/%d/sth/", t.treeID)}, func(i btree.Item) bool {
+		r := i.(*kv).v.(*trillian.SignedLogRoot)
+		var root types.LogRootV1
+		if err = root.UnmarshalBinary(r.LogRoot); err != nil {
+			return false
+		}
+		if root.TreeSize == treeSize {
+			slr = r
+		}
+		// Roots only grow, so there's no need to look further back.
+		return root.TreeSize > treeSize
+	})
+	if err != nil {
+		return nil, err
+	}
+	if slr == nil {
+		return nil, status.Errorf(codes.NotFound, "no root of size %d", treeSize)
+	}
+	return slr, nil
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader.
+func (t *logTreeTX) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	var latest types.LogRootV1
+	if err := latest.UnmarshalBinary(t.slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	var slr *trillian.SignedLogRoot
+	var err error
+	t.tx.DescendRange(sthKey(t.treeID, latest.TimestampNanos), &kv{k: fmt.Sprintf("/%d/sth/", t.treeID)}, func(i btree.Item) bool {
+		r := i.(*kv).v.(*trillian.SignedLogRoot)
+		var root types.LogRootV1
+		if err = root.UnmarshalBinary(r.LogRoot); err != nil {
+			return false
+		}
+		if bytes.Equal(root.RootHash, rootHash) {
+			slr = r
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if slr == nil {
+		return nil, status.Errorf(codes.NotFound, "no root with hash %x", rootHash)
+	}
+	return slr, nil
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader.
+func (t *logTreeTX) ListSignedLogRoots(ctx context.Context, r storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	var latest types.LogRootV1
+	if err := latest.UnmarshalBinary(t.slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	end := latest.TimestampNanos + 1
+	if r.EndTimestampNanos != 0 && r.EndTimestampNanos < end {
+		end = r.EndTimestampNanos
+	}
+	var ret []*trillian.SignedLogRoot
+	var err error
+	t.tx.AscendRange(sthKey(t.treeID, r.StartTimestampNanos), sthKey(t.treeID, end), func(i btree.Item) bool {
+		slr := i.(*kv).v.(*trillian.SignedLogRoot)
+		var root types.LogRootV1
+		if err = root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return false
+		}
+		if r.Contains(&root) {
+			ret = append(ret, slr)
+		}
+		return len(ret) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter.
+func (t *logTreeTX) CountUnsequenced(ctx context.Context) (int64, error) {
+	return int64(t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List).Len()), nil
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader.
+func (t *logTreeTX) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	first, last := int64(-1), int64(-1)
+	t.tx.AscendRange(seqLeafKey(t.treeID, 0), seqLeafKey(t.treeID, math.MaxInt64), func(i btree.Item) bool {
+		first = i.(*kv).v.(*trillian.LogLeaf).LeafIndex
+		return false
+	})
+	// seqLeafKey(t.treeID, -1) sorts before the keys of all of the leaves.
+	t.tx.DescendRange(seqLeafKey(t.treeID, math.MaxInt64), seqLeafKey(t.treeID, -1), func(i btree.Item) bool {
+		last = i.(*kv).v.(*trillian.LogLeaf).LeafIndex
+		return false
+	})
+	return first, last, nil
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader.
+func (t *logTreeTX) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	return t.getLeavesBySeqMap(idToSeqKey(t.treeID), identityHashes), nil
+}
+
+// getLeavesBySeqMap returns the leaves whose sequence numbers are mapped to
+// by any of hashes in the map stored at key. Sequence numbers are added to the
+// maps in order, so the leaves for each hash are ordered by sequence.
+func (t *logTreeTX) getLeavesBySeqMap(key btree.Item, leafHashes [][]byte) []*trillian.LogLeaf {
+	m := t.tx.Get(key).(*kv).v.(map[string][]int64)
 
 	ret := make([]*trillian.LogLeaf, 0, len(leafHashes))
 	for _, hash := range leafHashes {
@@ -310,7 +636,50 @@ func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, or
 			ret = append(ret, l.(*kv).v.(*trillian.LogLeaf))
 		}
 	}
-	return ret, nil
+	return ret
+}
+
+// UpdateLeafExtraData implements storage.ExtraDataUpdater.
+func (t *logTreeTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	k := seqLeafKey(t.treeID, index)
+	item := t.tx.Get(k)
+	if item == nil {
+		return nil, status.Errorf(codes.NotFound, "no leaf at index %d", index)
+	}
+	// Leaves are shared with earlier snapshots, so replace rather than modify.
+	leaf := proto.Clone(item.(*kv).v.(*trillian.LogLeaf)).(*trillian.LogLeaf)
+	change := &extraDataChange{previous: leaf.ExtraData, extraData: extraData, reason: reason}
+	leaf.ExtraData = extraData
+	k.(*kv).v = leaf
+	t.tx.ReplaceOrInsert(k)
+
+	h := extraDataKey(t.treeID, index, timestamp)
+	h.(*kv).v = change
+	t.tx.ReplaceOrInsert(h)
+	return leaf, nil
+}
+
+// RedactLeaf implements storage.LeafRedactor.
+func (t *logTreeTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	k := seqLeafKey(t.treeID, index)
+	item := t.tx.Get(k)
+	if item == nil {
+		return nil, status.Errorf(codes.NotFound, "no leaf at index %d", index)
+	}
+	if item.(*kv).v.(*trillian.LogLeaf).Redacted {
+		return nil, status.Errorf(codes.AlreadyExists, "leaf %d is already redacted", index)
+	}
+	// Leaves are shared with earlier snapshots, so replace rather than modify.
+	leaf := proto.Clone(item.(*kv).v.(*trillian.LogLeaf)).(*trillian.LogLeaf)
+	leaf.LeafValue = nil
+	leaf.Redacted = true
+	k.(*kv).v = leaf
+	t.tx.ReplaceOrInsert(k)
+
+	r := redactionKey(t.treeID, index)
+	r.(*kv).v = reason
+	t.tx.ReplaceOrInsert(r)
+	return leaf, nil
 }
 
 func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
@@ -355,23 +724,12 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 }
 
 func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	if err := t.storeSequencedLeaves(leaves); err != nil {
+		return err
+	}
 	countByMerkleHash := make(map[string]int)
 	for _, leaf := range leaves {
-		// This should fail on insert but catch it early
-		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
-			return fmt.Errorf("sequenced leaf has incorrect hash size: got %v, want %v", got, want)
-		}
-		mh := string(leaf.MerkleLeafHash)
-		countByMerkleHash[mh]++
-		// insert sequenced leaf:
-		k := seqLeafKey(t.treeID, leaf.LeafIndex)
-		k.(*kv).v = leaf
-		t.tx.ReplaceOrInsert(k)
-		// update merkle-to-seq mapping:
-		m := t.tx.Get(hashToSeqKey(t.treeID))
-		l := m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)]
-		l = append(l, leaf.LeafIndex)
-		m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)] = l
+		countByMerkleHash[string(leaf.MerkleLeafHash)]++
 	}
 
 	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
@@ -397,3 +755,37 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 
 	return nil
 }
+
+// WriteSequencedLeaves implements storage.SequencedLeafWriter.
+func (t *logTreeTX) WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	for _, leaf := range leaves {
+		if t.tx.Get(seqLeafKey(t.treeID, leaf.LeafIndex)) != nil {
+			return status.Errorf(codes.AlreadyExists, "leaf index %d already has a leaf", leaf.LeafIndex)
+		}
+	}
+	return t.storeSequencedLeaves(leaves)
+}
+
+// storeSequencedLeaves stores the leaves at their LeafIndex, and indexes them
+// by hash.
+func (t *logTreeTX) storeSequencedLeaves(leaves []*trillian.LogLeaf) error {
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return fmt.Errorf("sequenced leaf has incorrect hash size: got %v, want %v", got, want)
+		}
+		// insert sequenced leaf:
+		k := seqLeafKey(t.treeID, leaf.LeafIndex)
+		k.(*kv).v = leaf
+		t.tx.ReplaceOrInsert(k)
+		// update merkle-to-seq mapping:
+		m := t.tx.Get(hashToSeqKey(t.treeID))
+		l := m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)]
+		l = append(l, leaf.LeafIndex)
+		m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)] = l
+		// update identity-to-seq mapping:
+		ids := t.tx.Get(idToSeqKey(t.treeID)).(*kv).v.(map[string][]int64)
+		ids[string(leaf.LeafIdentityHash)] = append(ids[string(leaf.LeafIdentityHash)], leaf.LeafIndex)
+	}
+	return nil
+}
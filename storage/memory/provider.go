@@ -15,6 +15,8 @@
 package memory
 
 import (
+	"strconv"
+
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"k8s.io/klog/v2"
@@ -29,12 +31,20 @@ func init() {
 type memProvider struct {
 	mf monitoring.MetricFactory
 	ts *TreeStorage
+
+	// items is the number of items in the BTree of each tree, as of the
+	// last call to ExportStats.
+	items monitoring.Gauge
 }
 
 func newMemoryStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
 	return &memProvider{
-		mf: mf,
-		ts: NewTreeStorage(),
+		mf:    mf,
+		ts:    NewTreeStorage(),
+		items: mf.NewGauge("memory_btree_items", "Number of items in the BTree of each tree", "treeid"),
 	}, nil
 }
 
@@ -49,3 +59,11 @@ func (s *memProvider) AdminStorage() storage.AdminStorage {
 func (s *memProvider) Close() error {
 	return nil
 }
+
+// ExportStats implements storage.StatsExporter. It exports the number of items
+// stored for each tree.
+func (s *memProvider) ExportStats() {
+	for id, n := range s.ts.itemCounts() {
+		s.items.Set(float64(n), strconv.FormatInt(id, 10))
+	}
+}
@@ -15,9 +15,15 @@
 package memory
 
 import (
+	"context"
+	"strconv"
 	"testing"
 
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestMemoryStorageProvider(t *testing.T) {
@@ -65,3 +71,22 @@ func TestMemoryStorageProviderClose(t *testing.T) {
 		t.Fatalf("Failed to close the memory storage provider: %v", err)
 	}
 }
+
+func TestMemoryStorageProviderExportStats(t *testing.T) {
+	ctx := context.Background()
+	sp, err := storage.NewProvider("memory", nil)
+	if err != nil {
+		t.Fatalf("Got an unexpected error: %v", err)
+	}
+	tree, err := storage.CreateTree(ctx, sp.AdminStorage(), proto.Clone(stestonly.LogTree).(*trillian.Tree))
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	label := strconv.FormatInt(tree.TreeId, 10)
+	items := sp.(*memProvider).items.(*monitoring.InertFloat)
+
+	sp.(storage.StatsExporter).ExportStats()
+	if got := items.Value(label); got <= 0 {
+		t.Errorf("items = %v, want > 0", got)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/storagepb"
 	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/util/clock"
 	"google.golang.org/protobuf/proto"
 	"k8s.io/klog/v2"
 )
@@ -82,12 +83,22 @@ type TreeStorage struct {
 	// mu only protects access to the trees map.
 	mu    sync.RWMutex
 	trees map[int64]*tree
+
+	// timeSource provides the creation and update times of trees.
+	timeSource clock.TimeSource
 }
 
 // NewTreeStorage returns a new instance of the in-memory tree storage database.
 func NewTreeStorage() *TreeStorage {
+	return NewTreeStorageWithTimeSource(clock.System)
+}
+
+// NewTreeStorageWithTimeSource returns a new instance of the in-memory tree
+// storage database which timestamps trees using ts.
+func NewTreeStorageWithTimeSource(ts clock.TimeSource) *TreeStorage {
 	return &TreeStorage{
-		trees: make(map[int64]*tree),
+		trees:      make(map[int64]*tree),
+		timeSource: ts,
 	}
 }
 
@@ -98,6 +109,24 @@ func (m *TreeStorage) getTree(id int64) *tree {
 	return m.trees[id]
 }
 
+// itemCounts returns the number of items in the BTree of each tree.
+func (m *TreeStorage) itemCounts() map[int64]int {
+	m.mu.RLock()
+	trees := make(map[int64]*tree, len(m.trees))
+	for id, t := range m.trees {
+		trees[id] = t
+	}
+	m.mu.RUnlock()
+
+	counts := make(map[int64]int, len(trees))
+	for id, t := range trees {
+		t.RLock()
+		counts[id] = t.store.Len()
+		t.RUnlock()
+	}
+	return counts
+}
+
 // kv is a simple key->value type which implements btree's Item interface.
 type kv struct {
 	k string
@@ -123,6 +152,10 @@ func newTree(t *trillian.Tree) *tree {
 	k.(*kv).v = make(map[string][]int64)
 	ret.store.ReplaceOrInsert(k)
 
+	k = idToSeqKey(t.TreeId)
+	k.(*kv).v = make(map[string][]int64)
+	ret.store.ReplaceOrInsert(k)
+
 	return ret
 }
 
@@ -215,7 +248,10 @@ func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtr
 }
 
 func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
-	rev := t.writeRevision - 1
+	// Read the tiles as of the write revision, rather than the latest root, so
+	// that the nodes staged by earlier transactions of a split batch are kept.
+	// Otherwise nothing has been written at the write revision yet.
+	rev := t.writeRevision
 	return t.subtreeCache.SetNodes(nodes, t.getSubtreesAtRev(ctx, rev))
 }
 
@@ -288,6 +288,21 @@ func (mr *MockLogStorageMockRecorder) CheckDatabaseAccessible(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDatabaseAccessible", reflect.TypeOf((*MockLogStorage)(nil).CheckDatabaseAccessible), arg0)
 }
 
+// CountUnsequenced mocks base method.
+func (m *MockLogStorage) CountUnsequenced(arg0 context.Context, arg1 *trillian.Tree) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnsequenced", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnsequenced indicates an expected call of CountUnsequenced.
+func (mr *MockLogStorageMockRecorder) CountUnsequenced(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnsequenced", reflect.TypeOf((*MockLogStorage)(nil).CountUnsequenced), arg0, arg1)
+}
+
 // GetActiveLogIDs mocks base method.
 func (m *MockLogStorage) GetActiveLogIDs(arg0 context.Context) ([]int64, error) {
 	m.ctrl.T.Helper()
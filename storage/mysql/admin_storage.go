@@ -55,13 +55,14 @@ const (
 			PublicKey, -- Used to store StorageSettings
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			Options
 		FROM Trees`
 	selectNonDeletedTrees = selectTrees + nonDeletedWhere
 	selectTreeByID        = selectTrees + " WHERE TreeId = ?"
 
 	updateTreeSQL = `UPDATE Trees
-		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?
+		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, Options = ?
 		WHERE TreeId = ?`
 )
 
@@ -213,15 +214,33 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 
 	// Use the time truncated-to-millis throughout, as that's what's stored.
 	nowMillis := toMillisSinceEpoch(time.Now())
-	now := fromMillisSinceEpoch(nowMillis)
+	return t.insertTree(ctx, tree, id, nowMillis, nowMillis)
+}
+
+// ImportTree implements storage.TreeImporter.
+func (t *adminTX) ImportTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForImport(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+	newTree, err := t.insertTree(ctx, tree, tree.TreeId, toMillisSinceEpoch(tree.CreateTime.AsTime()), toMillisSinceEpoch(tree.UpdateTime.AsTime()))
+	if isDuplicateErr(err) {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %d already exists", tree.TreeId)
+	}
+	return newTree, err
+}
 
+// insertTree inserts a copy of tree with the given ID and timestamps.
+func (t *adminTX) insertTree(ctx context.Context, tree *trillian.Tree, id, createMillis, updateMillis int64) (*trillian.Tree, error) {
 	newTree := proto.Clone(tree).(*trillian.Tree)
 	newTree.TreeId = id
-	newTree.CreateTime = timestamppb.New(now)
+	newTree.CreateTime = timestamppb.New(fromMillisSinceEpoch(createMillis))
 	if err := newTree.CreateTime.CheckValid(); err != nil {
 		return nil, fmt.Errorf("failed to build create time: %w", err)
 	}
-	newTree.UpdateTime = timestamppb.New(now)
+	newTree.UpdateTime = timestamppb.New(fromMillisSinceEpoch(updateMillis))
 	if err := newTree.UpdateTime.CheckValid(); err != nil {
 		return nil, fmt.Errorf("failed to build update time: %w", err)
 	}
@@ -262,6 +281,10 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 	if err := enc.Encode(ss); err != nil {
 		return nil, fmt.Errorf("failed to encode storageSettings: %v", err)
 	}
+	options, err := storage.MarshalTreeOptions(newTree)
+	if err != nil {
+		return nil, err
+	}
 
 	insertTreeStmt, err := t.tx.PrepareContext(
 		ctx,
@@ -278,8 +301,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 			UpdateTimeMillis,
 			PrivateKey, -- Unused
 			PublicKey, -- Used to store StorageSettings
-			MaxRootDurationMillis)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+			MaxRootDurationMillis,
+			Options)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, err
 	}
@@ -299,11 +323,12 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		"ECDSA",          // Unused, filling in for backward compatibility.
 		newTree.DisplayName,
 		newTree.Description,
-		nowMillis,
-		nowMillis,
+		createMillis,
+		updateMillis,
 		[]byte{},     // PrivateKey: Unused, filling in for backward compatibility.
 		buff.Bytes(), // Using the otherwise unused PublicKey for storing StorageSettings.
 		rootDuration/time.Millisecond,
+		options,
 	)
 	if err != nil {
 		return nil, err
@@ -377,6 +402,10 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		return nil, fmt.Errorf("could not parse MaxRootDuration: %w", err)
 	}
 	rootDuration := tree.MaxRootDuration.AsDuration()
+	options, err := storage.MarshalTreeOptions(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
 	if err != nil {
@@ -400,6 +429,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		// PublicKey should not be updated with any storageSettings here without
 		// a lot of thought put into it. At the moment storageSettings are inferred
 		// when reading the tree, even if no value is stored in the database.
+		options,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
@@ -26,6 +26,8 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/mysql/mysqlpb"
 	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
@@ -63,6 +65,43 @@ func TestAdminTX_CreateTree_InitializesStorageStructures(t *testing.T) {
 	}
 }
 
+func TestAdminTX_ImportTree(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	tree, err := storage.CreateTree(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree() failed: %v", err)
+	}
+
+	frozen := proto.Clone(tree).(*trillian.Tree)
+	frozen.TreeId++
+	frozen.TreeState = trillian.TreeState_FROZEN
+	importTree := func(tree *trillian.Tree) (*trillian.Tree, error) {
+		var got *trillian.Tree
+		err := s.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+			var err error
+			got, err = tx.(storage.TreeImporter).ImportTree(ctx, tree)
+			return err
+		})
+		return got, err
+	}
+	if _, err := importTree(frozen); err != nil {
+		t.Fatalf("ImportTree() failed: %v", err)
+	}
+	got, err := storage.GetTree(ctx, s, frozen.TreeId)
+	if err != nil {
+		t.Fatalf("GetTree() failed: %v", err)
+	}
+	if !proto.Equal(got, frozen) {
+		t.Errorf("GetTree() = %v, want %v", got, frozen)
+	}
+	if _, err := importTree(tree); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("ImportTree() of an existing tree returned err = %v, want AlreadyExists", err)
+	}
+}
+
 func TestCreateTreeInvalidStates(t *testing.T) {
 	cleanTestDB(DB)
 	s := NewAdminStorage(DB)
@@ -1,8 +1,13 @@
 -- Caution - this removes all tables in our schema
 
+DROP TABLE IF EXISTS RecentSubmissions;
+DROP TABLE IF EXISTS UnsequencedCounts;
 DROP TABLE IF EXISTS Unsequenced;
 DROP TABLE IF EXISTS Subtree;
 DROP TABLE IF EXISTS SequencedLeafData;
+DROP TABLE IF EXISTS ExtraDataHistory;
+DROP TABLE IF EXISTS LeafRedactions;
+DROP TABLE IF EXISTS IntegrationEvents;
 DROP TABLE IF EXISTS TreeHead;
 DROP TABLE IF EXISTS LeafData;
 DROP TABLE IF EXISTS TreeControl;
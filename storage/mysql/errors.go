@@ -15,6 +15,8 @@
 package mysql
 
 import (
+	"errors"
+
 	"github.com/go-sql-driver/mysql"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -25,6 +27,10 @@ const (
 	errNumDuplicate = 1062
 	// ER_LOCK_DEADLOCK: Error returned when there was a deadlock.
 	errNumDeadlock = 1213
+	// ER_OPTION_PREVENTS_STATEMENT: Error returned when writing to a server
+	// which is running with --read-only, such as an Aurora writer which has
+	// been demoted by a failover.
+	errNumReadOnly = 1290
 )
 
 // mysqlToGRPC converts some types of MySQL errors to GRPC errors. This gives
@@ -37,9 +43,31 @@ func mysqlToGRPC(err error) error {
 	if mysqlErr.Number == errNumDeadlock {
 		return status.Errorf(codes.Aborted, "MySQL: %v", mysqlErr)
 	}
+	if mysqlErr.Number == errNumReadOnly {
+		return status.Errorf(codes.Unavailable, "MySQL: %v", mysqlErr)
+	}
 	return err
 }
 
+// isReadOnlyErr returns whether err is a write to a read-only server, which
+// can be retried once the connections reach the new writer.
+func isReadOnlyErr(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errNumReadOnly
+}
+
+// isDeadlockErr returns whether err is a deadlock, including one converted to
+// Aborted by mysqlToGRPC. A deadlock rolls back the whole transaction, which
+// can then be retried. Galera clusters also report transactions which fail
+// certification against one committed on another node as deadlocks.
+func isDeadlockErr(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == errNumDeadlock
+	}
+	return status.Code(err) == codes.Aborted
+}
+
 func isDuplicateErr(err error) bool {
 	switch err := err.(type) {
 	case *mysql.MySQLError:
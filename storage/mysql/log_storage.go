@@ -20,21 +20,26 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"github.com/transparency-dev/merkle/compact"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
@@ -45,6 +50,21 @@ const (
 	insertLeafDataSQL      = "INSERT INTO LeafData(TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos) VALUES" + valuesPlaceholder5
 	insertSequencedLeafSQL = "INSERT INTO SequencedLeafData(TreeId,LeafIdentityHash,MerkleLeafHash,SequenceNumber,IntegrateTimestampNanos) VALUES"
 
+	insertExtraDataHistorySQL = `INSERT INTO ExtraDataHistory(TreeId,LeafIdentityHash,UpdateTimestampNanos,SequenceNumber,PreviousExtraData,NewExtraData,Reason)
+			VALUES(?,?,?,?,?,?,?)`
+	updateLeafExtraDataSQL = "UPDATE LeafData SET ExtraData=? WHERE TreeId=? AND LeafIdentityHash=?"
+
+	insertLeafRedactionSQL = `INSERT INTO LeafRedactions(TreeId,LeafIdentityHash,RedactTimestampNanos,SequenceNumber,Reason)
+			VALUES(?,?,?,?,?)`
+	redactLeafValueSQL = "UPDATE LeafData SET LeafValue=? WHERE TreeId=? AND LeafIdentityHash=?"
+
+	insertIntegrationEventSQL = `INSERT INTO IntegrationEvents(TreeId,StartTimestampNanos,DurationNanos,BatchSize,TreeSize,RootHash,Signer)
+			VALUES(?,?,?,?,?,?,?)`
+	deleteIntegrationEventsSQL = "DELETE FROM IntegrationEvents WHERE TreeId=? AND StartTimestampNanos<?"
+	selectIntegrationEventsSQL = `SELECT StartTimestampNanos,DurationNanos,BatchSize,TreeSize,RootHash,Signer
+			FROM IntegrationEvents WHERE TreeId=? AND StartTimestampNanos<?
+			ORDER BY StartTimestampNanos DESC LIMIT ?`
+
 	selectNonDeletedTreeIDByTypeAndStateSQL = `
 		SELECT TreeId FROM Trees
 		  WHERE TreeType IN(?,?)
@@ -54,23 +74,54 @@ const (
 	selectLatestSignedLogRootSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature
 			FROM TreeHead WHERE TreeId=?
 			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
-
-	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
-			FROM LeafData l,SequencedLeafData s
-			WHERE l.LeafIdentityHash = s.LeafIdentityHash
-			AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId` + orderBySequenceNumberSQL
+	selectSignedLogRootByHashSQL = `SELECT TreeHeadTimestamp,TreeSize
+			FROM TreeHead WHERE TreeId=? AND RootHash=?
+			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
+	selectSignedLogRootsSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash
+			FROM TreeHead WHERE TreeId=?
+			AND TreeHeadTimestamp>=? AND TreeHeadTimestamp<? AND TreeSize>=? AND TreeSize<?
+			ORDER BY TreeHeadTimestamp LIMIT ?`
+
+	// redactedSQL is the last column of the leaf-selection statements, which
+	// is whether the leaf has been redacted.
+	redactedSQL = "EXISTS(SELECT 1 FROM LeafRedactions r WHERE r.TreeId = l.TreeId AND r.LeafIdentityHash = l.LeafIdentityHash)"
+
+	// selectLeavesByRangeSQL forces the join to be driven by an ordered range
+	// scan of the SequencedLeafData primary key, which covers all of the
+	// columns needed from it, followed by a primary key lookup in LeafData for
+	// each row. Without the hints the optimizer may instead drive the join from
+	// LeafData, doing a point lookup in SequencedLeafData for every leaf in the
+	// tree. The LIMIT stops the scan early if the range has gaps.
+	selectLeavesByRangeSQL = `SELECT STRAIGHT_JOIN s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,` + redactedSQL + `
+			FROM SequencedLeafData s FORCE INDEX (PRIMARY)
+			JOIN LeafData l ON l.TreeId = s.TreeId AND l.LeafIdentityHash = s.LeafIdentityHash
+			WHERE s.TreeId = ? AND s.SequenceNumber >= ? AND s.SequenceNumber < ?`
+	selectLeavesByRangeAscSQL  = selectLeavesByRangeSQL + orderBySequenceNumberSQL + " LIMIT ?"
+	selectLeavesByRangeDescSQL = selectLeavesByRangeSQL + orderBySequenceNumberSQL + " DESC LIMIT ?"
 
 	// These statements need to be expanded to provide the correct number of parameter placeholders.
-	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,` + redactedSQL + `
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
 			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
+	// selectLeavesByIndicesSQL needs to be expanded to provide the correct
+	// number of parameter placeholders.
+	selectLeavesByIndicesSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,` + redactedSQL + `
+			FROM SequencedLeafData s
+			JOIN LeafData l ON l.TreeId = s.TreeId AND l.LeafIdentityHash = s.LeafIdentityHash
+			WHERE s.SequenceNumber IN (` + placeholderSQL + `) AND s.TreeId = ?`
+	// selectSequencedLeavesByLeafIdentityHashSQL uses the index on
+	// SequencedLeafData(TreeId, LeafIdentityHash) which backs its foreign key.
+	selectSequencedLeavesByLeafIdentityHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,` + redactedSQL + `
+			FROM LeafData l,SequencedLeafData s
+			WHERE l.LeafIdentityHash = s.LeafIdentityHash
+			AND s.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
 	// TODO(#1548): rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
 	dummyMerkleLeafHash = "00000000000000000000000000000000"
 	// This statement returns a dummy Merkle leaf hash value (which must be
 	// of the right size) so that its signature matches that of the other
 	// leaf-selection statements.
-	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,` + redactedSQL + `
 			FROM LeafData l LEFT JOIN SequencedLeafData s ON (l.LeafIdentityHash = s.LeafIdentityHash AND l.TreeID = s.TreeID)
 			WHERE l.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ?`
 
@@ -78,6 +129,36 @@ const (
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
 	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
 
+	selectSequencedLeavesByLeafIdentityHashOrderedBySequenceSQL = selectSequencedLeavesByLeafIdentityHashSQL + orderBySequenceNumberSQL
+
+	selectSignedLogRootBySizeSQL = `SELECT TreeHeadTimestamp,RootHash
+			FROM TreeHead WHERE TreeId=? AND TreeSize=?
+			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
+
+	countUnsequencedSQL = "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=?"
+	// The MIN and MAX of a prefix of the primary key are each read with a
+	// single index lookup.
+	selectSequencedIndexRangeSQL = "SELECT COALESCE(MIN(SequenceNumber),-1),COALESCE(MAX(SequenceNumber),-1) FROM SequencedLeafData WHERE TreeId=?"
+
+	// Statements for the counts of Unsequenced rows, which are maintained if
+	// LogStorageOptions.MaintainUnsequencedCounts is set.
+	sumUnsequencedCountsSQL = "SELECT COALESCE(SUM(Count),0) FROM UnsequencedCounts WHERE TreeId=?"
+	addUnsequencedCountSQL  = `INSERT INTO UnsequencedCounts(TreeId,Shard,Count) VALUES(?,?,?)
+			ON DUPLICATE KEY UPDATE Count=Count+?`
+	// unsequencedCountShards is the number of rows the count of the
+	// Unsequenced rows of a tree is spread over.
+	unsequencedCountShards = 16
+
+	// Queue repair statements, see storage.QueueRepairer.
+	selectQueuedLeavesBeforeSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos
+			FROM Unsequenced
+			WHERE TreeId=? AND Bucket=0 AND QueueTimestampNanos<?
+			ORDER BY QueueTimestampNanos,LeafIdentityHash LIMIT ?`
+	selectQueuedMerkleLeafHashSQL = "SELECT MerkleLeafHash FROM Unsequenced WHERE TreeId=? AND Bucket=0 AND QueueTimestampNanos=? AND LeafIdentityHash=?"
+	deleteQueuedLeafSQL           = "DELETE FROM Unsequenced WHERE TreeId=? AND Bucket=0 AND QueueTimestampNanos=? AND LeafIdentityHash=?"
+	deleteUnsequencedLeafDataSQL  = `DELETE FROM LeafData WHERE TreeId=? AND LeafIdentityHash=?
+			AND NOT EXISTS(SELECT 1 FROM SequencedLeafData s WHERE s.TreeId=LeafData.TreeId AND s.LeafIdentityHash=LeafData.LeafIdentityHash)`
+
 	logIDLabel = "logid"
 )
 
@@ -86,6 +167,7 @@ var (
 	queuedCounter    monitoring.Counter
 	queuedDupCounter monitoring.Counter
 	dequeuedCounter  monitoring.Counter
+	deadlockCounter  monitoring.Counter
 
 	queueLatency            monitoring.Histogram
 	queueInsertLatency      monitoring.Histogram
@@ -101,6 +183,7 @@ func createMetrics(mf monitoring.MetricFactory) {
 	queuedCounter = mf.NewCounter("mysql_queued_leaves", "Number of leaves queued", logIDLabel)
 	queuedDupCounter = mf.NewCounter("mysql_queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
 	dequeuedCounter = mf.NewCounter("mysql_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+	deadlockCounter = mf.NewCounter("mysql_deadlock_retries", "Number of transactions retried after a deadlock", logIDLabel)
 
 	queueLatency = mf.NewHistogram("mysql_queue_leaves_latency", "Latency of queue leaves operation in seconds", logIDLabel)
 	queueInsertLatency = mf.NewHistogram("mysql_queue_leaves_latency_insert", "Latency of insertion part of queue leaves operation in seconds", logIDLabel)
@@ -125,35 +208,92 @@ type mySQLLogStorage struct {
 	*mySQLTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	opts          LogStorageOptions
+	// reader runs the snapshots if opts.ReaderDB is set.
+	reader *mySQLTreeStorage
+}
+
+// LogStorageOptions are optional behaviours of the MySQL log storage.
+type LogStorageOptions struct {
+	// MaintainUnsequencedCounts controls whether the UnsequencedCounts table
+	// is updated in the same transactions as the Unsequenced table, and used
+	// by CountUnsequenced instead of counting the rows of Unsequenced. All of
+	// the log servers and signers using a database must agree on it, and the
+	// counts must be initialised from the Unsequenced table when it's turned
+	// on for a database which isn't empty.
+	MaintainUnsequencedCounts bool
+
+	// DeadlockRetries is the number of times that a read-write transaction
+	// which fails with a deadlock is retried from the start. MariaDB Galera
+	// clusters need this, as they report transactions which conflict with one
+	// committed on another node as deadlocks, whether or not they waited for
+	// a lock.
+	DeadlockRetries int
+
+	// ReaderDB, if not nil, is the database on which SnapshotForTree runs
+	// read-only transactions, such as the reader endpoint of an Aurora
+	// cluster. It must be a replica of the database which the log storage
+	// writes to. The snapshots lag behind the writes by its replication lag.
+	ReaderDB *sql.DB
 }
 
 // NewLogStorage creates a storage.LogStorage instance for the specified MySQL URL.
 // It assumes storage.AdminStorage is backed by the same MySQL database as well.
 func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithOpts(db, mf, LogStorageOptions{})
+}
+
+// NewLogStorageWithOpts creates a storage.LogStorage instance like
+// NewLogStorage, with the given options.
+func NewLogStorageWithOpts(db *sql.DB, mf monitoring.MetricFactory, opts LogStorageOptions) storage.LogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
-	return &mySQLLogStorage{
+	m := &mySQLLogStorage{
 		admin:            NewAdminStorage(db),
 		mySQLTreeStorage: newTreeStorage(db),
 		metricFactory:    mf,
+		opts:             opts,
+	}
+	if opts.ReaderDB != nil {
+		m.reader = newTreeStorage(opts.ReaderDB)
 	}
+	return m
 }
 
 func (m *mySQLLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return m.db.PingContext(ctx)
 }
 
-func (m *mySQLLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence bool) (*sql.Stmt, error) {
+// CountUnsequenced implements storage.LogStorage.
+func (m *mySQLLogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	var count int64
+	if err := m.db.QueryRowContext(ctx, m.countUnsequencedSQL(), tree.TreeId).Scan(&count); err != nil {
+		return 0, mysqlToGRPC(err)
+	}
+	return count, nil
+}
+
+func leavesByMerkleHashQuery(num int, orderBySequence bool) string {
 	if orderBySequence {
-		return m.getStmt(ctx, selectLeavesByMerkleHashOrderedBySequenceSQL, num, "?", "?")
+		return expandPlaceholderSQL(selectLeavesByMerkleHashOrderedBySequenceSQL, num, "?", "?")
 	}
+	return expandPlaceholderSQL(selectLeavesByMerkleHashSQL, num, "?", "?")
+}
+
+func leavesByLeafIdentityHashQuery(num int) string {
+	return expandPlaceholderSQL(selectLeavesByLeafIdentityHashSQL, num, "?", "?")
+}
 
-	return m.getStmt(ctx, selectLeavesByMerkleHashSQL, num, "?", "?")
+func leavesByIndicesQuery(num int) string {
+	return expandPlaceholderSQL(selectLeavesByIndicesSQL, num, "?", "?")
 }
 
-func (m *mySQLLogStorage) getLeavesByLeafIdentityHashStmt(ctx context.Context, num int) (*sql.Stmt, error) {
-	return m.getStmt(ctx, selectLeavesByLeafIdentityHashSQL, num, "?", "?")
+func sequencedLeavesByLeafIdentityHashQuery(num int, orderBySequence bool) string {
+	if orderBySequence {
+		return expandPlaceholderSQL(selectSequencedLeavesByLeafIdentityHashOrderedBySequenceSQL, num, "?", "?")
+	}
+	return expandPlaceholderSQL(selectSequencedLeavesByLeafIdentityHashSQL, num, "?", "?")
 }
 
 func (m *mySQLLogStorage) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
@@ -183,12 +323,21 @@ func (m *mySQLLogStorage) GetActiveLogIDs(ctx context.Context) ([]int64, error)
 }
 
 func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
+	return m.beginInternalOn(ctx, m.mySQLTreeStorage, tree, nil)
+}
+
+// beginInternalOn begins a log transaction on the database of ts.
+func (m *mySQLLogStorage) beginInternalOn(ctx context.Context, ts *mySQLTreeStorage, tree *trillian.Tree, opts *sql.TxOptions) (*logTreeTX, error) {
 	once.Do(func() {
 		createMetrics(m.metricFactory)
 	})
 
-	stCache := cache.NewLogSubtreeCache(rfc6962.DefaultHasher)
-	ttx, err := m.beginTreeTx(ctx, tree, rfc6962.DefaultHasher.Size(), stCache)
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	stCache := cache.NewLogSubtreeCache(hasher)
+	ttx, err := ts.beginTreeTx(ctx, tree, hasher.Size(), stCache, opts)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -225,22 +374,62 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree
 // if the transaction is rolled back as a result of a canceled context. It must
 // return "generic" errors, and only log the specific ones for debugging.
 func (m *mySQLLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
-	tx, err := m.beginInternal(ctx, tree)
-	if err != nil && err != storage.ErrTreeNeedsInit {
-		return err
+	return m.retryDeadlocks(ctx, tree, func() error {
+		tx, err := m.beginInternal(ctx, tree)
+		if err != nil && err != storage.ErrTreeNeedsInit {
+			return err
+		}
+		defer func() {
+			if err := tx.Close(); err != nil {
+				klog.Errorf("tx.Close(): %v", err)
+			}
+		}()
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+}
+
+// retryDeadlocks calls f, which runs a transaction on tree, until it succeeds,
+// fails other than with a deadlock, or has been retried DeadlockRetries times.
+// A failure to write to a read-only database is returned as Unavailable.
+func (m *mySQLLogStorage) retryDeadlocks(ctx context.Context, tree *trillian.Tree, f func() error) error {
+	b := backoff.Backoff{
+		Min:    10 * time.Millisecond,
+		Max:    time.Second,
+		Factor: 2,
+		Jitter: true,
 	}
-	defer func() {
-		if err := tx.Close(); err != nil {
-			klog.Errorf("tx.Close(): %v", err)
+	for retries := 0; ; retries++ {
+		err := f()
+		if isReadOnlyErr(err) {
+			return status.Errorf(codes.Unavailable, "%d: database is read-only: %v", tree.TreeId, err)
+		}
+		if err == nil || retries >= m.opts.DeadlockRetries || !isDeadlockErr(err) {
+			return err
+		}
+		deadlockCounter.Inc(strconv.FormatInt(tree.TreeId, 10))
+		klog.V(1).Infof("%d: retrying transaction after deadlock: %v", tree.TreeId, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(b.Duration()):
 		}
-	}()
-	if err := f(ctx, tx); err != nil {
-		return err
 	}
-	return tx.Commit(ctx)
 }
 
 func (m *mySQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	var res []*trillian.QueuedLogLeaf
+	err := m.retryDeadlocks(ctx, tree, func() error {
+		var err error
+		res, err = m.addSequencedLeaves(ctx, tree, leaves, timestamp)
+		return err
+	})
+	return res, err
+}
+
+func (m *mySQLLogStorage) addSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
 	tx, err := m.beginInternal(ctx, tree)
 	if tx != nil {
 		// Ensure we don't leak the transaction. For example if we get an
@@ -266,7 +455,13 @@ func (m *mySQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian
 }
 
 func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
-	tx, err := m.beginInternal(ctx, tree)
+	var tx *logTreeTX
+	var err error
+	if m.reader != nil {
+		tx, err = m.beginInternalOn(ctx, m.reader, tree, &sql.TxOptions{ReadOnly: true})
+	} else {
+		tx, err = m.beginInternal(ctx, tree)
+	}
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -274,6 +469,16 @@ func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tr
 }
 
 func (m *mySQLLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	var ret []*trillian.QueuedLogLeaf
+	err := m.retryDeadlocks(ctx, tree, func() error {
+		var err error
+		ret, err = m.queueLeaves(ctx, tree, leaves, queueTimestamp)
+		return err
+	})
+	return ret, err
+}
+
+func (m *mySQLLogStorage) queueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
 	tx, err := m.beginInternal(ctx, tree)
 	if tx != nil {
 		// Ensure we don't leak the transaction. For example if we get an
@@ -327,6 +532,16 @@ func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([
 	return t.subtreeCache.GetNodes(ids, t.getSubtreesAtRev(ctx, t.readRev))
 }
 
+// ReadSubtrees implements storage.SubtreeReader.
+func (t *logTreeTX) ReadSubtrees(ctx context.Context, ids [][]byte, revision int64) ([]*storagepb.SubtreeProto, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if revision < 0 {
+		revision = t.readRev
+	}
+	return t.getSubtrees(ctx, revision, ids)
+}
+
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -334,7 +549,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	if t.treeType == trillian.TreeType_PREORDERED_LOG {
 		// TODO(pavelkalinnikov): Optimize this by fetching only the required
 		// fields of LogLeaf. We can avoid joining with LeafData table here.
-		return t.getLeavesByRangeInternal(ctx, int64(t.root.TreeSize), int64(limit))
+		return t.getLeavesByRangeInternal(ctx, int64(t.root.TreeSize), int64(limit), false)
 	}
 
 	start := time.Now()
@@ -468,6 +683,9 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		leafDuration := time.Since(leafStart)
 		observe(queueInsertEntryLatency, (leafDuration - insertDuration), label)
 	}
+	if err := t.addUnsequencedCount(ctx, int64(len(leaves)-existingCount)); err != nil {
+		return nil, err
+	}
 	insertDuration := time.Since(start)
 	observe(queueInsertLatency, insertDuration, label)
 	queuedCounter.Add(float64(len(leaves)), label)
@@ -602,13 +820,61 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	return res, nil
 }
 
+// WriteSequencedLeaves implements storage.SequencedLeafWriter.
+func (t *logTreeTX) WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		if err := leaf.IntegrateTimestamp.CheckValid(); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "leaves[%d] has invalid integrate timestamp: %v", i, err)
+		}
+		// The LeafData row may already exist if the same leaf was queued here.
+		_, err := t.tx.ExecContext(ctx, insertLeafDataSQL,
+			t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, leaf.QueueTimestamp.AsTime().UnixNano())
+		if err != nil && !isDuplicateErr(err) {
+			klog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
+			return mysqlToGRPC(err)
+		}
+		_, err = t.tx.ExecContext(ctx, insertSequencedLeafSQL+valuesPlaceholder5,
+			t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.LeafIndex, leaf.IntegrateTimestamp.AsTime().UnixNano())
+		if isDuplicateErr(err) {
+			return status.Errorf(codes.AlreadyExists, "leaf index %d already has a leaf", leaf.LeafIndex)
+		} else if err != nil {
+			klog.Errorf("Error inserting leaves[%d] into SequencedLeafData: %s", i, err)
+			return mysqlToGRPC(err)
+		}
+	}
+	return nil
+}
+
 func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.getLeavesByRangeInternal(ctx, start, count)
+	return t.getLeavesByRangeInternal(ctx, start, count, false)
+}
+
+// DescendingRangeReader is implemented by the transactions returned by the
+// MySQL LogStorage. Callers can type-assert a storage.ReadOnlyLogTreeTX to it
+// in order to read leaves in descending index order, for example to page
+// backwards from the end of a log, using a backward scan of the same index.
+type DescendingRangeReader interface {
+	// GetLeavesByRangeDescending returns the integrated leaves with indices in
+	// [start, start+count), in descending order of index.
+	GetLeavesByRangeDescending(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error)
 }
 
-func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+// GetLeavesByRangeDescending implements DescendingRangeReader.
+func (t *logTreeTX) GetLeavesByRangeDescending(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.getLeavesByRangeInternal(ctx, start, count, true)
+}
+
+func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count int64, descending bool) ([]*trillian.LogLeaf, error) {
 	if count <= 0 {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
 	}
@@ -616,7 +882,9 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
 	}
 
-	if t.treeType == trillian.TreeType_LOG {
+	// A descending scan must know where the range ends, so it is limited to
+	// integrated leaves for all tree types.
+	if t.treeType == trillian.TreeType_LOG || descending {
 		treeSize := int64(t.root.TreeSize)
 		if treeSize <= 0 {
 			return nil, status.Errorf(codes.OutOfRange, "empty tree")
@@ -629,9 +897,32 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		}
 	}
 	// TODO(pavelkalinnikov): Further clip `count` to a safe upper bound like 64k.
+	return t.scanLeavesByRange(ctx, start, count, descending)
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader.
+func (t *logTreeTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if treeSize := int64(t.root.TreeSize); start < treeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= TreeSize(%d)", start, treeSize)
+	}
+	return t.scanLeavesByRange(ctx, start, count, false)
+}
 
-	args := []interface{}{start, start + count, t.treeID}
-	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, args...)
+// scanLeavesByRange reads the leaves with consecutive indices in
+// [start, start+count), stopping at the first missing index beyond the tree
+// size.
+func (t *logTreeTX) scanLeavesByRange(ctx context.Context, start, count int64, descending bool) ([]*trillian.LogLeaf, error) {
+	query, step, wantIndex := selectLeavesByRangeAscSQL, int64(1), start
+	if descending {
+		query, step, wantIndex = selectLeavesByRangeDescSQL, -1, start+count-1
+	}
+	rows, err := t.tx.QueryContext(ctx, query, t.treeID, start, start+count, count)
 	if err != nil {
 		klog.Warningf("Failed to get leaves by range: %s", err)
 		return nil, err
@@ -643,7 +934,7 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 	}()
 
 	ret := make([]*trillian.LogLeaf, 0, count)
-	for wantIndex := start; rows.Next(); wantIndex++ {
+	for ; rows.Next(); wantIndex += step {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
 		if err := rows.Scan(
@@ -653,7 +944,8 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 			&leaf.LeafIndex,
 			&leaf.ExtraData,
 			&qTimestamp,
-			&iTimestamp); err != nil {
+			&iTimestamp,
+			&leaf.Redacted); err != nil {
 			klog.Warningf("Failed to scan merkle leaves: %s", err)
 			return nil, err
 		}
@@ -681,27 +973,292 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 	return ret, nil
 }
 
-func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+// UpdateLeafExtraData implements storage.ExtraDataUpdater. ExtraData is
+// stored per LeafIdentityHash, so the change applies to every leaf in the tree
+// with the same identity hash as the leaf at index.
+func (t *logTreeTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	tmpl, err := t.ls.getLeavesByMerkleHashStmt(ctx, len(leafHashes), orderBySequence)
+	leaves, err := t.getLeavesByRangeInternal(ctx, index, 1, false)
 	if err != nil {
 		return nil, err
 	}
+	if len(leaves) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no leaf at index %d", index)
+	}
+	leaf := leaves[0]
+
+	if _, err := t.tx.ExecContext(ctx, insertExtraDataHistorySQL,
+		t.treeID, leaf.LeafIdentityHash, timestamp.UnixNano(), index, leaf.ExtraData, extraData, reason); err != nil {
+		klog.Warningf("Failed to record ExtraData change: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	if _, err := t.tx.ExecContext(ctx, updateLeafExtraDataSQL, extraData, t.treeID, leaf.LeafIdentityHash); err != nil {
+		klog.Warningf("Failed to update ExtraData: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	leaf.ExtraData = extraData
+	return leaf, nil
+}
+
+// RedactLeaf implements storage.LeafRedactor. LeafValue is stored per
+// LeafIdentityHash, so the redaction applies to every leaf in the tree with
+// the same identity hash as the leaf at index.
+func (t *logTreeTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	leaves, err := t.getLeavesByRangeInternal(ctx, index, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(leaves) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no leaf at index %d", index)
+	}
+	leaf := leaves[0]
+	if leaf.Redacted {
+		return nil, status.Errorf(codes.AlreadyExists, "leaf %d is already redacted", index)
+	}
+
+	if _, err := t.tx.ExecContext(ctx, insertLeafRedactionSQL,
+		t.treeID, leaf.LeafIdentityHash, timestamp.UnixNano(), index, reason); err != nil {
+		klog.Warningf("Failed to record redaction: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	if _, err := t.tx.ExecContext(ctx, redactLeafValueSQL, []byte{}, t.treeID, leaf.LeafIdentityHash); err != nil {
+		klog.Warningf("Failed to redact LeafValue: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	leaf.LeafValue = nil
+	leaf.Redacted = true
+	return leaf, nil
+}
+
+// ListQueuedLeaves implements storage.QueueRepairer.
+func (t *logTreeTX) ListQueuedLeaves(ctx context.Context, before time.Time, limit int) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rows, err := t.tx.QueryContext(ctx, selectQueuedLeavesBeforeSQL, t.treeID, before.UnixNano(), limit)
+	if err != nil {
+		klog.Warningf("Failed to select queued leaves: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Errorf("rows.Close(): %v", err)
+		}
+	}()
+
+	var leaves []*trillian.LogLeaf
+	for rows.Next() {
+		var leaf trillian.LogLeaf
+		var queueTimestamp int64
+		if err := rows.Scan(&leaf.LeafIdentityHash, &leaf.MerkleLeafHash, &queueTimestamp); err != nil {
+			klog.Warningf("Error scanning queued leaf: %s", err)
+			return nil, err
+		}
+		leaf.QueueTimestamp = timestamppb.New(time.Unix(0, queueTimestamp))
+		leaves = append(leaves, &leaf)
+	}
+	return leaves, rows.Err()
+}
+
+// RequeueLeaves implements storage.QueueRepairer.
+func (t *logTreeTX) RequeueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, leaf := range leaves {
+		var merkleLeafHash []byte
+		if err := t.tx.QueryRowContext(ctx, selectQueuedMerkleLeafHashSQL,
+			t.treeID, leaf.QueueTimestamp.AsTime().UnixNano(), leaf.LeafIdentityHash).Scan(&merkleLeafHash); err == sql.ErrNoRows {
+			return status.Errorf(codes.NotFound, "leaf %x queued at %v not found", leaf.LeafIdentityHash, leaf.QueueTimestamp.AsTime())
+		} else if err != nil {
+			return mysqlToGRPC(err)
+		}
+		if err := t.deleteQueuedLeaf(ctx, leaf); err != nil {
+			return err
+		}
+		args := []interface{}{t.treeID, leaf.LeafIdentityHash, merkleLeafHash}
+		args = append(args, queueArgs(t.treeID, leaf.LeafIdentityHash, queueTimestamp)...)
+		if _, err := t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, args...); err != nil {
+			klog.Warningf("Error requeueing leaf: %s", err)
+			return mysqlToGRPC(err)
+		}
+	}
+	return nil
+}
+
+// DiscardQueuedLeaves implements storage.QueueRepairer. The LeafData of a
+// discarded leaf is kept if the leaf has also been sequenced.
+func (t *logTreeTX) DiscardQueuedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, leaf := range leaves {
+		if err := t.deleteQueuedLeaf(ctx, leaf); err != nil {
+			return err
+		}
+		if _, err := t.tx.ExecContext(ctx, deleteUnsequencedLeafDataSQL, t.treeID, leaf.LeafIdentityHash); err != nil {
+			klog.Warningf("Error deleting LeafData of discarded leaf: %s", err)
+			return mysqlToGRPC(err)
+		}
+	}
+	return t.addUnsequencedCount(ctx, -int64(len(leaves)))
+}
+
+// deleteQueuedLeaf removes the leaf from the Unsequenced table, or returns a
+// NotFound error if it isn't there. It must be called with mu held.
+func (t *logTreeTX) deleteQueuedLeaf(ctx context.Context, leaf *trillian.LogLeaf) error {
+	res, err := t.tx.ExecContext(ctx, deleteQueuedLeafSQL, t.treeID, leaf.QueueTimestamp.AsTime().UnixNano(), leaf.LeafIdentityHash)
+	if err != nil {
+		klog.Warningf("Error deleting queued leaf: %s", err)
+		return mysqlToGRPC(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return mysqlToGRPC(err)
+	} else if n == 0 {
+		return status.Errorf(codes.NotFound, "leaf %x queued at %v not found", leaf.LeafIdentityHash, leaf.QueueTimestamp.AsTime())
+	}
+	return nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.getLeavesByHashInternal(ctx, leafHashes, leavesByMerkleHashQuery(len(leafHashes), orderBySequence), "merkle")
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader.
+func (t *logTreeTX) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	beforeNanos := int64(math.MaxInt64)
+	if !before.IsZero() {
+		beforeNanos = before.UnixNano()
+	}
+	rows, err := t.tx.QueryContext(ctx, selectIntegrationEventsSQL, t.treeID, beforeNanos, limit)
+	if err != nil {
+		return nil, mysqlToGRPC(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Errorf("rows.Close(): %v", err)
+		}
+	}()
+
+	var ret []*trillian.IntegrationEvent
+	for rows.Next() {
+		var startNanos, durationNanos int64
+		event := &trillian.IntegrationEvent{}
+		if err := rows.Scan(&startNanos, &durationNanos, &event.BatchSize, &event.TreeSize, &event.RootHash, &event.Signer); err != nil {
+			return nil, err
+		}
+		event.StartTime = timestamppb.New(time.Unix(0, startNanos))
+		event.Duration = durationpb.New(time.Duration(durationNanos))
+		ret = append(ret, event)
+	}
+	return ret, rows.Err()
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter.
+func (t *logTreeTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.tx.ExecContext(ctx, deleteIntegrationEventsSQL, t.treeID, cutoff.UnixNano()); err != nil {
+		return mysqlToGRPC(err)
+	}
+	if _, err := t.tx.ExecContext(ctx, insertIntegrationEventSQL,
+		t.treeID,
+		event.GetStartTime().AsTime().UnixNano(),
+		event.GetDuration().AsDuration().Nanoseconds(),
+		event.BatchSize,
+		event.TreeSize,
+		event.RootHash,
+		event.Signer); err != nil {
+		return mysqlToGRPC(err)
+	}
+	return nil
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *logTreeTX) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	args := make([]interface{}, 0, len(indices)+1)
+	for _, index := range indices {
+		args = append(args, index)
+	}
+	args = append(args, t.treeID)
+	return t.queryLeaves(ctx, leavesByIndicesQuery(len(indices)), args, "index")
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter.
+func (t *logTreeTX) CountUnsequenced(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var count int64
+	if err := t.tx.QueryRowContext(ctx, t.ls.countUnsequencedSQL(), t.treeID).Scan(&count); err != nil {
+		return 0, mysqlToGRPC(err)
+	}
+	return count, nil
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader.
+func (t *logTreeTX) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var first, last int64
+	if err := t.tx.QueryRowContext(ctx, selectSequencedIndexRangeSQL, t.treeID).Scan(&first, &last); err != nil {
+		return 0, 0, mysqlToGRPC(err)
+	}
+	return first, last, nil
+}
+
+// countUnsequencedSQL returns the statement counting the Unsequenced rows of
+// a tree.
+func (m *mySQLLogStorage) countUnsequencedSQL() string {
+	if m.opts.MaintainUnsequencedCounts {
+		return sumUnsequencedCountsSQL
+	}
+	return countUnsequencedSQL
+}
+
+// addUnsequencedCount adds delta to the count of the Unsequenced rows of the
+// tree, if the counts are maintained.
+func (t *logTreeTX) addUnsequencedCount(ctx context.Context, delta int64) error {
+	if !t.ls.opts.MaintainUnsequencedCounts || delta == 0 {
+		return nil
+	}
+	shard := rand.Intn(unsequencedCountShards)
+	if _, err := t.tx.ExecContext(ctx, addUnsequencedCountSQL, t.treeID, shard, delta, delta); err != nil {
+		klog.Warningf("Error updating UnsequencedCounts: %s", err)
+		return mysqlToGRPC(err)
+	}
+	return nil
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader.
+func (t *logTreeTX) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	return t.getLeavesByHashInternal(ctx, leafHashes, tmpl, "merkle")
+	return t.getLeavesByHashInternal(ctx, identityHashes, sequencedLeavesByLeafIdentityHashQuery(len(identityHashes), orderBySequence), "sequenced-leaf-identity")
 }
 
 // getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
 // as a slice of LogLeaf objects for convenience.  However, note that the
 // returned LogLeaf objects will not have a valid MerkleLeafHash, LeafIndex, or IntegrateTimestamp.
 func (t *logTreeTX) getLeafDataByIdentityHash(ctx context.Context, leafHashes [][]byte) ([]*trillian.LogLeaf, error) {
-	tmpl, err := t.ls.getLeavesByLeafIdentityHashStmt(ctx, len(leafHashes))
-	if err != nil {
-		return nil, err
-	}
-	return t.getLeavesByHashInternal(ctx, leafHashes, tmpl, "leaf-identity")
+	return t.getLeavesByHashInternal(ctx, leafHashes, leavesByLeafIdentityHashQuery(len(leafHashes)), "leaf-identity")
 }
 
 func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
@@ -740,6 +1297,108 @@ func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoo
 	return &trillian.SignedLogRoot{LogRoot: logRoot}, treeRevision, nil
 }
 
+// GetSignedLogRootBySize implements storage.RootHistoryReader. TreeHead isn't
+// indexed by TreeSize, so this scans the roots of the tree.
+func (t *logTreeTX) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var timestamp int64
+	var rootHash []byte
+	if err := t.tx.QueryRowContext(ctx, selectSignedLogRootBySizeSQL, t.treeID, treeSize).Scan(&timestamp, &rootHash); err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no root of size %d", treeSize)
+	} else if err != nil {
+		return nil, mysqlToGRPC(err)
+	}
+	logRoot, err := (&types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		TreeSize:       treeSize,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader. TreeHead isn't
+// indexed by RootHash, so this scans the roots of the tree.
+func (t *logTreeTX) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var timestamp, treeSize int64
+	if err := t.tx.QueryRowContext(ctx, selectSignedLogRootByHashSQL, t.treeID, rootHash).Scan(&timestamp, &treeSize); err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no root with hash %x", rootHash)
+	} else if err != nil {
+		return nil, mysqlToGRPC(err)
+	}
+	logRoot, err := (&types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		TreeSize:       uint64(treeSize),
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader. The roots are read
+// in order of the primary key of TreeHead, and filtered by TreeSize.
+func (t *logTreeTX) ListSignedLogRoots(ctx context.Context, r storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	args := append([]interface{}{t.treeID}, rootRangeArgs(r, limit)...)
+	rows, err := t.tx.QueryContext(ctx, selectSignedLogRootsSQL, args...)
+	if err != nil {
+		return nil, mysqlToGRPC(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Errorf("rows.Close(): %v", err)
+		}
+	}()
+
+	var ret []*trillian.SignedLogRoot
+	for rows.Next() {
+		var timestamp, treeSize int64
+		var rootHash []byte
+		if err := rows.Scan(&timestamp, &treeSize, &rootHash); err != nil {
+			return nil, err
+		}
+		logRoot, err := (&types.LogRootV1{
+			RootHash:       rootHash,
+			TimestampNanos: uint64(timestamp),
+			TreeSize:       uint64(treeSize),
+		}).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}
+	return ret, rows.Err()
+}
+
+// rootRangeArgs returns the bounds of r, and the limit, as the arguments of
+// selectSignedLogRootsSQL which follow the tree ID.
+func rootRangeArgs(r storage.RootRange, limit int) []interface{} {
+	start := func(v uint64) int64 {
+		if v > math.MaxInt64 {
+			return math.MaxInt64
+		}
+		return int64(v)
+	}
+	end := func(v uint64) int64 {
+		if v == 0 {
+			return math.MaxInt64
+		}
+		return start(v)
+	}
+	return []interface{}{start(r.StartTimestampNanos), end(r.EndTimestampNanos), start(r.StartTreeSize), end(r.EndTreeSize), limit}
+}
+
 func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -769,22 +1428,20 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 	return checkResultOkAndRowCountIs(res, err, 1)
 }
 
-func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string) ([]*trillian.LogLeaf, error) {
-	stx := t.tx.StmtContext(ctx, tmpl)
-	defer func() {
-		if err := stx.Close(); err != nil {
-			klog.Errorf("stx.Close(): %v", err)
-		}
-	}()
-
+func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, query string, desc string) ([]*trillian.LogLeaf, error) {
 	var args []interface{}
 	for _, hash := range leafHashes {
 		args = append(args, []byte(hash))
 	}
 	args = append(args, t.treeID)
-	rows, err := stx.QueryContext(ctx, args...)
+	return t.queryLeaves(ctx, query, args, desc)
+}
+
+// queryLeaves runs query, a leaf-selection statement, with args.
+func (t *logTreeTX) queryLeaves(ctx context.Context, query string, args []interface{}, desc string) ([]*trillian.LogLeaf, error) {
+	rows, err := t.tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		klog.Warningf("Query() %s hash = %v", desc, err)
+		klog.Warningf("Query() %s = %v", desc, err)
 		return nil, err
 	}
 	defer func() {
@@ -805,7 +1462,7 @@ func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]
 		var integrateTS sql.NullInt64
 		var queueTS int64
 
-		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS); err != nil {
+		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS, &leaf.Redacted); err != nil {
 			klog.Warningf("LogID: %d Scan() %s = %s", t.treeID, desc, err)
 			return nil, err
 		}
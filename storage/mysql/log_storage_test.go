@@ -19,8 +19,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,14 +32,17 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
-var allTables = []string{"Unsequenced", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees"}
+var allTables = []string{"Unsequenced", "UnsequencedCounts", "TreeHead", "SequencedLeafData", "ExtraDataHistory", "LeafRedactions", "LeafData", "Subtree", "TreeControl", "Trees"}
 
 // Must be 32 bytes to match sha256 length if it was a real hash
 var (
@@ -127,6 +132,15 @@ func TestLogSuite(t *testing.T) {
 	storagetest.RunLogStorageTests(t, storageFactory)
 }
 
+func TestCrashConsistency(t *testing.T) {
+	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		t.Cleanup(func() { cleanTestDB(DB) })
+		return NewLogStorage(DB, nil), NewAdminStorage(DB)
+	}
+
+	storagetest.RunCrashConsistencyTests(t, storageFactory)
+}
+
 func TestQueueDuplicateLeaf(t *testing.T) {
 	ctx := context.Background()
 	cleanTestDB(DB)
@@ -220,6 +234,19 @@ func TestQueueLeaves(t *testing.T) {
 	if leavesToInsert != count {
 		t.Fatalf("Expected %d unsequenced rows but got: %d", leavesToInsert, count)
 	}
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err := tx.(storage.UnsequencedCounter).CountUnsequenced(ctx)
+		if err != nil {
+			t.Fatalf("CountUnsequenced(): %v", err)
+		}
+		if got != leavesToInsert {
+			t.Errorf("CountUnsequenced()=%d, want %d", got, leavesToInsert)
+		}
+		return nil
+	})
+	if got, err := s.CountUnsequenced(ctx, tree); err != nil || got != leavesToInsert {
+		t.Errorf("LogStorage.CountUnsequenced()=%d, %v, want %d", got, err, leavesToInsert)
+	}
 
 	// Additional check on timestamp being set correctly in the database
 	var queueTimestamp int64
@@ -452,6 +479,41 @@ func TestGetLeavesByHash(t *testing.T) {
 	})
 }
 
+func TestGetLeavesByIdentityHash(t *testing.T) {
+	ctx := context.Background()
+
+	// Create fake leaf as if it had been sequenced
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	data := []byte("some data")
+	createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, data, someExtraData, sequenceNumber, t)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		r := tx.(storage.IdentityHashReader)
+		leaves, err := r.GetLeavesByIdentityHash(ctx, [][]byte{dummyRawHash}, true)
+		if err != nil {
+			t.Fatalf("Unexpected error getting leaf by identity hash: %v", err)
+		}
+		if len(leaves) != 1 {
+			t.Fatalf("Got %d leaves but expected one", len(leaves))
+		}
+		checkLeafContents(leaves[0], sequenceNumber, dummyRawHash, dummyHash, data, someExtraData, t)
+
+		// The Merkle leaf hash is not an identity hash.
+		leaves, err = r.GetLeavesByIdentityHash(ctx, [][]byte{dummyHash}, false)
+		if err != nil {
+			t.Fatalf("Unexpected error getting leaf by identity hash: %v", err)
+		}
+		if len(leaves) != 0 {
+			t.Errorf("Got %d leaves but expected none", len(leaves))
+		}
+		return nil
+	})
+}
+
 func TestGetLeavesByHashBigBatch(t *testing.T) {
 	t.Skip("Known Issue: https://github.com/google/trillian/issues/1845")
 	ctx := context.Background()
@@ -539,6 +601,453 @@ func TestGetLeafDataByIdentityHash(t *testing.T) {
 	}
 }
 
+func TestGetLeavesByRangeDescending(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	const leafCount = 10
+	for i := int64(0); i < leafCount; i++ {
+		data := []byte(fmt.Sprintf("data %d", i))
+		hash := sha256.Sum256(data)
+		createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+	}
+	mustSignAndStoreLogRoot(ctx, t, s, tree, leafCount)
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer func() { _ = tx.Close() }()
+
+	// The range is clipped to the tree size.
+	leaves, err := tx.(DescendingRangeReader).GetLeavesByRangeDescending(ctx, 5, 10)
+	if err != nil {
+		t.Fatalf("GetLeavesByRangeDescending(): %v", err)
+	}
+	var got []int64
+	for _, leaf := range leaves {
+		got = append(got, leaf.LeafIndex)
+	}
+	if want := []int64{9, 8, 7, 6, 5}; !cmp.Equal(got, want) {
+		t.Errorf("GetLeavesByRangeDescending() returned indices %v, want %v", got, want)
+	}
+}
+
+func TestUpdateLeafExtraData(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	data := []byte("data")
+	hash := sha256.Sum256(data)
+	createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, 0, t)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 1)
+
+	newExtraData := []byte("new extra data")
+	updated := time.Unix(1700000000, 0)
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaf, err := tx.(storage.ExtraDataUpdater).UpdateLeafExtraData(ctx, 0, newExtraData, "test", updated)
+		if err != nil {
+			t.Fatalf("UpdateLeafExtraData(): %v", err)
+		}
+		checkLeafContents(leaf, 0, hash[:], hash[:], data, newExtraData, t)
+		if _, err := tx.(storage.ExtraDataUpdater).UpdateLeafExtraData(ctx, 1, newExtraData, "test", updated); status.Code(err) != codes.OutOfRange {
+			t.Errorf("UpdateLeafExtraData(1)=%v, want code %v", err, codes.OutOfRange)
+		}
+		return nil
+	})
+
+	var previous []byte
+	var reason string
+	if err := DB.QueryRowContext(ctx, "SELECT PreviousExtraData,Reason FROM ExtraDataHistory WHERE TreeId=? AND UpdateTimestampNanos=?",
+		tree.TreeId, updated.UnixNano()).Scan(&previous, &reason); err != nil {
+		t.Fatalf("Failed to read ExtraDataHistory: %v", err)
+	}
+	if !bytes.Equal(previous, someExtraData) || reason != "test" {
+		t.Errorf("ExtraDataHistory has %q, %q; want %q, %q", previous, reason, someExtraData, "test")
+	}
+}
+
+func TestRedactLeaf(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	data := []byte("data")
+	hash := sha256.Sum256(data)
+	createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, 0, t)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 1)
+
+	redacted := time.Unix(1700000000, 0)
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaf, err := tx.(storage.LeafRedactor).RedactLeaf(ctx, 0, "takedown", redacted)
+		if err != nil {
+			t.Fatalf("RedactLeaf(): %v", err)
+		}
+		if !leaf.Redacted || len(leaf.LeafValue) != 0 {
+			t.Errorf("RedactLeaf() returned leaf with Redacted=%v, LeafValue=%q; want true, empty", leaf.Redacted, leaf.LeafValue)
+		}
+		if _, err := tx.(storage.LeafRedactor).RedactLeaf(ctx, 0, "again", redacted); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("RedactLeaf() of redacted leaf=%v, want code %v", err, codes.AlreadyExists)
+		}
+		return nil
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.GetLeavesByHash(ctx, [][]byte{hash[:]}, false)
+		if err != nil {
+			t.Fatalf("GetLeavesByHash(): %v", err)
+		}
+		if len(leaves) != 1 || !leaves[0].Redacted || len(leaves[0].LeafValue) != 0 || !bytes.Equal(leaves[0].MerkleLeafHash, hash[:]) {
+			t.Errorf("GetLeavesByHash() after redaction = %v, want one redacted leaf with the original hash", leaves)
+		}
+		return nil
+	})
+
+	var reason string
+	if err := DB.QueryRowContext(ctx, "SELECT Reason FROM LeafRedactions WHERE TreeId=? AND RedactTimestampNanos=?",
+		tree.TreeId, redacted.UnixNano()).Scan(&reason); err != nil {
+		t.Fatalf("Failed to read LeafRedactions: %v", err)
+	}
+	if reason != "takedown" {
+		t.Errorf("LeafRedactions has reason %q, want %q", reason, "takedown")
+	}
+}
+
+func TestQueueRepairer(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	leaves := createTestLeaves(3, 0)
+	if _, err := s.QueueLeaves(ctx, tree, leaves, fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	requeued := fakeQueueTime.Add(time.Hour)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		qr := tx.(storage.QueueRepairer)
+		stuck, err := qr.ListQueuedLeaves(ctx, fakeQueueTime.Add(time.Second), 10)
+		if err != nil {
+			t.Fatalf("ListQueuedLeaves(): %v", err)
+		}
+		if len(stuck) != len(leaves) {
+			t.Fatalf("ListQueuedLeaves() returned %d leaves, want %d", len(stuck), len(leaves))
+		}
+		ensureLeavesHaveQueueTimestamp(t, stuck, fakeQueueTime)
+		if err := qr.RequeueLeaves(ctx, stuck[:1], requeued); err != nil {
+			t.Fatalf("RequeueLeaves(): %v", err)
+		}
+		if err := qr.DiscardQueuedLeaves(ctx, stuck[1:2]); err != nil {
+			t.Fatalf("DiscardQueuedLeaves(): %v", err)
+		}
+		if err := qr.DiscardQueuedLeaves(ctx, stuck[1:2]); status.Code(err) != codes.NotFound {
+			t.Errorf("DiscardQueuedLeaves() of discarded leaf=%v, want code %v", err, codes.NotFound)
+		}
+		return nil
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		queued, err := tx.DequeueLeaves(ctx, 10, requeued.Add(time.Second))
+		if err != nil {
+			t.Fatalf("DequeueLeaves(): %v", err)
+		}
+		if len(queued) != 2 {
+			t.Fatalf("DequeueLeaves() returned %d leaves, want 2", len(queued))
+		}
+		if got := queued[1].QueueTimestamp.AsTime(); !got.Equal(requeued) {
+			t.Errorf("Requeued leaf has QueueTimestamp %v, want %v", got, requeued)
+		}
+		return nil
+	})
+}
+
+func TestUnsequencedCounts(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorageWithOpts(DB, nil, LogStorageOptions{MaintainUnsequencedCounts: true})
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	checkCount := func(want int64) {
+		t.Helper()
+		if got, err := s.CountUnsequenced(ctx, tree); err != nil || got != want {
+			t.Errorf("CountUnsequenced()=%v, %v, want %v, nil", got, err, want)
+		}
+		var rows int64
+		if err := DB.QueryRowContext(ctx, countUnsequencedSQL, tree.TreeId).Scan(&rows); err != nil || rows != want {
+			t.Errorf("COUNT(*) of Unsequenced=%v, %v, want %v, nil", rows, err, want)
+		}
+	}
+
+	leaves := createTestLeaves(5, 0)
+	if _, err := s.QueueLeaves(ctx, tree, leaves, fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	// Duplicates aren't queued, so they aren't counted.
+	if _, err := s.QueueLeaves(ctx, tree, createTestLeaves(3, 3), fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	checkCount(6)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 3, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("DequeueLeaves(): %v", err)
+		}
+		for i, l := range dequeued {
+			l.LeafIndex = int64(i)
+			l.IntegrateTimestamp = timestamppb.New(fakeIntegrateTime)
+		}
+		return tx.UpdateSequencedLeaves(ctx, dequeued)
+	})
+	checkCount(3)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		qr := tx.(storage.QueueRepairer)
+		queued, err := qr.ListQueuedLeaves(ctx, fakeDequeueCutoffTime, 10)
+		if err != nil {
+			t.Fatalf("ListQueuedLeaves(): %v", err)
+		}
+		return qr.DiscardQueuedLeaves(ctx, queued[:1])
+	})
+	checkCount(2)
+}
+
+func TestDeadlockRetries(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	deadlock := &mysql.MySQLError{Number: errNumDeadlock, Message: "Deadlock found when trying to get lock"}
+
+	for _, test := range []struct {
+		desc      string
+		retries   int
+		deadlocks int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{desc: "no-retries", deadlocks: 1, err: deadlock, wantCalls: 1, wantErr: true},
+		{desc: "retried", retries: 2, deadlocks: 2, err: deadlock, wantCalls: 3},
+		{desc: "converted", retries: 2, deadlocks: 1, err: mysqlToGRPC(deadlock), wantCalls: 2},
+		{desc: "too-many", retries: 2, deadlocks: 5, err: deadlock, wantCalls: 3, wantErr: true},
+		{desc: "other-error", retries: 2, deadlocks: 5, err: errors.New("not a deadlock"), wantCalls: 1, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			s := NewLogStorageWithOpts(DB, nil, LogStorageOptions{DeadlockRetries: test.retries})
+			calls := 0
+			err := s.ReadWriteTransaction(ctx, tree, func(context.Context, storage.LogTreeTX) error {
+				calls++
+				if calls <= test.deadlocks {
+					return test.err
+				}
+				return nil
+			})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("ReadWriteTransaction(): %v, wantErr %v", err, test.wantErr)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("ReadWriteTransaction() called f %d times, want %d", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetLeavesByIndices(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	const leafCount = 10
+	for i := int64(0); i < leafCount; i++ {
+		data := []byte(fmt.Sprintf("data %d", i))
+		hash := sha256.Sum256(data)
+		createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+	}
+	mustSignAndStoreLogRoot(ctx, t, s, tree, leafCount)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.(storage.IndexedLeafReader).GetLeavesByIndices(ctx, []int64{7, 2, 9, 42})
+		if err != nil {
+			t.Fatalf("GetLeavesByIndices(): %v", err)
+		}
+		var got []int64
+		for _, leaf := range leaves {
+			got = append(got, leaf.LeafIndex)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if want := []int64{2, 7, 9}; !cmp.Equal(got, want) {
+			t.Errorf("GetLeavesByIndices() returned indices %v, want %v", got, want)
+		}
+		return nil
+	})
+}
+
+func TestGetSequencedIndexRange(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	checkRange := func(wantFirst, wantLast int64) {
+		t.Helper()
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			first, last, err := tx.(storage.SequencedIndexReader).GetSequencedIndexRange(ctx)
+			if err != nil {
+				t.Fatalf("GetSequencedIndexRange(): %v", err)
+			}
+			if first != wantFirst || last != wantLast {
+				t.Errorf("GetSequencedIndexRange()=%d, %d, want %d, %d", first, last, wantFirst, wantLast)
+			}
+			return nil
+		})
+	}
+	checkRange(-1, -1)
+
+	for _, i := range []int64{3, 4, 9} {
+		data := []byte(fmt.Sprintf("data %d", i))
+		hash := sha256.Sum256(data)
+		createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+	}
+	checkRange(3, 9)
+}
+
+// legacySelectLeavesByRangeSQL is the range query used before the join order
+// was forced, kept for comparison in BenchmarkGetLeavesByRange.
+const legacySelectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+		FROM LeafData l,SequencedLeafData s
+		WHERE l.LeafIdentityHash = s.LeafIdentityHash
+		AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId ORDER BY s.SequenceNumber`
+
+// BenchmarkGetLeavesByRange fetches all of the leaves of a 100k leaf tree,
+// comparing the legacy query with the forced range scan.
+func BenchmarkGetLeavesByRange(b *testing.B) {
+	ctx := context.Background()
+	const leafCount, batchSize = 100000, 1000
+
+	cleanTestDB(DB)
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(DB), testonly.LogTree)
+	if err != nil {
+		b.Fatalf("CreateTree(): %v", err)
+	}
+	s := NewLogStorage(DB, nil)
+
+	for start := int64(0); start < leafCount; start += batchSize {
+		var leafArgs, seqArgs []interface{}
+		for i := start; i < start+batchSize; i++ {
+			data := []byte(fmt.Sprintf("data %d", i))
+			hash := sha256.Sum256(data)
+			leafArgs = append(leafArgs, tree.TreeId, hash[:], data, someExtraData, fakeQueueTime.UnixNano())
+			seqArgs = append(seqArgs, tree.TreeId, i, hash[:], hash[:], fakeIntegrateTime.UnixNano())
+		}
+		values := strings.TrimSuffix(strings.Repeat("(?,?,?,?,?),", batchSize), ",")
+		if _, err := DB.ExecContext(ctx, "INSERT INTO LeafData(TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos) VALUES"+values, leafArgs...); err != nil {
+			b.Fatalf("Failed to insert leaf data: %v", err)
+		}
+		if _, err := DB.ExecContext(ctx, "INSERT INTO SequencedLeafData(TreeId,SequenceNumber,LeafIdentityHash,MerkleLeafHash,IntegrateTimestampNanos) VALUES"+values, seqArgs...); err != nil {
+			b.Fatalf("Failed to insert sequenced leaf data: %v", err)
+		}
+	}
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeLogRoot(ctx, tx, leafCount, 0, []byte{0})
+	}); err != nil {
+		b.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	b.Run("legacy-join", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			rows, err := DB.QueryContext(ctx, legacySelectLeavesByRangeSQL, 0, leafCount, tree.TreeId)
+			if err != nil {
+				b.Fatalf("QueryContext(): %v", err)
+			}
+			count := 0
+			for ; rows.Next(); count++ {
+			}
+			if err := rows.Close(); err != nil {
+				b.Fatalf("Close(): %v", err)
+			}
+			if count != leafCount {
+				b.Fatalf("Got %d leaves, want %d", count, leafCount)
+			}
+		}
+	})
+	for _, descending := range []bool{false, true} {
+		b.Run(fmt.Sprintf("range-scan-descending=%v", descending), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				tx, err := s.SnapshotForTree(ctx, tree)
+				if err != nil {
+					b.Fatalf("SnapshotForTree(): %v", err)
+				}
+				var leaves []*trillian.LogLeaf
+				if descending {
+					leaves, err = tx.(DescendingRangeReader).GetLeavesByRangeDescending(ctx, 0, leafCount)
+				} else {
+					leaves, err = tx.GetLeavesByRange(ctx, 0, leafCount)
+				}
+				_ = tx.Close()
+				if err != nil {
+					b.Fatalf("GetLeavesByRange(): %v", err)
+				}
+				if len(leaves) != leafCount {
+					b.Fatalf("Got %d leaves, want %d", len(leaves), leafCount)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkQueueLeavesUnsequencedCounts queues batches of leaves with and
+// without maintaining the counts of the Unsequenced table, which costs an extra
+// write per transaction.
+func BenchmarkQueueLeavesUnsequencedCounts(b *testing.B) {
+	ctx := context.Background()
+	const batchSize = 100
+
+	for _, maintain := range []bool{false, true} {
+		b.Run(fmt.Sprintf("maintain=%v", maintain), func(b *testing.B) {
+			cleanTestDB(DB)
+			tree, err := storage.CreateTree(ctx, NewAdminStorage(DB), testonly.LogTree)
+			if err != nil {
+				b.Fatalf("CreateTree(): %v", err)
+			}
+			s := NewLogStorageWithOpts(DB, nil, LogStorageOptions{MaintainUnsequencedCounts: maintain})
+			if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+				return storeLogRoot(ctx, tx, 0, 0, []byte{0})
+			}); err != nil {
+				b.Fatalf("ReadWriteTransaction(): %v", err)
+			}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if _, err := s.QueueLeaves(ctx, tree, createTestLeaves(batchSize, int64(n*batchSize)), fakeQueueTime); err != nil {
+					b.Fatalf("QueueLeaves(): %v", err)
+				}
+			}
+		})
+	}
+}
+
 func leavesEquivalent(t *testing.T, gotLeaves, wantLeaves []*trillian.LogLeaf) {
 	t.Helper()
 	want := make(map[string]*trillian.LogLeaf)
@@ -686,10 +1195,82 @@ func TestLogRootUpdate(t *testing.T) {
 		if !proto.Equal(root2, root3) {
 			t.Fatalf("Root round trip failed: <%v> and: <%v>", root, root2)
 		}
+		// The newest root of a size is returned.
+		bySize, err := tx2.(storage.RootHistoryReader).GetSignedLogRootBySize(ctx, 16)
+		if err != nil {
+			t.Fatalf("GetSignedLogRootBySize(): %v", err)
+		}
+		if !proto.Equal(root2, bySize) {
+			t.Errorf("GetSignedLogRootBySize()=<%v>, want <%v>", bySize, root2)
+		}
+		if _, err := tx2.(storage.RootHistoryReader).GetSignedLogRootBySize(ctx, 15); status.Code(err) != codes.NotFound {
+			t.Errorf("GetSignedLogRootBySize() of missing size=%v, want code %v", err, codes.NotFound)
+		}
+		byHash, err := tx2.(storage.RootHistoryReader).GetSignedLogRootByHash(ctx, []byte(dummyHash))
+		if err != nil {
+			t.Fatalf("GetSignedLogRootByHash(): %v", err)
+		}
+		if !proto.Equal(root2, byHash) {
+			t.Errorf("GetSignedLogRootByHash()=<%v>, want <%v>", byHash, root2)
+		}
+		if _, err := tx2.(storage.RootHistoryReader).GetSignedLogRootByHash(ctx, []byte("missing")); status.Code(err) != codes.NotFound {
+			t.Errorf("GetSignedLogRootByHash() of missing hash=%v, want code %v", err, codes.NotFound)
+		}
 		return nil
 	})
 }
 
+func TestListSignedLogRoots(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	var roots []*trillian.SignedLogRoot
+	for i := 0; i < 5; i++ {
+		root, err := SignLogRoot(&types.LogRootV1{
+			TimestampNanos: uint64(100 + i),
+			TreeSize:       uint64(2 * i),
+			RootHash:       []byte(fmt.Sprintf("root-%d", i)),
+		})
+		if err != nil {
+			t.Fatalf("SignLogRoot(): %v", err)
+		}
+		roots = append(roots, root)
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			return tx.StoreSignedLogRoot(ctx, root)
+		})
+	}
+
+	for _, test := range []struct {
+		desc  string
+		rng   storage.RootRange
+		limit int
+		want  []*trillian.SignedLogRoot
+	}{
+		{desc: "all", limit: 10, want: roots},
+		{desc: "limit", limit: 2, want: roots[:2]},
+		{desc: "time", rng: storage.RootRange{StartTimestampNanos: 101, EndTimestampNanos: 103}, limit: 10, want: roots[1:3]},
+		{desc: "size", rng: storage.RootRange{StartTreeSize: 3, EndTreeSize: 7}, limit: 10, want: roots[2:4]},
+		{desc: "open-end", rng: storage.RootRange{StartTreeSize: 6}, limit: 10, want: roots[3:]},
+		{desc: "empty", rng: storage.RootRange{StartTimestampNanos: 200}, limit: 10},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+				got, err := tx.(storage.RootHistoryReader).ListSignedLogRoots(ctx, test.rng, test.limit)
+				if err != nil {
+					t.Fatalf("ListSignedLogRoots(): %v", err)
+				}
+				if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+					t.Errorf("ListSignedLogRoots() diff (-want +got):\n%s", diff)
+				}
+				return nil
+			})
+		})
+	}
+}
+
 func TestGetActiveLogIDs(t *testing.T) {
 	ctx := context.Background()
 
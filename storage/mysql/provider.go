@@ -15,16 +15,22 @@
 package mysql
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/dbsecret"
+	"github.com/google/trillian/storage/dbstats"
 	"k8s.io/klog/v2"
 
 	// Load MySQL driver
@@ -35,8 +41,19 @@ var (
 	mySQLURI        = flag.String("mysql_uri", "test:zaphod@tcp(127.0.0.1:3306)/test", "Connection URI for MySQL database")
 	maxConns        = flag.Int("mysql_max_conns", 0, "Maximum connections to the database")
 	maxIdle         = flag.Int("mysql_max_idle_conns", -1, "Maximum idle database connections in the connection pool")
+	warmupConns     = flag.Int("mysql_warmup_conns", 0, "Number of database connections to open and check before the server reports itself as ready. If --mysql_max_idle_conns is unset, that many are kept idle in the connection pool")
 	mySQLTLSCA      = flag.String("mysql_tls_ca", "", "Path to the CA certificate file for MySQL TLS connection ")
 	mySQLServerName = flag.String("mysql_server_name", "", "Name of the MySQL server to be used as the Server Name in the TLS configuration")
+	maintainCounts  = flag.Bool("mysql_maintain_unsequenced_counts", false, "If true, the counts of queued leaves in the UnsequencedCounts table are kept up to date. It must be set on all of the log servers and signers using the database, or none of them")
+	createSchema    = flag.Bool("mysql_create_schema_if_missing", false, "If true, the storage schema of this version of Trillian is created when the database has no Trees table. Meant for test and development environments")
+	galera          = flag.Bool("mysql_galera", false, "If true, the database is a MariaDB Galera cluster, and reads wait for the writes committed on other nodes to be applied on the one connected to. Use with --mysql_deadlock_retries")
+	deadlockRetries = flag.Int("mysql_deadlock_retries", 0, "Number of times a log transaction which fails with a deadlock, or a Galera certification failure, is retried")
+	readerURI       = flag.String("mysql_reader_uri", "", "Connection URI for a read-only replica of the MySQL database, such as the reader endpoint of an Aurora cluster. If set, log reads which don't write are run on it, and may lag behind the writes")
+	connMaxLifetime = flag.Duration("mysql_conn_max_lifetime", 0, "Maximum time for which a database connection is reused. If positive, the connections follow the DNS name of the database to its new host after a failover, such as that of an Aurora cluster endpoint")
+	awsIAMRegion    = flag.String("mysql_aws_iam_auth_region", "", "If set, connections authenticate to an Amazon RDS or Aurora database with IAM database authentication tokens for this AWS region, in place of the password in --mysql_uri and --mysql_reader_uri. The connections need TLS")
+	cloudSQLIAMAuth = flag.Bool("mysql_cloudsql_iam_auth", false, "If true, connections authenticate to a Google Cloud SQL database with IAM database authentication, using the access tokens of the Application Default Credentials in place of the password in --mysql_uri and --mysql_reader_uri. The connections need TLS")
+	passwordSecret  = flag.String("mysql_password_secret", "", "Reference to a secret holding the database password, which is used in place of the one in --mysql_uri and --mysql_reader_uri, e.g. file:/run/secrets/db-password, vault:secret/data/trillian#password, awssm:trillian-db#password or gcpsm:projects/p/secrets/trillian-db/versions/latest. See the dbsecret package")
+	secretRefresh   = flag.Duration("mysql_password_secret_refresh", time.Minute, "How long a password fetched from --mysql_password_secret is used for new connections before it is fetched again")
 
 	mysqlMu              sync.Mutex
 	mysqlErr             error
@@ -62,7 +79,10 @@ func init() {
 
 type mysqlProvider struct {
 	db *sql.DB
-	mf monitoring.MetricFactory
+	// reader is the --mysql_reader_uri database, or nil.
+	reader *sql.DB
+	mf     monitoring.MetricFactory
+	stats  *dbstats.Exporter
 }
 
 func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
@@ -73,9 +93,22 @@ func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, err
 		if err != nil {
 			return nil, err
 		}
+		if *createSchema {
+			if _, err := CreateSchemaIfMissing(context.Background(), db); err != nil {
+				return nil, err
+			}
+		}
+		var reader *sql.DB
+		if *readerURI != "" {
+			if reader, err = openDatabase(*readerURI); err != nil {
+				return nil, err
+			}
+		}
 		mysqlStorageInstance = &mysqlProvider{
-			db: db,
-			mf: mf,
+			db:     db,
+			reader: reader,
+			mf:     mf,
+			stats:  dbstats.NewExporter(mf, "mysql"),
 		}
 	}
 	return mysqlStorageInstance, nil
@@ -87,16 +120,50 @@ func getMySQLDatabaseLocked() (*sql.DB, error) {
 	if mysqlDB != nil || mysqlErr != nil {
 		return mysqlDB, mysqlErr
 	}
-	dsn := *mySQLURI
+	db, err := openDatabase(*mySQLURI)
+	if err != nil {
+		mysqlErr = err
+		return nil, err
+	}
+	mysqlDB, mysqlErr = db, nil
+	return db, nil
+}
+
+// openDatabase opens the database at dsn with the connection options and pool
+// limits set by the flags.
+func openDatabase(dsn string) (*sql.DB, error) {
 	if *mySQLTLSCA != "" {
 		if err := registerMySQLTLSConfig(); err != nil {
 			return nil, err
 		}
-		dsn += "?tls=custom"
+		dsn = withParam(dsn, "tls=custom")
+	}
+	if *galera {
+		// Make reads causal: each waits until the node has applied all of
+		// the writes which the cluster committed before it started.
+		dsn = withParam(dsn, "wsrep_sync_wait=1")
+	}
+	var db *sql.DB
+	var err error
+	switch {
+	case *awsIAMRegion != "" && *cloudSQLIAMAuth:
+		return nil, errors.New("--mysql_aws_iam_auth_region and --mysql_cloudsql_iam_auth can't both be set")
+	case *passwordSecret != "" && (*awsIAMRegion != "" || *cloudSQLIAMAuth):
+		return nil, errors.New("--mysql_password_secret can't be set with IAM database authentication")
+	case *passwordSecret != "":
+		var secret *dbsecret.Secret
+		if secret, err = dbsecret.Open(context.Background(), *passwordSecret, *secretRefresh); err != nil {
+			return nil, err
+		}
+		db, err = OpenDBWithPasswordFunc(dsn, secret.Value)
+	case *awsIAMRegion != "":
+		db, err = OpenDBWithIAMAuth(dsn, *awsIAMRegion)
+	case *cloudSQLIAMAuth:
+		db, err = OpenDBWithCloudSQLIAMAuth(dsn)
+	default:
+		db, err = OpenDB(dsn)
 	}
-	db, err := OpenDB(dsn)
 	if err != nil {
-		mysqlErr = err
 		return nil, err
 	}
 	if *maxConns > 0 {
@@ -104,13 +171,21 @@ func getMySQLDatabaseLocked() (*sql.DB, error) {
 	}
 	if *maxIdle >= 0 {
 		db.SetMaxIdleConns(*maxIdle)
+	} else if *warmupConns > 0 {
+		db.SetMaxIdleConns(*warmupConns)
+	}
+	if *connMaxLifetime > 0 {
+		db.SetConnMaxLifetime(*connMaxLifetime)
 	}
-	mysqlDB, mysqlErr = db, nil
 	return db, nil
 }
 
 func (s *mysqlProvider) LogStorage() storage.LogStorage {
-	return NewLogStorage(s.db, s.mf)
+	return NewLogStorageWithOpts(s.db, s.mf, LogStorageOptions{
+		MaintainUnsequencedCounts: *maintainCounts,
+		DeadlockRetries:           *deadlockRetries,
+		ReaderDB:                  s.reader,
+	})
 }
 
 func (s *mysqlProvider) AdminStorage() storage.AdminStorage {
@@ -118,9 +193,69 @@ func (s *mysqlProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *mysqlProvider) Close() error {
+	if s.reader != nil {
+		if err := s.reader.Close(); err != nil {
+			return err
+		}
+	}
 	return s.db.Close()
 }
 
+// CheckSchema implements storage.SchemaChecker.
+func (s *mysqlProvider) CheckSchema(ctx context.Context) error {
+	return CheckSchema(ctx, s.db)
+}
+
+// ExportStats implements storage.StatsExporter. It exports the statistics of
+// the connection pool.
+func (s *mysqlProvider) ExportStats() {
+	s.stats.Update(s.db)
+}
+
+// Warmup implements storage.Warmer.
+func (s *mysqlProvider) Warmup(ctx context.Context) error {
+	n := *warmupConns
+	if *maxConns > 0 {
+		n = min(n, *maxConns)
+	}
+	return warmup(ctx, s.db, n)
+}
+
+// warmup opens n connections to db, checks each of them with a ping, and
+// then returns them all to the connection pool. The connections are held
+// until all of them are open, so that the pool doesn't hand out the same
+// connection twice.
+func warmup(ctx context.Context, db *sql.DB, n int) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, c := range conns {
+			if err := c.Close(); err != nil {
+				klog.Warningf("Close(): %v", err)
+			}
+		}
+	}()
+	for len(conns) < n {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open connection %d of %d: %v", len(conns)+1, n, err)
+		}
+		conns = append(conns, c)
+		if err := c.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to ping connection %d of %d: %v", len(conns), n, err)
+		}
+	}
+	klog.Infof("Opened %d database connections", n)
+	return nil
+}
+
+// withParam returns dsn with the parameter param, e.g. "tls=custom", added.
+func withParam(dsn, param string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	return dsn + "?" + param
+}
+
 // registerMySQLTLSConfig registers a custom TLS config for MySQL using a provided CA certificate and optional server name.
 // Returns an error if the CA certificate can't be read or added to the root cert pool, or when the registration of the TLS config fails.
 func registerMySQLTLSConfig() error {
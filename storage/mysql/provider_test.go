@@ -44,3 +44,28 @@ func TestMySQLStorageProviderErrorPersistence(t *testing.T) {
 		t.Fatalf("Expected second call to 'storage.NewProvider' to fail with %q, instead got: %q", err1, err2)
 	}
 }
+
+func TestWithParam(t *testing.T) {
+	for _, test := range []struct {
+		dsn, want string
+	}{
+		{dsn: "test:zaphod@tcp(127.0.0.1:3306)/test", want: "test:zaphod@tcp(127.0.0.1:3306)/test?tls=custom"},
+		{dsn: "test:zaphod@tcp(127.0.0.1:3306)/test?parseTime=true", want: "test:zaphod@tcp(127.0.0.1:3306)/test?parseTime=true&tls=custom"},
+	} {
+		if got := withParam(test.dsn, "tls=custom"); got != test.want {
+			t.Errorf("withParam(%q) = %q, want %q", test.dsn, got, test.want)
+		}
+	}
+}
+
+func TestOpenDBWithIAMAuthNeedsTLS(t *testing.T) {
+	for _, dsn := range []string{
+		"trillian@tcp(db.example.com:3306)/test",
+		"trillian@tcp(db.example.com:3306)/test?tls=false",
+		"trillian@tcp(db.example.com:3306)/test?tls=preferred",
+	} {
+		if _, err := OpenDBWithIAMAuth(dsn, "eu-west-1"); err == nil {
+			t.Errorf("OpenDBWithIAMAuth(%q) succeeded, want error", dsn)
+		}
+	}
+}
@@ -141,6 +141,10 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, leaves []dequeued
 		}
 	}
 
+	if err := t.addUnsequencedCount(ctx, -int64(len(leaves))); err != nil {
+		return err
+	}
+
 	observe(dequeueRemoveLatency, time.Since(start), labelForTX(t))
 	return nil
 }
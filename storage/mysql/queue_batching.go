@@ -121,8 +121,9 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	return t.removeSequencedLeaves(ctx, dequeuedLeaves)
 }
 
-func (m *mySQLLogStorage) getDeleteUnsequencedStmt(ctx context.Context, num int) (*sql.Stmt, error) {
-	return m.getStmt(ctx, deleteUnsequencedSQL, num, "?", "?")
+// deleteUnsequencedQuery returns the statement deleting num queued leaves.
+func deleteUnsequencedQuery(num int) string {
+	return expandPlaceholderSQL(deleteUnsequencedSQL, num, "?", "?")
 }
 
 // removeSequencedLeaves removes the passed in leaves slice (which may be
@@ -131,20 +132,17 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 	// Don't need to re-sort because the query ordered by leaf hash. If that changes because
 	// the query is expensive then the sort will need to be done here. See comment in
 	// QueueLeaves.
-	tmpl, err := t.ls.getDeleteUnsequencedStmt(ctx, len(queueIDs))
-	if err != nil {
-		klog.Warningf("Failed to get delete statement for sequenced work: %s", err)
-		return err
-	}
-	stx := t.tx.StmtContext(ctx, tmpl)
 	args := make([]interface{}, len(queueIDs))
 	for i, q := range queueIDs {
 		args[i] = []byte(q)
 	}
-	result, err := stx.ExecContext(ctx, args...)
+	result, err := t.tx.ExecContext(ctx, deleteUnsequencedQuery(len(queueIDs)), args...)
 	if err != nil {
 		// Error is handled by checkResultOkAndRowCountIs() below
 		klog.Warningf("Failed to delete sequenced work: %s", err)
 	}
-	return checkResultOkAndRowCountIs(result, err, int64(len(queueIDs)))
+	if err := checkResultOkAndRowCountIs(result, err, int64(len(queueIDs))); err != nil {
+		return err
+	}
+	return t.addUnsequencedCount(ctx, -int64(len(queueIDs)))
 }
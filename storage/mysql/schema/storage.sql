@@ -22,6 +22,7 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL, -- This is now used to store settings.
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  Options               MEDIUMBLOB, -- Serialized per-tree options, see storage.MarshalTreeOptions.
   PRIMARY KEY(TreeId)
 );
 
@@ -116,6 +117,48 @@ CREATE TABLE IF NOT EXISTS SequencedLeafData(
 CREATE INDEX SequencedLeafMerkleIdx
   ON SequencedLeafData(TreeId, MerkleLeafHash);
 
+-- Audit trail of changes made to LeafData.ExtraData by UpdateLeafExtraData.
+CREATE TABLE IF NOT EXISTS ExtraDataHistory(
+  TreeId               BIGINT NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  UpdateTimestampNanos BIGINT NOT NULL,
+  -- The index of the leaf named in the request.
+  SequenceNumber       BIGINT UNSIGNED NOT NULL,
+  PreviousExtraData    LONGBLOB,
+  NewExtraData         LONGBLOB,
+  Reason               TEXT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash, UpdateTimestampNanos),
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+-- Leaves whose LeafValue has been replaced with an empty tombstone by
+-- RedactLeaf, and the reasons why.
+CREATE TABLE IF NOT EXISTS LeafRedactions(
+  TreeId               BIGINT NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  RedactTimestampNanos BIGINT NOT NULL,
+  -- The index of the leaf named in the request.
+  SequenceNumber       BIGINT UNSIGNED NOT NULL,
+  Reason               TEXT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+-- Recent integration runs of the signer, as listed by the
+-- ListIntegrationEvents admin RPC. Events older than the retention period of
+-- the signer are deleted as new ones are added.
+CREATE TABLE IF NOT EXISTS IntegrationEvents(
+  TreeId               BIGINT NOT NULL,
+  StartTimestampNanos  BIGINT NOT NULL,
+  DurationNanos        BIGINT NOT NULL,
+  BatchSize            BIGINT NOT NULL,
+  TreeSize             BIGINT UNSIGNED NOT NULL,
+  RootHash             VARBINARY(255) NOT NULL,
+  Signer               VARCHAR(255) NOT NULL,
+  PRIMARY KEY(TreeId, StartTimestampNanos),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS Unsequenced(
   TreeId               BIGINT NOT NULL,
   -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
@@ -135,3 +178,30 @@ CREATE TABLE IF NOT EXISTS Unsequenced(
   QueueID VARBINARY(32) DEFAULT NULL UNIQUE,
   PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
 );
+
+-- Counts of the rows of Unsequenced, maintained in the same transactions as
+-- the rows themselves when the --mysql_maintain_unsequenced_counts flag is set,
+-- so that the MySQL quota manager can count them exactly without scanning the
+-- table. The count of a tree is split over shards, picked at random by each
+-- transaction, so that concurrent transactions don't contend for one row. The
+-- count of a shard may be negative; only the sum over the shards is meaningful.
+CREATE TABLE IF NOT EXISTS UnsequencedCounts(
+  TreeId               BIGINT NOT NULL,
+  Shard                INTEGER NOT NULL,
+  Count                BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, Shard),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Recently submitted leaves, used by the dedup/mysqldedup package to answer
+-- duplicate submissions without touching the tables above. Only needed if
+-- that package is in use. Rows are keyed like LeafData, and hold a serialized
+-- trillian.LogLeaf.
+CREATE TABLE IF NOT EXISTS RecentSubmissions(
+  TreeId               BIGINT NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  Leaf                 MEDIUMBLOB NOT NULL,
+  SubmitTimeMillis     BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  INDEX RecentSubmissionsTimeIdx(SubmitTimeMillis)
+);
@@ -0,0 +1,56 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian/storage/testdb"
+)
+
+func TestCreateSchemaIfMissing(t *testing.T) {
+	ctx := context.Background()
+	db, done, err := testdb.NewEmptyDB(ctx, testdb.DriverMySQL)
+	if err != nil {
+		t.Fatalf("NewEmptyDB(): %v", err)
+	}
+	defer done(ctx)
+
+	if created, err := CreateSchemaIfMissing(ctx, db); err != nil || !created {
+		t.Fatalf("CreateSchemaIfMissing() on empty database = %v, %v; want true, nil", created, err)
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM Trees").Scan(&n); err != nil || n != 0 {
+		t.Errorf("Trees has %d rows, %v; want 0, nil", n, err)
+	}
+	// The existing schema must be left alone.
+	if created, err := CreateSchemaIfMissing(ctx, db); err != nil || created {
+		t.Errorf("CreateSchemaIfMissing() on existing schema = %v, %v; want false, nil", created, err)
+	}
+}
+
+func TestSchemaStatements(t *testing.T) {
+	stmts := schemaStatements("# A comment; with a semicolon.\nCREATE TABLE A(\n  X INT -- Trailing comment.\n);\n\n-- Another comment.\nCREATE INDEX B ON A(X);\n")
+	want := []string{"CREATE TABLE A(\nX INT -- Trailing comment.\n)", "CREATE INDEX B ON A(X)"}
+	if len(stmts) != len(want) {
+		t.Fatalf("schemaStatements() = %q, want %q", stmts, want)
+	}
+	for i := range want {
+		if stmts[i] != want[i] {
+			t.Errorf("schemaStatements()[%d] = %q, want %q", i, stmts[i], want[i])
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/mysql/mysqlpb"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -58,7 +59,7 @@ func readTree(r row) (*trillian.Tree, error) {
 	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
 	var createMillis, updateMillis, maxRootDurationMillis int64
 	var displayName, description sql.NullString
-	var privateKey, publicKey []byte
+	var privateKey, publicKey, options []byte
 	var deleted sql.NullBool
 	var deleteMillis sql.NullInt64
 	err := r.Scan(
@@ -77,6 +78,7 @@ func readTree(r row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&options,
 	)
 	if err != nil {
 		return nil, err
@@ -128,6 +130,10 @@ func readTree(r row) (*trillian.Tree, error) {
 		}
 	}
 
+	if err := storage.UnmarshalTreeOptions(options, tree); err != nil {
+		return nil, err
+	}
+
 	// We're going to try to interpret PublicKey as storageSettings, but it could be a
 	// public key from a really old tree, or an empty column from a tree created in the
 	// period between Trillian key material being removed and this column being used for
@@ -24,6 +24,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -125,6 +126,35 @@ func TestNodeRoundTrip(t *testing.T) {
 	}
 }
 
+func TestBatchQueries(t *testing.T) {
+	for _, test := range []struct {
+		desc  string
+		query func(num int) string
+		fixed int // The number of placeholders outside of the batch.
+		per   int // The number of placeholders per batch entry.
+	}{
+		{desc: "subtrees", query: func(n int) string { return subtreesQuery(true, n) }, fixed: 3, per: 1},
+		{desc: "subtrees-norev", query: func(n int) string { return subtreesQuery(false, n) }, fixed: 1, per: 1},
+		{desc: "insert-subtrees", query: insertSubtreesQuery, per: 4},
+		{desc: "merkle-hash", query: func(n int) string { return leavesByMerkleHashQuery(n, true) }, fixed: 1, per: 1},
+		{desc: "identity-hash", query: leavesByLeafIdentityHashQuery, fixed: 1, per: 1},
+		{desc: "sequenced-identity-hash", query: func(n int) string { return sequencedLeavesByLeafIdentityHashQuery(n, false) }, fixed: 1, per: 1},
+		{desc: "indices", query: leavesByIndicesQuery, fixed: 1, per: 1},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			for _, num := range []int{1, 7} {
+				q := test.query(num)
+				if got, want := strings.Count(q, "?"), test.fixed+test.per*num; got != want {
+					t.Errorf("query(%d) has %d placeholders, want %d: %s", num, got, want, q)
+				}
+				if strings.Contains(q, placeholderSQL) {
+					t.Errorf("query(%d) not expanded: %s", num, q)
+				}
+			}
+		})
+	}
+}
+
 // This test ensures that node writes cross subtree boundaries so this edge case in the subtree
 // cache gets exercised. Any tree size > 256 will do this.
 func TestLogNodeRoundTripMultiSubtree(t *testing.T) {
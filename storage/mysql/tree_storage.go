@@ -19,14 +19,18 @@ import (
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"strings"
 	"sync"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/cloudsqlauth"
 	"github.com/google/trillian/storage/mysql/mysqlpb"
+	"github.com/google/trillian/storage/rdsauth"
 	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
 	"google.golang.org/protobuf/proto"
@@ -67,13 +71,6 @@ const (
 // Storage implementations, and contains functionality which is common to both,
 type mySQLTreeStorage struct {
 	db *sql.DB
-
-	// Must hold the mutex before manipulating the statement map. Sharing a lock because
-	// it only needs to be held while the statements are built, not while they execute and
-	// this will be a short time. These maps are from the number of placeholder '?'
-	// in the query to the statement that should be used.
-	statementMutex sync.Mutex
-	statements     map[string]map[int]*sql.Stmt
 }
 
 // OpenDB opens a database connection for all MySQL-based storage implementations.
@@ -84,7 +81,93 @@ func OpenDB(dbURL string) (*sql.DB, error) {
 		klog.Warningf("Could not open MySQL database, check config: %s", err)
 		return nil, err
 	}
+	return setStrictMode(db)
+}
+
+// OpenDBWithIAMAuth opens a database connection like OpenDB, to an Amazon RDS
+// or Aurora database which authenticates the user of dbURL with IAM database
+// authentication tokens signed for region. A token is used in place of the
+// password each time a connection is opened. The tokens are sent in clear
+// text, so dbURL must enable TLS.
+func OpenDBWithIAMAuth(dbURL, region string) (*sql.DB, error) {
+	return openDBWithTokens(dbURL, func(cfg *mysql.Config) (func() (string, error), error) {
+		tokens, err := rdsauth.NewTokenSource(cfg.Addr, region, cfg.User)
+		if err != nil {
+			return nil, err
+		}
+		return tokens.Token, nil
+	})
+}
+
+// OpenDBWithCloudSQLIAMAuth opens a database connection like OpenDB, to a
+// Google Cloud SQL database which authenticates the user of dbURL with IAM
+// database authentication. The access token of the Application Default
+// Credentials is used in place of the password each time a connection is
+// opened. The tokens are sent in clear text, so dbURL must enable TLS.
+func OpenDBWithCloudSQLIAMAuth(dbURL string) (*sql.DB, error) {
+	return openDBWithTokens(dbURL, func(*mysql.Config) (func() (string, error), error) {
+		tokens, err := cloudsqlauth.NewTokenSource(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return tokens.Token, nil
+	})
+}
+
+// openDBWithTokens opens a database connection whose connections log in with
+// a token from the function returned by newTokens for the parsed dbURL.
+func openDBWithTokens(dbURL string, newTokens func(*mysql.Config) (func() (string, error), error)) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dbURL)
+	if err != nil {
+		klog.Warningf("Could not parse MySQL connection URI, check config: %s", err)
+		return nil, err
+	}
+	if cfg.TLS == nil || cfg.AllowFallbackToPlaintext {
+		return nil, errors.New("IAM database authentication needs TLS to be required")
+	}
+	token, err := newTokens(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AllowCleartextPasswords = true
+	return openDBWithPasswordFunc(cfg, func(context.Context) (string, error) {
+		return token()
+	})
+}
+
+// OpenDBWithPasswordFunc opens a database connection like OpenDB, which
+// calls password for the password of the user of dbURL each time a
+// connection is opened, so that the password can be rotated. Any password in
+// dbURL is ignored.
+func OpenDBWithPasswordFunc(dbURL string, password func(context.Context) (string, error)) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dbURL)
+	if err != nil {
+		klog.Warningf("Could not parse MySQL connection URI, check config: %s", err)
+		return nil, err
+	}
+	return openDBWithPasswordFunc(cfg, password)
+}
+
+func openDBWithPasswordFunc(cfg *mysql.Config, password func(context.Context) (string, error)) (*sql.DB, error) {
+	if err := cfg.Apply(mysql.BeforeConnect(func(ctx context.Context, cfg *mysql.Config) error {
+		p, err := password(ctx)
+		if err != nil {
+			return err
+		}
+		cfg.Passwd = p
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		klog.Warningf("Could not open MySQL database, check config: %s", err)
+		return nil, err
+	}
+	return setStrictMode(sql.OpenDB(connector))
+}
 
+func setStrictMode(db *sql.DB) (*sql.DB, error) {
 	if _, err := db.ExecContext(context.TODO(), "SET sql_mode = 'STRICT_ALL_TABLES'"); err != nil {
 		klog.Warningf("Failed to set strict mode on mysql db: %s", err)
 		return nil, err
@@ -94,14 +177,18 @@ func OpenDB(dbURL string) (*sql.DB, error) {
 }
 
 func newTreeStorage(db *sql.DB) *mySQLTreeStorage {
-	return &mySQLTreeStorage{
-		db:         db,
-		statements: make(map[string]map[int]*sql.Stmt),
-	}
+	return &mySQLTreeStorage{db: db}
 }
 
 // expandPlaceholderSQL expands an sql statement by adding a specified number of '?'
 // placeholder slots. At most one placeholder will be expanded.
+//
+// The expanded statements are run directly on the transaction rather than
+// being prepared and cached for each number of slots seen: such a cache grows
+// with every distinct batch size, keeps a prepared statement open on each
+// connection for each entry, and serializes the storage users on its lock.
+// Setting interpolateParams=true in the DSN lets the driver send them without a
+// separate round trip for preparation.
 func expandPlaceholderSQL(sql string, num int, first, rest string) string {
 	if num <= 0 {
 		panic(fmt.Errorf("trying to expand SQL placeholder with <= 0 parameters: %s", sql))
@@ -112,49 +199,21 @@ func expandPlaceholderSQL(sql string, num int, first, rest string) string {
 	return strings.Replace(sql, placeholderSQL, parameters, 1)
 }
 
-// getStmt creates and caches sql.Stmt structs based on the passed in statement
-// and number of bound arguments.
-// TODO(al,martin): consider pulling this all out as a separate unit for reuse
-// elsewhere.
-func (m *mySQLTreeStorage) getStmt(ctx context.Context, statement string, num int, first, rest string) (*sql.Stmt, error) {
-	m.statementMutex.Lock()
-	defer m.statementMutex.Unlock()
-
-	if m.statements[statement] != nil {
-		if m.statements[statement][num] != nil {
-			// TODO(al,martin): we'll possibly need to expire Stmts from the cache,
-			// e.g. when DB connections break etc.
-			return m.statements[statement][num], nil
-		}
-	} else {
-		m.statements[statement] = make(map[int]*sql.Stmt)
-	}
-
-	s, err := m.db.PrepareContext(ctx, expandPlaceholderSQL(statement, num, first, rest))
-	if err != nil {
-		klog.Warningf("Failed to prepare statement %d: %s", num, err)
-		return nil, err
-	}
-
-	m.statements[statement][num] = s
-
-	return s, nil
-}
-
-func (m *mySQLTreeStorage) getSubtreeStmt(ctx context.Context, subtreeRevs bool, num int) (*sql.Stmt, error) {
+// subtreesQuery returns the statement reading num subtrees.
+func subtreesQuery(subtreeRevs bool, num int) string {
 	if subtreeRevs {
-		return m.getStmt(ctx, selectSubtreeSQL, num, "?", "?")
-	} else {
-		return m.getStmt(ctx, selectSubtreeSQLNoRev, num, "?", "?")
+		return expandPlaceholderSQL(selectSubtreeSQL, num, "?", "?")
 	}
+	return expandPlaceholderSQL(selectSubtreeSQLNoRev, num, "?", "?")
 }
 
-func (m *mySQLTreeStorage) setSubtreeStmt(ctx context.Context, num int) (*sql.Stmt, error) {
-	return m.getStmt(ctx, insertSubtreeMultiSQL, num, "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
+// insertSubtreesQuery returns the statement writing num subtrees.
+func insertSubtreesQuery(num int) string {
+	return expandPlaceholderSQL(insertSubtreeMultiSQL, num, "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
 }
 
-func (m *mySQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
-	t, err := m.db.BeginTx(ctx, nil /* opts */)
+func (m *mySQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache, opts *sql.TxOptions) (treeTX, error) {
+	t, err := m.db.BeginTx(ctx, opts)
 	if err != nil {
 		klog.Warningf("Could not start tree TX: %s", err)
 		return treeTX{}, err
@@ -199,17 +258,6 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, ids [][]by
 		return nil, nil
 	}
 
-	tmpl, err := t.ts.getSubtreeStmt(ctx, t.subtreeRevs, len(ids))
-	if err != nil {
-		return nil, err
-	}
-	stx := t.tx.StmtContext(ctx, tmpl)
-	defer func() {
-		if err := stx.Close(); err != nil {
-			klog.Errorf("stx.Close(): %v", err)
-		}
-	}()
-
 	var args []interface{}
 	if t.subtreeRevs {
 		args = make([]interface{}, 0, len(ids)+3)
@@ -232,7 +280,7 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, ids [][]by
 		}
 	}
 
-	rows, err := stx.QueryContext(ctx, args...)
+	rows, err := t.tx.QueryContext(ctx, subtreesQuery(t.subtreeRevs, len(ids)), args...)
 	if err != nil {
 		klog.Warningf("Failed to get merkle subtrees: %s", err)
 		return nil, err
@@ -335,18 +383,7 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 		args = append(args, subtreeRev)
 	}
 
-	tmpl, err := t.ts.setSubtreeStmt(ctx, len(subtrees))
-	if err != nil {
-		return err
-	}
-	stx := t.tx.StmtContext(ctx, tmpl)
-	defer func() {
-		if err := stx.Close(); err != nil {
-			klog.Errorf("stx.Close(): %v", err)
-		}
-	}()
-
-	r, err := stx.ExecContext(ctx, args...)
+	r, err := t.tx.ExecContext(ctx, insertSubtreesQuery(len(subtrees)), args...)
 	if err != nil {
 		klog.Warningf("Failed to set merkle subtrees: %s", err)
 		return err
@@ -386,7 +423,10 @@ func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtr
 func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	rev := t.writeRevision - 1
+	// Read the tiles as of the write revision, rather than the latest root, so
+	// that the nodes staged by earlier transactions of a split batch are kept.
+	// Otherwise nothing has been written at the write revision yet.
+	rev := t.writeRevision
 	return t.subtreeCache.SetNodes(nodes, t.getSubtreesAtRev(ctx, rev))
 }
 
@@ -35,14 +35,14 @@ import (
 const (
 	defaultSequenceIntervalSeconds = 60
 
-	selectTrees = "SELECT TreeId,TreeState,TreeType,DisplayName,Description,CreateTimeMillis,UpdateTimeMillis,MaxRootDurationMillis,Deleted,DeleteTimeMillis " +
+	selectTrees = "SELECT TreeId,TreeState,TreeType,DisplayName,Description,CreateTimeMillis,UpdateTimeMillis,MaxRootDurationMillis,Deleted,DeleteTimeMillis,Options " +
 		"FROM Trees"
 	selectNonDeletedTrees = selectTrees + " WHERE (Deleted IS NULL OR Deleted='false')"
 	selectTreeByID        = selectTrees + " WHERE TreeId=$1"
 
 	updateTreeSQL = "UPDATE Trees " +
-		"SET TreeState=$1,TreeType=$2,DisplayName=$3,Description=$4,UpdateTimeMillis=$5,MaxRootDurationMillis=$6 " +
-		"WHERE TreeId=$7"
+		"SET TreeState=$1,TreeType=$2,DisplayName=$3,Description=$4,UpdateTimeMillis=$5,MaxRootDurationMillis=$6,Options=$7 " +
+		"WHERE TreeId=$8"
 )
 
 // NewAdminStorage returns a PostgreSQL storage.AdminStorage implementation backed by DB.
@@ -188,10 +188,14 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, fmt.Errorf("could not parse MaxRootDuration: %w", err)
 	}
 	rootDuration := newTree.MaxRootDuration.AsDuration()
+	options, err := storage.MarshalTreeOptions(newTree)
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = t.tx.Exec(
 		ctx,
-		"INSERT INTO Trees(TreeId,TreeState,TreeType,DisplayName,Description,CreateTimeMillis,UpdateTimeMillis,MaxRootDurationMillis) VALUES($1,$2,$3,$4,$5,$6,$7,$8)",
+		"INSERT INTO Trees(TreeId,TreeState,TreeType,DisplayName,Description,CreateTimeMillis,UpdateTimeMillis,MaxRootDurationMillis,Options) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)",
 		newTree.TreeId,
 		newTree.TreeState.String(),
 		newTree.TreeType.String(),
@@ -200,6 +204,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		nowMillis,
 		nowMillis,
 		rootDuration/time.Millisecond,
+		options,
 	)
 	if err != nil {
 		return nil, err
@@ -243,6 +248,10 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		return nil, fmt.Errorf("could not parse MaxRootDuration: %w", err)
 	}
 	rootDuration := tree.MaxRootDuration.AsDuration()
+	options, err := storage.MarshalTreeOptions(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	if _, err = t.tx.Exec(
 		ctx,
@@ -253,6 +262,7 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		tree.Description,
 		nowMillis,
 		rootDuration/time.Millisecond,
+		options,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
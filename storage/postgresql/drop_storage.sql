@@ -7,10 +7,13 @@ DROP FUNCTION IF EXISTS add_sequenced_leaves;
 DROP TABLE IF EXISTS Unsequenced;
 DROP TABLE IF EXISTS Subtree;
 DROP TABLE IF EXISTS SequencedLeafData;
+DROP TABLE IF EXISTS IntegrationEvents;
 DROP TABLE IF EXISTS TreeHead;
 DROP TABLE IF EXISTS LeafData;
 DROP TABLE IF EXISTS TreeControl;
 DROP TABLE IF EXISTS Trees;
 
+DROP FUNCTION IF EXISTS leaf_identity_hash_prefix;
+
 DROP TYPE IF EXISTS TreeType;
 DROP TYPE IF EXISTS TreeState;
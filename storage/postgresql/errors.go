@@ -31,5 +31,11 @@ func postgresqlToGRPC(err error) error {
 	if postgresqlErr.Code == pgerrcode.DeadlockDetected {
 		return status.Errorf(codes.Aborted, "PostgreSQL: %v", postgresqlErr)
 	}
+	if postgresqlErr.Code == pgerrcode.ReadOnlySQLTransaction {
+		// The server is a replica, such as an Aurora writer which has been
+		// demoted by a failover, and the write can be retried once the
+		// connections reach the new writer.
+		return status.Errorf(codes.Unavailable, "PostgreSQL: %v", postgresqlErr)
+	}
 	return err
 }
@@ -21,11 +21,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
@@ -34,9 +36,9 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/transparency-dev/merkle/compact"
-	"github.com/transparency-dev/merkle/rfc6962"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/klog/v2"
 )
@@ -82,6 +84,15 @@ const (
 		" AND TreeState IN($3,$4)" +
 		" AND (Deleted IS NULL OR Deleted='false')"
 
+	insertIntegrationEventSQL = "INSERT INTO IntegrationEvents(TreeId,StartTimestampNanos,DurationNanos,BatchSize,TreeSize,RootHash,Signer) " +
+		"VALUES($1,$2,$3,$4,$5,$6,$7)"
+	deleteIntegrationEventsSQL = "DELETE FROM IntegrationEvents WHERE TreeId=$1 AND StartTimestampNanos<$2"
+	selectIntegrationEventsSQL = "SELECT StartTimestampNanos,DurationNanos,BatchSize,TreeSize,RootHash,Signer " +
+		"FROM IntegrationEvents " +
+		"WHERE TreeId=$1 AND StartTimestampNanos<$2 " +
+		"ORDER BY StartTimestampNanos DESC " +
+		"LIMIT $3"
+
 	selectLatestSignedLogRootSQL = "SELECT TreeHeadTimestamp,TreeSize,RootHash,RootSignature " +
 		"FROM TreeHead " +
 		"WHERE TreeId=$1 " +
@@ -100,6 +111,17 @@ const (
 		" INNER JOIN LeafData l ON (s.LeafIdentityHash=l.LeafIdentityHash AND s.TreeId=l.TreeId) " +
 		"WHERE s.MerkleLeafHash=ANY($1)" +
 		" AND l.TreeId=$2"
+	selectLeavesByIndicesSQL = "SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos " +
+		"FROM SequencedLeafData s" +
+		" INNER JOIN LeafData l ON (s.LeafIdentityHash=l.LeafIdentityHash AND s.TreeId=l.TreeId) " +
+		"WHERE s.SequenceNumber=ANY($1)" +
+		" AND s.TreeId=$2"
+	selectSequencedLeavesByLeafIdentityHashSQL = "SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos " +
+		"FROM SequencedLeafData s" +
+		" INNER JOIN LeafData l ON (s.LeafIdentityHash=l.LeafIdentityHash AND s.TreeId=l.TreeId) " +
+		"WHERE s.LeafIdentityHash=ANY($1)" +
+		" AND s.TreeId=$2" +
+		" AND l.LeafIdentityHashPrefix=ANY(" + leafIdentityHashPrefixesSQL + ")"
 	// TODO(robstradling): Per #1548, rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
 	dummyMerkleLeafHash = "00000000000000000000000000000000"
 	// This statement returns a dummy Merkle leaf hash value (which must be
@@ -109,12 +131,41 @@ const (
 		"FROM LeafData l" +
 		" LEFT JOIN SequencedLeafData s ON (l.LeafIdentityHash=s.LeafIdentityHash AND l.TreeId=s.TreeId) " +
 		"WHERE l.LeafIdentityHash=ANY($1)" +
-		" AND l.TreeId=$2"
+		" AND l.TreeId=$2" +
+		" AND l.LeafIdentityHashPrefix=ANY(" + leafIdentityHashPrefixesSQL + ")"
+	// leafIdentityHashPrefixesSQL buckets the LeafIdentityHash values in $1, so
+	// that the lookups by them can use the compact LeafDataIdentityPrefixIdx
+	// index rather than the primary key.
+	leafIdentityHashPrefixesSQL = "ARRAY(SELECT leaf_identity_hash_prefix(h) FROM unnest($1::BYTEA[]) h)"
 
 	// Same as above except with leaves ordered by sequence so we only incur this cost when necessary
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
 	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
 
+	selectSequencedLeavesByLeafIdentityHashOrderedBySequenceSQL = selectSequencedLeavesByLeafIdentityHashSQL + orderBySequenceNumberSQL
+
+	selectSignedLogRootBySizeSQL = "SELECT TreeHeadTimestamp,RootHash " +
+		"FROM TreeHead " +
+		"WHERE TreeId=$1 AND TreeSize=$2 " +
+		"ORDER BY TreeHeadTimestamp DESC " +
+		"LIMIT 1"
+	selectSignedLogRootByHashSQL = "SELECT TreeHeadTimestamp,TreeSize " +
+		"FROM TreeHead " +
+		"WHERE TreeId=$1 AND RootHash=$2 " +
+		"ORDER BY TreeHeadTimestamp DESC " +
+		"LIMIT 1"
+	selectSignedLogRootsSQL = "SELECT TreeHeadTimestamp,TreeSize,RootHash " +
+		"FROM TreeHead " +
+		"WHERE TreeId=$1 " +
+		"AND TreeHeadTimestamp>=$2 AND TreeHeadTimestamp<$3 AND TreeSize>=$4 AND TreeSize<$5 " +
+		"ORDER BY TreeHeadTimestamp " +
+		"LIMIT $6"
+
+	countUnsequencedSQL = "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=$1"
+	// The MIN and MAX of a prefix of the primary key are each read with a
+	// single index lookup.
+	selectSequencedIndexRangeSQL = "SELECT COALESCE(MIN(SequenceNumber),-1),COALESCE(MAX(SequenceNumber),-1) FROM SequencedLeafData WHERE TreeId=$1"
+
 	logIDLabel = "logid"
 )
 
@@ -151,25 +202,55 @@ type postgreSQLLogStorage struct {
 	*postgreSQLTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	// reader runs the snapshots if LogStorageOptions.ReaderDB is set.
+	reader *postgreSQLTreeStorage
+}
+
+// LogStorageOptions are optional behaviours of the PostgreSQL log storage.
+type LogStorageOptions struct {
+	// ReaderDB, if not nil, is the database on which SnapshotForTree runs
+	// read-only transactions, such as the reader endpoint of an Aurora
+	// cluster. It must be a replica of the database which the log storage
+	// writes to. The snapshots lag behind the writes by its replication lag.
+	ReaderDB *pgxpool.Pool
 }
 
 // NewLogStorage creates a storage.LogStorage instance for the specified PostgreSQL URL.
 // It assumes storage.AdminStorage is backed by the same PostgreSQL database as well.
 func NewLogStorage(db *pgxpool.Pool, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithOpts(db, mf, LogStorageOptions{})
+}
+
+// NewLogStorageWithOpts creates a storage.LogStorage instance like
+// NewLogStorage, with the given options.
+func NewLogStorageWithOpts(db *pgxpool.Pool, mf monitoring.MetricFactory, opts LogStorageOptions) storage.LogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
-	return &postgreSQLLogStorage{
+	m := &postgreSQLLogStorage{
 		admin:                 NewAdminStorage(db),
 		postgreSQLTreeStorage: newTreeStorage(db),
 		metricFactory:         mf,
 	}
+	if opts.ReaderDB != nil {
+		m.reader = newTreeStorage(opts.ReaderDB)
+	}
+	return m
 }
 
 func (m *postgreSQLLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return m.db.Ping(ctx)
 }
 
+// CountUnsequenced implements storage.LogStorage.
+func (m *postgreSQLLogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	var count int64
+	if err := m.db.QueryRow(ctx, countUnsequencedSQL, tree.TreeId).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (m *postgreSQLLogStorage) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	// Include logs that are DRAINING in the active list as we're still
 	// integrating leaves into them.
@@ -201,12 +282,21 @@ func (m *postgreSQLLogStorage) GetActiveLogIDs(ctx context.Context) ([]int64, er
 }
 
 func (m *postgreSQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
+	return m.beginInternalOn(ctx, m.postgreSQLTreeStorage, tree, pgx.TxOptions{})
+}
+
+// beginInternalOn begins a log transaction on the database of ts.
+func (m *postgreSQLLogStorage) beginInternalOn(ctx context.Context, ts *postgreSQLTreeStorage, tree *trillian.Tree, opts pgx.TxOptions) (*logTreeTX, error) {
 	once.Do(func() {
 		createMetrics(m.metricFactory)
 	})
 
-	stCache := cache.NewLogSubtreeCache(rfc6962.DefaultHasher)
-	ttx, err := m.beginTreeTx(ctx, tree, rfc6962.DefaultHasher.Size(), stCache)
+	hasher, err := hashers.ForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	stCache := cache.NewLogSubtreeCache(hasher)
+	ttx, err := ts.beginTreeTx(ctx, tree, hasher.Size(), stCache, opts)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -282,7 +372,13 @@ func (m *postgreSQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *tri
 }
 
 func (m *postgreSQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
-	tx, err := m.beginInternal(ctx, tree)
+	var tx *logTreeTX
+	var err error
+	if m.reader != nil {
+		tx, err = m.beginInternalOn(ctx, m.reader, tree, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	} else {
+		tx, err = m.beginInternal(ctx, tree)
+	}
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -664,7 +760,27 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		}
 	}
 	// TODO(robstradling): Further clip `count` to a safe upper bound like 64k.
+	return t.scanLeavesByRange(ctx, start, count)
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader.
+func (t *logTreeTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if treeSize := int64(t.root.TreeSize); start < treeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= TreeSize(%d)", start, treeSize)
+	}
+	return t.scanLeavesByRange(ctx, start, count)
+}
+
+// scanLeavesByRange reads the leaves with consecutive indices in
+// [start, start+count), stopping at the first missing index beyond the tree
+// size.
+func (t *logTreeTX) scanLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
 	rows, err := t.tx.Query(ctx, selectLeavesByRangeSQL, start, start+count, t.treeID)
 	if err != nil {
 		klog.Warningf("Failed to get leaves by range: %s", err)
@@ -730,6 +846,103 @@ func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, or
 	return t.getLeavesByHashInternal(ctx, leafHashes, query, "merkle")
 }
 
+// ListIntegrationEvents implements storage.IntegrationEventReader.
+func (t *logTreeTX) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	beforeNanos := int64(math.MaxInt64)
+	if !before.IsZero() {
+		beforeNanos = before.UnixNano()
+	}
+	rows, err := t.tx.Query(ctx, selectIntegrationEventsSQL, t.treeID, beforeNanos, limit)
+	if err != nil {
+		return nil, postgresqlToGRPC(err)
+	}
+	defer rows.Close()
+
+	var ret []*trillian.IntegrationEvent
+	for rows.Next() {
+		var startNanos, durationNanos, treeSize int64
+		event := &trillian.IntegrationEvent{}
+		if err := rows.Scan(&startNanos, &durationNanos, &event.BatchSize, &treeSize, &event.RootHash, &event.Signer); err != nil {
+			return nil, err
+		}
+		event.StartTime = timestamppb.New(time.Unix(0, startNanos))
+		event.Duration = durationpb.New(time.Duration(durationNanos))
+		event.TreeSize = uint64(treeSize)
+		ret = append(ret, event)
+	}
+	return ret, rows.Err()
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter.
+func (t *logTreeTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.tx.Exec(ctx, deleteIntegrationEventsSQL, t.treeID, cutoff.UnixNano()); err != nil {
+		return postgresqlToGRPC(err)
+	}
+	if _, err := t.tx.Exec(ctx, insertIntegrationEventSQL,
+		t.treeID,
+		event.GetStartTime().AsTime().UnixNano(),
+		event.GetDuration().AsDuration().Nanoseconds(),
+		event.BatchSize,
+		int64(event.TreeSize),
+		event.RootHash,
+		event.Signer); err != nil {
+		return postgresqlToGRPC(err)
+	}
+	return nil
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *logTreeTX) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.queryLeaves(ctx, selectLeavesByIndicesSQL, indices, "index")
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter.
+func (t *logTreeTX) CountUnsequenced(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var count int64
+	if err := t.tx.QueryRow(ctx, countUnsequencedSQL, t.treeID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader.
+func (t *logTreeTX) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var first, last int64
+	if err := t.tx.QueryRow(ctx, selectSequencedIndexRangeSQL, t.treeID).Scan(&first, &last); err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader, using the
+// SequencedLeafIdentityIdx index.
+func (t *logTreeTX) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	query := selectSequencedLeavesByLeafIdentityHashSQL
+	if orderBySequence {
+		query = selectSequencedLeavesByLeafIdentityHashOrderedBySequenceSQL
+	}
+
+	return t.getLeavesByHashInternal(ctx, identityHashes, query, "sequenced-leaf-identity")
+}
+
 // getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
 // as a slice of LogLeaf objects for convenience.  However, note that the
 // returned LogLeaf objects will not have a valid MerkleLeafHash, LeafIndex, or IntegrateTimestamp.
@@ -773,6 +986,101 @@ func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoo
 	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
 }
 
+// GetSignedLogRootBySize implements storage.RootHistoryReader.
+func (t *logTreeTX) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var timestamp int64
+	var rootHash []byte
+	if err := t.tx.QueryRow(ctx, selectSignedLogRootBySizeSQL, t.treeID, int64(treeSize)).Scan(&timestamp, &rootHash); err == pgx.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no root of size %d", treeSize)
+	} else if err != nil {
+		return nil, err
+	}
+	logRoot, err := (&types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		TreeSize:       treeSize,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader.
+func (t *logTreeTX) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var timestamp, treeSize int64
+	if err := t.tx.QueryRow(ctx, selectSignedLogRootByHashSQL, t.treeID, rootHash).Scan(&timestamp, &treeSize); err == pgx.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no root with hash %x", rootHash)
+	} else if err != nil {
+		return nil, err
+	}
+	logRoot, err := (&types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		TreeSize:       uint64(treeSize),
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader.
+func (t *logTreeTX) ListSignedLogRoots(ctx context.Context, r storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	args := append([]interface{}{t.treeID}, rootRangeArgs(r, limit)...)
+	rows, err := t.tx.Query(ctx, selectSignedLogRootsSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.SignedLogRoot
+	for rows.Next() {
+		var timestamp, treeSize int64
+		var rootHash []byte
+		if err := rows.Scan(&timestamp, &treeSize, &rootHash); err != nil {
+			return nil, err
+		}
+		logRoot, err := (&types.LogRootV1{
+			RootHash:       rootHash,
+			TimestampNanos: uint64(timestamp),
+			TreeSize:       uint64(treeSize),
+		}).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}
+	return ret, rows.Err()
+}
+
+// rootRangeArgs returns the bounds of r, and the limit, as the arguments of
+// selectSignedLogRootsSQL which follow the tree ID.
+func rootRangeArgs(r storage.RootRange, limit int) []interface{} {
+	start := func(v uint64) int64 {
+		if v > math.MaxInt64 {
+			return math.MaxInt64
+		}
+		return int64(v)
+	}
+	end := func(v uint64) int64 {
+		if v == 0 {
+			return math.MaxInt64
+		}
+		return start(v)
+	}
+	return []interface{}{start(r.StartTimestampNanos), end(r.EndTimestampNanos), start(r.StartTreeSize), end(r.EndTreeSize), limit}
+}
+
 func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -802,9 +1110,15 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 }
 
 func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, query string, desc string) ([]*trillian.LogLeaf, error) {
-	rows, err := t.tx.Query(ctx, query, leafHashes, t.treeID)
+	return t.queryLeaves(ctx, query, leafHashes, desc)
+}
+
+// queryLeaves runs query, a leaf-selection statement whose parameters are an
+// array of keys and the tree ID.
+func (t *logTreeTX) queryLeaves(ctx context.Context, query string, keys interface{}, desc string) ([]*trillian.LogLeaf, error) {
+	rows, err := t.tx.Query(ctx, query, keys, t.treeID)
 	if err != nil {
-		klog.Warningf("Query() %s hash = %v", desc, err)
+		klog.Warningf("Query() %s = %v", desc, err)
 		return nil, err
 	}
 	defer func() {
@@ -124,6 +124,15 @@ func TestLogSuite(t *testing.T) {
 	storagetest.RunLogStorageTests(t, storageFactory)
 }
 
+func TestCrashConsistency(t *testing.T) {
+	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		t.Cleanup(func() { cleanTestDB(DB) })
+		return NewLogStorage(DB, nil), NewAdminStorage(DB)
+	}
+
+	storagetest.RunCrashConsistencyTests(t, storageFactory)
+}
+
 func TestQueueDuplicateLeaf(t *testing.T) {
 	ctx := context.Background()
 	cleanTestDB(DB)
@@ -230,6 +239,19 @@ func TestQueueLeaves(t *testing.T) {
 	if leavesToInsert != count {
 		t.Fatalf("Expected %d unsequenced rows but got: %d", leavesToInsert, count)
 	}
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err := tx.(storage.UnsequencedCounter).CountUnsequenced(ctx)
+		if err != nil {
+			t.Fatalf("CountUnsequenced(): %v", err)
+		}
+		if got != leavesToInsert {
+			t.Errorf("CountUnsequenced()=%d, want %d", got, leavesToInsert)
+		}
+		return nil
+	})
+	if got, err := s.CountUnsequenced(ctx, tree); err != nil || got != leavesToInsert {
+		t.Errorf("LogStorage.CountUnsequenced()=%d, %v, want %d", got, err, leavesToInsert)
+	}
 
 	// Additional check on timestamp being set correctly in the database
 	var queueTimestamp int64
@@ -461,6 +483,41 @@ func TestGetLeavesByHash(t *testing.T) {
 	})
 }
 
+func TestGetLeavesByIdentityHash(t *testing.T) {
+	ctx := context.Background()
+
+	// Create fake leaf as if it had been sequenced
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	data := []byte("some data")
+	createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, data, someExtraData, sequenceNumber, t)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		r := tx.(storage.IdentityHashReader)
+		leaves, err := r.GetLeavesByIdentityHash(ctx, [][]byte{dummyRawHash}, true)
+		if err != nil {
+			t.Fatalf("Unexpected error getting leaf by identity hash: %v", err)
+		}
+		if len(leaves) != 1 {
+			t.Fatalf("Got %d leaves but expected one", len(leaves))
+		}
+		checkLeafContents(leaves[0], sequenceNumber, dummyRawHash, dummyHash, data, someExtraData, t)
+
+		// The Merkle leaf hash is not an identity hash.
+		leaves, err = r.GetLeavesByIdentityHash(ctx, [][]byte{dummyHash}, false)
+		if err != nil {
+			t.Fatalf("Unexpected error getting leaf by identity hash: %v", err)
+		}
+		if len(leaves) != 0 {
+			t.Errorf("Got %d leaves but expected none", len(leaves))
+		}
+		return nil
+	})
+}
+
 func TestGetLeavesByHashBigBatch(t *testing.T) {
 	ctx := context.Background()
 
@@ -491,6 +548,28 @@ func TestGetLeavesByHashBigBatch(t *testing.T) {
 	})
 }
 
+func TestLeafIdentityHashPrefix(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		hash []byte
+		want int64
+	}{
+		{hash: []byte{}, want: 0},
+		{hash: []byte{0x01}, want: 0x0100000000000000},
+		{hash: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, want: 0x0102030405060708},
+		{hash: []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xaa}, want: 0x7fffffffffffffff},
+		{hash: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}, want: -2},
+	} {
+		var got int64
+		if err := DB.QueryRow(ctx, "SELECT leaf_identity_hash_prefix($1)", test.hash).Scan(&got); err != nil {
+			t.Fatalf("leaf_identity_hash_prefix(%x): %v", test.hash, err)
+		}
+		if got != test.want {
+			t.Errorf("leaf_identity_hash_prefix(%x) = %#x, want %#x", test.hash, got, test.want)
+		}
+	}
+}
+
 func TestGetLeafDataByIdentityHash(t *testing.T) {
 	ctx := context.Background()
 
@@ -566,6 +645,71 @@ func leavesEquivalent(t *testing.T, gotLeaves, wantLeaves []*trillian.LogLeaf) {
 
 // -----------------------------------------------------------------------------
 
+func TestGetLeavesByIndices(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	const leafCount = 10
+	for i := int64(0); i < leafCount; i++ {
+		data := []byte(fmt.Sprintf("data %d", i))
+		hash := sha256.Sum256(data)
+		createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+	}
+	mustSignAndStoreLogRoot(ctx, t, s, tree, leafCount)
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.(storage.IndexedLeafReader).GetLeavesByIndices(ctx, []int64{7, 2, 9, 42})
+		if err != nil {
+			t.Fatalf("GetLeavesByIndices(): %v", err)
+		}
+		var got []int64
+		for _, leaf := range leaves {
+			got = append(got, leaf.LeafIndex)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if want := []int64{2, 7, 9}; !cmp.Equal(got, want) {
+			t.Errorf("GetLeavesByIndices() returned indices %v, want %v", got, want)
+		}
+		return nil
+	})
+}
+
+func TestGetSequencedIndexRange(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	checkRange := func(wantFirst, wantLast int64) {
+		t.Helper()
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			first, last, err := tx.(storage.SequencedIndexReader).GetSequencedIndexRange(ctx)
+			if err != nil {
+				t.Fatalf("GetSequencedIndexRange(): %v", err)
+			}
+			if first != wantFirst || last != wantLast {
+				t.Errorf("GetSequencedIndexRange()=%d, %d, want %d, %d", first, last, wantFirst, wantLast)
+			}
+			return nil
+		})
+	}
+	checkRange(-1, -1)
+
+	for _, i := range []int64{3, 4, 9} {
+		data := []byte(fmt.Sprintf("data %d", i))
+		hash := sha256.Sum256(data)
+		createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+	}
+	checkRange(3, 9)
+}
+
 func TestLatestSignedRootNoneWritten(t *testing.T) {
 	ctx := context.Background()
 
@@ -843,3 +987,58 @@ func leafInBatch(leaf *trillian.LogLeaf, batch []*trillian.LogLeaf) bool {
 
 	return false
 }
+
+// BenchmarkQueueLeavesDedup queues batches of leaves into a tree of 100k
+// leaves, half of which are duplicates, and looks them up by identity hash.
+func BenchmarkQueueLeavesDedup(b *testing.B) {
+	ctx := context.Background()
+	const leafCount, batchSize = 100000, 1000
+
+	cleanTestDB(DB)
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(DB), testonly.LogTree)
+	if err != nil {
+		b.Fatalf("CreateTree(): %v", err)
+	}
+	s := NewLogStorage(DB, nil)
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeLogRoot(ctx, tx, 0, []byte{0})
+	}); err != nil {
+		b.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+	for start := int64(0); start < leafCount; start += batchSize {
+		if _, err := s.QueueLeaves(ctx, tree, createTestLeaves(batchSize, start), fakeQueueTime); err != nil {
+			b.Fatalf("QueueLeaves(): %v", err)
+		}
+	}
+
+	b.Run("queue", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			// Half of the batch is already queued, and half is new.
+			start := int64(leafCount - batchSize/2 + n*batchSize)
+			if _, err := s.QueueLeaves(ctx, tree, createTestLeaves(batchSize, start), fakeQueueTime); err != nil {
+				b.Fatalf("QueueLeaves(): %v", err)
+			}
+		}
+	})
+	b.Run("lookup", func(b *testing.B) {
+		leaves := createTestLeaves(batchSize, leafCount/2)
+		hashes := make([][]byte, 0, len(leaves))
+		for _, l := range leaves {
+			hashes = append(hashes, l.LeafIdentityHash)
+		}
+		for n := 0; n < b.N; n++ {
+			if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+				got, err := tx.(*logTreeTX).getLeafDataByIdentityHash(ctx, hashes)
+				if err != nil {
+					return err
+				}
+				if len(got) != batchSize {
+					return fmt.Errorf("got %d leaves, want %d", len(got), batchSize)
+				}
+				return nil
+			}); err != nil {
+				b.Fatalf("ReadWriteTransaction(): %v", err)
+			}
+		}
+	})
+}
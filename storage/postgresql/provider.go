@@ -15,17 +15,31 @@
 package postgresql
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/dbsecret"
+	"github.com/google/trillian/storage/postgresql/replication"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"k8s.io/klog/v2"
 )
 
 var (
-	postgreSQLURI = flag.String("postgresql_uri", "postgresql:///defaultdb?host=localhost&user=test", "Connection URI for PostgreSQL database")
+	postgreSQLURI    = flag.String("postgresql_uri", "postgresql:///defaultdb?host=localhost&user=test", "Connection URI for PostgreSQL database")
+	publishSequenced = flag.Bool("postgresql_publish_sequenced_leaves", false, "If true, create a logical replication publication of the SequencedLeafData table unless it already exists")
+	createSchema     = flag.Bool("postgresql_create_schema_if_missing", false, "If true, the storage schema of this version of Trillian is created when the database has no Trees table. Meant for test and development environments")
+	warmupConns      = flag.Int("postgresql_warmup_conns", 0, "Number of database connections to open and check before the server reports itself as ready, capped at the pool_max_conns of the connection pool")
+	readerURI        = flag.String("postgresql_reader_uri", "", "Connection URI for a read-only replica of the PostgreSQL database, such as the reader endpoint of an Aurora cluster. If set, log reads which don't write are run on it, and may lag behind the writes")
+	awsIAMRegion     = flag.String("postgresql_aws_iam_auth_region", "", "If set, connections authenticate to an Amazon RDS or Aurora database with IAM database authentication tokens for this AWS region, in place of the password in --postgresql_uri and --postgresql_reader_uri. The connections need TLS")
+	cloudSQLIAMAuth  = flag.Bool("postgresql_cloudsql_iam_auth", false, "If true, connections authenticate to a Google Cloud SQL database with IAM database authentication, using the access tokens of the Application Default Credentials in place of the password in --postgresql_uri and --postgresql_reader_uri. The connections need TLS")
+	passwordSecret   = flag.String("postgresql_password_secret", "", "Reference to a secret holding the database password, which is used in place of the one in --postgresql_uri and --postgresql_reader_uri, e.g. file:/run/secrets/db-password, vault:secret/data/trillian#password, awssm:trillian-db#password or gcpsm:projects/p/secrets/trillian-db/versions/latest. See the dbsecret package")
+	secretRefresh    = flag.Duration("postgresql_password_secret_refresh", time.Minute, "How long a password fetched from --postgresql_password_secret is used for new connections before it is fetched again")
 
 	postgresqlMu              sync.Mutex
 	postgresqlErr             error
@@ -51,7 +65,10 @@ func init() {
 
 type postgresqlProvider struct {
 	db *pgxpool.Pool
-	mf monitoring.MetricFactory
+	// reader is the --postgresql_reader_uri database, or nil.
+	reader *pgxpool.Pool
+	mf     monitoring.MetricFactory
+	stats  *poolExporter
 }
 
 func newPostgreSQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
@@ -62,9 +79,27 @@ func newPostgreSQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider
 		if err != nil {
 			return nil, err
 		}
+		if *createSchema {
+			if _, err := CreateSchemaIfMissing(context.Background(), db); err != nil {
+				return nil, err
+			}
+		}
+		if *publishSequenced {
+			if err := replication.CreatePublication(context.Background(), db); err != nil {
+				return nil, err
+			}
+		}
+		var reader *pgxpool.Pool
+		if *readerURI != "" {
+			if reader, err = openDatabase(*readerURI); err != nil {
+				return nil, err
+			}
+		}
 		postgresqlStorageInstance = &postgresqlProvider{
-			db: db,
-			mf: mf,
+			db:     db,
+			reader: reader,
+			mf:     mf,
+			stats:  newPoolExporter(mf),
 		}
 	}
 	return postgresqlStorageInstance, nil
@@ -76,7 +111,7 @@ func getPostgreSQLDatabaseLocked() (*pgxpool.Pool, error) {
 	if postgresqlDB != nil || postgresqlErr != nil {
 		return postgresqlDB, postgresqlErr
 	}
-	db, err := OpenDB(*postgreSQLURI)
+	db, err := openDatabase(*postgreSQLURI)
 	if err != nil {
 		postgresqlErr = err
 		return nil, err
@@ -85,8 +120,30 @@ func getPostgreSQLDatabaseLocked() (*pgxpool.Pool, error) {
 	return db, nil
 }
 
+// openDatabase opens the database at dbURL, authenticating as set by the
+// flags.
+func openDatabase(dbURL string) (*pgxpool.Pool, error) {
+	switch {
+	case *awsIAMRegion != "" && *cloudSQLIAMAuth:
+		return nil, errors.New("--postgresql_aws_iam_auth_region and --postgresql_cloudsql_iam_auth can't both be set")
+	case *passwordSecret != "" && (*awsIAMRegion != "" || *cloudSQLIAMAuth):
+		return nil, errors.New("--postgresql_password_secret can't be set with IAM database authentication")
+	case *passwordSecret != "":
+		secret, err := dbsecret.Open(context.Background(), *passwordSecret, *secretRefresh)
+		if err != nil {
+			return nil, err
+		}
+		return OpenDBWithPasswordFunc(dbURL, secret.Value)
+	case *awsIAMRegion != "":
+		return OpenDBWithIAMAuth(dbURL, *awsIAMRegion)
+	case *cloudSQLIAMAuth:
+		return OpenDBWithCloudSQLIAMAuth(dbURL)
+	}
+	return OpenDB(dbURL)
+}
+
 func (s *postgresqlProvider) LogStorage() storage.LogStorage {
-	return NewLogStorage(s.db, s.mf)
+	return NewLogStorageWithOpts(s.db, s.mf, LogStorageOptions{ReaderDB: s.reader})
 }
 
 func (s *postgresqlProvider) AdminStorage() storage.AdminStorage {
@@ -94,6 +151,46 @@ func (s *postgresqlProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *postgresqlProvider) Close() error {
+	if s.reader != nil {
+		s.reader.Close()
+	}
 	s.db.Close()
 	return nil
 }
+
+// CheckSchema implements storage.SchemaChecker.
+func (s *postgresqlProvider) CheckSchema(ctx context.Context) error {
+	return CheckSchema(ctx, s.db)
+}
+
+// ExportStats implements storage.StatsExporter. It exports the statistics of
+// the connection pool.
+func (s *postgresqlProvider) ExportStats() {
+	s.stats.update(newPoolStat(s.db.Stat()))
+}
+
+// Warmup implements storage.Warmer. It opens and checks up to
+// --postgresql_warmup_conns connections, and then returns them to the
+// connection pool. The connections are held until all of them are open, so
+// that the pool doesn't hand out the same connection twice.
+func (s *postgresqlProvider) Warmup(ctx context.Context) error {
+	n := min(*warmupConns, int(s.db.Config().MaxConns))
+	conns := make([]*pgxpool.Conn, 0, n)
+	defer func() {
+		for _, c := range conns {
+			c.Release()
+		}
+	}()
+	for len(conns) < n {
+		c, err := s.db.Acquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open connection %d of %d: %v", len(conns)+1, n, err)
+		}
+		conns = append(conns, c)
+		if err := c.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to ping connection %d of %d: %v", len(conns), n, err)
+		}
+	}
+	klog.Infof("Opened %d database connections", n)
+	return nil
+}
@@ -44,3 +44,14 @@ func TestPostgreSQLStorageProviderErrorPersistence(t *testing.T) {
 		t.Fatalf("Expected second call to 'storage.NewProvider' to fail with %q, instead got: %q", err1, err2)
 	}
 }
+
+func TestOpenDBWithIAMAuthNeedsTLS(t *testing.T) {
+	for _, dbURL := range []string{
+		"postgresql://trillian@db.example.com:5432/test?sslmode=disable",
+		"postgresql://trillian@db.example.com:5432/test?sslmode=prefer",
+	} {
+		if _, err := OpenDBWithIAMAuth(dbURL, "eu-west-1"); err == nil {
+			t.Errorf("OpenDBWithIAMAuth(%q) succeeded, want error", dbURL)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replication allows downstream consumers, such as analytics or
+// indexing pipelines, to tail the leaves integrated into Trillian logs stored
+// in PostgreSQL using logical replication, rather than by polling
+// GetLeavesByRange.
+//
+// Logical replication requires the server to be configured with
+// wal_level = logical. The SequencedLeafData table is then published with:
+//
+//	CREATE PUBLICATION trillian_sequenced_leaves FOR TABLE SequencedLeafData;
+//
+// which CreatePublication does if necessary. Setting the
+// --postgresql_publish_sequenced_leaves flag makes the postgresql storage
+// provider call it when it starts.
+//
+// Any logical replication client can subscribe to the publication using the
+// pgoutput plugin, and Decoder decodes the messages it sends. For simple
+// consumers, Consumer reads changes from a replication slot using ordinary
+// SQL queries, so no replication connection is required.
+package replication
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PublicationName is the name of the publication of the SequencedLeafData
+// table.
+const PublicationName = "trillian_sequenced_leaves"
+
+// SequencedLeaf describes a leaf which has been integrated into a log.
+type SequencedLeaf struct {
+	TreeID           int64
+	LeafIndex        int64
+	LeafIdentityHash []byte
+	MerkleLeafHash   []byte
+	IntegrateTime    time.Time
+}
+
+// CreatePublication creates the publication of the SequencedLeafData table,
+// unless it already exists.
+func CreatePublication(ctx context.Context, db *pgxpool.Pool) error {
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = $1)", PublicationName).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check for publication %s: %v", PublicationName, err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := db.Exec(ctx, "CREATE PUBLICATION "+PublicationName+" FOR TABLE SequencedLeafData"); err != nil {
+		return fmt.Errorf("failed to create publication %s: %v", PublicationName, err)
+	}
+	return nil
+}
+
+// Consumer reads sequenced leaves from a logical replication slot using the
+// pgoutput plugin. Changes are consumed as they are read, so a Consumer
+// provides at-most-once delivery; callers which must not miss leaves should
+// persist them before calling Next again, or use a replication connection.
+type Consumer struct {
+	db      *pgxpool.Pool
+	slot    string
+	decoder *Decoder
+}
+
+// NewConsumer returns a Consumer which reads from the named replication slot,
+// creating the slot if it doesn't exist. Leaves integrated before the slot is
+// created are not returned.
+func NewConsumer(ctx context.Context, db *pgxpool.Pool, slot string) (*Consumer, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)", slot).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check for replication slot %q: %v", slot, err)
+	}
+	if !exists {
+		if _, err := db.Exec(ctx, "SELECT pg_create_logical_replication_slot($1, 'pgoutput')", slot); err != nil {
+			return nil, fmt.Errorf("failed to create replication slot %q: %v", slot, err)
+		}
+	}
+	return &Consumer{db: db, slot: slot, decoder: NewDecoder()}, nil
+}
+
+// Next consumes up to limit changes from the replication slot, and returns
+// the leaves they contain. It returns an empty slice if there are no pending
+// changes.
+func (c *Consumer) Next(ctx context.Context, limit int) ([]*SequencedLeaf, error) {
+	rows, err := c.db.Query(ctx, `SELECT data FROM pg_logical_slot_get_binary_changes($1, NULL, $2,
+		'proto_version', '1', 'publication_names', '`+PublicationName+`')`, c.slot, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication slot %q: %v", c.slot, err)
+	}
+	msgs, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication slot %q: %v", c.slot, err)
+	}
+	leaves := make([]*SequencedLeaf, 0, len(msgs))
+	for _, msg := range msgs {
+		leaf, err := c.decoder.Decode(msg)
+		if err != nil {
+			return nil, err
+		}
+		if leaf != nil {
+			leaves = append(leaves, leaf)
+		}
+	}
+	return leaves, nil
+}
+
+// relation describes a table, as announced by a pgoutput Relation message.
+type relation struct {
+	name    string
+	columns []string
+}
+
+// Decoder decodes messages sent by the pgoutput plugin (protocol version 1,
+// text format) for the publication of the SequencedLeafData table. It tracks
+// the Relation messages which precede changes, so a single Decoder must be
+// used for all of a replication stream's messages, in order.
+type Decoder struct {
+	relations map[uint32]relation
+}
+
+// NewDecoder returns a new Decoder.
+func NewDecoder() *Decoder {
+	return &Decoder{relations: make(map[uint32]relation)}
+}
+
+// Decode decodes a single pgoutput message, returning the leaf it describes
+// if it is an insert into the SequencedLeafData table, and nil otherwise.
+func (d *Decoder) Decode(msg []byte) (*SequencedLeaf, error) {
+	if len(msg) == 0 {
+		return nil, errors.New("empty pgoutput message")
+	}
+	r := &reader{buf: msg[1:]}
+	switch msg[0] {
+	case 'R':
+		id := r.uint32()
+		_ = r.string() // Namespace.
+		rel := relation{name: strings.ToLower(r.string())}
+		_ = r.byte() // Replica identity setting.
+		n := int(r.uint16())
+		for i := 0; i < n && r.err == nil; i++ {
+			_ = r.byte() // Flags.
+			rel.columns = append(rel.columns, strings.ToLower(r.string()))
+			_ = r.uint32() // Type OID.
+			_ = r.uint32() // Type modifier.
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("invalid pgoutput relation message: %v", r.err)
+		}
+		d.relations[id] = rel
+		return nil, nil
+	case 'I':
+		id := r.uint32()
+		if kind := r.byte(); r.err == nil && kind != 'N' {
+			return nil, fmt.Errorf("unexpected pgoutput tuple kind %q", kind)
+		}
+		rel, ok := d.relations[id]
+		if !ok && r.err == nil {
+			return nil, fmt.Errorf("pgoutput insert into unknown relation %d", id)
+		}
+		values := r.tuple()
+		if r.err != nil {
+			return nil, fmt.Errorf("invalid pgoutput insert message: %v", r.err)
+		}
+		if rel.name != "sequencedleafdata" {
+			return nil, nil
+		}
+		return leafFromTuple(rel.columns, values)
+	default:
+		// Begin, commit and other messages carry nothing of interest.
+		return nil, nil
+	}
+}
+
+func leafFromTuple(columns []string, values []*string) (*SequencedLeaf, error) {
+	if len(columns) != len(values) {
+		return nil, fmt.Errorf("pgoutput insert has %d values for %d columns", len(values), len(columns))
+	}
+	leaf := &SequencedLeaf{}
+	for i, col := range columns {
+		if values[i] == nil {
+			continue
+		}
+		v := *values[i]
+		var err error
+		switch col {
+		case "treeid":
+			leaf.TreeID, err = strconv.ParseInt(v, 10, 64)
+		case "sequencenumber":
+			leaf.LeafIndex, err = strconv.ParseInt(v, 10, 64)
+		case "leafidentityhash":
+			leaf.LeafIdentityHash, err = decodeBytea(v)
+		case "merkleleafhash":
+			leaf.MerkleLeafHash, err = decodeBytea(v)
+		case "integratetimestampnanos":
+			var nanos int64
+			nanos, err = strconv.ParseInt(v, 10, 64)
+			leaf.IntegrateTime = time.Unix(0, nanos).UTC()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for column %s: %v", v, col, err)
+		}
+	}
+	return leaf, nil
+}
+
+// decodeBytea decodes a BYTEA value in the hex text format.
+func decodeBytea(s string) ([]byte, error) {
+	h, ok := strings.CutPrefix(s, `\x`)
+	if !ok {
+		return nil, errors.New("not in hex format")
+	}
+	return hex.DecodeString(h)
+}
+
+// reader reads the fields of a pgoutput message. After the first error, all
+// reads return zero values and the error is kept in err.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.buf) < n {
+		r.err = errors.New("message truncated")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// string reads a NUL-terminated string.
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := strings.IndexByte(string(r.buf), 0)
+	if i < 0 {
+		r.err = errors.New("unterminated string")
+		return ""
+	}
+	s := string(r.buf[:i])
+	r.buf = r.buf[i+1:]
+	return s
+}
+
+// tuple reads TupleData, returning nil for NULL and unchanged TOASTed values.
+func (r *reader) tuple() []*string {
+	n := int(r.uint16())
+	values := make([]*string, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		switch kind := r.byte(); kind {
+		case 'n', 'u':
+			values = append(values, nil)
+		case 't':
+			v := string(r.next(int(r.uint32())))
+			values = append(values, &v)
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("unsupported tuple column kind %q", kind)
+			}
+		}
+	}
+	return values
+}
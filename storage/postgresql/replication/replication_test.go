@@ -0,0 +1,110 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// msgBuilder builds pgoutput messages for tests.
+type msgBuilder []byte
+
+func (b msgBuilder) byte(v byte) msgBuilder { return append(b, v) }
+func (b msgBuilder) uint16(v uint16) msgBuilder {
+	return binary.BigEndian.AppendUint16(b, v)
+}
+func (b msgBuilder) uint32(v uint32) msgBuilder {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+func (b msgBuilder) string(v string) msgBuilder { return append(append(b, v...), 0) }
+func (b msgBuilder) text(v string) msgBuilder {
+	return append(b.byte('t').uint32(uint32(len(v))), v...)
+}
+
+func relationMsg(id uint32, name string, columns ...string) []byte {
+	b := msgBuilder{'R'}.uint32(id).string("public").string(name).byte('d').uint16(uint16(len(columns)))
+	for _, c := range columns {
+		b = b.byte(0).string(c).uint32(20).uint32(0xffffffff)
+	}
+	return b
+}
+
+var leafColumns = []string{"treeid", "sequencenumber", "leafidentityhash", "merkleleafhash", "integratetimestampnanos"}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder()
+	for _, msg := range [][]byte{
+		{'B', 0, 0},
+		relationMsg(1, "sequencedleafdata", leafColumns...),
+		relationMsg(2, "leafdata", "treeid"),
+	} {
+		if leaf, err := d.Decode(msg); err != nil || leaf != nil {
+			t.Fatalf("Decode(%x)=%v, %v; want nil, nil", msg, leaf, err)
+		}
+	}
+
+	insert := msgBuilder{'I'}.uint32(1).byte('N').uint16(5).
+		text("42").text("7").text(`\x0102`).text(`\xa0b0`).text("1000")
+	got, err := d.Decode(insert)
+	if err != nil {
+		t.Fatalf("Decode(insert): %v", err)
+	}
+	want := &SequencedLeaf{
+		TreeID:           42,
+		LeafIndex:        7,
+		LeafIdentityHash: []byte{1, 2},
+		MerkleLeafHash:   []byte{0xa0, 0xb0},
+		IntegrateTime:    time.Unix(0, 1000).UTC(),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Decode(insert) diff (-got +want):\n%s", diff)
+	}
+
+	// Inserts into other tables are ignored.
+	other := msgBuilder{'I'}.uint32(2).byte('N').uint16(1).text("42")
+	if leaf, err := d.Decode(other); err != nil || leaf != nil {
+		t.Errorf("Decode(other)=%v, %v; want nil, nil", leaf, err)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		msg  []byte
+	}{
+		{desc: "empty", msg: nil},
+		{desc: "unknown-relation", msg: msgBuilder{'I'}.uint32(9).byte('N').uint16(1).text("42")},
+		{desc: "truncated-relation", msg: relationMsg(1, "sequencedleafdata", leafColumns...)[:12]},
+		{desc: "truncated-insert", msg: msgBuilder{'I'}.uint32(1).byte('N').uint16(5).text("42")},
+		{desc: "bad-bytea", msg: msgBuilder{'I'}.uint32(1).byte('N').uint16(5).
+			text("42").text("7").text("0102").text(`\xa0b0`).text("1000")},
+		{desc: "bad-int", msg: msgBuilder{'I'}.uint32(1).byte('N').uint16(5).
+			text("x").text("7").text(`\x0102`).text(`\xa0b0`).text("1000")},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			d := NewDecoder()
+			if _, err := d.Decode(relationMsg(1, "SequencedLeafData", leafColumns...)); err != nil {
+				t.Fatalf("Decode(relation): %v", err)
+			}
+			if _, err := d.Decode(test.msg); err == nil {
+				t.Errorf("Decode(%x) returned nil error", test.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	_ "embed" // For the schema.
+	"fmt"
+
+	"github.com/google/trillian/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"k8s.io/klog/v2"
+)
+
+// schemaSQL is the schema of the storage for this version of Trillian.
+//
+//go:embed schema/storage.sql
+var schemaSQL string
+
+// CreateSchemaIfMissing applies the schema in schema/storage.sql, which is
+// the one of this version of Trillian, to db unless it already has a Trees
+// table. It returns whether the schema was applied.
+//
+// It is meant for test and development environments starting from an empty
+// database. A database with an older schema is left alone, as it needs the
+// changes described in the CHANGELOG instead.
+func CreateSchemaIfMissing(ctx context.Context, db *pgxpool.Pool) (bool, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT to_regclass('trees') IS NOT NULL").Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look for the Trees table: %v", err)
+	}
+	if exists {
+		return false, nil
+	}
+	// Without arguments, the script is sent with the simple query protocol,
+	// which runs all of its statements in one transaction, so a failure
+	// leaves the database empty.
+	if _, err := db.Exec(ctx, schemaSQL); err != nil {
+		return false, fmt.Errorf("failed to create schema: %v", err)
+	}
+	klog.Info("Created the PostgreSQL storage schema")
+	return true, nil
+}
+
+// CheckSchema returns an error naming the tables and columns of the schema
+// of this version of Trillian which db lacks.
+func CheckSchema(ctx context.Context, db *pgxpool.Pool) error {
+	rows, err := db.Query(ctx, "SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()")
+	if err != nil {
+		return fmt.Errorf("failed to list the columns of the database: %v", err)
+	}
+	defer rows.Close()
+	have := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return err
+		}
+		have[table] = append(have[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return storage.MissingColumns(storage.SchemaColumns(schemaSQL), have)
+}
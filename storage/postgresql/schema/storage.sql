@@ -25,6 +25,7 @@ CREATE TABLE IF NOT EXISTS Trees(
   MaxRootDurationMillis BIGINT NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  Options               BYTEA, -- Serialized per-tree options, see storage.MarshalTreeOptions.
   PRIMARY KEY(TreeId)
 );
 
@@ -70,6 +71,17 @@ CREATE TABLE IF NOT EXISTS TreeHead(
 -- optimize physical storage layout. Most engines allow multiple nulls in a
 -- unique index but some may not.
 
+-- Returns the first 8 bytes of a LeafIdentityHash as a big-endian integer,
+-- zero-padded if the hash is shorter. It buckets the leaves for the
+-- deduplication lookups, whose index on this is a fraction of the size of the
+-- primary key, so stays in memory for far larger trees.
+CREATE OR REPLACE FUNCTION leaf_identity_hash_prefix(
+  hash bytea
+) RETURNS bigint
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+  SELECT ('x' || rpad(encode(substring(hash FROM 1 FOR 8), 'hex'), 16, '0'))::bit(64)::bigint
+$$;
+
 -- A leaf that has not been sequenced has a row in this table. If duplicate leaves
 -- are allowed they will all reference this row.
 CREATE TABLE IF NOT EXISTS LeafData(
@@ -86,6 +98,8 @@ CREATE TABLE IF NOT EXISTS LeafData(
   ExtraData            BYTEA,
   -- The timestamp from when this leaf data was first queued for inclusion.
   QueueTimestampNanos  BIGINT NOT NULL,
+  -- The bucket of LeafIdentityHash used for deduplication lookups.
+  LeafIdentityHashPrefix BIGINT GENERATED ALWAYS AS (leaf_identity_hash_prefix(LeafIdentityHash)) STORED,
   PRIMARY KEY(TreeId, LeafIdentityHash),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
   CHECK (length(LeafIdentityHash) <= 255)
@@ -116,12 +130,34 @@ CREATE TABLE IF NOT EXISTS SequencedLeafData(
   CHECK (length(MerkleLeafHash) <= 255)
 );
 
+-- Lookups by LeafIdentityHash narrow the candidates down with this index, and
+-- only check the full hash of the rows that it finds, which are rare for the
+-- deduplication of new leaves.
+CREATE INDEX LeafDataIdentityPrefixIdx
+  ON LeafData(TreeId, LeafIdentityHashPrefix);
+
 CREATE INDEX SequencedLeafMerkleIdx
   ON SequencedLeafData(TreeId, MerkleLeafHash);
 
 CREATE INDEX SequencedLeafIdentityIdx
   ON SequencedLeafData(TreeId, LeafIdentityHash);
 
+-- Recent integration runs of the signer, as listed by the
+-- ListIntegrationEvents admin RPC. Events older than the retention period of
+-- the signer are deleted as new ones are added.
+CREATE TABLE IF NOT EXISTS IntegrationEvents(
+  TreeId               BIGINT NOT NULL,
+  StartTimestampNanos  BIGINT NOT NULL,
+  DurationNanos        BIGINT NOT NULL,
+  BatchSize            BIGINT NOT NULL,
+  TreeSize             BIGINT NOT NULL,
+  RootHash             BYTEA NOT NULL,
+  Signer               TEXT NOT NULL,
+  PRIMARY KEY(TreeId, StartTimestampNanos),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  CHECK (length(RootHash) <= 255)
+);
+
 CREATE TABLE IF NOT EXISTS Unsequenced(
   TreeId               BIGINT NOT NULL,
   -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
@@ -175,6 +211,7 @@ BEGIN
     SET IsDuplicate = TRUE
     FROM LeafData l
     WHERE t.TreeId = l.TreeId
+      AND l.LeafIdentityHashPrefix = leaf_identity_hash_prefix(t.LeafIdentityHash)
       AND t.LeafIdentityHash = l.LeafIdentityHash;
   INSERT INTO LeafData (TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos)
     SELECT TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos
@@ -199,6 +236,7 @@ BEGIN
     SET IsDuplicateLeafData = TRUE
     FROM LeafData l
     WHERE t.TreeId = l.TreeId
+      AND l.LeafIdentityHashPrefix = leaf_identity_hash_prefix(t.LeafIdentityHash)
       AND t.LeafIdentityHash = l.LeafIdentityHash;
   UPDATE TempAddSequencedLeaves t
     SET IsDuplicateSequencedLeafData = TRUE
@@ -0,0 +1,43 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	testdb "github.com/google/trillian/storage/postgresql/testdbpgx"
+)
+
+func TestCreateSchemaIfMissing(t *testing.T) {
+	ctx := context.Background()
+	db, done, err := testdb.NewEmptyDB(ctx, testdb.DriverPostgreSQL)
+	if err != nil {
+		t.Fatalf("NewEmptyDB(): %v", err)
+	}
+	defer done(ctx)
+
+	if created, err := CreateSchemaIfMissing(ctx, db); err != nil || !created {
+		t.Fatalf("CreateSchemaIfMissing() on empty database = %v, %v; want true, nil", created, err)
+	}
+	var n int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM Trees").Scan(&n); err != nil || n != 0 {
+		t.Errorf("Trees has %d rows, %v; want 0, nil", n, err)
+	}
+	// The existing schema must be left alone.
+	if created, err := CreateSchemaIfMissing(ctx, db); err != nil || created {
+		t.Errorf("CreateSchemaIfMissing() on existing schema = %v, %v; want false, nil", created, err)
+	}
+}
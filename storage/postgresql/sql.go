@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -56,6 +57,7 @@ func readTree(r row) (*trillian.Tree, error) {
 	var displayName, description sql.NullString
 	var deleted sql.NullBool
 	var deleteMillis sql.NullInt64
+	var options []byte
 	err := r.Scan(
 		&tree.TreeId,
 		&treeState,
@@ -67,6 +69,7 @@ func readTree(r row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&options,
 	)
 	if err != nil {
 		return nil, err
@@ -115,5 +118,9 @@ func readTree(r row) (*trillian.Tree, error) {
 		}
 	}
 
+	if err := storage.UnmarshalTreeOptions(options, tree); err != nil {
+		return nil, err
+	}
+
 	return tree, nil
 }
@@ -0,0 +1,112 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolStat holds the statistics of a pgxpool.Pool.
+type poolStat struct {
+	maxConns, totalConns, acquiredConns, idleConns, constructingConns int32
+
+	// The following statistics are cumulative.
+	acquires, emptyAcquires, canceledAcquires int64
+	emptyAcquireWait                          time.Duration
+	newConns, lifetimeDestroys, idleDestroys  int64
+}
+
+func newPoolStat(s *pgxpool.Stat) poolStat {
+	return poolStat{
+		maxConns:          s.MaxConns(),
+		totalConns:        s.TotalConns(),
+		acquiredConns:     s.AcquiredConns(),
+		idleConns:         s.IdleConns(),
+		constructingConns: s.ConstructingConns(),
+		acquires:          s.AcquireCount(),
+		emptyAcquires:     s.EmptyAcquireCount(),
+		canceledAcquires:  s.CanceledAcquireCount(),
+		emptyAcquireWait:  s.EmptyAcquireWaitTime(),
+		newConns:          s.NewConnsCount(),
+		lifetimeDestroys:  s.MaxLifetimeDestroyCount(),
+		idleDestroys:      s.MaxIdleDestroyCount(),
+	}
+}
+
+// poolExporter sets metrics to the statistics of a connection pool.
+type poolExporter struct {
+	maxConns, totalConns, acquiredConns, idleConns, constructingConns monitoring.Gauge
+	acquires, emptyAcquires, canceledAcquires, emptyAcquireWait       monitoring.Counter
+	newConns, lifetimeDestroys, idleDestroys                          monitoring.Counter
+
+	// mu guards last, the statistics of the previous update.
+	mu   sync.Mutex
+	last poolStat
+}
+
+func newPoolExporter(mf monitoring.MetricFactory) *poolExporter {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &poolExporter{
+		maxConns:          mf.NewGauge("postgresql_pool_max_connections", "Maximum number of connections in the pool"),
+		totalConns:        mf.NewGauge("postgresql_pool_total_connections", "Number of connections in the pool, whether acquired, idle or being constructed"),
+		acquiredConns:     mf.NewGauge("postgresql_pool_acquired_connections", "Number of connections currently acquired from the pool"),
+		idleConns:         mf.NewGauge("postgresql_pool_idle_connections", "Number of idle connections in the pool"),
+		constructingConns: mf.NewGauge("postgresql_pool_constructing_connections", "Number of connections being constructed"),
+		acquires:          mf.NewCounter("postgresql_pool_acquires", "Number of connections acquired from the pool"),
+		emptyAcquires:     mf.NewCounter("postgresql_pool_waits", "Number of acquisitions which waited for a connection because the pool was empty"),
+		canceledAcquires:  mf.NewCounter("postgresql_pool_canceled_acquires", "Number of acquisitions cancelled by their contexts"),
+		emptyAcquireWait:  mf.NewCounter("postgresql_pool_wait_seconds", "Total time spent waiting for a connection because the pool was empty"),
+		newConns:          mf.NewCounter("postgresql_pool_new_connections", "Number of connections opened"),
+		lifetimeDestroys:  mf.NewCounter("postgresql_pool_closed_max_lifetime", "Number of connections closed because of their maximum lifetime"),
+		idleDestroys:      mf.NewCounter("postgresql_pool_closed_max_idle_time", "Number of connections closed because of their maximum idle time"),
+	}
+}
+
+// update sets the metrics to s. The counters are advanced by the increase of
+// the cumulative statistics since the previous update.
+func (e *poolExporter) update(s poolStat) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxConns.Set(float64(s.maxConns))
+	e.totalConns.Set(float64(s.totalConns))
+	e.acquiredConns.Set(float64(s.acquiredConns))
+	e.idleConns.Set(float64(s.idleConns))
+	e.constructingConns.Set(float64(s.constructingConns))
+	for _, c := range []struct {
+		counter   monitoring.Counter
+		cur, last int64
+	}{
+		{e.acquires, s.acquires, e.last.acquires},
+		{e.emptyAcquires, s.emptyAcquires, e.last.emptyAcquires},
+		{e.canceledAcquires, s.canceledAcquires, e.last.canceledAcquires},
+		{e.newConns, s.newConns, e.last.newConns},
+		{e.lifetimeDestroys, s.lifetimeDestroys, e.last.lifetimeDestroys},
+		{e.idleDestroys, s.idleDestroys, e.last.idleDestroys},
+	} {
+		if d := c.cur - c.last; d > 0 {
+			c.counter.Add(float64(d))
+		}
+	}
+	if d := s.emptyAcquireWait - e.last.emptyAcquireWait; d > 0 {
+		e.emptyAcquireWait.Add(d.Seconds())
+	}
+	e.last = s
+}
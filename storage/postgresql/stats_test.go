@@ -0,0 +1,40 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+)
+
+func TestPoolExporter(t *testing.T) {
+	ctx := context.Background()
+	e := newPoolExporter(monitoring.InertMetricFactory{})
+	e.update(newPoolStat(DB.Stat()))
+	before := e.acquires.(*monitoring.InertFloat).Value()
+	if err := DB.Ping(ctx); err != nil {
+		t.Fatalf("Ping(): %v", err)
+	}
+	e.update(newPoolStat(DB.Stat()))
+
+	if got, want := e.maxConns.(*monitoring.InertFloat).Value(), float64(DB.Config().MaxConns); got != want {
+		t.Errorf("max connections = %v, want %v", got, want)
+	}
+	if got := e.acquires.(*monitoring.InertFloat).Value(); got <= before {
+		t.Errorf("acquires = %v after a ping, want > %v", got, before)
+	}
+}
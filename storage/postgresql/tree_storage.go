@@ -18,12 +18,17 @@ package postgresql
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
 	"runtime/debug"
+	"strconv"
 	"sync"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/cloudsqlauth"
+	"github.com/google/trillian/storage/rdsauth"
 	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
 	"github.com/jackc/pgx/v5"
@@ -72,7 +77,93 @@ func OpenDB(dbURL string) (*pgxpool.Pool, error) {
 		klog.Warningf("Could not parse PostgreSQL connection URI, check config: %s", err)
 		return nil, err
 	}
+	return openPool(pgxConfig)
+}
+
+// OpenDBWithIAMAuth opens a database connection pool like OpenDB, to an Amazon
+// RDS or Aurora database which authenticates the user of dbURL with IAM
+// database authentication tokens signed for region. A token is used in place
+// of the password each time a connection is opened. The tokens are sent in
+// clear text, so dbURL must require TLS, e.g. with sslmode=verify-full.
+func OpenDBWithIAMAuth(dbURL, region string) (*pgxpool.Pool, error) {
+	return openDBWithTokens(dbURL, func(cc *pgx.ConnConfig) (func() (string, error), error) {
+		endpoint := net.JoinHostPort(cc.Host, strconv.Itoa(int(cc.Port)))
+		tokens, err := rdsauth.NewTokenSource(endpoint, region, cc.User)
+		if err != nil {
+			return nil, err
+		}
+		return tokens.Token, nil
+	})
+}
+
+// OpenDBWithCloudSQLIAMAuth opens a database connection pool like OpenDB, to a
+// Google Cloud SQL database which authenticates the user of dbURL with IAM
+// database authentication. The access token of the Application Default
+// Credentials is used in place of the password each time a connection is
+// opened. The tokens are sent in clear text, so dbURL must require TLS.
+func OpenDBWithCloudSQLIAMAuth(dbURL string) (*pgxpool.Pool, error) {
+	return openDBWithTokens(dbURL, func(*pgx.ConnConfig) (func() (string, error), error) {
+		tokens, err := cloudsqlauth.NewTokenSource(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return tokens.Token, nil
+	})
+}
+
+// openDBWithTokens opens a database connection pool whose connections log in
+// with a token from the function returned by newTokens for the parsed dbURL.
+func openDBWithTokens(dbURL string, newTokens func(*pgx.ConnConfig) (func() (string, error), error)) (*pgxpool.Pool, error) {
+	pgxConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		klog.Warningf("Could not parse PostgreSQL connection URI, check config: %s", err)
+		return nil, err
+	}
+	cc := pgxConfig.ConnConfig
+	if cc.TLSConfig == nil {
+		return nil, errors.New("IAM database authentication needs TLS to be required")
+	}
+	for _, fc := range cc.Fallbacks {
+		if fc.TLSConfig == nil {
+			return nil, errors.New("IAM database authentication needs TLS to be required")
+		}
+	}
+	token, err := newTokens(cc)
+	if err != nil {
+		return nil, err
+	}
+	setPasswordFunc(pgxConfig, func(context.Context) (string, error) {
+		return token()
+	})
+	return openPool(pgxConfig)
+}
+
+// OpenDBWithPasswordFunc opens a database connection pool like OpenDB, which
+// calls password for the password of the user of dbURL each time a
+// connection is opened, so that the password can be rotated. Any password in
+// dbURL is ignored.
+func OpenDBWithPasswordFunc(dbURL string, password func(context.Context) (string, error)) (*pgxpool.Pool, error) {
+	pgxConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		klog.Warningf("Could not parse PostgreSQL connection URI, check config: %s", err)
+		return nil, err
+	}
+	setPasswordFunc(pgxConfig, password)
+	return openPool(pgxConfig)
+}
+
+func setPasswordFunc(pgxConfig *pgxpool.Config, password func(context.Context) (string, error)) {
+	pgxConfig.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		p, err := password(ctx)
+		if err != nil {
+			return err
+		}
+		cc.Password = p
+		return nil
+	}
+}
 
+func openPool(pgxConfig *pgxpool.Config) (*pgxpool.Pool, error) {
 	db, err := pgxpool.NewWithConfig(context.TODO(), pgxConfig)
 	if err != nil {
 		// Don't log uri as it could contain credentials
@@ -89,8 +180,8 @@ func newTreeStorage(db *pgxpool.Pool) *postgreSQLTreeStorage {
 	}
 }
 
-func (m *postgreSQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
-	t, err := m.db.BeginTx(ctx, pgx.TxOptions{})
+func (m *postgreSQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache, opts pgx.TxOptions) (treeTX, error) {
+	t, err := m.db.BeginTx(ctx, opts)
 	if err != nil {
 		klog.Warningf("Could not start tree TX: %s", err)
 		return treeTX{}, err
@@ -15,8 +15,10 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/trillian/monitoring"
 )
@@ -80,3 +82,41 @@ type Provider interface {
 	// Close closes the underlying storage.
 	Close() error
 }
+
+// Warmer is implemented by Providers which can establish their database
+// connections ahead of serving, so that a server doesn't report itself as
+// ready before it can serve its first requests without delay.
+type Warmer interface {
+	// Warmup opens and checks the configured number of connections, and
+	// leaves them in the connection pool. It returns an error if any of them
+	// fails.
+	Warmup(ctx context.Context) error
+}
+
+// StatsExporter is implemented by Providers which can report the usage of
+// their internal resources, such as connection pools, through the metrics of
+// the MetricFactory they were created with.
+type StatsExporter interface {
+	// ExportStats sets the metrics to the current usage of the resources.
+	ExportStats()
+}
+
+// RunStatsExporter calls ExportStats of p every interval until ctx is done,
+// if p is a StatsExporter.
+func RunStatsExporter(ctx context.Context, p Provider, interval time.Duration) {
+	se, ok := p.(StatsExporter)
+	if !ok {
+		return
+	}
+	se.ExportStats()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		se.ExportStats()
+	}
+}
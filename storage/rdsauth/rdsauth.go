@@ -0,0 +1,86 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rdsauth provides the tokens with which clients authenticate to
+// Amazon RDS and Aurora databases using IAM database authentication, in place
+// of a password.
+package rdsauth
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/google/trillian/util/clock"
+)
+
+// reuseFor is how long a token is used for new connections after it's signed.
+// RDS accepts tokens for 15 minutes, which leaves a margin for clock skew.
+const reuseFor = 10 * time.Minute
+
+// TokenSource provides the tokens of one database user at one endpoint. It
+// signs a new token when the last one gets close to expiry, so it can be
+// asked for one each time a connection is opened.
+type TokenSource struct {
+	endpoint string
+	region   string
+	user     string
+	creds    *credentials.Credentials
+	ts       clock.TimeSource
+
+	mu     sync.Mutex
+	token  string
+	signed time.Time
+}
+
+// NewTokenSource returns a TokenSource for user at endpoint, which is given
+// as "host:port", in region. The tokens are signed with the credentials found
+// by the default AWS credential chain: the environment, the shared
+// configuration files, or the role of the EC2 instance or ECS task.
+func NewTokenSource(endpoint, region, user string) (*TokenSource, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+	return newTokenSource(endpoint, region, user, sess.Config.Credentials, clock.System), nil
+}
+
+func newTokenSource(endpoint, region, user string, creds *credentials.Credentials, ts clock.TimeSource) *TokenSource {
+	return &TokenSource{
+		endpoint: endpoint,
+		region:   region,
+		user:     user,
+		creds:    creds,
+		ts:       ts,
+	}
+}
+
+// Token returns a token which is valid for at least five more minutes.
+func (s *TokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.ts.Now()
+	if s.token != "" && now.Sub(s.signed) < reuseFor {
+		return s.token, nil
+	}
+	token, err := rdsutils.BuildAuthToken(s.endpoint, s.region, s.user, s.creds)
+	if err != nil {
+		return "", fmt.Errorf("failed to build RDS auth token for %s at %s: %v", s.user, s.endpoint, err)
+	}
+	s.token, s.signed = token, now
+	return token, nil
+}
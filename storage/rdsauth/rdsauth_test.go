@@ -0,0 +1,68 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rdsauth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/google/trillian/util/clock"
+)
+
+func TestToken(t *testing.T) {
+	creds := credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", "")
+	ts := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := newTokenSource("db.example.com:3306", "eu-west-1", "trillian", creds, ts)
+
+	token, err := s.Token()
+	if err != nil {
+		t.Fatalf("Token(): %v", err)
+	}
+	host, query, ok := strings.Cut(token, "?")
+	if !ok || host != "db.example.com:3306" {
+		t.Fatalf("Token() = %q, want one for db.example.com:3306", token)
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("Token() = %q, which has a bad query: %v", token, err)
+	}
+	for key, want := range map[string]string{
+		"Action":           "connect",
+		"DBUser":           "trillian",
+		"X-Amz-Credential": "AKIDEXAMPLE/",
+		"X-Amz-Expires":    "900",
+	} {
+		if got := params.Get(key); !strings.HasPrefix(got, want) {
+			t.Errorf("Token() has %s=%q, want %q", key, got, want)
+		}
+	}
+
+	ts.Set(ts.Now().Add(reuseFor - time.Second))
+	if got, err := s.Token(); err != nil || got != token {
+		t.Errorf("Token() before expiry = %q, %v, want the first token", got, err)
+	}
+	signed := s.signed
+
+	ts.Set(ts.Now().Add(time.Second))
+	if _, err := s.Token(); err != nil {
+		t.Fatalf("Token() after expiry: %v", err)
+	}
+	if !s.signed.After(signed) {
+		t.Errorf("Token() after expiry reused the token signed at %v", signed)
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replication asynchronously copies logs from a primary storage to a
+// standby storage, which may use a different backend, so that failing over to
+// the standby does not depend on the database's own replication.
+package replication
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	logIDLabel = "logid"
+
+	// DefaultInterval is used if Config.Interval is not positive.
+	DefaultInterval = 10 * time.Second
+	// DefaultBatchSize is used if Config.BatchSize is not positive.
+	DefaultBatchSize = 1000
+)
+
+var (
+	once             sync.Once
+	replicatedLeaves monitoring.Counter
+	replicationLag   monitoring.Gauge
+	replicationDelay monitoring.Gauge
+	replicationFails monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	replicatedLeaves = mf.NewCounter("replication_leaves", "Number of leaves copied to the standby storage", logIDLabel)
+	replicationLag = mf.NewGauge("replication_lag_leaves", "Number of leaves in the primary's latest root which are not yet in the standby's", logIDLabel)
+	replicationDelay = mf.NewGauge("replication_lag_seconds", "Difference between the timestamps of the primary's and the standby's latest roots", logIDLabel)
+	replicationFails = mf.NewCounter("replication_failures", "Number of failed attempts to replicate a log", logIDLabel)
+}
+
+// Storage is the storage of a set of logs.
+type Storage struct {
+	Admin storage.AdminStorage
+	Log   storage.LogStorage
+}
+
+// Config configures a Replicator.
+type Config struct {
+	// Interval is the time between replication passes over all logs. If it
+	// is not positive, DefaultInterval is used.
+	Interval time.Duration
+	// BatchSize is the maximum number of leaves copied in each transaction.
+	// If it is not positive, DefaultBatchSize is used.
+	BatchSize int
+}
+
+// Replicator copies the integrated leaves, Merkle nodes and roots of logs
+// from a primary storage to a standby storage.
+//
+// Logs are imported into the standby when first seen, which requires its
+// admin transactions to implement storage.TreeImporter, and its log
+// transactions must implement storage.SequencedLeafWriter. Later changes to
+// the trees' settings, and changes to the ExtraData or redaction of leaves
+// which were already copied, are not replicated.
+//
+// When a log's standby is more than BatchSize leaves behind, it is caught up
+// in several transactions, each storing an intermediate root which the
+// primary may never have published. The primary's roots are copied as they
+// are once the standby has caught up.
+//
+// Leaf data is copied as it is stored, so neither storage should be wrapped
+// with a storage/envelope.LogStorage.
+type Replicator struct {
+	primary    Storage
+	standby    Storage
+	cfg        Config
+	timeSource clock.TimeSource
+
+	mu       sync.Mutex
+	imported map[int64]bool
+}
+
+// NewReplicator creates a Replicator from primary to standby.
+func NewReplicator(primary, standby Storage, cfg Config, mf monitoring.MetricFactory, timeSource clock.TimeSource) *Replicator {
+	once.Do(func() { createMetrics(mf) })
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	return &Replicator{
+		primary:    primary,
+		standby:    standby,
+		cfg:        cfg,
+		timeSource: timeSource,
+		imported:   make(map[int64]bool),
+	}
+}
+
+// Run replicates all logs every configured interval until ctx is done.
+func (r *Replicator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.ReplicateAll(ctx); err != nil {
+			klog.Warningf("Replication: %v", err)
+		}
+	}
+}
+
+// ReplicateAll copies everything that the primary storage holds for all logs
+// and that the standby lacks. Failures for individual logs are logged and
+// counted, but don't stop the others from being replicated.
+func (r *Replicator) ReplicateAll(ctx context.Context) error {
+	trees, err := storage.ListTrees(ctx, r.primary.Admin, false)
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %v", err)
+	}
+	for _, t := range trees {
+		if t.TreeType != trillian.TreeType_LOG && t.TreeType != trillian.TreeType_PREORDERED_LOG {
+			continue
+		}
+		for {
+			n, err := r.ReplicateLog(ctx, t)
+			if err != nil {
+				replicationFails.Inc(strconv.FormatInt(t.TreeId, 10))
+				klog.Warningf("%v: replication failed: %v", t.TreeId, err)
+				break
+			}
+			if n < r.cfg.BatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ReplicateLog copies the next batch of up to BatchSize leaves of the given
+// log to the standby, with the Merkle nodes and root which cover them. It
+// returns the number of leaves copied.
+func (r *Replicator) ReplicateLog(ctx context.Context, t *trillian.Tree) (int, error) {
+	if err := r.importTree(ctx, t); err != nil {
+		return 0, err
+	}
+	label := strconv.FormatInt(t.TreeId, 10)
+
+	var copied int
+	err := r.standby.Log.ReadWriteTransaction(ctx, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		copied = 0
+		w, ok := tx.(storage.SequencedLeafWriter)
+		if !ok {
+			return status.Error(codes.Unimplemented, "standby storage does not support writing sequenced leaves")
+		}
+		standbyRoot, err := latestRoot(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to read standby root: %v", err)
+		}
+		primarySLR, primaryRoot, leaves, err := r.readPrimary(ctx, t, standbyRoot.TreeSize)
+		if err != nil || primarySLR == nil {
+			return err
+		}
+		defer func() {
+			replicationDelay.Set(float64(primaryRoot.TimestampNanos-standbyRoot.TimestampNanos)/float64(time.Second), label)
+			replicationLag.Set(float64(primaryRoot.TreeSize-standbyRoot.TreeSize), label)
+		}()
+
+		switch {
+		case primaryRoot.TreeSize < standbyRoot.TreeSize:
+			return status.Errorf(codes.FailedPrecondition, "standby tree size %d is larger than primary tree size %d", standbyRoot.TreeSize, primaryRoot.TreeSize)
+		case primaryRoot.TreeSize == standbyRoot.TreeSize:
+			if !bytes.Equal(primaryRoot.RootHash, standbyRoot.RootHash) && standbyRoot.TreeSize > 0 {
+				return status.Errorf(codes.DataLoss, "standby root hash %x differs from primary root hash %x at size %d", standbyRoot.RootHash, primaryRoot.RootHash, primaryRoot.TreeSize)
+			}
+			if primaryRoot.TimestampNanos <= standbyRoot.TimestampNanos {
+				return nil
+			}
+			// The primary has published a newer root of the same size.
+			if err := tx.StoreSignedLogRoot(ctx, primarySLR); err != nil {
+				return err
+			}
+			*standbyRoot = *primaryRoot
+			return nil
+		}
+
+		cr, err := compactRange(ctx, tx, t, standbyRoot)
+		if err != nil {
+			return err
+		}
+		nodes := make(map[compact.NodeID][]byte)
+		for _, leaf := range leaves {
+			if got, want := leaf.LeafIndex, int64(cr.End()); got != want {
+				return fmt.Errorf("primary returned leaf index %d, want %d", got, want)
+			}
+			if err := cr.Append(leaf.MerkleLeafHash, func(id compact.NodeID, hash []byte) { nodes[id] = hash }); err != nil {
+				return err
+			}
+		}
+		hash, err := cr.GetRootHash(nil)
+		if err != nil {
+			return err
+		}
+		if err := w.WriteSequencedLeaves(ctx, leaves); err != nil {
+			return fmt.Errorf("failed to write leaves: %v", err)
+		}
+		treeNodes := make([]tree.Node, 0, len(nodes))
+		for id, hash := range nodes {
+			treeNodes = append(treeNodes, tree.Node{ID: id, Hash: hash})
+		}
+		if err := tx.SetMerkleNodes(ctx, treeNodes); err != nil {
+			return fmt.Errorf("failed to write Merkle nodes: %v", err)
+		}
+
+		newSLR := primarySLR
+		newRoot := primaryRoot
+		if cr.End() < primaryRoot.TreeSize {
+			// Store a root for the leaves copied so far, as of the time the
+			// last of them was integrated.
+			ts := uint64(leaves[len(leaves)-1].IntegrateTimestamp.AsTime().UnixNano())
+			if ts <= standbyRoot.TimestampNanos {
+				ts = standbyRoot.TimestampNanos + 1
+			}
+			newRoot = &types.LogRootV1{TreeSize: cr.End(), RootHash: hash, TimestampNanos: ts}
+			logRoot, err := newRoot.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			newSLR = &trillian.SignedLogRoot{LogRoot: logRoot}
+		} else if !bytes.Equal(hash, primaryRoot.RootHash) {
+			return status.Errorf(codes.DataLoss, "copied leaves give root hash %x, but primary root hash is %x at size %d", hash, primaryRoot.RootHash, primaryRoot.TreeSize)
+		}
+		if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
+			return fmt.Errorf("failed to store root: %v", err)
+		}
+		*standbyRoot = *newRoot
+		copied = len(leaves)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	replicatedLeaves.Add(float64(copied), label)
+	return copied, nil
+}
+
+// importTree creates the tree in the standby storage, unless it already
+// exists there.
+func (r *Replicator) importTree(ctx context.Context, t *trillian.Tree) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.imported[t.TreeId] {
+		return nil
+	}
+	err := r.standby.Admin.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		i, ok := tx.(storage.TreeImporter)
+		if !ok {
+			return status.Error(codes.Unimplemented, "standby storage does not support importing trees")
+		}
+		_, err := i.ImportTree(ctx, t)
+		return err
+	})
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return fmt.Errorf("failed to import tree: %v", err)
+	}
+	r.imported[t.TreeId] = true
+	return nil
+}
+
+// readPrimary returns the primary's latest root of the log, and up to
+// BatchSize integrated leaves starting at index from. It returns a nil root if
+// the log is not initialised.
+func (r *Replicator) readPrimary(ctx context.Context, t *trillian.Tree, from uint64) (*trillian.SignedLogRoot, *types.LogRootV1, []*trillian.LogLeaf, error) {
+	tx, err := r.primary.Log.SnapshotForTree(ctx, t)
+	if errors.Is(err, storage.ErrTreeNeedsInit) {
+		return nil, nil, nil, nil
+	} else if err != nil {
+		return nil, nil, nil, err
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if errors.Is(err, storage.ErrTreeNeedsInit) || (err == nil && slr == nil) {
+		return nil, nil, nil, nil
+	} else if err != nil {
+		return nil, nil, nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read primary root: %v", err)
+	}
+
+	var leaves []*trillian.LogLeaf
+	if count := min(root.TreeSize-min(from, root.TreeSize), uint64(r.cfg.BatchSize)); count > 0 {
+		if leaves, err = tx.GetLeavesByRange(ctx, int64(from), int64(count)); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read primary leaves: %v", err)
+		}
+		if got := uint64(len(leaves)); got != count {
+			return nil, nil, nil, fmt.Errorf("primary returned %d leaves, want %d", got, count)
+		}
+	}
+	return slr, &root, leaves, tx.Commit(ctx)
+}
+
+// latestRoot returns the latest root stored in tx, or an empty root if the
+// log is not initialised.
+func latestRoot(ctx context.Context, tx storage.ReadOnlyLogTreeTX) (*types.LogRootV1, error) {
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if errors.Is(err, storage.ErrTreeNeedsInit) || (err == nil && slr == nil) {
+		return &types.LogRootV1{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// compactRange returns the compact range covering the leaves of root, as
+// stored in tx, hashed by the hasher of tree t.
+func compactRange(ctx context.Context, tx storage.LogTreeTX, t *trillian.Tree, root *types.LogRootV1) (*compact.Range, error) {
+	hasher, err := hashers.ForTree(t)
+	if err != nil {
+		return nil, err
+	}
+	rangeFactory := hashers.RangeFactory(hasher)
+	if root.TreeSize == 0 {
+		return rangeFactory.NewEmptyRange(0), nil
+	}
+	ids := compact.RangeNodes(0, root.TreeSize, nil)
+	nodes, err := tx.GetMerkleNodes(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read standby nodes: %v", err)
+	}
+	if got, want := len(nodes), len(ids); got != want {
+		return nil, fmt.Errorf("failed to get %d standby nodes, got %d", want, got)
+	}
+	hashes := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		hashes[i] = node.Hash
+	}
+	return rangeFactory.NewRange(0, root.TreeSize, hashes)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/replication"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+func newServer(ts *memory.TreeStorage) (extension.Registry, *server.TrillianLogRPCServer) {
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+		QuotaManager: quota.Noop(),
+	}
+	return registry, server.NewTrillianLogRPCServer(registry, clock.System)
+}
+
+func TestReplicator(t *testing.T) {
+	// Non-positive batch sizes are replaced with the default.
+	for _, batchSize := range []int{7, 0, -1} {
+		t.Run(fmt.Sprintf("batch-size-%d", batchSize), func(t *testing.T) {
+			testReplicator(t, batchSize)
+		})
+	}
+}
+
+func testReplicator(t *testing.T, batchSize int) {
+	t.Helper()
+	ctx := context.Background()
+	log.InitMetrics(nil)
+
+	primary, ps := newServer(memory.NewTreeStorage())
+	standby, ss := newServer(memory.NewTreeStorage())
+	r := replication.NewReplicator(
+		replication.Storage{Admin: primary.AdminStorage, Log: primary.LogStorage},
+		replication.Storage{Admin: standby.AdminStorage, Log: standby.LogStorage},
+		replication.Config{BatchSize: batchSize}, nil, clock.System)
+
+	tree, err := storage.CreateTree(ctx, primary.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if _, err := ps.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		t.Fatalf("InitLog(): %v", err)
+	}
+
+	leafCount := 0
+	for _, batch := range []int{0, 30, 1, 7, 12} {
+		for i := 0; i < batch; i++ {
+			leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d", leafCount))}
+			if _, err := ps.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: tree.TreeId, Leaf: leaf}); err != nil {
+				t.Fatalf("QueueLeaf(): %v", err)
+			}
+			leafCount++
+		}
+		if _, err := log.IntegrateBatch(ctx, tree, batch, 0, 0, clock.System, primary.LogStorage, primary.QuotaManager); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+		if err := r.ReplicateAll(ctx); err != nil {
+			t.Fatalf("ReplicateAll(): %v", err)
+		}
+
+		want, err := ps.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetLatestSignedLogRoot(primary): %v", err)
+		}
+		got, err := ss.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetLatestSignedLogRoot(standby): %v", err)
+		}
+		if diff := cmp.Diff(want.SignedLogRoot, got.SignedLogRoot, protocmp.Transform()); diff != "" {
+			t.Fatalf("after %d leaves, standby root diff (-primary +standby):\n%s", leafCount, diff)
+		}
+	}
+
+	// The standby can serve the log on its own.
+	if _, err := ss.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: tree.TreeId, FirstTreeSize: 3, SecondTreeSize: int64(leafCount)}); err != nil {
+		t.Errorf("GetConsistencyProof(standby): %v", err)
+	}
+	want, err := ps.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, Count: int64(leafCount)})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(primary): %v", err)
+	}
+	got, err := ss.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, Count: int64(leafCount)})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(standby): %v", err)
+	}
+	if diff := cmp.Diff(want.Leaves, got.Leaves, protocmp.Transform()); diff != "" {
+		t.Errorf("standby leaves diff (-primary +standby):\n%s", diff)
+	}
+	for _, leaf := range want.Leaves {
+		if _, err := ss.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: tree.TreeId, LeafHash: leaf.MerkleLeafHash, TreeSize: int64(leafCount)}); err != nil {
+			t.Errorf("GetInclusionProofByHash(standby, %d): %v", leaf.LeafIndex, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SchemaChecker is implemented by Providers which can check that their
+// database has the schema which this version of Trillian needs.
+type SchemaChecker interface {
+	// CheckSchema returns an error naming the tables and columns of the
+	// schema which the database lacks.
+	CheckSchema(ctx context.Context) error
+}
+
+var createTableRE = regexp.MustCompile(`(?i)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\(`)
+
+// SchemaColumns returns the columns of each table created by the SQL script,
+// which must put each column definition on new line, after the line ending
+// with the comma which ends the previous definition. Lines starting with
+// "--" or "#" are comments. Keys and constraints aren't columns.
+func SchemaColumns(script string) map[string][]string {
+	tables := make(map[string][]string)
+	var table string
+	defStart := false
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := createTableRE.FindStringSubmatch(line); m != nil {
+			table, defStart = m[1], true
+			tables[table] = nil
+			continue
+		}
+		if table == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ")") {
+			table = ""
+			continue
+		}
+		if defStart {
+			if name, _, _ := strings.Cut(line, " "); !isConstraint(name) {
+				tables[table] = append(tables[table], name)
+			}
+		}
+		defStart = strings.HasSuffix(line, ",")
+	}
+	return tables
+}
+
+func isConstraint(word string) bool {
+	word, _, _ = strings.Cut(strings.ToUpper(word), "(")
+	switch word {
+	case "PRIMARY", "FOREIGN", "KEY", "INDEX", "UNIQUE", "CONSTRAINT", "CHECK":
+		return true
+	}
+	return false
+}
+
+// MissingColumns compares the tables and columns which a schema needs with
+// those which a database has, ignoring the case of the names, and returns an
+// error naming the missing ones. Tables listed in optional may be missing.
+func MissingColumns(want, have map[string][]string, optional ...string) error {
+	haveCols := make(map[string]bool)
+	for table, cols := range have {
+		haveCols[strings.ToLower(table)] = true
+		for _, col := range cols {
+			haveCols[strings.ToLower(table+"."+col)] = true
+		}
+	}
+	var missing []string
+	for table, cols := range want {
+		if !haveCols[strings.ToLower(table)] {
+			if !slices.Contains(optional, table) {
+				missing = append(missing, table)
+			}
+			continue
+		}
+		for _, col := range cols {
+			if !haveCols[strings.ToLower(table+"."+col)] {
+				missing = append(missing, table+"."+col)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("the database lacks %s of the schema; apply the changes described in the CHANGELOG", strings.Join(missing, ", "))
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSchemaColumns(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		want map[string][]string
+	}{
+		{
+			file: "mysql/schema/storage.sql",
+			want: map[string][]string{
+				"TreeControl":       {"TreeId", "SigningEnabled", "SequencingEnabled", "SequenceIntervalSeconds"},
+				"Unsequenced":       {"TreeId", "Bucket", "LeafIdentityHash", "MerkleLeafHash", "QueueTimestampNanos", "QueueID"},
+				"RecentSubmissions": {"TreeId", "LeafIdentityHash", "Leaf", "SubmitTimeMillis"},
+			},
+		},
+		{
+			file: "postgresql/schema/storage.sql",
+			want: map[string][]string{
+				"LeafData":          {"TreeId", "LeafIdentityHash", "LeafValue", "ExtraData", "QueueTimestampNanos", "LeafIdentityHashPrefix"},
+				"SequencedLeafData": {"TreeId", "SequenceNumber", "LeafIdentityHash", "MerkleLeafHash", "IntegrateTimestampNanos"},
+			},
+		},
+	} {
+		script, err := os.ReadFile(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := SchemaColumns(string(script))
+		for table, want := range test.want {
+			if !slices.Equal(got[table], want) {
+				t.Errorf("%s: SchemaColumns()[%s] = %v, want %v", test.file, table, got[table], want)
+			}
+		}
+		if _, ok := got["Trees"]; !ok {
+			t.Errorf("%s: SchemaColumns() has no Trees table", test.file)
+		}
+	}
+}
+
+func TestMissingColumns(t *testing.T) {
+	want := map[string][]string{
+		"Trees":             {"TreeId", "Options"},
+		"RecentSubmissions": {"TreeId"},
+	}
+	for _, test := range []struct {
+		desc     string
+		have     map[string][]string
+		optional []string
+		wantErr  string
+	}{
+		{
+			desc: "all",
+			have: map[string][]string{"trees": {"treeid", "options"}, "recentsubmissions": {"treeid"}},
+		},
+		{
+			desc:    "missing",
+			have:    map[string][]string{"Trees": {"TreeId"}},
+			wantErr: "RecentSubmissions, Trees.Options",
+		},
+		{
+			desc:     "optional",
+			have:     map[string][]string{"Trees": {"TreeId", "Options", "Extra"}},
+			optional: []string{"RecentSubmissions"},
+		},
+	} {
+		err := MissingColumns(want, test.have, test.optional...)
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: MissingColumns(): %v", test.desc, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: MissingColumns() = %v, want error naming %s", test.desc, err, test.wantErr)
+		}
+	}
+}
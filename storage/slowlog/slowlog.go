@@ -0,0 +1,443 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog provides a storage.LogStorage wrapper which logs and counts
+// storage operations that take longer than a threshold, for any storage
+// provider, so that database performance regressions can be diagnosed from
+// Trillian's own logs.
+package slowlog
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/util/clock"
+	"github.com/transparency-dev/merkle/compact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	logIDLabel = "logid"
+	opLabel    = "operation"
+)
+
+var (
+	once    sync.Once
+	slowOps monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	slowOps = mf.NewCounter("storage_slow_operations", "Number of storage operations which took longer than the slow operation threshold, by operation", logIDLabel, opLabel)
+}
+
+// Config holds the parameters for slow operation logging.
+type Config struct {
+	// Threshold is the duration beyond which an operation is logged and
+	// counted as slow. Must be positive.
+	Threshold time.Duration
+	// TimeSource defaults to clock.System.
+	TimeSource clock.TimeSource
+}
+
+// LogStorage wraps a storage.LogStorage, timing its operations.
+//
+// Each operation of a transaction is timed on its own, and so is the whole
+// transaction, from its start until it is committed or closed. The row count
+// logged for an operation is the number of leaves, nodes or roots it read or
+// wrote, and that of a transaction is the total of its operations.
+type LogStorage struct {
+	storage.LogStorage
+	cfg Config
+}
+
+// NewLogStorage returns a LogStorage which logs the operations on ls which
+// are slower than cfg.Threshold.
+func NewLogStorage(ls storage.LogStorage, cfg Config, mf monitoring.MetricFactory) *LogStorage {
+	once.Do(func() { createMetrics(mf) })
+	if cfg.TimeSource == nil {
+		cfg.TimeSource = clock.System
+	}
+	return &LogStorage{LogStorage: ls, cfg: cfg}
+}
+
+// observe logs and counts the operation op on treeID, which started at
+// start, if it was slow.
+func (s *LogStorage) observe(treeID int64, op string, start time.Time, rows int64, err error) {
+	d := s.cfg.TimeSource.Now().Sub(start)
+	if d <= s.cfg.Threshold {
+		return
+	}
+	slowOps.Inc(strconv.FormatInt(treeID, 10), op)
+	if err != nil {
+		klog.Warningf("%d: slow storage operation %s took %v for %d rows, and failed: %v", treeID, op, d, rows, err)
+		return
+	}
+	klog.Warningf("%d: slow storage operation %s took %v for %d rows", treeID, op, d, rows)
+}
+
+// SnapshotForTree implements storage.LogStorage.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	start := s.cfg.TimeSource.Now()
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		s.observe(tree.TreeId, "SnapshotForTree", start, 0, err)
+		return nil, err
+	}
+	return &snapshot{ReadOnlyLogTreeTX: tx, s: s, treeID: tree.TreeId, start: start}, nil
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	start := s.cfg.TimeSource.Now()
+	var rows int64
+	err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		ltx := &logTX{snapshot: &snapshot{ReadOnlyLogTreeTX: tx, s: s, treeID: tree.TreeId, start: s.cfg.TimeSource.Now()}, tx: tx}
+		// Only the rows of the last attempt count, if the storage retries.
+		defer func() { rows = ltx.rows.Load() }()
+		return f(ctx, ltx)
+	})
+	s.observe(tree.TreeId, "ReadWriteTransaction", start, rows, err)
+	return err
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	start := s.cfg.TimeSource.Now()
+	ret, err := s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	s.observe(tree.TreeId, "QueueLeaves", start, int64(len(leaves)), err)
+	return ret, err
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	start := s.cfg.TimeSource.Now()
+	ret, err := s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	s.observe(tree.TreeId, "AddSequencedLeaves", start, int64(len(leaves)), err)
+	return ret, err
+}
+
+// CountUnsequenced implements storage.LogStorage.
+func (s *LogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	start := s.cfg.TimeSource.Now()
+	ret, err := s.LogStorage.CountUnsequenced(ctx, tree)
+	s.observe(tree.TreeId, "CountUnsequenced", start, 1, err)
+	return ret, err
+}
+
+// snapshot times the reads made through a transaction.
+type snapshot struct {
+	storage.ReadOnlyLogTreeTX
+	s      *LogStorage
+	treeID int64
+	// start is when the transaction started.
+	start time.Time
+	// rows is the number of rows read or written by the transaction so far.
+	rows atomic.Int64
+	// finished is set once the transaction has been observed.
+	finished atomic.Bool
+}
+
+// now returns the start time of an operation.
+func (t *snapshot) now() time.Time {
+	return t.s.cfg.TimeSource.Now()
+}
+
+// observe records an operation of the transaction.
+func (t *snapshot) observe(op string, start time.Time, rows int, err error) {
+	t.rows.Add(int64(rows))
+	t.s.observe(t.treeID, op, start, int64(rows), err)
+}
+
+// finish records the whole transaction, once.
+func (t *snapshot) finish(err error) {
+	if t.finished.Swap(true) {
+		return
+	}
+	t.s.observe(t.treeID, "SnapshotForTree", t.start, t.rows.Load(), err)
+}
+
+func (t *snapshot) Commit(ctx context.Context) error {
+	start := t.now()
+	err := t.ReadOnlyLogTreeTX.Commit(ctx)
+	t.observe("Commit", start, 0, err)
+	t.finish(err)
+	return err
+}
+
+func (t *snapshot) Close() error {
+	err := t.ReadOnlyLogTreeTX.Close()
+	t.finish(nil)
+	return err
+}
+
+func (t *snapshot) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]tree.Node, error) {
+	start := t.now()
+	nodes, err := t.ReadOnlyLogTreeTX.GetMerkleNodes(ctx, ids)
+	t.observe("GetMerkleNodes", start, len(nodes), err)
+	return nodes, err
+}
+
+func (t *snapshot) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	opStart := t.now()
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByRange(ctx, start, count)
+	t.observe("GetLeavesByRange", opStart, len(leaves), err)
+	return leaves, err
+}
+
+func (t *snapshot) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	start := t.now()
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByHash(ctx, leafHashes, orderBySequence)
+	t.observe("GetLeavesByHash", start, len(leaves), err)
+	return leaves, err
+}
+
+func (t *snapshot) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	start := t.now()
+	root, err := t.ReadOnlyLogTreeTX.LatestSignedLogRoot(ctx)
+	t.observe("LatestSignedLogRoot", start, rowCount(root != nil), err)
+	return root, err
+}
+
+// GetLeavesByIndices implements storage.IndexedLeafReader.
+func (t *snapshot) GetLeavesByIndices(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	start := t.now()
+	leaves, err := storage.GetLeavesByIndices(ctx, t.ReadOnlyLogTreeTX, indices)
+	t.observe("GetLeavesByIndices", start, len(leaves), err)
+	return leaves, err
+}
+
+// GetLeavesByIdentityHash implements storage.IdentityHashReader if the
+// underlying transaction does.
+func (t *snapshot) GetLeavesByIdentityHash(ctx context.Context, identityHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IdentityHashReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support lookup by leaf identity hash")
+	}
+	start := t.now()
+	leaves, err := r.GetLeavesByIdentityHash(ctx, identityHashes, orderBySequence)
+	t.observe("GetLeavesByIdentityHash", start, len(leaves), err)
+	return leaves, err
+}
+
+// GetSignedLogRootBySize implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootBySize(ctx context.Context, treeSize uint64) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	start := t.now()
+	root, err := r.GetSignedLogRootBySize(ctx, treeSize)
+	t.observe("GetSignedLogRootBySize", start, rowCount(root != nil), err)
+	return root, err
+}
+
+// GetSignedLogRootByHash implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) GetSignedLogRootByHash(ctx context.Context, rootHash []byte) (*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	start := t.now()
+	root, err := r.GetSignedLogRootByHash(ctx, rootHash)
+	t.observe("GetSignedLogRootByHash", start, rowCount(root != nil), err)
+	return root, err
+}
+
+// ListSignedLogRoots implements storage.RootHistoryReader if the
+// underlying transaction does.
+func (t *snapshot) ListSignedLogRoots(ctx context.Context, rng storage.RootRange, limit int) ([]*trillian.SignedLogRoot, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.RootHistoryReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support reading earlier roots")
+	}
+	start := t.now()
+	roots, err := r.ListSignedLogRoots(ctx, rng, limit)
+	t.observe("ListSignedLogRoots", start, len(roots), err)
+	return roots, err
+}
+
+// ListIntegrationEvents implements storage.IntegrationEventReader if the
+// underlying transaction does.
+func (t *snapshot) ListIntegrationEvents(ctx context.Context, before time.Time, limit int) ([]*trillian.IntegrationEvent, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.IntegrationEventReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	start := t.now()
+	events, err := r.ListIntegrationEvents(ctx, before, limit)
+	t.observe("ListIntegrationEvents", start, len(events), err)
+	return events, err
+}
+
+// CountUnsequenced implements storage.UnsequencedCounter if the underlying
+// transaction does.
+func (t *snapshot) CountUnsequenced(ctx context.Context) (int64, error) {
+	c, ok := t.ReadOnlyLogTreeTX.(storage.UnsequencedCounter)
+	if !ok {
+		return 0, status.Error(codes.Unimplemented, "storage does not support counting unsequenced leaves")
+	}
+	start := t.now()
+	count, err := c.CountUnsequenced(ctx)
+	t.observe("CountUnsequenced", start, 1, err)
+	return count, err
+}
+
+// GetSequencedIndexRange implements storage.SequencedIndexReader if the
+// underlying transaction does.
+func (t *snapshot) GetSequencedIndexRange(ctx context.Context) (int64, int64, error) {
+	r, ok := t.ReadOnlyLogTreeTX.(storage.SequencedIndexReader)
+	if !ok {
+		return 0, 0, status.Error(codes.Unimplemented, "storage does not support finding the range of sequenced leaves")
+	}
+	start := t.now()
+	first, last, err := r.GetSequencedIndexRange(ctx)
+	t.observe("GetSequencedIndexRange", start, 1, err)
+	return first, last, err
+}
+
+// logTX times the reads and writes made through a read-write transaction.
+// The transaction as a whole is timed by ReadWriteTransaction.
+type logTX struct {
+	*snapshot
+	tx storage.LogTreeTX
+}
+
+func (t *logTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	start := t.now()
+	err := t.tx.SetMerkleNodes(ctx, nodes)
+	t.observe("SetMerkleNodes", start, len(nodes), err)
+	return err
+}
+
+func (t *logTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	start := t.now()
+	err := t.tx.StoreSignedLogRoot(ctx, root)
+	t.observe("StoreSignedLogRoot", start, 1, err)
+	return err
+}
+
+func (t *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	start := t.now()
+	leaves, err := t.tx.DequeueLeaves(ctx, limit, cutoff)
+	t.observe("DequeueLeaves", start, len(leaves), err)
+	return leaves, err
+}
+
+func (t *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	start := t.now()
+	err := t.tx.UpdateSequencedLeaves(ctx, leaves)
+	t.observe("UpdateSequencedLeaves", start, len(leaves), err)
+	return err
+}
+
+// Commit implements storage.LogTreeTX. Unlike that of a snapshot, it doesn't
+// finish the transaction, as ReadWriteTransaction does that.
+func (t *logTX) Commit(ctx context.Context) error {
+	start := t.now()
+	err := t.tx.Commit(ctx)
+	t.observe("Commit", start, 0, err)
+	return err
+}
+
+// Close implements storage.LogTreeTX.
+func (t *logTX) Close() error {
+	return t.tx.Close()
+}
+
+// WriteSequencedLeaves implements storage.SequencedLeafWriter if the
+// underlying transaction does.
+func (t *logTX) WriteSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	w, ok := t.tx.(storage.SequencedLeafWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not support writing sequenced leaves")
+	}
+	start := t.now()
+	err := w.WriteSequencedLeaves(ctx, leaves)
+	t.observe("WriteSequencedLeaves", start, len(leaves), err)
+	return err
+}
+
+// UpdateLeafExtraData implements storage.ExtraDataUpdater if the underlying
+// transaction does.
+func (t *logTX) UpdateLeafExtraData(ctx context.Context, index int64, extraData []byte, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	u, ok := t.tx.(storage.ExtraDataUpdater)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support updating leaf extra data")
+	}
+	start := t.now()
+	leaf, err := u.UpdateLeafExtraData(ctx, index, extraData, reason, timestamp)
+	t.observe("UpdateLeafExtraData", start, 1, err)
+	return leaf, err
+}
+
+// RedactLeaf implements storage.LeafRedactor if the underlying transaction
+// does.
+func (t *logTX) RedactLeaf(ctx context.Context, index int64, reason string, timestamp time.Time) (*trillian.LogLeaf, error) {
+	r, ok := t.tx.(storage.LeafRedactor)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support redacting leaves")
+	}
+	start := t.now()
+	leaf, err := r.RedactLeaf(ctx, index, reason, timestamp)
+	t.observe("RedactLeaf", start, 1, err)
+	return leaf, err
+}
+
+// GetStagedLeaves implements storage.StagedLeafReader if the underlying
+// transaction does.
+func (t *logTX) GetStagedLeaves(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	r, ok := t.tx.(storage.StagedLeafReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not support staged leaves")
+	}
+	begin := t.now()
+	leaves, err := r.GetStagedLeaves(ctx, start, count)
+	t.observe("GetStagedLeaves", begin, len(leaves), err)
+	return leaves, err
+}
+
+// AddIntegrationEvent implements storage.IntegrationEventWriter if the
+// underlying transaction does.
+func (t *logTX) AddIntegrationEvent(ctx context.Context, event *trillian.IntegrationEvent, cutoff time.Time) error {
+	w, ok := t.tx.(storage.IntegrationEventWriter)
+	if !ok {
+		return status.Error(codes.Unimplemented, "storage does not record integration events")
+	}
+	start := t.now()
+	err := w.AddIntegrationEvent(ctx, event, cutoff)
+	t.observe("AddIntegrationEvent", start, 1, err)
+	return err
+}
+
+// rowCount returns 1 if a row was found, and 0 otherwise.
+func rowCount(found bool) int {
+	if found {
+		return 1
+	}
+	return 0
+}
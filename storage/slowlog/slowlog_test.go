@@ -0,0 +1,128 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring/testonly"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testTree = &trillian.Tree{TreeId: 1234}
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	tx := storage.NewMockLogTreeTX(ctrl)
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	s := NewLogStorage(ls, Config{Threshold: time.Second, TimeSource: ts}, nil)
+
+	ls.EXPECT().ReadWriteTransaction(gomock.Any(), testTree, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *trillian.Tree, f storage.LogTXFunc) error { return f(ctx, tx) })
+	leaves := []*trillian.LogLeaf{{}, {}, {}}
+	tx.EXPECT().DequeueLeaves(gomock.Any(), 10, gomock.Any()).DoAndReturn(
+		func(context.Context, int, time.Time) ([]*trillian.LogLeaf, error) {
+			ts.Set(ts.Now().Add(2 * time.Second))
+			return leaves, nil
+		})
+	tx.EXPECT().UpdateSequencedLeaves(gomock.Any(), leaves).Return(nil)
+
+	label := "1234"
+	slowDequeues := testonly.NewCounterSnapshot(slowOps, label, "DequeueLeaves")
+	slowUpdates := testonly.NewCounterSnapshot(slowOps, label, "UpdateSequencedLeaves")
+	slowTXs := testonly.NewCounterSnapshot(slowOps, label, "ReadWriteTransaction")
+	if err := s.ReadWriteTransaction(ctx, testTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.DequeueLeaves(ctx, 10, ts.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.UpdateSequencedLeaves(ctx, leaves); err != nil {
+			return err
+		}
+		// Optional interfaces are forwarded, if the storage implements them.
+		if _, err := tx.(storage.ExtraDataUpdater).UpdateLeafExtraData(ctx, 0, nil, "", ts.Now()); status.Code(err) != codes.Unimplemented {
+			t.Errorf("UpdateLeafExtraData() = %v, want Unimplemented", err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction() = %v", err)
+	}
+
+	for _, test := range []struct {
+		op    string
+		delta float64
+		want  float64
+	}{
+		{op: "DequeueLeaves", delta: slowDequeues.Delta(), want: 1},
+		{op: "UpdateSequencedLeaves", delta: slowUpdates.Delta(), want: 0},
+		{op: "ReadWriteTransaction", delta: slowTXs.Delta(), want: 1},
+	} {
+		if test.delta != test.want {
+			t.Errorf("%s slow operations = %v, want %v", test.op, test.delta, test.want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ls := storage.NewMockLogStorage(ctrl)
+	tx := storage.NewMockReadOnlyLogTreeTX(ctrl)
+	ts := clock.NewFake(time.Unix(1700000000, 0))
+	s := NewLogStorage(ls, Config{Threshold: time.Second, TimeSource: ts}, nil)
+
+	ls.EXPECT().SnapshotForTree(gomock.Any(), testTree).Return(tx, nil)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(&trillian.SignedLogRoot{}, nil)
+	tx.EXPECT().Commit(gomock.Any()).Return(nil)
+	tx.EXPECT().Close().Return(nil)
+
+	label := "1234"
+	slowRoots := testonly.NewCounterSnapshot(slowOps, label, "LatestSignedLogRoot")
+	slowSnapshots := testonly.NewCounterSnapshot(slowOps, label, "SnapshotForTree")
+	snap, err := s.SnapshotForTree(ctx, testTree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree() = %v", err)
+	}
+	if _, err := snap.LatestSignedLogRoot(ctx); err != nil {
+		t.Fatalf("LatestSignedLogRoot() = %v", err)
+	}
+	// Time spent between operations only counts towards the transaction.
+	ts.Set(ts.Now().Add(2 * time.Second))
+	if err := snap.Commit(ctx); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+	if err := snap.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if got := slowRoots.Delta(); got != 0 {
+		t.Errorf("LatestSignedLogRoot slow operations = %v, want 0", got)
+	}
+	if got := slowSnapshots.Delta(); got != 1 {
+		t.Errorf("SnapshotForTree slow operations = %v, want 1", got)
+	}
+	if _, err := snap.(storage.UnsequencedCounter).CountUnsequenced(ctx); status.Code(err) != codes.Unimplemented {
+		t.Errorf("CountUnsequenced() = %v, want Unimplemented", err)
+	}
+}
@@ -189,13 +189,13 @@ func SetFDLimit(uLimit uint64) error {
 	return unix.Setrlimit(unix.RLIMIT_NOFILE, &rLimit)
 }
 
-// newEmptyDB creates a new, empty database.
+// NewEmptyDB creates a new, empty database.
 // It returns the database handle and a clean-up function, or an error.
 // The returned clean-up function should be called once the caller is finished
 // using the DB, the caller should not continue to use the returned DB after
 // calling this function as it may, for example, delete the underlying
 // instance.
-func newEmptyDB(ctx context.Context, driver DriverName) (*sql.DB, func(context.Context), error) {
+func NewEmptyDB(ctx context.Context, driver DriverName) (*sql.DB, func(context.Context), error) {
 	if err := SetFDLimit(2048); err != nil {
 		return nil, nil, err
 	}
@@ -245,7 +245,7 @@ func newEmptyDB(ctx context.Context, driver DriverName) (*sql.DB, func(context.C
 // generated.
 // NewTrillianDB is equivalent to Default().NewTrillianDB(ctx).
 func NewTrillianDB(ctx context.Context, driver DriverName) (*sql.DB, func(context.Context), error) {
-	db, done, err := newEmptyDB(ctx, driver)
+	db, done, err := NewEmptyDB(ctx, driver)
 	if err != nil {
 		return nil, nil, err
 	}
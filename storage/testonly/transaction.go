@@ -107,6 +107,11 @@ func (f *FakeLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.
 	return res, nil
 }
 
+// CountUnsequenced implements LogStorage.CountUnsequenced.
+func (f *FakeLogStorage) CountUnsequenced(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	return 0, status.Error(codes.Unimplemented, "CountUnsequenced is not implemented")
+}
+
 // CheckDatabaseAccessible implements LogStorage.CheckDatabaseAccessible
 func (f *FakeLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return nil
@@ -0,0 +1,62 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/google/trillian"
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalTreeOptions serializes the fields of tree which do not have a
+// dedicated column in SQL-based storage implementations. This allows new
+// per-tree configuration to be persisted without a schema change each time.
+//
+// The fields which are stored in their own columns (ID, state, type, names,
+// timestamps, deletion status, storage settings and max root duration) are
+// omitted from the result.
+func MarshalTreeOptions(tree *trillian.Tree) ([]byte, error) {
+	opts := proto.Clone(tree).(*trillian.Tree)
+	opts.TreeId = 0
+	opts.TreeState = trillian.TreeState_UNKNOWN_TREE_STATE
+	opts.TreeType = trillian.TreeType_UNKNOWN_TREE_TYPE
+	opts.DisplayName = ""
+	opts.Description = ""
+	opts.StorageSettings = nil
+	opts.MaxRootDuration = nil
+	opts.CreateTime = nil
+	opts.UpdateTime = nil
+	opts.Deleted = false
+	opts.DeleteTime = nil
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tree options: %v", err)
+	}
+	return b, nil
+}
+
+// UnmarshalTreeOptions merges options previously serialized by
+// MarshalTreeOptions into tree. An empty or nil b leaves tree unchanged, which
+// is the case for trees created before options were persisted.
+func UnmarshalTreeOptions(b []byte, tree *trillian.Tree) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(b, tree); err != nil {
+		return fmt.Errorf("failed to unmarshal tree options: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTreeOptionsRoundTrip(t *testing.T) {
+	tree := newTree()
+	tree.TreeId = 12345
+	tree.AutoFreeze = true
+
+	b, err := MarshalTreeOptions(tree)
+	if err != nil {
+		t.Fatalf("MarshalTreeOptions(): %v", err)
+	}
+
+	// Column-backed fields must not be overwritten by the stored options.
+	got := &trillian.Tree{
+		TreeId:      67890,
+		TreeState:   trillian.TreeState_FROZEN,
+		DisplayName: "Other Log",
+	}
+	if err := UnmarshalTreeOptions(b, got); err != nil {
+		t.Fatalf("UnmarshalTreeOptions(): %v", err)
+	}
+	want := &trillian.Tree{
+		TreeId:      67890,
+		TreeState:   trillian.TreeState_FROZEN,
+		DisplayName: "Other Log",
+		AutoFreeze:  true,
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("UnmarshalTreeOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestUnmarshalTreeOptionsEmpty(t *testing.T) {
+	tree := newTree()
+	want := proto.Clone(tree)
+	for _, b := range [][]byte{nil, {}} {
+		if err := UnmarshalTreeOptions(b, tree); err != nil {
+			t.Errorf("UnmarshalTreeOptions(%v): %v", b, err)
+		}
+		if !proto.Equal(tree, want) {
+			t.Errorf("UnmarshalTreeOptions(%v) modified tree: %v, want %v", b, tree, want)
+		}
+	}
+}
+
+func TestUnmarshalTreeOptionsCorrupt(t *testing.T) {
+	if err := UnmarshalTreeOptions([]byte{0xff}, newTree()); err == nil {
+		t.Error("UnmarshalTreeOptions() returned nil error for corrupt options")
+	}
+}
@@ -16,8 +16,14 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/leafvalidators"
+	"github.com/google/trillian/merkle/hashers"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -39,11 +45,70 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "invalid deleted: %v", tree.Deleted)
 	case tree.DeleteTime != nil:
 		return status.Errorf(codes.InvalidArgument, "invalid delete_time: %+v (must be nil)", tree.DeleteTime)
+	case tree.SequencingRequestedTime != nil:
+		return status.Errorf(codes.InvalidArgument, "invalid sequencing_requested_time: %+v (must be nil)", tree.SequencingRequestedTime)
+	}
+	if err := validateTemporalShard(tree.TemporalShard); err != nil {
+		return err
+	}
+	if _, err := hashers.ForTree(tree); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid hasher_id: %v", err)
 	}
 
 	return validateMutableTreeFields(ctx, tree)
 }
 
+// ValidateTreeForImport returns nil if tree is valid for insertion with its
+// existing TreeId and timestamps, as done when replicating trees, error
+// otherwise. Unlike new trees, imported trees may be in any state.
+func ValidateTreeForImport(ctx context.Context, tree *trillian.Tree) error {
+	switch {
+	case tree == nil:
+		return status.Error(codes.InvalidArgument, "a tree is required")
+	case tree.TreeId <= 0:
+		return status.Errorf(codes.InvalidArgument, "invalid tree_id: %d", tree.TreeId)
+	case tree.TreeType == trillian.TreeType_UNKNOWN_TREE_TYPE:
+		return status.Errorf(codes.InvalidArgument, "invalid tree_type: %s", tree.TreeType)
+	case tree.Deleted:
+		return status.Errorf(codes.InvalidArgument, "invalid deleted: %v", tree.Deleted)
+	}
+	if err := tree.CreateTime.CheckValid(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "create_time malformed: %v", err)
+	}
+	if err := tree.UpdateTime.CheckValid(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "update_time malformed: %v", err)
+	}
+	if err := validateTemporalShard(tree.TemporalShard); err != nil {
+		return err
+	}
+	if _, err := hashers.ForTree(tree); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid hasher_id: %v", err)
+	}
+
+	return validateMutableTreeFields(ctx, tree)
+}
+
+// validateTemporalShard returns nil if shard is either unset or describes a
+// non-empty validity window within a named shard set.
+func validateTemporalShard(shard *trillian.TemporalShard) error {
+	if shard == nil {
+		return nil
+	}
+	if shard.ShardSet == "" {
+		return status.Error(codes.InvalidArgument, "temporal_shard.shard_set is required")
+	}
+	if err := shard.NotAfterStart.CheckValid(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "temporal_shard.not_after_start malformed: %v", err)
+	}
+	if err := shard.NotAfterLimit.CheckValid(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "temporal_shard.not_after_limit malformed: %v", err)
+	}
+	if start, limit := shard.NotAfterStart.AsTime(), shard.NotAfterLimit.AsTime(); !start.Before(limit) {
+		return status.Errorf(codes.InvalidArgument, "temporal_shard window is empty: [%v, %v)", start, limit)
+	}
+	return nil
+}
+
 // validateTreeTypeUpdate returns nil iff oldTree.TreeType can be updated to
 // newTree.TreeType. The tree type is changeable only if the Tree is and
 // remains in the FROZEN state.
@@ -88,6 +153,10 @@ func ValidateTreeForUpdate(ctx context.Context, storedTree, newTree *trillian.Tr
 		return status.Error(codes.InvalidArgument, "readonly field changed: deleted")
 	case !proto.Equal(storedTree.DeleteTime, newTree.DeleteTime):
 		return status.Error(codes.InvalidArgument, "readonly field changed: delete_time")
+	case !proto.Equal(storedTree.TemporalShard, newTree.TemporalShard):
+		return status.Error(codes.InvalidArgument, "readonly field changed: temporal_shard")
+	case storedTree.HasherId != newTree.HasherId:
+		return status.Error(codes.InvalidArgument, "readonly field changed: hasher_id")
 	}
 	return validateMutableTreeFields(ctx, newTree)
 }
@@ -101,6 +170,43 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 	} else if duration := tree.MaxRootDuration.AsDuration(); duration < 0 {
 		return status.Errorf(codes.InvalidArgument, "max_root_duration negative: %v", tree.MaxRootDuration)
 	}
+	if tree.MergeDelayTarget != nil {
+		if err := tree.MergeDelayTarget.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "merge_delay_target malformed: %v", err)
+		} else if tree.MergeDelayTarget.AsDuration() < 0 {
+			return status.Errorf(codes.InvalidArgument, "merge_delay_target negative: %v", tree.MergeDelayTarget)
+		}
+	}
+
+	if tree.MaxTreeSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "max_tree_size negative: %v", tree.MaxTreeSize)
+	}
+	if _, ok := trillian.DequeueOrder_name[int32(tree.DequeueOrder)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid dequeue_order: %v", tree.DequeueOrder)
+	} else if tree.DequeueOrder != trillian.DequeueOrder_STORAGE_DEQUEUE_ORDER && tree.TreeType != trillian.TreeType_LOG {
+		return status.Errorf(codes.InvalidArgument, "dequeue_order %v is only valid for LOG trees, not %v", tree.DequeueOrder, tree.TreeType)
+	}
+	for _, m := range tree.DisabledMethods {
+		if !isLogMethod(m) {
+			return status.Errorf(codes.InvalidArgument, "invalid disabled_methods: %q is not a unary TrillianLog RPC", m)
+		}
+	}
+	if err := leafvalidators.Check(tree.LeafValidators); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid leaf_validators: %v", err)
+	}
+	if err := validateCheckpointOrigin(tree.CheckpointOrigin); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid checkpoint_origin: %v", err)
+	}
+	for i, id := range tree.EcosystemIds {
+		if err := validateEcosystemID(id); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid ecosystem_ids: %v", err)
+		}
+		for _, prev := range tree.EcosystemIds[:i] {
+			if prev == id {
+				return status.Errorf(codes.InvalidArgument, "invalid ecosystem_ids: %q is repeated", id)
+			}
+		}
+	}
 
 	// Implementations may vary, so let's assume storage_settings is mutable.
 	// Other than checking that it's a valid Any there isn't much to do at this layer, though.
@@ -113,3 +219,44 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 
 	return nil
 }
+
+// isLogMethod returns whether name is the name of a unary RPC of the
+// TrillianLog service.
+func isLogMethod(name string) bool {
+	for _, m := range trillian.TrillianLog_ServiceDesc.Methods {
+		if m.MethodName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCheckpointOrigin returns an error if origin can't be the origin
+// line of a checkpoint. Empty means the tree has none.
+func validateCheckpointOrigin(origin string) error {
+	if !utf8.ValidString(origin) {
+		return fmt.Errorf("%q isn't UTF-8", origin)
+	}
+	if strings.TrimSpace(origin) != origin {
+		return fmt.Errorf("%q starts or ends with whitespace", origin)
+	}
+	for _, r := range origin {
+		if r != ' ' && (unicode.IsSpace(r) || unicode.IsControl(r)) {
+			return fmt.Errorf("%q contains %U", origin, r)
+		}
+	}
+	return nil
+}
+
+// validateEcosystemID returns an error if id isn't of the form
+// "<ecosystem>:<identifier>", without whitespace.
+func validateEcosystemID(id string) error {
+	ecosystem, ident, ok := strings.Cut(id, ":")
+	if !ok || ecosystem == "" || ident == "" {
+		return fmt.Errorf("%q isn't <ecosystem>:<identifier>", id)
+	}
+	if !utf8.ValidString(id) || strings.IndexFunc(id, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return fmt.Errorf("%q contains whitespace or control characters", id)
+	}
+	return nil
+}
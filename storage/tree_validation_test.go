@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -61,12 +62,40 @@ func TestValidateTreeForCreation(t *testing.T) {
 	invalidRootDuration := newTree()
 	invalidRootDuration.MaxRootDuration = durationpb.New(-1 * time.Second)
 
+	validMaxTreeSize := newTree()
+	validMaxTreeSize.MaxTreeSize = 1 << 20
+
+	invalidMaxTreeSize := newTree()
+	invalidMaxTreeSize.MaxTreeSize = -1
+
+	validShard := newTree()
+	validShard.TemporalShard = &trillian.TemporalShard{
+		ShardSet:      "llamas",
+		NotAfterStart: timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		NotAfterLimit: timestamppb.New(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	unnamedShard := proto.Clone(validShard).(*trillian.Tree)
+	unnamedShard.TemporalShard.ShardSet = ""
+
+	emptyShardWindow := proto.Clone(validShard).(*trillian.Tree)
+	emptyShardWindow.TemporalShard.NotAfterLimit = emptyShardWindow.TemporalShard.NotAfterStart
+
+	validHasher := newTree()
+	validHasher.HasherId = hashers.RFC6962SHA512_256
+
+	unknownHasher := newTree()
+	unknownHasher.HasherId = "llama"
+
 	deletedTree := newTree()
 	deletedTree.Deleted = true
 
 	deleteTimeTree := newTree()
 	deleteTimeTree.DeleteTime = timestamppb.Now()
 
+	sequencingRequestedTree := newTree()
+	sequencingRequestedTree.SequencingRequestedTime = timestamppb.Now()
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -119,6 +148,38 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    invalidRootDuration,
 			wantErr: true,
 		},
+		{
+			desc: "validMaxTreeSize",
+			tree: validMaxTreeSize,
+		},
+		{
+			desc:    "invalidMaxTreeSize",
+			tree:    invalidMaxTreeSize,
+			wantErr: true,
+		},
+		{
+			desc: "validShard",
+			tree: validShard,
+		},
+		{
+			desc:    "unnamedShard",
+			tree:    unnamedShard,
+			wantErr: true,
+		},
+		{
+			desc:    "emptyShardWindow",
+			tree:    emptyShardWindow,
+			wantErr: true,
+		},
+		{
+			desc: "validHasher",
+			tree: validHasher,
+		},
+		{
+			desc:    "unknownHasher",
+			tree:    unknownHasher,
+			wantErr: true,
+		},
 		{
 			desc:    "deletedTree",
 			tree:    deletedTree,
@@ -129,6 +190,11 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    deleteTimeTree,
 			wantErr: true,
 		},
+		{
+			desc:    "sequencingRequestedTree",
+			tree:    sequencingRequestedTree,
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		err := ValidateTreeForCreation(ctx, test.tree)
@@ -194,6 +260,102 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "validMergeDelayTarget",
+			updatefn: func(tree *trillian.Tree) {
+				tree.MergeDelayTarget = durationpb.New(24 * time.Hour)
+			},
+		},
+		{
+			desc: "invalidMergeDelayTarget",
+			updatefn: func(tree *trillian.Tree) {
+				tree.MergeDelayTarget = durationpb.New(-time.Second)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "FIFODequeueOrder",
+			updatefn: func(tree *trillian.Tree) {
+				tree.DequeueOrder = trillian.DequeueOrder_FIFO_DEQUEUE_ORDER
+			},
+		},
+		{
+			desc:     "FIFODequeueOrderPreorderedLog",
+			treeType: trillian.TreeType_PREORDERED_LOG,
+			updatefn: func(tree *trillian.Tree) {
+				tree.DequeueOrder = trillian.DequeueOrder_FIFO_DEQUEUE_ORDER
+			},
+			wantErr: true,
+		},
+		{
+			desc: "unknownDequeueOrder",
+			updatefn: func(tree *trillian.Tree) {
+				tree.DequeueOrder = 42
+			},
+			wantErr: true,
+		},
+		{
+			desc: "disabledMethods",
+			updatefn: func(tree *trillian.Tree) {
+				tree.DisabledMethods = []string{"GetLeavesByRange", "QueueLeaf"}
+			},
+		},
+		{
+			desc: "unknownDisabledMethod",
+			updatefn: func(tree *trillian.Tree) {
+				tree.DisabledMethods = []string{"GetLeavesByRange", "GetLeaves"}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "streamingDisabledMethod",
+			updatefn: func(tree *trillian.Tree) {
+				tree.DisabledMethods = []string{"WatchSignedLogRoots"}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "unknownLeafValidator",
+			updatefn: func(tree *trillian.Tree) {
+				tree.LeafValidators = []string{"NoSuchValidator"}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "checkpointIdentifiers",
+			updatefn: func(tree *trillian.Tree) {
+				tree.CheckpointOrigin = "example.com/log 2025"
+				tree.EcosystemIds = []string{"ct:a2V5", "example:log-1"}
+			},
+		},
+		{
+			desc: "checkpointOriginNewline",
+			updatefn: func(tree *trillian.Tree) {
+				tree.CheckpointOrigin = "example.com/log\n2025"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "checkpointOriginTrailingSpace",
+			updatefn: func(tree *trillian.Tree) {
+				tree.CheckpointOrigin = "example.com/log "
+			},
+			wantErr: true,
+		},
+		{
+			desc: "ecosystemIDWithoutEcosystem",
+			updatefn: func(tree *trillian.Tree) {
+				tree.EcosystemIds = []string{"a2V5"}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "ecosystemIDRepeated",
+			updatefn: func(tree *trillian.Tree) {
+				tree.EcosystemIds = []string{"ct:a2V5", "ct:a2V5"}
+			},
+			wantErr: true,
+		},
 		// Changes on readonly fields
 		{
 			desc: "TreeId",
@@ -259,6 +421,18 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			updatefn: func(tree *trillian.Tree) { tree.DeleteTime = timestamppb.Now() },
 			wantErr:  true,
 		},
+		{
+			desc: "TemporalShard",
+			updatefn: func(tree *trillian.Tree) {
+				tree.TemporalShard = &trillian.TemporalShard{ShardSet: "llamas"}
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "HasherId",
+			updatefn: func(tree *trillian.Tree) { tree.HasherId = hashers.RFC6962SHA512_256 },
+			wantErr:  true,
+		},
 	}
 	for _, test := range tests {
 		tree := newTree()
@@ -19,53 +19,29 @@ package integration
 import (
 	"context"
 	"database/sql"
-	"net"
-	"sync"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"k8s.io/klog/v2"
-
-	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
-	"github.com/google/trillian/log"
 	"github.com/google/trillian/quota"
-	"github.com/google/trillian/server"
-	"github.com/google/trillian/server/admin"
-	"github.com/google/trillian/server/interceptor"
 	"github.com/google/trillian/storage/mysql"
 	"github.com/google/trillian/storage/testdb"
-	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/trilliantest"
+	"google.golang.org/grpc"
 
 	_ "github.com/go-sql-driver/mysql" // Load MySQL driver
 )
 
-var (
-	sequencerWindow = time.Duration(0)
-	batchSize       = 50
-	// SequencerInterval is the time between runs of the sequencer.
-	SequencerInterval = 500 * time.Millisecond
-	timeSource        = clock.System
-)
+// SequencerInterval is the time between runs of the sequencer.
+var SequencerInterval = 500 * time.Millisecond
 
-// LogEnv is a test environment that contains both a log server and a connection to it.
+// LogEnv is a test environment that contains both a log server and a
+// connection to it. It is a trilliantest.LogEnv, optionally backed by a fresh
+// MySQL database.
 type LogEnv struct {
-	registry        extension.Registry
-	pendingTasks    *sync.WaitGroup
-	grpcServer      *grpc.Server
-	adminServer     *admin.Server
-	logServer       *server.TrillianLogRPCServer
-	LogOperation    log.Operation
-	Sequencer       *log.OperationManager
-	sequencerCancel context.CancelFunc
-	ClientConn      *grpc.ClientConn // TODO(gbelvin): Deprecate.
+	*trilliantest.LogEnv
 
-	Address string
-	Log     trillian.TrillianLogClient
-	Admin   trillian.TrillianAdminClient
-	DB      *sql.DB
-	dbDone  func(context.Context)
+	DB     *sql.DB
+	dbDone func(context.Context)
 }
 
 // NewLogEnv creates a fresh DB, log server, and client. The numSequencers parameter
@@ -118,91 +94,26 @@ func NewLogEnvWithRegistry(ctx context.Context, numSequencers int, registry exte
 }
 
 // NewLogEnvWithRegistryAndGRPCOptions works the same way as NewLogEnv, but allows callers to also set additional grpc.ServerOption and grpc.DialOption values.
+// Only the storage and quota manager of the registry are used.
 func NewLogEnvWithRegistryAndGRPCOptions(ctx context.Context, numSequencers int, registry extension.Registry, serverOpts []grpc.ServerOption, clientOpts []grpc.DialOption) (*LogEnv, error) {
-	// Create the GRPC Server.
-	serverOpts = append(serverOpts, grpc.UnaryInterceptor(interceptor.ErrorWrapper))
-	grpcServer := grpc.NewServer(serverOpts...)
-
-	// Setup the Admin Server.
-	adminServer := admin.New(registry, nil)
-	trillian.RegisterTrillianAdminServer(grpcServer, adminServer)
-
-	// Setup the Log Server.
-	logServer := server.NewTrillianLogRPCServer(registry, timeSource)
-	trillian.RegisterTrillianLogServer(grpcServer, logServer)
-
-	// Create Sequencer.
-	sequencerManager := log.NewSequencerManager(registry, sequencerWindow)
-	var wg sync.WaitGroup
-	var sequencerTask *log.OperationManager
-	ctx, cancel := context.WithCancel(ctx)
-	info := log.OperationInfo{
-		Registry:    registry,
-		BatchSize:   batchSize,
-		NumWorkers:  numSequencers,
-		RunInterval: SequencerInterval,
-		TimeSource:  timeSource,
-	}
-	// Start a live sequencer in a goroutine.
-	sequencerTask = log.NewOperationManager(info, sequencerManager)
-	wg.Add(1)
-	go func(wg *sync.WaitGroup, om *log.OperationManager) {
-		defer wg.Done()
-		om.OperationLoop(ctx)
-	}(&wg, sequencerTask)
-
-	// Listen and start server.
-	addr, lis, err := listen()
+	env, err := trilliantest.NewLogEnv(ctx, trilliantest.Config{
+		NumSequencers:     numSequencers,
+		SequencerInterval: SequencerInterval,
+		QuotaManager:      registry.QuotaManager,
+		AdminStorage:      registry.AdminStorage,
+		LogStorage:        registry.LogStorage,
+		ServerOptions:     serverOpts,
+		DialOptions:       clientOpts,
+	})
 	if err != nil {
-		cancel()
 		return nil, err
 	}
-	wg.Add(1)
-	go func(wg *sync.WaitGroup, grpcServer *grpc.Server, lis net.Listener) {
-		defer wg.Done()
-		if err := grpcServer.Serve(lis); err != nil {
-			klog.Errorf("gRPC server stopped: %v", err)
-			klog.Flush()
-		}
-	}(&wg, grpcServer, lis)
-
-	// Connect to the server.
-	if clientOpts == nil {
-		clientOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	}
-
-	cc, err := grpc.Dial(addr, clientOpts...)
-	if err != nil {
-		cancel()
-		return nil, err
-	}
-
-	return &LogEnv{
-		registry:        registry,
-		pendingTasks:    &wg,
-		grpcServer:      grpcServer,
-		adminServer:     adminServer,
-		logServer:       logServer,
-		Address:         addr,
-		ClientConn:      cc,
-		Log:             trillian.NewTrillianLogClient(cc),
-		Admin:           trillian.NewTrillianAdminClient(cc),
-		LogOperation:    sequencerManager,
-		Sequencer:       sequencerTask,
-		sequencerCancel: cancel,
-	}, nil
+	return &LogEnv{LogEnv: env}, nil
 }
 
 // Close shuts down the server.
 func (env *LogEnv) Close() {
-	if env.sequencerCancel != nil {
-		env.sequencerCancel()
-	}
-	if err := env.ClientConn.Close(); err != nil {
-		klog.Errorf("ClientConn.Close(): %v", err)
-	}
-	env.grpcServer.GracefulStop()
-	env.pendingTasks.Wait()
+	env.LogEnv.Close()
 	if env.dbDone != nil {
 		env.dbDone(context.TODO())
 	}
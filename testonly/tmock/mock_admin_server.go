@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	trillian "github.com/google/trillian"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // MockTrillianAdminServer is a mock of TrillianAdminServer interface.
@@ -35,6 +36,21 @@ func (m *MockTrillianAdminServer) EXPECT() *MockTrillianAdminServerMockRecorder
 	return m.recorder
 }
 
+// CancelOperation mocks base method.
+func (m *MockTrillianAdminServer) CancelOperation(arg0 context.Context, arg1 *trillian.CancelOperationRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOperation", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOperation indicates an expected call of CancelOperation.
+func (mr *MockTrillianAdminServerMockRecorder) CancelOperation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOperation", reflect.TypeOf((*MockTrillianAdminServer)(nil).CancelOperation), arg0, arg1)
+}
+
 // CreateTree mocks base method.
 func (m *MockTrillianAdminServer) CreateTree(arg0 context.Context, arg1 *trillian.CreateTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +81,36 @@ func (mr *MockTrillianAdminServerMockRecorder) DeleteTree(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).DeleteTree), arg0, arg1)
 }
 
+// ExportTree mocks base method.
+func (m *MockTrillianAdminServer) ExportTree(arg0 context.Context, arg1 *trillian.ExportTreeRequest) (*trillian.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTree", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportTree indicates an expected call of ExportTree.
+func (mr *MockTrillianAdminServerMockRecorder) ExportTree(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).ExportTree), arg0, arg1)
+}
+
+// GetOperation mocks base method.
+func (m *MockTrillianAdminServer) GetOperation(arg0 context.Context, arg1 *trillian.GetOperationRequest) (*trillian.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperation", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperation indicates an expected call of GetOperation.
+func (mr *MockTrillianAdminServerMockRecorder) GetOperation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperation", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetOperation), arg0, arg1)
+}
+
 // GetTree mocks base method.
 func (m *MockTrillianAdminServer) GetTree(arg0 context.Context, arg1 *trillian.GetTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +126,51 @@ func (mr *MockTrillianAdminServerMockRecorder) GetTree(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetTree), arg0, arg1)
 }
 
+// HardDeleteTree mocks base method.
+func (m *MockTrillianAdminServer) HardDeleteTree(arg0 context.Context, arg1 *trillian.HardDeleteTreeRequest) (*trillian.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HardDeleteTree", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HardDeleteTree indicates an expected call of HardDeleteTree.
+func (mr *MockTrillianAdminServerMockRecorder) HardDeleteTree(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).HardDeleteTree), arg0, arg1)
+}
+
+// ListIntegrationEvents mocks base method.
+func (m *MockTrillianAdminServer) ListIntegrationEvents(arg0 context.Context, arg1 *trillian.ListIntegrationEventsRequest) (*trillian.ListIntegrationEventsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIntegrationEvents", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListIntegrationEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIntegrationEvents indicates an expected call of ListIntegrationEvents.
+func (mr *MockTrillianAdminServerMockRecorder) ListIntegrationEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIntegrationEvents", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListIntegrationEvents), arg0, arg1)
+}
+
+// ListOperations mocks base method.
+func (m *MockTrillianAdminServer) ListOperations(arg0 context.Context, arg1 *trillian.ListOperationsRequest) (*trillian.ListOperationsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOperations", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListOperationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOperations indicates an expected call of ListOperations.
+func (mr *MockTrillianAdminServerMockRecorder) ListOperations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOperations", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListOperations), arg0, arg1)
+}
+
 // ListTrees mocks base method.
 func (m *MockTrillianAdminServer) ListTrees(arg0 context.Context, arg1 *trillian.ListTreesRequest) (*trillian.ListTreesResponse, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +186,36 @@ func (mr *MockTrillianAdminServerMockRecorder) ListTrees(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrees", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListTrees), arg0, arg1)
 }
 
+// MigrateTree mocks base method.
+func (m *MockTrillianAdminServer) MigrateTree(arg0 context.Context, arg1 *trillian.MigrateTreeRequest) (*trillian.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTree", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrateTree indicates an expected call of MigrateTree.
+func (mr *MockTrillianAdminServerMockRecorder) MigrateTree(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).MigrateTree), arg0, arg1)
+}
+
+// TriggerSequencing mocks base method.
+func (m *MockTrillianAdminServer) TriggerSequencing(arg0 context.Context, arg1 *trillian.TriggerSequencingRequest) (*trillian.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TriggerSequencing", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TriggerSequencing indicates an expected call of TriggerSequencing.
+func (mr *MockTrillianAdminServerMockRecorder) TriggerSequencing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TriggerSequencing", reflect.TypeOf((*MockTrillianAdminServer)(nil).TriggerSequencing), arg0, arg1)
+}
+
 // UndeleteTree mocks base method.
 func (m *MockTrillianAdminServer) UndeleteTree(arg0 context.Context, arg1 *trillian.UndeleteTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +65,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetConsistencyProof(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsistencyProof", reflect.TypeOf((*MockTrillianLogServer)(nil).GetConsistencyProof), arg0, arg1)
 }
 
+// GetConsistencyProofByRootHash mocks base method.
+func (m *MockTrillianLogServer) GetConsistencyProofByRootHash(arg0 context.Context, arg1 *trillian.GetConsistencyProofByRootHashRequest) (*trillian.GetConsistencyProofByRootHashResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsistencyProofByRootHash", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetConsistencyProofByRootHashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsistencyProofByRootHash indicates an expected call of GetConsistencyProofByRootHash.
+func (mr *MockTrillianLogServerMockRecorder) GetConsistencyProofByRootHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsistencyProofByRootHash", reflect.TypeOf((*MockTrillianLogServer)(nil).GetConsistencyProofByRootHash), arg0, arg1)
+}
+
 // GetEntryAndProof mocks base method.
 func (m *MockTrillianLogServer) GetEntryAndProof(arg0 context.Context, arg1 *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +125,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetInclusionProofByHash(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProofByHash", reflect.TypeOf((*MockTrillianLogServer)(nil).GetInclusionProofByHash), arg0, arg1)
 }
 
+// GetInclusionProofByIdentityHash mocks base method.
+func (m *MockTrillianLogServer) GetInclusionProofByIdentityHash(arg0 context.Context, arg1 *trillian.GetInclusionProofByIdentityHashRequest) (*trillian.GetInclusionProofByIdentityHashResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInclusionProofByIdentityHash", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetInclusionProofByIdentityHashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInclusionProofByIdentityHash indicates an expected call of GetInclusionProofByIdentityHash.
+func (mr *MockTrillianLogServerMockRecorder) GetInclusionProofByIdentityHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProofByIdentityHash", reflect.TypeOf((*MockTrillianLogServer)(nil).GetInclusionProofByIdentityHash), arg0, arg1)
+}
+
 // GetLatestSignedLogRoot mocks base method.
 func (m *MockTrillianLogServer) GetLatestSignedLogRoot(arg0 context.Context, arg1 *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +155,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetLatestSignedLogRoot(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSignedLogRoot", reflect.TypeOf((*MockTrillianLogServer)(nil).GetLatestSignedLogRoot), arg0, arg1)
 }
 
+// GetLeavesByIndices mocks base method.
+func (m *MockTrillianLogServer) GetLeavesByIndices(arg0 context.Context, arg1 *trillian.GetLeavesByIndicesRequest) (*trillian.GetLeavesByIndicesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeavesByIndices", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetLeavesByIndicesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesByIndices indicates an expected call of GetLeavesByIndices.
+func (mr *MockTrillianLogServerMockRecorder) GetLeavesByIndices(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByIndices", reflect.TypeOf((*MockTrillianLogServer)(nil).GetLeavesByIndices), arg0, arg1)
+}
+
 // GetLeavesByRange mocks base method.
 func (m *MockTrillianLogServer) GetLeavesByRange(arg0 context.Context, arg1 *trillian.GetLeavesByRangeRequest) (*trillian.GetLeavesByRangeResponse, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +185,36 @@ func (mr *MockTrillianLogServerMockRecorder) GetLeavesByRange(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRange", reflect.TypeOf((*MockTrillianLogServer)(nil).GetLeavesByRange), arg0, arg1)
 }
 
+// GetSequencedLeafCount mocks base method.
+func (m *MockTrillianLogServer) GetSequencedLeafCount(arg0 context.Context, arg1 *trillian.GetSequencedLeafCountRequest) (*trillian.GetSequencedLeafCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSequencedLeafCount", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetSequencedLeafCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSequencedLeafCount indicates an expected call of GetSequencedLeafCount.
+func (mr *MockTrillianLogServerMockRecorder) GetSequencedLeafCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSequencedLeafCount", reflect.TypeOf((*MockTrillianLogServer)(nil).GetSequencedLeafCount), arg0, arg1)
+}
+
+// GetTreeStats mocks base method.
+func (m *MockTrillianLogServer) GetTreeStats(arg0 context.Context, arg1 *trillian.GetTreeStatsRequest) (*trillian.GetTreeStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeStats", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetTreeStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeStats indicates an expected call of GetTreeStats.
+func (mr *MockTrillianLogServerMockRecorder) GetTreeStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeStats", reflect.TypeOf((*MockTrillianLogServer)(nil).GetTreeStats), arg0, arg1)
+}
+
 // InitLog mocks base method.
 func (m *MockTrillianLogServer) InitLog(arg0 context.Context, arg1 *trillian.InitLogRequest) (*trillian.InitLogResponse, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +230,36 @@ func (mr *MockTrillianLogServerMockRecorder) InitLog(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitLog", reflect.TypeOf((*MockTrillianLogServer)(nil).InitLog), arg0, arg1)
 }
 
+// ListSignedLogRoots mocks base method.
+func (m *MockTrillianLogServer) ListSignedLogRoots(arg0 context.Context, arg1 *trillian.ListSignedLogRootsRequest) (*trillian.ListSignedLogRootsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSignedLogRoots", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListSignedLogRootsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSignedLogRoots indicates an expected call of ListSignedLogRoots.
+func (mr *MockTrillianLogServerMockRecorder) ListSignedLogRoots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSignedLogRoots", reflect.TypeOf((*MockTrillianLogServer)(nil).ListSignedLogRoots), arg0, arg1)
+}
+
+// GetRangeInclusionProof mocks base method.
+func (m *MockTrillianLogServer) GetRangeInclusionProof(arg0 context.Context, arg1 *trillian.GetRangeInclusionProofRequest) (*trillian.GetRangeInclusionProofResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRangeInclusionProof", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetRangeInclusionProofResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRangeInclusionProof indicates an expected call of GetRangeInclusionProof.
+func (mr *MockTrillianLogServerMockRecorder) GetRangeInclusionProof(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeInclusionProof", reflect.TypeOf((*MockTrillianLogServer)(nil).GetRangeInclusionProof), arg0, arg1)
+}
+
 // QueueLeaf mocks base method.
 func (m *MockTrillianLogServer) QueueLeaf(arg0 context.Context, arg1 *trillian.QueueLeafRequest) (*trillian.QueueLeafResponse, error) {
 	m.ctrl.T.Helper()
@@ -169,3 +274,47 @@ func (mr *MockTrillianLogServerMockRecorder) QueueLeaf(arg0, arg1 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueLeaf", reflect.TypeOf((*MockTrillianLogServer)(nil).QueueLeaf), arg0, arg1)
 }
+
+// RedactLeaf mocks base method.
+func (m *MockTrillianLogServer) RedactLeaf(arg0 context.Context, arg1 *trillian.RedactLeafRequest) (*trillian.RedactLeafResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedactLeaf", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.RedactLeafResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedactLeaf indicates an expected call of RedactLeaf.
+func (mr *MockTrillianLogServerMockRecorder) RedactLeaf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactLeaf", reflect.TypeOf((*MockTrillianLogServer)(nil).RedactLeaf), arg0, arg1)
+}
+
+// UpdateLeafExtraData mocks base method.
+func (m *MockTrillianLogServer) UpdateLeafExtraData(arg0 context.Context, arg1 *trillian.UpdateLeafExtraDataRequest) (*trillian.UpdateLeafExtraDataResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLeafExtraData", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.UpdateLeafExtraDataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLeafExtraData indicates an expected call of UpdateLeafExtraData.
+func (mr *MockTrillianLogServerMockRecorder) UpdateLeafExtraData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLeafExtraData", reflect.TypeOf((*MockTrillianLogServer)(nil).UpdateLeafExtraData), arg0, arg1)
+}
+
+// WatchSignedLogRoots mocks base method.
+func (m *MockTrillianLogServer) WatchSignedLogRoots(arg0 *trillian.WatchSignedLogRootsRequest, arg1 trillian.TrillianLog_WatchSignedLogRootsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchSignedLogRoots", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchSignedLogRoots indicates an expected call of WatchSignedLogRoots.
+func (mr *MockTrillianLogServerMockRecorder) WatchSignedLogRoots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSignedLogRoots", reflect.TypeOf((*MockTrillianLogServer)(nil).WatchSignedLogRoots), arg0, arg1)
+}
@@ -286,6 +286,57 @@ func (TreeType) EnumDescriptor() ([]byte, []int) {
 	return file_trillian_proto_rawDescGZIP(), []int{3}
 }
 
+// Order in which the queued leaves of a LOG tree are integrated.
+type DequeueOrder int32
+
+const (
+	// The storage chooses the order, which may favour throughput over strict
+	// ordering, e.g. by dequeuing from a subset of buckets at a time.
+	DequeueOrder_STORAGE_DEQUEUE_ORDER DequeueOrder = 0
+	// Leaves are integrated strictly in the order of their queue timestamps,
+	// with ties broken by leaf identity hash, on every storage.
+	DequeueOrder_FIFO_DEQUEUE_ORDER DequeueOrder = 1
+)
+
+// Enum value maps for DequeueOrder.
+var (
+	DequeueOrder_name = map[int32]string{
+		0: "STORAGE_DEQUEUE_ORDER",
+		1: "FIFO_DEQUEUE_ORDER",
+	}
+	DequeueOrder_value = map[string]int32{
+		"STORAGE_DEQUEUE_ORDER": 0,
+		"FIFO_DEQUEUE_ORDER":    1,
+	}
+)
+
+func (x DequeueOrder) Enum() *DequeueOrder {
+	p := new(DequeueOrder)
+	*p = x
+	return p
+}
+
+func (x DequeueOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DequeueOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_trillian_proto_enumTypes[4].Descriptor()
+}
+
+func (DequeueOrder) Type() protoreflect.EnumType {
+	return &file_trillian_proto_enumTypes[4]
+}
+
+func (x DequeueOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DequeueOrder.Descriptor instead.
+func (DequeueOrder) EnumDescriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{4}
+}
+
 // Represents a tree.
 // Readonly attributes are assigned at tree creation, after which they may not
 // be modified.
@@ -331,7 +382,95 @@ type Tree struct {
 	Deleted bool `protobuf:"varint,19,opt,name=deleted,proto3" json:"deleted,omitempty"`
 	// Time of tree deletion, if any.
 	// Readonly.
-	DeleteTime    *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	DeleteTime *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	// If true, the signer automatically transitions the tree from DRAINING to
+	// FROZEN once all queued leaves have been integrated and a root covering
+	// them has been published.
+	// Optional.
+	AutoFreeze bool `protobuf:"varint,21,opt,name=auto_freeze,json=autoFreeze,proto3" json:"auto_freeze,omitempty"`
+	// The maximum number of leaves the tree may contain. Once the tree,
+	// together with the leaves queued for it, reaches this size new leaves are
+	// rejected with FAILED_PRECONDITION, and the signer never integrates leaves
+	// beyond it. If zero, the size is unlimited.
+	// Optional.
+	MaxTreeSize int64 `protobuf:"varint,22,opt,name=max_tree_size,json=maxTreeSize,proto3" json:"max_tree_size,omitempty"`
+	// If set, the tree is one of a family of temporally sharded trees, and is
+	// intended to hold entries whose timestamps fall within the given window.
+	// Trillian doesn't interpret leaf contents, so personalities are responsible
+	// for routing entries to the right shard.
+	// Readonly after Tree creation.
+	TemporalShard *TemporalShard `protobuf:"bytes,23,opt,name=temporal_shard,json=temporalShard,proto3" json:"temporal_shard,omitempty"`
+	// The team or person responsible for the tree, e.g. "ct-team". Included in
+	// admin audit logs and signer alerts so that problems can be routed to
+	// whoever operates the tree.
+	// Optional.
+	Owner string `protobuf:"bytes,24,opt,name=owner,proto3" json:"owner,omitempty"`
+	// How to reach the owner of the tree, e.g. an email address or paging
+	// alias.
+	// Optional.
+	Contact string `protobuf:"bytes,25,opt,name=contact,proto3" json:"contact,omitempty"`
+	// If true, the extra_data of the tree's leaves may be replaced with
+	// UpdateLeafExtraData.
+	// Optional.
+	MutableExtraData bool `protobuf:"varint,26,opt,name=mutable_extra_data,json=mutableExtraData,proto3" json:"mutable_extra_data,omitempty"`
+	// If set, the leaf_value and extra_data of the tree's leaves are encrypted
+	// with a per-tree data key before being written to storage. Leaf hashes are
+	// computed over the plaintext, so encryption is invisible to clients.
+	// Optional, and can't be changed once the tree is created.
+	LeafEncryption *LeafEncryption `protobuf:"bytes,27,opt,name=leaf_encryption,json=leafEncryption,proto3" json:"leaf_encryption,omitempty"`
+	// If true, the leaf_value of the tree's leaves may be replaced with a
+	// tombstone with RedactLeaf.
+	// Optional.
+	AllowRedaction bool `protobuf:"varint,28,opt,name=allow_redaction,json=allowRedaction,proto3" json:"allow_redaction,omitempty"`
+	// Identifies the hasher which computes the Merkle tree of the log, as
+	// registered with the merkle/hashers package, e.g. "RFC6962_SHA512_256".
+	// Empty means RFC 6962 with SHA-256.
+	// Optional, and can't be changed once the tree is created.
+	HasherId string `protobuf:"bytes,29,opt,name=hasher_id,json=hasherId,proto3" json:"hasher_id,omitempty"`
+	// The delay between queuing and integration within which the tree's leaves
+	// should be integrated, e.g. the maximum merge delay promised by the log.
+	// If set, the signer tracks the fraction of leaves integrated within it.
+	// Optional.
+	MergeDelayTarget *durationpb.Duration `protobuf:"bytes,30,opt,name=merge_delay_target,json=mergeDelayTarget,proto3" json:"merge_delay_target,omitempty"`
+	// Time at which an immediate sequencing pass was requested with
+	// TriggerSequencing, if it hasn't been handled by the signer yet. The
+	// signer integrates the tree's queued leaves regardless of the guard
+	// window, then clears it.
+	// Readonly.
+	SequencingRequestedTime *timestamppb.Timestamp `protobuf:"bytes,31,opt,name=sequencing_requested_time,json=sequencingRequestedTime,proto3" json:"sequencing_requested_time,omitempty"`
+	// Order in which the tree's queued leaves are integrated. Only valid for
+	// LOG trees.
+	// Optional.
+	DequeueOrder DequeueOrder `protobuf:"varint,32,opt,name=dequeue_order,json=dequeueOrder,proto3,enum=trillian.DequeueOrder" json:"dequeue_order,omitempty"`
+	// Names of TrillianLog RPCs which are refused for the tree with
+	// PERMISSION_DENIED, e.g. "GetLeavesByRange" for a log whose leaves mustn't
+	// be enumerated, or "QueueLeaf" for a mirror. Only unary RPCs can be
+	// disabled.
+	// Optional.
+	DisabledMethods []string `protobuf:"bytes,33,rep,name=disabled_methods,json=disabledMethods,proto3" json:"disabled_methods,omitempty"`
+	// If true, leaves may be queued or added without a leaf_value, with their
+	// merkle_leaf_hash set by the caller instead, for personalities which keep
+	// leaf values elsewhere and only need their hashes in the tree. The server
+	// can't check that such hashes are the hashes of any leaf values.
+	// Optional.
+	AllowPrehashedLeaves bool `protobuf:"varint,34,opt,name=allow_prehashed_leaves,json=allowPrehashedLeaves,proto3" json:"allow_prehashed_leaves,omitempty"`
+	// Names of the validators, as registered with the leafvalidators package,
+	// which check the leaves queued to the tree, in addition to those
+	// registered for all trees of its type. Leaves which any of them rejects
+	// are refused with INVALID_ARGUMENT.
+	// Optional.
+	LeafValidators []string `protobuf:"bytes,35,rep,name=leaf_validators,json=leafValidators,proto3" json:"leaf_validators,omitempty"`
+	// Origin line of the checkpoints of the tree, which identifies it in the
+	// signed notes that its personality emits, e.g. "example.com/log2025"
+	// (see https://c2sp.org/tlog-checkpoint). Must be unique among the trees
+	// of the deployment, and must not contain whitespace other than spaces.
+	// Optional.
+	CheckpointOrigin string `protobuf:"bytes,36,opt,name=checkpoint_origin,json=checkpointOrigin,proto3" json:"checkpoint_origin,omitempty"`
+	// Identifiers of the tree in the ecosystems which it is part of, each
+	// "<ecosystem>:<identifier>", e.g. "ct:<base64 log ID>". Each must be unique
+	// among the trees of the deployment.
+	// Optional.
+	EcosystemIds  []string `protobuf:"bytes,37,rep,name=ecosystem_ids,json=ecosystemIds,proto3" json:"ecosystem_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -443,6 +582,250 @@ func (x *Tree) GetDeleteTime() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Tree) GetAutoFreeze() bool {
+	if x != nil {
+		return x.AutoFreeze
+	}
+	return false
+}
+
+func (x *Tree) GetMaxTreeSize() int64 {
+	if x != nil {
+		return x.MaxTreeSize
+	}
+	return 0
+}
+
+func (x *Tree) GetTemporalShard() *TemporalShard {
+	if x != nil {
+		return x.TemporalShard
+	}
+	return nil
+}
+
+func (x *Tree) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Tree) GetContact() string {
+	if x != nil {
+		return x.Contact
+	}
+	return ""
+}
+
+func (x *Tree) GetMutableExtraData() bool {
+	if x != nil {
+		return x.MutableExtraData
+	}
+	return false
+}
+
+func (x *Tree) GetLeafEncryption() *LeafEncryption {
+	if x != nil {
+		return x.LeafEncryption
+	}
+	return nil
+}
+
+func (x *Tree) GetAllowRedaction() bool {
+	if x != nil {
+		return x.AllowRedaction
+	}
+	return false
+}
+
+func (x *Tree) GetHasherId() string {
+	if x != nil {
+		return x.HasherId
+	}
+	return ""
+}
+
+func (x *Tree) GetMergeDelayTarget() *durationpb.Duration {
+	if x != nil {
+		return x.MergeDelayTarget
+	}
+	return nil
+}
+
+func (x *Tree) GetSequencingRequestedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SequencingRequestedTime
+	}
+	return nil
+}
+
+func (x *Tree) GetDequeueOrder() DequeueOrder {
+	if x != nil {
+		return x.DequeueOrder
+	}
+	return DequeueOrder_STORAGE_DEQUEUE_ORDER
+}
+
+func (x *Tree) GetDisabledMethods() []string {
+	if x != nil {
+		return x.DisabledMethods
+	}
+	return nil
+}
+
+func (x *Tree) GetAllowPrehashedLeaves() bool {
+	if x != nil {
+		return x.AllowPrehashedLeaves
+	}
+	return false
+}
+
+func (x *Tree) GetLeafValidators() []string {
+	if x != nil {
+		return x.LeafValidators
+	}
+	return nil
+}
+
+func (x *Tree) GetCheckpointOrigin() string {
+	if x != nil {
+		return x.CheckpointOrigin
+	}
+	return ""
+}
+
+func (x *Tree) GetEcosystemIds() []string {
+	if x != nil {
+		return x.EcosystemIds
+	}
+	return nil
+}
+
+// LeafEncryption describes the data key which encrypts a tree's leaf data at
+// rest.
+type LeafEncryption struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The URI of the key encryption key which wraps the data key, in a form
+	// understood by the server's key wrapper.
+	KeyUri string `protobuf:"bytes,1,opt,name=key_uri,json=keyUri,proto3" json:"key_uri,omitempty"`
+	// The tree's data key, encrypted by the key encryption key. If empty on
+	// CreateTree, the server generates a data key.
+	WrappedKey    []byte `protobuf:"bytes,2,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeafEncryption) Reset() {
+	*x = LeafEncryption{}
+	mi := &file_trillian_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeafEncryption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeafEncryption) ProtoMessage() {}
+
+func (x *LeafEncryption) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeafEncryption.ProtoReflect.Descriptor instead.
+func (*LeafEncryption) Descriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LeafEncryption) GetKeyUri() string {
+	if x != nil {
+		return x.KeyUri
+	}
+	return ""
+}
+
+func (x *LeafEncryption) GetWrappedKey() []byte {
+	if x != nil {
+		return x.WrappedKey
+	}
+	return nil
+}
+
+// TemporalShard describes a tree's place within a family of temporally sharded
+// trees (a "shard set"), such as the yearly shards of a Certificate
+// Transparency log. Each shard holds entries whose timestamps fall within its
+// validity window.
+type TemporalShard struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the shard set the tree belongs to.
+	ShardSet string `protobuf:"bytes,1,opt,name=shard_set,json=shardSet,proto3" json:"shard_set,omitempty"`
+	// Start of the validity window, inclusive.
+	NotAfterStart *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=not_after_start,json=notAfterStart,proto3" json:"not_after_start,omitempty"`
+	// End of the validity window, exclusive.
+	NotAfterLimit *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=not_after_limit,json=notAfterLimit,proto3" json:"not_after_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemporalShard) Reset() {
+	*x = TemporalShard{}
+	mi := &file_trillian_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemporalShard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemporalShard) ProtoMessage() {}
+
+func (x *TemporalShard) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemporalShard.ProtoReflect.Descriptor instead.
+func (*TemporalShard) Descriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TemporalShard) GetShardSet() string {
+	if x != nil {
+		return x.ShardSet
+	}
+	return ""
+}
+
+func (x *TemporalShard) GetNotAfterStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfterStart
+	}
+	return nil
+}
+
+func (x *TemporalShard) GetNotAfterLimit() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfterLimit
+	}
+	return nil
+}
+
 // SignedLogRoot represents a commitment by a Log to a particular tree.
 //
 // Note that the signature itself is no-longer provided by Trillian since
@@ -494,7 +877,7 @@ type SignedLogRoot struct {
 
 func (x *SignedLogRoot) Reset() {
 	*x = SignedLogRoot{}
-	mi := &file_trillian_proto_msgTypes[1]
+	mi := &file_trillian_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -506,7 +889,7 @@ func (x *SignedLogRoot) String() string {
 func (*SignedLogRoot) ProtoMessage() {}
 
 func (x *SignedLogRoot) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[1]
+	mi := &file_trillian_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -519,7 +902,7 @@ func (x *SignedLogRoot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignedLogRoot.ProtoReflect.Descriptor instead.
 func (*SignedLogRoot) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{1}
+	return file_trillian_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SignedLogRoot) GetLogRoot() []byte {
@@ -544,7 +927,7 @@ type Proof struct {
 
 func (x *Proof) Reset() {
 	*x = Proof{}
-	mi := &file_trillian_proto_msgTypes[2]
+	mi := &file_trillian_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -556,7 +939,7 @@ func (x *Proof) String() string {
 func (*Proof) ProtoMessage() {}
 
 func (x *Proof) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[2]
+	mi := &file_trillian_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -569,7 +952,7 @@ func (x *Proof) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proof.ProtoReflect.Descriptor instead.
 func (*Proof) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{2}
+	return file_trillian_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Proof) GetLeafIndex() int64 {
@@ -590,7 +973,7 @@ var File_trillian_proto protoreflect.FileDescriptor
 
 const file_trillian_proto_rawDesc = "" +
 	"\n" +
-	"\x0etrillian.proto\x12\btrillian\x1a\x19google/protobuf/any.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf1\x05\n" +
+	"\x0etrillian.proto\x12\btrillian\x1a\x19google/protobuf/any.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x97\f\n" +
 	"\x04Tree\x12\x17\n" +
 	"\atree_id\x18\x01 \x01(\x03R\x06treeId\x122\n" +
 	"\n" +
@@ -606,9 +989,35 @@ const file_trillian_proto_rawDesc = "" +
 	"updateTime\x12\x18\n" +
 	"\adeleted\x18\x13 \x01(\bR\adeleted\x12;\n" +
 	"\vdelete_time\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"deleteTimeJ\x04\b\x04\x10\bJ\x04\b\n" +
+	"deleteTime\x12\x1f\n" +
+	"\vauto_freeze\x18\x15 \x01(\bR\n" +
+	"autoFreeze\x12\"\n" +
+	"\rmax_tree_size\x18\x16 \x01(\x03R\vmaxTreeSize\x12>\n" +
+	"\x0etemporal_shard\x18\x17 \x01(\v2\x17.trillian.TemporalShardR\rtemporalShard\x12\x14\n" +
+	"\x05owner\x18\x18 \x01(\tR\x05owner\x12\x18\n" +
+	"\acontact\x18\x19 \x01(\tR\acontact\x12,\n" +
+	"\x12mutable_extra_data\x18\x1a \x01(\bR\x10mutableExtraData\x12A\n" +
+	"\x0fleaf_encryption\x18\x1b \x01(\v2\x18.trillian.LeafEncryptionR\x0eleafEncryption\x12'\n" +
+	"\x0fallow_redaction\x18\x1c \x01(\bR\x0eallowRedaction\x12\x1b\n" +
+	"\thasher_id\x18\x1d \x01(\tR\bhasherId\x12G\n" +
+	"\x12merge_delay_target\x18\x1e \x01(\v2\x19.google.protobuf.DurationR\x10mergeDelayTarget\x12V\n" +
+	"\x19sequencing_requested_time\x18\x1f \x01(\v2\x1a.google.protobuf.TimestampR\x17sequencingRequestedTime\x12;\n" +
+	"\rdequeue_order\x18  \x01(\x0e2\x16.trillian.DequeueOrderR\fdequeueOrder\x12)\n" +
+	"\x10disabled_methods\x18! \x03(\tR\x0fdisabledMethods\x124\n" +
+	"\x16allow_prehashed_leaves\x18\" \x01(\bR\x14allowPrehashedLeaves\x12'\n" +
+	"\x0fleaf_validators\x18# \x03(\tR\x0eleafValidators\x12+\n" +
+	"\x11checkpoint_origin\x18$ \x01(\tR\x10checkpointOrigin\x12#\n" +
+	"\recosystem_ids\x18% \x03(\tR\fecosystemIdsJ\x04\b\x04\x10\bJ\x04\b\n" +
 	"\x10\rJ\x04\b\x0e\x10\x0fJ\x04\b\x12\x10\x13R\x1ecreate_time_millis_since_epochR\x10duplicate_policyR\x0ehash_algorithmR\rhash_strategyR\vprivate_keyR\n" +
-	"public_keyR\x13signature_algorithmR\x16signature_cipher_suiteR\x1eupdate_time_millis_since_epoch\"\x9d\x01\n" +
+	"public_keyR\x13signature_algorithmR\x16signature_cipher_suiteR\x1eupdate_time_millis_since_epoch\"J\n" +
+	"\x0eLeafEncryption\x12\x17\n" +
+	"\akey_uri\x18\x01 \x01(\tR\x06keyUri\x12\x1f\n" +
+	"\vwrapped_key\x18\x02 \x01(\fR\n" +
+	"wrappedKey\"\xb4\x01\n" +
+	"\rTemporalShard\x12\x1b\n" +
+	"\tshard_set\x18\x01 \x01(\tR\bshardSet\x12B\n" +
+	"\x0fnot_after_start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\rnotAfterStart\x12B\n" +
+	"\x0fnot_after_limit\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rnotAfterLimit\"\x9d\x01\n" +
 	"\rSignedLogRoot\x12\x19\n" +
 	"\blog_root\x18\b \x01(\fR\alogRootJ\x04\b\x01\x10\bJ\x04\b\t\x10\n" +
 	"R\bkey_hintR\x06log_idR\x12log_root_signatureR\troot_hashR\tsignatureR\x0ftimestamp_nanosR\rtree_revisionR\ttree_size\"P\n" +
@@ -639,7 +1048,10 @@ const file_trillian_proto_rawDesc = "" +
 	"\bTreeType\x12\x15\n" +
 	"\x11UNKNOWN_TREE_TYPE\x10\x00\x12\a\n" +
 	"\x03LOG\x10\x01\x12\x12\n" +
-	"\x0ePREORDERED_LOG\x10\x03\"\x04\b\x02\x10\x02*\x03MAPBH\n" +
+	"\x0ePREORDERED_LOG\x10\x03\"\x04\b\x02\x10\x02*\x03MAP*A\n" +
+	"\fDequeueOrder\x12\x19\n" +
+	"\x15STORAGE_DEQUEUE_ORDER\x10\x00\x12\x16\n" +
+	"\x12FIFO_DEQUEUE_ORDER\x10\x01BH\n" +
 	"\x19com.google.trillian.protoB\rTrillianProtoP\x01Z\x1agithub.com/google/trillianb\x06proto3"
 
 var (
@@ -654,33 +1066,43 @@ func file_trillian_proto_rawDescGZIP() []byte {
 	return file_trillian_proto_rawDescData
 }
 
-var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_trillian_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_trillian_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_trillian_proto_goTypes = []any{
 	(LogRootFormat)(0),            // 0: trillian.LogRootFormat
 	(HashStrategy)(0),             // 1: trillian.HashStrategy
 	(TreeState)(0),                // 2: trillian.TreeState
 	(TreeType)(0),                 // 3: trillian.TreeType
-	(*Tree)(nil),                  // 4: trillian.Tree
-	(*SignedLogRoot)(nil),         // 5: trillian.SignedLogRoot
-	(*Proof)(nil),                 // 6: trillian.Proof
-	(*anypb.Any)(nil),             // 7: google.protobuf.Any
-	(*durationpb.Duration)(nil),   // 8: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(DequeueOrder)(0),             // 4: trillian.DequeueOrder
+	(*Tree)(nil),                  // 5: trillian.Tree
+	(*LeafEncryption)(nil),        // 6: trillian.LeafEncryption
+	(*TemporalShard)(nil),         // 7: trillian.TemporalShard
+	(*SignedLogRoot)(nil),         // 8: trillian.SignedLogRoot
+	(*Proof)(nil),                 // 9: trillian.Proof
+	(*anypb.Any)(nil),             // 10: google.protobuf.Any
+	(*durationpb.Duration)(nil),   // 11: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
 }
 var file_trillian_proto_depIdxs = []int32{
-	2, // 0: trillian.Tree.tree_state:type_name -> trillian.TreeState
-	3, // 1: trillian.Tree.tree_type:type_name -> trillian.TreeType
-	7, // 2: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
-	8, // 3: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
-	9, // 4: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
-	9, // 5: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
-	9, // 6: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	2,  // 0: trillian.Tree.tree_state:type_name -> trillian.TreeState
+	3,  // 1: trillian.Tree.tree_type:type_name -> trillian.TreeType
+	10, // 2: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
+	11, // 3: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
+	12, // 4: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
+	12, // 5: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
+	12, // 6: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
+	7,  // 7: trillian.Tree.temporal_shard:type_name -> trillian.TemporalShard
+	6,  // 8: trillian.Tree.leaf_encryption:type_name -> trillian.LeafEncryption
+	11, // 9: trillian.Tree.merge_delay_target:type_name -> google.protobuf.Duration
+	12, // 10: trillian.Tree.sequencing_requested_time:type_name -> google.protobuf.Timestamp
+	4,  // 11: trillian.Tree.dequeue_order:type_name -> trillian.DequeueOrder
+	12, // 12: trillian.TemporalShard.not_after_start:type_name -> google.protobuf.Timestamp
+	12, // 13: trillian.TemporalShard.not_after_limit:type_name -> google.protobuf.Timestamp
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_trillian_proto_init() }
@@ -693,8 +1115,8 @@ func file_trillian_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trillian_proto_rawDesc), len(file_trillian_proto_rawDesc)),
-			NumEnums:      4,
-			NumMessages:   3,
+			NumEnums:      5,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
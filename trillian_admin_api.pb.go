@@ -21,9 +21,13 @@
 package trillian
 
 import (
+	status "google.golang.org/genproto/googleapis/rpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -36,6 +40,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Kind of action performed by an operation.
+type Operation_Kind int32
+
+const (
+	Operation_UNKNOWN_KIND Operation_Kind = 0
+	// Permanently removes a soft-deleted tree, see HardDeleteTree.
+	Operation_HARD_DELETE Operation_Kind = 1
+	// Writes a log's leaves to a file, see ExportTree.
+	Operation_EXPORT Operation_Kind = 2
+	// Copies a log to another storage, see MigrateTree.
+	Operation_MIGRATE Operation_Kind = 3
+)
+
+// Enum value maps for Operation_Kind.
+var (
+	Operation_Kind_name = map[int32]string{
+		0: "UNKNOWN_KIND",
+		1: "HARD_DELETE",
+		2: "EXPORT",
+		3: "MIGRATE",
+	}
+	Operation_Kind_value = map[string]int32{
+		"UNKNOWN_KIND": 0,
+		"HARD_DELETE":  1,
+		"EXPORT":       2,
+		"MIGRATE":      3,
+	}
+)
+
+func (x Operation_Kind) Enum() *Operation_Kind {
+	p := new(Operation_Kind)
+	*p = x
+	return p
+}
+
+func (x Operation_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Operation_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_trillian_admin_api_proto_enumTypes[0].Descriptor()
+}
+
+func (Operation_Kind) Type() protoreflect.EnumType {
+	return &file_trillian_admin_api_proto_enumTypes[0]
+}
+
+func (x Operation_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Operation_Kind.Descriptor instead.
+func (Operation_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{8, 0}
+}
+
 // ListTrees request.
 // No filters or pagination options are provided.
 type ListTreesRequest struct {
@@ -371,11 +431,714 @@ func (x *UndeleteTreeRequest) GetTreeId() int64 {
 	return 0
 }
 
+// TriggerSequencing request.
+type TriggerSequencingRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the log to sequence.
+	TreeId        int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerSequencingRequest) Reset() {
+	*x = TriggerSequencingRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerSequencingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerSequencingRequest) ProtoMessage() {}
+
+func (x *TriggerSequencingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerSequencingRequest.ProtoReflect.Descriptor instead.
+func (*TriggerSequencingRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TriggerSequencingRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// Operation is a long-running admin action on a tree, which runs in the
+// background of the admin server that started it, modelled on
+// google.longrunning.Operation.
+type Operation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the operation, unique within the admin server which runs it, of
+	// the form "operations/<id>".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// ID of the tree the operation acts on.
+	TreeId int64 `protobuf:"varint,2,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Kind of action performed by the operation.
+	Kind Operation_Kind `protobuf:"varint,3,opt,name=kind,proto3,enum=trillian.Operation_Kind" json:"kind,omitempty"`
+	// Time at which the operation was started.
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// Time at which the operation last made progress, or finished.
+	UpdateTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// Units of work done so far, out of total_units, e.g. leaves copied. If
+	// total_units is 0, the total amount of work isn't known yet.
+	DoneUnits  int64 `protobuf:"varint,6,opt,name=done_units,json=doneUnits,proto3" json:"done_units,omitempty"`
+	TotalUnits int64 `protobuf:"varint,7,opt,name=total_units,json=totalUnits,proto3" json:"total_units,omitempty"`
+	// Whether the operation has finished, successfully or not.
+	Done bool `protobuf:"varint,8,opt,name=done,proto3" json:"done,omitempty"`
+	// The error of the operation, if it failed or was cancelled, in which case
+	// its code is CANCELLED. Only set if done is true.
+	Error *status.Status `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// Describes the result of a successful operation, e.g. the path of the
+	// file written by an export.
+	Result        string `protobuf:"bytes,10,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_trillian_admin_api_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Operation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Operation) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *Operation) GetKind() Operation_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Operation_UNKNOWN_KIND
+}
+
+func (x *Operation) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Operation) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Operation) GetDoneUnits() int64 {
+	if x != nil {
+		return x.DoneUnits
+	}
+	return 0
+}
+
+func (x *Operation) GetTotalUnits() int64 {
+	if x != nil {
+		return x.TotalUnits
+	}
+	return 0
+}
+
+func (x *Operation) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *Operation) GetError() *status.Status {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *Operation) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+// HardDeleteTree request.
+type HardDeleteTreeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the soft-deleted tree to permanently delete.
+	TreeId        int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HardDeleteTreeRequest) Reset() {
+	*x = HardDeleteTreeRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HardDeleteTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HardDeleteTreeRequest) ProtoMessage() {}
+
+func (x *HardDeleteTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HardDeleteTreeRequest.ProtoReflect.Descriptor instead.
+func (*HardDeleteTreeRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *HardDeleteTreeRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// ExportTree request.
+type ExportTreeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the log to export.
+	TreeId        int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTreeRequest) Reset() {
+	*x = ExportTreeRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTreeRequest) ProtoMessage() {}
+
+func (x *ExportTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTreeRequest.ProtoReflect.Descriptor instead.
+func (*ExportTreeRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExportTreeRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// MigrateTree request.
+type MigrateTreeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the log to migrate.
+	TreeId        int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MigrateTreeRequest) Reset() {
+	*x = MigrateTreeRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrateTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateTreeRequest) ProtoMessage() {}
+
+func (x *MigrateTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateTreeRequest.ProtoReflect.Descriptor instead.
+func (*MigrateTreeRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MigrateTreeRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// GetOperation request.
+type GetOperationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the operation.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOperationRequest) Reset() {
+	*x = GetOperationRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOperationRequest) ProtoMessage() {}
+
+func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOperationRequest.ProtoReflect.Descriptor instead.
+func (*GetOperationRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOperationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// ListOperations request.
+type ListOperationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// If set, only the operations on this tree are returned.
+	TreeId        int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsRequest) Reset() {
+	*x = ListOperationsRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsRequest) ProtoMessage() {}
+
+func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsRequest.ProtoReflect.Descriptor instead.
+func (*ListOperationsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListOperationsRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// ListOperations response.
+type ListOperationsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The operations, most recently started first.
+	Operations    []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsResponse) Reset() {
+	*x = ListOperationsResponse{}
+	mi := &file_trillian_admin_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsResponse) ProtoMessage() {}
+
+func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsResponse.ProtoReflect.Descriptor instead.
+func (*ListOperationsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListOperationsResponse) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+// CancelOperation request.
+type CancelOperationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the operation.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOperationRequest) Reset() {
+	*x = CancelOperationRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOperationRequest) ProtoMessage() {}
+
+func (x *CancelOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOperationRequest.ProtoReflect.Descriptor instead.
+func (*CancelOperationRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CancelOperationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// IntegrationEvent records a run of the signer which stored a new root for a
+// log, whether it integrated any leaves or not.
+type IntegrationEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Time at which the run started.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Time taken by the run, up to storing the new root.
+	Duration *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Number of leaves integrated by the run.
+	BatchSize int64 `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// Tree size of the new root.
+	TreeSize uint64 `protobuf:"varint,4,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	// Root hash of the new root.
+	RootHash []byte `protobuf:"bytes,5,opt,name=root_hash,json=rootHash,proto3" json:"root_hash,omitempty"`
+	// Identifies the signer instance which made the run.
+	Signer        string `protobuf:"bytes,6,opt,name=signer,proto3" json:"signer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntegrationEvent) Reset() {
+	*x = IntegrationEvent{}
+	mi := &file_trillian_admin_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntegrationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntegrationEvent) ProtoMessage() {}
+
+func (x *IntegrationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntegrationEvent.ProtoReflect.Descriptor instead.
+func (*IntegrationEvent) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *IntegrationEvent) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *IntegrationEvent) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *IntegrationEvent) GetBatchSize() int64 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *IntegrationEvent) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *IntegrationEvent) GetRootHash() []byte {
+	if x != nil {
+		return x.RootHash
+	}
+	return nil
+}
+
+func (x *IntegrationEvent) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+// ListIntegrationEvents request.
+type ListIntegrationEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the log whose integration events to list.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Maximum number of events to return. Defaults to 100 if unset.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// If set, only the events which started before this time are returned,
+	// for paging back through the history.
+	Before        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=before,proto3" json:"before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIntegrationEventsRequest) Reset() {
+	*x = ListIntegrationEventsRequest{}
+	mi := &file_trillian_admin_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIntegrationEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIntegrationEventsRequest) ProtoMessage() {}
+
+func (x *ListIntegrationEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIntegrationEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListIntegrationEventsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListIntegrationEventsRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *ListIntegrationEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListIntegrationEventsRequest) GetBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+// ListIntegrationEvents response.
+type ListIntegrationEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The integration events, most recent first.
+	Events        []*IntegrationEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIntegrationEventsResponse) Reset() {
+	*x = ListIntegrationEventsResponse{}
+	mi := &file_trillian_admin_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIntegrationEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIntegrationEventsResponse) ProtoMessage() {}
+
+func (x *ListIntegrationEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIntegrationEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListIntegrationEventsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListIntegrationEventsResponse) GetEvents() []*IntegrationEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_trillian_admin_api_proto protoreflect.FileDescriptor
 
 const file_trillian_admin_api_proto_rawDesc = "" +
 	"\n" +
-	"\x18trillian_admin_api.proto\x12\btrillian\x1a\x0etrillian.proto\x1a google/protobuf/field_mask.proto\"5\n" +
+	"\x18trillian_admin_api.proto\x12\btrillian\x1a\x0etrillian.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x17google/rpc/status.proto\"5\n" +
 	"\x10ListTreesRequest\x12!\n" +
 	"\fshow_deleted\x18\x01 \x01(\bR\vshowDeleted\"7\n" +
 	"\x11ListTreesResponse\x12\"\n" +
@@ -391,7 +1154,62 @@ const file_trillian_admin_api_proto_rawDesc = "" +
 	"\x11DeleteTreeRequest\x12\x17\n" +
 	"\atree_id\x18\x01 \x01(\x03R\x06treeId\".\n" +
 	"\x13UndeleteTreeRequest\x12\x17\n" +
-	"\atree_id\x18\x01 \x01(\x03R\x06treeId2\x86\x03\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\"3\n" +
+	"\x18TriggerSequencingRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\"\xba\x03\n" +
+	"\tOperation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\atree_id\x18\x02 \x01(\x03R\x06treeId\x12,\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x18.trillian.Operation.KindR\x04kind\x12;\n" +
+	"\vcreate_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vupdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\x12\x1d\n" +
+	"\n" +
+	"done_units\x18\x06 \x01(\x03R\tdoneUnits\x12\x1f\n" +
+	"\vtotal_units\x18\a \x01(\x03R\n" +
+	"totalUnits\x12\x12\n" +
+	"\x04done\x18\b \x01(\bR\x04done\x12(\n" +
+	"\x05error\x18\t \x01(\v2\x12.google.rpc.StatusR\x05error\x12\x16\n" +
+	"\x06result\x18\n" +
+	" \x01(\tR\x06result\"B\n" +
+	"\x04Kind\x12\x10\n" +
+	"\fUNKNOWN_KIND\x10\x00\x12\x0f\n" +
+	"\vHARD_DELETE\x10\x01\x12\n" +
+	"\n" +
+	"\x06EXPORT\x10\x02\x12\v\n" +
+	"\aMIGRATE\x10\x03\"0\n" +
+	"\x15HardDeleteTreeRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\",\n" +
+	"\x11ExportTreeRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\"-\n" +
+	"\x12MigrateTreeRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\")\n" +
+	"\x13GetOperationRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"0\n" +
+	"\x15ListOperationsRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\"M\n" +
+	"\x16ListOperationsResponse\x123\n" +
+	"\n" +
+	"operations\x18\x01 \x03(\v2\x13.trillian.OperationR\n" +
+	"operations\",\n" +
+	"\x16CancelOperationRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\xf5\x01\n" +
+	"\x10IntegrationEvent\x129\n" +
+	"\n" +
+	"start_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x03 \x01(\x03R\tbatchSize\x12\x1b\n" +
+	"\ttree_size\x18\x04 \x01(\x04R\btreeSize\x12\x1b\n" +
+	"\troot_hash\x18\x05 \x01(\fR\brootHash\x12\x16\n" +
+	"\x06signer\x18\x06 \x01(\tR\x06signer\"\x88\x01\n" +
+	"\x1cListIntegrationEventsRequest\x12\x17\n" +
+	"\atree_id\x18\x01 \x01(\x03R\x06treeId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x122\n" +
+	"\x06before\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x06before\"S\n" +
+	"\x1dListIntegrationEventsResponse\x122\n" +
+	"\x06events\x18\x01 \x03(\v2\x1a.trillian.IntegrationEventR\x06events2\xf9\a\n" +
 	"\rTrillianAdmin\x12F\n" +
 	"\tListTrees\x12\x1a.trillian.ListTreesRequest\x1a\x1b.trillian.ListTreesResponse\"\x00\x125\n" +
 	"\aGetTree\x12\x18.trillian.GetTreeRequest\x1a\x0e.trillian.Tree\"\x00\x12;\n" +
@@ -401,7 +1219,16 @@ const file_trillian_admin_api_proto_rawDesc = "" +
 	"UpdateTree\x12\x1b.trillian.UpdateTreeRequest\x1a\x0e.trillian.Tree\"\x00\x12;\n" +
 	"\n" +
 	"DeleteTree\x12\x1b.trillian.DeleteTreeRequest\x1a\x0e.trillian.Tree\"\x00\x12?\n" +
-	"\fUndeleteTree\x12\x1d.trillian.UndeleteTreeRequest\x1a\x0e.trillian.Tree\"\x00BP\n" +
+	"\fUndeleteTree\x12\x1d.trillian.UndeleteTreeRequest\x1a\x0e.trillian.Tree\"\x00\x12I\n" +
+	"\x11TriggerSequencing\x12\".trillian.TriggerSequencingRequest\x1a\x0e.trillian.Tree\"\x00\x12H\n" +
+	"\x0eHardDeleteTree\x12\x1f.trillian.HardDeleteTreeRequest\x1a\x13.trillian.Operation\"\x00\x12@\n" +
+	"\n" +
+	"ExportTree\x12\x1b.trillian.ExportTreeRequest\x1a\x13.trillian.Operation\"\x00\x12B\n" +
+	"\vMigrateTree\x12\x1c.trillian.MigrateTreeRequest\x1a\x13.trillian.Operation\"\x00\x12D\n" +
+	"\fGetOperation\x12\x1d.trillian.GetOperationRequest\x1a\x13.trillian.Operation\"\x00\x12U\n" +
+	"\x0eListOperations\x12\x1f.trillian.ListOperationsRequest\x1a .trillian.ListOperationsResponse\"\x00\x12M\n" +
+	"\x0fCancelOperation\x12 .trillian.CancelOperationRequest\x1a\x16.google.protobuf.Empty\"\x00\x12j\n" +
+	"\x15ListIntegrationEvents\x12&.trillian.ListIntegrationEventsRequest\x1a'.trillian.ListIntegrationEventsResponse\"\x00BP\n" +
 	"\x19com.google.trillian.protoB\x15TrillianAdminApiProtoP\x01Z\x1agithub.com/google/trillianb\x06proto3"
 
 var (
@@ -416,40 +1243,83 @@ func file_trillian_admin_api_proto_rawDescGZIP() []byte {
 	return file_trillian_admin_api_proto_rawDescData
 }
 
-var file_trillian_admin_api_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_trillian_admin_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_trillian_admin_api_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_trillian_admin_api_proto_goTypes = []any{
-	(*ListTreesRequest)(nil),      // 0: trillian.ListTreesRequest
-	(*ListTreesResponse)(nil),     // 1: trillian.ListTreesResponse
-	(*GetTreeRequest)(nil),        // 2: trillian.GetTreeRequest
-	(*CreateTreeRequest)(nil),     // 3: trillian.CreateTreeRequest
-	(*UpdateTreeRequest)(nil),     // 4: trillian.UpdateTreeRequest
-	(*DeleteTreeRequest)(nil),     // 5: trillian.DeleteTreeRequest
-	(*UndeleteTreeRequest)(nil),   // 6: trillian.UndeleteTreeRequest
-	(*Tree)(nil),                  // 7: trillian.Tree
-	(*fieldmaskpb.FieldMask)(nil), // 8: google.protobuf.FieldMask
+	(Operation_Kind)(0),                   // 0: trillian.Operation.Kind
+	(*ListTreesRequest)(nil),              // 1: trillian.ListTreesRequest
+	(*ListTreesResponse)(nil),             // 2: trillian.ListTreesResponse
+	(*GetTreeRequest)(nil),                // 3: trillian.GetTreeRequest
+	(*CreateTreeRequest)(nil),             // 4: trillian.CreateTreeRequest
+	(*UpdateTreeRequest)(nil),             // 5: trillian.UpdateTreeRequest
+	(*DeleteTreeRequest)(nil),             // 6: trillian.DeleteTreeRequest
+	(*UndeleteTreeRequest)(nil),           // 7: trillian.UndeleteTreeRequest
+	(*TriggerSequencingRequest)(nil),      // 8: trillian.TriggerSequencingRequest
+	(*Operation)(nil),                     // 9: trillian.Operation
+	(*HardDeleteTreeRequest)(nil),         // 10: trillian.HardDeleteTreeRequest
+	(*ExportTreeRequest)(nil),             // 11: trillian.ExportTreeRequest
+	(*MigrateTreeRequest)(nil),            // 12: trillian.MigrateTreeRequest
+	(*GetOperationRequest)(nil),           // 13: trillian.GetOperationRequest
+	(*ListOperationsRequest)(nil),         // 14: trillian.ListOperationsRequest
+	(*ListOperationsResponse)(nil),        // 15: trillian.ListOperationsResponse
+	(*CancelOperationRequest)(nil),        // 16: trillian.CancelOperationRequest
+	(*IntegrationEvent)(nil),              // 17: trillian.IntegrationEvent
+	(*ListIntegrationEventsRequest)(nil),  // 18: trillian.ListIntegrationEventsRequest
+	(*ListIntegrationEventsResponse)(nil), // 19: trillian.ListIntegrationEventsResponse
+	(*Tree)(nil),                          // 20: trillian.Tree
+	(*fieldmaskpb.FieldMask)(nil),         // 21: google.protobuf.FieldMask
+	(*timestamppb.Timestamp)(nil),         // 22: google.protobuf.Timestamp
+	(*status.Status)(nil),                 // 23: google.rpc.Status
+	(*durationpb.Duration)(nil),           // 24: google.protobuf.Duration
+	(*emptypb.Empty)(nil),                 // 25: google.protobuf.Empty
 }
 var file_trillian_admin_api_proto_depIdxs = []int32{
-	7,  // 0: trillian.ListTreesResponse.tree:type_name -> trillian.Tree
-	7,  // 1: trillian.CreateTreeRequest.tree:type_name -> trillian.Tree
-	7,  // 2: trillian.UpdateTreeRequest.tree:type_name -> trillian.Tree
-	8,  // 3: trillian.UpdateTreeRequest.update_mask:type_name -> google.protobuf.FieldMask
-	0,  // 4: trillian.TrillianAdmin.ListTrees:input_type -> trillian.ListTreesRequest
-	2,  // 5: trillian.TrillianAdmin.GetTree:input_type -> trillian.GetTreeRequest
-	3,  // 6: trillian.TrillianAdmin.CreateTree:input_type -> trillian.CreateTreeRequest
-	4,  // 7: trillian.TrillianAdmin.UpdateTree:input_type -> trillian.UpdateTreeRequest
-	5,  // 8: trillian.TrillianAdmin.DeleteTree:input_type -> trillian.DeleteTreeRequest
-	6,  // 9: trillian.TrillianAdmin.UndeleteTree:input_type -> trillian.UndeleteTreeRequest
-	1,  // 10: trillian.TrillianAdmin.ListTrees:output_type -> trillian.ListTreesResponse
-	7,  // 11: trillian.TrillianAdmin.GetTree:output_type -> trillian.Tree
-	7,  // 12: trillian.TrillianAdmin.CreateTree:output_type -> trillian.Tree
-	7,  // 13: trillian.TrillianAdmin.UpdateTree:output_type -> trillian.Tree
-	7,  // 14: trillian.TrillianAdmin.DeleteTree:output_type -> trillian.Tree
-	7,  // 15: trillian.TrillianAdmin.UndeleteTree:output_type -> trillian.Tree
-	10, // [10:16] is the sub-list for method output_type
-	4,  // [4:10] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	20, // 0: trillian.ListTreesResponse.tree:type_name -> trillian.Tree
+	20, // 1: trillian.CreateTreeRequest.tree:type_name -> trillian.Tree
+	20, // 2: trillian.UpdateTreeRequest.tree:type_name -> trillian.Tree
+	21, // 3: trillian.UpdateTreeRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 4: trillian.Operation.kind:type_name -> trillian.Operation.Kind
+	22, // 5: trillian.Operation.create_time:type_name -> google.protobuf.Timestamp
+	22, // 6: trillian.Operation.update_time:type_name -> google.protobuf.Timestamp
+	23, // 7: trillian.Operation.error:type_name -> google.rpc.Status
+	9,  // 8: trillian.ListOperationsResponse.operations:type_name -> trillian.Operation
+	22, // 9: trillian.IntegrationEvent.start_time:type_name -> google.protobuf.Timestamp
+	24, // 10: trillian.IntegrationEvent.duration:type_name -> google.protobuf.Duration
+	22, // 11: trillian.ListIntegrationEventsRequest.before:type_name -> google.protobuf.Timestamp
+	17, // 12: trillian.ListIntegrationEventsResponse.events:type_name -> trillian.IntegrationEvent
+	1,  // 13: trillian.TrillianAdmin.ListTrees:input_type -> trillian.ListTreesRequest
+	3,  // 14: trillian.TrillianAdmin.GetTree:input_type -> trillian.GetTreeRequest
+	4,  // 15: trillian.TrillianAdmin.CreateTree:input_type -> trillian.CreateTreeRequest
+	5,  // 16: trillian.TrillianAdmin.UpdateTree:input_type -> trillian.UpdateTreeRequest
+	6,  // 17: trillian.TrillianAdmin.DeleteTree:input_type -> trillian.DeleteTreeRequest
+	7,  // 18: trillian.TrillianAdmin.UndeleteTree:input_type -> trillian.UndeleteTreeRequest
+	8,  // 19: trillian.TrillianAdmin.TriggerSequencing:input_type -> trillian.TriggerSequencingRequest
+	10, // 20: trillian.TrillianAdmin.HardDeleteTree:input_type -> trillian.HardDeleteTreeRequest
+	11, // 21: trillian.TrillianAdmin.ExportTree:input_type -> trillian.ExportTreeRequest
+	12, // 22: trillian.TrillianAdmin.MigrateTree:input_type -> trillian.MigrateTreeRequest
+	13, // 23: trillian.TrillianAdmin.GetOperation:input_type -> trillian.GetOperationRequest
+	14, // 24: trillian.TrillianAdmin.ListOperations:input_type -> trillian.ListOperationsRequest
+	16, // 25: trillian.TrillianAdmin.CancelOperation:input_type -> trillian.CancelOperationRequest
+	18, // 26: trillian.TrillianAdmin.ListIntegrationEvents:input_type -> trillian.ListIntegrationEventsRequest
+	2,  // 27: trillian.TrillianAdmin.ListTrees:output_type -> trillian.ListTreesResponse
+	20, // 28: trillian.TrillianAdmin.GetTree:output_type -> trillian.Tree
+	20, // 29: trillian.TrillianAdmin.CreateTree:output_type -> trillian.Tree
+	20, // 30: trillian.TrillianAdmin.UpdateTree:output_type -> trillian.Tree
+	20, // 31: trillian.TrillianAdmin.DeleteTree:output_type -> trillian.Tree
+	20, // 32: trillian.TrillianAdmin.UndeleteTree:output_type -> trillian.Tree
+	20, // 33: trillian.TrillianAdmin.TriggerSequencing:output_type -> trillian.Tree
+	9,  // 34: trillian.TrillianAdmin.HardDeleteTree:output_type -> trillian.Operation
+	9,  // 35: trillian.TrillianAdmin.ExportTree:output_type -> trillian.Operation
+	9,  // 36: trillian.TrillianAdmin.MigrateTree:output_type -> trillian.Operation
+	9,  // 37: trillian.TrillianAdmin.GetOperation:output_type -> trillian.Operation
+	15, // 38: trillian.TrillianAdmin.ListOperations:output_type -> trillian.ListOperationsResponse
+	25, // 39: trillian.TrillianAdmin.CancelOperation:output_type -> google.protobuf.Empty
+	19, // 40: trillian.TrillianAdmin.ListIntegrationEvents:output_type -> trillian.ListIntegrationEventsResponse
+	27, // [27:41] is the sub-list for method output_type
+	13, // [13:27] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_trillian_admin_api_proto_init() }
@@ -463,13 +1333,14 @@ func file_trillian_admin_api_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trillian_admin_api_proto_rawDesc), len(file_trillian_admin_api_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_trillian_admin_api_proto_goTypes,
 		DependencyIndexes: file_trillian_admin_api_proto_depIdxs,
+		EnumInfos:         file_trillian_admin_api_proto_enumTypes,
 		MessageInfos:      file_trillian_admin_api_proto_msgTypes,
 	}.Build()
 	File_trillian_admin_api_proto = out.File
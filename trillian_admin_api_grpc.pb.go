@@ -25,6 +25,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -33,12 +34,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TrillianAdmin_ListTrees_FullMethodName    = "/trillian.TrillianAdmin/ListTrees"
-	TrillianAdmin_GetTree_FullMethodName      = "/trillian.TrillianAdmin/GetTree"
-	TrillianAdmin_CreateTree_FullMethodName   = "/trillian.TrillianAdmin/CreateTree"
-	TrillianAdmin_UpdateTree_FullMethodName   = "/trillian.TrillianAdmin/UpdateTree"
-	TrillianAdmin_DeleteTree_FullMethodName   = "/trillian.TrillianAdmin/DeleteTree"
-	TrillianAdmin_UndeleteTree_FullMethodName = "/trillian.TrillianAdmin/UndeleteTree"
+	TrillianAdmin_ListTrees_FullMethodName             = "/trillian.TrillianAdmin/ListTrees"
+	TrillianAdmin_GetTree_FullMethodName               = "/trillian.TrillianAdmin/GetTree"
+	TrillianAdmin_CreateTree_FullMethodName            = "/trillian.TrillianAdmin/CreateTree"
+	TrillianAdmin_UpdateTree_FullMethodName            = "/trillian.TrillianAdmin/UpdateTree"
+	TrillianAdmin_DeleteTree_FullMethodName            = "/trillian.TrillianAdmin/DeleteTree"
+	TrillianAdmin_UndeleteTree_FullMethodName          = "/trillian.TrillianAdmin/UndeleteTree"
+	TrillianAdmin_TriggerSequencing_FullMethodName     = "/trillian.TrillianAdmin/TriggerSequencing"
+	TrillianAdmin_HardDeleteTree_FullMethodName        = "/trillian.TrillianAdmin/HardDeleteTree"
+	TrillianAdmin_ExportTree_FullMethodName            = "/trillian.TrillianAdmin/ExportTree"
+	TrillianAdmin_MigrateTree_FullMethodName           = "/trillian.TrillianAdmin/MigrateTree"
+	TrillianAdmin_GetOperation_FullMethodName          = "/trillian.TrillianAdmin/GetOperation"
+	TrillianAdmin_ListOperations_FullMethodName        = "/trillian.TrillianAdmin/ListOperations"
+	TrillianAdmin_CancelOperation_FullMethodName       = "/trillian.TrillianAdmin/CancelOperation"
+	TrillianAdmin_ListIntegrationEvents_FullMethodName = "/trillian.TrillianAdmin/ListIntegrationEvents"
 )
 
 // TrillianAdminClient is the client API for TrillianAdmin service.
@@ -68,6 +77,36 @@ type TrillianAdminClient interface {
 	// A soft-deleted tree may be undeleted for a certain period, after which
 	// it'll be permanently deleted.
 	UndeleteTree(ctx context.Context, in *UndeleteTreeRequest, opts ...grpc.CallOption) (*Tree, error)
+	// Requests an immediate sequencing pass over a log, which integrates all of
+	// its queued leaves regardless of the signer's guard window, e.g. to flush
+	// the queue before maintenance. The request is stored with the tree, see
+	// sequencing_requested_time, and handled by the signer which holds
+	// mastership of the log on its next pass.
+	// The log must be ACTIVE or DRAINING.
+	TriggerSequencing(ctx context.Context, in *TriggerSequencingRequest, opts ...grpc.CallOption) (*Tree, error)
+	// Starts permanently deleting a soft-deleted tree and all of its data,
+	// without waiting for the deleted tree garbage collector.
+	HardDeleteTree(ctx context.Context, in *HardDeleteTreeRequest, opts ...grpc.CallOption) (*Operation, error)
+	// Starts writing the integrated leaves of a log, up to its latest root, to
+	// a file in the export directory of the admin server.
+	ExportTree(ctx context.Context, in *ExportTreeRequest, opts ...grpc.CallOption) (*Operation, error)
+	// Starts copying a log, up to its latest root, to the migration storage of
+	// the admin server. Leaves integrated after the copy started are not
+	// copied, so the log should be FROZEN first for a complete migration.
+	MigrateTree(ctx context.Context, in *MigrateTreeRequest, opts ...grpc.CallOption) (*Operation, error)
+	// Gets the latest state of a long-running operation.
+	// Operations are kept in the memory of the admin server which started
+	// them, so they are only visible there, and are lost if it restarts.
+	GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*Operation, error)
+	// Lists the running and recently finished long-running operations.
+	ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error)
+	// Requests the cancellation of a long-running operation. The operation
+	// stops at its next checkpoint, keeping the work done so far.
+	CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Lists the recent integration runs of a log, as recorded by the signer
+	// if the storage supports it. The history is kept for a limited time, see
+	// the --integration_event_retention flag of the signer.
+	ListIntegrationEvents(ctx context.Context, in *ListIntegrationEventsRequest, opts ...grpc.CallOption) (*ListIntegrationEventsResponse, error)
 }
 
 type trillianAdminClient struct {
@@ -138,6 +177,86 @@ func (c *trillianAdminClient) UndeleteTree(ctx context.Context, in *UndeleteTree
 	return out, nil
 }
 
+func (c *trillianAdminClient) TriggerSequencing(ctx context.Context, in *TriggerSequencingRequest, opts ...grpc.CallOption) (*Tree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tree)
+	err := c.cc.Invoke(ctx, TrillianAdmin_TriggerSequencing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) HardDeleteTree(ctx context.Context, in *HardDeleteTreeRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, TrillianAdmin_HardDeleteTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) ExportTree(ctx context.Context, in *ExportTreeRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, TrillianAdmin_ExportTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) MigrateTree(ctx context.Context, in *MigrateTreeRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, TrillianAdmin_MigrateTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, TrillianAdmin_GetOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOperationsResponse)
+	err := c.cc.Invoke(ctx, TrillianAdmin_ListOperations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TrillianAdmin_CancelOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) ListIntegrationEvents(ctx context.Context, in *ListIntegrationEventsRequest, opts ...grpc.CallOption) (*ListIntegrationEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIntegrationEventsResponse)
+	err := c.cc.Invoke(ctx, TrillianAdmin_ListIntegrationEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianAdminServer is the server API for TrillianAdmin service.
 // All implementations should embed UnimplementedTrillianAdminServer
 // for forward compatibility.
@@ -165,6 +284,36 @@ type TrillianAdminServer interface {
 	// A soft-deleted tree may be undeleted for a certain period, after which
 	// it'll be permanently deleted.
 	UndeleteTree(context.Context, *UndeleteTreeRequest) (*Tree, error)
+	// Requests an immediate sequencing pass over a log, which integrates all of
+	// its queued leaves regardless of the signer's guard window, e.g. to flush
+	// the queue before maintenance. The request is stored with the tree, see
+	// sequencing_requested_time, and handled by the signer which holds
+	// mastership of the log on its next pass.
+	// The log must be ACTIVE or DRAINING.
+	TriggerSequencing(context.Context, *TriggerSequencingRequest) (*Tree, error)
+	// Starts permanently deleting a soft-deleted tree and all of its data,
+	// without waiting for the deleted tree garbage collector.
+	HardDeleteTree(context.Context, *HardDeleteTreeRequest) (*Operation, error)
+	// Starts writing the integrated leaves of a log, up to its latest root, to
+	// a file in the export directory of the admin server.
+	ExportTree(context.Context, *ExportTreeRequest) (*Operation, error)
+	// Starts copying a log, up to its latest root, to the migration storage of
+	// the admin server. Leaves integrated after the copy started are not
+	// copied, so the log should be FROZEN first for a complete migration.
+	MigrateTree(context.Context, *MigrateTreeRequest) (*Operation, error)
+	// Gets the latest state of a long-running operation.
+	// Operations are kept in the memory of the admin server which started
+	// them, so they are only visible there, and are lost if it restarts.
+	GetOperation(context.Context, *GetOperationRequest) (*Operation, error)
+	// Lists the running and recently finished long-running operations.
+	ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error)
+	// Requests the cancellation of a long-running operation. The operation
+	// stops at its next checkpoint, keeping the work done so far.
+	CancelOperation(context.Context, *CancelOperationRequest) (*emptypb.Empty, error)
+	// Lists the recent integration runs of a log, as recorded by the signer
+	// if the storage supports it. The history is kept for a limited time, see
+	// the --integration_event_retention flag of the signer.
+	ListIntegrationEvents(context.Context, *ListIntegrationEventsRequest) (*ListIntegrationEventsResponse, error)
 }
 
 // UnimplementedTrillianAdminServer should be embedded to have
@@ -192,6 +341,30 @@ func (UnimplementedTrillianAdminServer) DeleteTree(context.Context, *DeleteTreeR
 func (UnimplementedTrillianAdminServer) UndeleteTree(context.Context, *UndeleteTreeRequest) (*Tree, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UndeleteTree not implemented")
 }
+func (UnimplementedTrillianAdminServer) TriggerSequencing(context.Context, *TriggerSequencingRequest) (*Tree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerSequencing not implemented")
+}
+func (UnimplementedTrillianAdminServer) HardDeleteTree(context.Context, *HardDeleteTreeRequest) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HardDeleteTree not implemented")
+}
+func (UnimplementedTrillianAdminServer) ExportTree(context.Context, *ExportTreeRequest) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportTree not implemented")
+}
+func (UnimplementedTrillianAdminServer) MigrateTree(context.Context, *MigrateTreeRequest) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateTree not implemented")
+}
+func (UnimplementedTrillianAdminServer) GetOperation(context.Context, *GetOperationRequest) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
+}
+func (UnimplementedTrillianAdminServer) ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOperations not implemented")
+}
+func (UnimplementedTrillianAdminServer) CancelOperation(context.Context, *CancelOperationRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOperation not implemented")
+}
+func (UnimplementedTrillianAdminServer) ListIntegrationEvents(context.Context, *ListIntegrationEventsRequest) (*ListIntegrationEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIntegrationEvents not implemented")
+}
 func (UnimplementedTrillianAdminServer) testEmbeddedByValue() {}
 
 // UnsafeTrillianAdminServer may be embedded to opt out of forward compatibility for this service.
@@ -320,6 +493,150 @@ func _TrillianAdmin_UndeleteTree_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianAdmin_TriggerSequencing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerSequencingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).TriggerSequencing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_TriggerSequencing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).TriggerSequencing(ctx, req.(*TriggerSequencingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_HardDeleteTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HardDeleteTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).HardDeleteTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_HardDeleteTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).HardDeleteTree(ctx, req.(*HardDeleteTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_ExportTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).ExportTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_ExportTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).ExportTree(ctx, req.(*ExportTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_MigrateTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).MigrateTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_MigrateTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).MigrateTree(ctx, req.(*MigrateTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).GetOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_GetOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).GetOperation(ctx, req.(*GetOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_ListOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).ListOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_ListOperations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).ListOperations(ctx, req.(*ListOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_CancelOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).CancelOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_CancelOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).CancelOperation(ctx, req.(*CancelOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_ListIntegrationEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIntegrationEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).ListIntegrationEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianAdmin_ListIntegrationEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).ListIntegrationEvents(ctx, req.(*ListIntegrationEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TrillianAdmin_ServiceDesc is the grpc.ServiceDesc for TrillianAdmin service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -351,6 +668,38 @@ var TrillianAdmin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UndeleteTree",
 			Handler:    _TrillianAdmin_UndeleteTree_Handler,
 		},
+		{
+			MethodName: "TriggerSequencing",
+			Handler:    _TrillianAdmin_TriggerSequencing_Handler,
+		},
+		{
+			MethodName: "HardDeleteTree",
+			Handler:    _TrillianAdmin_HardDeleteTree_Handler,
+		},
+		{
+			MethodName: "ExportTree",
+			Handler:    _TrillianAdmin_ExportTree_Handler,
+		},
+		{
+			MethodName: "MigrateTree",
+			Handler:    _TrillianAdmin_MigrateTree_Handler,
+		},
+		{
+			MethodName: "GetOperation",
+			Handler:    _TrillianAdmin_GetOperation_Handler,
+		},
+		{
+			MethodName: "ListOperations",
+			Handler:    _TrillianAdmin_ListOperations_Handler,
+		},
+		{
+			MethodName: "CancelOperation",
+			Handler:    _TrillianAdmin_CancelOperation_Handler,
+		},
+		{
+			MethodName: "ListIntegrationEvents",
+			Handler:    _TrillianAdmin_ListIntegrationEvents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_admin_api.proto",
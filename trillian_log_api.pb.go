@@ -163,7 +163,13 @@ type QueueLeafResponse struct {
 	// Log.  If the submitted leaf was already present in the Log (as indicated by
 	// its leaf identity hash), then the returned leaf will be the pre-existing
 	// leaf entry rather than the submitted leaf.
-	QueuedLeaf    *QueuedLogLeaf `protobuf:"bytes,2,opt,name=queued_leaf,json=queuedLeaf,proto3" json:"queued_leaf,omitempty"`
+	QueuedLeaf *QueuedLogLeaf `protobuf:"bytes,2,opt,name=queued_leaf,json=queuedLeaf,proto3" json:"queued_leaf,omitempty"`
+	// An opaque token identifying the view of the log at the time of the
+	// write, if the server issues session tokens. Read requests for the log
+	// which carry it fail with UNAVAILABLE, and can be retried on another
+	// replica, if the server's view of the log is older, e.g. because it reads
+	// from a lagging database replica.
+	SessionToken  []byte `protobuf:"bytes,3,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -205,12 +211,26 @@ func (x *QueueLeafResponse) GetQueuedLeaf() *QueuedLogLeaf {
 	return nil
 }
 
+func (x *QueueLeafResponse) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
 type GetInclusionProofRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	LeafIndex     int64                  `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
-	TreeSize      int64                  `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
-	ChargeTo      *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	LogId     int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	LeafIndex int64                  `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	TreeSize  int64                  `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	ChargeTo  *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// If set, the response includes the log root of the proof's tree size in
+	// proof_root. Not all storage implementations support this for roots other
+	// than the latest.
+	IncludeProofRoot bool `protobuf:"varint,5,opt,name=include_proof_root,json=includeProofRoot,proto3" json:"include_proof_root,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,6,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -273,6 +293,20 @@ func (x *GetInclusionProofRequest) GetChargeTo() *ChargeTo {
 	return nil
 }
 
+func (x *GetInclusionProofRequest) GetIncludeProofRoot() bool {
+	if x != nil {
+		return x.IncludeProofRoot
+	}
+	return false
+}
+
+func (x *GetInclusionProofRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
 type GetInclusionProofResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The proof field may be empty if the requested tree_size was larger
@@ -283,6 +317,10 @@ type GetInclusionProofResponse struct {
 	// the proof field will be empty.
 	Proof         *Proof         `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
 	SignedLogRoot *SignedLogRoot `protobuf:"bytes,3,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// The log root of the same tree size as proof, which proof can be verified
+	// against, if include_proof_root was set in the request. It is unset if the
+	// log never published a root of that size.
+	ProofRoot     *SignedLogRoot `protobuf:"bytes,4,opt,name=proof_root,json=proofRoot,proto3" json:"proof_root,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -331,6 +369,13 @@ func (x *GetInclusionProofResponse) GetSignedLogRoot() *SignedLogRoot {
 	return nil
 }
 
+func (x *GetInclusionProofResponse) GetProofRoot() *SignedLogRoot {
+	if x != nil {
+		return x.ProofRoot
+	}
+	return nil
+}
+
 type GetInclusionProofByHashRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
@@ -340,8 +385,11 @@ type GetInclusionProofByHashRequest struct {
 	TreeSize        int64     `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
 	OrderBySequence bool      `protobuf:"varint,4,opt,name=order_by_sequence,json=orderBySequence,proto3" json:"order_by_sequence,omitempty"`
 	ChargeTo        *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,6,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetInclusionProofByHashRequest) Reset() {
@@ -409,6 +457,13 @@ func (x *GetInclusionProofByHashRequest) GetChargeTo() *ChargeTo {
 	return nil
 }
 
+func (x *GetInclusionProofByHashRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
 type GetInclusionProofByHashResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Logs can potentially contain leaves with duplicate hashes so it's possible
@@ -465,19 +520,164 @@ func (x *GetInclusionProofByHashResponse) GetSignedLogRoot() *SignedLogRoot {
 	return nil
 }
 
+type GetInclusionProofByIdentityHashRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The identity hash of the leaf entry, as given when it was queued.
+	LeafIdentityHash []byte    `protobuf:"bytes,2,opt,name=leaf_identity_hash,json=leafIdentityHash,proto3" json:"leaf_identity_hash,omitempty"`
+	TreeSize         int64     `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	OrderBySequence  bool      `protobuf:"varint,4,opt,name=order_by_sequence,json=orderBySequence,proto3" json:"order_by_sequence,omitempty"`
+	ChargeTo         *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,6,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) Reset() {
+	*x = GetInclusionProofByIdentityHashRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInclusionProofByIdentityHashRequest) ProtoMessage() {}
+
+func (x *GetInclusionProofByIdentityHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInclusionProofByIdentityHashRequest.ProtoReflect.Descriptor instead.
+func (*GetInclusionProofByIdentityHashRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetLeafIdentityHash() []byte {
+	if x != nil {
+		return x.LeafIdentityHash
+	}
+	return nil
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetTreeSize() int64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetOrderBySequence() bool {
+	if x != nil {
+		return x.OrderBySequence
+	}
+	return false
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+func (x *GetInclusionProofByIdentityHashRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetInclusionProofByIdentityHashResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One proof for each leaf with the requested identity hash which is within
+	// the requested tree size. Usually there is at most one, but
+	// PREORDERED_LOG trees may contain duplicates.
+	Proof         []*Proof       `protobuf:"bytes,1,rep,name=proof,proto3" json:"proof,omitempty"`
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInclusionProofByIdentityHashResponse) Reset() {
+	*x = GetInclusionProofByIdentityHashResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInclusionProofByIdentityHashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInclusionProofByIdentityHashResponse) ProtoMessage() {}
+
+func (x *GetInclusionProofByIdentityHashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInclusionProofByIdentityHashResponse.ProtoReflect.Descriptor instead.
+func (*GetInclusionProofByIdentityHashResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetInclusionProofByIdentityHashResponse) GetProof() []*Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *GetInclusionProofByIdentityHashResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
 type GetConsistencyProofRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	LogId          int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
 	FirstTreeSize  int64                  `protobuf:"varint,2,opt,name=first_tree_size,json=firstTreeSize,proto3" json:"first_tree_size,omitempty"`
 	SecondTreeSize int64                  `protobuf:"varint,3,opt,name=second_tree_size,json=secondTreeSize,proto3" json:"second_tree_size,omitempty"`
 	ChargeTo       *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,5,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetConsistencyProofRequest) Reset() {
 	*x = GetConsistencyProofRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[7]
+	mi := &file_trillian_log_api_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -489,7 +689,7 @@ func (x *GetConsistencyProofRequest) String() string {
 func (*GetConsistencyProofRequest) ProtoMessage() {}
 
 func (x *GetConsistencyProofRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[7]
+	mi := &file_trillian_log_api_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -502,7 +702,7 @@ func (x *GetConsistencyProofRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetConsistencyProofRequest.ProtoReflect.Descriptor instead.
 func (*GetConsistencyProofRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{7}
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetConsistencyProofRequest) GetLogId() int64 {
@@ -533,6 +733,13 @@ func (x *GetConsistencyProofRequest) GetChargeTo() *ChargeTo {
 	return nil
 }
 
+func (x *GetConsistencyProofRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
 type GetConsistencyProofResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The proof field may be empty if the requested tree_size was larger
@@ -549,7 +756,7 @@ type GetConsistencyProofResponse struct {
 
 func (x *GetConsistencyProofResponse) Reset() {
 	*x = GetConsistencyProofResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[8]
+	mi := &file_trillian_log_api_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -561,7 +768,7 @@ func (x *GetConsistencyProofResponse) String() string {
 func (*GetConsistencyProofResponse) ProtoMessage() {}
 
 func (x *GetConsistencyProofResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[8]
+	mi := &file_trillian_log_api_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,7 +781,7 @@ func (x *GetConsistencyProofResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetConsistencyProofResponse.ProtoReflect.Descriptor instead.
 func (*GetConsistencyProofResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{8}
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetConsistencyProofResponse) GetProof() *Proof {
@@ -591,32 +798,37 @@ func (x *GetConsistencyProofResponse) GetSignedLogRoot() *SignedLogRoot {
 	return nil
 }
 
-type GetLatestSignedLogRootRequest struct {
-	state    protoimpl.MessageState `protogen:"open.v1"`
-	LogId    int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	ChargeTo *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
-	// If first_tree_size is non-zero, the response will include a consistency
-	// proof between first_tree_size and the new tree size (if not smaller).
-	FirstTreeSize int64 `protobuf:"varint,3,opt,name=first_tree_size,json=firstTreeSize,proto3" json:"first_tree_size,omitempty"`
+type GetConsistencyProofByRootHashRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The root hash of the earlier root.
+	FirstRootHash []byte `protobuf:"bytes,2,opt,name=first_root_hash,json=firstRootHash,proto3" json:"first_root_hash,omitempty"`
+	// The root hash of the later root. Its tree size must not be smaller than
+	// that of first_root_hash.
+	SecondRootHash []byte    `protobuf:"bytes,3,opt,name=second_root_hash,json=secondRootHash,proto3" json:"second_root_hash,omitempty"`
+	ChargeTo       *ChargeTo `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,5,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLatestSignedLogRootRequest) Reset() {
-	*x = GetLatestSignedLogRootRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[9]
+func (x *GetConsistencyProofByRootHashRequest) Reset() {
+	*x = GetConsistencyProofByRootHashRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLatestSignedLogRootRequest) String() string {
+func (x *GetConsistencyProofByRootHashRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLatestSignedLogRootRequest) ProtoMessage() {}
+func (*GetConsistencyProofByRootHashRequest) ProtoMessage() {}
 
-func (x *GetLatestSignedLogRootRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[9]
+func (x *GetConsistencyProofByRootHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -627,58 +839,74 @@ func (x *GetLatestSignedLogRootRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLatestSignedLogRootRequest.ProtoReflect.Descriptor instead.
-func (*GetLatestSignedLogRootRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GetConsistencyProofByRootHashRequest.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofByRootHashRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetLatestSignedLogRootRequest) GetLogId() int64 {
+func (x *GetConsistencyProofByRootHashRequest) GetLogId() int64 {
 	if x != nil {
 		return x.LogId
 	}
 	return 0
 }
 
-func (x *GetLatestSignedLogRootRequest) GetChargeTo() *ChargeTo {
+func (x *GetConsistencyProofByRootHashRequest) GetFirstRootHash() []byte {
+	if x != nil {
+		return x.FirstRootHash
+	}
+	return nil
+}
+
+func (x *GetConsistencyProofByRootHashRequest) GetSecondRootHash() []byte {
+	if x != nil {
+		return x.SecondRootHash
+	}
+	return nil
+}
+
+func (x *GetConsistencyProofByRootHashRequest) GetChargeTo() *ChargeTo {
 	if x != nil {
 		return x.ChargeTo
 	}
 	return nil
 }
 
-func (x *GetLatestSignedLogRootRequest) GetFirstTreeSize() int64 {
+func (x *GetConsistencyProofByRootHashRequest) GetSessionToken() []byte {
 	if x != nil {
-		return x.FirstTreeSize
+		return x.SessionToken
 	}
-	return 0
+	return nil
 }
 
-type GetLatestSignedLogRootResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SignedLogRoot *SignedLogRoot         `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
-	// proof is filled in with a consistency proof if first_tree_size in
-	// GetLatestSignedLogRootRequest is non-zero (and within the tree size
-	// available at the server).
-	Proof         *Proof `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+type GetConsistencyProofByRootHashResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The consistency proof between the tree sizes of first_root and
+	// second_root.
+	Proof *Proof `protobuf:"bytes,1,opt,name=proof,proto3" json:"proof,omitempty"`
+	// The most recent stored roots with first_root_hash and second_root_hash.
+	FirstRoot     *SignedLogRoot `protobuf:"bytes,2,opt,name=first_root,json=firstRoot,proto3" json:"first_root,omitempty"`
+	SecondRoot    *SignedLogRoot `protobuf:"bytes,3,opt,name=second_root,json=secondRoot,proto3" json:"second_root,omitempty"`
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,4,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLatestSignedLogRootResponse) Reset() {
-	*x = GetLatestSignedLogRootResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[10]
+func (x *GetConsistencyProofByRootHashResponse) Reset() {
+	*x = GetConsistencyProofByRootHashResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLatestSignedLogRootResponse) String() string {
+func (x *GetConsistencyProofByRootHashResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLatestSignedLogRootResponse) ProtoMessage() {}
+func (*GetConsistencyProofByRootHashResponse) ProtoMessage() {}
 
-func (x *GetLatestSignedLogRootResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[10]
+func (x *GetConsistencyProofByRootHashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -689,50 +917,1331 @@ func (x *GetLatestSignedLogRootResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLatestSignedLogRootResponse.ProtoReflect.Descriptor instead.
-func (*GetLatestSignedLogRootResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use GetConsistencyProofByRootHashResponse.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofByRootHashResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetLatestSignedLogRootResponse) GetSignedLogRoot() *SignedLogRoot {
+func (x *GetConsistencyProofByRootHashResponse) GetProof() *Proof {
 	if x != nil {
-		return x.SignedLogRoot
+		return x.Proof
 	}
 	return nil
 }
 
-func (x *GetLatestSignedLogRootResponse) GetProof() *Proof {
+func (x *GetConsistencyProofByRootHashResponse) GetFirstRoot() *SignedLogRoot {
 	if x != nil {
-		return x.Proof
+		return x.FirstRoot
 	}
 	return nil
 }
 
-type GetEntryAndProofRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	LeafIndex     int64                  `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
-	TreeSize      int64                  `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
-	ChargeTo      *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+func (x *GetConsistencyProofByRootHashResponse) GetSecondRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SecondRoot
+	}
+	return nil
+}
+
+func (x *GetConsistencyProofByRootHashResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+type GetRangeInclusionProofRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The first leaf index of the range.
+	StartIndex int64 `protobuf:"varint,2,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
+	// The leaf index after the end of the range. Must be greater than
+	// start_index, and not greater than tree_size.
+	EndIndex int64     `protobuf:"varint,3,opt,name=end_index,json=endIndex,proto3" json:"end_index,omitempty"`
+	TreeSize int64     `protobuf:"varint,4,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	ChargeTo *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,6,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEntryAndProofRequest) Reset() {
-	*x = GetEntryAndProofRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[11]
+func (x *GetRangeInclusionProofRequest) Reset() {
+	*x = GetRangeInclusionProofRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEntryAndProofRequest) String() string {
+func (x *GetRangeInclusionProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRangeInclusionProofRequest) ProtoMessage() {}
+
+func (x *GetRangeInclusionProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRangeInclusionProofRequest.ProtoReflect.Descriptor instead.
+func (*GetRangeInclusionProofRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetRangeInclusionProofRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetRangeInclusionProofRequest) GetStartIndex() int64 {
+	if x != nil {
+		return x.StartIndex
+	}
+	return 0
+}
+
+func (x *GetRangeInclusionProofRequest) GetEndIndex() int64 {
+	if x != nil {
+		return x.EndIndex
+	}
+	return 0
+}
+
+func (x *GetRangeInclusionProofRequest) GetTreeSize() int64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetRangeInclusionProofRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+func (x *GetRangeInclusionProofRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetRangeInclusionProofResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The hashes of the compact range [0, start_index), from left to right.
+	LeftHashes [][]byte `protobuf:"bytes,1,rep,name=left_hashes,json=leftHashes,proto3" json:"left_hashes,omitempty"`
+	// The hashes of the compact range [end_index, tree_size), from left to
+	// right.
+	RightHashes   [][]byte       `protobuf:"bytes,2,rep,name=right_hashes,json=rightHashes,proto3" json:"right_hashes,omitempty"`
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,3,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRangeInclusionProofResponse) Reset() {
+	*x = GetRangeInclusionProofResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRangeInclusionProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRangeInclusionProofResponse) ProtoMessage() {}
+
+func (x *GetRangeInclusionProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRangeInclusionProofResponse.ProtoReflect.Descriptor instead.
+func (*GetRangeInclusionProofResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetRangeInclusionProofResponse) GetLeftHashes() [][]byte {
+	if x != nil {
+		return x.LeftHashes
+	}
+	return nil
+}
+
+func (x *GetRangeInclusionProofResponse) GetRightHashes() [][]byte {
+	if x != nil {
+		return x.RightHashes
+	}
+	return nil
+}
+
+func (x *GetRangeInclusionProofResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+type GetLatestSignedLogRootRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	LogId    int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	ChargeTo *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// If first_tree_size is non-zero, the response will include a consistency
+	// proof between first_tree_size and the new tree size (if not smaller).
+	FirstTreeSize int64 `protobuf:"varint,3,opt,name=first_tree_size,json=firstTreeSize,proto3" json:"first_tree_size,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,4,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestSignedLogRootRequest) Reset() {
+	*x = GetLatestSignedLogRootRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestSignedLogRootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestSignedLogRootRequest) ProtoMessage() {}
+
+func (x *GetLatestSignedLogRootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestSignedLogRootRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestSignedLogRootRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetLatestSignedLogRootRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetLatestSignedLogRootRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+func (x *GetLatestSignedLogRootRequest) GetFirstTreeSize() int64 {
+	if x != nil {
+		return x.FirstTreeSize
+	}
+	return 0
+}
+
+func (x *GetLatestSignedLogRootRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetLatestSignedLogRootResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SignedLogRoot *SignedLogRoot         `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// proof is filled in with a consistency proof if first_tree_size in
+	// GetLatestSignedLogRootRequest is non-zero (and within the tree size
+	// available at the server).
+	Proof         *Proof `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestSignedLogRootResponse) Reset() {
+	*x = GetLatestSignedLogRootResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestSignedLogRootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestSignedLogRootResponse) ProtoMessage() {}
+
+func (x *GetLatestSignedLogRootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestSignedLogRootResponse.ProtoReflect.Descriptor instead.
+func (*GetLatestSignedLogRootResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetLatestSignedLogRootResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+func (x *GetLatestSignedLogRootResponse) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type ListSignedLogRootsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// If set, only the roots with timestamps at or after start_time, and
+	// before end_time, are returned.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Only the roots with tree sizes of at least start_tree_size, and less than
+	// end_tree_size if it is non-zero, are returned.
+	StartTreeSize int64 `protobuf:"varint,4,opt,name=start_tree_size,json=startTreeSize,proto3" json:"start_tree_size,omitempty"`
+	EndTreeSize   int64 `protobuf:"varint,5,opt,name=end_tree_size,json=endTreeSize,proto3" json:"end_tree_size,omitempty"`
+	// Maximum number of roots to return. Defaults to 100 if unset.
+	PageSize int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token of the previous response, to continue the listing
+	// with the same request.
+	PageToken     string    `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	ChargeTo      *ChargeTo `protobuf:"bytes,8,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSignedLogRootsRequest) Reset() {
+	*x = ListSignedLogRootsRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSignedLogRootsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSignedLogRootsRequest) ProtoMessage() {}
+
+func (x *ListSignedLogRootsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSignedLogRootsRequest.ProtoReflect.Descriptor instead.
+func (*ListSignedLogRootsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListSignedLogRootsRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *ListSignedLogRootsRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ListSignedLogRootsRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *ListSignedLogRootsRequest) GetStartTreeSize() int64 {
+	if x != nil {
+		return x.StartTreeSize
+	}
+	return 0
+}
+
+func (x *ListSignedLogRootsRequest) GetEndTreeSize() int64 {
+	if x != nil {
+		return x.EndTreeSize
+	}
+	return 0
+}
+
+func (x *ListSignedLogRootsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSignedLogRootsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListSignedLogRootsRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+type ListSignedLogRootsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The roots, oldest first.
+	SignedLogRoots []*SignedLogRoot `protobuf:"bytes,1,rep,name=signed_log_roots,json=signedLogRoots,proto3" json:"signed_log_roots,omitempty"`
+	// Set if there may be further roots, which are returned by repeating the
+	// request with it as the page_token.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSignedLogRootsResponse) Reset() {
+	*x = ListSignedLogRootsResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSignedLogRootsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSignedLogRootsResponse) ProtoMessage() {}
+
+func (x *ListSignedLogRootsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSignedLogRootsResponse.ProtoReflect.Descriptor instead.
+func (*ListSignedLogRootsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListSignedLogRootsResponse) GetSignedLogRoots() []*SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoots
+	}
+	return nil
+}
+
+func (x *ListSignedLogRootsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type WatchSignedLogRootsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// If first_tree_size is non-zero, each response will include a consistency
+	// proof from the tree size of the previous response (or first_tree_size for
+	// the first response) to the new tree size.
+	FirstTreeSize int64 `protobuf:"varint,2,opt,name=first_tree_size,json=firstTreeSize,proto3" json:"first_tree_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSignedLogRootsRequest) Reset() {
+	*x = WatchSignedLogRootsRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSignedLogRootsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSignedLogRootsRequest) ProtoMessage() {}
+
+func (x *WatchSignedLogRootsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSignedLogRootsRequest.ProtoReflect.Descriptor instead.
+func (*WatchSignedLogRootsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchSignedLogRootsRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *WatchSignedLogRootsRequest) GetFirstTreeSize() int64 {
+	if x != nil {
+		return x.FirstTreeSize
+	}
+	return 0
+}
+
+type WatchSignedLogRootsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SignedLogRoot *SignedLogRoot         `protobuf:"bytes,1,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// proof is filled in with a consistency proof if first_tree_size in
+	// WatchSignedLogRootsRequest is non-zero.
+	Proof         *Proof `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSignedLogRootsResponse) Reset() {
+	*x = WatchSignedLogRootsResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSignedLogRootsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSignedLogRootsResponse) ProtoMessage() {}
+
+func (x *WatchSignedLogRootsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSignedLogRootsResponse.ProtoReflect.Descriptor instead.
+func (*WatchSignedLogRootsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WatchSignedLogRootsResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+func (x *WatchSignedLogRootsResponse) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type GetTreeStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	ChargeTo      *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTreeStatsRequest) Reset() {
+	*x = GetTreeStatsRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTreeStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeStatsRequest) ProtoMessage() {}
+
+func (x *GetTreeStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetTreeStatsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetTreeStatsRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetTreeStatsRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+type GetTreeStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The size of the tree at its latest root.
+	TreeSize uint64 `protobuf:"varint,1,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	// The time at which the latest root was created.
+	RootTimestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=root_timestamp,json=rootTimestamp,proto3" json:"root_timestamp,omitempty"`
+	// The number of leaves queued but not yet integrated, or -1 if the storage
+	// can't count them.
+	UnsequencedCount int64 `protobuf:"varint,3,opt,name=unsequenced_count,json=unsequencedCount,proto3" json:"unsequenced_count,omitempty"`
+	// The time at which the last leaf of the tree was integrated. Unset if the
+	// tree is empty.
+	LastIntegrationTimestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_integration_timestamp,json=lastIntegrationTimestamp,proto3" json:"last_integration_timestamp,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *GetTreeStatsResponse) Reset() {
+	*x = GetTreeStatsResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTreeStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeStatsResponse) ProtoMessage() {}
+
+func (x *GetTreeStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetTreeStatsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetTreeStatsResponse) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetTreeStatsResponse) GetRootTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RootTimestamp
+	}
+	return nil
+}
+
+func (x *GetTreeStatsResponse) GetUnsequencedCount() int64 {
+	if x != nil {
+		return x.UnsequencedCount
+	}
+	return 0
+}
+
+func (x *GetTreeStatsResponse) GetLastIntegrationTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastIntegrationTimestamp
+	}
+	return nil
+}
+
+type GetSequencedLeafCountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	ChargeTo      *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSequencedLeafCountRequest) Reset() {
+	*x = GetSequencedLeafCountRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSequencedLeafCountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSequencedLeafCountRequest) ProtoMessage() {}
+
+func (x *GetSequencedLeafCountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSequencedLeafCountRequest.ProtoReflect.Descriptor instead.
+func (*GetSequencedLeafCountRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetSequencedLeafCountRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetSequencedLeafCountRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+type GetSequencedLeafCountResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The size of the tree at its latest root.
+	TreeSize uint64 `protobuf:"varint,1,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	// The lowest index at which a leaf is stored, or -1 if there are none.
+	FirstIndex int64 `protobuf:"varint,2,opt,name=first_index,json=firstIndex,proto3" json:"first_index,omitempty"`
+	// The highest index at which a leaf is stored, or -1 if there are none.
+	// Leaves may be missing between first_index and last_index in pre-ordered
+	// logs, which can be filled in any order.
+	LastIndex     int64 `protobuf:"varint,3,opt,name=last_index,json=lastIndex,proto3" json:"last_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSequencedLeafCountResponse) Reset() {
+	*x = GetSequencedLeafCountResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSequencedLeafCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSequencedLeafCountResponse) ProtoMessage() {}
+
+func (x *GetSequencedLeafCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSequencedLeafCountResponse.ProtoReflect.Descriptor instead.
+func (*GetSequencedLeafCountResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetSequencedLeafCountResponse) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetSequencedLeafCountResponse) GetFirstIndex() int64 {
+	if x != nil {
+		return x.FirstIndex
+	}
+	return 0
+}
+
+func (x *GetSequencedLeafCountResponse) GetLastIndex() int64 {
+	if x != nil {
+		return x.LastIndex
+	}
+	return 0
+}
+
+type GetEntryAndProofRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	LogId     int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	LeafIndex int64                  `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	TreeSize  int64                  `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	ChargeTo  *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// If set, the response includes the log root of the proof's tree size in
+	// proof_root. Not all storage implementations support this for roots other
+	// than the latest.
+	IncludeProofRoot bool `protobuf:"varint,5,opt,name=include_proof_root,json=includeProofRoot,proto3" json:"include_proof_root,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken []byte `protobuf:"bytes,6,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	// Indices of further leaves to return with their inclusion proofs to the
+	// same tree size as that of leaf_index. Each must be less than tree_size,
+	// and there may be at most 999 of them. If any is beyond the size of the
+	// proofs, OUT_OF_RANGE is returned.
+	AdditionalLeafIndices []int64 `protobuf:"varint,7,rep,packed,name=additional_leaf_indices,json=additionalLeafIndices,proto3" json:"additional_leaf_indices,omitempty"`
+	// If set, the proofs are to tree_size exactly, and OUT_OF_RANGE is
+	// returned if it's beyond the size of the current root, rather than the
+	// proofs being to the current size.
+	PinTreeSize   bool `protobuf:"varint,8,opt,name=pin_tree_size,json=pinTreeSize,proto3" json:"pin_tree_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntryAndProofRequest) Reset() {
+	*x = GetEntryAndProofRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntryAndProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntryAndProofRequest) ProtoMessage() {}
+
+func (x *GetEntryAndProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntryAndProofRequest.ProtoReflect.Descriptor instead.
+func (*GetEntryAndProofRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetEntryAndProofRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *GetEntryAndProofRequest) GetLeafIndex() int64 {
+	if x != nil {
+		return x.LeafIndex
+	}
+	return 0
+}
+
+func (x *GetEntryAndProofRequest) GetTreeSize() int64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetEntryAndProofRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofRequest) GetIncludeProofRoot() bool {
+	if x != nil {
+		return x.IncludeProofRoot
+	}
+	return false
+}
+
+func (x *GetEntryAndProofRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofRequest) GetAdditionalLeafIndices() []int64 {
+	if x != nil {
+		return x.AdditionalLeafIndices
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofRequest) GetPinTreeSize() bool {
+	if x != nil {
+		return x.PinTreeSize
+	}
+	return false
+}
+
+type GetEntryAndProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proof         *Proof                 `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	Leaf          *LogLeaf               `protobuf:"bytes,3,opt,name=leaf,proto3" json:"leaf,omitempty"`
+	SignedLogRoot *SignedLogRoot         `protobuf:"bytes,4,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// The log root of the same tree size as proof, which proof can be verified
+	// against, if include_proof_root was set in the request. It is unset if the
+	// log never published a root of that size.
+	ProofRoot *SignedLogRoot `protobuf:"bytes,5,opt,name=proof_root,json=proofRoot,proto3" json:"proof_root,omitempty"`
+	// The leaves of additional_leaf_indices and their inclusion proofs, to the
+	// same tree size as proof, in the order they were requested.
+	AdditionalEntries []*EntryAndProof `protobuf:"bytes,6,rep,name=additional_entries,json=additionalEntries,proto3" json:"additional_entries,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetEntryAndProofResponse) Reset() {
+	*x = GetEntryAndProofResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntryAndProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntryAndProofResponse) ProtoMessage() {}
+
+func (x *GetEntryAndProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntryAndProofResponse.ProtoReflect.Descriptor instead.
+func (*GetEntryAndProofResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetEntryAndProofResponse) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofResponse) GetLeaf() *LogLeaf {
+	if x != nil {
+		return x.Leaf
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofResponse) GetProofRoot() *SignedLogRoot {
+	if x != nil {
+		return x.ProofRoot
+	}
+	return nil
+}
+
+func (x *GetEntryAndProofResponse) GetAdditionalEntries() []*EntryAndProof {
+	if x != nil {
+		return x.AdditionalEntries
+	}
+	return nil
+}
+
+// EntryAndProof is a log leaf with its inclusion proof.
+type EntryAndProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Leaf          *LogLeaf               `protobuf:"bytes,1,opt,name=leaf,proto3" json:"leaf,omitempty"`
+	Proof         *Proof                 `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryAndProof) Reset() {
+	*x = EntryAndProof{}
+	mi := &file_trillian_log_api_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryAndProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryAndProof) ProtoMessage() {}
+
+func (x *EntryAndProof) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryAndProof.ProtoReflect.Descriptor instead.
+func (*EntryAndProof) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *EntryAndProof) GetLeaf() *LogLeaf {
+	if x != nil {
+		return x.Leaf
+	}
+	return nil
+}
+
+func (x *EntryAndProof) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type InitLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	ChargeTo      *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitLogRequest) Reset() {
+	*x = InitLogRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitLogRequest) ProtoMessage() {}
+
+func (x *InitLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitLogRequest.ProtoReflect.Descriptor instead.
+func (*InitLogRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *InitLogRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *InitLogRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+type InitLogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Created       *SignedLogRoot         `protobuf:"bytes,1,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitLogResponse) Reset() {
+	*x = InitLogResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitLogResponse) ProtoMessage() {}
+
+func (x *InitLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitLogResponse.ProtoReflect.Descriptor instead.
+func (*InitLogResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *InitLogResponse) GetCreated() *SignedLogRoot {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+type AddSequencedLeavesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	LogId    int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	Leaves   []*LogLeaf             `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	ChargeTo *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// leaf_charge_to optionally names additional users to charge quota to for
+	// each of the leaves. If set, it must have an entry for each of the leaves,
+	// in the same order. The users in charge_to are charged for all leaves, and
+	// the users in leaf_charge_to[i] for leaves[i] only, which allows
+	// personalities batching leaves from many customers to attribute write quota
+	// to the originating customer of each leaf.
+	LeafChargeTo  []*ChargeTo `protobuf:"bytes,5,rep,name=leaf_charge_to,json=leafChargeTo,proto3" json:"leaf_charge_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSequencedLeavesRequest) Reset() {
+	*x = AddSequencedLeavesRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSequencedLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSequencedLeavesRequest) ProtoMessage() {}
+
+func (x *AddSequencedLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSequencedLeavesRequest.ProtoReflect.Descriptor instead.
+func (*AddSequencedLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AddSequencedLeavesRequest) GetLogId() int64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *AddSequencedLeavesRequest) GetLeaves() []*LogLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *AddSequencedLeavesRequest) GetChargeTo() *ChargeTo {
+	if x != nil {
+		return x.ChargeTo
+	}
+	return nil
+}
+
+func (x *AddSequencedLeavesRequest) GetLeafChargeTo() []*ChargeTo {
+	if x != nil {
+		return x.LeafChargeTo
+	}
+	return nil
+}
+
+type AddSequencedLeavesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Same number and order as in the corresponding request.
+	Results []*QueuedLogLeaf `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	// An opaque token identifying the view of the log at the time of the
+	// write, if the server issues session tokens, see QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,3,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSequencedLeavesResponse) Reset() {
+	*x = AddSequencedLeavesResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSequencedLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSequencedLeavesResponse) ProtoMessage() {}
+
+func (x *AddSequencedLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSequencedLeavesResponse.ProtoReflect.Descriptor instead.
+func (*AddSequencedLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AddSequencedLeavesResponse) GetResults() []*QueuedLogLeaf {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *AddSequencedLeavesResponse) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetLeavesByRangeRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	LogId      int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	StartIndex int64                  `protobuf:"varint,2,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
+	Count      int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	ChargeTo   *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,5,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeavesByRangeRequest) Reset() {
+	*x = GetLeavesByRangeRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeavesByRangeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEntryAndProofRequest) ProtoMessage() {}
+func (*GetLeavesByRangeRequest) ProtoMessage() {}
 
-func (x *GetEntryAndProofRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[11]
+func (x *GetLeavesByRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,63 +2252,73 @@ func (x *GetEntryAndProofRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEntryAndProofRequest.ProtoReflect.Descriptor instead.
-func (*GetEntryAndProofRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use GetLeavesByRangeRequest.ProtoReflect.Descriptor instead.
+func (*GetLeavesByRangeRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *GetEntryAndProofRequest) GetLogId() int64 {
+func (x *GetLeavesByRangeRequest) GetLogId() int64 {
 	if x != nil {
 		return x.LogId
 	}
 	return 0
 }
 
-func (x *GetEntryAndProofRequest) GetLeafIndex() int64 {
+func (x *GetLeavesByRangeRequest) GetStartIndex() int64 {
 	if x != nil {
-		return x.LeafIndex
+		return x.StartIndex
 	}
 	return 0
 }
 
-func (x *GetEntryAndProofRequest) GetTreeSize() int64 {
+func (x *GetLeavesByRangeRequest) GetCount() int64 {
 	if x != nil {
-		return x.TreeSize
+		return x.Count
 	}
 	return 0
 }
 
-func (x *GetEntryAndProofRequest) GetChargeTo() *ChargeTo {
+func (x *GetLeavesByRangeRequest) GetChargeTo() *ChargeTo {
 	if x != nil {
 		return x.ChargeTo
 	}
 	return nil
 }
 
-type GetEntryAndProofResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Proof         *Proof                 `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
-	Leaf          *LogLeaf               `protobuf:"bytes,3,opt,name=leaf,proto3" json:"leaf,omitempty"`
-	SignedLogRoot *SignedLogRoot         `protobuf:"bytes,4,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+func (x *GetLeavesByRangeRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetLeavesByRangeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Returned log leaves starting from the `start_index` of the request, in
+	// order. There may be fewer than `request.count` leaves returned, if the
+	// requested range extended beyond the size of the tree or if the server opted
+	// to return fewer leaves than requested.
+	Leaves        []*LogLeaf     `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEntryAndProofResponse) Reset() {
-	*x = GetEntryAndProofResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[12]
+func (x *GetLeavesByRangeResponse) Reset() {
+	*x = GetLeavesByRangeResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEntryAndProofResponse) String() string {
+func (x *GetLeavesByRangeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEntryAndProofResponse) ProtoMessage() {}
+func (*GetLeavesByRangeResponse) ProtoMessage() {}
 
-func (x *GetEntryAndProofResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[12]
+func (x *GetLeavesByRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,55 +2329,54 @@ func (x *GetEntryAndProofResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEntryAndProofResponse.ProtoReflect.Descriptor instead.
-func (*GetEntryAndProofResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *GetEntryAndProofResponse) GetProof() *Proof {
-	if x != nil {
-		return x.Proof
-	}
-	return nil
+// Deprecated: Use GetLeavesByRangeResponse.ProtoReflect.Descriptor instead.
+func (*GetLeavesByRangeResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *GetEntryAndProofResponse) GetLeaf() *LogLeaf {
+func (x *GetLeavesByRangeResponse) GetLeaves() []*LogLeaf {
 	if x != nil {
-		return x.Leaf
+		return x.Leaves
 	}
 	return nil
 }
 
-func (x *GetEntryAndProofResponse) GetSignedLogRoot() *SignedLogRoot {
+func (x *GetLeavesByRangeResponse) GetSignedLogRoot() *SignedLogRoot {
 	if x != nil {
 		return x.SignedLogRoot
 	}
 	return nil
 }
 
-type InitLogRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	ChargeTo      *ChargeTo              `protobuf:"bytes,2,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+type GetLeavesByIndicesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The indices of the leaves to return. Each must be less than the size of
+	// the tree, and there may be at most 1000 of them.
+	LeafIndex []int64   `protobuf:"varint,2,rep,packed,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	ChargeTo  *ChargeTo `protobuf:"bytes,3,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+	// Optional session_token returned by an earlier write, see
+	// QueueLeafResponse.
+	SessionToken  []byte `protobuf:"bytes,4,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitLogRequest) Reset() {
-	*x = InitLogRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[13]
+func (x *GetLeavesByIndicesRequest) Reset() {
+	*x = GetLeavesByIndicesRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitLogRequest) String() string {
+func (x *GetLeavesByIndicesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitLogRequest) ProtoMessage() {}
+func (*GetLeavesByIndicesRequest) ProtoMessage() {}
 
-func (x *InitLogRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[13]
+func (x *GetLeavesByIndicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -869,47 +2387,63 @@ func (x *InitLogRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitLogRequest.ProtoReflect.Descriptor instead.
-func (*InitLogRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use GetLeavesByIndicesRequest.ProtoReflect.Descriptor instead.
+func (*GetLeavesByIndicesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *InitLogRequest) GetLogId() int64 {
+func (x *GetLeavesByIndicesRequest) GetLogId() int64 {
 	if x != nil {
 		return x.LogId
 	}
 	return 0
 }
 
-func (x *InitLogRequest) GetChargeTo() *ChargeTo {
+func (x *GetLeavesByIndicesRequest) GetLeafIndex() []int64 {
+	if x != nil {
+		return x.LeafIndex
+	}
+	return nil
+}
+
+func (x *GetLeavesByIndicesRequest) GetChargeTo() *ChargeTo {
 	if x != nil {
 		return x.ChargeTo
 	}
 	return nil
 }
 
-type InitLogResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Created       *SignedLogRoot         `protobuf:"bytes,1,opt,name=created,proto3" json:"created,omitempty"`
+func (x *GetLeavesByIndicesRequest) GetSessionToken() []byte {
+	if x != nil {
+		return x.SessionToken
+	}
+	return nil
+}
+
+type GetLeavesByIndicesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The leaves with the requested indices, in the order they were requested.
+	Leaves        []*LogLeaf     `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	SignedLogRoot *SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitLogResponse) Reset() {
-	*x = InitLogResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[14]
+func (x *GetLeavesByIndicesResponse) Reset() {
+	*x = GetLeavesByIndicesResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitLogResponse) String() string {
+func (x *GetLeavesByIndicesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitLogResponse) ProtoMessage() {}
+func (*GetLeavesByIndicesResponse) ProtoMessage() {}
 
-func (x *InitLogResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[14]
+func (x *GetLeavesByIndicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -920,42 +2454,56 @@ func (x *InitLogResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitLogResponse.ProtoReflect.Descriptor instead.
-func (*InitLogResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use GetLeavesByIndicesResponse.ProtoReflect.Descriptor instead.
+func (*GetLeavesByIndicesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *InitLogResponse) GetCreated() *SignedLogRoot {
+func (x *GetLeavesByIndicesResponse) GetLeaves() []*LogLeaf {
 	if x != nil {
-		return x.Created
+		return x.Leaves
 	}
 	return nil
 }
 
-type AddSequencedLeavesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	Leaves        []*LogLeaf             `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
-	ChargeTo      *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+func (x *GetLeavesByIndicesResponse) GetSignedLogRoot() *SignedLogRoot {
+	if x != nil {
+		return x.SignedLogRoot
+	}
+	return nil
+}
+
+type UpdateLeafExtraDataRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The index of the leaf whose extra_data is replaced. Must be less than the
+	// current tree size.
+	LeafIndex int64 `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	// The new extra_data.
+	ExtraData []byte `protobuf:"bytes,3,opt,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty"`
+	// Why the extra_data is being replaced, recorded in the audit trail.
+	// Required.
+	Reason        string    `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	ChargeTo      *ChargeTo `protobuf:"bytes,5,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddSequencedLeavesRequest) Reset() {
-	*x = AddSequencedLeavesRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[15]
+func (x *UpdateLeafExtraDataRequest) Reset() {
+	*x = UpdateLeafExtraDataRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddSequencedLeavesRequest) String() string {
+func (x *UpdateLeafExtraDataRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddSequencedLeavesRequest) ProtoMessage() {}
+func (*UpdateLeafExtraDataRequest) ProtoMessage() {}
 
-func (x *AddSequencedLeavesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[15]
+func (x *UpdateLeafExtraDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -966,55 +2514,69 @@ func (x *AddSequencedLeavesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddSequencedLeavesRequest.ProtoReflect.Descriptor instead.
-func (*AddSequencedLeavesRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use UpdateLeafExtraDataRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLeafExtraDataRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *AddSequencedLeavesRequest) GetLogId() int64 {
+func (x *UpdateLeafExtraDataRequest) GetLogId() int64 {
 	if x != nil {
 		return x.LogId
 	}
 	return 0
 }
 
-func (x *AddSequencedLeavesRequest) GetLeaves() []*LogLeaf {
+func (x *UpdateLeafExtraDataRequest) GetLeafIndex() int64 {
 	if x != nil {
-		return x.Leaves
+		return x.LeafIndex
+	}
+	return 0
+}
+
+func (x *UpdateLeafExtraDataRequest) GetExtraData() []byte {
+	if x != nil {
+		return x.ExtraData
 	}
 	return nil
 }
 
-func (x *AddSequencedLeavesRequest) GetChargeTo() *ChargeTo {
+func (x *UpdateLeafExtraDataRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *UpdateLeafExtraDataRequest) GetChargeTo() *ChargeTo {
 	if x != nil {
 		return x.ChargeTo
 	}
 	return nil
 }
 
-type AddSequencedLeavesResponse struct {
+type UpdateLeafExtraDataResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Same number and order as in the corresponding request.
-	Results       []*QueuedLogLeaf `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	// The leaf, with its new extra_data.
+	Leaf          *LogLeaf `protobuf:"bytes,1,opt,name=leaf,proto3" json:"leaf,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddSequencedLeavesResponse) Reset() {
-	*x = AddSequencedLeavesResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[16]
+func (x *UpdateLeafExtraDataResponse) Reset() {
+	*x = UpdateLeafExtraDataResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddSequencedLeavesResponse) String() string {
+func (x *UpdateLeafExtraDataResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddSequencedLeavesResponse) ProtoMessage() {}
+func (*UpdateLeafExtraDataResponse) ProtoMessage() {}
 
-func (x *AddSequencedLeavesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[16]
+func (x *UpdateLeafExtraDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1025,43 +2587,45 @@ func (x *AddSequencedLeavesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddSequencedLeavesResponse.ProtoReflect.Descriptor instead.
-func (*AddSequencedLeavesResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use UpdateLeafExtraDataResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLeafExtraDataResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *AddSequencedLeavesResponse) GetResults() []*QueuedLogLeaf {
+func (x *UpdateLeafExtraDataResponse) GetLeaf() *LogLeaf {
 	if x != nil {
-		return x.Results
+		return x.Leaf
 	}
 	return nil
 }
 
-type GetLeavesByRangeRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogId         int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	StartIndex    int64                  `protobuf:"varint,2,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
-	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
-	ChargeTo      *ChargeTo              `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
+type RedactLeafRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	LogId int64                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// The index of the leaf to redact. Must be less than the current tree size.
+	LeafIndex int64 `protobuf:"varint,2,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	// Why the leaf is being redacted, recorded in the audit trail. Required.
+	Reason        string    `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	ChargeTo      *ChargeTo `protobuf:"bytes,4,opt,name=charge_to,json=chargeTo,proto3" json:"charge_to,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLeavesByRangeRequest) Reset() {
-	*x = GetLeavesByRangeRequest{}
-	mi := &file_trillian_log_api_proto_msgTypes[17]
+func (x *RedactLeafRequest) Reset() {
+	*x = RedactLeafRequest{}
+	mi := &file_trillian_log_api_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLeavesByRangeRequest) String() string {
+func (x *RedactLeafRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLeavesByRangeRequest) ProtoMessage() {}
+func (*RedactLeafRequest) ProtoMessage() {}
 
-func (x *GetLeavesByRangeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[17]
+func (x *RedactLeafRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1072,66 +2636,62 @@ func (x *GetLeavesByRangeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLeavesByRangeRequest.ProtoReflect.Descriptor instead.
-func (*GetLeavesByRangeRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use RedactLeafRequest.ProtoReflect.Descriptor instead.
+func (*RedactLeafRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *GetLeavesByRangeRequest) GetLogId() int64 {
+func (x *RedactLeafRequest) GetLogId() int64 {
 	if x != nil {
 		return x.LogId
 	}
 	return 0
 }
 
-func (x *GetLeavesByRangeRequest) GetStartIndex() int64 {
+func (x *RedactLeafRequest) GetLeafIndex() int64 {
 	if x != nil {
-		return x.StartIndex
+		return x.LeafIndex
 	}
 	return 0
 }
 
-func (x *GetLeavesByRangeRequest) GetCount() int64 {
+func (x *RedactLeafRequest) GetReason() string {
 	if x != nil {
-		return x.Count
+		return x.Reason
 	}
-	return 0
+	return ""
 }
 
-func (x *GetLeavesByRangeRequest) GetChargeTo() *ChargeTo {
+func (x *RedactLeafRequest) GetChargeTo() *ChargeTo {
 	if x != nil {
 		return x.ChargeTo
 	}
 	return nil
 }
 
-type GetLeavesByRangeResponse struct {
+type RedactLeafResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Returned log leaves starting from the `start_index` of the request, in
-	// order. There may be fewer than `request.count` leaves returned, if the
-	// requested range extended beyond the size of the tree or if the server opted
-	// to return fewer leaves than requested.
-	Leaves        []*LogLeaf     `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
-	SignedLogRoot *SignedLogRoot `protobuf:"bytes,2,opt,name=signed_log_root,json=signedLogRoot,proto3" json:"signed_log_root,omitempty"`
+	// The redacted leaf.
+	Leaf          *LogLeaf `protobuf:"bytes,1,opt,name=leaf,proto3" json:"leaf,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLeavesByRangeResponse) Reset() {
-	*x = GetLeavesByRangeResponse{}
-	mi := &file_trillian_log_api_proto_msgTypes[18]
+func (x *RedactLeafResponse) Reset() {
+	*x = RedactLeafResponse{}
+	mi := &file_trillian_log_api_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLeavesByRangeResponse) String() string {
+func (x *RedactLeafResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLeavesByRangeResponse) ProtoMessage() {}
+func (*RedactLeafResponse) ProtoMessage() {}
 
-func (x *GetLeavesByRangeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[18]
+func (x *RedactLeafResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_log_api_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1142,21 +2702,14 @@ func (x *GetLeavesByRangeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLeavesByRangeResponse.ProtoReflect.Descriptor instead.
-func (*GetLeavesByRangeResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{18}
-}
-
-func (x *GetLeavesByRangeResponse) GetLeaves() []*LogLeaf {
-	if x != nil {
-		return x.Leaves
-	}
-	return nil
+// Deprecated: Use RedactLeafResponse.ProtoReflect.Descriptor instead.
+func (*RedactLeafResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *GetLeavesByRangeResponse) GetSignedLogRoot() *SignedLogRoot {
+func (x *RedactLeafResponse) GetLeaf() *LogLeaf {
 	if x != nil {
-		return x.SignedLogRoot
+		return x.Leaf
 	}
 	return nil
 }
@@ -1184,7 +2737,7 @@ type QueuedLogLeaf struct {
 
 func (x *QueuedLogLeaf) Reset() {
 	*x = QueuedLogLeaf{}
-	mi := &file_trillian_log_api_proto_msgTypes[19]
+	mi := &file_trillian_log_api_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1196,7 +2749,7 @@ func (x *QueuedLogLeaf) String() string {
 func (*QueuedLogLeaf) ProtoMessage() {}
 
 func (x *QueuedLogLeaf) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[19]
+	mi := &file_trillian_log_api_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1209,7 +2762,7 @@ func (x *QueuedLogLeaf) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueuedLogLeaf.ProtoReflect.Descriptor instead.
 func (*QueuedLogLeaf) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{19}
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *QueuedLogLeaf) GetLeaf() *LogLeaf {
@@ -1291,13 +2844,18 @@ type LogLeaf struct {
 	// integrate_timestamp holds the time at which this leaf was integrated into
 	// the tree.  Clients should not set this field on submissions.
 	IntegrateTimestamp *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=integrate_timestamp,json=integrateTimestamp,proto3" json:"integrate_timestamp,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// redacted is set if the leaf_value has been removed with RedactLeaf, in
+	// which case leaf_value is empty. The merkle_leaf_hash is still that of the
+	// original leaf_value, so proofs for the leaf remain valid. Clients should
+	// not set this field on submissions.
+	Redacted      bool `protobuf:"varint,8,opt,name=redacted,proto3" json:"redacted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LogLeaf) Reset() {
 	*x = LogLeaf{}
-	mi := &file_trillian_log_api_proto_msgTypes[20]
+	mi := &file_trillian_log_api_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1309,7 +2867,7 @@ func (x *LogLeaf) String() string {
 func (*LogLeaf) ProtoMessage() {}
 
 func (x *LogLeaf) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_log_api_proto_msgTypes[20]
+	mi := &file_trillian_log_api_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1322,7 +2880,7 @@ func (x *LogLeaf) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogLeaf.ProtoReflect.Descriptor instead.
 func (*LogLeaf) Descriptor() ([]byte, []int) {
-	return file_trillian_log_api_proto_rawDescGZIP(), []int{20}
+	return file_trillian_log_api_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *LogLeaf) GetMerkleLeafHash() []byte {
@@ -1374,6 +2932,13 @@ func (x *LogLeaf) GetIntegrateTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *LogLeaf) GetRedacted() bool {
+	if x != nil {
+		return x.Redacted
+	}
+	return false
+}
+
 var File_trillian_log_api_proto protoreflect.FileDescriptor
 
 const file_trillian_log_api_proto_rawDesc = "" +
@@ -1384,76 +2949,197 @@ const file_trillian_log_api_proto_rawDesc = "" +
 	"\x10QueueLeafRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12%\n" +
 	"\x04leaf\x18\x02 \x01(\v2\x11.trillian.LogLeafR\x04leaf\x12/\n" +
-	"\tcharge_to\x18\x03 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"M\n" +
+	"\tcharge_to\x18\x03 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"r\n" +
 	"\x11QueueLeafResponse\x128\n" +
 	"\vqueued_leaf\x18\x02 \x01(\v2\x17.trillian.QueuedLogLeafR\n" +
-	"queuedLeaf\"\x9e\x01\n" +
+	"queuedLeaf\x12#\n" +
+	"\rsession_token\x18\x03 \x01(\fR\fsessionToken\"\xf1\x01\n" +
 	"\x18GetInclusionProofRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1d\n" +
 	"\n" +
 	"leaf_index\x18\x02 \x01(\x03R\tleafIndex\x12\x1b\n" +
 	"\ttree_size\x18\x03 \x01(\x03R\btreeSize\x12/\n" +
-	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\x83\x01\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12,\n" +
+	"\x12include_proof_root\x18\x05 \x01(\bR\x10includeProofRoot\x12#\n" +
+	"\rsession_token\x18\x06 \x01(\fR\fsessionToken\"\xbb\x01\n" +
 	"\x19GetInclusionProofResponse\x12%\n" +
 	"\x05proof\x18\x02 \x01(\v2\x0f.trillian.ProofR\x05proof\x12?\n" +
-	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xce\x01\n" +
+	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\x126\n" +
+	"\n" +
+	"proof_root\x18\x04 \x01(\v2\x17.trillian.SignedLogRootR\tproofRoot\"\xf3\x01\n" +
 	"\x1eGetInclusionProofByHashRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1b\n" +
 	"\tleaf_hash\x18\x02 \x01(\fR\bleafHash\x12\x1b\n" +
 	"\ttree_size\x18\x03 \x01(\x03R\btreeSize\x12*\n" +
 	"\x11order_by_sequence\x18\x04 \x01(\bR\x0forderBySequence\x12/\n" +
-	"\tcharge_to\x18\x05 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\x89\x01\n" +
+	"\tcharge_to\x18\x05 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x06 \x01(\fR\fsessionToken\"\x89\x01\n" +
 	"\x1fGetInclusionProofByHashResponse\x12%\n" +
 	"\x05proof\x18\x02 \x03(\v2\x0f.trillian.ProofR\x05proof\x12?\n" +
-	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xb6\x01\n" +
+	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\x8c\x02\n" +
+	"&GetInclusionProofByIdentityHashRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12,\n" +
+	"\x12leaf_identity_hash\x18\x02 \x01(\fR\x10leafIdentityHash\x12\x1b\n" +
+	"\ttree_size\x18\x03 \x01(\x03R\btreeSize\x12*\n" +
+	"\x11order_by_sequence\x18\x04 \x01(\bR\x0forderBySequence\x12/\n" +
+	"\tcharge_to\x18\x05 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x06 \x01(\fR\fsessionToken\"\x91\x01\n" +
+	"'GetInclusionProofByIdentityHashResponse\x12%\n" +
+	"\x05proof\x18\x01 \x03(\v2\x0f.trillian.ProofR\x05proof\x12?\n" +
+	"\x0fsigned_log_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xdb\x01\n" +
 	"\x1aGetConsistencyProofRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12&\n" +
 	"\x0ffirst_tree_size\x18\x02 \x01(\x03R\rfirstTreeSize\x12(\n" +
 	"\x10second_tree_size\x18\x03 \x01(\x03R\x0esecondTreeSize\x12/\n" +
-	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\x85\x01\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x05 \x01(\fR\fsessionToken\"\x85\x01\n" +
 	"\x1bGetConsistencyProofResponse\x12%\n" +
 	"\x05proof\x18\x02 \x01(\v2\x0f.trillian.ProofR\x05proof\x12?\n" +
-	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\x8f\x01\n" +
+	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xe5\x01\n" +
+	"$GetConsistencyProofByRootHashRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12&\n" +
+	"\x0ffirst_root_hash\x18\x02 \x01(\fR\rfirstRootHash\x12(\n" +
+	"\x10second_root_hash\x18\x03 \x01(\fR\x0esecondRootHash\x12/\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x05 \x01(\fR\fsessionToken\"\x81\x02\n" +
+	"%GetConsistencyProofByRootHashResponse\x12%\n" +
+	"\x05proof\x18\x01 \x01(\v2\x0f.trillian.ProofR\x05proof\x126\n" +
+	"\n" +
+	"first_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\tfirstRoot\x128\n" +
+	"\vsecond_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\n" +
+	"secondRoot\x12?\n" +
+	"\x0fsigned_log_root\x18\x04 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xe7\x01\n" +
+	"\x1dGetRangeInclusionProofRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1f\n" +
+	"\vstart_index\x18\x02 \x01(\x03R\n" +
+	"startIndex\x12\x1b\n" +
+	"\tend_index\x18\x03 \x01(\x03R\bendIndex\x12\x1b\n" +
+	"\ttree_size\x18\x04 \x01(\x03R\btreeSize\x12/\n" +
+	"\tcharge_to\x18\x05 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x06 \x01(\fR\fsessionToken\"\xa5\x01\n" +
+	"\x1eGetRangeInclusionProofResponse\x12\x1f\n" +
+	"\vleft_hashes\x18\x01 \x03(\fR\n" +
+	"leftHashes\x12!\n" +
+	"\fright_hashes\x18\x02 \x03(\fR\vrightHashes\x12?\n" +
+	"\x0fsigned_log_root\x18\x03 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xb4\x01\n" +
 	"\x1dGetLatestSignedLogRootRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12/\n" +
 	"\tcharge_to\x18\x02 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12&\n" +
-	"\x0ffirst_tree_size\x18\x03 \x01(\x03R\rfirstTreeSize\"\x88\x01\n" +
+	"\x0ffirst_tree_size\x18\x03 \x01(\x03R\rfirstTreeSize\x12#\n" +
+	"\rsession_token\x18\x04 \x01(\fR\fsessionToken\"\x88\x01\n" +
 	"\x1eGetLatestSignedLogRootResponse\x12?\n" +
 	"\x0fsigned_log_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\x12%\n" +
-	"\x05proof\x18\x03 \x01(\v2\x0f.trillian.ProofR\x05proof\"\x9d\x01\n" +
+	"\x05proof\x18\x03 \x01(\v2\x0f.trillian.ProofR\x05proof\"\xdd\x02\n" +
+	"\x19ListSignedLogRootsRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12&\n" +
+	"\x0fstart_tree_size\x18\x04 \x01(\x03R\rstartTreeSize\x12\"\n" +
+	"\rend_tree_size\x18\x05 \x01(\x03R\vendTreeSize\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\x12/\n" +
+	"\tcharge_to\x18\b \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\x87\x01\n" +
+	"\x1aListSignedLogRootsResponse\x12A\n" +
+	"\x10signed_log_roots\x18\x01 \x03(\v2\x17.trillian.SignedLogRootR\x0esignedLogRoots\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"[\n" +
+	"\x1aWatchSignedLogRootsRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12&\n" +
+	"\x0ffirst_tree_size\x18\x02 \x01(\x03R\rfirstTreeSize\"\x85\x01\n" +
+	"\x1bWatchSignedLogRootsResponse\x12?\n" +
+	"\x0fsigned_log_root\x18\x01 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\x12%\n" +
+	"\x05proof\x18\x02 \x01(\v2\x0f.trillian.ProofR\x05proof\"]\n" +
+	"\x13GetTreeStatsRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12/\n" +
+	"\tcharge_to\x18\x02 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\xfd\x01\n" +
+	"\x14GetTreeStatsResponse\x12\x1b\n" +
+	"\ttree_size\x18\x01 \x01(\x04R\btreeSize\x12A\n" +
+	"\x0eroot_timestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\rrootTimestamp\x12+\n" +
+	"\x11unsequenced_count\x18\x03 \x01(\x03R\x10unsequencedCount\x12X\n" +
+	"\x1alast_integration_timestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x18lastIntegrationTimestamp\"f\n" +
+	"\x1cGetSequencedLeafCountRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12/\n" +
+	"\tcharge_to\x18\x02 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"|\n" +
+	"\x1dGetSequencedLeafCountResponse\x12\x1b\n" +
+	"\ttree_size\x18\x01 \x01(\x04R\btreeSize\x12\x1f\n" +
+	"\vfirst_index\x18\x02 \x01(\x03R\n" +
+	"firstIndex\x12\x1d\n" +
+	"\n" +
+	"last_index\x18\x03 \x01(\x03R\tlastIndex\"\xcc\x02\n" +
 	"\x17GetEntryAndProofRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1d\n" +
 	"\n" +
 	"leaf_index\x18\x02 \x01(\x03R\tleafIndex\x12\x1b\n" +
 	"\ttree_size\x18\x03 \x01(\x03R\btreeSize\x12/\n" +
-	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\xa9\x01\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12,\n" +
+	"\x12include_proof_root\x18\x05 \x01(\bR\x10includeProofRoot\x12#\n" +
+	"\rsession_token\x18\x06 \x01(\fR\fsessionToken\x126\n" +
+	"\x17additional_leaf_indices\x18\a \x03(\x03R\x15additionalLeafIndices\x12\"\n" +
+	"\rpin_tree_size\x18\b \x01(\bR\vpinTreeSize\"\xa9\x02\n" +
 	"\x18GetEntryAndProofResponse\x12%\n" +
 	"\x05proof\x18\x02 \x01(\v2\x0f.trillian.ProofR\x05proof\x12%\n" +
 	"\x04leaf\x18\x03 \x01(\v2\x11.trillian.LogLeafR\x04leaf\x12?\n" +
-	"\x0fsigned_log_root\x18\x04 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"X\n" +
+	"\x0fsigned_log_root\x18\x04 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\x126\n" +
+	"\n" +
+	"proof_root\x18\x05 \x01(\v2\x17.trillian.SignedLogRootR\tproofRoot\x12F\n" +
+	"\x12additional_entries\x18\x06 \x03(\v2\x17.trillian.EntryAndProofR\x11additionalEntries\"]\n" +
+	"\rEntryAndProof\x12%\n" +
+	"\x04leaf\x18\x01 \x01(\v2\x11.trillian.LogLeafR\x04leaf\x12%\n" +
+	"\x05proof\x18\x02 \x01(\v2\x0f.trillian.ProofR\x05proof\"X\n" +
 	"\x0eInitLogRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12/\n" +
 	"\tcharge_to\x18\x02 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"D\n" +
 	"\x0fInitLogResponse\x121\n" +
-	"\acreated\x18\x01 \x01(\v2\x17.trillian.SignedLogRootR\acreated\"\x8e\x01\n" +
+	"\acreated\x18\x01 \x01(\v2\x17.trillian.SignedLogRootR\acreated\"\xc8\x01\n" +
 	"\x19AddSequencedLeavesRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12)\n" +
 	"\x06leaves\x18\x02 \x03(\v2\x11.trillian.LogLeafR\x06leaves\x12/\n" +
-	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"O\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x128\n" +
+	"\x0eleaf_charge_to\x18\x05 \x03(\v2\x12.trillian.ChargeToR\fleafChargeTo\"t\n" +
 	"\x1aAddSequencedLeavesResponse\x121\n" +
-	"\aresults\x18\x02 \x03(\v2\x17.trillian.QueuedLogLeafR\aresults\"\x98\x01\n" +
+	"\aresults\x18\x02 \x03(\v2\x17.trillian.QueuedLogLeafR\aresults\x12#\n" +
+	"\rsession_token\x18\x03 \x01(\fR\fsessionToken\"\xbd\x01\n" +
 	"\x17GetLeavesByRangeRequest\x12\x15\n" +
 	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1f\n" +
 	"\vstart_index\x18\x02 \x01(\x03R\n" +
 	"startIndex\x12\x14\n" +
 	"\x05count\x18\x03 \x01(\x03R\x05count\x12/\n" +
-	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"\x86\x01\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x05 \x01(\fR\fsessionToken\"\x86\x01\n" +
 	"\x18GetLeavesByRangeResponse\x12)\n" +
 	"\x06leaves\x18\x01 \x03(\v2\x11.trillian.LogLeafR\x06leaves\x12?\n" +
-	"\x0fsigned_log_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"b\n" +
+	"\x0fsigned_log_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xa7\x01\n" +
+	"\x19GetLeavesByIndicesRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1d\n" +
+	"\n" +
+	"leaf_index\x18\x02 \x03(\x03R\tleafIndex\x12/\n" +
+	"\tcharge_to\x18\x03 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\x12#\n" +
+	"\rsession_token\x18\x04 \x01(\fR\fsessionToken\"\x88\x01\n" +
+	"\x1aGetLeavesByIndicesResponse\x12)\n" +
+	"\x06leaves\x18\x01 \x03(\v2\x11.trillian.LogLeafR\x06leaves\x12?\n" +
+	"\x0fsigned_log_root\x18\x02 \x01(\v2\x17.trillian.SignedLogRootR\rsignedLogRoot\"\xba\x01\n" +
+	"\x1aUpdateLeafExtraDataRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1d\n" +
+	"\n" +
+	"leaf_index\x18\x02 \x01(\x03R\tleafIndex\x12\x1d\n" +
+	"\n" +
+	"extra_data\x18\x03 \x01(\fR\textraData\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12/\n" +
+	"\tcharge_to\x18\x05 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\"D\n" +
+	"\x1bUpdateLeafExtraDataResponse\x12%\n" +
+	"\x04leaf\x18\x01 \x01(\v2\x11.trillian.LogLeafR\x04leaf\"\x92\x01\n" +
+	"\x11RedactLeafRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x03R\x05logId\x12\x1d\n" +
+	"\n" +
+	"leaf_index\x18\x02 \x01(\x03R\tleafIndex\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12/\n" +
+	"\tcharge_to\x18\x04 \x01(\v2\x12.trillian.ChargeToR\bchargeTo\";\n" +
+	"\x12RedactLeafResponse\x12%\n" +
+	"\x04leaf\x18\x01 \x01(\v2\x11.trillian.LogLeafR\x04leaf\"b\n" +
 	"\rQueuedLogLeaf\x12%\n" +
 	"\x04leaf\x18\x01 \x01(\v2\x11.trillian.LogLeafR\x04leaf\x12*\n" +
-	"\x06status\x18\x02 \x01(\v2\x12.google.rpc.StatusR\x06status\"\xd0\x02\n" +
+	"\x06status\x18\x02 \x01(\v2\x12.google.rpc.StatusR\x06status\"\xec\x02\n" +
 	"\aLogLeaf\x12(\n" +
 	"\x10merkle_leaf_hash\x18\x01 \x01(\fR\x0emerkleLeafHash\x12\x1d\n" +
 	"\n" +
@@ -1464,17 +3150,29 @@ const file_trillian_log_api_proto_rawDesc = "" +
 	"leaf_index\x18\x04 \x01(\x03R\tleafIndex\x12,\n" +
 	"\x12leaf_identity_hash\x18\x05 \x01(\fR\x10leafIdentityHash\x12C\n" +
 	"\x0fqueue_timestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0equeueTimestamp\x12K\n" +
-	"\x13integrate_timestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x12integrateTimestamp2\xdb\x06\n" +
+	"\x13integrate_timestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x12integrateTimestamp\x12\x1a\n" +
+	"\bredacted\x18\b \x01(\bR\bredacted2\xf6\x0e\n" +
 	"\vTrillianLog\x12F\n" +
 	"\tQueueLeaf\x12\x1a.trillian.QueueLeafRequest\x1a\x1b.trillian.QueueLeafResponse\"\x00\x12^\n" +
 	"\x11GetInclusionProof\x12\".trillian.GetInclusionProofRequest\x1a#.trillian.GetInclusionProofResponse\"\x00\x12p\n" +
-	"\x17GetInclusionProofByHash\x12(.trillian.GetInclusionProofByHashRequest\x1a).trillian.GetInclusionProofByHashResponse\"\x00\x12d\n" +
-	"\x13GetConsistencyProof\x12$.trillian.GetConsistencyProofRequest\x1a%.trillian.GetConsistencyProofResponse\"\x00\x12m\n" +
-	"\x16GetLatestSignedLogRoot\x12'.trillian.GetLatestSignedLogRootRequest\x1a(.trillian.GetLatestSignedLogRootResponse\"\x00\x12[\n" +
+	"\x17GetInclusionProofByHash\x12(.trillian.GetInclusionProofByHashRequest\x1a).trillian.GetInclusionProofByHashResponse\"\x00\x12\x88\x01\n" +
+	"\x1fGetInclusionProofByIdentityHash\x120.trillian.GetInclusionProofByIdentityHashRequest\x1a1.trillian.GetInclusionProofByIdentityHashResponse\"\x00\x12d\n" +
+	"\x13GetConsistencyProof\x12$.trillian.GetConsistencyProofRequest\x1a%.trillian.GetConsistencyProofResponse\"\x00\x12\x82\x01\n" +
+	"\x1dGetConsistencyProofByRootHash\x12..trillian.GetConsistencyProofByRootHashRequest\x1a/.trillian.GetConsistencyProofByRootHashResponse\"\x00\x12m\n" +
+	"\x16GetRangeInclusionProof\x12'.trillian.GetRangeInclusionProofRequest\x1a(.trillian.GetRangeInclusionProofResponse\"\x00\x12m\n" +
+	"\x16GetLatestSignedLogRoot\x12'.trillian.GetLatestSignedLogRootRequest\x1a(.trillian.GetLatestSignedLogRootResponse\"\x00\x12a\n" +
+	"\x12ListSignedLogRoots\x12#.trillian.ListSignedLogRootsRequest\x1a$.trillian.ListSignedLogRootsResponse\"\x00\x12O\n" +
+	"\fGetTreeStats\x12\x1d.trillian.GetTreeStatsRequest\x1a\x1e.trillian.GetTreeStatsResponse\"\x00\x12j\n" +
+	"\x15GetSequencedLeafCount\x12&.trillian.GetSequencedLeafCountRequest\x1a'.trillian.GetSequencedLeafCountResponse\"\x00\x12[\n" +
 	"\x10GetEntryAndProof\x12!.trillian.GetEntryAndProofRequest\x1a\".trillian.GetEntryAndProofResponse\"\x00\x12@\n" +
 	"\aInitLog\x12\x18.trillian.InitLogRequest\x1a\x19.trillian.InitLogResponse\"\x00\x12a\n" +
 	"\x12AddSequencedLeaves\x12#.trillian.AddSequencedLeavesRequest\x1a$.trillian.AddSequencedLeavesResponse\"\x00\x12[\n" +
-	"\x10GetLeavesByRange\x12!.trillian.GetLeavesByRangeRequest\x1a\".trillian.GetLeavesByRangeResponse\"\x00BN\n" +
+	"\x10GetLeavesByRange\x12!.trillian.GetLeavesByRangeRequest\x1a\".trillian.GetLeavesByRangeResponse\"\x00\x12a\n" +
+	"\x12GetLeavesByIndices\x12#.trillian.GetLeavesByIndicesRequest\x1a$.trillian.GetLeavesByIndicesResponse\"\x00\x12f\n" +
+	"\x13WatchSignedLogRoots\x12$.trillian.WatchSignedLogRootsRequest\x1a%.trillian.WatchSignedLogRootsResponse\"\x000\x01\x12d\n" +
+	"\x13UpdateLeafExtraData\x12$.trillian.UpdateLeafExtraDataRequest\x1a%.trillian.UpdateLeafExtraDataResponse\"\x00\x12I\n" +
+	"\n" +
+	"RedactLeaf\x12\x1b.trillian.RedactLeafRequest\x1a\x1c.trillian.RedactLeafResponse\"\x00BN\n" +
 	"\x19com.google.trillian.protoB\x13TrillianLogApiProtoP\x01Z\x1agithub.com/google/trillianb\x06proto3"
 
 var (
@@ -1489,89 +3187,163 @@ func file_trillian_log_api_proto_rawDescGZIP() []byte {
 	return file_trillian_log_api_proto_rawDescData
 }
 
-var file_trillian_log_api_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_trillian_log_api_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_trillian_log_api_proto_goTypes = []any{
-	(*ChargeTo)(nil),                        // 0: trillian.ChargeTo
-	(*QueueLeafRequest)(nil),                // 1: trillian.QueueLeafRequest
-	(*QueueLeafResponse)(nil),               // 2: trillian.QueueLeafResponse
-	(*GetInclusionProofRequest)(nil),        // 3: trillian.GetInclusionProofRequest
-	(*GetInclusionProofResponse)(nil),       // 4: trillian.GetInclusionProofResponse
-	(*GetInclusionProofByHashRequest)(nil),  // 5: trillian.GetInclusionProofByHashRequest
-	(*GetInclusionProofByHashResponse)(nil), // 6: trillian.GetInclusionProofByHashResponse
-	(*GetConsistencyProofRequest)(nil),      // 7: trillian.GetConsistencyProofRequest
-	(*GetConsistencyProofResponse)(nil),     // 8: trillian.GetConsistencyProofResponse
-	(*GetLatestSignedLogRootRequest)(nil),   // 9: trillian.GetLatestSignedLogRootRequest
-	(*GetLatestSignedLogRootResponse)(nil),  // 10: trillian.GetLatestSignedLogRootResponse
-	(*GetEntryAndProofRequest)(nil),         // 11: trillian.GetEntryAndProofRequest
-	(*GetEntryAndProofResponse)(nil),        // 12: trillian.GetEntryAndProofResponse
-	(*InitLogRequest)(nil),                  // 13: trillian.InitLogRequest
-	(*InitLogResponse)(nil),                 // 14: trillian.InitLogResponse
-	(*AddSequencedLeavesRequest)(nil),       // 15: trillian.AddSequencedLeavesRequest
-	(*AddSequencedLeavesResponse)(nil),      // 16: trillian.AddSequencedLeavesResponse
-	(*GetLeavesByRangeRequest)(nil),         // 17: trillian.GetLeavesByRangeRequest
-	(*GetLeavesByRangeResponse)(nil),        // 18: trillian.GetLeavesByRangeResponse
-	(*QueuedLogLeaf)(nil),                   // 19: trillian.QueuedLogLeaf
-	(*LogLeaf)(nil),                         // 20: trillian.LogLeaf
-	(*Proof)(nil),                           // 21: trillian.Proof
-	(*SignedLogRoot)(nil),                   // 22: trillian.SignedLogRoot
-	(*status.Status)(nil),                   // 23: google.rpc.Status
-	(*timestamppb.Timestamp)(nil),           // 24: google.protobuf.Timestamp
+	(*ChargeTo)(nil),                                // 0: trillian.ChargeTo
+	(*QueueLeafRequest)(nil),                        // 1: trillian.QueueLeafRequest
+	(*QueueLeafResponse)(nil),                       // 2: trillian.QueueLeafResponse
+	(*GetInclusionProofRequest)(nil),                // 3: trillian.GetInclusionProofRequest
+	(*GetInclusionProofResponse)(nil),               // 4: trillian.GetInclusionProofResponse
+	(*GetInclusionProofByHashRequest)(nil),          // 5: trillian.GetInclusionProofByHashRequest
+	(*GetInclusionProofByHashResponse)(nil),         // 6: trillian.GetInclusionProofByHashResponse
+	(*GetInclusionProofByIdentityHashRequest)(nil),  // 7: trillian.GetInclusionProofByIdentityHashRequest
+	(*GetInclusionProofByIdentityHashResponse)(nil), // 8: trillian.GetInclusionProofByIdentityHashResponse
+	(*GetConsistencyProofRequest)(nil),              // 9: trillian.GetConsistencyProofRequest
+	(*GetConsistencyProofResponse)(nil),             // 10: trillian.GetConsistencyProofResponse
+	(*GetConsistencyProofByRootHashRequest)(nil),    // 11: trillian.GetConsistencyProofByRootHashRequest
+	(*GetConsistencyProofByRootHashResponse)(nil),   // 12: trillian.GetConsistencyProofByRootHashResponse
+	(*GetRangeInclusionProofRequest)(nil),           // 13: trillian.GetRangeInclusionProofRequest
+	(*GetRangeInclusionProofResponse)(nil),          // 14: trillian.GetRangeInclusionProofResponse
+	(*GetLatestSignedLogRootRequest)(nil),           // 15: trillian.GetLatestSignedLogRootRequest
+	(*GetLatestSignedLogRootResponse)(nil),          // 16: trillian.GetLatestSignedLogRootResponse
+	(*ListSignedLogRootsRequest)(nil),               // 17: trillian.ListSignedLogRootsRequest
+	(*ListSignedLogRootsResponse)(nil),              // 18: trillian.ListSignedLogRootsResponse
+	(*WatchSignedLogRootsRequest)(nil),              // 19: trillian.WatchSignedLogRootsRequest
+	(*WatchSignedLogRootsResponse)(nil),             // 20: trillian.WatchSignedLogRootsResponse
+	(*GetTreeStatsRequest)(nil),                     // 21: trillian.GetTreeStatsRequest
+	(*GetTreeStatsResponse)(nil),                    // 22: trillian.GetTreeStatsResponse
+	(*GetSequencedLeafCountRequest)(nil),            // 23: trillian.GetSequencedLeafCountRequest
+	(*GetSequencedLeafCountResponse)(nil),           // 24: trillian.GetSequencedLeafCountResponse
+	(*GetEntryAndProofRequest)(nil),                 // 25: trillian.GetEntryAndProofRequest
+	(*GetEntryAndProofResponse)(nil),                // 26: trillian.GetEntryAndProofResponse
+	(*EntryAndProof)(nil),                           // 27: trillian.EntryAndProof
+	(*InitLogRequest)(nil),                          // 28: trillian.InitLogRequest
+	(*InitLogResponse)(nil),                         // 29: trillian.InitLogResponse
+	(*AddSequencedLeavesRequest)(nil),               // 30: trillian.AddSequencedLeavesRequest
+	(*AddSequencedLeavesResponse)(nil),              // 31: trillian.AddSequencedLeavesResponse
+	(*GetLeavesByRangeRequest)(nil),                 // 32: trillian.GetLeavesByRangeRequest
+	(*GetLeavesByRangeResponse)(nil),                // 33: trillian.GetLeavesByRangeResponse
+	(*GetLeavesByIndicesRequest)(nil),               // 34: trillian.GetLeavesByIndicesRequest
+	(*GetLeavesByIndicesResponse)(nil),              // 35: trillian.GetLeavesByIndicesResponse
+	(*UpdateLeafExtraDataRequest)(nil),              // 36: trillian.UpdateLeafExtraDataRequest
+	(*UpdateLeafExtraDataResponse)(nil),             // 37: trillian.UpdateLeafExtraDataResponse
+	(*RedactLeafRequest)(nil),                       // 38: trillian.RedactLeafRequest
+	(*RedactLeafResponse)(nil),                      // 39: trillian.RedactLeafResponse
+	(*QueuedLogLeaf)(nil),                           // 40: trillian.QueuedLogLeaf
+	(*LogLeaf)(nil),                                 // 41: trillian.LogLeaf
+	(*Proof)(nil),                                   // 42: trillian.Proof
+	(*SignedLogRoot)(nil),                           // 43: trillian.SignedLogRoot
+	(*timestamppb.Timestamp)(nil),                   // 44: google.protobuf.Timestamp
+	(*status.Status)(nil),                           // 45: google.rpc.Status
 }
 var file_trillian_log_api_proto_depIdxs = []int32{
-	20, // 0: trillian.QueueLeafRequest.leaf:type_name -> trillian.LogLeaf
+	41, // 0: trillian.QueueLeafRequest.leaf:type_name -> trillian.LogLeaf
 	0,  // 1: trillian.QueueLeafRequest.charge_to:type_name -> trillian.ChargeTo
-	19, // 2: trillian.QueueLeafResponse.queued_leaf:type_name -> trillian.QueuedLogLeaf
+	40, // 2: trillian.QueueLeafResponse.queued_leaf:type_name -> trillian.QueuedLogLeaf
 	0,  // 3: trillian.GetInclusionProofRequest.charge_to:type_name -> trillian.ChargeTo
-	21, // 4: trillian.GetInclusionProofResponse.proof:type_name -> trillian.Proof
-	22, // 5: trillian.GetInclusionProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	0,  // 6: trillian.GetInclusionProofByHashRequest.charge_to:type_name -> trillian.ChargeTo
-	21, // 7: trillian.GetInclusionProofByHashResponse.proof:type_name -> trillian.Proof
-	22, // 8: trillian.GetInclusionProofByHashResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	0,  // 9: trillian.GetConsistencyProofRequest.charge_to:type_name -> trillian.ChargeTo
-	21, // 10: trillian.GetConsistencyProofResponse.proof:type_name -> trillian.Proof
-	22, // 11: trillian.GetConsistencyProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	0,  // 12: trillian.GetLatestSignedLogRootRequest.charge_to:type_name -> trillian.ChargeTo
-	22, // 13: trillian.GetLatestSignedLogRootResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	21, // 14: trillian.GetLatestSignedLogRootResponse.proof:type_name -> trillian.Proof
-	0,  // 15: trillian.GetEntryAndProofRequest.charge_to:type_name -> trillian.ChargeTo
-	21, // 16: trillian.GetEntryAndProofResponse.proof:type_name -> trillian.Proof
-	20, // 17: trillian.GetEntryAndProofResponse.leaf:type_name -> trillian.LogLeaf
-	22, // 18: trillian.GetEntryAndProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	0,  // 19: trillian.InitLogRequest.charge_to:type_name -> trillian.ChargeTo
-	22, // 20: trillian.InitLogResponse.created:type_name -> trillian.SignedLogRoot
-	20, // 21: trillian.AddSequencedLeavesRequest.leaves:type_name -> trillian.LogLeaf
-	0,  // 22: trillian.AddSequencedLeavesRequest.charge_to:type_name -> trillian.ChargeTo
-	19, // 23: trillian.AddSequencedLeavesResponse.results:type_name -> trillian.QueuedLogLeaf
-	0,  // 24: trillian.GetLeavesByRangeRequest.charge_to:type_name -> trillian.ChargeTo
-	20, // 25: trillian.GetLeavesByRangeResponse.leaves:type_name -> trillian.LogLeaf
-	22, // 26: trillian.GetLeavesByRangeResponse.signed_log_root:type_name -> trillian.SignedLogRoot
-	20, // 27: trillian.QueuedLogLeaf.leaf:type_name -> trillian.LogLeaf
-	23, // 28: trillian.QueuedLogLeaf.status:type_name -> google.rpc.Status
-	24, // 29: trillian.LogLeaf.queue_timestamp:type_name -> google.protobuf.Timestamp
-	24, // 30: trillian.LogLeaf.integrate_timestamp:type_name -> google.protobuf.Timestamp
-	1,  // 31: trillian.TrillianLog.QueueLeaf:input_type -> trillian.QueueLeafRequest
-	3,  // 32: trillian.TrillianLog.GetInclusionProof:input_type -> trillian.GetInclusionProofRequest
-	5,  // 33: trillian.TrillianLog.GetInclusionProofByHash:input_type -> trillian.GetInclusionProofByHashRequest
-	7,  // 34: trillian.TrillianLog.GetConsistencyProof:input_type -> trillian.GetConsistencyProofRequest
-	9,  // 35: trillian.TrillianLog.GetLatestSignedLogRoot:input_type -> trillian.GetLatestSignedLogRootRequest
-	11, // 36: trillian.TrillianLog.GetEntryAndProof:input_type -> trillian.GetEntryAndProofRequest
-	13, // 37: trillian.TrillianLog.InitLog:input_type -> trillian.InitLogRequest
-	15, // 38: trillian.TrillianLog.AddSequencedLeaves:input_type -> trillian.AddSequencedLeavesRequest
-	17, // 39: trillian.TrillianLog.GetLeavesByRange:input_type -> trillian.GetLeavesByRangeRequest
-	2,  // 40: trillian.TrillianLog.QueueLeaf:output_type -> trillian.QueueLeafResponse
-	4,  // 41: trillian.TrillianLog.GetInclusionProof:output_type -> trillian.GetInclusionProofResponse
-	6,  // 42: trillian.TrillianLog.GetInclusionProofByHash:output_type -> trillian.GetInclusionProofByHashResponse
-	8,  // 43: trillian.TrillianLog.GetConsistencyProof:output_type -> trillian.GetConsistencyProofResponse
-	10, // 44: trillian.TrillianLog.GetLatestSignedLogRoot:output_type -> trillian.GetLatestSignedLogRootResponse
-	12, // 45: trillian.TrillianLog.GetEntryAndProof:output_type -> trillian.GetEntryAndProofResponse
-	14, // 46: trillian.TrillianLog.InitLog:output_type -> trillian.InitLogResponse
-	16, // 47: trillian.TrillianLog.AddSequencedLeaves:output_type -> trillian.AddSequencedLeavesResponse
-	18, // 48: trillian.TrillianLog.GetLeavesByRange:output_type -> trillian.GetLeavesByRangeResponse
-	40, // [40:49] is the sub-list for method output_type
-	31, // [31:40] is the sub-list for method input_type
-	31, // [31:31] is the sub-list for extension type_name
-	31, // [31:31] is the sub-list for extension extendee
-	0,  // [0:31] is the sub-list for field type_name
+	42, // 4: trillian.GetInclusionProofResponse.proof:type_name -> trillian.Proof
+	43, // 5: trillian.GetInclusionProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	43, // 6: trillian.GetInclusionProofResponse.proof_root:type_name -> trillian.SignedLogRoot
+	0,  // 7: trillian.GetInclusionProofByHashRequest.charge_to:type_name -> trillian.ChargeTo
+	42, // 8: trillian.GetInclusionProofByHashResponse.proof:type_name -> trillian.Proof
+	43, // 9: trillian.GetInclusionProofByHashResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 10: trillian.GetInclusionProofByIdentityHashRequest.charge_to:type_name -> trillian.ChargeTo
+	42, // 11: trillian.GetInclusionProofByIdentityHashResponse.proof:type_name -> trillian.Proof
+	43, // 12: trillian.GetInclusionProofByIdentityHashResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 13: trillian.GetConsistencyProofRequest.charge_to:type_name -> trillian.ChargeTo
+	42, // 14: trillian.GetConsistencyProofResponse.proof:type_name -> trillian.Proof
+	43, // 15: trillian.GetConsistencyProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 16: trillian.GetConsistencyProofByRootHashRequest.charge_to:type_name -> trillian.ChargeTo
+	42, // 17: trillian.GetConsistencyProofByRootHashResponse.proof:type_name -> trillian.Proof
+	43, // 18: trillian.GetConsistencyProofByRootHashResponse.first_root:type_name -> trillian.SignedLogRoot
+	43, // 19: trillian.GetConsistencyProofByRootHashResponse.second_root:type_name -> trillian.SignedLogRoot
+	43, // 20: trillian.GetConsistencyProofByRootHashResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 21: trillian.GetRangeInclusionProofRequest.charge_to:type_name -> trillian.ChargeTo
+	43, // 22: trillian.GetRangeInclusionProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 23: trillian.GetLatestSignedLogRootRequest.charge_to:type_name -> trillian.ChargeTo
+	43, // 24: trillian.GetLatestSignedLogRootResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	42, // 25: trillian.GetLatestSignedLogRootResponse.proof:type_name -> trillian.Proof
+	44, // 26: trillian.ListSignedLogRootsRequest.start_time:type_name -> google.protobuf.Timestamp
+	44, // 27: trillian.ListSignedLogRootsRequest.end_time:type_name -> google.protobuf.Timestamp
+	0,  // 28: trillian.ListSignedLogRootsRequest.charge_to:type_name -> trillian.ChargeTo
+	43, // 29: trillian.ListSignedLogRootsResponse.signed_log_roots:type_name -> trillian.SignedLogRoot
+	43, // 30: trillian.WatchSignedLogRootsResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	42, // 31: trillian.WatchSignedLogRootsResponse.proof:type_name -> trillian.Proof
+	0,  // 32: trillian.GetTreeStatsRequest.charge_to:type_name -> trillian.ChargeTo
+	44, // 33: trillian.GetTreeStatsResponse.root_timestamp:type_name -> google.protobuf.Timestamp
+	44, // 34: trillian.GetTreeStatsResponse.last_integration_timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 35: trillian.GetSequencedLeafCountRequest.charge_to:type_name -> trillian.ChargeTo
+	0,  // 36: trillian.GetEntryAndProofRequest.charge_to:type_name -> trillian.ChargeTo
+	42, // 37: trillian.GetEntryAndProofResponse.proof:type_name -> trillian.Proof
+	41, // 38: trillian.GetEntryAndProofResponse.leaf:type_name -> trillian.LogLeaf
+	43, // 39: trillian.GetEntryAndProofResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	43, // 40: trillian.GetEntryAndProofResponse.proof_root:type_name -> trillian.SignedLogRoot
+	27, // 41: trillian.GetEntryAndProofResponse.additional_entries:type_name -> trillian.EntryAndProof
+	41, // 42: trillian.EntryAndProof.leaf:type_name -> trillian.LogLeaf
+	42, // 43: trillian.EntryAndProof.proof:type_name -> trillian.Proof
+	0,  // 44: trillian.InitLogRequest.charge_to:type_name -> trillian.ChargeTo
+	43, // 45: trillian.InitLogResponse.created:type_name -> trillian.SignedLogRoot
+	41, // 46: trillian.AddSequencedLeavesRequest.leaves:type_name -> trillian.LogLeaf
+	0,  // 47: trillian.AddSequencedLeavesRequest.charge_to:type_name -> trillian.ChargeTo
+	0,  // 48: trillian.AddSequencedLeavesRequest.leaf_charge_to:type_name -> trillian.ChargeTo
+	40, // 49: trillian.AddSequencedLeavesResponse.results:type_name -> trillian.QueuedLogLeaf
+	0,  // 50: trillian.GetLeavesByRangeRequest.charge_to:type_name -> trillian.ChargeTo
+	41, // 51: trillian.GetLeavesByRangeResponse.leaves:type_name -> trillian.LogLeaf
+	43, // 52: trillian.GetLeavesByRangeResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 53: trillian.GetLeavesByIndicesRequest.charge_to:type_name -> trillian.ChargeTo
+	41, // 54: trillian.GetLeavesByIndicesResponse.leaves:type_name -> trillian.LogLeaf
+	43, // 55: trillian.GetLeavesByIndicesResponse.signed_log_root:type_name -> trillian.SignedLogRoot
+	0,  // 56: trillian.UpdateLeafExtraDataRequest.charge_to:type_name -> trillian.ChargeTo
+	41, // 57: trillian.UpdateLeafExtraDataResponse.leaf:type_name -> trillian.LogLeaf
+	0,  // 58: trillian.RedactLeafRequest.charge_to:type_name -> trillian.ChargeTo
+	41, // 59: trillian.RedactLeafResponse.leaf:type_name -> trillian.LogLeaf
+	41, // 60: trillian.QueuedLogLeaf.leaf:type_name -> trillian.LogLeaf
+	45, // 61: trillian.QueuedLogLeaf.status:type_name -> google.rpc.Status
+	44, // 62: trillian.LogLeaf.queue_timestamp:type_name -> google.protobuf.Timestamp
+	44, // 63: trillian.LogLeaf.integrate_timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 64: trillian.TrillianLog.QueueLeaf:input_type -> trillian.QueueLeafRequest
+	3,  // 65: trillian.TrillianLog.GetInclusionProof:input_type -> trillian.GetInclusionProofRequest
+	5,  // 66: trillian.TrillianLog.GetInclusionProofByHash:input_type -> trillian.GetInclusionProofByHashRequest
+	7,  // 67: trillian.TrillianLog.GetInclusionProofByIdentityHash:input_type -> trillian.GetInclusionProofByIdentityHashRequest
+	9,  // 68: trillian.TrillianLog.GetConsistencyProof:input_type -> trillian.GetConsistencyProofRequest
+	11, // 69: trillian.TrillianLog.GetConsistencyProofByRootHash:input_type -> trillian.GetConsistencyProofByRootHashRequest
+	13, // 70: trillian.TrillianLog.GetRangeInclusionProof:input_type -> trillian.GetRangeInclusionProofRequest
+	15, // 71: trillian.TrillianLog.GetLatestSignedLogRoot:input_type -> trillian.GetLatestSignedLogRootRequest
+	17, // 72: trillian.TrillianLog.ListSignedLogRoots:input_type -> trillian.ListSignedLogRootsRequest
+	21, // 73: trillian.TrillianLog.GetTreeStats:input_type -> trillian.GetTreeStatsRequest
+	23, // 74: trillian.TrillianLog.GetSequencedLeafCount:input_type -> trillian.GetSequencedLeafCountRequest
+	25, // 75: trillian.TrillianLog.GetEntryAndProof:input_type -> trillian.GetEntryAndProofRequest
+	28, // 76: trillian.TrillianLog.InitLog:input_type -> trillian.InitLogRequest
+	30, // 77: trillian.TrillianLog.AddSequencedLeaves:input_type -> trillian.AddSequencedLeavesRequest
+	32, // 78: trillian.TrillianLog.GetLeavesByRange:input_type -> trillian.GetLeavesByRangeRequest
+	34, // 79: trillian.TrillianLog.GetLeavesByIndices:input_type -> trillian.GetLeavesByIndicesRequest
+	19, // 80: trillian.TrillianLog.WatchSignedLogRoots:input_type -> trillian.WatchSignedLogRootsRequest
+	36, // 81: trillian.TrillianLog.UpdateLeafExtraData:input_type -> trillian.UpdateLeafExtraDataRequest
+	38, // 82: trillian.TrillianLog.RedactLeaf:input_type -> trillian.RedactLeafRequest
+	2,  // 83: trillian.TrillianLog.QueueLeaf:output_type -> trillian.QueueLeafResponse
+	4,  // 84: trillian.TrillianLog.GetInclusionProof:output_type -> trillian.GetInclusionProofResponse
+	6,  // 85: trillian.TrillianLog.GetInclusionProofByHash:output_type -> trillian.GetInclusionProofByHashResponse
+	8,  // 86: trillian.TrillianLog.GetInclusionProofByIdentityHash:output_type -> trillian.GetInclusionProofByIdentityHashResponse
+	10, // 87: trillian.TrillianLog.GetConsistencyProof:output_type -> trillian.GetConsistencyProofResponse
+	12, // 88: trillian.TrillianLog.GetConsistencyProofByRootHash:output_type -> trillian.GetConsistencyProofByRootHashResponse
+	14, // 89: trillian.TrillianLog.GetRangeInclusionProof:output_type -> trillian.GetRangeInclusionProofResponse
+	16, // 90: trillian.TrillianLog.GetLatestSignedLogRoot:output_type -> trillian.GetLatestSignedLogRootResponse
+	18, // 91: trillian.TrillianLog.ListSignedLogRoots:output_type -> trillian.ListSignedLogRootsResponse
+	22, // 92: trillian.TrillianLog.GetTreeStats:output_type -> trillian.GetTreeStatsResponse
+	24, // 93: trillian.TrillianLog.GetSequencedLeafCount:output_type -> trillian.GetSequencedLeafCountResponse
+	26, // 94: trillian.TrillianLog.GetEntryAndProof:output_type -> trillian.GetEntryAndProofResponse
+	29, // 95: trillian.TrillianLog.InitLog:output_type -> trillian.InitLogResponse
+	31, // 96: trillian.TrillianLog.AddSequencedLeaves:output_type -> trillian.AddSequencedLeavesResponse
+	33, // 97: trillian.TrillianLog.GetLeavesByRange:output_type -> trillian.GetLeavesByRangeResponse
+	35, // 98: trillian.TrillianLog.GetLeavesByIndices:output_type -> trillian.GetLeavesByIndicesResponse
+	20, // 99: trillian.TrillianLog.WatchSignedLogRoots:output_type -> trillian.WatchSignedLogRootsResponse
+	37, // 100: trillian.TrillianLog.UpdateLeafExtraData:output_type -> trillian.UpdateLeafExtraDataResponse
+	39, // 101: trillian.TrillianLog.RedactLeaf:output_type -> trillian.RedactLeafResponse
+	83, // [83:102] is the sub-list for method output_type
+	64, // [64:83] is the sub-list for method input_type
+	64, // [64:64] is the sub-list for extension type_name
+	64, // [64:64] is the sub-list for extension extendee
+	0,  // [0:64] is the sub-list for field type_name
 }
 
 func init() { file_trillian_log_api_proto_init() }
@@ -1586,7 +3358,7 @@ func file_trillian_log_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trillian_log_api_proto_rawDesc), len(file_trillian_log_api_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   21,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -33,15 +33,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TrillianLog_QueueLeaf_FullMethodName               = "/trillian.TrillianLog/QueueLeaf"
-	TrillianLog_GetInclusionProof_FullMethodName       = "/trillian.TrillianLog/GetInclusionProof"
-	TrillianLog_GetInclusionProofByHash_FullMethodName = "/trillian.TrillianLog/GetInclusionProofByHash"
-	TrillianLog_GetConsistencyProof_FullMethodName     = "/trillian.TrillianLog/GetConsistencyProof"
-	TrillianLog_GetLatestSignedLogRoot_FullMethodName  = "/trillian.TrillianLog/GetLatestSignedLogRoot"
-	TrillianLog_GetEntryAndProof_FullMethodName        = "/trillian.TrillianLog/GetEntryAndProof"
-	TrillianLog_InitLog_FullMethodName                 = "/trillian.TrillianLog/InitLog"
-	TrillianLog_AddSequencedLeaves_FullMethodName      = "/trillian.TrillianLog/AddSequencedLeaves"
-	TrillianLog_GetLeavesByRange_FullMethodName        = "/trillian.TrillianLog/GetLeavesByRange"
+	TrillianLog_QueueLeaf_FullMethodName                       = "/trillian.TrillianLog/QueueLeaf"
+	TrillianLog_GetInclusionProof_FullMethodName               = "/trillian.TrillianLog/GetInclusionProof"
+	TrillianLog_GetInclusionProofByHash_FullMethodName         = "/trillian.TrillianLog/GetInclusionProofByHash"
+	TrillianLog_GetInclusionProofByIdentityHash_FullMethodName = "/trillian.TrillianLog/GetInclusionProofByIdentityHash"
+	TrillianLog_GetConsistencyProof_FullMethodName             = "/trillian.TrillianLog/GetConsistencyProof"
+	TrillianLog_GetConsistencyProofByRootHash_FullMethodName   = "/trillian.TrillianLog/GetConsistencyProofByRootHash"
+	TrillianLog_GetRangeInclusionProof_FullMethodName          = "/trillian.TrillianLog/GetRangeInclusionProof"
+	TrillianLog_GetLatestSignedLogRoot_FullMethodName          = "/trillian.TrillianLog/GetLatestSignedLogRoot"
+	TrillianLog_ListSignedLogRoots_FullMethodName              = "/trillian.TrillianLog/ListSignedLogRoots"
+	TrillianLog_GetTreeStats_FullMethodName                    = "/trillian.TrillianLog/GetTreeStats"
+	TrillianLog_GetSequencedLeafCount_FullMethodName           = "/trillian.TrillianLog/GetSequencedLeafCount"
+	TrillianLog_GetEntryAndProof_FullMethodName                = "/trillian.TrillianLog/GetEntryAndProof"
+	TrillianLog_InitLog_FullMethodName                         = "/trillian.TrillianLog/InitLog"
+	TrillianLog_AddSequencedLeaves_FullMethodName              = "/trillian.TrillianLog/AddSequencedLeaves"
+	TrillianLog_GetLeavesByRange_FullMethodName                = "/trillian.TrillianLog/GetLeavesByRange"
+	TrillianLog_GetLeavesByIndices_FullMethodName              = "/trillian.TrillianLog/GetLeavesByIndices"
+	TrillianLog_WatchSignedLogRoots_FullMethodName             = "/trillian.TrillianLog/WatchSignedLogRoots"
+	TrillianLog_UpdateLeafExtraData_FullMethodName             = "/trillian.TrillianLog/UpdateLeafExtraData"
+	TrillianLog_RedactLeaf_FullMethodName                      = "/trillian.TrillianLog/RedactLeaf"
 )
 
 // TrillianLogClient is the client API for TrillianLog service.
@@ -92,12 +102,34 @@ type TrillianLogClient interface {
 	// If any of the leaves that match the given Merkle has have a leaf index that
 	// is beyond the requested tree size, the corresponding proof entry will be empty.
 	GetInclusionProofByHash(ctx context.Context, in *GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*GetInclusionProofByHashResponse, error)
+	// GetInclusionProofByIdentityHash returns an inclusion proof for any
+	// integrated leaves that have the given leaf identity hash in a particular
+	// tree.
+	//
+	// Leaves with an index beyond the requested tree size are skipped. Not all
+	// storage implementations support this method.
+	GetInclusionProofByIdentityHash(ctx context.Context, in *GetInclusionProofByIdentityHashRequest, opts ...grpc.CallOption) (*GetInclusionProofByIdentityHashResponse, error)
 	// GetConsistencyProof returns a consistency proof between different sizes of
 	// a particular tree.
 	//
 	// If the requested tree size is larger than the server is aware of,
 	// the response will include the latest known log root and an empty proof.
 	GetConsistencyProof(ctx context.Context, in *GetConsistencyProofRequest, opts ...grpc.CallOption) (*GetConsistencyProofResponse, error)
+	// GetConsistencyProofByRootHash returns a consistency proof between two
+	// roots of a particular tree, identified by their root hashes rather than
+	// their sizes. The sizes are resolved from the roots stored by the log, so
+	// the roots must have been published by it.
+	//
+	// Not all storage implementations support this method.
+	GetConsistencyProofByRootHash(ctx context.Context, in *GetConsistencyProofByRootHashRequest, opts ...grpc.CallOption) (*GetConsistencyProofByRootHashResponse, error)
+	// GetRangeInclusionProof returns a proof that the contiguous range of leaves
+	// [start_index, end_index) is included in a particular tree size. The proof
+	// consists of the compact ranges to the left and right of the range, from
+	// which the root hash can be computed given the leaf hashes of the range.
+	//
+	// If the requested tree size is larger than the server is aware of, the
+	// response will include the latest known log root and an empty proof.
+	GetRangeInclusionProof(ctx context.Context, in *GetRangeInclusionProofRequest, opts ...grpc.CallOption) (*GetRangeInclusionProofResponse, error)
 	// GetLatestSignedLogRoot returns the latest log root for a given tree,
 	// and optionally also includes a consistency proof from an earlier tree size
 	// to the new size of the tree.
@@ -105,12 +137,33 @@ type TrillianLogClient interface {
 	// If the earlier tree size is larger than the server is aware of,
 	// an InvalidArgument error is returned.
 	GetLatestSignedLogRoot(ctx context.Context, in *GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*GetLatestSignedLogRootResponse, error)
+	// ListSignedLogRoots returns the log roots of a tree stored by the log, in
+	// increasing order of their timestamps, optionally restricted to a range of
+	// timestamps or tree sizes. Results are paginated.
+	//
+	// Not all storage implementations support this method.
+	ListSignedLogRoots(ctx context.Context, in *ListSignedLogRootsRequest, opts ...grpc.CallOption) (*ListSignedLogRootsResponse, error)
+	// GetTreeStats returns cheap freshness data about a tree, such as its size
+	// and the time of its latest root, without the need to parse a log root.
+	// It is intended for dashboards and routers which track many trees.
+	GetTreeStats(ctx context.Context, in *GetTreeStatsRequest, opts ...grpc.CallOption) (*GetTreeStatsResponse, error)
+	// GetSequencedLeafCount returns the size of a tree along with the lowest
+	// and highest indices at which leaves are stored, which for pre-ordered
+	// logs may be beyond the size of the tree, so that mirrors can track how
+	// far they have been filled. The indices are found with index lookups
+	// rather than by counting the leaves.
+	//
+	// An Unimplemented error is returned if the storage can't find them.
+	GetSequencedLeafCount(ctx context.Context, in *GetSequencedLeafCountRequest, opts ...grpc.CallOption) (*GetSequencedLeafCountResponse, error)
 	// GetEntryAndProof returns a log leaf and the corresponding inclusion proof
 	// to a specified tree size, for a given leaf index in a particular tree.
+	// Further leaves may be requested with their proofs to the same tree size,
+	// so that a window of entries can be verified against one root.
 	//
 	// If the requested tree size is unavailable but the leaf is
 	// in scope for the current tree, the returned proof will be for the
-	// current tree size rather than the requested tree size.
+	// current tree size rather than the requested tree size, unless
+	// pin_tree_size is set, in which case OUT_OF_RANGE is returned.
 	GetEntryAndProof(ctx context.Context, in *GetEntryAndProofRequest, opts ...grpc.CallOption) (*GetEntryAndProofResponse, error)
 	// InitLog initializes a particular tree, creating the initial signed log
 	// root (which will be of size 0).
@@ -121,6 +174,39 @@ type TrillianLogClient interface {
 	// GetLeavesByRange returns a batch of leaves whose leaf indices are in a
 	// sequential range.
 	GetLeavesByRange(ctx context.Context, in *GetLeavesByRangeRequest, opts ...grpc.CallOption) (*GetLeavesByRangeResponse, error)
+	// GetLeavesByIndices returns a batch of leaves with arbitrary, not
+	// necessarily contiguous, leaf indices, e.g. for monitors which sample
+	// random entries.
+	GetLeavesByIndices(ctx context.Context, in *GetLeavesByIndicesRequest, opts ...grpc.CallOption) (*GetLeavesByIndicesResponse, error)
+	// WatchSignedLogRoots streams log roots for a given tree as they are
+	// published, so that monitors don't need to poll GetLatestSignedLogRoot.
+	// The current root is sent immediately, followed by each new root that the
+	// server becomes aware of. Each response optionally includes a consistency
+	// proof from the previously sent tree size (or from first_tree_size for the
+	// first response).
+	//
+	// If first_tree_size is larger than the server is aware of, no roots are
+	// sent until the tree has grown to at least that size.
+	WatchSignedLogRoots(ctx context.Context, in *WatchSignedLogRootsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchSignedLogRootsResponse], error)
+	// UpdateLeafExtraData replaces the extra_data of an integrated leaf. The
+	// leaf value, and so the Merkle tree, is unchanged. The previous extra_data
+	// is kept in an audit trail in storage, along with the reason for the
+	// change.
+	//
+	// Only permitted for trees with mutable_extra_data set. Trillian does not
+	// authenticate callers, so access to this method should be restricted in
+	// front of the log server.
+	UpdateLeafExtraData(ctx context.Context, in *UpdateLeafExtraDataRequest, opts ...grpc.CallOption) (*UpdateLeafExtraDataResponse, error)
+	// RedactLeaf replaces the leaf_value of an integrated leaf with an empty
+	// tombstone, for example to comply with a takedown request. The Merkle leaf
+	// hash is kept, so the tree and its proofs are unchanged, and reads of the
+	// leaf return it with redacted set. The redaction is recorded in an audit
+	// trail in storage, along with its reason.
+	//
+	// Only permitted for trees with allow_redaction set. Trillian does not
+	// authenticate callers, so access to this method should be restricted in
+	// front of the log server.
+	RedactLeaf(ctx context.Context, in *RedactLeafRequest, opts ...grpc.CallOption) (*RedactLeafResponse, error)
 }
 
 type trillianLogClient struct {
@@ -161,6 +247,16 @@ func (c *trillianLogClient) GetInclusionProofByHash(ctx context.Context, in *Get
 	return out, nil
 }
 
+func (c *trillianLogClient) GetInclusionProofByIdentityHash(ctx context.Context, in *GetInclusionProofByIdentityHashRequest, opts ...grpc.CallOption) (*GetInclusionProofByIdentityHashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInclusionProofByIdentityHashResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetInclusionProofByIdentityHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *trillianLogClient) GetConsistencyProof(ctx context.Context, in *GetConsistencyProofRequest, opts ...grpc.CallOption) (*GetConsistencyProofResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetConsistencyProofResponse)
@@ -171,6 +267,26 @@ func (c *trillianLogClient) GetConsistencyProof(ctx context.Context, in *GetCons
 	return out, nil
 }
 
+func (c *trillianLogClient) GetConsistencyProofByRootHash(ctx context.Context, in *GetConsistencyProofByRootHashRequest, opts ...grpc.CallOption) (*GetConsistencyProofByRootHashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConsistencyProofByRootHashResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetConsistencyProofByRootHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianLogClient) GetRangeInclusionProof(ctx context.Context, in *GetRangeInclusionProofRequest, opts ...grpc.CallOption) (*GetRangeInclusionProofResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRangeInclusionProofResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetRangeInclusionProof_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *trillianLogClient) GetLatestSignedLogRoot(ctx context.Context, in *GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*GetLatestSignedLogRootResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetLatestSignedLogRootResponse)
@@ -181,6 +297,36 @@ func (c *trillianLogClient) GetLatestSignedLogRoot(ctx context.Context, in *GetL
 	return out, nil
 }
 
+func (c *trillianLogClient) ListSignedLogRoots(ctx context.Context, in *ListSignedLogRootsRequest, opts ...grpc.CallOption) (*ListSignedLogRootsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSignedLogRootsResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_ListSignedLogRoots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianLogClient) GetTreeStats(ctx context.Context, in *GetTreeStatsRequest, opts ...grpc.CallOption) (*GetTreeStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTreeStatsResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetTreeStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianLogClient) GetSequencedLeafCount(ctx context.Context, in *GetSequencedLeafCountRequest, opts ...grpc.CallOption) (*GetSequencedLeafCountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSequencedLeafCountResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetSequencedLeafCount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *trillianLogClient) GetEntryAndProof(ctx context.Context, in *GetEntryAndProofRequest, opts ...grpc.CallOption) (*GetEntryAndProofResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetEntryAndProofResponse)
@@ -221,6 +367,55 @@ func (c *trillianLogClient) GetLeavesByRange(ctx context.Context, in *GetLeavesB
 	return out, nil
 }
 
+func (c *trillianLogClient) GetLeavesByIndices(ctx context.Context, in *GetLeavesByIndicesRequest, opts ...grpc.CallOption) (*GetLeavesByIndicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLeavesByIndicesResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_GetLeavesByIndices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianLogClient) WatchSignedLogRoots(ctx context.Context, in *WatchSignedLogRootsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchSignedLogRootsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TrillianLog_ServiceDesc.Streams[0], TrillianLog_WatchSignedLogRoots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSignedLogRootsRequest, WatchSignedLogRootsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrillianLog_WatchSignedLogRootsClient = grpc.ServerStreamingClient[WatchSignedLogRootsResponse]
+
+func (c *trillianLogClient) UpdateLeafExtraData(ctx context.Context, in *UpdateLeafExtraDataRequest, opts ...grpc.CallOption) (*UpdateLeafExtraDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLeafExtraDataResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_UpdateLeafExtraData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianLogClient) RedactLeaf(ctx context.Context, in *RedactLeafRequest, opts ...grpc.CallOption) (*RedactLeafResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedactLeafResponse)
+	err := c.cc.Invoke(ctx, TrillianLog_RedactLeaf_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianLogServer is the server API for TrillianLog service.
 // All implementations should embed UnimplementedTrillianLogServer
 // for forward compatibility.
@@ -269,12 +464,34 @@ type TrillianLogServer interface {
 	// If any of the leaves that match the given Merkle has have a leaf index that
 	// is beyond the requested tree size, the corresponding proof entry will be empty.
 	GetInclusionProofByHash(context.Context, *GetInclusionProofByHashRequest) (*GetInclusionProofByHashResponse, error)
+	// GetInclusionProofByIdentityHash returns an inclusion proof for any
+	// integrated leaves that have the given leaf identity hash in a particular
+	// tree.
+	//
+	// Leaves with an index beyond the requested tree size are skipped. Not all
+	// storage implementations support this method.
+	GetInclusionProofByIdentityHash(context.Context, *GetInclusionProofByIdentityHashRequest) (*GetInclusionProofByIdentityHashResponse, error)
 	// GetConsistencyProof returns a consistency proof between different sizes of
 	// a particular tree.
 	//
 	// If the requested tree size is larger than the server is aware of,
 	// the response will include the latest known log root and an empty proof.
 	GetConsistencyProof(context.Context, *GetConsistencyProofRequest) (*GetConsistencyProofResponse, error)
+	// GetConsistencyProofByRootHash returns a consistency proof between two
+	// roots of a particular tree, identified by their root hashes rather than
+	// their sizes. The sizes are resolved from the roots stored by the log, so
+	// the roots must have been published by it.
+	//
+	// Not all storage implementations support this method.
+	GetConsistencyProofByRootHash(context.Context, *GetConsistencyProofByRootHashRequest) (*GetConsistencyProofByRootHashResponse, error)
+	// GetRangeInclusionProof returns a proof that the contiguous range of leaves
+	// [start_index, end_index) is included in a particular tree size. The proof
+	// consists of the compact ranges to the left and right of the range, from
+	// which the root hash can be computed given the leaf hashes of the range.
+	//
+	// If the requested tree size is larger than the server is aware of, the
+	// response will include the latest known log root and an empty proof.
+	GetRangeInclusionProof(context.Context, *GetRangeInclusionProofRequest) (*GetRangeInclusionProofResponse, error)
 	// GetLatestSignedLogRoot returns the latest log root for a given tree,
 	// and optionally also includes a consistency proof from an earlier tree size
 	// to the new size of the tree.
@@ -282,12 +499,33 @@ type TrillianLogServer interface {
 	// If the earlier tree size is larger than the server is aware of,
 	// an InvalidArgument error is returned.
 	GetLatestSignedLogRoot(context.Context, *GetLatestSignedLogRootRequest) (*GetLatestSignedLogRootResponse, error)
+	// ListSignedLogRoots returns the log roots of a tree stored by the log, in
+	// increasing order of their timestamps, optionally restricted to a range of
+	// timestamps or tree sizes. Results are paginated.
+	//
+	// Not all storage implementations support this method.
+	ListSignedLogRoots(context.Context, *ListSignedLogRootsRequest) (*ListSignedLogRootsResponse, error)
+	// GetTreeStats returns cheap freshness data about a tree, such as its size
+	// and the time of its latest root, without the need to parse a log root.
+	// It is intended for dashboards and routers which track many trees.
+	GetTreeStats(context.Context, *GetTreeStatsRequest) (*GetTreeStatsResponse, error)
+	// GetSequencedLeafCount returns the size of a tree along with the lowest
+	// and highest indices at which leaves are stored, which for pre-ordered
+	// logs may be beyond the size of the tree, so that mirrors can track how
+	// far they have been filled. The indices are found with index lookups
+	// rather than by counting the leaves.
+	//
+	// An Unimplemented error is returned if the storage can't find them.
+	GetSequencedLeafCount(context.Context, *GetSequencedLeafCountRequest) (*GetSequencedLeafCountResponse, error)
 	// GetEntryAndProof returns a log leaf and the corresponding inclusion proof
 	// to a specified tree size, for a given leaf index in a particular tree.
+	// Further leaves may be requested with their proofs to the same tree size,
+	// so that a window of entries can be verified against one root.
 	//
 	// If the requested tree size is unavailable but the leaf is
 	// in scope for the current tree, the returned proof will be for the
-	// current tree size rather than the requested tree size.
+	// current tree size rather than the requested tree size, unless
+	// pin_tree_size is set, in which case OUT_OF_RANGE is returned.
 	GetEntryAndProof(context.Context, *GetEntryAndProofRequest) (*GetEntryAndProofResponse, error)
 	// InitLog initializes a particular tree, creating the initial signed log
 	// root (which will be of size 0).
@@ -298,6 +536,39 @@ type TrillianLogServer interface {
 	// GetLeavesByRange returns a batch of leaves whose leaf indices are in a
 	// sequential range.
 	GetLeavesByRange(context.Context, *GetLeavesByRangeRequest) (*GetLeavesByRangeResponse, error)
+	// GetLeavesByIndices returns a batch of leaves with arbitrary, not
+	// necessarily contiguous, leaf indices, e.g. for monitors which sample
+	// random entries.
+	GetLeavesByIndices(context.Context, *GetLeavesByIndicesRequest) (*GetLeavesByIndicesResponse, error)
+	// WatchSignedLogRoots streams log roots for a given tree as they are
+	// published, so that monitors don't need to poll GetLatestSignedLogRoot.
+	// The current root is sent immediately, followed by each new root that the
+	// server becomes aware of. Each response optionally includes a consistency
+	// proof from the previously sent tree size (or from first_tree_size for the
+	// first response).
+	//
+	// If first_tree_size is larger than the server is aware of, no roots are
+	// sent until the tree has grown to at least that size.
+	WatchSignedLogRoots(*WatchSignedLogRootsRequest, grpc.ServerStreamingServer[WatchSignedLogRootsResponse]) error
+	// UpdateLeafExtraData replaces the extra_data of an integrated leaf. The
+	// leaf value, and so the Merkle tree, is unchanged. The previous extra_data
+	// is kept in an audit trail in storage, along with the reason for the
+	// change.
+	//
+	// Only permitted for trees with mutable_extra_data set. Trillian does not
+	// authenticate callers, so access to this method should be restricted in
+	// front of the log server.
+	UpdateLeafExtraData(context.Context, *UpdateLeafExtraDataRequest) (*UpdateLeafExtraDataResponse, error)
+	// RedactLeaf replaces the leaf_value of an integrated leaf with an empty
+	// tombstone, for example to comply with a takedown request. The Merkle leaf
+	// hash is kept, so the tree and its proofs are unchanged, and reads of the
+	// leaf return it with redacted set. The redaction is recorded in an audit
+	// trail in storage, along with its reason.
+	//
+	// Only permitted for trees with allow_redaction set. Trillian does not
+	// authenticate callers, so access to this method should be restricted in
+	// front of the log server.
+	RedactLeaf(context.Context, *RedactLeafRequest) (*RedactLeafResponse, error)
 }
 
 // UnimplementedTrillianLogServer should be embedded to have
@@ -316,12 +587,30 @@ func (UnimplementedTrillianLogServer) GetInclusionProof(context.Context, *GetInc
 func (UnimplementedTrillianLogServer) GetInclusionProofByHash(context.Context, *GetInclusionProofByHashRequest) (*GetInclusionProofByHashResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetInclusionProofByHash not implemented")
 }
+func (UnimplementedTrillianLogServer) GetInclusionProofByIdentityHash(context.Context, *GetInclusionProofByIdentityHashRequest) (*GetInclusionProofByIdentityHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInclusionProofByIdentityHash not implemented")
+}
 func (UnimplementedTrillianLogServer) GetConsistencyProof(context.Context, *GetConsistencyProofRequest) (*GetConsistencyProofResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConsistencyProof not implemented")
 }
+func (UnimplementedTrillianLogServer) GetConsistencyProofByRootHash(context.Context, *GetConsistencyProofByRootHashRequest) (*GetConsistencyProofByRootHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsistencyProofByRootHash not implemented")
+}
+func (UnimplementedTrillianLogServer) GetRangeInclusionProof(context.Context, *GetRangeInclusionProofRequest) (*GetRangeInclusionProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRangeInclusionProof not implemented")
+}
 func (UnimplementedTrillianLogServer) GetLatestSignedLogRoot(context.Context, *GetLatestSignedLogRootRequest) (*GetLatestSignedLogRootResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestSignedLogRoot not implemented")
 }
+func (UnimplementedTrillianLogServer) ListSignedLogRoots(context.Context, *ListSignedLogRootsRequest) (*ListSignedLogRootsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSignedLogRoots not implemented")
+}
+func (UnimplementedTrillianLogServer) GetTreeStats(context.Context, *GetTreeStatsRequest) (*GetTreeStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTreeStats not implemented")
+}
+func (UnimplementedTrillianLogServer) GetSequencedLeafCount(context.Context, *GetSequencedLeafCountRequest) (*GetSequencedLeafCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSequencedLeafCount not implemented")
+}
 func (UnimplementedTrillianLogServer) GetEntryAndProof(context.Context, *GetEntryAndProofRequest) (*GetEntryAndProofResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEntryAndProof not implemented")
 }
@@ -334,6 +623,18 @@ func (UnimplementedTrillianLogServer) AddSequencedLeaves(context.Context, *AddSe
 func (UnimplementedTrillianLogServer) GetLeavesByRange(context.Context, *GetLeavesByRangeRequest) (*GetLeavesByRangeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesByRange not implemented")
 }
+func (UnimplementedTrillianLogServer) GetLeavesByIndices(context.Context, *GetLeavesByIndicesRequest) (*GetLeavesByIndicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesByIndices not implemented")
+}
+func (UnimplementedTrillianLogServer) WatchSignedLogRoots(*WatchSignedLogRootsRequest, grpc.ServerStreamingServer[WatchSignedLogRootsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSignedLogRoots not implemented")
+}
+func (UnimplementedTrillianLogServer) UpdateLeafExtraData(context.Context, *UpdateLeafExtraDataRequest) (*UpdateLeafExtraDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLeafExtraData not implemented")
+}
+func (UnimplementedTrillianLogServer) RedactLeaf(context.Context, *RedactLeafRequest) (*RedactLeafResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedactLeaf not implemented")
+}
 func (UnimplementedTrillianLogServer) testEmbeddedByValue() {}
 
 // UnsafeTrillianLogServer may be embedded to opt out of forward compatibility for this service.
@@ -408,6 +709,24 @@ func _TrillianLog_GetInclusionProofByHash_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianLog_GetInclusionProofByIdentityHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInclusionProofByIdentityHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetInclusionProofByIdentityHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetInclusionProofByIdentityHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetInclusionProofByIdentityHash(ctx, req.(*GetInclusionProofByIdentityHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TrillianLog_GetConsistencyProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetConsistencyProofRequest)
 	if err := dec(in); err != nil {
@@ -426,6 +745,42 @@ func _TrillianLog_GetConsistencyProof_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianLog_GetConsistencyProofByRootHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsistencyProofByRootHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetConsistencyProofByRootHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetConsistencyProofByRootHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetConsistencyProofByRootHash(ctx, req.(*GetConsistencyProofByRootHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianLog_GetRangeInclusionProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRangeInclusionProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetRangeInclusionProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetRangeInclusionProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetRangeInclusionProof(ctx, req.(*GetRangeInclusionProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TrillianLog_GetLatestSignedLogRoot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetLatestSignedLogRootRequest)
 	if err := dec(in); err != nil {
@@ -444,6 +799,60 @@ func _TrillianLog_GetLatestSignedLogRoot_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianLog_ListSignedLogRoots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSignedLogRootsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).ListSignedLogRoots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_ListSignedLogRoots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).ListSignedLogRoots(ctx, req.(*ListSignedLogRootsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianLog_GetTreeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTreeStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetTreeStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetTreeStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetTreeStats(ctx, req.(*GetTreeStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianLog_GetSequencedLeafCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSequencedLeafCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetSequencedLeafCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetSequencedLeafCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetSequencedLeafCount(ctx, req.(*GetSequencedLeafCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TrillianLog_GetEntryAndProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetEntryAndProofRequest)
 	if err := dec(in); err != nil {
@@ -516,6 +925,71 @@ func _TrillianLog_GetLeavesByRange_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianLog_GetLeavesByIndices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeavesByIndicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).GetLeavesByIndices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_GetLeavesByIndices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).GetLeavesByIndices(ctx, req.(*GetLeavesByIndicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianLog_WatchSignedLogRoots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSignedLogRootsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrillianLogServer).WatchSignedLogRoots(m, &grpc.GenericServerStream[WatchSignedLogRootsRequest, WatchSignedLogRootsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrillianLog_WatchSignedLogRootsServer = grpc.ServerStreamingServer[WatchSignedLogRootsResponse]
+
+func _TrillianLog_UpdateLeafExtraData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLeafExtraDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).UpdateLeafExtraData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_UpdateLeafExtraData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).UpdateLeafExtraData(ctx, req.(*UpdateLeafExtraDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianLog_RedactLeaf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedactLeafRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianLogServer).RedactLeaf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrillianLog_RedactLeaf_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianLogServer).RedactLeaf(ctx, req.(*RedactLeafRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TrillianLog_ServiceDesc is the grpc.ServiceDesc for TrillianLog service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -535,14 +1009,38 @@ var TrillianLog_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetInclusionProofByHash",
 			Handler:    _TrillianLog_GetInclusionProofByHash_Handler,
 		},
+		{
+			MethodName: "GetInclusionProofByIdentityHash",
+			Handler:    _TrillianLog_GetInclusionProofByIdentityHash_Handler,
+		},
 		{
 			MethodName: "GetConsistencyProof",
 			Handler:    _TrillianLog_GetConsistencyProof_Handler,
 		},
+		{
+			MethodName: "GetConsistencyProofByRootHash",
+			Handler:    _TrillianLog_GetConsistencyProofByRootHash_Handler,
+		},
+		{
+			MethodName: "GetRangeInclusionProof",
+			Handler:    _TrillianLog_GetRangeInclusionProof_Handler,
+		},
 		{
 			MethodName: "GetLatestSignedLogRoot",
 			Handler:    _TrillianLog_GetLatestSignedLogRoot_Handler,
 		},
+		{
+			MethodName: "ListSignedLogRoots",
+			Handler:    _TrillianLog_ListSignedLogRoots_Handler,
+		},
+		{
+			MethodName: "GetTreeStats",
+			Handler:    _TrillianLog_GetTreeStats_Handler,
+		},
+		{
+			MethodName: "GetSequencedLeafCount",
+			Handler:    _TrillianLog_GetSequencedLeafCount_Handler,
+		},
 		{
 			MethodName: "GetEntryAndProof",
 			Handler:    _TrillianLog_GetEntryAndProof_Handler,
@@ -559,7 +1057,25 @@ var TrillianLog_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLeavesByRange",
 			Handler:    _TrillianLog_GetLeavesByRange_Handler,
 		},
+		{
+			MethodName: "GetLeavesByIndices",
+			Handler:    _TrillianLog_GetLeavesByIndices_Handler,
+		},
+		{
+			MethodName: "UpdateLeafExtraData",
+			Handler:    _TrillianLog_UpdateLeafExtraData_Handler,
+		},
+		{
+			MethodName: "RedactLeaf",
+			Handler:    _TrillianLog_RedactLeaf_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSignedLogRoots",
+			Handler:       _TrillianLog_WatchSignedLogRoots_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_log_api.proto",
 }
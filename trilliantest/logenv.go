@@ -0,0 +1,253 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trilliantest provides a hermetic, in-process Trillian log
+// environment for use in tests of personalities built on top of Trillian.
+//
+// A LogEnv bundles a gRPC server hosting both the TrillianLog and
+// TrillianAdmin services, a sequencer, and in-memory storage. Nothing is
+// persisted and no external services are required, so tests using it can run
+// anywhere `go test` runs.
+//
+// Unlike the testonly packages, the API of this package is supported and
+// follows the usual compatibility guarantees.
+package trilliantest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/admin"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultBatchSize is the sequencer batch size used if Config.BatchSize is
+	// unset.
+	DefaultBatchSize = 50
+	// DefaultSequencerInterval is the time between sequencer passes used if
+	// Config.SequencerInterval is unset.
+	DefaultSequencerInterval = 100 * time.Millisecond
+)
+
+// Config holds the parameters of a LogEnv. The zero value is a usable
+// configuration which runs a single background sequencer.
+type Config struct {
+	// NumSequencers is the number of sequencer workers to run in parallel.
+	// If zero, one worker is used.
+	NumSequencers int
+	// ManualSequencing disables the background sequencer. Tests must call
+	// LogEnv.Sequence to integrate queued leaves.
+	ManualSequencing bool
+	// SequencerInterval is the time between background sequencer passes.
+	SequencerInterval time.Duration
+	// SequencerGuardWindow is the time elapsed before submitted leaves are
+	// eligible for sequencing.
+	SequencerGuardWindow time.Duration
+	// BatchSize is the maximum number of leaves integrated per pass.
+	BatchSize int
+	// TimeSource is used by the server, the sequencer and storage, so that
+	// tests can control queue, integration, root and tree timestamps.
+	// Defaults to clock.System.
+	TimeSource clock.TimeSource
+	// QuotaManager is used by the server and sequencer. Defaults to
+	// quota.Noop().
+	QuotaManager quota.Manager
+	// AdminStorage and LogStorage, if set, are used instead of fresh
+	// in-memory storage, e.g. to run the environment against a database.
+	// Either both or neither must be set. TimeSource only applies to the
+	// server and the sequencer then.
+	AdminStorage storage.AdminStorage
+	LogStorage   storage.LogStorage
+
+	// ServerOptions are additional options passed to grpc.NewServer.
+	ServerOptions []grpc.ServerOption
+	// DialOptions are additional options used to connect to the server. If
+	// none are provided an insecure connection is used.
+	DialOptions []grpc.DialOption
+}
+
+// LogEnv is a self-contained Trillian log environment: a log server, an admin
+// server and a sequencer sharing in-memory storage, plus clients connected to
+// them over a local gRPC connection.
+type LogEnv struct {
+	// Address is the localhost:port the gRPC server is listening on.
+	Address string
+	// Log is a client for the TrillianLog service.
+	Log trillian.TrillianLogClient
+	// Admin is a client for the TrillianAdmin service.
+	Admin trillian.TrillianAdminClient
+	// Registry holds the storage and quota implementations used by the
+	// environment, for tests which need to inspect them directly.
+	Registry extension.Registry
+
+	conn       *grpc.ClientConn
+	grpcServer *grpc.Server
+	sequencer  *log.OperationManager
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewLogEnv starts a new LogEnv configured by cfg. Callers must call Close
+// when they are finished with it.
+func NewLogEnv(ctx context.Context, cfg Config) (*LogEnv, error) {
+	if cfg.NumSequencers <= 0 {
+		cfg.NumSequencers = 1
+	}
+	if cfg.SequencerInterval <= 0 {
+		cfg.SequencerInterval = DefaultSequencerInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.TimeSource == nil {
+		cfg.TimeSource = clock.System
+	}
+	if cfg.QuotaManager == nil {
+		cfg.QuotaManager = quota.Noop()
+	}
+
+	if (cfg.AdminStorage == nil) != (cfg.LogStorage == nil) {
+		return nil, errors.New("AdminStorage and LogStorage must be set together")
+	}
+	if cfg.AdminStorage == nil {
+		ts := memory.NewTreeStorageWithTimeSource(cfg.TimeSource)
+		cfg.AdminStorage = memory.NewAdminStorage(ts)
+		cfg.LogStorage = memory.NewLogStorage(ts, nil)
+	}
+	registry := extension.Registry{
+		AdminStorage: cfg.AdminStorage,
+		LogStorage:   cfg.LogStorage,
+		QuotaManager: cfg.QuotaManager,
+	}
+
+	// The error wrapper is chained, so that callers may pass their own
+	// interceptors in ServerOptions.
+	serverOpts := append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptor.ErrorWrapper)}, cfg.ServerOptions...)
+	grpcServer := grpc.NewServer(serverOpts...)
+	trillian.RegisterTrillianAdminServer(grpcServer, admin.New(registry, nil))
+	trillian.RegisterTrillianLogServer(grpcServer, server.NewTrillianLogRPCServer(registry, cfg.TimeSource))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %v", err)
+	}
+	// The address is given by name, so that it matches certificates issued
+	// for localhost.
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	if err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("unrecognized format for listen address %v: %v", lis.Addr(), err)
+	}
+	addr := net.JoinHostPort("localhost", port)
+	dialOpts := cfg.DialOptions
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(addr, dialOpts...)
+	if err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("failed to dial %v: %v", addr, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	env := &LogEnv{
+		Address:    addr,
+		Log:        trillian.NewTrillianLogClient(conn),
+		Admin:      trillian.NewTrillianAdminClient(conn),
+		Registry:   registry,
+		conn:       conn,
+		grpcServer: grpcServer,
+		cancel:     cancel,
+	}
+
+	env.sequencer = log.NewOperationManager(log.OperationInfo{
+		Registry:    registry,
+		BatchSize:   cfg.BatchSize,
+		NumWorkers:  cfg.NumSequencers,
+		RunInterval: cfg.SequencerInterval,
+		TimeSource:  cfg.TimeSource,
+	}, log.NewSequencerManager(registry, cfg.SequencerGuardWindow))
+
+	env.wg.Add(1)
+	go func() {
+		defer env.wg.Done()
+		if err := grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			klog.Errorf("gRPC server stopped: %v", err)
+		}
+	}()
+	if !cfg.ManualSequencing {
+		env.wg.Add(1)
+		go func() {
+			defer env.wg.Done()
+			env.sequencer.OperationLoop(ctx)
+		}()
+	}
+	return env, nil
+}
+
+// CreateLog creates and initialises a tree based on the given template, which
+// must be of type LOG or PREORDERED_LOG.
+//
+// The server is in-process, so unlike client.CreateAndInitTree there is no
+// need to retry. Not using the client package also lets its tests use a
+// LogEnv.
+func (env *LogEnv) CreateLog(ctx context.Context, template *trillian.Tree) (*trillian.Tree, error) {
+	switch tt := template.GetTreeType(); tt {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+	default:
+		return nil, fmt.Errorf("tree type %v is not a log", tt)
+	}
+	tree, err := env.Admin.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: template})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := env.Log.InitLog(ctx, &trillian.InitLogRequest{LogId: tree.TreeId}); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Sequence runs a single sequencing pass over all active logs, blocking until
+// it completes. It is primarily intended for use with ManualSequencing, but
+// may be used to hurry the background sequencer along too.
+func (env *LogEnv) Sequence(ctx context.Context) {
+	env.sequencer.OperationSingle(ctx)
+}
+
+// Close shuts down the environment and releases its resources. All data held
+// by the environment is discarded.
+func (env *LogEnv) Close() {
+	env.cancel()
+	if err := env.conn.Close(); err != nil {
+		klog.Errorf("conn.Close(): %v", err)
+	}
+	env.grpcServer.GracefulStop()
+	env.wg.Wait()
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trilliantest
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+func TestLogEnvManualSequencing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := NewLogEnv(ctx, Config{ManualSequencing: true})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	tree, err := env.CreateLog(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+
+	const numLeaves = 5
+	for i := 0; i < numLeaves; i++ {
+		if _, err := env.Log.QueueLeaf(ctx, &trillian.QueueLeafRequest{
+			LogId: tree.TreeId,
+			Leaf:  &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf("leaf %d", i))},
+		}); err != nil {
+			t.Fatalf("QueueLeaf(%d): %v", i, err)
+		}
+	}
+
+	// Nothing is integrated until the test asks for it.
+	if got := treeSize(ctx, t, env, tree.TreeId); got != 0 {
+		t.Fatalf("tree size before sequencing = %d, want 0", got)
+	}
+	env.Sequence(ctx)
+	if got := treeSize(ctx, t, env, tree.TreeId); got != numLeaves {
+		t.Fatalf("tree size after sequencing = %d, want %d", got, numLeaves)
+	}
+}
+
+func TestLogEnvServerInterceptor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var calls atomic.Int32
+	count := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		calls.Add(1)
+		return handler(ctx, req)
+	}
+	env, err := NewLogEnv(ctx, Config{
+		ManualSequencing: true,
+		ServerOptions:    []grpc.ServerOption{grpc.UnaryInterceptor(count)},
+	})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	if _, err := env.CreateLog(ctx, stestonly.LogTree); err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+	if got := calls.Load(); got == 0 {
+		t.Error("interceptor passed in ServerOptions was not called")
+	}
+}
+
+func TestLogEnvBackgroundSequencing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := NewLogEnv(ctx, Config{SequencerInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	tree, err := env.CreateLog(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+	c, err := client.NewFromTree(env.Log, tree, types.LogRootV1{})
+	if err != nil {
+		t.Fatalf("NewFromTree(): %v", err)
+	}
+	if err := c.AddLeaf(ctx, []byte("hello")); err != nil {
+		t.Fatalf("AddLeaf(): %v", err)
+	}
+}
+
+func treeSize(ctx context.Context, t *testing.T, env *LogEnv, logID int64) uint64 {
+	t.Helper()
+	resp, err := env.Log.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: logID})
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(resp.SignedLogRoot.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	return root.TreeSize
+}
+
+func TestLogEnvFakeClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fakeClock := clock.NewFake(start)
+	env, err := NewLogEnv(ctx, Config{ManualSequencing: true, TimeSource: fakeClock})
+	if err != nil {
+		t.Fatalf("NewLogEnv(): %v", err)
+	}
+	defer env.Close()
+
+	tree, err := env.CreateLog(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateLog(): %v", err)
+	}
+	if got := tree.CreateTime.AsTime(); !got.Equal(start) {
+		t.Errorf("CreateTime = %v, want %v", got, start)
+	}
+
+	queued := start.Add(time.Minute)
+	fakeClock.Set(queued)
+	if _, err := env.Log.QueueLeaf(ctx, &trillian.QueueLeafRequest{
+		LogId: tree.TreeId,
+		Leaf:  &trillian.LogLeaf{LeafValue: []byte("leaf")},
+	}); err != nil {
+		t.Fatalf("QueueLeaf(): %v", err)
+	}
+
+	integrated := queued.Add(time.Hour)
+	fakeClock.Set(integrated)
+	env.Sequence(ctx)
+
+	resp, err := env.Log.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, StartIndex: 0, Count: 1})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if len(resp.Leaves) != 1 {
+		t.Fatalf("GetLeavesByRange() returned %d leaves, want 1", len(resp.Leaves))
+	}
+	leaf := resp.Leaves[0]
+	if got := leaf.QueueTimestamp.AsTime(); !got.Equal(queued) {
+		t.Errorf("QueueTimestamp = %v, want %v", got, queued)
+	}
+	// The merge delay is exactly the time the fake clock was advanced by.
+	if got := leaf.IntegrateTimestamp.AsTime(); !got.Equal(integrated) {
+		t.Errorf("IntegrateTimestamp = %v, want %v", got, integrated)
+	}
+
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(resp.SignedLogRoot.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if got, want := root.TimestampNanos, uint64(integrated.UnixNano()); got != want {
+		t.Errorf("root TimestampNanos = %d, want %d", got, want)
+	}
+}
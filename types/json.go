@@ -0,0 +1,159 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/trillian"
+)
+
+// The JSON encodings below are canonical: each value has exactly one encoding,
+// with fields in a fixed order and no insignificant whitespace. Field names
+// are those of the corresponding proto fields, byte strings are encoded with
+// standard padded base64, and 64-bit integers are encoded as decimal strings,
+// as in the proto JSON mapping, so that they survive JSON parsers which read
+// numbers as doubles.
+
+// logRootJSON is the JSON encoding of LogRootV1.
+type logRootJSON struct {
+	TreeSize       uint64 `json:"tree_size,string"`
+	RootHash       []byte `json:"root_hash"`
+	TimestampNanos uint64 `json:"timestamp_nanos,string"`
+	Revision       uint64 `json:"revision,string"`
+	Metadata       []byte `json:"metadata"`
+}
+
+// MarshalJSON returns the canonical JSON encoding of the log root, e.g.:
+//
+//	{"tree_size":"2","root_hash":"AQI=","timestamp_nanos":"3","revision":"0","metadata":""}
+func (l LogRootV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(logRootJSON{
+		TreeSize:       l.TreeSize,
+		RootHash:       nonNil(l.RootHash),
+		TimestampNanos: l.TimestampNanos,
+		Revision:       l.Revision,
+		Metadata:       nonNil(l.Metadata),
+	})
+}
+
+// UnmarshalJSON parses a log root encoded by MarshalJSON.
+func (l *LogRootV1) UnmarshalJSON(data []byte) error {
+	var j logRootJSON
+	if err := unmarshalStrict(data, &j); err != nil {
+		return fmt.Errorf("invalid log root: %v", err)
+	}
+	if len(j.RootHash) > 128 {
+		return fmt.Errorf("invalid log root: root_hash of %d bytes, want at most 128", len(j.RootHash))
+	}
+	if len(j.Metadata) > 65535 {
+		return fmt.Errorf("invalid log root: metadata of %d bytes, want at most 65535", len(j.Metadata))
+	}
+	*l = LogRootV1{
+		TreeSize:       j.TreeSize,
+		RootHash:       j.RootHash,
+		TimestampNanos: j.TimestampNanos,
+		Revision:       j.Revision,
+		Metadata:       j.Metadata,
+	}
+	return nil
+}
+
+// signedLogRootJSON is the JSON encoding of trillian.SignedLogRoot.
+type signedLogRootJSON struct {
+	LogRoot *LogRootV1 `json:"log_root"`
+}
+
+// MarshalSignedLogRootJSON returns the canonical JSON encoding of a
+// SignedLogRoot, in which its log_root is decoded, e.g.:
+//
+//	{"log_root":{"tree_size":"2","root_hash":"AQI=",...}}
+//
+// It returns an error if the log root isn't a valid LogRootV1.
+func MarshalSignedLogRootJSON(slr *trillian.SignedLogRoot) ([]byte, error) {
+	var root LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	return json.Marshal(signedLogRootJSON{LogRoot: &root})
+}
+
+// UnmarshalSignedLogRootJSON parses a SignedLogRoot encoded by
+// MarshalSignedLogRootJSON.
+func UnmarshalSignedLogRootJSON(data []byte) (*trillian.SignedLogRoot, error) {
+	var j signedLogRootJSON
+	if err := unmarshalStrict(data, &j); err != nil {
+		return nil, fmt.Errorf("invalid signed log root: %v", err)
+	}
+	if j.LogRoot == nil {
+		return nil, fmt.Errorf("invalid signed log root: missing log_root")
+	}
+	logRoot, err := j.LogRoot.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: logRoot}, nil
+}
+
+// proofJSON is the JSON encoding of trillian.Proof.
+type proofJSON struct {
+	LeafIndex int64    `json:"leaf_index,string"`
+	Hashes    [][]byte `json:"hashes"`
+}
+
+// MarshalProofJSON returns the canonical JSON encoding of a Proof, e.g.:
+//
+//	{"leaf_index":"1","hashes":["AQI=","AwQ="]}
+func MarshalProofJSON(p *trillian.Proof) ([]byte, error) {
+	hashes := make([][]byte, 0, len(p.GetHashes()))
+	for _, h := range p.GetHashes() {
+		hashes = append(hashes, nonNil(h))
+	}
+	return json.Marshal(proofJSON{LeafIndex: p.GetLeafIndex(), Hashes: hashes})
+}
+
+// UnmarshalProofJSON parses a Proof encoded by MarshalProofJSON.
+func UnmarshalProofJSON(data []byte) (*trillian.Proof, error) {
+	var j proofJSON
+	if err := unmarshalStrict(data, &j); err != nil {
+		return nil, fmt.Errorf("invalid proof: %v", err)
+	}
+	return &trillian.Proof{LeafIndex: j.LeafIndex, Hashes: j.Hashes}, nil
+}
+
+// unmarshalStrict parses data into v, rejecting unknown fields and trailing
+// data.
+func unmarshalStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("trailing data")
+	}
+	return nil
+}
+
+// nonNil returns b, or an empty slice if b is nil, which encoding/json would
+// otherwise encode as null.
+func nonNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLogRootJSON(t *testing.T) {
+	root := LogRootV1{
+		TreeSize:       2,
+		RootHash:       []byte{1, 2},
+		TimestampNanos: 1700000000123456789,
+		Metadata:       []byte{},
+	}
+	const want = `{"tree_size":"2","root_hash":"AQI=","timestamp_nanos":"1700000000123456789","revision":"0","metadata":""}`
+
+	// Values and pointers encode the same, including within other structs.
+	for _, v := range []interface{}{root, &root} {
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(): %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("Marshal()=%s, want %s", got, want)
+		}
+	}
+
+	var got LogRootV1
+	if err := json.Unmarshal([]byte(want), &got); err != nil {
+		t.Fatalf("Unmarshal(): %v", err)
+	}
+	if !reflect.DeepEqual(got, root) {
+		t.Errorf("Unmarshal()=%+v, want %+v", got, root)
+	}
+
+	for _, bad := range []string{
+		`{"tree_size":2}`,
+		`{"tree_size":"2","unknown":1}`,
+		`{"root_hash":"not base64"}`,
+		`{} {}`,
+	} {
+		var got LogRootV1
+		if err := got.UnmarshalJSON([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalJSON(%s): got no error", bad)
+		}
+	}
+}
+
+func TestSignedLogRootJSON(t *testing.T) {
+	slr := &trillian.SignedLogRoot{LogRoot: MustMarshalLogRoot(&LogRootV1{TreeSize: 5, RootHash: []byte{3}, TimestampNanos: 6})}
+	const want = `{"log_root":{"tree_size":"5","root_hash":"Aw==","timestamp_nanos":"6","revision":"0","metadata":""}}`
+
+	got, err := MarshalSignedLogRootJSON(slr)
+	if err != nil {
+		t.Fatalf("MarshalSignedLogRootJSON(): %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("MarshalSignedLogRootJSON()=%s, want %s", got, want)
+	}
+	parsed, err := UnmarshalSignedLogRootJSON(got)
+	if err != nil {
+		t.Fatalf("UnmarshalSignedLogRootJSON(): %v", err)
+	}
+	if !proto.Equal(parsed, slr) {
+		t.Errorf("UnmarshalSignedLogRootJSON()=%v, want %v", parsed, slr)
+	}
+
+	if _, err := MarshalSignedLogRootJSON(&trillian.SignedLogRoot{LogRoot: []byte("bad")}); err == nil {
+		t.Error("MarshalSignedLogRootJSON(invalid root): got no error")
+	}
+	if _, err := UnmarshalSignedLogRootJSON([]byte(`{}`)); err == nil {
+		t.Error("UnmarshalSignedLogRootJSON(missing root): got no error")
+	}
+}
+
+func TestProofJSON(t *testing.T) {
+	for _, test := range []struct {
+		proof *trillian.Proof
+		want  string
+	}{
+		{proof: &trillian.Proof{LeafIndex: 1, Hashes: [][]byte{{1, 2}, {3, 4}}}, want: `{"leaf_index":"1","hashes":["AQI=","AwQ="]}`},
+		{proof: &trillian.Proof{}, want: `{"leaf_index":"0","hashes":[]}`},
+	} {
+		got, err := MarshalProofJSON(test.proof)
+		if err != nil {
+			t.Fatalf("MarshalProofJSON(): %v", err)
+		}
+		if string(got) != test.want {
+			t.Errorf("MarshalProofJSON()=%s, want %s", got, test.want)
+		}
+		parsed, err := UnmarshalProofJSON(got)
+		if err != nil {
+			t.Fatalf("UnmarshalProofJSON(): %v", err)
+		}
+		if !proto.Equal(parsed, test.proof) {
+			t.Errorf("UnmarshalProofJSON()=%v, want %v", parsed, test.proof)
+		}
+	}
+}
@@ -42,6 +42,14 @@ type RunnerConfig struct {
 	MasterHoldInterval time.Duration
 	// MasterHoldJitter is the maximum addition to MasterHoldInterval.
 	MasterHoldJitter time.Duration
+	// PreElectionBackoff is the maximum interval to wait after deliberately
+	// resigning mastership before standing for election again, so that other
+	// instances have a chance to win it. There is no backoff if it is zero.
+	PreElectionBackoff time.Duration
+	// ResignProbability is the probability of resigning mastership each time
+	// the hold interval elapses. Otherwise mastership is held for another
+	// interval. Values outside of (0, 1] mean always resigning.
+	ResignProbability float64
 
 	TimeSource clock.TimeSource
 }
@@ -56,6 +64,15 @@ func (cfg *RunnerConfig) ResignDelay() time.Duration {
 	return delay + time.Duration(add)
 }
 
+// ShouldResign randomly decides, with ResignProbability, whether to resign
+// mastership once the hold interval has elapsed.
+func (cfg *RunnerConfig) ShouldResign() bool {
+	if cfg.ResignProbability <= 0 || cfg.ResignProbability >= 1 {
+		return true
+	}
+	return rand.Float64() < cfg.ResignProbability
+}
+
 // fixupRunnerConfig ensures operation parameters have required minimum values.
 func fixupRunnerConfig(cfg *RunnerConfig) {
 	if cfg.PreElectionPause < MinPreElectionPause {
@@ -67,6 +84,9 @@ func fixupRunnerConfig(cfg *RunnerConfig) {
 	if cfg.MasterHoldJitter < 0 {
 		cfg.MasterHoldJitter = 0
 	}
+	if cfg.PreElectionBackoff < 0 {
+		cfg.PreElectionBackoff = 0
+	}
 	if cfg.TimeSource == nil {
 		cfg.TimeSource = clock.System
 	}
@@ -121,9 +141,23 @@ func (er *Runner) Run(ctx context.Context, pending chan<- Resignation) {
 			klog.Errorf("%s: %v", er.id, err)
 			break
 		}
+		if err := er.backoff(ctx); err != nil {
+			break // The context has been canceled during the backoff.
+		}
 	}
 }
 
+// backoff waits for a random interval of up to PreElectionBackoff after a
+// resignation, before the next election.
+func (er *Runner) backoff(ctx context.Context) error {
+	if er.cfg.PreElectionBackoff <= 0 {
+		return nil
+	}
+	pause := time.Duration(rand.Int63n(er.cfg.PreElectionBackoff.Nanoseconds()))
+	klog.V(1).Infof("%s: backing off for %v before standing for election again", er.id, pause)
+	return clock.SleepSource(ctx, pause, er.cfg.TimeSource)
+}
+
 func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) error {
 	klog.V(1).Infof("%s: When I left you, I was but the learner", er.id)
 	if err := er.election.Await(ctx); err != nil {
@@ -138,26 +172,41 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 		return fmt.Errorf("election.WithMastership() failed: %v", err)
 	}
 
+	for {
+		if err := er.hold(mctx); err != nil {
+			klog.Errorf("%s: no longer the master!", er.id)
+			return err
+		}
+		if er.cfg.ShouldResign() {
+			break
+		}
+		klog.V(1).Infof("%s: holding mastership for another interval", er.id)
+	}
+
+	klog.Infof("%s: queue up resignation of mastership", er.id)
+	done := make(chan struct{})
+	r := Resignation{ID: er.id, er: er, done: done}
+	select {
+	case pending <- r:
+		<-done // Block until acted on.
+	default:
+		klog.Warning("Dropping resignation because operation manager seems to be exiting")
+	}
+	return nil
+}
+
+// hold waits for a randomized hold interval, and returns the error of mctx if
+// mastership is lost in the meantime.
+func (er *Runner) hold(mctx context.Context) error {
 	timer := er.cfg.TimeSource.NewTimer(er.cfg.ResignDelay())
 	defer timer.Stop()
 
 	select {
 	case <-mctx.Done(): // Mastership context is canceled.
-		klog.Errorf("%s: no longer the master!", er.id)
 		return mctx.Err()
-
 	case <-timer.Chan():
-		klog.Infof("%s: queue up resignation of mastership", er.id)
-		done := make(chan struct{})
-		r := Resignation{ID: er.id, er: er, done: done}
-		select {
-		case pending <- r:
-			<-done // Block until acted on.
-		default:
-			klog.Warning("Dropping resignation because operation manager seems to be exiting")
-		}
+		return nil
 	}
-	return nil
 }
 
 // Resignation indicates that a master should explicitly resign mastership, and
@@ -56,6 +56,35 @@ func TestConfigResignDelay(t *testing.T) {
 	}
 }
 
+func TestConfigShouldResign(t *testing.T) {
+	const checks = 10000
+	for _, tc := range []struct {
+		prob    float64
+		wantMin int
+		wantMax int
+	}{
+		{prob: 0, wantMin: checks, wantMax: checks},
+		{prob: -1, wantMin: checks, wantMax: checks},
+		{prob: 1, wantMin: checks, wantMax: checks},
+		{prob: 2, wantMin: checks, wantMax: checks},
+		{prob: 0.5, wantMin: checks * 4 / 10, wantMax: checks * 6 / 10},
+		{prob: 1e-12, wantMin: 0, wantMax: 1},
+	} {
+		t.Run(fmt.Sprintf("%v", tc.prob), func(t *testing.T) {
+			cfg := election.RunnerConfig{ResignProbability: tc.prob}
+			var resigned int
+			for i := 0; i < checks; i++ {
+				if cfg.ShouldResign() {
+					resigned++
+				}
+			}
+			if resigned < tc.wantMin || resigned > tc.wantMax {
+				t.Errorf("ShouldResign(): resigned %d times out of %d, want between %d and %d", resigned, checks, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
+
 // TODO(pavelkalinnikov): Reduce flakiness risk in this test by making fewer
 // time assumptions.
 func TestElectionRunnerRun(t *testing.T) {
@@ -74,12 +103,15 @@ func TestElectionRunnerRun(t *testing.T) {
 		wantMaster bool
 		loseMaster bool
 		resign     bool
+		// resignProb is the ResignProbability of the runner, if not 1.
+		resignProb float64
 	}{
 		// Basic cases.
 		{desc: "not-master"},
 		{desc: "is-master", isMaster: true, wantMaster: true},
 		{desc: "lose-master", isMaster: true, wantMaster: true, loseMaster: true},
 		{desc: "resign", isMaster: true, wantMaster: true, resign: true},
+		{desc: "hold", isMaster: true, wantMaster: true, resign: true, resignProb: 1e-12},
 		// Error cases.
 		{desc: "err-await", errs: to.Errs{Await: errors.New("ErrAwait")}},
 		{desc: "err-mctx", errs: to.Errs{WithMastership: errors.New("ErrMastership")}},
@@ -108,7 +140,7 @@ func TestElectionRunnerRun(t *testing.T) {
 			start := time.Now()
 			ts := clock.NewFake(start)
 			tracker := election.NewMasterTracker([]string{logID}, nil)
-			cfg := election.RunnerConfig{TimeSource: ts}
+			cfg := election.RunnerConfig{ResignProbability: tc.resignProb, TimeSource: ts}
 			er := election.NewRunner(logID, &cfg, tracker, nil, d)
 			resignations := make(chan election.Resignation, 100)
 
@@ -150,7 +182,9 @@ func TestElectionRunnerRun(t *testing.T) {
 				time.Sleep(100 * time.Millisecond)
 			}
 
-			checkMaster(t, tracker.Held(), tc.wantMaster && !tc.loseMaster && !tc.resign)
+			// Mastership is held for another interval if the runner doesn't resign.
+			resigned := tc.resign && tc.resignProb == 0
+			checkMaster(t, tracker.Held(), tc.wantMaster && !tc.loseMaster && !resigned)
 			cancel()  // If Runner is still running, it should stop now.
 			wg.Wait() // Wait until it stops.
 			checkMaster(t, tracker.Held(), false)
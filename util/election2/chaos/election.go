@@ -0,0 +1,229 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chaos contains an election2.Election implementation which grants
+// and revokes mastership at random, for exercising the mastership loss paths
+// of its users under churn. It must not be used in production.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/election2"
+	"k8s.io/klog/v2"
+)
+
+// Distribution is a random distribution of durations. It implements
+// flag.Value, in the "kind:mean" format, e.g. "exponential:30s".
+type Distribution struct {
+	// Kind is one of "fixed", "uniform" or "exponential". A uniform
+	// distribution ranges over [0, 2*Mean).
+	Kind string
+	// Mean is the mean duration.
+	Mean time.Duration
+}
+
+// Sample returns a random duration drawn from the distribution.
+func (d Distribution) Sample(r *rand.Rand) time.Duration {
+	if d.Mean <= 0 {
+		return 0
+	}
+	switch d.Kind {
+	case "uniform":
+		return time.Duration(r.Int63n(2 * int64(d.Mean)))
+	case "exponential":
+		return time.Duration(r.ExpFloat64() * float64(d.Mean))
+	default:
+		return d.Mean
+	}
+}
+
+// String returns the distribution in the "kind:mean" format.
+func (d *Distribution) String() string {
+	if d.Kind == "" {
+		return d.Mean.String()
+	}
+	return fmt.Sprintf("%s:%v", d.Kind, d.Mean)
+}
+
+// Set parses a distribution in the "kind:mean" format. A bare duration is
+// taken to be fixed.
+func (d *Distribution) Set(s string) error {
+	kind, mean, ok := strings.Cut(s, ":")
+	if !ok {
+		kind, mean = "fixed", s
+	}
+	switch kind {
+	case "fixed", "uniform", "exponential":
+	default:
+		return fmt.Errorf("unknown distribution %q, want fixed, uniform or exponential", kind)
+	}
+	m, err := time.ParseDuration(mean)
+	if err != nil {
+		return err
+	}
+	if m < 0 {
+		return fmt.Errorf("negative mean %v", m)
+	}
+	d.Kind, d.Mean = kind, m
+	return nil
+}
+
+// Config describes how mastership is granted and revoked.
+type Config struct {
+	// Wait is the distribution of the time Await blocks before granting
+	// mastership.
+	Wait Distribution
+	// Hold is the distribution of the time mastership is held for before it
+	// gets revoked.
+	Hold Distribution
+	// Seed seeds the random number generator of a Factory.
+	Seed int64
+	// TimeSource is used for timing the grants and revocations. The system
+	// time is used if it is nil.
+	TimeSource clock.TimeSource
+}
+
+// Factory creates Election instances sharing the same Config and random
+// number generator.
+type Factory struct {
+	cfg Config
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewFactory returns a Factory for the given Config.
+func NewFactory(cfg Config) *Factory {
+	if cfg.TimeSource == nil {
+		cfg.TimeSource = clock.System
+	}
+	return &Factory{cfg: cfg, rnd: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+// NewElection creates an Election for the given resource.
+func (f *Factory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
+	return &Election{f: f, resourceID: resourceID}, nil
+}
+
+// sample draws a duration from d. It is safe for concurrent use.
+func (f *Factory) sample(d Distribution) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return d.Sample(f.rnd)
+}
+
+// Election implements election2.Election by granting mastership after a
+// random wait, and revoking it after a random hold time.
+type Election struct {
+	f          *Factory
+	resourceID string
+
+	mu      sync.Mutex
+	master  bool
+	revoked chan struct{} // Closed when the current mastership is lost.
+}
+
+// Await blocks for a random wait time, and then grants mastership, which gets
+// revoked after a random hold time. It returns immediately if the instance is
+// already the master.
+func (e *Election) Await(ctx context.Context) error {
+	e.mu.Lock()
+	master := e.master
+	e.mu.Unlock()
+	if master {
+		return nil
+	}
+
+	ts := e.f.cfg.TimeSource
+	if wait := e.f.sample(e.f.cfg.Wait); wait > 0 {
+		if err := clock.SleepSource(ctx, wait, ts); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.master {
+		return nil
+	}
+	e.master = true
+	revoked := make(chan struct{})
+	e.revoked = revoked
+	hold := e.f.sample(e.f.cfg.Hold)
+	klog.Infof("%s: chaos election granting mastership for %v", e.resourceID, hold)
+	timer := ts.NewTimer(hold)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-timer.Chan():
+			klog.Infof("%s: chaos election revoking mastership", e.resourceID)
+			e.revoke(revoked)
+		case <-revoked:
+		}
+	}()
+	return nil
+}
+
+// revoke ends the mastership which revoked is tracking, if it is current.
+func (e *Election) revoke(revoked chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.master || e.revoked != revoked {
+		return
+	}
+	e.master = false
+	close(e.revoked)
+}
+
+// WithMastership returns mastership context, which gets canceled when the
+// mastership is revoked or resigned. If the instance is not the master, the
+// returned context is canceled already.
+func (e *Election) WithMastership(ctx context.Context) (context.Context, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cctx, cancel := context.WithCancel(ctx)
+	if !e.master {
+		cancel()
+		return cctx, nil
+	}
+	revoked := e.revoked
+	go func() {
+		defer cancel()
+		select {
+		case <-revoked:
+		case <-cctx.Done():
+		}
+	}()
+	return cctx, nil
+}
+
+// Resign releases mastership.
+func (e *Election) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	revoked := e.revoked
+	e.mu.Unlock()
+	e.revoke(revoked)
+	return nil
+}
+
+// Close releases mastership.
+func (e *Election) Close(ctx context.Context) error {
+	return e.Resign(ctx)
+}
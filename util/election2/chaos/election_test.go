@@ -0,0 +1,132 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/util/clock"
+)
+
+func TestDistribution(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    Distribution
+		wantErr bool
+	}{
+		{in: "10s", want: Distribution{Kind: "fixed", Mean: 10 * time.Second}},
+		{in: "uniform:1m", want: Distribution{Kind: "uniform", Mean: time.Minute}},
+		{in: "exponential:500ms", want: Distribution{Kind: "exponential", Mean: 500 * time.Millisecond}},
+		{in: "normal:1s", wantErr: true},
+		{in: "fixed:soon", wantErr: true},
+		{in: "fixed:-1s", wantErr: true},
+	} {
+		t.Run(test.in, func(t *testing.T) {
+			var d Distribution
+			err := d.Set(test.in)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Set(%q): %v, wantErr %v", test.in, err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d != test.want {
+				t.Errorf("Set(%q) = %+v, want %+v", test.in, d, test.want)
+			}
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				got := d.Sample(r)
+				if got < 0 || (d.Kind == "fixed" && got != d.Mean) || (d.Kind == "uniform" && got >= 2*d.Mean) {
+					t.Fatalf("Sample() = %v, out of range for %v", got, &d)
+				}
+			}
+		})
+	}
+}
+
+func TestElectionRevokes(t *testing.T) {
+	ctx := context.Background()
+	ts := clock.NewFake(time.Now())
+	f := NewFactory(Config{
+		Hold:       Distribution{Kind: "fixed", Mean: time.Minute},
+		TimeSource: ts,
+	})
+	e, err := f.NewElection(ctx, "tree")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := e.Await(ctx); err != nil {
+			t.Fatalf("Await(): %v", err)
+		}
+		mctx, err := e.WithMastership(ctx)
+		if err != nil {
+			t.Fatalf("WithMastership(): %v", err)
+		}
+		if err := mctx.Err(); err != nil {
+			t.Fatalf("mastership context done after Await(): %v", err)
+		}
+		ts.Set(ts.Now().Add(time.Minute - time.Second))
+		if err := mctx.Err(); err != nil {
+			t.Fatalf("mastership context done before the hold time: %v", err)
+		}
+		ts.Set(ts.Now().Add(time.Second))
+		<-mctx.Done()
+	}
+
+	// Once revoked, mastership contexts are born canceled.
+	mctx, err := e.WithMastership(ctx)
+	if err != nil {
+		t.Fatalf("WithMastership(): %v", err)
+	}
+	if mctx.Err() == nil {
+		t.Error("WithMastership() after revocation returned a live context")
+	}
+}
+
+func TestElectionResign(t *testing.T) {
+	ctx := context.Background()
+	f := NewFactory(Config{Hold: Distribution{Kind: "fixed", Mean: time.Hour}})
+	e, err := f.NewElection(ctx, "tree")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	if err := e.Await(ctx); err != nil {
+		t.Fatalf("Await(): %v", err)
+	}
+	mctx, err := e.WithMastership(ctx)
+	if err != nil {
+		t.Fatalf("WithMastership(): %v", err)
+	}
+	if err := e.Resign(ctx); err != nil {
+		t.Fatalf("Resign(): %v", err)
+	}
+	<-mctx.Done()
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	slow := NewFactory(Config{Wait: Distribution{Kind: "fixed", Mean: time.Hour}})
+	e, err = slow.NewElection(ctx, "tree")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	if err := e.Await(cctx); err != context.Canceled {
+		t.Errorf("Await(canceled) = %v, want %v", err, context.Canceled)
+	}
+}
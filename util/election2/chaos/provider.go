@@ -0,0 +1,49 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chaos
+
+import (
+	"flag"
+	"time"
+
+	"github.com/google/trillian/util/election2"
+	"k8s.io/klog/v2"
+)
+
+// ElectionName identifies the chaos election implementation.
+const ElectionName = "chaos"
+
+var (
+	wait = Distribution{Kind: "exponential", Mean: 5 * time.Second}
+	hold = Distribution{Kind: "exponential", Mean: time.Minute}
+	seed = flag.Int64("chaos_election_seed", 0, "Seed for the random mastership grants and revocations, or 0 to use the current time. Only effective for election_system=chaos.")
+)
+
+func init() {
+	flag.Var(&wait, "chaos_election_wait", "Distribution of the time to wait before granting mastership, as kind:mean with kind one of fixed, uniform or exponential. Only effective for election_system=chaos.")
+	flag.Var(&hold, "chaos_election_hold", "Distribution of the time to hold mastership for before revoking it, as kind:mean with kind one of fixed, uniform or exponential. Only effective for election_system=chaos.")
+	if err := election2.RegisterProvider(ElectionName, newFactory); err != nil {
+		klog.Fatalf("Failed to register election implementation %v: %v", ElectionName, err)
+	}
+}
+
+func newFactory() (election2.Factory, error) {
+	s := *seed
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+	klog.Warningf("Using the chaos election system with wait %v, hold %v and seed %d: mastership will be revoked at random", &wait, &hold, s)
+	return NewFactory(Config{Wait: wait, Hold: hold, Seed: s}), nil
+}
@@ -85,3 +85,12 @@ type Election interface {
 type Factory interface {
 	NewElection(ctx context.Context, resourceID string) (Election, error)
 }
+
+// Warmer is implemented by Factory types which can establish the sessions of
+// their elections ahead of time, so that the first elections don't have to
+// wait for them.
+type Warmer interface {
+	// Warmup creates the configured number of sessions, which later calls to
+	// NewElection use. It returns an error if any of them fails.
+	Warmup(ctx context.Context) error
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/google/trillian/util/election2"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -125,18 +126,68 @@ func (e *Election) Close(ctx context.Context) error {
 	return e.session.Close()
 }
 
+// sessionTTL is the TTL in seconds of the leases of the sessions created by
+// Warmup, which is the default of concurrency.NewSession.
+const sessionTTL = 60
+
 // Factory creates Election instances.
 type Factory struct {
 	client     *clientv3.Client
 	instanceID string
 	lockDir    string
+	// warmSessions is the number of sessions which Warmup creates.
+	warmSessions int
+
+	mu sync.Mutex
+	// sessions holds the sessions created by Warmup which no election uses
+	// yet.
+	sessions []*concurrency.Session
+}
+
+// Warmup implements election2.Warmer.
+func (f *Factory) Warmup(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.sessions) < f.warmSessions {
+		// Grant the lease with ctx, so that an unreachable etcd doesn't block
+		// the warmup forever. The session keeps the lease alive.
+		lease, err := f.client.Grant(ctx, sessionTTL)
+		if err != nil {
+			return fmt.Errorf("failed to grant etcd lease: %v", err)
+		}
+		session, err := concurrency.NewSession(f.client, concurrency.WithLease(lease.ID))
+		if err != nil {
+			return fmt.Errorf("failed to create etcd session: %v", err)
+		}
+		f.sessions = append(f.sessions, session)
+	}
+	return nil
+}
+
+// newSession returns a session created by Warmup, if any is left, or a new
+// one.
+func (f *Factory) newSession() (*concurrency.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.sessions) > 0 {
+		session := f.sessions[len(f.sessions)-1]
+		f.sessions = f.sessions[:len(f.sessions)-1]
+		select {
+		case <-session.Done():
+			// The lease has expired, or couldn't be kept alive.
+			continue
+		default:
+			return session, nil
+		}
+	}
+	return concurrency.NewSession(f.client)
 }
 
 // NewElection creates a specific Election instance.
 func (f *Factory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
 	// TODO(pavelkalinnikov): Re-create the session if it expires.
 	// TODO(pavelkalinnikov): Share the same session between Election instances.
-	session, err := concurrency.NewSession(f.client)
+	session, err := f.newSession()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create etcd session: %v", err)
 	}
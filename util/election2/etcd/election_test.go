@@ -61,6 +61,45 @@ func TestElectionThroughCommonClient(t *testing.T) {
 	}
 }
 
+func TestWarmup(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	fact := &Factory{
+		client:       client,
+		instanceID:   "serv",
+		lockDir:      "warm/",
+		warmSessions: 2,
+	}
+	if err := fact.Warmup(ctx); err != nil {
+		t.Fatalf("Warmup(): %v", err)
+	}
+	if got, want := len(fact.sessions), 2; got != want {
+		t.Fatalf("Warmup() created %d sessions, want %d", got, want)
+	}
+
+	// The elections use the warm sessions first.
+	for i, id := range []string{"10", "20", "30"} {
+		el, err := fact.NewElection(ctx, id)
+		if err != nil {
+			t.Fatalf("NewElection(%s): %v", id, err)
+		}
+		if got, want := len(fact.sessions), max(1-i, 0); got != want {
+			t.Errorf("NewElection(%s) left %d warm sessions, want %d", id, got, want)
+		}
+		if err := el.Await(ctx); err != nil {
+			t.Fatalf("Await(%s): %v", id, err)
+		}
+		if err := el.Close(ctx); err != nil {
+			t.Fatalf("Close(%s): %v", id, err)
+		}
+	}
+}
+
 func TestElection(t *testing.T) {
 	_, client, cleanup, err := etcd.StartEtcd()
 	if err != nil {
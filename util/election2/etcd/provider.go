@@ -17,7 +17,8 @@ import (
 const ElectionName = "etcd"
 
 var (
-	lockDir = flag.String("lock_file_path", "/test/multimaster", "etcd lock file directory path")
+	lockDir      = flag.String("lock_file_path", "/test/multimaster", "etcd lock file directory path")
+	warmSessions = flag.Int("etcd_warmup_sessions", 0, "Number of etcd election sessions to create before the server reports itself as ready, which the first elections use")
 )
 
 func init() {
@@ -50,8 +51,9 @@ func newFactory() (election2.Factory, error) {
 
 	// The passed in etcd client should remain valid for the lifetime of the object.
 	return &Factory{
-		client:     client,
-		instanceID: instanceID,
-		lockDir:    *lockDir,
+		client:       client,
+		instanceID:   instanceID,
+		lockDir:      *lockDir,
+		warmSessions: *warmSessions,
 	}, nil
 }
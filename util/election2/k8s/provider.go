@@ -19,6 +19,10 @@ var (
 	kubeconfig = flag.String("kubeconfig", "", "Paths to a kubeconfig. Only required if out-of-cluster.")
 	namespace  = flag.String("lock_namespace", "", "The lease lock resource namespace. Only effective for election_system=k8s.")
 	instanceID = flag.String("lock_holder_identity", "", "The identity of the holder of a current lease")
+	// The defaults match the --master_hold_jitter and --master_hold_interval
+	// defaults of the log signer, which these were previously taken from.
+	leaseDuration = flag.Duration("lock_lease_duration", 120*time.Second, "How long a lease lock is valid for without being renewed. Only effective for election_system=k8s.")
+	retryPeriod   = flag.Duration("lock_retry_period", 60*time.Second, "How often to try to acquire or renew a lease lock. Only effective for election_system=k8s.")
 )
 
 func init() {
@@ -45,23 +49,11 @@ func newFactory() (election2.Factory, error) {
 
 	clientset := kubernetes.NewForConfigOrDie(config)
 
-	holdInterval := flag.Lookup("master_hold_interval").Value
-	holdIntervalDuration, err := time.ParseDuration(holdInterval.String())
-	if err != nil {
-		return nil, fmt.Errorf("master_hold_interval: %w", err)
-	}
-
-	holdJitter := flag.Lookup("master_hold_jitter").Value
-	holdJitterDuration, err := time.ParseDuration(holdJitter.String())
-	if err != nil {
-		return nil, fmt.Errorf("master_hold_jitter: %w", err)
-	}
-
 	return &Factory{
 		client:        clientset.CoordinationV1(),
 		namespace:     *namespace,
 		instanceID:    instance,
-		leaseDuration: holdJitterDuration,
-		retryPeriod:   holdIntervalDuration,
+		leaseDuration: *leaseDuration,
+		retryPeriod:   *retryPeriod,
 	}, nil
 }
@@ -0,0 +1,98 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package load samples the resource usage of the current process.
+package load
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	heapMetric     = "/memory/classes/heap/objects:bytes"
+	cpuTotalMetric = "/cpu/classes/total:cpu-seconds"
+	cpuIdleMetric  = "/cpu/classes/idle:cpu-seconds"
+)
+
+// Monitor periodically samples the process's heap size and CPU usage,
+// and reports whether either exceeds its threshold.
+type Monitor struct {
+	maxHeapBytes uint64
+	maxCPU       float64
+	overloaded   atomic.Bool
+
+	samples      []metrics.Sample
+	lastCPUTotal float64
+	lastCPUIdle  float64
+}
+
+// NewMonitor returns a Monitor which considers the process to be
+// overloaded while its live heap exceeds maxHeapBytes, or while the fraction
+// of available CPU time it used over the last sampling interval exceeds
+// maxCPU. A zero threshold disables that check.
+func NewMonitor(maxHeapBytes uint64, maxCPU float64) *Monitor {
+	m := &Monitor{
+		maxHeapBytes: maxHeapBytes,
+		maxCPU:       maxCPU,
+		samples: []metrics.Sample{
+			{Name: heapMetric},
+			{Name: cpuTotalMetric},
+			{Name: cpuIdleMetric},
+		},
+	}
+	m.sample()
+	return m
+}
+
+// Overloaded reports whether the process was overloaded when last sampled.
+func (m *Monitor) Overloaded() bool {
+	return m.overloaded.Load()
+}
+
+// Run samples the process's load every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.sample()
+	}
+}
+
+// sample reads the runtime metrics and updates the overloaded state. The
+// runtime's CPU accounting is only updated by garbage collections, so the CPU
+// estimate lags; it is intended to catch sustained overload rather than
+// spikes.
+func (m *Monitor) sample() {
+	metrics.Read(m.samples)
+	overloaded := false
+	if heap := m.samples[0].Value; m.maxHeapBytes > 0 && heap.Kind() == metrics.KindUint64 {
+		overloaded = heap.Uint64() > m.maxHeapBytes
+	}
+	if total, idle := m.samples[1].Value, m.samples[2].Value; total.Kind() == metrics.KindFloat64 && idle.Kind() == metrics.KindFloat64 {
+		dTotal, dIdle := total.Float64()-m.lastCPUTotal, idle.Float64()-m.lastCPUIdle
+		m.lastCPUTotal, m.lastCPUIdle = total.Float64(), idle.Float64()
+		if m.maxCPU > 0 && dTotal > 0 {
+			overloaded = overloaded || (dTotal-dIdle)/dTotal > m.maxCPU
+		}
+	}
+	m.overloaded.Store(overloaded)
+}
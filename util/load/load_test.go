@@ -1,4 +1,4 @@
-// Copyright 2017 Google LLC. All Rights Reserved.
+// Copyright 2025 Trillian Authors. All Rights Reserved.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -12,23 +12,16 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package integration
+package load
 
-import (
-	"fmt"
-	"net"
-)
+import "testing"
 
-// listen opens a random high numbered port for listening.
-func listen() (string, net.Listener, error) {
-	lis, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to listen: %v", err)
+func TestMonitor(t *testing.T) {
+	// Any process has more than one byte of heap.
+	if m := NewMonitor(1, 0); !m.Overloaded() {
+		t.Error("Overloaded() = false with tiny heap limit")
 	}
-	_, port, err := net.SplitHostPort(lis.Addr().String())
-	if err != nil {
-		return "", nil, fmt.Errorf("unrecognized format for listen address %v: %v", lis.Addr().String(), err)
+	if m := NewMonitor(0, 0); m.Overloaded() {
+		t.Error("Overloaded() = true with no limits")
 	}
-	addr := "localhost:" + port
-	return addr, lis, nil
 }
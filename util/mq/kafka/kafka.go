@@ -0,0 +1,229 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kafka implements the mq interfaces for Apache Kafka, through the v2
+// API of a Kafka REST Proxy.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/trillian/util/mq"
+)
+
+const (
+	contentType = "application/vnd.kafka.v2+json"
+	binaryType  = "application/vnd.kafka.binary.v2+json"
+)
+
+// record is a Kafka record in the binary embedded format of the REST Proxy,
+// where the key and value are base64 encoded.
+type record struct {
+	Topic     string `json:"topic,omitempty"`
+	Key       []byte `json:"key,omitempty"`
+	Value     []byte `json:"value"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// offset identifies a record to commit the offset of.
+type offset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// Subscriber consumes the records of a topic as a member of a consumer group.
+// Offsets are only committed when records are acknowledged.
+type Subscriber struct {
+	client  *http.Client
+	baseURI string
+}
+
+// NewSubscriber creates a consumer in the group for the topic, through the
+// REST Proxy at proxyURL. The consumer starts from the committed offsets of
+// the group, or from the earliest records if there are none.
+func NewSubscriber(ctx context.Context, client *http.Client, proxyURL, group, topic string) (*Subscriber, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var created struct {
+		BaseURI string `json:"base_uri"`
+	}
+	cfg := map[string]string{
+		"format":             "binary",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}
+	u := strings.TrimSuffix(proxyURL, "/") + "/consumers/" + url.PathEscape(group)
+	if err := do(ctx, client, http.MethodPost, u, contentType, cfg, &created); err != nil {
+		return nil, fmt.Errorf("failed to create consumer in group %v: %v", group, err)
+	}
+	s := &Subscriber{client: client, baseURI: created.BaseURI}
+	if err := do(ctx, client, http.MethodPost, s.baseURI+"/subscription", contentType, map[string][]string{"topics": {topic}}, nil); err != nil {
+		_ = s.Close(ctx)
+		return nil, fmt.Errorf("failed to subscribe to %v: %v", topic, err)
+	}
+	return s, nil
+}
+
+// Receive implements mq.Subscriber. The REST Proxy limits the number of
+// records returned by size rather than count, so it may return more than max.
+func (s *Subscriber) Receive(ctx context.Context, max int) ([]*mq.Message, error) {
+	var records []record
+	if err := do(ctx, s.client, http.MethodGet, s.baseURI+"/records", binaryType, nil, &records); err != nil {
+		return nil, fmt.Errorf("failed to fetch records: %v", err)
+	}
+	msgs := make([]*mq.Message, 0, len(records))
+	for _, r := range records {
+		id := fmt.Sprintf("%s/%d/%d", r.Topic, r.Partition, r.Offset)
+		msgs = append(msgs, mq.NewMessage(id, r.Key, r.Value, offset{Topic: r.Topic, Partition: r.Partition, Offset: r.Offset}))
+	}
+	return msgs, nil
+}
+
+// Ack implements mq.Subscriber. It commits the highest offset of each
+// partition among msgs, so all the records received before them from the
+// same partitions must have been processed too.
+func (s *Subscriber) Ack(ctx context.Context, msgs []*mq.Message) error {
+	type partition struct {
+		topic string
+		id    int32
+	}
+	highest := make(map[partition]offset)
+	var order []partition
+	for _, m := range msgs {
+		o := m.AckToken().(offset)
+		p := partition{o.Topic, o.Partition}
+		h, ok := highest[p]
+		if !ok {
+			order = append(order, p)
+		}
+		if !ok || o.Offset > h.Offset {
+			highest[p] = o
+		}
+	}
+	if len(order) == 0 {
+		return nil
+	}
+	req := struct {
+		Offsets []offset `json:"offsets"`
+	}{}
+	for _, p := range order {
+		req.Offsets = append(req.Offsets, highest[p])
+	}
+	if err := do(ctx, s.client, http.MethodPost, s.baseURI+"/offsets", contentType, req, nil); err != nil {
+		return fmt.Errorf("failed to commit offsets: %v", err)
+	}
+	return nil
+}
+
+// Close implements mq.Subscriber. It deletes the consumer, so that its
+// partitions are reassigned to the other members of the group.
+func (s *Subscriber) Close(ctx context.Context) error {
+	return do(ctx, s.client, http.MethodDelete, s.baseURI, contentType, nil, nil)
+}
+
+// do sends a request with the JSON encoding of req, if not nil, to the REST
+// Proxy, and decodes the JSON response into rsp, if not nil. accept is both
+// the content type of the request and the accepted type of the response.
+func do(ctx context.Context, client *http.Client, method, u, accept string, req, rsp any) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		hreq.Header.Set("Content-Type", accept)
+	}
+	hreq.Header.Set("Accept", accept)
+	hrsp, err := client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hrsp.Body.Close()
+	if hrsp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(hrsp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, u, hrsp.Status, bytes.TrimSpace(msg))
+	}
+	if rsp == nil || hrsp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(hrsp.Body).Decode(rsp)
+}
+
+// Publisher produces records to a topic, through the REST Proxy. The key of
+// each record is the key of its message.
+type Publisher struct {
+	client   *http.Client
+	topicURL string
+}
+
+// NewPublisher returns a Publisher for the topic, through the REST Proxy at
+// proxyURL.
+func NewPublisher(client *http.Client, proxyURL, topic string) *Publisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Publisher{
+		client:   client,
+		topicURL: strings.TrimSuffix(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+	}
+}
+
+// Publish implements mq.Publisher.
+func (p *Publisher) Publish(ctx context.Context, msgs []*mq.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	type produceRecord struct {
+		Key   []byte `json:"key,omitempty"`
+		Value []byte `json:"value"`
+	}
+	req := struct {
+		Records []produceRecord `json:"records"`
+	}{}
+	for _, m := range msgs {
+		req.Records = append(req.Records, produceRecord{Key: m.Key, Value: m.Data})
+	}
+	var rsp struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := do(ctx, p.client, http.MethodPost, p.topicURL, binaryType, req, &rsp); err != nil {
+		return fmt.Errorf("failed to produce %d records: %v", len(msgs), err)
+	}
+	for i, o := range rsp.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("failed to produce record %d: %d: %s", i, *o.ErrorCode, o.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/util/mq"
+)
+
+// fakeProxy serves the consumer API of a REST Proxy with a single consumer.
+type fakeProxy struct {
+	*httptest.Server
+	records   []record
+	committed []offset
+	deleted   bool
+}
+
+func newFakeProxy(t *testing.T) *fakeProxy {
+	t.Helper()
+	p := &fakeProxy{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /consumers/group", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"instance_id": "c", "base_uri": p.URL + "/consumers/group/instances/c"})
+	})
+	mux.HandleFunc("POST /consumers/group/instances/c/subscription", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /consumers/group/instances/c/records", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(p.records)
+	})
+	mux.HandleFunc("POST /consumers/group/instances/c/offsets", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Offsets []offset `json:"offsets"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.committed = append(p.committed, req.Offsets...)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /consumers/group/instances/c", func(w http.ResponseWriter, r *http.Request) {
+		p.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestSubscriber(t *testing.T) {
+	ctx := context.Background()
+	p := newFakeProxy(t)
+	p.records = []record{
+		{Topic: "leaves", Key: []byte("k"), Value: []byte("a"), Partition: 0, Offset: 7},
+		{Topic: "leaves", Value: []byte("b"), Partition: 1, Offset: 3},
+		{Topic: "leaves", Value: []byte("c"), Partition: 0, Offset: 8},
+	}
+
+	s, err := NewSubscriber(ctx, nil, p.URL, "group", "leaves")
+	if err != nil {
+		t.Fatalf("NewSubscriber(): %v", err)
+	}
+	msgs, err := s.Receive(ctx, 10)
+	if err != nil {
+		t.Fatalf("Receive(): %v", err)
+	}
+	if got, want := len(msgs), 3; got != want {
+		t.Fatalf("Receive() returned %d messages, want %d", got, want)
+	}
+	if got := msgs[0]; got.ID != "leaves/0/7" || string(got.Key) != "k" || string(got.Data) != "a" {
+		t.Errorf("Receive()[0] = %+v, want key k and data a", got)
+	}
+
+	if err := s.Ack(ctx, msgs); err != nil {
+		t.Fatalf("Ack(): %v", err)
+	}
+	// Only the highest offset of each partition is committed.
+	want := []offset{{Topic: "leaves", Partition: 0, Offset: 8}, {Topic: "leaves", Partition: 1, Offset: 3}}
+	if diff := cmp.Diff(want, p.committed); diff != "" {
+		t.Errorf("committed offsets diff (-want +got):\n%s", diff)
+	}
+
+	if err := s.Close(ctx); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if !p.deleted {
+		t.Error("Close() didn't delete the consumer")
+	}
+}
+
+func TestNewSubscriberError(t *testing.T) {
+	p := newFakeProxy(t)
+	if _, err := NewSubscriber(context.Background(), nil, p.URL, "other", "leaves"); err == nil {
+		t.Error("NewSubscriber() for an unknown group succeeded, want error")
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+	var got []record
+	failing := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /topics/leaves", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Records []record `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = append(got, req.Records...)
+		if failing {
+			_, _ = w.Write([]byte(`{"offsets":[{"error_code":50003,"error":"broker unavailable"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewPublisher(nil, srv.URL, "leaves")
+	if err := p.Publish(ctx, []*mq.Message{{Key: []byte("1/0"), Data: []byte("a")}, {Key: []byte("1/1"), Data: []byte("b")}}); err != nil {
+		t.Fatalf("Publish(): %v", err)
+	}
+	want := []record{{Key: []byte("1/0"), Value: []byte("a")}, {Key: []byte("1/1"), Value: []byte("b")}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("produced records diff (-want +got):\n%s", diff)
+	}
+
+	failing = true
+	if err := p.Publish(ctx, []*mq.Message{{Data: []byte("c")}}); err == nil {
+		t.Error("Publish() succeeded with a record error, want error")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mq defines the interface to the message queues, such as Kafka and
+// Google Cloud Pub/Sub, which leaves can be moved through. The
+// implementations are in its subpackages.
+package mq
+
+import "context"
+
+// Message is a message consumed from, or published to, a message queue.
+type Message struct {
+	// ID identifies the message within its queue, for logging.
+	ID string
+	// Key is the key of the message, if any. Messages with the same key are
+	// duplicates of each other.
+	Key []byte
+	// Data is the payload of the message.
+	Data []byte
+
+	// ack is the opaque token used by the Subscriber to acknowledge the
+	// message.
+	ack any
+}
+
+// NewMessage returns a Message received by a Subscriber, which acknowledges
+// it with the given token.
+func NewMessage(id string, key, data []byte, ack any) *Message {
+	return &Message{ID: id, Key: key, Data: data, ack: ack}
+}
+
+// AckToken returns the token passed to NewMessage.
+func (m *Message) AckToken() any {
+	return m.ack
+}
+
+// Subscriber consumes messages from a message queue with at-least-once
+// semantics: a message is delivered again, possibly to another Subscriber, if
+// it isn't acknowledged.
+type Subscriber interface {
+	// Receive waits for up to max messages. It may return fewer, including
+	// none if there are none after a while.
+	Receive(ctx context.Context, max int) ([]*Message, error)
+	// Ack acknowledges that msgs, received by Receive, have been processed,
+	// so they are not delivered again.
+	Ack(ctx context.Context, msgs []*Message) error
+	// Close releases the resources held by the Subscriber.
+	Close(ctx context.Context) error
+}
+
+// Publisher publishes messages to a message queue.
+type Publisher interface {
+	// Publish publishes msgs, in order. If it fails, some of them may have
+	// been published nonetheless.
+	Publish(ctx context.Context, msgs []*Message) error
+}
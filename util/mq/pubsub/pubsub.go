@@ -0,0 +1,140 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pubsub implements the mq interfaces for Google Cloud Pub/Sub.
+package pubsub
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/trillian/util/mq"
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// maxPublish is the maximum number of messages in a Pub/Sub publish request.
+const maxPublish = 1000
+
+// Subscriber pulls messages from a Pub/Sub subscription.
+type Subscriber struct {
+	subs         *pubsub.ProjectsSubscriptionsService
+	subscription string
+	keyAttribute string
+}
+
+// NewSubscriber returns a Subscriber for the subscription, named like
+// "projects/<project>/subscriptions/<subscription>". If keyAttribute is set,
+// the key of each message is the value of that attribute.
+func NewSubscriber(ctx context.Context, subscription, keyAttribute string, opts ...option.ClientOption) (*Subscriber, error) {
+	svc, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %v", err)
+	}
+	return &Subscriber{
+		subs:         pubsub.NewProjectsSubscriptionsService(svc),
+		subscription: subscription,
+		keyAttribute: keyAttribute,
+	}, nil
+}
+
+// Receive implements mq.Subscriber.
+func (s *Subscriber) Receive(ctx context.Context, max int) ([]*mq.Message, error) {
+	rsp, err := s.subs.Pull(s.subscription, &pubsub.PullRequest{MaxMessages: int64(max)}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull from %v: %v", s.subscription, err)
+	}
+	msgs := make([]*mq.Message, 0, len(rsp.ReceivedMessages))
+	for _, rm := range rsp.ReceivedMessages {
+		m := rm.Message
+		if m == nil {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(m.Data)
+		if err != nil {
+			return nil, fmt.Errorf("message %v: invalid data: %v", m.MessageId, err)
+		}
+		var key []byte
+		if s.keyAttribute != "" {
+			if v, ok := m.Attributes[s.keyAttribute]; ok {
+				key = []byte(v)
+			}
+		}
+		msgs = append(msgs, mq.NewMessage(m.MessageId, key, data, rm.AckId))
+	}
+	return msgs, nil
+}
+
+// Ack implements mq.Subscriber.
+func (s *Subscriber) Ack(ctx context.Context, msgs []*mq.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.AckToken().(string))
+	}
+	if _, err := s.subs.Acknowledge(s.subscription, &pubsub.AcknowledgeRequest{AckIds: ids}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to acknowledge %d messages of %v: %v", len(ids), s.subscription, err)
+	}
+	return nil
+}
+
+// Close implements mq.Subscriber.
+func (s *Subscriber) Close(ctx context.Context) error {
+	return nil
+}
+
+// Publisher publishes messages to a Pub/Sub topic.
+type Publisher struct {
+	topics       *pubsub.ProjectsTopicsService
+	topic        string
+	keyAttribute string
+}
+
+// NewPublisher returns a Publisher for the topic, named like
+// "projects/<project>/topics/<topic>". If keyAttribute is set, the key of
+// each message is set as the value of that attribute.
+func NewPublisher(ctx context.Context, topic, keyAttribute string, opts ...option.ClientOption) (*Publisher, error) {
+	svc, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %v", err)
+	}
+	return &Publisher{
+		topics:       pubsub.NewProjectsTopicsService(svc),
+		topic:        topic,
+		keyAttribute: keyAttribute,
+	}, nil
+}
+
+// Publish implements mq.Publisher.
+func (p *Publisher) Publish(ctx context.Context, msgs []*mq.Message) error {
+	for len(msgs) > 0 {
+		n := min(len(msgs), maxPublish)
+		req := &pubsub.PublishRequest{Messages: make([]*pubsub.PubsubMessage, 0, n)}
+		for _, m := range msgs[:n] {
+			pm := &pubsub.PubsubMessage{Data: base64.StdEncoding.EncodeToString(m.Data)}
+			if p.keyAttribute != "" && len(m.Key) > 0 {
+				pm.Attributes = map[string]string{p.keyAttribute: string(m.Key)}
+			}
+			req.Messages = append(req.Messages, pm)
+		}
+		if _, err := p.topics.Publish(p.topic, req).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to publish %d messages to %v: %v", n, p.topic, err)
+		}
+		msgs = msgs[n:]
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Trillian Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/trillian/util/mq"
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+func TestSubscriber(t *testing.T) {
+	ctx := context.Background()
+	var acked []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/projects/p/subscriptions/s:pull", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&pubsub.PullResponse{ReceivedMessages: []*pubsub.ReceivedMessage{
+			{AckId: "ack1", Message: &pubsub.PubsubMessage{MessageId: "1", Data: base64.StdEncoding.EncodeToString([]byte("a")), Attributes: map[string]string{"id": "k"}}},
+			{AckId: "ack2", Message: &pubsub.PubsubMessage{MessageId: "2", Data: base64.StdEncoding.EncodeToString([]byte("b"))}},
+		}})
+	})
+	mux.HandleFunc("POST /v1/projects/p/subscriptions/s:acknowledge", func(w http.ResponseWriter, r *http.Request) {
+		var req pubsub.AcknowledgeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		acked = append(acked, req.AckIds...)
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s, err := NewSubscriber(ctx, "projects/p/subscriptions/s", "id", option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewSubscriber(): %v", err)
+	}
+	msgs, err := s.Receive(ctx, 10)
+	if err != nil {
+		t.Fatalf("Receive(): %v", err)
+	}
+	if got, want := len(msgs), 2; got != want {
+		t.Fatalf("Receive() returned %d messages, want %d", got, want)
+	}
+	if got := msgs[0]; got.ID != "1" || string(got.Key) != "k" || string(got.Data) != "a" {
+		t.Errorf("Receive()[0] = %+v, want key k and data a", got)
+	}
+	if got := msgs[1]; got.Key != nil || string(got.Data) != "b" {
+		t.Errorf("Receive()[1] = %+v, want no key and data b", got)
+	}
+
+	if err := s.Ack(ctx, msgs); err != nil {
+		t.Fatalf("Ack(): %v", err)
+	}
+	if diff := cmp.Diff([]string{"ack1", "ack2"}, acked); diff != "" {
+		t.Errorf("acked diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+	var got []*pubsub.PubsubMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/projects/p/topics/t:publish", func(w http.ResponseWriter, r *http.Request) {
+		var req pubsub.PublishRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = append(got, req.Messages...)
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p, err := NewPublisher(ctx, "projects/p/topics/t", "id", option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewPublisher(): %v", err)
+	}
+	if err := p.Publish(ctx, []*mq.Message{{Key: []byte("1/0"), Data: []byte("a")}, {Data: []byte("b")}}); err != nil {
+		t.Fatalf("Publish(): %v", err)
+	}
+	want := []*pubsub.PubsubMessage{
+		{Data: base64.StdEncoding.EncodeToString([]byte("a")), Attributes: map[string]string{"id": "1/0"}},
+		{Data: base64.StdEncoding.EncodeToString([]byte("b"))},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(pubsub.PubsubMessage{})); diff != "" {
+		t.Errorf("published messages diff (-want +got):\n%s", diff)
+	}
+}